@@ -0,0 +1,270 @@
+package base
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["Bonus"] = &Bonus{}
+}
+
+// Bonus 奖池组件
+// 奖池按 游戏类型:场次 分别存放在redis中
+// 真实奖池 RedisBonusTable，系统奖池 RedisBonusSystemTable，机器人展示奖池 RedisBonusRobotTable
+type Bonus struct {
+	common.BonusI
+	Base
+}
+
+// LoadComponent 加载组件
+func (obj *Bonus) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *Bonus) Start() {
+	obj.Base.Start()
+}
+
+// getBonusKey 获取奖池的键名 游戏类型:场次
+func getBonusKey(gameType pb.GameType, gameScene int32) string {
+	return strconv.Itoa(int(gameType)) + ":" + strconv.Itoa(int(gameScene))
+}
+
+// getSystemRatio 获取系统奖池抽成比例(单位%)，读取场次配置BonusSystemRatio，未配置时为0
+func getSystemRatio(gameType pb.GameType, gameScene int32) int64 {
+	config := common.Configer.GetGameConfig(gameType, gameScene, "BonusSystemRatio")
+	if config == nil {
+		return 0
+	}
+	ratio, err := strconv.ParseInt(config.GetValue(), 10, 64)
+	if err != nil || ratio < 0 || ratio > 100 {
+		common.LogError("Bonus getSystemRatio BonusSystemRatio config err", gameType, gameScene, config.GetValue())
+		return 0
+	}
+	return ratio
+}
+
+// getScore 读取奖池分数，不存在时为0
+func (obj *Bonus) getScore(table string, key string, extroInfo *pb.MessageExtroInfo) (int64, *pb.ErrorMessage) {
+	request := &pb.RedisMessage{}
+	request.Table = table
+	request.Key = key
+	reply := &pb.RedisMessage{}
+	msgErr := common.Router.Call("Redis", "GetString", request, reply, extroInfo)
+	if msgErr != nil {
+		common.LogError("Bonus getScore GetString has err", table, key, msgErr)
+		return 0, msgErr
+	}
+	if reply.GetValueString() == "" {
+		return 0, nil
+	}
+	score, err := strconv.ParseInt(reply.GetValueString(), 10, 64)
+	if err != nil {
+		common.LogError("Bonus getScore ParseInt has err", table, key, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return score, nil
+}
+
+// setScore 保存奖池分数
+func (obj *Bonus) setScore(table string, key string, score int64, extroInfo *pb.MessageExtroInfo) *pb.ErrorMessage {
+	request := &pb.RedisMessage{}
+	request.Table = table
+	request.Key = key
+	request.ValueString = strconv.FormatInt(score, 10)
+	msgErr := common.Router.Call("Redis", "SetString", request, &pb.RedisMessage{}, extroInfo)
+	if msgErr != nil {
+		common.LogError("Bonus setScore SetString has err", table, key, msgErr)
+		return msgErr
+	}
+	return nil
+}
+
+// incrSystemScore 系统奖池增加/减少分数，返回变动后的分数
+func (obj *Bonus) incrSystemScore(key string, score int64, extroInfo *pb.MessageExtroInfo) (int64, *pb.ErrorMessage) {
+	request := &pb.RedisMessage{}
+	request.Table = common.RedisBonusSystemTable + ":" + key
+	request.Count = score
+	reply := &pb.RedisMessage{}
+	msgErr := common.Router.Call("Redis", "IncrBy", request, reply, extroInfo)
+	if msgErr != nil {
+		common.LogError("Bonus incrSystemScore IncrBy has err", key, msgErr)
+		return 0, msgErr
+	}
+	return reply.GetValueInt64(), nil
+}
+
+// newBonusRecord 生成奖池变动记录
+func newBonusRecord(gameType pb.GameType, gameScene int32, reason pb.ResourceChangeReason, beforeBonus int64, afterBonus int64) *pb.BonusRecordReport {
+	record := &pb.BonusRecordReport{}
+	record.GameType = gameType
+	record.GameScene = gameScene
+	record.ChangeTime = time.Now().Unix()
+	record.ChangeReason = reason
+	record.BonusName = common.RedisBonusTable + ":" + getBonusKey(gameType, gameScene)
+	record.BeforeBonusChangeNum = beforeBonus
+	record.BonusChangeNum = afterBonus - beforeBonus
+	record.AfterBonusChangeNum = afterBonus
+	return record
+}
+
+// GetBonusByGameTypeAndGameScene 根据游戏类型和场次按比例从奖池中取出分数
+// 参数：游戏类型gameType,游戏场次gameScene,取值比例ratio(单位%),reason原因
+// 返回：取出的分数，奖池变动记录，错误信息
+// 奖池变动记录不在此推送，由外部补全玩家信息（Uuid,ShortId）后调用common.PushBonusRecord推送
+func (obj *Bonus) GetBonusByGameTypeAndGameScene(gameType pb.GameType, gameScene int32, ratio int, reason pb.ResourceChangeReason) (int64, *pb.BonusRecordReport, *pb.ErrorMessage) {
+	if ratio <= 0 || ratio > 100 {
+		common.LogError("Bonus GetBonusByGameTypeAndGameScene ratio err", gameType, gameScene, ratio)
+		return 0, nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	extroInfo := &pb.MessageExtroInfo{}
+	key := getBonusKey(gameType, gameScene)
+	mutex, err := obj.ComponentLock(common.MessageLockBonus+key, extroInfo)
+	if err != nil {
+		common.LogError("Bonus GetBonusByGameTypeAndGameScene MessageLockBonus has err", err)
+		return 0, nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	defer obj.ComponentUnlock(common.MessageLockBonus+key, extroInfo, mutex)
+
+	beforeBonus, msgErr := obj.getScore(common.RedisBonusTable, key, extroInfo)
+	if msgErr != nil {
+		return 0, nil, msgErr
+	}
+	getScore := beforeBonus * int64(ratio) / 100
+	if getScore <= 0 {
+		return 0, nil, nil
+	}
+	afterBonus := beforeBonus - getScore
+	msgErr = obj.setScore(common.RedisBonusTable, key, afterBonus, extroInfo)
+	if msgErr != nil {
+		return 0, nil, msgErr
+	}
+
+	record := newBonusRecord(gameType, gameScene, reason, beforeBonus, afterBonus)
+	record.BonusReward = getScore
+	systemBonus, msgErr := obj.getScore(common.RedisBonusSystemTable, key, extroInfo)
+	if msgErr == nil {
+		record.BeforeSystemBonus = systemBonus
+		record.AfterSystemBonus = systemBonus
+	}
+	return getScore, record, nil
+}
+
+// AddBonus 增加/减少奖池金额
+// 参数：gameType游戏类型，gameScene游戏场次，incrScore增加/减少的金额，reason原因，boss是否为老板奖池（不抽系统奖池）
+// 增加时按BonusSystemRatio抽成到系统奖池，返回奖池变动后的金额
+// 减少时奖池最多扣到0，返回实际扣除的金额
+func (obj *Bonus) AddBonus(gameType pb.GameType, gameScene int32, incrScore int64, reason pb.ResourceChangeReason, boss bool) (int64, *pb.BonusRecordReport, *pb.ErrorMessage) {
+	if incrScore == 0 {
+		return 0, nil, nil
+	}
+	extroInfo := &pb.MessageExtroInfo{}
+	key := getBonusKey(gameType, gameScene)
+	mutex, err := obj.ComponentLock(common.MessageLockBonus+key, extroInfo)
+	if err != nil {
+		common.LogError("Bonus AddBonus MessageLockBonus has err", err)
+		return 0, nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	defer obj.ComponentUnlock(common.MessageLockBonus+key, extroInfo, mutex)
+
+	beforeBonus, msgErr := obj.getScore(common.RedisBonusTable, key, extroInfo)
+	if msgErr != nil {
+		return 0, nil, msgErr
+	}
+
+	systemRatio := int64(0)
+	systemScore := int64(0)
+	afterBonus := beforeBonus + incrScore
+	if incrScore > 0 && !boss {
+		systemRatio = getSystemRatio(gameType, gameScene)
+		systemScore = incrScore * systemRatio / 100
+		afterBonus -= systemScore
+	}
+	if afterBonus < 0 {
+		afterBonus = 0
+	}
+	msgErr = obj.setScore(common.RedisBonusTable, key, afterBonus, extroInfo)
+	if msgErr != nil {
+		return 0, nil, msgErr
+	}
+
+	record := newBonusRecord(gameType, gameScene, reason, beforeBonus, afterBonus)
+	record.SystemRatio = systemRatio
+	if systemScore > 0 {
+		afterSystem, msgErr := obj.incrSystemScore(key, systemScore, extroInfo)
+		if msgErr != nil {
+			common.LogError("Bonus AddBonus incrSystemScore has err", key, systemScore)
+		} else {
+			record.BeforeSystemBonus = afterSystem - systemScore
+			record.ChangeSystemBonus = systemScore
+			record.AfterSystemBonus = afterSystem
+		}
+	}
+	common.PushBonusRecord(record)
+
+	if incrScore > 0 {
+		return afterBonus, record, nil
+	}
+	return beforeBonus - afterBonus, record, nil
+}
+
+// GetBonusOnlyReady 只读获取奖池分数
+// 参数：游戏类型gameType,游戏场次gameScene
+// 返回：分数，错误信息
+func (obj *Bonus) GetBonusOnlyReady(gameType pb.GameType, gameScene int32) (int64, *pb.ErrorMessage) {
+	return obj.getScore(common.RedisBonusTable, getBonusKey(gameType, gameScene), &pb.MessageExtroInfo{})
+}
+
+// GetRobotBonus 获取机器人的奖池分 -- 只是一个展示的分数而已,不影响真实奖池
+// 参数：gameType游戏类型，gameScene游戏场次
+// 返回：分数,错误信息
+func (obj *Bonus) GetRobotBonus(gameType pb.GameType, gameScene int32) (int64, *pb.ErrorMessage) {
+	return obj.getScore(common.RedisBonusRobotTable, getBonusKey(gameType, gameScene), &pb.MessageExtroInfo{})
+}
+
+// AddRobotBonus 增加/减少机器人奖池分数 -- 只是一个展示的分数而已,不影响真实奖池
+// 参数：gameType游戏类型，gameScene游戏场次，score分数
+// 返回：错误信息
+func (obj *Bonus) AddRobotBonus(gameType pb.GameType, gameScene int32, score int64) *pb.ErrorMessage {
+	extroInfo := &pb.MessageExtroInfo{}
+	key := getBonusKey(gameType, gameScene)
+	mutex, err := obj.ComponentLock(common.MessageLockRobotBonus+key, extroInfo)
+	if err != nil {
+		common.LogError("Bonus AddRobotBonus MessageLockRobotBonus has err", err)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	defer obj.ComponentUnlock(common.MessageLockRobotBonus+key, extroInfo, mutex)
+
+	robotBonus, msgErr := obj.getScore(common.RedisBonusRobotTable, key, extroInfo)
+	if msgErr != nil {
+		return msgErr
+	}
+	robotBonus += score
+	if robotBonus < 0 {
+		robotBonus = 0
+	}
+	return obj.setScore(common.RedisBonusRobotTable, key, robotBonus, extroInfo)
+}
+
+// IncrBySystemBonus 增加/减少 系统奖池
+// 参数：gameType游戏类型，gameScene游戏场次，score分数
+// 返回：变动前金额,变动后金额,错误信息
+func (obj *Bonus) IncrBySystemBonus(gameType pb.GameType, gameScene int32, score int64) (int64, int64, *pb.ErrorMessage) {
+	afterSystem, msgErr := obj.incrSystemScore(getBonusKey(gameType, gameScene), score, &pb.MessageExtroInfo{})
+	if msgErr != nil {
+		return 0, 0, msgErr
+	}
+	return afterSystem - score, afterSystem, nil
+}
+
+// OnlyReadSystemBonus 只读查询 系统奖池分数
+// 参数：gameType游戏类型，gameScene游戏场次
+// 返回：分数,错误信息
+func (obj *Bonus) OnlyReadSystemBonus(gameType pb.GameType, gameScene int32) (int64, *pb.ErrorMessage) {
+	return obj.getScore(common.RedisBonusSystemTable, getBonusKey(gameType, gameScene), &pb.MessageExtroInfo{})
+}
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"gameServer-demo/src/common"
 	"strconv"
+	"time"
 
 	pb "gameServer-demo/src/grpc"
 
 	"github.com/golang/protobuf/proto"
+	uuid "github.com/satori/go.uuid"
 	"github.com/streadway/amqp"
 )
 
@@ -216,6 +218,7 @@ func (obj *MQ) SendReport(msg []byte) error {
 	}
 	err = obj.ReportMsgPublishChannel.Publish("", queueName, false, false, amqp.Publishing{
 		ContentType: "text/plain",
+		MessageId:   uuid.NewV4().String(),
 		Body:        msg,
 	})
 	if err != nil {
@@ -285,6 +288,95 @@ func (obj *MQ) BindReport(uuid string, subCallBack common.ReportMsgSubFunc) erro
 	return nil
 }
 
+// BindReportBatch 绑定报表批量消费者
+// 每攒够batchSize条消息或者每秒回调一次，回调成功整批确认，失败整批重新入队
+func (obj *MQ) BindReportBatch(uuid string, batchSize int, subCallBack common.ReportMsgBatchSubFunc) error {
+	if common.IsDev == true {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	err := obj.CheckConnection()
+	if err != nil {
+		common.LogError("MQ BindReportBatch CheckConnection has err", err)
+		return err
+	}
+	err = obj.ReportMsgPublishChannel.Cancel(uuid, false)
+	if err != nil {
+		common.LogError("MQ BindReportBatch Cancel has err", err)
+		return err
+	}
+	queueName := common.ReportMsgQueue
+	_, err = obj.ReportMsgPublishChannel.QueueDeclare(queueName, true, false, false, false, nil)
+	if err != nil {
+		common.LogError("MQ BindReportBatch QueueDeclare has err", err)
+		return err
+	}
+	// 预取数量与批量大小一致，否则攒不够一批
+	err = obj.ReportMsgPublishChannel.Qos(batchSize, 0, true)
+	if err != nil {
+		common.LogError("MQ BindReportBatch Qos has err", err)
+		return err
+	}
+	msgList, err := obj.ReportMsgPublishChannel.Consume(queueName, uuid, false, false, false, false, nil)
+	if err != nil {
+		common.LogError("MQ BindReportBatch Consume has err", err)
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		batch := make([]amqp.Delivery, 0, batchSize)
+		// 回调并确认消息
+		flush := func() {
+			if len(batch) <= 0 {
+				return
+			}
+			deliveryList := make([]*common.ReportMsgDelivery, 0, len(batch))
+			for _, msg := range batch {
+				deliveryList = append(deliveryList, &common.ReportMsgDelivery{
+					MessageId: msg.MessageId,
+					Body:      msg.Body,
+				})
+			}
+			cbErr := subCallBack(deliveryList)
+			for _, msg := range batch {
+				if cbErr != nil {
+					// 重新入队，否则未确认的消息会持续占用内存
+					err := msg.Reject(true)
+					if err != nil {
+						common.LogError("MQ BindReportBatch Reject has err", err)
+					}
+					continue
+				}
+				err := msg.Ack(false)
+				if err != nil {
+					common.LogError("MQ BindReportBatch Ack has err", err)
+				}
+			}
+			batch = batch[:0]
+		}
+		for {
+			select {
+			case msg, ok := <-msgList:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, msg)
+				if len(batch) >= batchSize {
+					flush()
+				}
+			case <-ticker.C:
+				flush()
+			}
+		}
+	}()
+
+	return nil
+}
+
 // UnBindReport 取消绑定报表消费者
 func (obj *MQ) UnBindReport(uuid string) error {
 	err := obj.ReportMsgPublishChannel.Cancel(uuid, false)
@@ -0,0 +1,143 @@
+package common
+
+import (
+	pb "gameServer-demo/src/grpc"
+	"sort"
+)
+
+// 牛牛一手牌的张数
+const bullPokerNum = 5
+
+// GetBullPokerValue 获取一张牌在牛牛中的点数，10和JQK都算10点
+func GetBullPokerValue(poker *pb.Poker) int64 {
+	if poker.GetPokerNum() >= pb.PokerNum_PokerNum10 {
+		return 10
+	}
+	return int64(poker.GetPokerNum())
+}
+
+// GetBullPokerType 获取五张牌的牛牛牌型
+// 牌型大小：五小牛>炸弹牛>同花牛>葫芦牛>顺子牛>五花牛>牛牛>有牛（从牛9到牛1）>无牛
+// 参数：pokers 五张牌
+// 返回值：牌型，牌数不是五张时返回CrazyBullCardType_None
+func GetBullPokerType(pokers []*pb.Poker) pb.CrazyBullPokerType {
+	if len(pokers) != bullPokerNum {
+		return pb.CrazyBullPokerType_CrazyBullCardType_None
+	}
+	// 五小牛：五张牌都小于5并且点数和不大于10
+	var sum int64
+	isLittle := true
+	for _, poker := range pokers {
+		value := GetBullPokerValue(poker)
+		sum += value
+		if value >= 5 {
+			isLittle = false
+		}
+	}
+	if isLittle && sum <= 10 {
+		return pb.CrazyBullPokerType_CrazyBullCardType_LittleBull
+	}
+
+	// 统计每个点数的张数
+	numCount := make(map[pb.PokerNum]int)
+	for _, poker := range pokers {
+		numCount[poker.GetPokerNum()]++
+	}
+	var counts []int
+	for _, count := range numCount {
+		counts = append(counts, count)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	// 炸弹牛：有四张点数相同
+	if counts[0] == 4 {
+		return pb.CrazyBullPokerType_CrazyBullCardType_BoomBull
+	}
+	// 同花牛：五张牌花色相同
+	isSameFlower := true
+	for _, poker := range pokers[1:] {
+		if poker.GetPokerColor() != pokers[0].GetPokerColor() {
+			isSameFlower = false
+			break
+		}
+	}
+	if isSameFlower {
+		return pb.CrazyBullPokerType_CrazyBullCardType_SameFlowerBull
+	}
+	// 葫芦牛：三张点数相同加一对
+	if counts[0] == 3 && counts[1] == 2 {
+		return pb.CrazyBullPokerType_CrazyBullCardType_GourdBull
+	}
+	// 顺子牛：五张点数连续
+	if len(numCount) == bullPokerNum {
+		minNum, maxNum := pokers[0].GetPokerNum(), pokers[0].GetPokerNum()
+		for _, poker := range pokers[1:] {
+			if poker.GetPokerNum() < minNum {
+				minNum = poker.GetPokerNum()
+			}
+			if poker.GetPokerNum() > maxNum {
+				maxNum = poker.GetPokerNum()
+			}
+		}
+		if maxNum-minNum == bullPokerNum-1 {
+			return pb.CrazyBullPokerType_CrazyBullCardType_AlongBull
+		}
+	}
+	// 五花牛：五张牌都是JQK
+	isStreaky := true
+	for _, poker := range pokers {
+		if poker.GetPokerNum() < pb.PokerNum_PokerNumJ {
+			isStreaky = false
+			break
+		}
+	}
+	if isStreaky {
+		return pb.CrazyBullPokerType_CrazyBullCardType_StreakyBull
+	}
+
+	// 普通牛：任意三张的点数和是10的倍数，剩下两张的点数和的个位数就是牛几
+	for i := 0; i < bullPokerNum; i++ {
+		for j := i + 1; j < bullPokerNum; j++ {
+			for k := j + 1; k < bullPokerNum; k++ {
+				threeSum := GetBullPokerValue(pokers[i]) + GetBullPokerValue(pokers[j]) + GetBullPokerValue(pokers[k])
+				if threeSum%10 != 0 {
+					continue
+				}
+				point := (sum - threeSum) % 10
+				if point == 0 {
+					return pb.CrazyBullPokerType_CrazyBullCardType_BullBull
+				}
+				return pb.CrazyBullPokerType(point)
+			}
+		}
+	}
+	return pb.CrazyBullPokerType_CrazyBullCardType_None
+}
+
+// GetBullMaxPoker 获取一手牌中最大的单张牌，先比点数K最大A最小，点数相同再比花色
+func GetBullMaxPoker(pokers []*pb.Poker) *pb.Poker {
+	var maxPoker *pb.Poker
+	for _, poker := range pokers {
+		if maxPoker == nil || compareBullSinglePoker(poker, maxPoker) {
+			maxPoker = poker
+		}
+	}
+	return maxPoker
+}
+
+// CompareBullPokers 比较两手牛牛牌的大小，牌型相同时比较最大的单张牌
+// 参数：a，b 两手牌，以及它们的牌型
+// 返回值：a比b大时返回true
+func CompareBullPokers(a []*pb.Poker, aType pb.CrazyBullPokerType, b []*pb.Poker, bType pb.CrazyBullPokerType) bool {
+	if aType != bType {
+		return aType > bType
+	}
+	return compareBullSinglePoker(GetBullMaxPoker(a), GetBullMaxPoker(b))
+}
+
+// compareBullSinglePoker 比较两张牌的大小，a比b大时返回true
+func compareBullSinglePoker(a *pb.Poker, b *pb.Poker) bool {
+	if a.GetPokerNum() != b.GetPokerNum() {
+		return a.GetPokerNum() > b.GetPokerNum()
+	}
+	return a.GetPokerColor() > b.GetPokerColor()
+}
@@ -7,9 +7,104 @@ import (
 // PushBobbinGameConfigTemp 推筒子配置模板
 var PushBobbinGameConfigTemp map[string]*pb.GameConfig
 
+// DragonTigerFightGameConfigTemp 龙虎斗配置模板
+var DragonTigerFightGameConfigTemp map[string]*pb.GameConfig
+
+// BaccaratGameConfigTemp 百家乐配置模板
+var BaccaratGameConfigTemp map[string]*pb.GameConfig
+
+// HundredBullGameConfigTemp 百人牛牛配置模板
+var HundredBullGameConfigTemp map[string]*pb.GameConfig
+
+// RedBlackGameConfigTemp 红黑大战配置模板
+var RedBlackGameConfigTemp map[string]*pb.GameConfig
+
+// BenzBMWGameConfigTemp 奔驰宝马配置模板
+var BenzBMWGameConfigTemp map[string]*pb.GameConfig
+
+// GemWarsGameConfigTemp 宝石战争配置模板
+var GemWarsGameConfigTemp map[string]*pb.GameConfig
+
+// CompareBullGameConfigTemp 拼牛牛配置模板
+var CompareBullGameConfigTemp map[string]*pb.GameConfig
+
+// CrazyBullGameConfigTemp 疯狂牛牛配置模板
+var CrazyBullGameConfigTemp map[string]*pb.GameConfig
+
+// JinhuaGameConfigTemp 炸金花配置模板
+var JinhuaGameConfigTemp map[string]*pb.GameConfig
+
+// RunFastGameConfigTemp 跑得快配置模板
+var RunFastGameConfigTemp map[string]*pb.GameConfig
+
+// ShiSanShuiGameConfigTemp 十三水配置模板
+var ShiSanShuiGameConfigTemp map[string]*pb.GameConfig
+
+// XueZhanMahjongGameConfigTemp 血战麻将配置模板
+var XueZhanMahjongGameConfigTemp map[string]*pb.GameConfig
+
+// LinCangMahjongGameConfigTemp 临沧麻将配置模板
+var LinCangMahjongGameConfigTemp map[string]*pb.GameConfig
+
+// GangHuaMahjongGameConfigTemp 杠花麻将配置模板
+var GangHuaMahjongGameConfigTemp map[string]*pb.GameConfig
+
+// LinkUpGameConfigTemp 连连看配置模板
+var LinkUpGameConfigTemp map[string]*pb.GameConfig
+
+// LineGameGameConfigTemp 连线游戏配置模板
+var LineGameGameConfigTemp map[string]*pb.GameConfig
+
+// ClearJoyGameConfigTemp 消消乐配置模板
+var ClearJoyGameConfigTemp map[string]*pb.GameConfig
+
+// DaXuanGameConfigTemp 打旋配置模板
+var DaXuanGameConfigTemp map[string]*pb.GameConfig
+
+// DoubleLinkedGameConfigTemp 双连配置模板
+var DoubleLinkedGameConfigTemp map[string]*pb.GameConfig
+
 func init() {
 	// 推筒子配置模板
 	pushBobbinConfigTemp()
+	// 龙虎斗配置模板
+	dragonTigerFightConfigTemp()
+	// 百家乐配置模板
+	baccaratConfigTemp()
+	// 百人牛牛配置模板
+	hundredBullConfigTemp()
+	// 红黑大战配置模板
+	redBlackConfigTemp()
+	// 奔驰宝马配置模板
+	benzBMWConfigTemp()
+	// 宝石战争配置模板
+	gemWarsConfigTemp()
+	// 拼牛牛配置模板
+	compareBullConfigTemp()
+	// 疯狂牛牛配置模板
+	crazyBullConfigTemp()
+	// 炸金花配置模板
+	jinhuaConfigTemp()
+	// 跑得快配置模板
+	runFastConfigTemp()
+	// 十三水配置模板
+	shiSanShuiConfigTemp()
+	// 血战麻将配置模板
+	xueZhanMahjongConfigTemp()
+	// 临沧麻将配置模板
+	linCangMahjongConfigTemp()
+	// 杠花麻将配置模板
+	gangHuaMahjongConfigTemp()
+	// 连连看配置模板
+	linkUpConfigTemp()
+	// 连线游戏配置模板
+	lineGameConfigTemp()
+	// 消消乐配置模板
+	clearJoyConfigTemp()
+	// 打旋配置模板
+	daXuanConfigTemp()
+	// 双连配置模板
+	doubleLinkedConfigTemp()
 }
 
 // InitGameConfigTemp 预设组件要用的游戏配置模版，如果变量已存在，则不重置
@@ -182,3 +277,1928 @@ func pushBobbinConfigTemp() {
 		Remark: "推筒子的抽水，单位：%",
 	}
 }
+
+//龙虎斗配置模版
+func dragonTigerFightConfigTemp() {
+	DragonTigerFightGameConfigTemp = make(map[string]*pb.GameConfig)
+	DragonTigerFightGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "100",
+		Remark: "龙虎斗的房间最大容纳的玩家数量",
+	}
+	DragonTigerFightGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "龙虎斗的入场限制",
+	}
+	DragonTigerFightGameConfigTemp["OutBalance"] = &pb.GameConfig{
+		Name:   "OutBalance",
+		Value:  "0",
+		Remark: "龙虎斗的出场限制",
+	}
+	DragonTigerFightGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "2",
+		Remark: "龙虎斗的发牌阶段时长",
+	}
+	DragonTigerFightGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "3",
+		Remark: "龙虎斗的准备阶段时长",
+	}
+	DragonTigerFightGameConfigTemp["BetTime"] = &pb.GameConfig{
+		Name:   "BetTime",
+		Value:  "15",
+		Remark: "龙虎斗的下注阶段时长",
+	}
+	DragonTigerFightGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "5",
+		Remark: "龙虎斗的结算开牌阶段时长",
+	}
+	DragonTigerFightGameConfigTemp["OddsDragon"] = &pb.GameConfig{
+		Name:   "OddsDragon",
+		Value:  "1",
+		Remark: "龙虎斗的龙区域赔率",
+	}
+	DragonTigerFightGameConfigTemp["OddsTiger"] = &pb.GameConfig{
+		Name:   "OddsTiger",
+		Value:  "1",
+		Remark: "龙虎斗的虎区域赔率",
+	}
+	DragonTigerFightGameConfigTemp["OddsDraw"] = &pb.GameConfig{
+		Name:   "OddsDraw",
+		Value:  "8",
+		Remark: "龙虎斗的和区域赔率",
+	}
+	DragonTigerFightGameConfigTemp["DrawReturnRatio"] = &pb.GameConfig{
+		Name:   "DrawReturnRatio",
+		Value:  "50",
+		Remark: "龙虎斗开和时龙、虎区域下注的退还比例，单位：%",
+	}
+	DragonTigerFightGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "1,3",
+		Remark: "龙虎斗的游戏类型",
+	}
+	DragonTigerFightGameConfigTemp["UserBankerMoney"] = &pb.GameConfig{
+		Name:   "UserBankerMoney",
+		Value:  "10000",
+		Remark: "龙虎斗的玩家当庄所需最低金额",
+	}
+	DragonTigerFightGameConfigTemp["UserBankerRound"] = &pb.GameConfig{
+		Name:   "UserBankerRound",
+		Value:  "5",
+		Remark: "龙虎斗的玩家当庄最多回合数",
+	}
+	DragonTigerFightGameConfigTemp["DefaultBankerMoney"] = &pb.GameConfig{
+		Name:   "DefaultBankerMoney",
+		Value:  "100000",
+		Remark: "龙虎斗的系统当庄默认的金钱数",
+	}
+	DragonTigerFightGameConfigTemp["BankersLength"] = &pb.GameConfig{
+		Name:   "BankersLength",
+		Value:  "10",
+		Remark: "龙虎斗庄家申请列表人数限制",
+	}
+	DragonTigerFightGameConfigTemp["Chips"] = &pb.GameConfig{
+		Name:   "Chips",
+		Value:  "1000,5000,10000,50000,100000,500000",
+		Remark: "龙虎斗的下注的筹码值",
+	}
+	DragonTigerFightGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "龙虎斗的抽水，单位：%",
+	}
+}
+
+//百家乐配置模版
+func baccaratConfigTemp() {
+	BaccaratGameConfigTemp = make(map[string]*pb.GameConfig)
+	BaccaratGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "100",
+		Remark: "百家乐的房间最大容纳的玩家数量",
+	}
+	BaccaratGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "百家乐的入场限制",
+	}
+	BaccaratGameConfigTemp["OutBalance"] = &pb.GameConfig{
+		Name:   "OutBalance",
+		Value:  "0",
+		Remark: "百家乐的出场限制",
+	}
+	BaccaratGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "2",
+		Remark: "百家乐的发牌阶段时长",
+	}
+	BaccaratGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "3",
+		Remark: "百家乐的准备阶段时长",
+	}
+	BaccaratGameConfigTemp["BetTime"] = &pb.GameConfig{
+		Name:   "BetTime",
+		Value:  "15",
+		Remark: "百家乐的下注阶段时长",
+	}
+	BaccaratGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "8",
+		Remark: "百家乐的结算开牌阶段时长",
+	}
+	BaccaratGameConfigTemp["OddsZhuang"] = &pb.GameConfig{
+		Name:   "OddsZhuang",
+		Value:  "1",
+		Remark: "百家乐的庄区域赔率",
+	}
+	BaccaratGameConfigTemp["OddsXian"] = &pb.GameConfig{
+		Name:   "OddsXian",
+		Value:  "1",
+		Remark: "百家乐的闲区域赔率",
+	}
+	BaccaratGameConfigTemp["OddsHe"] = &pb.GameConfig{
+		Name:   "OddsHe",
+		Value:  "8",
+		Remark: "百家乐的和区域赔率",
+	}
+	BaccaratGameConfigTemp["OddsZhuangDui"] = &pb.GameConfig{
+		Name:   "OddsZhuangDui",
+		Value:  "11",
+		Remark: "百家乐的庄对区域赔率",
+	}
+	BaccaratGameConfigTemp["OddsXianDui"] = &pb.GameConfig{
+		Name:   "OddsXianDui",
+		Value:  "11",
+		Remark: "百家乐的闲对区域赔率",
+	}
+	BaccaratGameConfigTemp["ZhuangCommission"] = &pb.GameConfig{
+		Name:   "ZhuangCommission",
+		Value:  "5",
+		Remark: "百家乐庄区域赢钱时的佣金，单位：%",
+	}
+	BaccaratGameConfigTemp["DeckNum"] = &pb.GameConfig{
+		Name:   "DeckNum",
+		Value:  "8",
+		Remark: "百家乐牌靴中牌的副数",
+	}
+	BaccaratGameConfigTemp["CutCardNum"] = &pb.GameConfig{
+		Name:   "CutCardNum",
+		Value:  "60",
+		Remark: "百家乐牌靴剩余牌数少于这个数时换新牌靴",
+	}
+	BaccaratGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "1,3",
+		Remark: "百家乐的游戏类型",
+	}
+	BaccaratGameConfigTemp["UserBankerMoney"] = &pb.GameConfig{
+		Name:   "UserBankerMoney",
+		Value:  "10000",
+		Remark: "百家乐的玩家当庄所需最低金额",
+	}
+	BaccaratGameConfigTemp["UserBankerRound"] = &pb.GameConfig{
+		Name:   "UserBankerRound",
+		Value:  "5",
+		Remark: "百家乐的玩家当庄最多回合数",
+	}
+	BaccaratGameConfigTemp["DefaultBankerMoney"] = &pb.GameConfig{
+		Name:   "DefaultBankerMoney",
+		Value:  "100000",
+		Remark: "百家乐的系统当庄默认的金钱数",
+	}
+	BaccaratGameConfigTemp["BankersLength"] = &pb.GameConfig{
+		Name:   "BankersLength",
+		Value:  "10",
+		Remark: "百家乐庄家申请列表人数限制",
+	}
+	BaccaratGameConfigTemp["Chips"] = &pb.GameConfig{
+		Name:   "Chips",
+		Value:  "1000,5000,10000,50000,100000,500000",
+		Remark: "百家乐的下注的筹码值",
+	}
+	BaccaratGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "0",
+		Remark: "百家乐的抽水，单位：%",
+	}
+}
+
+//百人牛牛配置模版
+func hundredBullConfigTemp() {
+	HundredBullGameConfigTemp = make(map[string]*pb.GameConfig)
+	HundredBullGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "100",
+		Remark: "百人牛牛的房间最大容纳的玩家数量",
+	}
+	HundredBullGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "百人牛牛的入场限制",
+	}
+	HundredBullGameConfigTemp["OutBalance"] = &pb.GameConfig{
+		Name:   "OutBalance",
+		Value:  "0",
+		Remark: "百人牛牛的出场限制",
+	}
+	HundredBullGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "3",
+		Remark: "百人牛牛的发牌阶段时长",
+	}
+	HundredBullGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "3",
+		Remark: "百人牛牛的准备阶段时长",
+	}
+	HundredBullGameConfigTemp["BetTime"] = &pb.GameConfig{
+		Name:   "BetTime",
+		Value:  "15",
+		Remark: "百人牛牛的下注阶段时长",
+	}
+	HundredBullGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "10",
+		Remark: "百人牛牛的结算开牌阶段时长",
+	}
+	HundredBullGameConfigTemp["OddsNone"] = &pb.GameConfig{
+		Name:   "OddsNone",
+		Value:  "1",
+		Remark: "百人牛牛的无牛翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsBull1"] = &pb.GameConfig{
+		Name:   "OddsBull1",
+		Value:  "1",
+		Remark: "百人牛牛的牛1翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsBull2"] = &pb.GameConfig{
+		Name:   "OddsBull2",
+		Value:  "1",
+		Remark: "百人牛牛的牛2翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsBull3"] = &pb.GameConfig{
+		Name:   "OddsBull3",
+		Value:  "1",
+		Remark: "百人牛牛的牛3翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsBull4"] = &pb.GameConfig{
+		Name:   "OddsBull4",
+		Value:  "1",
+		Remark: "百人牛牛的牛4翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsBull5"] = &pb.GameConfig{
+		Name:   "OddsBull5",
+		Value:  "1",
+		Remark: "百人牛牛的牛5翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsBull6"] = &pb.GameConfig{
+		Name:   "OddsBull6",
+		Value:  "1",
+		Remark: "百人牛牛的牛6翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsBull7"] = &pb.GameConfig{
+		Name:   "OddsBull7",
+		Value:  "2",
+		Remark: "百人牛牛的牛7翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsBull8"] = &pb.GameConfig{
+		Name:   "OddsBull8",
+		Value:  "2",
+		Remark: "百人牛牛的牛8翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsBull9"] = &pb.GameConfig{
+		Name:   "OddsBull9",
+		Value:  "2",
+		Remark: "百人牛牛的牛9翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsBullBull"] = &pb.GameConfig{
+		Name:   "OddsBullBull",
+		Value:  "3",
+		Remark: "百人牛牛的牛牛翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsStreakyBull"] = &pb.GameConfig{
+		Name:   "OddsStreakyBull",
+		Value:  "4",
+		Remark: "百人牛牛的五花牛翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsAlongBull"] = &pb.GameConfig{
+		Name:   "OddsAlongBull",
+		Value:  "4",
+		Remark: "百人牛牛的顺子牛翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsGourdBull"] = &pb.GameConfig{
+		Name:   "OddsGourdBull",
+		Value:  "5",
+		Remark: "百人牛牛的葫芦牛翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsSameFlowerBull"] = &pb.GameConfig{
+		Name:   "OddsSameFlowerBull",
+		Value:  "5",
+		Remark: "百人牛牛的同花牛翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsBoomBull"] = &pb.GameConfig{
+		Name:   "OddsBoomBull",
+		Value:  "5",
+		Remark: "百人牛牛的炸弹牛翻倍赔率",
+	}
+	HundredBullGameConfigTemp["OddsLittleBull"] = &pb.GameConfig{
+		Name:   "OddsLittleBull",
+		Value:  "5",
+		Remark: "百人牛牛的五小牛翻倍赔率",
+	}
+	HundredBullGameConfigTemp["WinLogNum"] = &pb.GameConfig{
+		Name:   "WinLogNum",
+		Value:  "60",
+		Remark: "百人牛牛保存的输赢记录局数",
+	}
+	HundredBullGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "1,3",
+		Remark: "百人牛牛的游戏类型",
+	}
+	HundredBullGameConfigTemp["UserBankerMoney"] = &pb.GameConfig{
+		Name:   "UserBankerMoney",
+		Value:  "100000",
+		Remark: "百人牛牛的玩家当庄所需最低金额",
+	}
+	HundredBullGameConfigTemp["UserBankerRound"] = &pb.GameConfig{
+		Name:   "UserBankerRound",
+		Value:  "5",
+		Remark: "百人牛牛的玩家当庄最多回合数",
+	}
+	HundredBullGameConfigTemp["DefaultBankerMoney"] = &pb.GameConfig{
+		Name:   "DefaultBankerMoney",
+		Value:  "1000000",
+		Remark: "百人牛牛的系统当庄默认的金钱数",
+	}
+	HundredBullGameConfigTemp["BankersLength"] = &pb.GameConfig{
+		Name:   "BankersLength",
+		Value:  "10",
+		Remark: "百人牛牛庄家申请列表人数限制",
+	}
+	HundredBullGameConfigTemp["Chips"] = &pb.GameConfig{
+		Name:   "Chips",
+		Value:  "1000,5000,10000,50000,100000,500000",
+		Remark: "百人牛牛的下注的筹码值",
+	}
+	HundredBullGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "百人牛牛的抽水，单位：%",
+	}
+}
+
+//红黑大战配置模版
+func redBlackConfigTemp() {
+	RedBlackGameConfigTemp = make(map[string]*pb.GameConfig)
+	RedBlackGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "100",
+		Remark: "红黑大战的房间最大容纳的玩家数量",
+	}
+	RedBlackGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "红黑大战的入场限制",
+	}
+	RedBlackGameConfigTemp["OutBalance"] = &pb.GameConfig{
+		Name:   "OutBalance",
+		Value:  "0",
+		Remark: "红黑大战的出场限制",
+	}
+	RedBlackGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "2",
+		Remark: "红黑大战的发牌阶段时长",
+	}
+	RedBlackGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "3",
+		Remark: "红黑大战的准备阶段时长",
+	}
+	RedBlackGameConfigTemp["BetTime"] = &pb.GameConfig{
+		Name:   "BetTime",
+		Value:  "15",
+		Remark: "红黑大战的下注阶段时长",
+	}
+	RedBlackGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "6",
+		Remark: "红黑大战的结算开牌阶段时长",
+	}
+	RedBlackGameConfigTemp["OddsRed"] = &pb.GameConfig{
+		Name:   "OddsRed",
+		Value:  "1",
+		Remark: "红黑大战的红方区域赔率",
+	}
+	RedBlackGameConfigTemp["OddsBlack"] = &pb.GameConfig{
+		Name:   "OddsBlack",
+		Value:  "1",
+		Remark: "红黑大战的黑方区域赔率",
+	}
+	RedBlackGameConfigTemp["OddsLuckPair"] = &pb.GameConfig{
+		Name:   "OddsLuckPair",
+		Value:  "1",
+		Remark: "红黑大战的幸运一击区域赔率，赢家牌型为对子（9到A）",
+	}
+	RedBlackGameConfigTemp["OddsLuckStraight"] = &pb.GameConfig{
+		Name:   "OddsLuckStraight",
+		Value:  "2",
+		Remark: "红黑大战的幸运一击区域赔率，赢家牌型为顺子",
+	}
+	RedBlackGameConfigTemp["OddsLuckFlush"] = &pb.GameConfig{
+		Name:   "OddsLuckFlush",
+		Value:  "3",
+		Remark: "红黑大战的幸运一击区域赔率，赢家牌型为金花",
+	}
+	RedBlackGameConfigTemp["OddsLuckStraightFlush"] = &pb.GameConfig{
+		Name:   "OddsLuckStraightFlush",
+		Value:  "10",
+		Remark: "红黑大战的幸运一击区域赔率，赢家牌型为顺金",
+	}
+	RedBlackGameConfigTemp["OddsLuckTrip"] = &pb.GameConfig{
+		Name:   "OddsLuckTrip",
+		Value:  "15",
+		Remark: "红黑大战的幸运一击区域赔率，赢家牌型为豹子",
+	}
+	RedBlackGameConfigTemp["WinLogNum"] = &pb.GameConfig{
+		Name:   "WinLogNum",
+		Value:  "60",
+		Remark: "红黑大战保存的输赢记录局数",
+	}
+	RedBlackGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "1,3",
+		Remark: "红黑大战的游戏类型",
+	}
+	RedBlackGameConfigTemp["UserBankerMoney"] = &pb.GameConfig{
+		Name:   "UserBankerMoney",
+		Value:  "100000",
+		Remark: "红黑大战的玩家当庄所需最低金额",
+	}
+	RedBlackGameConfigTemp["UserBankerRound"] = &pb.GameConfig{
+		Name:   "UserBankerRound",
+		Value:  "5",
+		Remark: "红黑大战的玩家当庄最多回合数",
+	}
+	RedBlackGameConfigTemp["DefaultBankerMoney"] = &pb.GameConfig{
+		Name:   "DefaultBankerMoney",
+		Value:  "1000000",
+		Remark: "红黑大战的系统当庄默认的金钱数",
+	}
+	RedBlackGameConfigTemp["BankersLength"] = &pb.GameConfig{
+		Name:   "BankersLength",
+		Value:  "10",
+		Remark: "红黑大战庄家申请列表人数限制",
+	}
+	RedBlackGameConfigTemp["Chips"] = &pb.GameConfig{
+		Name:   "Chips",
+		Value:  "1000,5000,10000,50000,100000,500000",
+		Remark: "红黑大战的下注的筹码值",
+	}
+	RedBlackGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "红黑大战的抽水，单位：%",
+	}
+}
+
+//奔驰宝马配置模版
+func benzBMWConfigTemp() {
+	BenzBMWGameConfigTemp = make(map[string]*pb.GameConfig)
+	BenzBMWGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "100",
+		Remark: "奔驰宝马的房间最大容纳的玩家数量",
+	}
+	BenzBMWGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "奔驰宝马的入场限制",
+	}
+	BenzBMWGameConfigTemp["OutBalance"] = &pb.GameConfig{
+		Name:   "OutBalance",
+		Value:  "0",
+		Remark: "奔驰宝马的出场限制",
+	}
+	BenzBMWGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "3",
+		Remark: "奔驰宝马的准备阶段时长",
+	}
+	BenzBMWGameConfigTemp["BetTime"] = &pb.GameConfig{
+		Name:   "BetTime",
+		Value:  "15",
+		Remark: "奔驰宝马的下注阶段时长",
+	}
+	BenzBMWGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "8",
+		Remark: "奔驰宝马的转盘开奖结算阶段时长",
+	}
+	BenzBMWGameConfigTemp["SlotOdds"] = &pb.GameConfig{
+		Name:   "SlotOdds",
+		Value:  "40,30,20,10,5,5,5,5",
+		Remark: "奔驰宝马各车标的赔率（含本金），逗号分隔，数量就是下注区域的数量",
+	}
+	BenzBMWGameConfigTemp["SlotWeights"] = &pb.GameConfig{
+		Name:   "SlotWeights",
+		Value:  "24,32,48,96,190,190,190,190",
+		Remark: "奔驰宝马各车标开出的权重，逗号分隔，顺序和数量与SlotOdds一致",
+	}
+	BenzBMWGameConfigTemp["WinLogNum"] = &pb.GameConfig{
+		Name:   "WinLogNum",
+		Value:  "60",
+		Remark: "奔驰宝马保存的开奖记录局数",
+	}
+	BenzBMWGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "1,3",
+		Remark: "奔驰宝马的游戏类型",
+	}
+	BenzBMWGameConfigTemp["UserBankerMoney"] = &pb.GameConfig{
+		Name:   "UserBankerMoney",
+		Value:  "10000",
+		Remark: "奔驰宝马的玩家当庄所需最低金额",
+	}
+	BenzBMWGameConfigTemp["UserBankerRound"] = &pb.GameConfig{
+		Name:   "UserBankerRound",
+		Value:  "5",
+		Remark: "奔驰宝马的玩家当庄最多回合数",
+	}
+	BenzBMWGameConfigTemp["DefaultBankerMoney"] = &pb.GameConfig{
+		Name:   "DefaultBankerMoney",
+		Value:  "100000",
+		Remark: "奔驰宝马的系统当庄默认的金钱数",
+	}
+	BenzBMWGameConfigTemp["BankersLength"] = &pb.GameConfig{
+		Name:   "BankersLength",
+		Value:  "10",
+		Remark: "奔驰宝马庄家申请列表人数限制",
+	}
+	BenzBMWGameConfigTemp["Chips"] = &pb.GameConfig{
+		Name:   "Chips",
+		Value:  "1000,5000,10000,50000,100000,500000",
+		Remark: "奔驰宝马的下注的筹码值",
+	}
+	BenzBMWGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "奔驰宝马的抽水，单位：%",
+	}
+}
+
+//宝石战争配置模版
+func gemWarsConfigTemp() {
+	GemWarsGameConfigTemp = make(map[string]*pb.GameConfig)
+	GemWarsGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "100",
+		Remark: "宝石战争的房间最大容纳的玩家数量",
+	}
+	GemWarsGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "宝石战争的入场限制",
+	}
+	GemWarsGameConfigTemp["OutBalance"] = &pb.GameConfig{
+		Name:   "OutBalance",
+		Value:  "0",
+		Remark: "宝石战争的出场限制",
+	}
+	GemWarsGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "3",
+		Remark: "宝石战争的准备阶段时长",
+	}
+	GemWarsGameConfigTemp["BetTime"] = &pb.GameConfig{
+		Name:   "BetTime",
+		Value:  "15",
+		Remark: "宝石战争的下注阶段时长",
+	}
+	GemWarsGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "8",
+		Remark: "宝石战争的开奖结算阶段时长",
+	}
+	GemWarsGameConfigTemp["GemOdds"] = &pb.GameConfig{
+		Name:   "GemOdds",
+		Value:  "4,4,4,4,12",
+		Remark: "宝石战争红、绿、黄、蓝、金五种宝石的赔率（含本金），逗号分隔",
+	}
+	GemWarsGameConfigTemp["GemWeights"] = &pb.GameConfig{
+		Name:   "GemWeights",
+		Value:  "23,23,23,23,8",
+		Remark: "宝石战争五种宝石开出的权重，逗号分隔，顺序与GemOdds一致",
+	}
+	GemWarsGameConfigTemp["NumOdds"] = &pb.GameConfig{
+		Name:   "NumOdds",
+		Value:  "7,7,7,7,7,7,7,7",
+		Remark: "宝石战争1到8号数字的赔率（含本金），逗号分隔",
+	}
+	GemWarsGameConfigTemp["NumWeights"] = &pb.GameConfig{
+		Name:   "NumWeights",
+		Value:  "1,1,1,1,1,1,1,1",
+		Remark: "宝石战争1到8号数字开出的权重，逗号分隔，顺序与NumOdds一致",
+	}
+	GemWarsGameConfigTemp["WinLogNum"] = &pb.GameConfig{
+		Name:   "WinLogNum",
+		Value:  "30",
+		Remark: "宝石战争保存的开奖记录局数",
+	}
+	GemWarsGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "1,3",
+		Remark: "宝石战争的游戏类型",
+	}
+	GemWarsGameConfigTemp["DefaultBankerMoney"] = &pb.GameConfig{
+		Name:   "DefaultBankerMoney",
+		Value:  "100000",
+		Remark: "宝石战争的系统当庄默认的金钱数",
+	}
+	GemWarsGameConfigTemp["Chips"] = &pb.GameConfig{
+		Name:   "Chips",
+		Value:  "1000,5000,10000,50000,100000,500000",
+		Remark: "宝石战争的下注的筹码值",
+	}
+	GemWarsGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "宝石战争的抽水，单位：%",
+	}
+}
+
+//拼牛牛配置模版
+func compareBullConfigTemp() {
+	CompareBullGameConfigTemp = make(map[string]*pb.GameConfig)
+	CompareBullGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "5",
+		Remark: "拼牛牛的房间最大人数",
+	}
+	CompareBullGameConfigTemp["PlayerStartNum"] = &pb.GameConfig{
+		Name:   "PlayerStartNum",
+		Value:  "2",
+		Remark: "拼牛牛开始游戏需要的最少准备人数",
+	}
+	CompareBullGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "拼牛牛的入场金额，准备阶段金额不足的玩家会被踢出",
+	}
+	CompareBullGameConfigTemp["BaseScore"] = &pb.GameConfig{
+		Name:   "BaseScore",
+		Value:  "100",
+		Remark: "拼牛牛的底分，输家赔给赢家底分乘以赢家牌型赔率",
+	}
+	CompareBullGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "15",
+		Remark: "拼牛牛的准备阶段时长，时间到了没有准备的玩家会被踢出",
+	}
+	CompareBullGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "3",
+		Remark: "拼牛牛的发牌阶段时长",
+	}
+	CompareBullGameConfigTemp["OpenCardTime"] = &pb.GameConfig{
+		Name:   "OpenCardTime",
+		Value:  "10",
+		Remark: "拼牛牛的开牌阶段时长，时间到了系统帮没开牌的玩家开牌",
+	}
+	CompareBullGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "5",
+		Remark: "拼牛牛的结算阶段时长",
+	}
+	CompareBullGameConfigTemp["OddsNone"] = &pb.GameConfig{
+		Name:   "OddsNone",
+		Value:  "1",
+		Remark: "拼牛牛无牛的赔率",
+	}
+	CompareBullGameConfigTemp["OddsBull1"] = &pb.GameConfig{
+		Name:   "OddsBull1",
+		Value:  "1",
+		Remark: "拼牛牛牛1的赔率",
+	}
+	CompareBullGameConfigTemp["OddsBull2"] = &pb.GameConfig{
+		Name:   "OddsBull2",
+		Value:  "1",
+		Remark: "拼牛牛牛2的赔率",
+	}
+	CompareBullGameConfigTemp["OddsBull3"] = &pb.GameConfig{
+		Name:   "OddsBull3",
+		Value:  "1",
+		Remark: "拼牛牛牛3的赔率",
+	}
+	CompareBullGameConfigTemp["OddsBull4"] = &pb.GameConfig{
+		Name:   "OddsBull4",
+		Value:  "1",
+		Remark: "拼牛牛牛4的赔率",
+	}
+	CompareBullGameConfigTemp["OddsBull5"] = &pb.GameConfig{
+		Name:   "OddsBull5",
+		Value:  "1",
+		Remark: "拼牛牛牛5的赔率",
+	}
+	CompareBullGameConfigTemp["OddsBull6"] = &pb.GameConfig{
+		Name:   "OddsBull6",
+		Value:  "1",
+		Remark: "拼牛牛牛6的赔率",
+	}
+	CompareBullGameConfigTemp["OddsBull7"] = &pb.GameConfig{
+		Name:   "OddsBull7",
+		Value:  "2",
+		Remark: "拼牛牛牛7的赔率",
+	}
+	CompareBullGameConfigTemp["OddsBull8"] = &pb.GameConfig{
+		Name:   "OddsBull8",
+		Value:  "2",
+		Remark: "拼牛牛牛8的赔率",
+	}
+	CompareBullGameConfigTemp["OddsBull9"] = &pb.GameConfig{
+		Name:   "OddsBull9",
+		Value:  "2",
+		Remark: "拼牛牛牛9的赔率",
+	}
+	CompareBullGameConfigTemp["OddsBullBull"] = &pb.GameConfig{
+		Name:   "OddsBullBull",
+		Value:  "3",
+		Remark: "拼牛牛牛牛的赔率",
+	}
+	CompareBullGameConfigTemp["OddsLittleBull"] = &pb.GameConfig{
+		Name:   "OddsLittleBull",
+		Value:  "5",
+		Remark: "拼牛牛五小牛的赔率",
+	}
+	CompareBullGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "2,4",
+		Remark: "拼牛牛的游戏类型",
+	}
+	CompareBullGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "拼牛牛赢家的抽水，单位：%",
+	}
+}
+
+//疯狂牛牛配置模版
+func crazyBullConfigTemp() {
+	CrazyBullGameConfigTemp = make(map[string]*pb.GameConfig)
+	CrazyBullGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "5",
+		Remark: "疯狂牛牛的房间最大人数",
+	}
+	CrazyBullGameConfigTemp["PlayerStartNum"] = &pb.GameConfig{
+		Name:   "PlayerStartNum",
+		Value:  "2",
+		Remark: "疯狂牛牛开始游戏需要的最少准备人数",
+	}
+	CrazyBullGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "5000",
+		Remark: "疯狂牛牛的入场金额，准备阶段金额不足的玩家会被踢出",
+	}
+	CrazyBullGameConfigTemp["BaseScore"] = &pb.GameConfig{
+		Name:   "BaseScore",
+		Value:  "100",
+		Remark: "疯狂牛牛的底分，输赢为底分*庄家倍数*闲家下注倍数*赢家牌型赔率",
+	}
+	CrazyBullGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "15",
+		Remark: "疯狂牛牛的准备阶段时长，时间到了没有准备的玩家会被踢出",
+	}
+	CrazyBullGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "3",
+		Remark: "疯狂牛牛的发牌阶段时长",
+	}
+	CrazyBullGameConfigTemp["RushVillageTime"] = &pb.GameConfig{
+		Name:   "RushVillageTime",
+		Value:  "8",
+		Remark: "疯狂牛牛的抢庄阶段时长，时间到了没有抢庄的玩家默认不抢",
+	}
+	CrazyBullGameConfigTemp["BetTime"] = &pb.GameConfig{
+		Name:   "BetTime",
+		Value:  "8",
+		Remark: "疯狂牛牛的下注阶段时长，时间到了没有下注的闲家默认下最小倍数",
+	}
+	CrazyBullGameConfigTemp["RubbingCardsTime"] = &pb.GameConfig{
+		Name:   "RubbingCardsTime",
+		Value:  "10",
+		Remark: "疯狂牛牛的搓牌阶段时长，时间到了系统帮没开牌的玩家开牌",
+	}
+	CrazyBullGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "5",
+		Remark: "疯狂牛牛的结算阶段时长",
+	}
+	CrazyBullGameConfigTemp["RushVillageOdds"] = &pb.GameConfig{
+		Name:   "RushVillageOdds",
+		Value:  "0,1,2,3,4",
+		Remark: "疯狂牛牛可以选择的抢庄倍数，0表示不抢",
+	}
+	CrazyBullGameConfigTemp["BetOdds"] = &pb.GameConfig{
+		Name:   "BetOdds",
+		Value:  "1,2,3,5",
+		Remark: "疯狂牛牛闲家可以选择的下注倍数",
+	}
+	CrazyBullGameConfigTemp["OddsNone"] = &pb.GameConfig{
+		Name:   "OddsNone",
+		Value:  "1",
+		Remark: "疯狂牛牛无牛的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsBull1"] = &pb.GameConfig{
+		Name:   "OddsBull1",
+		Value:  "1",
+		Remark: "疯狂牛牛牛1的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsBull2"] = &pb.GameConfig{
+		Name:   "OddsBull2",
+		Value:  "1",
+		Remark: "疯狂牛牛牛2的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsBull3"] = &pb.GameConfig{
+		Name:   "OddsBull3",
+		Value:  "1",
+		Remark: "疯狂牛牛牛3的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsBull4"] = &pb.GameConfig{
+		Name:   "OddsBull4",
+		Value:  "1",
+		Remark: "疯狂牛牛牛4的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsBull5"] = &pb.GameConfig{
+		Name:   "OddsBull5",
+		Value:  "1",
+		Remark: "疯狂牛牛牛5的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsBull6"] = &pb.GameConfig{
+		Name:   "OddsBull6",
+		Value:  "1",
+		Remark: "疯狂牛牛牛6的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsBull7"] = &pb.GameConfig{
+		Name:   "OddsBull7",
+		Value:  "2",
+		Remark: "疯狂牛牛牛7的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsBull8"] = &pb.GameConfig{
+		Name:   "OddsBull8",
+		Value:  "2",
+		Remark: "疯狂牛牛牛8的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsBull9"] = &pb.GameConfig{
+		Name:   "OddsBull9",
+		Value:  "2",
+		Remark: "疯狂牛牛牛9的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsBullBull"] = &pb.GameConfig{
+		Name:   "OddsBullBull",
+		Value:  "3",
+		Remark: "疯狂牛牛牛牛的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsStreakyBull"] = &pb.GameConfig{
+		Name:   "OddsStreakyBull",
+		Value:  "4",
+		Remark: "疯狂牛牛五花牛的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsAlongBull"] = &pb.GameConfig{
+		Name:   "OddsAlongBull",
+		Value:  "4",
+		Remark: "疯狂牛牛顺子牛的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsGourdBull"] = &pb.GameConfig{
+		Name:   "OddsGourdBull",
+		Value:  "5",
+		Remark: "疯狂牛牛葫芦牛的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsSameFlowerBull"] = &pb.GameConfig{
+		Name:   "OddsSameFlowerBull",
+		Value:  "5",
+		Remark: "疯狂牛牛同花牛的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsBoomBull"] = &pb.GameConfig{
+		Name:   "OddsBoomBull",
+		Value:  "5",
+		Remark: "疯狂牛牛炸弹牛的赔率",
+	}
+	CrazyBullGameConfigTemp["OddsLittleBull"] = &pb.GameConfig{
+		Name:   "OddsLittleBull",
+		Value:  "5",
+		Remark: "疯狂牛牛五小牛的赔率",
+	}
+	CrazyBullGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "2,4",
+		Remark: "疯狂牛牛的游戏类型",
+	}
+	CrazyBullGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "疯狂牛牛赢家的抽水，单位：%",
+	}
+}
+
+//炸金花配置模版
+func jinhuaConfigTemp() {
+	JinhuaGameConfigTemp = make(map[string]*pb.GameConfig)
+	JinhuaGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "5",
+		Remark: "炸金花房间最大人数",
+	}
+	JinhuaGameConfigTemp["PlayerStartNum"] = &pb.GameConfig{
+		Name:   "PlayerStartNum",
+		Value:  "2",
+		Remark: "炸金花开始游戏需要的准备人数",
+	}
+	JinhuaGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "2000",
+		Remark: "炸金花进入房间和继续游戏需要的最低金额",
+	}
+	JinhuaGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "15",
+		Remark: "炸金花准备阶段的时间，单位：秒",
+	}
+	JinhuaGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "3",
+		Remark: "炸金花发牌阶段的时间，单位：秒",
+	}
+	JinhuaGameConfigTemp["OperateTime"] = &pb.GameConfig{
+		Name:   "OperateTime",
+		Value:  "15",
+		Remark: "炸金花每个玩家的操作时间，超时按弃牌处理，单位：秒",
+	}
+	JinhuaGameConfigTemp["OperateIntervalMilli"] = &pb.GameConfig{
+		Name:   "OperateIntervalMilli",
+		Value:  "500",
+		Remark: "炸金花玩家操作后到下一个玩家操作的间隔，单位：毫秒",
+	}
+	JinhuaGameConfigTemp["CompareIntervalMilli"] = &pb.GameConfig{
+		Name:   "CompareIntervalMilli",
+		Value:  "2500",
+		Remark: "炸金花比牌后到下一个玩家操作的间隔，用于播放比牌动画，单位：毫秒",
+	}
+	JinhuaGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "5",
+		Remark: "炸金花结算阶段的时间，单位：秒",
+	}
+	JinhuaGameConfigTemp["RaiseValue"] = &pb.GameConfig{
+		Name:   "RaiseValue",
+		Value:  "100,200,500,1000,2000",
+		Remark: "炸金花加注等级1到最大等级对应的闷牌下注值，看牌后翻倍，第一个值是底注",
+	}
+	JinhuaGameConfigTemp["JinhuaMode"] = &pb.GameConfig{
+		Name:   "JinhuaMode",
+		Value:  "1",
+		Remark: "炸金花模式，1：普通模式，2：激情模式（可以直接看牌和比牌，最大轮数为PassionMaxRound）",
+	}
+	JinhuaGameConfigTemp["BankerType"] = &pb.GameConfig{
+		Name:   "BankerType",
+		Value:  "1",
+		Remark: "炸金花庄家类型，1：赢家坐庄，2：轮流坐庄，3：随机坐庄",
+	}
+	JinhuaGameConfigTemp["MaxRound"] = &pb.GameConfig{
+		Name:   "MaxRound",
+		Value:  "20",
+		Remark: "炸金花普通模式每个玩家的最大轮数，达到后系统比牌",
+	}
+	JinhuaGameConfigTemp["PassionMaxRound"] = &pb.GameConfig{
+		Name:   "PassionMaxRound",
+		Value:  "10",
+		Remark: "炸金花激情模式每个玩家的最大轮数，达到后系统比牌",
+	}
+	JinhuaGameConfigTemp["MustBlindRound"] = &pb.GameConfig{
+		Name:   "MustBlindRound",
+		Value:  "1",
+		Remark: "炸金花普通模式前几轮必须闷牌",
+	}
+	JinhuaGameConfigTemp["CompareMinRound"] = &pb.GameConfig{
+		Name:   "CompareMinRound",
+		Value:  "2",
+		Remark: "炸金花普通模式第几轮开始可以比牌",
+	}
+	JinhuaGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "2,4",
+		Remark: "炸金花的游戏类型",
+	}
+	JinhuaGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "炸金花赢家的抽水，单位：%",
+	}
+}
+
+//跑得快配置模版
+func runFastConfigTemp() {
+	RunFastGameConfigTemp = make(map[string]*pb.GameConfig)
+	RunFastGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "3",
+		Remark: "跑得快房间最大人数",
+	}
+	RunFastGameConfigTemp["PlayerStartNum"] = &pb.GameConfig{
+		Name:   "PlayerStartNum",
+		Value:  "3",
+		Remark: "跑得快开始游戏需要的准备人数",
+	}
+	RunFastGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "跑得快进入房间和继续游戏需要的最低金额",
+	}
+	RunFastGameConfigTemp["BaseScore"] = &pb.GameConfig{
+		Name:   "BaseScore",
+		Value:  "10",
+		Remark: "跑得快底分，输家按剩余牌数乘底分输给赢家",
+	}
+	RunFastGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "15",
+		Remark: "跑得快准备阶段的时间，单位：秒",
+	}
+	RunFastGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "3",
+		Remark: "跑得快发牌阶段的时间，单位：秒",
+	}
+	RunFastGameConfigTemp["OperateTime"] = &pb.GameConfig{
+		Name:   "OperateTime",
+		Value:  "15",
+		Remark: "跑得快每个玩家的出牌时间，超时由系统自动出牌，单位：秒",
+	}
+	RunFastGameConfigTemp["AutoOperateTime"] = &pb.GameConfig{
+		Name:   "AutoOperateTime",
+		Value:  "1",
+		Remark: "跑得快断线或者已经退出的玩家由系统自动出牌的等待时间，单位：秒",
+	}
+	RunFastGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "5",
+		Remark: "跑得快结算阶段的时间，单位：秒",
+	}
+	RunFastGameConfigTemp["SpringOdds"] = &pb.GameConfig{
+		Name:   "SpringOdds",
+		Value:  "2",
+		Remark: "跑得快春天（一张牌都没出）输的倍数",
+	}
+	RunFastGameConfigTemp["AntiSpringOdds"] = &pb.GameConfig{
+		Name:   "AntiSpringOdds",
+		Value:  "2",
+		Remark: "跑得快反春（先出牌后再也没有出牌）输的倍数",
+	}
+	RunFastGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "2,4",
+		Remark: "跑得快的游戏类型",
+	}
+	RunFastGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "跑得快赢家的抽水，单位：%",
+	}
+}
+
+//十三水配置模版
+func shiSanShuiConfigTemp() {
+	ShiSanShuiGameConfigTemp = make(map[string]*pb.GameConfig)
+	ShiSanShuiGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "4",
+		Remark: "十三水房间最大人数",
+	}
+	ShiSanShuiGameConfigTemp["PlayerStartNum"] = &pb.GameConfig{
+		Name:   "PlayerStartNum",
+		Value:  "2",
+		Remark: "十三水开始游戏需要的准备人数",
+	}
+	ShiSanShuiGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "2000",
+		Remark: "十三水进入房间和继续游戏需要的最低金额",
+	}
+	ShiSanShuiGameConfigTemp["BaseScore"] = &pb.GameConfig{
+		Name:   "BaseScore",
+		Value:  "10",
+		Remark: "十三水底分，每一水的金额",
+	}
+	ShiSanShuiGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "15",
+		Remark: "十三水准备阶段的时间，单位：秒",
+	}
+	ShiSanShuiGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "3",
+		Remark: "十三水发牌阶段的时间，单位：秒",
+	}
+	ShiSanShuiGameConfigTemp["PlaceTime"] = &pb.GameConfig{
+		Name:   "PlaceTime",
+		Value:  "60",
+		Remark: "十三水摆牌阶段的时间，超时由系统摆牌，单位：秒",
+	}
+	ShiSanShuiGameConfigTemp["SuggestNum"] = &pb.GameConfig{
+		Name:   "SuggestNum",
+		Value:  "5",
+		Remark: "十三水推荐摆牌方案的最大数量",
+	}
+	ShiSanShuiGameConfigTemp["CompareStepTime"] = &pb.GameConfig{
+		Name:   "CompareStepTime",
+		Value:  "2",
+		Remark: "十三水比牌时每一步展示的时间，单位：秒",
+	}
+	ShiSanShuiGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "5",
+		Remark: "十三水结算阶段的时间，单位：秒",
+	}
+	ShiSanShuiGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "2,4",
+		Remark: "十三水的游戏类型",
+	}
+	ShiSanShuiGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "十三水赢家的抽水，单位：%",
+	}
+}
+
+//血战麻将配置模版
+func xueZhanMahjongConfigTemp() {
+	XueZhanMahjongGameConfigTemp = make(map[string]*pb.GameConfig)
+	XueZhanMahjongGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "4",
+		Remark: "血战麻将房间最大人数",
+	}
+	XueZhanMahjongGameConfigTemp["PlayerStartNum"] = &pb.GameConfig{
+		Name:   "PlayerStartNum",
+		Value:  "4",
+		Remark: "血战麻将开始游戏需要的准备人数",
+	}
+	XueZhanMahjongGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "2000",
+		Remark: "血战麻将进入房间和继续游戏需要的最低金额",
+	}
+	XueZhanMahjongGameConfigTemp["BaseScore"] = &pb.GameConfig{
+		Name:   "BaseScore",
+		Value:  "10",
+		Remark: "血战麻将底分，胡牌分数为底分乘以2的番数次方",
+	}
+	XueZhanMahjongGameConfigTemp["MaxFan"] = &pb.GameConfig{
+		Name:   "MaxFan",
+		Value:  "4",
+		Remark: "血战麻将封顶番数",
+	}
+	XueZhanMahjongGameConfigTemp["ZhiKongScore"] = &pb.GameConfig{
+		Name:   "ZhiKongScore",
+		Value:  "2",
+		Remark: "血战麻将直杠由点杠的玩家给的分数，单位：底分",
+	}
+	XueZhanMahjongGameConfigTemp["BaKongScore"] = &pb.GameConfig{
+		Name:   "BaKongScore",
+		Value:  "1",
+		Remark: "血战麻将巴杠每个没胡的玩家给的分数，单位：底分",
+	}
+	XueZhanMahjongGameConfigTemp["AnKongScore"] = &pb.GameConfig{
+		Name:   "AnKongScore",
+		Value:  "2",
+		Remark: "血战麻将暗杠每个没胡的玩家给的分数，单位：底分",
+	}
+	XueZhanMahjongGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "15",
+		Remark: "血战麻将准备阶段的时间，单位：秒",
+	}
+	XueZhanMahjongGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "3",
+		Remark: "血战麻将发牌阶段的时间，单位：秒",
+	}
+	XueZhanMahjongGameConfigTemp["IsChangeThreeCards"] = &pb.GameConfig{
+		Name:   "IsChangeThreeCards",
+		Value:  "1",
+		Remark: "血战麻将是否换三张，1：换三张，0：不换",
+	}
+	XueZhanMahjongGameConfigTemp["ChangeThreeCardsTime"] = &pb.GameConfig{
+		Name:   "ChangeThreeCardsTime",
+		Value:  "15",
+		Remark: "血战麻将换三张阶段的时间，超时由系统选牌，单位：秒",
+	}
+	XueZhanMahjongGameConfigTemp["BlankSuitTime"] = &pb.GameConfig{
+		Name:   "BlankSuitTime",
+		Value:  "10",
+		Remark: "血战麻将定缺阶段的时间，超时使用推荐的花色，单位：秒",
+	}
+	XueZhanMahjongGameConfigTemp["OutputTime"] = &pb.GameConfig{
+		Name:   "OutputTime",
+		Value:  "15",
+		Remark: "血战麻将玩家出牌的时间，超时由系统出牌，单位：秒",
+	}
+	XueZhanMahjongGameConfigTemp["OperateTime"] = &pb.GameConfig{
+		Name:   "OperateTime",
+		Value:  "10",
+		Remark: "血战麻将玩家响应碰杠胡的时间，超时能胡就胡否则过牌，单位：秒",
+	}
+	XueZhanMahjongGameConfigTemp["AutoOperateTime"] = &pb.GameConfig{
+		Name:   "AutoOperateTime",
+		Value:  "1",
+		Remark: "血战麻将断线或者已经退出的玩家由系统操作的等待时间，单位：秒",
+	}
+	XueZhanMahjongGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "8",
+		Remark: "血战麻将结算阶段的时间，单位：秒",
+	}
+	XueZhanMahjongGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "1,4",
+		Remark: "血战麻将的游戏类型",
+	}
+	XueZhanMahjongGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "血战麻将赢家的抽水，单位：%",
+	}
+}
+
+//临沧麻将配置模版
+func linCangMahjongConfigTemp() {
+	LinCangMahjongGameConfigTemp = make(map[string]*pb.GameConfig)
+	LinCangMahjongGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "4",
+		Remark: "临沧麻将房间最大人数",
+	}
+	LinCangMahjongGameConfigTemp["PlayerStartNum"] = &pb.GameConfig{
+		Name:   "PlayerStartNum",
+		Value:  "4",
+		Remark: "临沧麻将开始游戏需要的准备人数",
+	}
+	LinCangMahjongGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "2000",
+		Remark: "临沧麻将进入房间和继续游戏需要的最低金额",
+	}
+	LinCangMahjongGameConfigTemp["BaseScore"] = &pb.GameConfig{
+		Name:   "BaseScore",
+		Value:  "10",
+		Remark: "临沧麻将底分，胡牌分数为底分乘以胡牌倍数",
+	}
+	LinCangMahjongGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "15",
+		Remark: "临沧麻将准备阶段的时间，单位：秒",
+	}
+	LinCangMahjongGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "3",
+		Remark: "临沧麻将发牌阶段的时间，单位：秒",
+	}
+	LinCangMahjongGameConfigTemp["OutputTime"] = &pb.GameConfig{
+		Name:   "OutputTime",
+		Value:  "15",
+		Remark: "临沧麻将玩家出牌的时间，超时由系统出牌，单位：秒",
+	}
+	LinCangMahjongGameConfigTemp["OperateTime"] = &pb.GameConfig{
+		Name:   "OperateTime",
+		Value:  "10",
+		Remark: "临沧麻将玩家响应碰杠胡的时间，超时能胡就胡否则过牌，单位：秒",
+	}
+	LinCangMahjongGameConfigTemp["AutoOperateTime"] = &pb.GameConfig{
+		Name:   "AutoOperateTime",
+		Value:  "1",
+		Remark: "临沧麻将断线或者已经退出的玩家由系统操作的等待时间，单位：秒",
+	}
+	LinCangMahjongGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "8",
+		Remark: "临沧麻将结算阶段的时间，单位：秒",
+	}
+	LinCangMahjongGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "1,4",
+		Remark: "临沧麻将的游戏类型",
+	}
+	LinCangMahjongGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "临沧麻将赢家的抽水，单位：%",
+	}
+	LinCangMahjongGameConfigTemp["ZhiKongScore"] = &pb.GameConfig{
+		Name:   "ZhiKongScore",
+		Value:  "3",
+		Remark: "临沧麻将直杠的分数，点杠的玩家付底分乘以这个倍数",
+	}
+	LinCangMahjongGameConfigTemp["BaKongScore"] = &pb.GameConfig{
+		Name:   "BaKongScore",
+		Value:  "1",
+		Remark: "临沧麻将巴杠的分数，其他玩家每人付底分乘以这个倍数",
+	}
+	LinCangMahjongGameConfigTemp["AnKongScore"] = &pb.GameConfig{
+		Name:   "AnKongScore",
+		Value:  "2",
+		Remark: "临沧麻将暗杠的分数，其他玩家每人付底分乘以这个倍数",
+	}
+	LinCangMahjongGameConfigTemp["FlowerScore"] = &pb.GameConfig{
+		Name:   "FlowerScore",
+		Value:  "1",
+		Remark: "临沧麻将胡牌时每张花牌增加的倍数",
+	}
+	LinCangMahjongGameConfigTemp["MaxMultiple"] = &pb.GameConfig{
+		Name:   "MaxMultiple",
+		Value:  "64",
+		Remark: "临沧麻将胡牌的封顶倍数，0为不封顶",
+	}
+	LinCangMahjongGameConfigTemp["WinTypeScore"] = &pb.GameConfig{
+		Name:   "WinTypeScore",
+		Value:  "Ping:1,NoPongOrKong:2,PongPong:2,FullFlush:4,SevenPair:4,Seabed:2,ThirteenMao:13,MiddleLastCard:2,SideLastCard:2,BadCards:2,FivePlumBlossom:4,MahjongWinEnumSevenStars:8,MahjongWinEnumTenOldMen:8,DrawSky:10,DiscardLand:10",
+		Remark: "临沧麻将胡牌类型的倍数表，格式为 类型:倍数,类型:倍数",
+	}
+	LinCangMahjongGameConfigTemp["WinSourceScore"] = &pb.GameConfig{
+		Name:   "WinSourceScore",
+		Value:  "Draw:2,Discard:1,GrabKong:2,DrawAfterKong:2,DiscardAfterKong:2,SevenStars:2,TenOldMen:2",
+		Remark: "临沧麻将胡牌来源的倍数表，格式为 来源:倍数,来源:倍数",
+	}
+	LinCangMahjongGameConfigTemp["BPlus"] = &pb.GameConfig{
+		Name:   "BPlus",
+		Value:  "1",
+		Remark: "临沧麻将胡牌倍数相加还是相乘，1：相加，0：相乘",
+	}
+	LinCangMahjongGameConfigTemp["BNoPongKong"] = &pb.GameConfig{
+		Name:   "BNoPongKong",
+		Value:  "1",
+		Remark: "临沧麻将是否开启门清，1：开启，0：关闭",
+	}
+	LinCangMahjongGameConfigTemp["BFivePlumBlossom"] = &pb.GameConfig{
+		Name:   "BFivePlumBlossom",
+		Value:  "1",
+		Remark: "临沧麻将是否开启五梅花，1：开启，0：关闭",
+	}
+	LinCangMahjongGameConfigTemp["BSevenStars"] = &pb.GameConfig{
+		Name:   "BSevenStars",
+		Value:  "1",
+		Remark: "临沧麻将是否开启七星，1：开启，0：关闭",
+	}
+	LinCangMahjongGameConfigTemp["BTenOldMen"] = &pb.GameConfig{
+		Name:   "BTenOldMen",
+		Value:  "1",
+		Remark: "临沧麻将是否开启十老头，1：开启，0：关闭",
+	}
+	LinCangMahjongGameConfigTemp["BDrawSky"] = &pb.GameConfig{
+		Name:   "BDrawSky",
+		Value:  "1",
+		Remark: "临沧麻将是否开启天胡，1：开启，0：关闭",
+	}
+	LinCangMahjongGameConfigTemp["BDiscardLand"] = &pb.GameConfig{
+		Name:   "BDiscardLand",
+		Value:  "1",
+		Remark: "临沧麻将是否开启地胡，1：开启，0：关闭",
+	}
+	LinCangMahjongGameConfigTemp["BHardBad"] = &pb.GameConfig{
+		Name:   "BHardBad",
+		Value:  "1",
+		Remark: "临沧麻将是否开启硬烂(烂牌可以胡)，1：开启，0：关闭",
+	}
+	LinCangMahjongGameConfigTemp["BMiddleLast"] = &pb.GameConfig{
+		Name:   "BMiddleLast",
+		Value:  "1",
+		Remark: "临沧麻将是否开启卡绝张，1：开启，0：关闭",
+	}
+	LinCangMahjongGameConfigTemp["BSideLast"] = &pb.GameConfig{
+		Name:   "BSideLast",
+		Value:  "1",
+		Remark: "临沧麻将是否开启边绝张，1：开启，0：关闭",
+	}
+	LinCangMahjongGameConfigTemp["BSevenStarsAndTenOldMenFirst"] = &pb.GameConfig{
+		Name:   "BSevenStarsAndTenOldMenFirst",
+		Value:  "1",
+		Remark: "临沧麻将一炮多响时七星、十老头是否优先胡牌，1：开启，0：关闭",
+	}
+}
+
+//杠花麻将配置模版
+func gangHuaMahjongConfigTemp() {
+	GangHuaMahjongGameConfigTemp = make(map[string]*pb.GameConfig)
+	GangHuaMahjongGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "4",
+		Remark: "杠花麻将房间最大人数",
+	}
+	GangHuaMahjongGameConfigTemp["PlayerStartNum"] = &pb.GameConfig{
+		Name:   "PlayerStartNum",
+		Value:  "4",
+		Remark: "杠花麻将开始游戏需要的准备人数",
+	}
+	GangHuaMahjongGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "2000",
+		Remark: "杠花麻将进入房间和继续游戏需要的最低金额",
+	}
+	GangHuaMahjongGameConfigTemp["BaseScore"] = &pb.GameConfig{
+		Name:   "BaseScore",
+		Value:  "10",
+		Remark: "杠花麻将底分，胡牌和杠牌分数为底分乘以倍数",
+	}
+	GangHuaMahjongGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "15",
+		Remark: "杠花麻将准备阶段的时间，单位：秒",
+	}
+	GangHuaMahjongGameConfigTemp["BankChangeTime"] = &pb.GameConfig{
+		Name:   "BankChangeTime",
+		Value:  "8",
+		Remark: "杠花麻将定庄阶段连庄的庄家选择是否下庄的时间，超时默认不下庄，单位：秒",
+	}
+	GangHuaMahjongGameConfigTemp["MaxBankerTimes"] = &pb.GameConfig{
+		Name:   "MaxBankerTimes",
+		Value:  "0",
+		Remark: "杠花麻将庄家最多连庄的次数，达到后强制下庄，0：不限制",
+	}
+	GangHuaMahjongGameConfigTemp["BetTime"] = &pb.GameConfig{
+		Name:   "BetTime",
+		Value:  "10",
+		Remark: "杠花麻将买点阶段的时间，超时默认不买，单位：秒",
+	}
+	GangHuaMahjongGameConfigTemp["MaxPoints"] = &pb.GameConfig{
+		Name:   "MaxPoints",
+		Value:  "2",
+		Remark: "杠花麻将闲家最多可以买的点数",
+	}
+	GangHuaMahjongGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "3",
+		Remark: "杠花麻将发牌阶段的时间，单位：秒",
+	}
+	GangHuaMahjongGameConfigTemp["OutputTime"] = &pb.GameConfig{
+		Name:   "OutputTime",
+		Value:  "15",
+		Remark: "杠花麻将出牌的时间，超时系统自动出牌，单位：秒",
+	}
+	GangHuaMahjongGameConfigTemp["OperateTime"] = &pb.GameConfig{
+		Name:   "OperateTime",
+		Value:  "10",
+		Remark: "杠花麻将碰杠胡和选明牌的时间，超时系统自动操作，单位：秒",
+	}
+	GangHuaMahjongGameConfigTemp["AutoOperateTime"] = &pb.GameConfig{
+		Name:   "AutoOperateTime",
+		Value:  "1",
+		Remark: "杠花麻将断线或者已经退出的玩家系统自动操作的等待时间，单位：秒",
+	}
+	GangHuaMahjongGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "8",
+		Remark: "杠花麻将结算阶段的时间，单位：秒",
+	}
+	GangHuaMahjongGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "1,4",
+		Remark: "杠花麻将的游戏类型",
+	}
+	GangHuaMahjongGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "杠花麻将赢家抽水比例，单位：%",
+	}
+	GangHuaMahjongGameConfigTemp["ZhiKongScore"] = &pb.GameConfig{
+		Name:   "ZhiKongScore",
+		Value:  "1",
+		Remark: "杠花麻将直杠的分数，底分的倍数，其他玩家每人付一份",
+	}
+	GangHuaMahjongGameConfigTemp["BuKongScore"] = &pb.GameConfig{
+		Name:   "BuKongScore",
+		Value:  "1",
+		Remark: "杠花麻将补杠的分数，底分的倍数，其他玩家每人付一份",
+	}
+	GangHuaMahjongGameConfigTemp["AnKongScore"] = &pb.GameConfig{
+		Name:   "AnKongScore",
+		Value:  "2",
+		Remark: "杠花麻将暗杠的分数，底分的倍数，其他玩家每人付一份",
+	}
+	GangHuaMahjongGameConfigTemp["WinTypeScore"] = &pb.GameConfig{
+		Name:   "WinTypeScore",
+		Value:  "normalWin:1,WinningWithAllPairedTiles:2,SevenPairs:4,SevenPairsOfDragonClawBack:8",
+		Remark: "杠花麻将胡牌类型的倍数，格式为 类型:倍数",
+	}
+	GangHuaMahjongGameConfigTemp["WinKindScore"] = &pb.GameConfig{
+		Name:   "WinKindScore",
+		Value:  "NaturalWin:8,EarthlyHand:8,AfterAGang:2,AfterTwoGang:4,AfterThreeGang:8,UniqueKaZhang:2,AllOfOneSuit:4",
+		Remark: "杠花麻将胡牌番种的倍数，多个番种相乘，格式为 番种:倍数",
+	}
+}
+
+//连连看配置模版
+func linkUpConfigTemp() {
+	LinkUpGameConfigTemp = make(map[string]*pb.GameConfig)
+	LinkUpGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "4",
+		Remark: "连连看房间最大人数",
+	}
+	LinkUpGameConfigTemp["PlayerStartNum"] = &pb.GameConfig{
+		Name:   "PlayerStartNum",
+		Value:  "2",
+		Remark: "连连看开始游戏需要的准备人数",
+	}
+	LinkUpGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "连连看进入房间和继续游戏需要的最低金额",
+	}
+	LinkUpGameConfigTemp["BaseScore"] = &pb.GameConfig{
+		Name:   "BaseScore",
+		Value:  "100",
+		Remark: "连连看底分，每个玩家开局出底分放进奖池",
+	}
+	LinkUpGameConfigTemp["RankRates"] = &pb.GameConfig{
+		Name:   "RankRates",
+		Value:  "70,30,0,0",
+		Remark: "连连看奖池按名次分配的比例，逗号分隔，依次为第一名、第二名……",
+	}
+	LinkUpGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "15",
+		Remark: "连连看准备阶段的时间，单位：秒",
+	}
+	LinkUpGameConfigTemp["PlayTime"] = &pb.GameConfig{
+		Name:   "PlayTime",
+		Value:  "180",
+		Remark: "连连看比赛消除的时间，单位：秒",
+	}
+	LinkUpGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "5",
+		Remark: "连连看结算阶段的时间，单位：秒",
+	}
+	LinkUpGameConfigTemp["BoardRow"] = &pb.GameConfig{
+		Name:   "BoardRow",
+		Value:  "8",
+		Remark: "连连看图的行数，行数乘列数必须是偶数",
+	}
+	LinkUpGameConfigTemp["BoardColumn"] = &pb.GameConfig{
+		Name:   "BoardColumn",
+		Value:  "12",
+		Remark: "连连看图的列数，行数乘列数必须是偶数",
+	}
+	LinkUpGameConfigTemp["IconKindNum"] = &pb.GameConfig{
+		Name:   "IconKindNum",
+		Value:  "16",
+		Remark: "连连看一张图上使用的图标种类数，最多24种",
+	}
+	LinkUpGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "2,4",
+		Remark: "连连看的游戏类型",
+	}
+	LinkUpGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "连连看赢家的抽水，单位：%",
+	}
+}
+
+//连线游戏配置模版
+func lineGameConfigTemp() {
+	LineGameGameConfigTemp = make(map[string]*pb.GameConfig)
+	LineGameGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "连线游戏进入的最低金额",
+	}
+	LineGameGameConfigTemp["WagerList"] = &pb.GameConfig{
+		Name:   "WagerList",
+		Value:  "100,200,500,1000,2000,5000",
+		Remark: "连线游戏可以选择的下注金额，逗号隔开",
+	}
+	LineGameGameConfigTemp["BonusRatio"] = &pb.GameConfig{
+		Name:   "BonusRatio",
+		Value:  "2",
+		Remark: "连线游戏每次下注放入奖池的比例，单位：%",
+	}
+	LineGameGameConfigTemp["JackpotRatio"] = &pb.GameConfig{
+		Name:   "JackpotRatio",
+		Value:  "50",
+		Remark: "连线游戏中奖池时获得奖池的比例，单位：%",
+	}
+	LineGameGameConfigTemp["ControlTimes"] = &pb.GameConfig{
+		Name:   "ControlTimes",
+		Value:  "5",
+		Remark: "连线游戏血池控制时最多重新开奖的次数",
+	}
+	LineGameGameConfigTemp["MaxFreeSpinNum"] = &pb.GameConfig{
+		Name:   "MaxFreeSpinNum",
+		Value:  "50",
+		Remark: "连线游戏一次下注最多进行的免费游戏次数",
+	}
+	LineGameGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "3",
+		Remark: "连线游戏的游戏类型",
+	}
+}
+
+//消消乐配置模版
+func clearJoyConfigTemp() {
+	ClearJoyGameConfigTemp = make(map[string]*pb.GameConfig)
+	ClearJoyGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "消消乐进入的最低金额",
+	}
+	ClearJoyGameConfigTemp["WagerList"] = &pb.GameConfig{
+		Name:   "WagerList",
+		Value:  "100,200,500,1000,2000,5000",
+		Remark: "消消乐可以选择的下注金额，逗号隔开",
+	}
+	ClearJoyGameConfigTemp["ImageWidth"] = &pb.GameConfig{
+		Name:   "ImageWidth",
+		Value:  "6",
+		Remark: "消消乐图的宽度",
+	}
+	ClearJoyGameConfigTemp["ImageHeight"] = &pb.GameConfig{
+		Name:   "ImageHeight",
+		Value:  "6",
+		Remark: "消消乐图的高度",
+	}
+	ClearJoyGameConfigTemp["ChainMultiples"] = &pb.GameConfig{
+		Name:   "ChainMultiples",
+		Value:  "1,2,3,5,8",
+		Remark: "消消乐依次每次连消的倍数，超过后都用最后一个倍数",
+	}
+	ClearJoyGameConfigTemp["MaxChainNum"] = &pb.GameConfig{
+		Name:   "MaxChainNum",
+		Value:  "20",
+		Remark: "消消乐一次下注最多连消的次数",
+	}
+	ClearJoyGameConfigTemp["ControlTimes"] = &pb.GameConfig{
+		Name:   "ControlTimes",
+		Value:  "5",
+		Remark: "消消乐血池控制时最多重新开奖的次数",
+	}
+	ClearJoyGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "3",
+		Remark: "消消乐的游戏类型",
+	}
+}
+
+//打旋配置模版
+func daXuanConfigTemp() {
+	DaXuanGameConfigTemp = make(map[string]*pb.GameConfig)
+	DaXuanGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "1000",
+		Remark: "打旋进入房间需要的最低金额",
+	}
+	DaXuanGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "20",
+		Remark: "打旋房间最大人数，包括8个座位和旁观的玩家",
+	}
+	DaXuanGameConfigTemp["PlayerStartNum"] = &pb.GameConfig{
+		Name:   "PlayerStartNum",
+		Value:  "2",
+		Remark: "打旋开始游戏需要的准备人数",
+	}
+	DaXuanGameConfigTemp["MinUpSeat"] = &pb.GameConfig{
+		Name:   "MinUpSeat",
+		Value:  "1000",
+		Remark: "打旋上座和补充钵钵时一次最少带入的金额",
+	}
+	DaXuanGameConfigTemp["MaxUpSeat"] = &pb.GameConfig{
+		Name:   "MaxUpSeat",
+		Value:  "100000",
+		Remark: "打旋上座和补充钵钵时一次最多带入的金额",
+	}
+	DaXuanGameConfigTemp["PenaltyQuota"] = &pb.GameConfig{
+		Name:   "PenaltyQuota",
+		Value:  "1000",
+		Remark: "打旋房间解散时赢钱超过这个额度的玩家需要计算惩罚",
+	}
+	DaXuanGameConfigTemp["PenaltyRatio"] = &pb.GameConfig{
+		Name:   "PenaltyRatio",
+		Value:  "10",
+		Remark: "打旋惩罚的比例，按玩家的总输赢计算，罚款分给输家，单位：%",
+	}
+	DaXuanGameConfigTemp["PenaltyTime"] = &pb.GameConfig{
+		Name:   "PenaltyTime",
+		Value:  "300",
+		Remark: "打旋离座或者离开房间超过这个时间的赢家受到惩罚，单位：秒",
+	}
+	DaXuanGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "5",
+		Remark: "打旋准备阶段的时间，单位：秒",
+	}
+	DaXuanGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "2",
+		Remark: "打旋每次发牌的动画时间，单位：秒",
+	}
+	DaXuanGameConfigTemp["OperateTime"] = &pb.GameConfig{
+		Name:   "OperateTime",
+		Value:  "15",
+		Remark: "打旋玩家说话的时间，超时能休就休否则丢牌，单位：秒",
+	}
+	DaXuanGameConfigTemp["OperateIntervalMilli"] = &pb.GameConfig{
+		Name:   "OperateIntervalMilli",
+		Value:  "800",
+		Remark: "打旋玩家说话后到下一个玩家说话的间隔，单位：毫秒",
+	}
+	DaXuanGameConfigTemp["PartTime"] = &pb.GameConfig{
+		Name:   "PartTime",
+		Value:  "15",
+		Remark: "打旋分牌阶段的时间，超时由系统分牌，单位：秒",
+	}
+	DaXuanGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "6",
+		Remark: "打旋结算阶段的时间，单位：秒",
+	}
+	DaXuanGameConfigTemp["Mongo"] = &pb.GameConfig{
+		Name:   "Mongo",
+		Value:  "10",
+		Remark: "打旋每局每个玩家下到皮池的芒果",
+	}
+	DaXuanGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "打旋赢家的抽水，单位：%",
+	}
+	DaXuanGameConfigTemp["BonusRatio"] = &pb.GameConfig{
+		Name:   "BonusRatio",
+		Value:  "20",
+		Remark: "打旋抽水进入奖池的比例，单位：%",
+	}
+	DaXuanGameConfigTemp["PrizeRatio"] = &pb.GameConfig{
+		Name:   "PrizeRatio",
+		Value:  "TianHuang:30,DuoHuang:10,ZhaDan:5,DuoDuo:1",
+		Remark: "打旋奖励牌型从奖池中领奖的比例，格式为 牌型:比例，单位：%",
+	}
+	DaXuanGameConfigTemp["SanHuaTen"] = &pb.GameConfig{
+		Name:   "SanHuaTen",
+		Value:  "5",
+		Remark: "打旋三花十其他玩家每人给的芒果倍数",
+	}
+	DaXuanGameConfigTemp["SanHuaSix"] = &pb.GameConfig{
+		Name:   "SanHuaSix",
+		Value:  "3",
+		Remark: "打旋三花六其他玩家每人给的芒果倍数",
+	}
+	DaXuanGameConfigTemp["KeepSeatTime"] = &pb.GameConfig{
+		Name:   "KeepSeatTime",
+		Value:  "180",
+		Remark: "打旋保座的最长时间，超时自动下座，单位：秒",
+	}
+	DaXuanGameConfigTemp["RoomTime"] = &pb.GameConfig{
+		Name:   "RoomTime",
+		Value:  "60",
+		Remark: "打旋房间的存在时间，到时间后在准备阶段解散，单位：分钟",
+	}
+	DaXuanGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "2,4",
+		Remark: "打旋的游戏类型",
+	}
+}
+
+//双连配置模版
+func doubleLinkedConfigTemp() {
+	DoubleLinkedGameConfigTemp = make(map[string]*pb.GameConfig)
+	DoubleLinkedGameConfigTemp["MaxPlayer"] = &pb.GameConfig{
+		Name:   "MaxPlayer",
+		Value:  "6",
+		Remark: "双连的房间最大人数",
+	}
+	DoubleLinkedGameConfigTemp["PlayerStartNum"] = &pb.GameConfig{
+		Name:   "PlayerStartNum",
+		Value:  "2",
+		Remark: "双连开始游戏需要的最少准备人数",
+	}
+	DoubleLinkedGameConfigTemp["EnterBalance"] = &pb.GameConfig{
+		Name:   "EnterBalance",
+		Value:  "5000",
+		Remark: "双连的入场金额，准备阶段金额不足的玩家会被踢出",
+	}
+	DoubleLinkedGameConfigTemp["BaseScore"] = &pb.GameConfig{
+		Name:   "BaseScore",
+		Value:  "100",
+		Remark: "双连的底分，输赢为底分*庄家倍数*闲家下注倍数*赢家牌型赔率",
+	}
+	DoubleLinkedGameConfigTemp["ReadyTime"] = &pb.GameConfig{
+		Name:   "ReadyTime",
+		Value:  "15",
+		Remark: "双连的准备阶段时长，时间到了没有准备的玩家会被踢出",
+	}
+	DoubleLinkedGameConfigTemp["RushVillageTime"] = &pb.GameConfig{
+		Name:   "RushVillageTime",
+		Value:  "8",
+		Remark: "双连的抢庄阶段时长，时间到了没有抢庄的玩家默认不抢",
+	}
+	DoubleLinkedGameConfigTemp["BetTime"] = &pb.GameConfig{
+		Name:   "BetTime",
+		Value:  "8",
+		Remark: "双连的下注阶段时长，时间到了没有下注的闲家默认下最小倍数",
+	}
+	DoubleLinkedGameConfigTemp["DealTime"] = &pb.GameConfig{
+		Name:   "DealTime",
+		Value:  "3",
+		Remark: "双连的发牌阶段时长",
+	}
+	DoubleLinkedGameConfigTemp["RubbingCardsTime"] = &pb.GameConfig{
+		Name:   "RubbingCardsTime",
+		Value:  "10",
+		Remark: "双连的搓牌阶段时长，时间到了系统帮没开牌的玩家开牌",
+	}
+	DoubleLinkedGameConfigTemp["LaoTime"] = &pb.GameConfig{
+		Name:   "LaoTime",
+		Value:  "10",
+		Remark: "双连闲家的捞牌阶段时长，时间到了没有选择的闲家能炸就炸，否则不捞",
+	}
+	DoubleLinkedGameConfigTemp["ZhuangLaoTime"] = &pb.GameConfig{
+		Name:   "ZhuangLaoTime",
+		Value:  "15",
+		Remark: "双连庄家的比牌和捞牌阶段时长，时间到了庄家默认不捞",
+	}
+	DoubleLinkedGameConfigTemp["SettleTime"] = &pb.GameConfig{
+		Name:   "SettleTime",
+		Value:  "5",
+		Remark: "双连的结算阶段时长",
+	}
+	DoubleLinkedGameConfigTemp["RushVillageOdds"] = &pb.GameConfig{
+		Name:   "RushVillageOdds",
+		Value:  "0,1,2,3,4",
+		Remark: "双连可以选择的抢庄倍数，0表示不抢",
+	}
+	DoubleLinkedGameConfigTemp["BetOdds"] = &pb.GameConfig{
+		Name:   "BetOdds",
+		Value:  "1,2,3,5",
+		Remark: "双连闲家可以选择的下注倍数",
+	}
+	DoubleLinkedGameConfigTemp["OddsNone"] = &pb.GameConfig{
+		Name:   "OddsNone",
+		Value:  "1",
+		Remark: "双连普通点数的赔率",
+	}
+	DoubleLinkedGameConfigTemp["OddsDoubleLink"] = &pb.GameConfig{
+		Name:   "OddsDoubleLink",
+		Value:  "2",
+		Remark: "双连双联（两张同花色）的赔率",
+	}
+	DoubleLinkedGameConfigTemp["OddsTrio"] = &pb.GameConfig{
+		Name:   "OddsTrio",
+		Value:  "3",
+		Remark: "双连三联（三张同花色）的赔率",
+	}
+	DoubleLinkedGameConfigTemp["OddsPair"] = &pb.GameConfig{
+		Name:   "OddsPair",
+		Value:  "2",
+		Remark: "双连对子的赔率",
+	}
+	DoubleLinkedGameConfigTemp["Odds3GaLa"] = &pb.GameConfig{
+		Name:   "Odds3GaLa",
+		Value:  "5",
+		Remark: "双连三嘎啦（三张相同）的赔率",
+	}
+	DoubleLinkedGameConfigTemp["OddsTuoLaJi"] = &pb.GameConfig{
+		Name:   "OddsTuoLaJi",
+		Value:  "4",
+		Remark: "双连杂色拖拉机（顺子）的赔率",
+	}
+	DoubleLinkedGameConfigTemp["OddsQingTuoLaJi"] = &pb.GameConfig{
+		Name:   "OddsQingTuoLaJi",
+		Value:  "5",
+		Remark: "双连清拖拉机（同花顺）的赔率",
+	}
+	DoubleLinkedGameConfigTemp["Odds3P"] = &pb.GameConfig{
+		Name:   "Odds3P",
+		Value:  "3",
+		Remark: "双连3P（三张JQK）的赔率",
+	}
+	DoubleLinkedGameConfigTemp["CateGory"] = &pb.GameConfig{
+		Name:   "CateGory",
+		Value:  "2,4",
+		Remark: "双连的游戏类型",
+	}
+	DoubleLinkedGameConfigTemp["Commission"] = &pb.GameConfig{
+		Name:   "Commission",
+		Value:  "5",
+		Remark: "双连赢家的抽水，单位：%",
+	}
+}
@@ -0,0 +1,199 @@
+package common
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+
+	pb "gameServer-demo/src/grpc"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/any"
+)
+
+// GamePluginDoKind Do协议中一种操作的路由方式
+type GamePluginDoKind int32
+
+const (
+	// GamePluginDoLogic 普通操作，路由到玩家所在的线路，由driver转发给逻辑组件的方法
+	GamePluginDoLogic GamePluginDoKind = iota
+	// GamePluginDoJoinRoom 加入房间，通过GameJoinRoomJudge选择线路，driver调用GameDriverJoinRoom
+	GamePluginDoJoinRoom
+	// GamePluginDoExitRoom 退出房间，driver调用GameDriverExitRoom，对局中不允许退出时转给ExitInGameComponent
+	GamePluginDoExitRoom
+	// GamePluginDoReject 这个游戏不支持的操作，直接返回RejectCode
+	GamePluginDoReject
+	// GamePluginDoCreateRoom 创建自建房，随机选择线路，driver调用GameDriverCreatePlayerRoom
+	GamePluginDoCreateRoom
+	// GamePluginDoRoomMaster 自建房房主操作，请求内容可以是开始、踢人或者解散，driver调用GameDriverPlayerRoomMasterDo
+	GamePluginDoRoomMaster
+)
+
+// GamePluginDo 游戏Do协议中一种操作类型的描述
+// Request和Reply只作为消息类型的模板，每次请求都会新建一个同类型的消息
+type GamePluginDo struct {
+	Kind       GamePluginDoKind
+	Request    proto.Message
+	Reply      proto.Message
+	Component  string
+	Method     string
+	RejectCode pb.ErrorCode
+}
+
+// GameRobotActionI 机器人行为接口，和Robot/action.BaseActionI的定义一致
+type GameRobotActionI interface {
+	Action(playerInfo *pb.PlayerInfo, roomInfo *pb.RoomInfo, actionConfig *pb.RobotActionConfig, extraInfo *pb.MessageExtroInfo) (bool, bool, int64)
+}
+
+// GamePlugin 游戏注册描述
+// 注册后会生成 游戏名+"Route" 和 游戏名+"Driver" 两个组件，游戏名就是GameType的名字
+// 新游戏只需要写各个状态的逻辑组件，再在包的init里调用RegisterGamePlugin声明描述即可
+type GamePlugin struct {
+	GameType pb.GameType
+	// CnName 游戏中文名，用于日志
+	CnName string
+	// ConfigTemp 游戏配置模板，driver启动时保存到内存中
+	ConfigTemp map[string]*pb.GameConfig
+	// DoList Do协议的DoType和操作描述的映射
+	DoList map[int32]*GamePluginDo
+	// StateComponents 房间状态和驱动组件的映射，布局配置中driver配置了对应状态时以布局配置为准
+	StateComponents map[pb.RoomState]string
+	// ExitInGameComponent 对局中退出房间时调用这个组件的RequestExitInGame，为空则不允许对局中退出
+	ExitInGameComponent string
+	// RobotJoinAction 机器人加入房间的行为，开放这个行为时会初始化下面的机器人配置模板
+	RobotJoinAction             pb.RobotAction
+	RobotActions                map[pb.RobotAction]GameRobotActionI
+	RobotActionConfigNames      []string
+	RobotActionGroupConfigNames []string
+}
+
+// GamePluginMap 游戏名和注册描述的映射
+var GamePluginMap = make(map[string]*GamePlugin)
+
+// RegisterGamePlugin 注册一个游戏，需要在包的init里调用，重复注册或者描述不完整时直接panic
+func RegisterGamePlugin(plugin *GamePlugin) {
+	if plugin == nil || plugin.GameType == pb.GameType_None {
+		panic("common RegisterGamePlugin plugin or GameType is nil")
+	}
+	name := plugin.GetName()
+	if _, ok := GamePluginMap[name]; ok {
+		panic("common RegisterGamePlugin repeat register " + name)
+	}
+	for doType, oneDo := range plugin.DoList {
+		if oneDo == nil {
+			panic("common RegisterGamePlugin " + name + " do is nil")
+		}
+		if oneDo.Kind == GamePluginDoReject {
+			continue
+		}
+		if oneDo.Request == nil || oneDo.Reply == nil {
+			panic("common RegisterGamePlugin " + name + " do request or reply is nil " + strconv.Itoa(int(doType)))
+		}
+		if oneDo.Kind == GamePluginDoLogic && (oneDo.Component == "" || oneDo.Method == "") {
+			panic("common RegisterGamePlugin " + name + " do component or method is empty " + oneDo.Method)
+		}
+	}
+	GamePluginMap[name] = plugin
+}
+
+// GetGamePlugin 根据游戏名获取注册描述
+func GetGamePlugin(name string) *GamePlugin {
+	return GamePluginMap[name]
+}
+
+// GetGamePluginByRobotJoinAction 根据机器人加入房间的行为获取注册描述
+func GetGamePluginByRobotJoinAction(robotAction pb.RobotAction) *GamePlugin {
+	for _, plugin := range GamePluginMap {
+		if plugin.RobotJoinAction != 0 && plugin.RobotJoinAction == robotAction {
+			return plugin
+		}
+	}
+	return nil
+}
+
+// GetName 游戏名，也是生成的组件名前缀
+func (plugin *GamePlugin) GetName() string {
+	return plugin.GameType.String()
+}
+
+// GetRouteName 生成的route组件名
+func (plugin *GamePlugin) GetRouteName() string {
+	return plugin.GetName() + "Route"
+}
+
+// GetDriverName 生成的driver组件名（不含线路）
+func (plugin *GamePlugin) GetDriverName() string {
+	return plugin.GetName() + "Driver"
+}
+
+// GetServerNumCfgName driver线路数量的全局配置名
+func (plugin *GamePlugin) GetServerNumCfgName() string {
+	return plugin.GetName() + "ServerNum"
+}
+
+// GetMaxRoomNumCfgName 单条线路最大房间数的全局配置名
+func (plugin *GamePlugin) GetMaxRoomNumCfgName() string {
+	return plugin.GetName() + "MaxRoomNumOneServer"
+}
+
+// HasPlayerRoom 这个游戏是否开放了自建房
+func (plugin *GamePlugin) HasPlayerRoom() bool {
+	for _, oneDo := range plugin.DoList {
+		if oneDo.Kind == GamePluginDoCreateRoom {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPlayerRoomCfgNames 自建房总局数和房卡数的全局配置名，CustomRoomConfig按这些配置检测总局数
+func (plugin *GamePlugin) GetPlayerRoomCfgNames() []string {
+	return []string{
+		plugin.GetName() + "RoomPlayNum",
+		plugin.GetName() + "MasterPayNum",
+		plugin.GetName() + "AAPayNum",
+	}
+}
+
+// GetStateComponent 获取驱动某个房间状态的组件名，优先使用布局配置
+func (plugin *GamePlugin) GetStateComponent(roomState pb.RoomState, config *OneComponentConfig) string {
+	if config != nil {
+		componentName := (*config)[pb.RoomState_name[int32(roomState)]]
+		if componentName != "" {
+			return componentName
+		}
+	}
+	return plugin.StateComponents[roomState]
+}
+
+// NewRequest 新建一个这种操作的请求消息
+func (oneDo *GamePluginDo) NewRequest() proto.Message {
+	return newGamePluginMessage(oneDo.Request)
+}
+
+// NewReply 新建一个这种操作的回复消息
+func (oneDo *GamePluginDo) NewReply() proto.Message {
+	return newGamePluginMessage(oneDo.Reply)
+}
+
+// newGamePluginMessage 根据模板新建一个同类型的空消息
+func newGamePluginMessage(temp proto.Message) proto.Message {
+	return reflect.New(reflect.TypeOf(temp).Elem()).Interface().(proto.Message)
+}
+
+// GetGameDoRequestInfo 获取各游戏Do协议中的操作类型和操作内容
+// 各游戏的DoRequest的DoType是不同的枚举类型，这里通过反射统一获取
+func GetGameDoRequestInfo(request proto.Message) (int32, *any.Any, error) {
+	if request == nil {
+		return 0, nil, errors.New("common GetGameDoRequestInfo request is nil")
+	}
+	requestValue := reflect.ValueOf(request)
+	getDoType := requestValue.MethodByName("GetDoType")
+	getContent := requestValue.MethodByName("GetDoMessageContent")
+	if !getDoType.IsValid() || !getContent.IsValid() {
+		return 0, nil, errors.New("common GetGameDoRequestInfo request is not a DoRequest " + proto.MessageName(request))
+	}
+	doType := int32(getDoType.Call(nil)[0].Int())
+	content, _ := getContent.Call(nil)[0].Interface().(*any.Any)
+	return doType, content, nil
+}
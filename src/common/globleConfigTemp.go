@@ -20,6 +20,191 @@ func init() {
 		Value:  "100",
 		Remark: "推筒子在一个线路上的房间最大数量",
 	}
+	GlobleConfigTemp["DragonTigerFightServerNum"] = &pb.GlobalConfig{
+		Name:   "DragonTigerFightServerNum",
+		Value:  "1",
+		Remark: "龙虎斗的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["DragonTigerFightMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "DragonTigerFightMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "龙虎斗在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["BaccaratServerNum"] = &pb.GlobalConfig{
+		Name:   "BaccaratServerNum",
+		Value:  "1",
+		Remark: "百家乐的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["BaccaratMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "BaccaratMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "百家乐在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["HundredBullServerNum"] = &pb.GlobalConfig{
+		Name:   "HundredBullServerNum",
+		Value:  "1",
+		Remark: "百人牛牛的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["HundredBullMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "HundredBullMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "百人牛牛在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["RedBlackServerNum"] = &pb.GlobalConfig{
+		Name:   "RedBlackServerNum",
+		Value:  "1",
+		Remark: "红黑大战的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["RedBlackMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "RedBlackMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "红黑大战在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["BenzBMWServerNum"] = &pb.GlobalConfig{
+		Name:   "BenzBMWServerNum",
+		Value:  "1",
+		Remark: "奔驰宝马的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["BenzBMWMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "BenzBMWMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "奔驰宝马在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["GemWarsServerNum"] = &pb.GlobalConfig{
+		Name:   "GemWarsServerNum",
+		Value:  "1",
+		Remark: "宝石战争的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["GemWarsMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "GemWarsMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "宝石战争在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["CompareBullServerNum"] = &pb.GlobalConfig{
+		Name:   "CompareBullServerNum",
+		Value:  "1",
+		Remark: "拼牛牛的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["CompareBullMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "CompareBullMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "拼牛牛在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["CrazyBullServerNum"] = &pb.GlobalConfig{
+		Name:   "CrazyBullServerNum",
+		Value:  "1",
+		Remark: "疯狂牛牛的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["CrazyBullMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "CrazyBullMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "疯狂牛牛在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["JinhuaServerNum"] = &pb.GlobalConfig{
+		Name:   "JinhuaServerNum",
+		Value:  "1",
+		Remark: "炸金花的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["JinhuaMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "JinhuaMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "炸金花在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["RunFastServerNum"] = &pb.GlobalConfig{
+		Name:   "RunFastServerNum",
+		Value:  "1",
+		Remark: "跑得快的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["RunFastMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "RunFastMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "跑得快在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["ShiSanShuiServerNum"] = &pb.GlobalConfig{
+		Name:   "ShiSanShuiServerNum",
+		Value:  "1",
+		Remark: "十三水的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["ShiSanShuiMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "ShiSanShuiMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "十三水在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["XueZhanMahjongServerNum"] = &pb.GlobalConfig{
+		Name:   "XueZhanMahjongServerNum",
+		Value:  "1",
+		Remark: "血战麻将的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["XueZhanMahjongMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "XueZhanMahjongMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "血战麻将在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["LinCangMahjongServerNum"] = &pb.GlobalConfig{
+		Name:   "LinCangMahjongServerNum",
+		Value:  "1",
+		Remark: "临沧麻将的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["LinCangMahjongMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "LinCangMahjongMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "临沧麻将在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["GangHuaMahjongServerNum"] = &pb.GlobalConfig{
+		Name:   "GangHuaMahjongServerNum",
+		Value:  "1",
+		Remark: "杠花麻将的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["GangHuaMahjongMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "GangHuaMahjongMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "杠花麻将在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["LinkUpServerNum"] = &pb.GlobalConfig{
+		Name:   "LinkUpServerNum",
+		Value:  "1",
+		Remark: "连连看的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["LinkUpMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "LinkUpMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "连连看在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["DaXuanServerNum"] = &pb.GlobalConfig{
+		Name:   "DaXuanServerNum",
+		Value:  "1",
+		Remark: "打旋的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["DaXuanMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "DaXuanMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "打旋在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["DoubleLinkedServerNum"] = &pb.GlobalConfig{
+		Name:   "DoubleLinkedServerNum",
+		Value:  "1",
+		Remark: "双连的房间线路数量,新增线路必须先创建服务后再配置",
+	}
+	GlobleConfigTemp["DoubleLinkedMaxRoomNumOneServer"] = &pb.GlobalConfig{
+		Name:   "DoubleLinkedMaxRoomNumOneServer",
+		Value:  "100",
+		Remark: "双连在一个线路上的房间最大数量",
+	}
+	GlobleConfigTemp["DoubleLinkedRoomPlayNum"] = &pb.GlobalConfig{
+		Name:   "DoubleLinkedRoomPlayNum",
+		Value:  "8,16,24",
+		Remark: "双连自建房可选的总局数,和房主支付,AA支付的房卡数一一对应",
+	}
+	GlobleConfigTemp["DoubleLinkedMasterPayNum"] = &pb.GlobalConfig{
+		Name:   "DoubleLinkedMasterPayNum",
+		Value:  "4000,8000,12000",
+		Remark: "双连自建房房主支付时房主需要支付的金额,和总局数一一对应",
+	}
+	GlobleConfigTemp["DoubleLinkedAAPayNum"] = &pb.GlobalConfig{
+		Name:   "DoubleLinkedAAPayNum",
+		Value:  "1000,2000,3000",
+		Remark: "双连自建房AA支付时每个玩家需要支付的金额,和总局数一一对应",
+	}
 	GlobleConfigTemp["GetRecordDay"] = &pb.GlobalConfig{
 		Name:   "GetRecordDay",
 		Value:  "7",
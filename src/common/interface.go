@@ -48,6 +48,9 @@ type PlayerMsgSubFunc func(msg []byte) error
 // ReportMsgSubFunc 报表消费MQ中推送的消息回调
 type ReportMsgSubFunc func(msg []byte) error
 
+// ReportMsgBatchSubFunc 报表批量消费MQ中推送的消息回调，返回错误时整批消息重新入队
+type ReportMsgBatchSubFunc func(msgList []*ReportMsgDelivery) error
+
 // AchievementMsgSubFunc 业绩消费MQ中推送的消息回调
 type AchievementMsgSubFunc func(msg []byte) error
 
@@ -68,6 +71,7 @@ type MQI interface {
 	SendReport(msg []byte) error
 	SendAchievement(msg []byte) error
 	BindReport(uuid string, subCallBack ReportMsgSubFunc) error
+	BindReportBatch(uuid string, batchSize int, subCallBack ReportMsgBatchSubFunc) error
 	UnBindReport(uuid string) error
 
 	//发送玩家开始玩游戏通知
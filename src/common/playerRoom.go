@@ -0,0 +1,362 @@
+package common
+
+import (
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// IsPlayerRoom 房间是否是玩家自建房
+func IsPlayerRoom(roomInfo *pb.RoomInfo) bool {
+	return roomInfo.GetRoomType() == pb.RoomType_RoomType_PlayerRoom
+}
+
+// IsPlayerRoomStarted 自建房是否已经开始，房主点开始并扣除房卡后才算开始
+func IsPlayerRoomStarted(roomInfo *pb.RoomInfo) bool {
+	return IsPlayerRoom(roomInfo) && roomInfo.GetPayStatus() == pb.PayStatus_PayStatus_Success
+}
+
+// GameDriverCreatePlayerRoom 创建自建房通用接口，只在房卡模式下开放
+// 按请求中的配置创建房间并生成房间码，创建成功后房主直接加入房间，其他玩家通过房间码加入
+// 参数：创建房间的请求，服务器所能容纳的最大房间数的配置名，房间管理器，rpc附加消息
+// 返回：房间码信息，rpc错误信息
+func GameDriverCreatePlayerRoom(createRoomRequest *pb.GameCreateRoomRequest, maxRoomConfigName string, rm *RoomManager, extroInfo *pb.MessageExtroInfo) (*pb.GameCreateRoomReply, *pb.ErrorMessage) {
+	uid := extroInfo.GetUserId()
+	if uid == "" {
+		LogError("GameDriverCreatePlayerRoom uuid == nil")
+		return nil, GetGrpcErrorMessage(pb.ErrorCode_UserNotLogin, "")
+	}
+	if GameMode != pb.GameMode_GameMode_Card {
+		LogError("GameDriverCreatePlayerRoom not card mode", GameMode)
+		return nil, GetGrpcErrorMessage(pb.ErrorCode_InvalidRequest, "")
+	}
+	if createRoomRequest.GetGameType() != pb.GameType_None && createRoomRequest.GetGameType() != rm.gameType {
+		LogError("GameDriverCreatePlayerRoom GameType err", createRoomRequest.GetGameType(), rm.gameType)
+		return nil, GetGrpcErrorMessage(pb.ErrorCode_GameTypeError, "")
+	}
+
+	loadPlayerRequest := &pb.LoadPlayerRequest{}
+	loadPlayerRequest.Uuid = uid
+	loadPlayerReply := &pb.LoadPlayerReply{}
+	msgErr := Router.Call("PlayerInfo", "LoadPlayer", loadPlayerRequest, loadPlayerReply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	playerInfo := loadPlayerReply.GetPlayerInfo()
+	if playerInfo.GetRoomId() != "" {
+		LogError("GameDriverCreatePlayerRoom user in other game", uid)
+		return nil, GetGrpcErrorMessage(pb.ErrorCode_PlayerInOtherGame, "")
+	}
+
+	maxRoomNumStr := Configer.GetGlobal(maxRoomConfigName).GetValue()
+	maxRoomNum, err := strconv.Atoi(maxRoomNumStr)
+	if err != nil {
+		LogError("GameDriverCreatePlayerRoom Atoi(maxRoomNumStr) has err", maxRoomNumStr)
+		return nil, GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	joinRoomRequest := &pb.GameJoinRoomRequest{}
+	joinRoomRequest.GameType = rm.gameType
+	joinRoomRequest.GameScene = createRoomRequest.GetGameScene()
+	roomCodeInfo := &pb.RoomCodeInfo{}
+	msgErr = rm.CreateRoomWithFunc(maxRoomNum, joinRoomRequest, func(roomInfo *pb.RoomInfo) *pb.ErrorMessage {
+		msgErr := initPlayerRoom(roomInfo, uid, createRoomRequest.GetConfig())
+		if msgErr != nil {
+			return msgErr
+		}
+		// 房间码最后生成，之后不会再有失败的情况
+		roomCodeInfo.RoomUUID = roomInfo.GetUuid()
+		roomCodeInfo.ServerIndex = roomInfo.GetGameServerIndex()
+		roomCodeInfo.GameType = roomInfo.GetGameType()
+		roomCodeInfo.RoomType = roomInfo.GetRoomType()
+		roomCode, msgErr := CreateRoomCodeInfo(roomCodeInfo)
+		if msgErr != nil {
+			return msgErr
+		}
+		roomInfo.RoomCode = roomCode
+		return nil
+	})
+	if msgErr != nil {
+		LogError("GameDriverCreatePlayerRoom CreateRoomWithFunc has err", msgErr)
+		return nil, msgErr
+	}
+
+	// 房主加入房间，加入失败的话房间也没有意义了，直接删除
+	joinRoomRequest.RoomUUID = roomCodeInfo.GetRoomUUID()
+	joinRoomRequest.RoomCode = roomCodeInfo.GetRoomCode()
+	roomInfo, msgErr := rm.JoinRoom(playerInfo, joinRoomRequest, extroInfo)
+	if msgErr != nil || roomInfo == nil {
+		LogError("GameDriverCreatePlayerRoom master join room fail", uid, msgErr)
+		err := rm.DeleteRoom(roomCodeInfo.GetRoomUUID(), true)
+		if err != nil {
+			LogError("GameDriverCreatePlayerRoom DeleteRoom has err", err)
+		}
+		if msgErr == nil {
+			msgErr = GetGrpcErrorMessage(pb.ErrorCode_NotJoinRoom, "")
+		}
+		return nil, msgErr
+	}
+
+	reply := &pb.GameCreateRoomReply{}
+	reply.GameType = rm.gameType
+	reply.RoomCodeInfo = roomCodeInfo
+	return reply, nil
+}
+
+// initPlayerRoom 初始化自建房的房间信息，检测并应用房主自定义的配置
+func initPlayerRoom(roomInfo *pb.RoomInfo, masterUUID string, customConfig []*pb.GameConfig) *pb.ErrorMessage {
+	roomInfo.RoomType = pb.RoomType_RoomType_PlayerRoom
+	roomInfo.RoomMasterUUID = masterUUID
+	roomInfo.StartPlayerUUID = masterUUID
+	roomInfo.PayStatus = pb.PayStatus_PayStatus_None
+	// 房间配置是场次配置的引用，自定义之前先拷贝一份，避免改到场次配置
+	roomConfig := make([]*pb.GameConfig, 0, len(roomInfo.GetConfig()))
+	for _, oneConfig := range roomInfo.GetConfig() {
+		roomConfig = append(roomConfig, proto.Clone(oneConfig).(*pb.GameConfig))
+	}
+	roomInfo.Config = roomConfig
+
+	for _, oneConfig := range customConfig {
+		msgErr := CustomRoomConfig(roomInfo, oneConfig.GetName(), oneConfig.GetValue())
+		if msgErr != nil {
+			return msgErr
+		}
+	}
+	// 总局数和支付方式必须由房主选择
+	if roomInfo.GetRoomAllPlayNum() <= 0 {
+		LogError("initPlayerRoom RoomAllPlayNum not set", masterUUID)
+		return GetGrpcErrorMessage(pb.ErrorCode_RoomAllPlayNumConfigError, "")
+	}
+	if roomInfo.GetPayType() != pb.PayType_PayType_MasterPay && roomInfo.GetPayType() != pb.PayType_PayType_AA {
+		LogError("initPlayerRoom PayType not set", masterUUID, roomInfo.GetPayType())
+		return GetGrpcErrorMessage(pb.ErrorCode_PayTypeConfigError, "")
+	}
+	maxPlayer, err := strconv.Atoi(GetRoomConfig(roomInfo, "MaxPlayer"))
+	if err != nil {
+		LogError("initPlayerRoom MaxPlayer has err", err)
+		return GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerStartNum, err := strconv.Atoi(GetRoomConfig(roomInfo, "PlayerStartNum"))
+	if err != nil {
+		LogError("initPlayerRoom PlayerStartNum has err", err)
+		return GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	if playerStartNum > maxPlayer {
+		LogError("initPlayerRoom PlayerStartNum more than MaxPlayer", playerStartNum, maxPlayer)
+		return GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+	}
+	return nil
+}
+
+// GameDriverPlayerRoomMasterDo 自建房房主操作通用接口
+// 根据请求的消息类型区分操作：GameStartRoomRequest开始，KickPlayerRequest踢人，DissolveLeagueRoomRequest解散
+// 参数：房主操作的请求，房间管理器，rpc附加消息
+// 返回：rpc错误信息
+func GameDriverPlayerRoomMasterDo(request proto.Message, rm *RoomManager, extroInfo *pb.MessageExtroInfo) *pb.ErrorMessage {
+	uid := extroInfo.GetUserId()
+	if uid == "" {
+		LogError("GameDriverPlayerRoomMasterDo uuid == nil")
+		return GetGrpcErrorMessage(pb.ErrorCode_UserNotLogin, "")
+	}
+
+	loadPlayerRequest := &pb.LoadPlayerRequest{}
+	loadPlayerRequest.Uuid = uid
+	loadPlayerReply := &pb.LoadPlayerReply{}
+	msgErr := Router.Call("PlayerInfo", "LoadPlayer", loadPlayerRequest, loadPlayerReply, extroInfo)
+	if msgErr != nil {
+		return msgErr
+	}
+
+	_, msgErr = rm.Do(loadPlayerReply.GetPlayerInfo(), func(roomInfo *pb.RoomInfo) (*pb.RoomInfo, proto.Message, *pb.ErrorMessage) {
+		if !IsPlayerRoom(roomInfo) {
+			return nil, nil, GetGrpcErrorMessage(pb.ErrorCode_InvalidRequest, "")
+		}
+		if roomInfo.GetRoomMasterUUID() != uid {
+			return nil, nil, GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+		}
+		var msgErr *pb.ErrorMessage
+		switch realRequest := request.(type) {
+		case *pb.GameStartRoomRequest:
+			msgErr = startPlayerRoom(roomInfo)
+		case *pb.KickPlayerRequest:
+			msgErr = kickPlayerRoomPlayer(roomInfo, realRequest.GetUuid())
+		case *pb.DissolveLeagueRoomRequest:
+			if realRequest.GetRoomUUID() != "" && realRequest.GetRoomUUID() != roomInfo.GetUuid() {
+				return nil, nil, GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+			}
+			msgErr = dissolvePlayerRoom(roomInfo)
+		default:
+			LogError("GameDriverPlayerRoomMasterDo unknown request", proto.MessageName(request))
+			return nil, nil, GetGrpcErrorMessage(pb.ErrorCode_InvalidRequest, "")
+		}
+		if msgErr != nil {
+			return nil, nil, msgErr
+		}
+		return roomInfo, nil, nil
+	})
+	return msgErr
+}
+
+// startPlayerRoom 房主开始自建房，人数够开始人数后扣除房卡，所有玩家直接准备
+// 房主支付只扣房主的，AA支付每个玩家都扣，先检查所有人都够再扣
+func startPlayerRoom(roomInfo *pb.RoomInfo) *pb.ErrorMessage {
+	if IsPlayerRoomStarted(roomInfo) || roomInfo.GetRoomPlayNum() != 1 ||
+		roomInfo.GetCurRoomState() != pb.RoomState_RoomStateReady || roomInfo.GetNextRoomState() == pb.RoomState_RoomStateReady {
+		return GetGrpcErrorMessage(pb.ErrorCode_GameAlreadyStart, "")
+	}
+	playerStartNum, err := strconv.Atoi(GetRoomConfig(roomInfo, "PlayerStartNum"))
+	if err != nil {
+		LogError("startPlayerRoom PlayerStartNum has err", err)
+		return GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	seatedPlayers := make([]*pb.RoomPlayerInfo, 0, len(roomInfo.GetPlayerInfo()))
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None {
+			continue
+		}
+		seatedPlayers = append(seatedPlayers, onePlayer)
+	}
+	if len(seatedPlayers) < playerStartNum {
+		return GetGrpcErrorMessage(pb.ErrorCode_StartRoomFail, "")
+	}
+
+	payPlayers := []*pb.RoomPlayerInfo{}
+	payNum := int64(0)
+	switch roomInfo.GetPayType() {
+	case pb.PayType_PayType_MasterPay:
+		masterPlayer := GetRoomPlayerInfo(roomInfo, roomInfo.GetRoomMasterUUID())
+		if masterPlayer == nil {
+			LogError("startPlayerRoom master not in room", roomInfo.GetRoomMasterUUID())
+			return GetGrpcErrorMessage(pb.ErrorCode_StartRoomFail, "")
+		}
+		payPlayers = append(payPlayers, masterPlayer)
+		payNum = roomInfo.GetMasterPayNum()
+	case pb.PayType_PayType_AA:
+		payPlayers = seatedPlayers
+		payNum = roomInfo.GetAaPayNum()
+	default:
+		LogError("startPlayerRoom PayType err", roomInfo.GetPayType())
+		return GetGrpcErrorMessage(pb.ErrorCode_PayTypeConfigError, "")
+	}
+	for _, onePlayer := range payPlayers {
+		if onePlayer.GetBalance() < payNum {
+			return GetGrpcErrorMessage(pb.ErrorCode_BalanceNotEnough, "")
+		}
+	}
+	for _, onePlayer := range payPlayers {
+		onePlayer.Balance -= payNum
+		// 房间已经扣过了，不需要再同步到房间
+		go ChangeOtherBalance(onePlayer.GetUuid(), -payNum, false, true, pb.ResourceChangeReason_CreateRoom)
+	}
+	roomInfo.PayStatus = pb.PayStatus_PayStatus_Success
+	roomInfo.StartPayTime = time.Now().Unix()
+	roomInfo.AllSettleInfo = []*pb.SettleInfo{}
+
+	for _, onePlayer := range seatedPlayers {
+		beforeState := onePlayer.GetPlayerRoomState()
+		onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateReady
+		if beforeState != pb.PlayerRoomState_PlayerRoomStateReady {
+			PlayerStateChangeBroadcast(roomInfo, onePlayer.GetUuid(), beforeState, pb.PlayerRoomState_PlayerRoomStateReady)
+		}
+	}
+	roomInfo.ReadyPlayerNum = int32(len(seatedPlayers))
+	pushPlayReady := &pb.RoomPlayerReadyNumMessege{
+		RoomId:   roomInfo.GetUuid(),
+		ReadyNum: int64(len(seatedPlayers)),
+	}
+	RoomBroadcast(roomInfo, pushPlayReady)
+	// 操作后的驱动直接开始游戏
+	roomInfo.DoTime = time.Now().Unix()
+	return nil
+}
+
+// kickPlayerRoomPlayer 房主在开始前踢出玩家，由房间的Kick踢出
+func kickPlayerRoomPlayer(roomInfo *pb.RoomInfo, uuid string) *pb.ErrorMessage {
+	if IsPlayerRoomStarted(roomInfo) || roomInfo.GetCurRoomState() != pb.RoomState_RoomStateReady {
+		return GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	if uuid == "" || uuid == roomInfo.GetRoomMasterUUID() {
+		return GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+	}
+	kickPlayer := GetRoomPlayerInfo(roomInfo, uuid)
+	if kickPlayer == nil {
+		return GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	kickPlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+	kickPlayer.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_KickNotReady
+	return nil
+}
+
+// dissolvePlayerRoom 房主解散自建房
+// 开始前直接解散，开始后只能在两局之间解散，解散后进入总结算
+func dissolvePlayerRoom(roomInfo *pb.RoomInfo) *pb.ErrorMessage {
+	if !IsPlayerRoomStarted(roomInfo) {
+		kickAllPlayerRoomPlayers(roomInfo)
+		return nil
+	}
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateReady {
+		return GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	roomInfo.LastRoomState = pb.RoomState_RoomStateReady
+	roomInfo.CurRoomState = pb.RoomState_RoomStateAllSettle
+	roomInfo.NextRoomState = pb.RoomState_RoomStateAllSettle
+	roomInfo.DoTime = time.Now().Unix()
+	return nil
+}
+
+// kickAllPlayerRoomPlayers 标记所有玩家被踢出并标记房间死亡，房间码在删除房间时一起删除
+func kickAllPlayerRoomPlayers(roomInfo *pb.RoomInfo) {
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" {
+			continue
+		}
+		onePlayer.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_RoomDisSolve
+		onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+	}
+	roomInfo.Dead = true
+}
+
+// PlayerRoomWaitStart 自建房开始前只初始化准备阶段，之后等待房主开始，不驱动游戏的准备逻辑
+func PlayerRoomWaitStart(roomInfo *pb.RoomInfo) bool {
+	return IsPlayerRoom(roomInfo) && !IsPlayerRoomStarted(roomInfo) &&
+		roomInfo.GetCurRoomState() == pb.RoomState_RoomStateReady &&
+		roomInfo.GetNextRoomState() != pb.RoomState_RoomStateReady
+}
+
+// PlayerRoomAfterDrive 自建房每次驱动后的局数处理
+// 从结算回到准备时局数加一，打满总局数后进入总结算
+func PlayerRoomAfterDrive(beforeState pb.RoomState, roomInfo *pb.RoomInfo) {
+	if !IsPlayerRoomStarted(roomInfo) {
+		return
+	}
+	if beforeState != pb.RoomState_RoomStateSettle || roomInfo.GetCurRoomState() != pb.RoomState_RoomStateReady {
+		return
+	}
+	roomInfo.RoomPlayNum++
+	if roomInfo.GetRoomPlayNum() > roomInfo.GetRoomAllPlayNum() {
+		roomInfo.CurRoomState = pb.RoomState_RoomStateAllSettle
+		roomInfo.NextRoomState = pb.RoomState_RoomStateAllSettle
+		roomInfo.DoTime = time.Now().Unix()
+	}
+}
+
+// PlayerRoomAllSettle 自建房总结算，推送所有局的结算信息后解散房间
+func PlayerRoomAllSettle(roomInfo *pb.RoomInfo) *pb.RoomInfo {
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:      roomInfo.GetUuid(),
+		BeforeState: roomInfo.GetLastRoomState(),
+		AfterState:  pb.RoomState_RoomStateAllSettle,
+	}
+	RoomBroadcast(roomInfo, pushRoomState)
+
+	pushBigSettle := &pb.PushRoomBigSettleInfo{
+		RoomId:        roomInfo.GetUuid(),
+		AllSettleInfo: roomInfo.GetAllSettleInfo(),
+	}
+	RoomBroadcast(roomInfo, pushBigSettle)
+
+	kickAllPlayerRoomPlayers(roomInfo)
+	return roomInfo
+}
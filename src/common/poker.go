@@ -0,0 +1,29 @@
+package common
+
+import (
+	pb "gameServer-demo/src/grpc"
+)
+
+// GetPokerHeap 获取指定副数的扑克牌堆（不含大小王），牌堆未洗牌
+// 参数：deckNum 牌的副数
+// 返回值：牌堆
+func GetPokerHeap(deckNum int) []*pb.Poker {
+	pokers := make([]*pb.Poker, 0, deckNum*52)
+	for deck := 0; deck < deckNum; deck++ {
+		for color := pb.PokerColor_PokerColorDiamond; color <= pb.PokerColor_PokerColorSpade; color++ {
+			for num := pb.PokerNum_PokerNum1; num <= pb.PokerNum_PokerNumK; num++ {
+				pokers = append(pokers, &pb.Poker{PokerNum: num, PokerColor: color})
+			}
+		}
+	}
+	return pokers
+}
+
+// GetShufflePokerHeap 获取指定副数并且已经洗好的扑克牌堆（不含大小王）
+// 参数：deckNum 牌的副数
+// 返回值：牌堆
+func GetShufflePokerHeap(deckNum int) []*pb.Poker {
+	pokers := GetPokerHeap(deckNum)
+	RandSlice(pokers)
+	return pokers
+}
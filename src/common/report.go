@@ -62,3 +62,17 @@ func PushPlayerBalanceChangeRecord(playerInfo *pb.PlayerInfo, changeBalance int6
 	balanceChangeRecord.ChangeReason = changeReason
 	return PushBalanceChangeRecord(balanceChangeRecord)
 }
+
+// PushBonusRecord 推送奖池变动记录
+func PushBonusRecord(bonusRecord *pb.BonusRecordReport) *pb.ErrorMessage {
+	reportMsg := &pb.ReportMessage{}
+
+	reportMsg.ReportType = pb.ReportType_ReportType_BonusRecordReport
+	reportAny, err := ptypes.MarshalAny(bonusRecord)
+	if err != nil {
+		LogError("report PushBonusRecord GRPC HandleMessage MarshalAny(reply) has err", err)
+		return GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	reportMsg.ReportContent = reportAny
+	return sendMessage(reportMsg)
+}
@@ -8,6 +8,13 @@ import (
 // RobotActionConfigTemp 机器人行为模版配置，提供默认模版
 var RobotActionConfigTemp map[string]*pb.RobotActionConfig
 
+// 宝石战争的机器人行为，协议的RobotAction中还没有定义，先占用180之后的值
+const (
+	RobotActionGemWarsJoinRoom pb.RobotAction = 180
+	RobotActionGemWarsExitRoom pb.RobotAction = 181
+	RobotActionGemWarsPlay     pb.RobotAction = 182
+)
+
 func init() {
 	RobotActionConfigTemp = make(map[string]*pb.RobotActionConfig)
 	RobotActionConfigTemp["default-online"] = &pb.RobotActionConfig{
@@ -67,6 +74,269 @@ func init() {
 		RobotDownBankRatio: 20,
 		BanksLength:        5,
 	}
+	// 龙虎斗
+	RobotActionConfigTemp["default-dragontiger-joinRoom"] = &pb.RobotActionConfig{
+		ActionUuid:           "default-dragontiger-joinRoom",
+		ActionName:           "默认龙虎斗加入房间",
+		ActionType:           pb.RobotAction_RobotAction_DragonTiger_JoinRoom,
+		JoinRoomScenes:       []int32{1},
+		JoinRoomScenesWeight: []int32{100},
+		JoinRoomRobotLimit:   20,
+	}
+	RobotActionConfigTemp["default-dragontiger-exitRoom"] = &pb.RobotActionConfig{
+		ActionUuid: "default-dragontiger-exitRoom",
+		ActionName: "默认龙虎斗退出房间",
+		ActionType: pb.RobotAction_RobotAction_DragonTiger_ExitRoom,
+	}
+	RobotActionConfigTemp["default-dragontiger-play"] = &pb.RobotActionConfig{
+		ActionUuid:                "default-dragontiger-play",
+		ActionName:                "默认龙虎斗玩耍",
+		ActionType:                pb.RobotAction_RobotAction_DragonTiger_Play,
+		MinPlayNum:                10,
+		MaxPlayNum:                150,
+		PlayEndPre:                10,
+		MinBalance:                20000,
+		RepeatBet:                 30,
+		DragonTigerBets:           []int32{45, 45, 10},
+		DragonTigerBetMoneyWeight: []int32{60, 30, 10, 0, 0, 0},
+	}
+	RobotActionConfigTemp["default-dragontigerBank-play"] = &pb.RobotActionConfig{
+		ActionUuid:         "default-dragontigerBank-play",
+		ActionName:         "默认龙虎斗庄家玩耍",
+		ActionType:         pb.RobotAction_RobotAction_DragonTigerBank_Play,
+		MinPlayNum:         10,
+		MaxPlayNum:         200,
+		PlayEndPre:         1,
+		MinBalance:         1000000,
+		RobotDownBankRatio: 20,
+		BanksLength:        5,
+	}
+	// 百家乐
+	RobotActionConfigTemp["default-baccarat-joinRoom"] = &pb.RobotActionConfig{
+		ActionUuid:           "default-baccarat-joinRoom",
+		ActionName:           "默认百家乐加入房间",
+		ActionType:           pb.RobotAction_RobotAction_Baccarat_JoinRoom,
+		JoinRoomScenes:       []int32{1},
+		JoinRoomScenesWeight: []int32{100},
+		JoinRoomRobotLimit:   20,
+	}
+	RobotActionConfigTemp["default-baccarat-exitRoom"] = &pb.RobotActionConfig{
+		ActionUuid: "default-baccarat-exitRoom",
+		ActionName: "默认百家乐退出房间",
+		ActionType: pb.RobotAction_RobotAction_Baccarat_ExitRoom,
+	}
+	RobotActionConfigTemp["default-baccarat-play"] = &pb.RobotActionConfig{
+		ActionUuid:             "default-baccarat-play",
+		ActionName:             "默认百家乐玩耍",
+		ActionType:             pb.RobotAction_RobotAction_Baccarat_Play,
+		MinPlayNum:             10,
+		MaxPlayNum:             150,
+		PlayEndPre:             10,
+		MinBalance:             20000,
+		RepeatBet:              30,
+		BaccaratBets:           []int32{40, 5, 5, 40, 10},
+		BaccaratBetMoneyWeight: []int32{60, 30, 10, 0, 0, 0},
+	}
+	RobotActionConfigTemp["default-baccaratBank-play"] = &pb.RobotActionConfig{
+		ActionUuid:         "default-baccaratBank-play",
+		ActionName:         "默认百家乐庄家玩耍",
+		ActionType:         pb.RobotAction_RobotAction_BaccaratBank_Play,
+		MinPlayNum:         10,
+		MaxPlayNum:         200,
+		PlayEndPre:         1,
+		MinBalance:         1000000,
+		RobotDownBankRatio: 20,
+		BanksLength:        5,
+	}
+	// 百人牛牛
+	RobotActionConfigTemp["default-hundredbull-joinRoom"] = &pb.RobotActionConfig{
+		ActionUuid:           "default-hundredbull-joinRoom",
+		ActionName:           "默认百人牛牛加入房间",
+		ActionType:           pb.RobotAction_RobotAction_HundredBull_JoinRoom,
+		JoinRoomScenes:       []int32{1},
+		JoinRoomScenesWeight: []int32{100},
+		JoinRoomRobotLimit:   20,
+	}
+	RobotActionConfigTemp["default-hundredbull-exitRoom"] = &pb.RobotActionConfig{
+		ActionUuid: "default-hundredbull-exitRoom",
+		ActionName: "默认百人牛牛退出房间",
+		ActionType: pb.RobotAction_RobotAction_HundredBull_ExitRoom,
+	}
+	RobotActionConfigTemp["default-hundredbull-play"] = &pb.RobotActionConfig{
+		ActionUuid:                "default-hundredbull-play",
+		ActionName:                "默认百人牛牛玩耍",
+		ActionType:                pb.RobotAction_RobotAction_HundredBull_Play,
+		MinPlayNum:                10,
+		MaxPlayNum:                150,
+		PlayEndPre:                10,
+		MinBalance:                20000,
+		RepeatBet:                 30,
+		HundredBullBets:           []int32{20, 20, 20, 20, 5, 5, 5, 5},
+		HundredBullBetMoneyWeight: []int32{60, 30, 10, 0, 0, 0},
+	}
+	RobotActionConfigTemp["default-hundredbullBank-play"] = &pb.RobotActionConfig{
+		ActionUuid:         "default-hundredbullBank-play",
+		ActionName:         "默认百人牛牛庄家玩耍",
+		ActionType:         pb.RobotAction_RobotAction_HundredBullBank_Play,
+		MinPlayNum:         10,
+		MaxPlayNum:         200,
+		PlayEndPre:         1,
+		MinBalance:         10000000,
+		RobotDownBankRatio: 20,
+		BanksLength:        5,
+	}
+	// 红黑大战
+	RobotActionConfigTemp["default-redblack-joinRoom"] = &pb.RobotActionConfig{
+		ActionUuid:           "default-redblack-joinRoom",
+		ActionName:           "默认红黑大战加入房间",
+		ActionType:           pb.RobotAction_RobotAction_RedBlack_JoinRoom,
+		JoinRoomScenes:       []int32{1},
+		JoinRoomScenesWeight: []int32{100},
+		JoinRoomRobotLimit:   20,
+	}
+	RobotActionConfigTemp["default-redblack-exitRoom"] = &pb.RobotActionConfig{
+		ActionUuid: "default-redblack-exitRoom",
+		ActionName: "默认红黑大战退出房间",
+		ActionType: pb.RobotAction_RobotAction_RedBlack_ExitRoom,
+	}
+	RobotActionConfigTemp["default-redblack-play"] = &pb.RobotActionConfig{
+		ActionUuid:             "default-redblack-play",
+		ActionName:             "默认红黑大战玩耍",
+		ActionType:             pb.RobotAction_RobotAction_RedBlack_Play,
+		MinPlayNum:             10,
+		MaxPlayNum:             150,
+		PlayEndPre:             10,
+		MinBalance:             20000,
+		RepeatBet:              30,
+		RedBlackBets:           []int32{45, 45, 10},
+		RedBlackBetMoneyWeight: []int32{60, 30, 10, 0, 0, 0},
+	}
+	RobotActionConfigTemp["default-redblackBank-play"] = &pb.RobotActionConfig{
+		ActionUuid:         "default-redblackBank-play",
+		ActionName:         "默认红黑大战庄家玩耍",
+		ActionType:         pb.RobotAction_RobotAction_RedBlackBank_Play,
+		MinPlayNum:         10,
+		MaxPlayNum:         200,
+		PlayEndPre:         1,
+		MinBalance:         10000000,
+		RobotDownBankRatio: 20,
+		BanksLength:        5,
+	}
+	RobotActionConfigTemp["default-gemwars-joinRoom"] = &pb.RobotActionConfig{
+		ActionUuid:           "default-gemwars-joinRoom",
+		ActionName:           "默认宝石战争加入房间",
+		ActionType:           RobotActionGemWarsJoinRoom,
+		JoinRoomScenes:       []int32{1},
+		JoinRoomScenesWeight: []int32{100},
+		JoinRoomRobotLimit:   20,
+	}
+	RobotActionConfigTemp["default-gemwars-exitRoom"] = &pb.RobotActionConfig{
+		ActionUuid: "default-gemwars-exitRoom",
+		ActionName: "默认宝石战争退出房间",
+		ActionType: RobotActionGemWarsExitRoom,
+	}
+	RobotActionConfigTemp["default-gemwars-play"] = &pb.RobotActionConfig{
+		ActionUuid: "default-gemwars-play",
+		ActionName: "默认宝石战争玩耍",
+		ActionType: RobotActionGemWarsPlay,
+		MinPlayNum: 10,
+		MaxPlayNum: 150,
+		PlayEndPre: 10,
+		MinBalance: 20000,
+		RepeatBet:  30,
+	}
+	// 拼牛牛
+	RobotActionConfigTemp["default-comparebull-joinRoom"] = &pb.RobotActionConfig{
+		ActionUuid:           "default-comparebull-joinRoom",
+		ActionName:           "默认拼牛牛加入房间",
+		ActionType:           pb.RobotAction_RobotAction_CompareBull_JoinRoom,
+		JoinRoomScenes:       []int32{1},
+		JoinRoomScenesWeight: []int32{100},
+		JoinRoomRobotLimit:   3,
+	}
+	RobotActionConfigTemp["default-comparebull-exitRoom"] = &pb.RobotActionConfig{
+		ActionUuid: "default-comparebull-exitRoom",
+		ActionName: "默认拼牛牛退出房间",
+		ActionType: pb.RobotAction_RobotAction_CompareBull_ExitRoom,
+	}
+	RobotActionConfigTemp["default-comparebull-play"] = &pb.RobotActionConfig{
+		ActionUuid: "default-comparebull-play",
+		ActionName: "默认拼牛牛玩耍",
+		ActionType: pb.RobotAction_RobotAction_CompareBull_Play,
+		MinPlayNum: 5,
+		MaxPlayNum: 50,
+		PlayEndPre: 10,
+		MinBalance: 20000,
+	}
+	// 疯狂牛牛
+	RobotActionConfigTemp["default-crazybull-joinRoom"] = &pb.RobotActionConfig{
+		ActionUuid:           "default-crazybull-joinRoom",
+		ActionName:           "默认疯狂牛牛加入房间",
+		ActionType:           pb.RobotAction_RobotAction_CrazyBull_JoinRoom,
+		JoinRoomScenes:       []int32{1},
+		JoinRoomScenesWeight: []int32{100},
+		JoinRoomRobotLimit:   3,
+	}
+	RobotActionConfigTemp["default-crazybull-exitRoom"] = &pb.RobotActionConfig{
+		ActionUuid: "default-crazybull-exitRoom",
+		ActionName: "默认疯狂牛牛退出房间",
+		ActionType: pb.RobotAction_RobotAction_CrazyBull_ExitRoom,
+	}
+	RobotActionConfigTemp["default-crazybull-play"] = &pb.RobotActionConfig{
+		ActionUuid: "default-crazybull-play",
+		ActionName: "默认疯狂牛牛玩耍",
+		ActionType: pb.RobotAction_RobotAction_CrazyBull_Play,
+		MinPlayNum: 5,
+		MaxPlayNum: 50,
+		PlayEndPre: 10,
+		MinBalance: 50000,
+	}
+	// 炸金花
+	RobotActionConfigTemp["default-jinhua-joinRoom"] = &pb.RobotActionConfig{
+		ActionUuid:           "default-jinhua-joinRoom",
+		ActionName:           "默认炸金花加入房间",
+		ActionType:           pb.RobotAction_RobotAction_Jinhua_JoinRoom,
+		JoinRoomScenes:       []int32{1},
+		JoinRoomScenesWeight: []int32{100},
+		JoinRoomRobotLimit:   3,
+	}
+	RobotActionConfigTemp["default-jinhua-exitRoom"] = &pb.RobotActionConfig{
+		ActionUuid: "default-jinhua-exitRoom",
+		ActionName: "默认炸金花退出房间",
+		ActionType: pb.RobotAction_RobotAction_Jinhua_ExitRoom,
+	}
+	RobotActionConfigTemp["default-jinhua-play"] = &pb.RobotActionConfig{
+		ActionUuid: "default-jinhua-play",
+		ActionName: "默认炸金花玩耍",
+		ActionType: pb.RobotAction_RobotAction_Jinhua_Play,
+		MinPlayNum: 5,
+		MaxPlayNum: 50,
+		PlayEndPre: 10,
+		MinBalance: 20000,
+	}
+	// 连连看
+	RobotActionConfigTemp["default-linkup-joinRoom"] = &pb.RobotActionConfig{
+		ActionUuid:           "default-linkup-joinRoom",
+		ActionName:           "默认连连看加入房间",
+		ActionType:           pb.RobotAction_RobotAction_LinkUp_JoinRoom,
+		JoinRoomScenes:       []int32{1},
+		JoinRoomScenesWeight: []int32{100},
+		JoinRoomRobotLimit:   2,
+	}
+	RobotActionConfigTemp["default-linkup-exitRoom"] = &pb.RobotActionConfig{
+		ActionUuid: "default-linkup-exitRoom",
+		ActionName: "默认连连看退出房间",
+		ActionType: pb.RobotAction_RobotAction_LinkUp_ExitRoom,
+	}
+	RobotActionConfigTemp["default-linkup-play"] = &pb.RobotActionConfig{
+		ActionUuid: "default-linkup-play",
+		ActionName: "默认连连看玩耍",
+		ActionType: pb.RobotAction_RobotAction_LinkUp_Play,
+		MinPlayNum: 5,
+		MaxPlayNum: 50,
+		PlayEndPre: 10,
+		MinBalance: 5000,
+	}
 }
 
 // InitRobotActionConfigTemp 预设组件要用的机器人行为配置模版，如果变量已存在，则不重置
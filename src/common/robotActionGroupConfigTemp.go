@@ -39,6 +39,197 @@ func init() {
 		},
 		RobotNum: 2,
 	}
+
+	// 龙虎斗
+	RobotActionGroupConfigTemp["default-dragon-tiger-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-dragon-tiger-robot",
+		ActionGroupName: "默认龙虎斗机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-dragontiger-joinRoom",
+			"default-dragontiger-play",
+			"default-dragontiger-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 2,
+	}
+
+	// 龙虎斗庄家机器人
+	RobotActionGroupConfigTemp["default-dragon-tiger-bank-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-dragon-tiger-bank-robot",
+		ActionGroupName: "默认龙虎斗庄家机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-dragontiger-joinRoom",
+			"default-dragontigerBank-play",
+			"default-dragontiger-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 2,
+	}
+
+	// 百家乐
+	RobotActionGroupConfigTemp["default-baccarat-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-baccarat-robot",
+		ActionGroupName: "默认百家乐机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-baccarat-joinRoom",
+			"default-baccarat-play",
+			"default-baccarat-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 2,
+	}
+
+	// 百家乐庄家机器人
+	RobotActionGroupConfigTemp["default-baccarat-bank-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-baccarat-bank-robot",
+		ActionGroupName: "默认百家乐庄家机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-baccarat-joinRoom",
+			"default-baccaratBank-play",
+			"default-baccarat-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 2,
+	}
+
+	// 百人牛牛
+	RobotActionGroupConfigTemp["default-hundred-bull-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-hundred-bull-robot",
+		ActionGroupName: "默认百人牛牛机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-hundredbull-joinRoom",
+			"default-hundredbull-play",
+			"default-hundredbull-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 2,
+	}
+
+	// 百人牛牛庄家机器人
+	RobotActionGroupConfigTemp["default-hundred-bull-bank-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-hundred-bull-bank-robot",
+		ActionGroupName: "默认百人牛牛庄家机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-hundredbull-joinRoom",
+			"default-hundredbullBank-play",
+			"default-hundredbull-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 2,
+	}
+
+	// 红黑大战
+	RobotActionGroupConfigTemp["default-red-black-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-red-black-robot",
+		ActionGroupName: "默认红黑大战机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-redblack-joinRoom",
+			"default-redblack-play",
+			"default-redblack-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 2,
+	}
+
+	// 红黑大战庄家机器人
+	RobotActionGroupConfigTemp["default-red-black-bank-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-red-black-bank-robot",
+		ActionGroupName: "默认红黑大战庄家机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-redblack-joinRoom",
+			"default-redblackBank-play",
+			"default-redblack-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 2,
+	}
+
+	// 宝石战争机器人
+	RobotActionGroupConfigTemp["default-gem-wars-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-gem-wars-robot",
+		ActionGroupName: "默认宝石战争机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-gemwars-joinRoom",
+			"default-gemwars-play",
+			"default-gemwars-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 2,
+	}
+
+	// 拼牛牛机器人
+	RobotActionGroupConfigTemp["default-compare-bull-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-compare-bull-robot",
+		ActionGroupName: "默认拼牛牛机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-comparebull-joinRoom",
+			"default-comparebull-play",
+			"default-comparebull-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 4,
+	}
+
+	// 疯狂牛牛机器人
+	RobotActionGroupConfigTemp["default-crazy-bull-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-crazy-bull-robot",
+		ActionGroupName: "默认疯狂牛牛机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-crazybull-joinRoom",
+			"default-crazybull-play",
+			"default-crazybull-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 4,
+	}
+	RobotActionGroupConfigTemp["default-jinhua-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-jinhua-robot",
+		ActionGroupName: "默认炸金花机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-jinhua-joinRoom",
+			"default-jinhua-play",
+			"default-jinhua-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 4,
+	}
+	RobotActionGroupConfigTemp["default-linkup-robot"] = &pb.RobotActionGroupConfig{
+		ActionGroupUuid: "default-linkup-robot",
+		ActionGroupName: "默认连连看机器人",
+		ActionConfigsUuid: []string{
+			"default-online",
+			"default-recharge",
+			"default-linkup-joinRoom",
+			"default-linkup-play",
+			"default-linkup-exitRoom",
+			"default-offline",
+		},
+		RobotNum: 4,
+	}
 }
 
 // InitRobotActionGroupConfigTemp 预设组件要用的机器人行为配置模版，如果变量已存在，则不重置
@@ -102,7 +102,7 @@ func (rm *RoomManager) ClearRoomLoop() {
 						// 将钱从safeMoney 扣 -- 不能大于safeMoney
 						if v.PunishTime > int64(penaltyTime) && v.AllWinOrLose > int64(penaltyQuota) {
 							onePunish := v.AllWinOrLose * int64(penaltyRatio) / 100
-							if onePunish >= v.SafeMoney {
+							if onePunish < v.SafeMoney {
 								v.SafeMoney -= onePunish
 								allPunish += onePunish
 							} else {
@@ -112,7 +112,7 @@ func (rm *RoomManager) ClearRoomLoop() {
 						}
 						// 输者计数
 						if v.AllWinOrLose < 0 {
-							allLose += AbsInt64(v.WinOrLose)
+							allLose += AbsInt64(v.AllWinOrLose)
 						}
 					}
 				}
@@ -121,7 +121,7 @@ func (rm *RoomManager) ClearRoomLoop() {
 					v.PunishTime += nowTime - room.GetRoomInfo().RoundStartTime // 上回合的游戏时间也计入惩罚时间中
 					if v.PunishTime > int64(penaltyTime) && v.AllWinOrLose > int64(penaltyQuota) {
 						onePunish := v.AllWinOrLose * int64(penaltyRatio) / 100
-						if onePunish >= v.SafeMoney {
+						if onePunish < v.SafeMoney {
 							v.SafeMoney -= onePunish
 							allPunish += onePunish
 						} else {
@@ -129,17 +129,21 @@ func (rm *RoomManager) ClearRoomLoop() {
 							v.SafeMoney = 0
 						}
 					}
+					if v.AllWinOrLose < 0 {
+						allLose += AbsInt64(v.AllWinOrLose)
+					}
 				}
 
+				// 房间已经解散，本金直接退回玩家身上，不需要再同步到房间
 				// 结算 在房间者
 				for _, v := range room.GetRoomInfo().PlayerInfo {
 					if v.Uuid != "" && v.AllSafeMoney > 0 {
 						// 输赢 >= 0 正常结算
 						if v.AllWinOrLose >= 0 {
-							go ChangeOtherBalance(v.Uuid, v.SafeMoney, true, true, pb.ResourceChangeReason_DaXuanBackMoney)
+							go ChangeOtherBalance(v.Uuid, v.SafeMoney, false, true, pb.ResourceChangeReason_DaXuanBackMoney)
 						} else {
 							v.SafeMoney += allPunish * AbsInt64(v.AllWinOrLose) / allLose
-							go ChangeOtherBalance(v.Uuid, v.SafeMoney, true, true, pb.ResourceChangeReason_DaXuanBackMoney)
+							go ChangeOtherBalance(v.Uuid, v.SafeMoney, false, true, pb.ResourceChangeReason_DaXuanBackMoney)
 						}
 					}
 				}
@@ -147,10 +151,10 @@ func (rm *RoomManager) ClearRoomLoop() {
 				for _, v := range room.GetRoomInfo().QPlayer {
 					// 输赢 >= 0 正常结算
 					if v.AllWinOrLose >= 0 {
-						go ChangeOtherBalance(v.PlayerUuid, v.SafeMoney, true, true, pb.ResourceChangeReason_DaXuanBackMoney)
+						go ChangeOtherBalance(v.PlayerUuid, v.SafeMoney, false, true, pb.ResourceChangeReason_DaXuanBackMoney)
 					} else {
 						v.SafeMoney += allPunish * AbsInt64(v.AllWinOrLose) / allLose
-						go ChangeOtherBalance(v.PlayerUuid, v.SafeMoney, true, true, pb.ResourceChangeReason_DaXuanBackMoney)
+						go ChangeOtherBalance(v.PlayerUuid, v.SafeMoney, false, true, pb.ResourceChangeReason_DaXuanBackMoney)
 					}
 				}
 				// 将所有玩家踢出
@@ -211,6 +215,22 @@ func (rm *RoomManager) GetRoom(roomID string) *Room {
 	return nil
 }
 
+// GetAllRoomInfo 获得所有没有死亡的房间信息的拷贝，用于房间列表
+func (rm *RoomManager) GetAllRoomInfo() []*pb.RoomInfo {
+	rm.rmLock.Lock()
+	defer rm.rmLock.Unlock()
+
+	allRoomInfo := make([]*pb.RoomInfo, 0, len(rm.roomMap))
+	for _, room := range rm.roomMap {
+		roomInfo := room.CloneRoomInfo()
+		if roomInfo.GetDead() {
+			continue
+		}
+		allRoomInfo = append(allRoomInfo, roomInfo)
+	}
+	return allRoomInfo
+}
+
 //SyncRoomPlayerInfo 同步房间玩家信息
 func (rm *RoomManager) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo) *pb.ErrorMessage {
 	rm.rmLock.Lock()
@@ -552,10 +572,16 @@ func (r *Room) Drive(driveFunc RoomDriveFunc) {
 		r.roomInfo.DoTime = afterDriveTime
 		timerTime = 5
 	}*/
-	// 毫秒级被设定就执行毫秒级的
+	// 毫秒级被设定就执行毫秒级的，MilliDoTime是毫秒级的下次驱动时间戳
+	// 驱动函数更新了MilliDoTime，或者之前设定的MilliDoTime还没到（比如玩家操作触发的驱动），都按照毫秒级定时
 	// 否则：秒级设定
-	if afterMilliTime > beforeMilliTime {
-		r.roomTimer, r.roomTimerStopChan = StartTimer(time.Duration(afterMilliTime-beforeMilliTime)*time.Millisecond, false, func() bool {
+	nowMilliTime := time.Now().UnixNano() / 1e6
+	if afterMilliTime > beforeMilliTime || afterMilliTime > nowMilliTime {
+		milliTimerTime := afterMilliTime - nowMilliTime
+		if milliTimerTime < 0 {
+			milliTimerTime = 0
+		}
+		r.roomTimer, r.roomTimerStopChan = StartTimer(time.Duration(milliTimerTime)*time.Millisecond, false, func() bool {
 			r.Drive(driveFunc)
 			return false
 		})
@@ -632,6 +658,14 @@ func (r *Room) GetRoomInfo() *pb.RoomInfo {
 	return nil
 }
 
+// CloneRoomInfo 加锁拷贝一份房间信息，拷贝可以在锁外随意读写
+func (r *Room) CloneRoomInfo() *pb.RoomInfo {
+	r.rlock.Lock()
+	defer r.rlock.Unlock()
+
+	return proto.Clone(r.roomInfo).(*pb.RoomInfo)
+}
+
 // GetRoomPlayerInfo 获得房间的某个玩家的信息
 func (r *Room) GetRoomPlayerInfo(uuid string) *pb.RoomPlayerInfo {
 	roomInfo := r.roomInfo
@@ -739,6 +773,10 @@ func (r *Room) TryJoinRoom(playerInfo *pb.PlayerInfo, joinRoomRequest *pb.GameJo
 		}
 		//return r.ResetJoinRoom(playerInfo.GetUuid()), nil
 	}
+	// 自建房只能通过房间码加入，不参与匹配
+	if joinRoomRequest.GetRoomUUID() == "" && r.roomInfo.GetRoomType() == pb.RoomType_RoomType_PlayerRoom {
+		return nil, nil
+	}
 
 	// 获取游戏类型
 	// 如百人场，只要房间没满，他就可以重复进入该房间
@@ -813,6 +851,8 @@ func (r *Room) TryJoinRoom(playerInfo *pb.PlayerInfo, joinRoomRequest *pb.GameJo
 			roomPlayer.PunishTime = r.roomInfo.QPlayer[escapeeIndex].PunishTime     // 惩罚时间
 			roomPlayer.SafeMoney = r.roomInfo.QPlayer[escapeeIndex].SafeMoney       // 桌子里的钱
 			roomPlayer.AllSafeMoney = r.roomInfo.QPlayer[escapeeIndex].AllSafeMoney // 总带入桌子里的钱
+			// 回到房间后就不再是逃跑者，避免再次退出时重复记录
+			r.roomInfo.QPlayer = append(r.roomInfo.QPlayer[:escapeeIndex], r.roomInfo.QPlayer[escapeeIndex+1:]...)
 		}
 	}
 
@@ -1011,6 +1051,13 @@ func (r *Room) TryExitRoom(playerInfo *pb.PlayerInfo, extroInfo *pb.MessageExtro
 			r.roomInfo.Dead = true
 		}
 	}
+	// 自建房开始前房主退出就解散房间
+	if IsPlayerRoom(r.roomInfo) && !IsPlayerRoomStarted(r.roomInfo) && r.roomInfo.GetRoomMasterUUID() == playerInfo.GetUuid() {
+		kickAllPlayerRoomPlayers(r.roomInfo)
+		r.Kick(false)
+		r.Save(false)
+		return nil
+	}
 
 	//房间有多少人准备了，推送给所有玩家
 	readyNum := 0
@@ -1126,7 +1173,17 @@ func (r *Room) ResetJoinRoom(uuid string) *pb.RoomInfo {
 	reply.CrazyBullMultipleuuidOdds = r.roomInfo.GetCrazyBullMultipleuuidOdds()
 	reply.DoubleLinkedMultipleuuid = r.roomInfo.GetDoubleLinkedMultipleuuid()
 	reply.DoubleLinkedMultipleuuidOdds = r.roomInfo.GetDoubleLinkedMultipleuuidOdds()
-	reply.MahjongGameInfo = r.roomInfo.MahjongGameInfo
+	reply.DaXuanInRoom = r.roomInfo.DaXuanInRoom
+	reply.DaXuanMultipleIndex = r.roomInfo.GetDaXuanMultipleIndex()
+	reply.DaXuanUpperUuid = r.roomInfo.GetDaXuanUpperUuid()
+	reply.DaXuanIsDiJiuWang = r.roomInfo.GetDaXuanIsDiJiuWang()
+	//麻将牌墙和玩家新摸的牌不能给前端看，牌墙只保留长度
+	if r.roomInfo.MahjongGameInfo != nil {
+		mahjongGameInfo := proto.Clone(r.roomInfo.MahjongGameInfo).(*pb.MahjongGameInfo)
+		mahjongGameInfo.MahjongCards = getHiddenMahjongs(len(mahjongGameInfo.MahjongCards))
+		mahjongGameInfo.NewMahjong = nil
+		reply.MahjongGameInfo = mahjongGameInfo
+	}
 	reply.BenzBMWGameInfo = r.roomInfo.BenzBMWGameInfo
 	reply.ClubUUID = r.roomInfo.GetClubUUID()
 	reply.LeagueUUID = r.roomInfo.GetLeagueUUID()
@@ -1166,7 +1223,20 @@ func (r *Room) ResetJoinRoom(uuid string) *pb.RoomInfo {
 			//uuid ，shortId，名字 ，金额，碰牌区，杠牌区，别人打出的哪一张牌，
 			//玩家房间中的状态,玩家出的扑克牌，玩家出的麻将牌，玩家输赢信息 -- 不变
 			newOnePlayerInfo := &pb.RoomPlayerInfo{}
-			newOnePlayerInfo.MahjongPlayerInfo = k.MahjongPlayerInfo
+			//别人的麻将手牌、新摸的牌、可以进行的操作、换三张阶段选的牌只保留张数，还没公布的定缺也要隐藏
+			if k.MahjongPlayerInfo != nil {
+				mahjongPlayerInfo := proto.Clone(k.MahjongPlayerInfo).(*pb.MahjongPlayerInfo)
+				mahjongPlayerInfo.HandRegion = getHiddenMahjongs(len(mahjongPlayerInfo.HandRegion))
+				if r.roomInfo.CurRoomState == pb.RoomState_RoomStateChangeThreeCards {
+					mahjongPlayerInfo.ChowRegion = getHiddenMahjongs(len(mahjongPlayerInfo.ChowRegion))
+				}
+				mahjongPlayerInfo.NewMahjong = nil
+				mahjongPlayerInfo.WaitChoice = nil
+				if !r.roomInfo.GetMahjongGameInfo().GetHasBeenBlankSuit() {
+					mahjongPlayerInfo.BlankSuit = pb.MahjongColor_MahjongColorNone
+				}
+				newOnePlayerInfo.MahjongPlayerInfo = mahjongPlayerInfo
+			}
 			newOnePlayerInfo.Uuid = k.Uuid
 			newOnePlayerInfo.ShortId = k.ShortId
 			newOnePlayerInfo.Account = k.Account
@@ -1192,6 +1262,15 @@ func (r *Room) ResetJoinRoom(uuid string) *pb.RoomInfo {
 			newOnePlayerInfo.City = k.GetCity()
 			newOnePlayerInfo.HeadImgUrl = k.GetHeadImgUrl()
 			newOnePlayerInfo.ShiSanShuiPlacePoker = k.ShiSanShuiPlacePoker
+			//打旋只能看到别人的明牌和桌上的钵钵
+			newOnePlayerInfo.DaXuanPublicPoker = k.DaXuanPublicPoker
+			newOnePlayerInfo.IsSeat = k.IsSeat
+			newOnePlayerInfo.IsFold = k.IsFold
+			newOnePlayerInfo.KeepSeat = k.KeepSeat
+			newOnePlayerInfo.SafeMoney = k.SafeMoney
+			newOnePlayerInfo.AllSafeMoney = k.AllSafeMoney
+			newOnePlayerInfo.BetsCount = k.BetsCount
+			newOnePlayerInfo.AllWinOrLose = k.AllWinOrLose
 			if newOnePlayerInfo.Name == "" {
 				newOnePlayerInfo.Name = k.GetShortId()
 			}
@@ -1227,6 +1306,16 @@ func (r *Room) ResetJoinRoom(uuid string) *pb.RoomInfo {
 	return reply
 }
 
+// getHiddenMahjongs 生成指定张数的隐藏麻将牌，用于给前端展示别人的手牌和牌墙的张数
+func getHiddenMahjongs(num int) []*pb.Mahjong {
+	hiddenMahjongs := make([]*pb.Mahjong, 0, num)
+	for i := 0; i < num; i++ {
+		hiddenMahjongs = append(hiddenMahjongs, &pb.Mahjong{MahjongNum: pb.MahjongNum_MahjongNumNone,
+			MahjongColor: pb.MahjongColor_MahjongColorNone})
+	}
+	return hiddenMahjongs
+}
+
 // PlayerUpSeat 玩家上座
 func (rm *RoomManager) PlayerUpSeat(playerInfo *pb.PlayerInfo, upSeatRequest *pb.DaXuanUpSeatRequest, extraInfo *pb.MessageExtroInfo) (*pb.DaXuanUpSeatReply, *pb.ErrorMessage) {
 	roomID := playerInfo.GetRoomId()
@@ -1325,7 +1414,7 @@ func (r *Room) TryUpSeat(playerInfo *pb.PlayerInfo, realRequest *pb.DaXuanUpSeat
 			}
 
 			RoomBroadcast(r.roomInfo, pushMsg)
-
+			r.Save(false)
 		} else {
 			realReply.IsSuccess = false
 			realReply.SafeMoney = r.roomInfo.PlayerInfo[playerIndex].SafeMoney
@@ -1381,7 +1470,7 @@ func (r *Room) TryUpSeat(playerInfo *pb.PlayerInfo, realRequest *pb.DaXuanUpSeat
 	r.roomInfo.PlayerInfo[playerIndex] = &pb.RoomPlayerInfo{}
 
 	realReply.IsSuccess = true
-	realReply.SafeMoney = r.roomInfo.PlayerInfo[playerIndex].SafeMoney
+	realReply.SafeMoney = r.roomInfo.PlayerInfo[int(realRequest.TableIndex)].SafeMoney
 
 	// 推送 变动信息
 	pushMsg := &pb.PushTableChange{
@@ -1520,7 +1609,7 @@ func (r *Room) TryTopUp(playerInfo *pb.PlayerInfo, realRequest *pb.DaXuanTopUpRe
 	}
 	RoomBroadcast(r.roomInfo, pushMsg)
 
-	r.Save(true)
+	r.Save(false)
 
 	return realReply, nil
 }
@@ -66,10 +66,11 @@ func GetFirstJoinRoomPlayer(roomInfo *pb.RoomInfo) string {
 
 // 下座位  抢座模式用
 // 参数：房间roomInfo,玩家索引playerIndex,桌子isTable
+// 下座后钵钵还留在玩家身上，可以再上座，房间解散时才退回
 func DownSeat(roomInfo *pb.RoomInfo, playerIndex int) bool {
-	var newIndex int
+	newIndex := -1
 
-	if playerIndex >= 8 {
+	if playerIndex < 0 || playerIndex >= 8 {
 		return false
 	}
 
@@ -80,17 +81,14 @@ func DownSeat(roomInfo *pb.RoomInfo, playerIndex int) bool {
 		}
 	}
 
-	// 有空位 就互换 + 将保险箱金额回收
-	if newIndex != 0 {
+	roomInfo.PlayerInfo[playerIndex].IsSeat = false
+	// 有空位 就互换
+	if newIndex != -1 {
 		roomInfo.PlayerInfo[newIndex], roomInfo.PlayerInfo[playerIndex] = roomInfo.PlayerInfo[playerIndex], roomInfo.PlayerInfo[newIndex]
-		roomInfo.PlayerInfo[playerIndex].Balance += roomInfo.PlayerInfo[playerIndex].SafeMoney
-		roomInfo.PlayerInfo[playerIndex].SafeMoney = 0
-		// 没空位,就追加 - 同时置空座位 + 将保险箱金额回收
+		// 没空位,就追加 - 同时置空座位
 	} else {
 		roomInfo.PlayerInfo = append(roomInfo.PlayerInfo, roomInfo.PlayerInfo[playerIndex])
-		roomInfo.PlayerInfo[newIndex] = &pb.RoomPlayerInfo{}
-		roomInfo.PlayerInfo[len(roomInfo.PlayerInfo)-1].Balance += roomInfo.PlayerInfo[len(roomInfo.PlayerInfo)-1].SafeMoney
-		roomInfo.PlayerInfo[len(roomInfo.PlayerInfo)-1].SafeMoney = 0
+		roomInfo.PlayerInfo[playerIndex] = &pb.RoomPlayerInfo{}
 	}
 	return true
 }
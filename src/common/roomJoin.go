@@ -40,6 +40,9 @@ func GameDriverJoinRoomWithCreateRoomFunc(
 		if msgErr != nil {
 			return nil, msgErr
 		}
+		if roomCodeInfo == nil {
+			return nil, GetGrpcErrorMessage(pb.ErrorCode_InValidRoomCode, "")
+		}
 		joinRoomRequest.RoomUUID = roomCodeInfo.GetRoomUUID()
 	}
 
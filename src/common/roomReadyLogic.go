@@ -131,6 +131,8 @@ func HundredGameReadyDiverNextState(request *pb.RoomInfo, readyFunc GameReadyFun
 			request.PlayerInfo[v].PlayerBets = make([]int64, 8)
 		case pb.GameType_RedBlack:
 			request.PlayerInfo[v].PlayerBets = make([]int64, 3)
+		case pb.GameType_Baccarat:
+			request.PlayerInfo[v].PlayerBets = make([]int64, 5)
 		}
 		request.PlayerInfo[v].WinOrLose = 0
 		request.PlayerInfo[v].GetBonus = 0
@@ -141,6 +143,8 @@ func HundredGameReadyDiverNextState(request *pb.RoomInfo, readyFunc GameReadyFun
 		request.PlayerInfo[v].DragonTigerWinMoney = 0
 		// 红黑特有
 		request.PlayerInfo[v].RedBlackOnlyWinMoney = 0
+		// 百家乐特有
+		request.PlayerInfo[v].BaccaratWinMoney = 0
 		// 当查在线表后，有他时就跳过，没有就标记将他踢掉
 		if isOnline {
 			// 复原玩家状态
@@ -178,6 +182,10 @@ func HundredGameReadyDiverNextState(request *pb.RoomInfo, readyFunc GameReadyFun
 	// 百人牛牛
 	request.HundredBullAllBet = make([]int64, 8)
 	request.HundredBullPokerList = []*pb.HundredBullPokerCard{}
+	// 百家乐
+	request.BaccaratAllBet = make([]int64, 5)
+	request.ZhuangPoker = nil
+	request.XianPoker = nil
 	// 推筒子
 	request.AllBet = make([]int64, 4)
 	request.PushBobbinMahjongList = []*pb.PushBobbinMahjong{}
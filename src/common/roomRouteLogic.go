@@ -34,6 +34,21 @@ func GameJoinRoomJudge(playerInfo *pb.PlayerInfo, serverNum int, joinRequest *pb
 			LogError("roomRouteLogic GameJoinRoomJudge JoinRoom RoomCode is nil", gameType, joinRequest.GameScene)
 			return "", GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
 		}
+		// 通过房间码加入时必须路由到房间所在的线路
+		if joinRequest.GetRoomCode() != "" {
+			roomCodeInfo, msgErr := GetRoomCodeInfo(joinRequest.GetRoomCode())
+			if msgErr != nil {
+				return "", msgErr
+			}
+			if roomCodeInfo == nil {
+				return "", GetGrpcErrorMessage(pb.ErrorCode_InValidRoomCode, "")
+			}
+			if roomCodeInfo.GetGameType() != gameType {
+				LogError("roomRouteLogic GameJoinRoomJudge RoomCode GameType err", joinRequest.GetRoomCode(), roomCodeInfo.GetGameType(), gameType)
+				return "", GetGrpcErrorMessage(pb.ErrorCode_GameTypeError, "")
+			}
+			driverServerIndex = roomCodeInfo.GetServerIndex()
+		}
 		// 如果是通过房间码或者房间id加入，则这里不判断金额，在加入房间的逻辑里判断
 		if joinRequest.GetRoomCode() == "" && joinRequest.GetRoomUUID() == "" {
 			enterBalanceStr := Configer.GetGameConfig(gameType, joinRequest.GameScene, "EnterBalance")
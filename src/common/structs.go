@@ -35,3 +35,11 @@ type WeChatTokenExtendedData struct {
 	OpenId  string
 	UnionId string
 }
+
+// ReportMsgDelivery 报表队列中的一条消息
+type ReportMsgDelivery struct {
+	// 消息id，发送时生成，用于消费去重
+	MessageId string
+	// 消息内容，pb.ReportMessage
+	Body []byte
+}
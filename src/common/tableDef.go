@@ -157,6 +157,10 @@ const (
 	MysqlReportRoomCardChangeRecord string = "report_room_card_change_record"
 	// MysqlReportBonusRecord 奖池变动记录
 	MysqlReportBonusRecord string = "report_bonus_change_record"
+	// MysqlReportConsumedMessage 报表队列已消费消息表，用于消费去重
+	MysqlReportConsumedMessage string = "report_consumed_message"
+	// MysqlReportDeadLetter 报表队列死信表，存放无法解析的消息
+	MysqlReportDeadLetter string = "report_dead_letter"
 	// MysqlAllianceLeaderRoomCardSettleInfo 副盟主结算信息
 	MysqlAllianceLeaderRoomCardSettleInfo string = "report_alliance_leader_room_card_settle_info"
 )
@@ -455,4 +459,35 @@ const (
 	AUTO_INCREMENT=1
 	DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci
 	`
+
+	// MysqlCheckReportConsumedMessage 报表队列已消费消息表
+	MysqlCheckReportConsumedMessage string = `
+	CREATE TABLE IF NOT EXISTS report_consumed_message(
+	auto_id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+	message_id varchar(128) NOT NULL,
+	consumed_time bigint(20) NOT NULL,
+	UNIQUE KEY auto_id (auto_id) USING BTREE,
+	UNIQUE KEY (message_id),
+	KEY (consumed_time)
+	)ENGINE=InnoDB 
+	AUTO_INCREMENT=1
+	DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci
+	`
+
+	// MysqlCheckReportDeadLetter 报表队列死信表
+	MysqlCheckReportDeadLetter string = `
+	CREATE TABLE IF NOT EXISTS report_dead_letter(
+	auto_id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+	message_id varchar(128) NOT NULL,
+	report_type int(11) NOT NULL,
+	reason varchar(255) NOT NULL,
+	content MEDIUMBLOB NOT NULL,
+	created_time bigint(20) NOT NULL,
+	UNIQUE KEY auto_id (auto_id) USING BTREE,
+	KEY (message_id),
+	KEY (created_time)
+	)ENGINE=InnoDB 
+	AUTO_INCREMENT=1
+	DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci
+	`
 )
@@ -0,0 +1,92 @@
+package common
+
+import (
+	pb "gameServer-demo/src/grpc"
+	"sort"
+)
+
+// 三张牌玩法一手牌的张数
+const threePokerNum = 3
+
+// GetThreePokerValue 获取一张牌在三张牌玩法中的点数，A最大算14点
+func GetThreePokerValue(poker *pb.Poker) int64 {
+	if poker.GetPokerNum() == pb.PokerNum_PokerNum1 {
+		return 14
+	}
+	return int64(poker.GetPokerNum())
+}
+
+// GetThreePokerType 获取三张牌的牌型
+// 牌型大小：豹子>顺金>金花>顺子>对子>单张，A23算最小的顺子
+// 参数：pokers 三张牌
+// 返回值：牌型，牌数不是三张时返回RedBlackCardType_None
+func GetThreePokerType(pokers []*pb.Poker) pb.RedBlackPokerType {
+	return GetThreePokerCompare(pokers).GetRedBlackPokerType()
+}
+
+// GetThreePokerCompare 计算三张牌的牌型和用于比较大小的信息
+// 牌按得分从高到低排序，对子时对子牌在前；A23顺子中A按1点计算
+// 分数先比牌型，再依次比三张牌的点数，最后比得分最高的牌的花色，所以一副牌中不会出现分数相同的两手牌
+// 参数：pokers 三张牌
+// 返回值：比较信息，牌数不是三张时牌型为RedBlackCardType_None
+func GetThreePokerCompare(pokers []*pb.Poker) *pb.CompareRedBlackPoker {
+	compare := &pb.CompareRedBlackPoker{
+		RedBlackPoker: pokers,
+	}
+	if len(pokers) != threePokerNum {
+		return compare
+	}
+	sorted := make([]*pb.Poker, threePokerNum)
+	copy(sorted, pokers)
+	sort.Slice(sorted, func(i, j int) bool {
+		return compareThreeSinglePoker(sorted[i], sorted[j])
+	})
+	values := make([]int64, threePokerNum)
+	for index, poker := range sorted {
+		values[index] = GetThreePokerValue(poker)
+	}
+
+	isFlush := sorted[0].GetPokerColor() == sorted[1].GetPokerColor() && sorted[1].GetPokerColor() == sorted[2].GetPokerColor()
+	isStraight := values[0] == values[1]+1 && values[1] == values[2]+1
+	// A23顺子，A放到最后按1点计算
+	if values[0] == 14 && values[1] == 3 && values[2] == 2 {
+		isStraight = true
+		sorted = []*pb.Poker{sorted[1], sorted[2], sorted[0]}
+		values = []int64{3, 2, 1}
+	}
+
+	pokerType := pb.RedBlackPokerType_RedBlackCardType_Single
+	switch {
+	case values[0] == values[2]:
+		pokerType = pb.RedBlackPokerType_RedBlackCardType_Trip
+	case isStraight && isFlush:
+		pokerType = pb.RedBlackPokerType_RedBlackCardType_StraightFlush
+	case isFlush:
+		pokerType = pb.RedBlackPokerType_RedBlackCardType_Flush
+	case isStraight:
+		pokerType = pb.RedBlackPokerType_RedBlackCardType_Straight
+	case values[0] == values[1]:
+		pokerType = pb.RedBlackPokerType_RedBlackCardType_Pair
+	case values[1] == values[2]:
+		// 对子牌放到前面
+		pokerType = pb.RedBlackPokerType_RedBlackCardType_Pair
+		sorted = []*pb.Poker{sorted[1], sorted[2], sorted[0]}
+		values = []int64{values[1], values[2], values[0]}
+	}
+
+	compare.RedBlackPokerType = pokerType
+	compare.TypePoker = sorted[0]
+	compare.MaxPoker = sorted[1]
+	compare.SinglePoker = sorted[2]
+	compare.PokerScore = int64(pokerType)<<24 | values[0]<<16 | values[1]<<12 | values[2]<<8 | int64(sorted[0].GetPokerColor())
+	return compare
+}
+
+// compareThreeSinglePoker 比较两张牌的大小，先比点数A最大，点数相同再比花色，a比b大时返回true
+func compareThreeSinglePoker(a *pb.Poker, b *pb.Poker) bool {
+	aValue, bValue := GetThreePokerValue(a), GetThreePokerValue(b)
+	if aValue != bValue {
+		return aValue > bValue
+	}
+	return a.GetPokerColor() > b.GetPokerColor()
+}
@@ -0,0 +1,315 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["BaccaratBet"] = &BaccaratBet{}
+}
+
+// BaccaratBet 百家乐游戏的下注组件，用于处理下注阶段的逻辑和玩家上下庄
+type BaccaratBet struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *BaccaratBet) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *BaccaratBet) Start() {
+	obj.Base.Start()
+}
+
+// Drive 百家乐下注阶段的主驱动
+func (obj *BaccaratBet) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateBet {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateSettle
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	// 获取下注阶段时长
+	betTimeStr := common.GetRoomConfig(request, "BetTime")
+	betTime, err := strconv.Atoi(betTimeStr)
+	if err != nil {
+		common.LogError("BaccaratBet Drive betTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 推送房间状态改变的信息
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateDeal,
+		AfterState:        pb.RoomState_RoomStateBet,
+		AfterStateEndTime: nowTime + int64(betTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	//下个状态
+	request.NextRoomState = pb.RoomState_RoomStateSettle
+	request.DoTime = nowTime + int64(betTime)
+	return request, nil
+}
+
+// RequestPlayerBet 玩家下注(区域：庄、庄对、闲对、闲、和）
+func (obj *BaccaratBet) RequestPlayerBet(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	//获取用户id
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("BaccaratBet RequestPlayerBet uuid == nil")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	roomInfo := request.GetRoomInfo()
+
+	//庄家不能下注
+	if uuid == roomInfo.BankerUuid {
+		common.LogError("BaccaratBet RequestPlayerBet BankerUuid can not bet")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BankerCannotBet, "")
+	}
+
+	//必须是下注状态才能下注
+	if roomInfo.CurRoomState != pb.RoomState_RoomStateBet {
+		common.LogError("BaccaratBet RequestPlayerBet Room State not is Bet")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInBetTime, "")
+	}
+
+	realRequest := &pb.BaccaratBetRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("BaccaratBet RequestPlayerBet ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	betArea := realRequest.GetBetArea()
+	betBalance := realRequest.GetBetBalance()
+	if betArea < pb.BaccaratCardArea_AreaZhuang || betArea > pb.BaccaratCardArea_AreaHe || betBalance <= 0 {
+		common.LogError("BaccaratBet RequestPlayerBet request invalid", betArea, betBalance)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BetRequestInvalid, "")
+	}
+	areaIndex := int(betArea) - 1
+
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uuid)
+	//玩家不在房间里面，这是错误的
+	if playerInfo == nil {
+		common.LogError("BaccaratBet RequestPlayerBet player not in room", uuid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+
+	// 判断玩家身上的钱是否够这次下注的钱
+	if playerInfo.Balance < betBalance {
+		common.LogError("百家乐玩家下注金额不足", uuid, playerInfo.Balance, betBalance)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerBalanceNotEnough, "")
+	}
+
+	// 判断限红
+	if len(roomInfo.MaxBetRatio) != betAreaNum || betBalance > roomInfo.MaxBetRatio[areaIndex] {
+		common.LogError("百家乐玩家下注超出限红", betArea, betBalance)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BetRatioNotEnough, "")
+	}
+	odds, msgErr := getBaccaratOdds(roomInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	bankerMoney, msgErr := getBankerMoney(roomInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	// 1.将下注金额累加到玩家下注与总注，并重新计算限红
+	if len(playerInfo.PlayerBets) != betAreaNum {
+		playerInfo.PlayerBets = make([]int64, betAreaNum)
+	}
+	playerInfo.PlayerBets[areaIndex] += betBalance
+	roomInfo.BaccaratAllBet[areaIndex] += betBalance
+	refreshMaxBetRatio(roomInfo, bankerMoney, odds)
+
+	// 2.减去房间信息里面玩家新增下注的金额 -- 最后结算才将金额从玩家表扣除
+	playerInfo.Balance -= betBalance
+	playerInfo.WinOrLose -= betBalance
+
+	// 3.将下注成功的玩家状态改变成游戏中
+	playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStatePlay
+
+	realReply := &pb.BaccaratBetReply{
+		IsSuccess:     true,
+		RoomId:        roomInfo.GetUuid(),
+		PlayerBalance: playerInfo.Balance,
+	}
+
+	// 广播玩家下注
+	pushMsg := &pb.PushBaccaratPlayerBet{
+		AllBet:        roomInfo.BaccaratAllBet,
+		Uuid:          uuid,
+		PlayerBets:    playerInfo.PlayerBets,
+		MaxBetRatio:   roomInfo.MaxBetRatio,
+		RoomId:        roomInfo.GetUuid(),
+		PlayerBalance: playerInfo.Balance,
+	}
+	common.RoomBroadcast(roomInfo, pushMsg)
+
+	// 所有区域都无法再下最小筹码时，直接开牌结算
+	minChip := getMinChip(roomInfo)
+	canBet := false
+	for _, maxBet := range roomInfo.MaxBetRatio {
+		if maxBet >= minChip {
+			canBet = true
+			break
+		}
+	}
+	if !canBet {
+		common.LogDebug("筹码已经达到庄家限红，直接开牌结算")
+		roomInfo.DoTime = time.Now().Unix()
+	}
+
+	return obj.packReply(roomInfo, realReply)
+}
+
+// RequestUpBanker 玩家上庄
+func (obj *BaccaratBet) RequestUpBanker(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	//获取用户id
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("BaccaratBet RequestUpBanker uuid == nil")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	roomInfo := request.GetRoomInfo()
+
+	realRequest := &pb.BaccaratUpBankerRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("BaccaratBet RequestUpBanker ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 获取上庄最小金额，上庄玩家列表最大长度
+	userBankerMoneyStr := common.GetRoomConfig(roomInfo, "UserBankerMoney")
+	userBankerMoney, err := strconv.ParseInt(userBankerMoneyStr, 10, 64)
+	if err != nil {
+		common.LogError("BaccaratBet RequestUpBanker userBankerMoney has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	bankersLengthStr := common.GetRoomConfig(roomInfo, "BankersLength")
+	bankersLength, err := strconv.Atoi(bankersLengthStr)
+	if err != nil {
+		common.LogError("BaccaratBet RequestUpBanker bankersLength has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uuid)
+	if playerInfo == nil {
+		common.LogError("BaccaratBet RequestUpBanker player not in room", uuid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+
+	// 判断上庄玩家是否是庄家或者已经在申请列表里
+	if uuid == roomInfo.BankerUuid || common.PlayerIsInBankers(uuid, roomInfo) {
+		common.LogError("BaccaratBet RequestUpBanker player already in Bankers,uuid = ", uuid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerAlreadyInBanker, "")
+	}
+
+	// 判断上庄玩家金额够否
+	if playerInfo.Balance < userBankerMoney {
+		common.LogError("BaccaratBet RequestUpBanker player Balance is not enough,uuid = ", uuid, " balance = ", playerInfo.Balance)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerBalanceNotEnough, "")
+	}
+
+	// 判断上庄玩家列表是否有空位
+	if len(roomInfo.Bankers) >= bankersLength {
+		common.LogError("BaccaratBet RequestUpBanker bankers length >= ", bankersLength)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BankersIsFull, "")
+	}
+
+	// 将用户加入到申请庄家列表，将玩家状态改变成游戏中
+	roomInfo.Bankers = append(roomInfo.Bankers, uuid)
+	playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStatePlay
+
+	// 广播现在庄家申请队列
+	pushMsg := &pb.PushBaccaratChangeBankers{
+		RoomId:  roomInfo.GetUuid(),
+		Bankers: roomInfo.Bankers,
+	}
+	common.RoomBroadcast(roomInfo, pushMsg)
+
+	realReply := &pb.BaccaratUpBankerReply{
+		IsSuccess: true,
+		RoomId:    roomInfo.GetUuid(),
+	}
+	return obj.packReply(roomInfo, realReply)
+}
+
+// RequestDownBanker 玩家下庄
+func (obj *BaccaratBet) RequestDownBanker(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	//获取用户id
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("BaccaratBet RequestDownBanker uuid == nil")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	roomInfo := request.GetRoomInfo()
+
+	realRequest := &pb.BaccaratDownBankerRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("BaccaratBet RequestDownBanker ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	realReply := &pb.BaccaratDownBankerReply{
+		RoomId: roomInfo.GetUuid(),
+	}
+
+	// 1.玩家在庄家申请列表，就将他删除+广播
+	for index, bankerUuid := range roomInfo.Bankers {
+		if bankerUuid != uuid {
+			continue
+		}
+		roomInfo.Bankers = append(roomInfo.Bankers[:index], roomInfo.Bankers[index+1:]...)
+		realReply.IsSuccess = true
+		tempInfo := common.GetRoomPlayerInfo(roomInfo, uuid)
+		if tempInfo != nil {
+			tempInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateFree
+		}
+		pushMsg := &pb.PushBaccaratChangeBankers{
+			RoomId:  roomInfo.GetUuid(),
+			Bankers: roomInfo.Bankers,
+		}
+		common.RoomBroadcast(roomInfo, pushMsg)
+		break
+	}
+
+	// 2.如果玩家是庄家,设置庄家申请了下庄,在下一回合定庄阶段将庄家改变
+	if uuid == roomInfo.BankerUuid {
+		roomInfo.DownBankerQuest = true
+		realReply.IsSuccess = true
+	}
+	return obj.packReply(roomInfo, realReply)
+}
+
+// packReply 封装回复给driver的房间信息和回复消息
+func (obj *BaccaratBet) packReply(roomInfo *pb.RoomInfo, realReply proto.Message) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	realReplyAny, err := ptypes.MarshalAny(realReply)
+	if err != nil {
+		common.LogError("BaccaratBet packReply MarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	reply.RoomInfo = roomInfo
+	reply.Message = realReplyAny
+	return reply, nil
+}
@@ -0,0 +1,103 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["BaccaratDeal"] = &BaccaratDeal{}
+}
+
+// BaccaratDeal 百家乐游戏组件，用于处理发牌阶段的逻辑
+type BaccaratDeal struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *BaccaratDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *BaccaratDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 房间发牌状态的驱动逻辑
+func (obj *BaccaratDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	//获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateDeal {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateBet
+		request.NextRoomState = pb.RoomState_RoomStateBet
+		request.DoTime = nowTime
+		return request, nil
+	}
+	dealTimeStr := common.GetRoomConfig(request, "DealTime")
+	dealTime, err := strconv.Atoi(dealTimeStr)
+	if err != nil {
+		common.LogError("BaccaratDeal Drive dealTimeStr has err", dealTimeStr)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 牌靴中的牌不足切牌数时换一副新牌靴，同时清空路单
+	deckNumStr := common.GetRoomConfig(request, "DeckNum")
+	deckNum, err := strconv.Atoi(deckNumStr)
+	if err != nil || deckNum <= 0 {
+		common.LogError("BaccaratDeal Drive DeckNum has err", deckNumStr)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	cutCardNumStr := common.GetRoomConfig(request, "CutCardNum")
+	cutCardNum, err := strconv.Atoi(cutCardNumStr)
+	if err != nil {
+		common.LogError("BaccaratDeal Drive CutCardNum has err", cutCardNumStr)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	if cutCardNum < maxRoundCardNum {
+		cutCardNum = maxRoundCardNum
+	}
+	if len(request.PokerCardHeap) < cutCardNum {
+		request.PokerCardHeap = common.GetShufflePokerHeap(deckNum)
+		request.BaccaratWinInfos = nil
+		pushWinInfos := &pb.PushBaccaratWinInfos{
+			RoomId:           request.GetUuid(),
+			BaccaratWinInfos: request.GetBaccaratWinInfos(),
+		}
+		common.RoomBroadcast(request, pushWinInfos)
+	}
+
+	//推送消息
+	roomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateLocation,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + int64(dealTime),
+	}
+	common.RoomBroadcast(request, roomState)
+	// 庄闲各发两张暗牌，开牌和补牌在结算阶段进行
+	pushPoker := &pb.PushBaccaratPoker{
+		RoomId: request.GetUuid(),
+		ZhuangPoker: []*pb.Poker{
+			{PokerNum: pb.PokerNum_PokerNumNone, PokerColor: pb.PokerColor_PokerColorNone},
+			{PokerNum: pb.PokerNum_PokerNumNone, PokerColor: pb.PokerColor_PokerColorNone},
+		},
+		XianPoker: []*pb.Poker{
+			{PokerNum: pb.PokerNum_PokerNumNone, PokerColor: pb.PokerColor_PokerColorNone},
+			{PokerNum: pb.PokerNum_PokerNumNone, PokerColor: pb.PokerColor_PokerColorNone},
+		},
+	}
+	common.RoomBroadcast(request, pushPoker)
+
+	//下个状态
+	request.NextRoomState = pb.RoomState_RoomStateBet
+	request.DoTime = nowTime + int64(dealTime)
+	return request, nil
+}
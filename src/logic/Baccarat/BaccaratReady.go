@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+)
+
+func init() {
+	common.AllComponentMap["BaccaratReady"] = &BaccaratReady{}
+}
+
+// BaccaratReady 百家乐游戏的准备组件，用于处理准备阶段的逻辑
+type BaccaratReady struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *BaccaratReady) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *BaccaratReady) Start() {
+	obj.Base.Start()
+	common.InitGameConfigTemp(common.BaccaratGameConfigTemp, pb.GameType_Baccarat)
+}
+
+// Drive 百家乐准备阶段的主驱动
+func (obj *BaccaratReady) Drive(request *pb.RoomInfo, extraInfo *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	return common.HundredGameReadyDiver(request, func(roomInfo *pb.RoomInfo) *pb.ErrorMessage {
+		// 新回合的下注信息推送给房间所有人
+		pushReadyInit := &pb.PushBaccaratReadyInit{
+			RoomId:         roomInfo.GetUuid(),
+			PlayerInfo:     roomInfo.GetPlayerInfo(),
+			BaccaratAllBet: roomInfo.GetBaccaratAllBet(),
+		}
+		common.RoomBroadcast(roomInfo, pushReadyInit)
+		return nil
+	})
+}
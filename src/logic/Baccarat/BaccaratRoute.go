@@ -0,0 +1,157 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+)
+
+func init() {
+	common.AllComponentMap["BaccaratRoute"] = &BaccaratRoute{}
+}
+
+// BaccaratRoute 百家乐游戏的功能中转组件，其他服务通过这个组件中转百家乐协议到具体逻辑组件中
+type BaccaratRoute struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *BaccaratRoute) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *BaccaratRoute) Start() {
+	obj.Base.Start()
+
+	// 需要的配置进行模版初始化
+	initGlobleConfigNameArr := []string{
+		"BaccaratServerNum",
+	}
+	err := common.InitGlobleConfigTemp(initGlobleConfigNameArr)
+	if err != nil {
+		panic("BaccaratRoute initGlobleConfigNameArr has err")
+	}
+}
+
+// Do 中转协议的具体逻辑(玩家的请求操作通过do路由到driver）
+func (obj *BaccaratRoute) Do(request *pb.BaccaratDoRequest, extroInfo *pb.MessageExtroInfo) (proto.Message, *pb.ErrorMessage) {
+	doType := request.GetDoType()
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("BaccaratRoute Do uuid == nil")
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotLogin, "")
+	}
+	// 选择一个driver线路
+	originMessage := request.GetDoMessageContent()
+	serverNumConfig := common.Configer.GetGlobal("BaccaratServerNum")
+	serverNumStr := serverNumConfig.GetValue()
+	serverNum, err := strconv.Atoi(serverNumStr)
+	if err != nil {
+		common.LogError("BaccaratRoute Do Atoi(serverNumStr) has err", serverNumStr)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 获取玩家信息
+	loadPlayerRequest := &pb.LoadPlayerRequest{}
+	loadPlayerRequest.Uuid = uuid
+	loadPlayerReply := &pb.LoadPlayerReply{}
+	msgErr := common.Router.Call("PlayerInfo", "LoadPlayer", loadPlayerRequest, loadPlayerReply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	playerInfo := loadPlayerReply.GetPlayerInfo()
+
+	var requestMessage proto.Message
+	var replyMessage proto.Message
+	//玩家线路
+	var driverServerIndex string
+	//方法名
+	var methodName string
+	switch doType {
+	//进入房间--与其他逻辑不同
+	case pb.BaccaratDoType_BaccaratDo_JoinRoom:
+		requestMessage = &pb.GameJoinRoomRequest{}
+		replyMessage = &pb.GameJoinRoomReply{}
+
+		err := ptypes.UnmarshalAny(originMessage, requestMessage)
+		if err != nil {
+			common.LogError("BaccaratRoute Do joinRoom ptypes.UnmarshalAny has err", doType, uuid, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		driverServerIndex, msgErr = common.GameJoinRoomJudge(playerInfo, serverNum, requestMessage.(*pb.GameJoinRoomRequest), pb.GameType_Baccarat)
+		if msgErr != nil {
+			return nil, msgErr
+		}
+
+		methodName = "RequestJoinRoom"
+	//退出房间
+	case pb.BaccaratDoType_BaccaratDo_ExitRoom:
+		requestMessage = &pb.GameExitRoomRequest{}
+		replyMessage = &pb.GameExitRoomReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestExitRoom"
+	//玩家下注
+	case pb.BaccaratDoType_BaccaratDo_Bets:
+		requestMessage = &pb.BaccaratBetRequest{}
+		replyMessage = &pb.BaccaratBetReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestPlayerBet"
+	//玩家上庄
+	case pb.BaccaratDoType_BaccaratDo_RushVillage:
+		requestMessage = &pb.BaccaratUpBankerRequest{}
+		replyMessage = &pb.BaccaratUpBankerReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestUpBanker"
+	//玩家下庄
+	case pb.BaccaratDoType_BaccaratDo_RushVillageDown:
+		requestMessage = &pb.BaccaratDownBankerRequest{}
+		replyMessage = &pb.BaccaratDownBankerReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestDownBanker"
+
+	// 房间状态由服务端驱动，不接受客户端改变
+	default:
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	err = ptypes.UnmarshalAny(originMessage, requestMessage)
+	if err != nil {
+		common.LogError("BaccaratRoute Do request ptypes.UnmarshalAny has err", doType, uuid, err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	componentName := "BaccaratDriver" + driverServerIndex
+	// common.Router.Call 都是路由到 driver,  driver 再分发给各个组件
+	msgErr = common.Router.Call(componentName, methodName, requestMessage, replyMessage, extroInfo)
+	if msgErr != nil {
+		common.LogError(msgErr)
+		return nil, msgErr
+	}
+	return replyMessage, nil
+}
+
+// SyncRoomPlayerInfo 同步房间玩家信息
+func (obj *BaccaratRoute) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo, extroInfo *pb.MessageExtroInfo) (*pb.EmptyMessage, *pb.ErrorMessage) {
+	reply := &pb.EmptyMessage{}
+	componentName := "BaccaratDriver" + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "SyncRoomPlayerInfo", request, reply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	return reply, nil
+}
+
+// DelRoom 刪除房間
+func (obj *BaccaratRoute) DelRoom(request *pb.DelRoomByGameTypeRequest, extraInfo *pb.MessageExtroInfo) (*pb.DelRoomByGameTypeReply, *pb.ErrorMessage) {
+	reply := &pb.DelRoomByGameTypeReply{}
+	componentName := "BaccaratDriver" + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "DelRoom", request, reply, extraInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
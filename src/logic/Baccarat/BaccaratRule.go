@@ -0,0 +1,353 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+)
+
+// 下注区域的数量（庄、庄对、闲对、闲、和）
+const betAreaNum = 5
+
+// 下注区域对应的下标
+const (
+	zhuangIndex    = int(pb.BaccaratCardArea_AreaZhuang) - 1
+	zhuangDuiIndex = int(pb.BaccaratCardArea_AreaZhuangDui) - 1
+	xianDuiIndex   = int(pb.BaccaratCardArea_AreaXianDui) - 1
+	xianIndex      = int(pb.BaccaratCardArea_AreaXian) - 1
+	heIndex        = int(pb.BaccaratCardArea_AreaHe) - 1
+)
+
+// 血池控制时最多尝试的发牌位置数量
+const controlTryNum = 30
+
+// 一局最多用到的牌数
+const maxRoundCardNum = 6
+
+// baccaratOdds 百家乐的赔率配置
+type baccaratOdds struct {
+	// 各区域赔率，下标为区域-1
+	areaOdds []int64
+	// 庄区域赢钱时的佣金 单位：%
+	zhuangCommission int64
+}
+
+// baccaratResult 一局的开牌结果
+type baccaratResult struct {
+	zhuangPoker []*pb.Poker
+	xianPoker   []*pb.Poker
+	// 庄、闲、和
+	winner     pb.BaccaratType
+	zhuangPair bool
+	xianPair   bool
+}
+
+// getBaccaratOdds 获取房间的赔率配置
+func getBaccaratOdds(roomInfo *pb.RoomInfo) (*baccaratOdds, *pb.ErrorMessage) {
+	odds := &baccaratOdds{
+		areaOdds: make([]int64, betAreaNum),
+	}
+	oddsNames := map[int]string{
+		zhuangIndex:    "OddsZhuang",
+		zhuangDuiIndex: "OddsZhuangDui",
+		xianDuiIndex:   "OddsXianDui",
+		xianIndex:      "OddsXian",
+		heIndex:        "OddsHe",
+	}
+	for index, oddsName := range oddsNames {
+		oddsStr := common.GetRoomConfig(roomInfo, oddsName)
+		oddsNum, err := strconv.ParseInt(oddsStr, 10, 64)
+		if err != nil || oddsNum <= 0 {
+			common.LogError("getBaccaratOdds has err", oddsName, oddsStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		odds.areaOdds[index] = oddsNum
+	}
+	commissionStr := common.GetRoomConfig(roomInfo, "ZhuangCommission")
+	commission, err := strconv.ParseInt(commissionStr, 10, 64)
+	if err != nil || commission < 0 || commission >= 100 {
+		common.LogError("getBaccaratOdds ZhuangCommission has err", commissionStr, err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	odds.zhuangCommission = commission
+	return odds, nil
+}
+
+// getPokerPoint 获取一张牌的点数，A为1，10和JQK为0
+func getPokerPoint(poker *pb.Poker) int64 {
+	if poker.GetPokerNum() >= pb.PokerNum_PokerNum10 {
+		return 0
+	}
+	return int64(poker.GetPokerNum())
+}
+
+// getPokersPoint 获取一手牌的点数，取总和的个位数
+func getPokersPoint(pokers []*pb.Poker) int64 {
+	var point int64
+	for _, poker := range pokers {
+		point += getPokerPoint(poker)
+	}
+	return point % 10
+}
+
+// zhuangNeedDraw 庄家是否需要补第三张牌
+// 参数：庄家前两张牌的点数，闲家补的第三张牌（闲家没有补牌时为nil）
+func zhuangNeedDraw(zhuangPoint int64, xianThird *pb.Poker) bool {
+	// 闲家没有补牌时，庄家和闲家的补牌规则一样
+	if xianThird == nil {
+		return zhuangPoint <= 5
+	}
+	thirdPoint := getPokerPoint(xianThird)
+	switch zhuangPoint {
+	case 0, 1, 2:
+		return true
+	case 3:
+		return thirdPoint != 8
+	case 4:
+		return thirdPoint >= 2 && thirdPoint <= 7
+	case 5:
+		return thirdPoint >= 4 && thirdPoint <= 7
+	case 6:
+		return thirdPoint == 6 || thirdPoint == 7
+	}
+	return false
+}
+
+// dealBaccarat 从牌堆顶开始按照补牌规则发一局牌
+// 返回值：开牌结果，用掉的牌数，牌堆中的牌不够时开牌结果为nil
+func dealBaccarat(cardHeap []*pb.Poker) (*baccaratResult, int) {
+	if len(cardHeap) < maxRoundCardNum {
+		return nil, 0
+	}
+	// 闲庄交替各发两张
+	result := &baccaratResult{
+		xianPoker:   []*pb.Poker{cardHeap[0], cardHeap[2]},
+		zhuangPoker: []*pb.Poker{cardHeap[1], cardHeap[3]},
+	}
+	usedNum := 4
+	xianPoint := getPokersPoint(result.xianPoker)
+	zhuangPoint := getPokersPoint(result.zhuangPoker)
+	// 任意一方拿到8点或9点（天牌）时都不补牌
+	if xianPoint < 8 && zhuangPoint < 8 {
+		var xianThird *pb.Poker
+		// 闲家0-5点补牌，6-7点停牌
+		if xianPoint <= 5 {
+			xianThird = cardHeap[usedNum]
+			result.xianPoker = append(result.xianPoker, xianThird)
+			usedNum++
+		}
+		if zhuangNeedDraw(zhuangPoint, xianThird) {
+			result.zhuangPoker = append(result.zhuangPoker, cardHeap[usedNum])
+			usedNum++
+		}
+		xianPoint = getPokersPoint(result.xianPoker)
+		zhuangPoint = getPokersPoint(result.zhuangPoker)
+	}
+	switch {
+	case zhuangPoint > xianPoint:
+		result.winner = pb.BaccaratType_TypeZhuang
+	case zhuangPoint < xianPoint:
+		result.winner = pb.BaccaratType_TypeXian
+	default:
+		result.winner = pb.BaccaratType_TypeHe
+	}
+	// 前两张牌点数相同为对子
+	result.zhuangPair = result.zhuangPoker[0].GetPokerNum() == result.zhuangPoker[1].GetPokerNum()
+	result.xianPair = result.xianPoker[0].GetPokerNum() == result.xianPoker[1].GetPokerNum()
+	return result, usedNum
+}
+
+// getAreaResult 计算某个区域的下注在开奖结果下的返还金额（含本金）和盈利金额
+// 返回值：返还金额，盈利金额
+func getAreaResult(areaIndex int, bet int64, result *baccaratResult, odds *baccaratOdds) (int64, int64) {
+	if bet <= 0 {
+		return 0, 0
+	}
+	var win bool
+	switch areaIndex {
+	case zhuangIndex:
+		// 开和时庄闲的下注退还
+		if result.winner == pb.BaccaratType_TypeHe {
+			return bet, 0
+		}
+		if result.winner == pb.BaccaratType_TypeZhuang {
+			winBalance := bet * odds.areaOdds[areaIndex] * (100 - odds.zhuangCommission) / 100
+			return bet + winBalance, winBalance
+		}
+	case xianIndex:
+		if result.winner == pb.BaccaratType_TypeHe {
+			return bet, 0
+		}
+		win = result.winner == pb.BaccaratType_TypeXian
+	case heIndex:
+		win = result.winner == pb.BaccaratType_TypeHe
+	case zhuangDuiIndex:
+		win = result.zhuangPair
+	case xianDuiIndex:
+		win = result.xianPair
+	}
+	if !win {
+		return 0, 0
+	}
+	winBalance := bet * odds.areaOdds[areaIndex]
+	return bet + winBalance, winBalance
+}
+
+// getBetsNetWin 计算一组下注在开奖结果下的净输赢（未抽水）
+func getBetsNetWin(bets []int64, result *baccaratResult, odds *baccaratOdds) int64 {
+	var netWin int64
+	for areaIndex, bet := range bets {
+		if bet <= 0 {
+			continue
+		}
+		backBalance, _ := getAreaResult(areaIndex, bet, result, odds)
+		netWin += backBalance - bet
+	}
+	return netWin
+}
+
+// getAllResultTypes 获取所有可能的开奖组合（庄闲和与庄对、闲对的组合），只用于计算输赢
+func getAllResultTypes() []*baccaratResult {
+	var results []*baccaratResult
+	winners := []pb.BaccaratType{pb.BaccaratType_TypeZhuang, pb.BaccaratType_TypeXian, pb.BaccaratType_TypeHe}
+	pairs := []bool{false, true}
+	for _, winner := range winners {
+		for _, zhuangPair := range pairs {
+			for _, xianPair := range pairs {
+				results = append(results, &baccaratResult{
+					winner:     winner,
+					zhuangPair: zhuangPair,
+					xianPair:   xianPair,
+				})
+			}
+		}
+	}
+	return results
+}
+
+// getBankerMoney 获取当前庄家可用于赔付的金额
+func getBankerMoney(roomInfo *pb.RoomInfo) (int64, *pb.ErrorMessage) {
+	if roomInfo.GetBankerUuid() == "systemBanker" {
+		defaultMoneyStr := common.GetRoomConfig(roomInfo, "DefaultBankerMoney")
+		defaultMoney, err := strconv.ParseInt(defaultMoneyStr, 10, 64)
+		if err != nil {
+			common.LogError("getBankerMoney DefaultBankerMoney has err", defaultMoneyStr, err)
+			return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		return defaultMoney, nil
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo == nil {
+		return 0, nil
+	}
+	return bankerInfo.GetBalance(), nil
+}
+
+// refreshMaxBetRatio 根据庄家金额和房间总注刷新各区域的限红
+// 每个区域的限红是这个区域中奖的所有开奖组合下庄家还能赔付的下注金额中的最小值
+func refreshMaxBetRatio(roomInfo *pb.RoomInfo, bankerMoney int64, odds *baccaratOdds) {
+	if len(roomInfo.BaccaratAllBet) != betAreaNum {
+		roomInfo.BaccaratAllBet = make([]int64, betAreaNum)
+	}
+	roomInfo.MaxBetRatio = make([]int64, betAreaNum)
+	allResults := getAllResultTypes()
+	for areaIndex := 0; areaIndex < betAreaNum; areaIndex++ {
+		maxBet := int64(-1)
+		for _, result := range allResults {
+			// 按100的下注计算这个区域的盈利比例
+			_, winPer100 := getAreaResult(areaIndex, 100, result, odds)
+			if winPer100 <= 0 {
+				continue
+			}
+			bankerLose := getBetsNetWin(roomInfo.BaccaratAllBet, result, odds)
+			oneMaxBet := (bankerMoney - bankerLose) * 100 / winPer100
+			if maxBet == -1 || oneMaxBet < maxBet {
+				maxBet = oneMaxBet
+			}
+		}
+		if maxBet < 0 {
+			maxBet = 0
+		}
+		roomInfo.MaxBetRatio[areaIndex] = maxBet
+	}
+}
+
+// getMinChip 获取最小的筹码值
+func getMinChip(roomInfo *pb.RoomInfo) int64 {
+	var minChip int64
+	for _, chipStr := range strings.Split(common.GetRoomConfig(roomInfo, "Chips"), ",") {
+		chip, err := strconv.ParseInt(chipStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		if minChip == 0 || chip < minChip {
+			minChip = chip
+		}
+	}
+	return minChip
+}
+
+// getSystemScore 计算开奖结果下平台的收益（真实玩家输的钱）
+func getSystemScore(roomInfo *pb.RoomInfo, result *baccaratResult, odds *baccaratOdds, bankerIsRobot bool) int64 {
+	var playerNetWin int64
+	var bankerNetWin int64
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetUuid() == roomInfo.GetBankerUuid() {
+			continue
+		}
+		netWin := getBetsNetWin(onePlayer.GetPlayerBets(), result, odds)
+		bankerNetWin -= netWin
+		if !onePlayer.GetIsRobot() {
+			playerNetWin += netWin
+		}
+	}
+	score := -playerNetWin
+	if !bankerIsRobot {
+		score -= bankerNetWin
+	}
+	return score
+}
+
+// dealByControl 根据血池状态从牌靴中发一局牌
+// 不控制时从牌靴顶部发牌，控制时在牌靴前面的若干个位置中选择平台收益最高（或最低）的位置发牌
+// 返回值：开牌结果，发牌后剩余的牌靴
+func dealByControl(roomInfo *pb.RoomInfo, cardHeap []*pb.Poker, bloodState pb.BloodSlotStatus, odds *baccaratOdds) (*baccaratResult, []*pb.Poker) {
+	bestOffset := 0
+	bestResult, bestUsedNum := dealBaccarat(cardHeap)
+	if bloodState == pb.BloodSlotStatus_BloodSlotStatus_Win || bloodState == pb.BloodSlotStatus_BloodSlotStatus_Lose {
+		bankerIsRobot := isBankerIsRobot(roomInfo)
+		bestScore := getSystemScore(roomInfo, bestResult, odds, bankerIsRobot)
+		for offset := 1; offset < controlTryNum; offset++ {
+			result, usedNum := dealBaccarat(cardHeap[offset:])
+			if result == nil {
+				break
+			}
+			score := getSystemScore(roomInfo, result, odds, bankerIsRobot)
+			if (bloodState == pb.BloodSlotStatus_BloodSlotStatus_Win && score > bestScore) ||
+				(bloodState == pb.BloodSlotStatus_BloodSlotStatus_Lose && score < bestScore) {
+				bestScore = score
+				bestOffset = offset
+				bestResult = result
+				bestUsedNum = usedNum
+			}
+		}
+	}
+	// 从牌靴中移除用掉的牌
+	remainHeap := make([]*pb.Poker, 0, len(cardHeap)-bestUsedNum)
+	remainHeap = append(remainHeap, cardHeap[:bestOffset]...)
+	remainHeap = append(remainHeap, cardHeap[bestOffset+bestUsedNum:]...)
+	return bestResult, remainHeap
+}
+
+// isBankerIsRobot 判断庄家是否是机器人
+func isBankerIsRobot(roomInfo *pb.RoomInfo) bool {
+	if roomInfo.GetBankerUuid() == "systemBanker" {
+		return true
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo == nil {
+		return true
+	}
+	return bankerInfo.GetIsRobot()
+}
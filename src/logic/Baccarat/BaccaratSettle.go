@@ -0,0 +1,297 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["BaccaratSettle"] = &BaccaratSettle{}
+}
+
+// 路单最多保存的局数
+const maxWinInfoNum = 100
+
+// BaccaratSettle 百家乐游戏的结算组件，用于处理开牌和结算阶段的逻辑
+type BaccaratSettle struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *BaccaratSettle) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *BaccaratSettle) Start() {
+	obj.Base.Start()
+}
+
+// Drive 百家乐结算组件主驱动
+func (obj *BaccaratSettle) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	return common.HundredGameSettleDiver(request, obj.realDrive)
+}
+
+// realDrive 百家乐结算组件主logic
+func (obj *BaccaratSettle) realDrive(request *pb.RoomInfo) *pb.ErrorMessage {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+
+	// 获取抽数比例
+	commissionStr := common.GetRoomConfig(request, "Commission")
+	commission, err := strconv.ParseInt(commissionStr, 10, 64)
+	if err != nil {
+		common.LogError("BaccaratSettle Drive commissionStr has err", err)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	odds, msgErr := getBaccaratOdds(request)
+	if msgErr != nil {
+		return msgErr
+	}
+
+	// 1.开牌，按照补牌规则从牌靴中发牌，血池需要控制时选择合适的发牌位置
+	cardHeap := request.GetPokerCardHeap()
+	if len(cardHeap) < maxRoundCardNum {
+		deckNum, err := strconv.Atoi(common.GetRoomConfig(request, "DeckNum"))
+		if err != nil || deckNum <= 0 {
+			common.LogError("BaccaratSettle Drive DeckNum has err", err)
+			return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		cardHeap = common.GetShufflePokerHeap(deckNum)
+		request.BaccaratWinInfos = nil
+	}
+	bloodState := common.BloodGetState(request.GetGameType(), request.GetGameScene())
+	result, remainHeap := dealByControl(request, cardHeap, bloodState, odds)
+	// 剩余的牌留在牌靴中给下一局使用
+	request.PokerCardHeap = remainHeap
+	request.ZhuangPoker = result.zhuangPoker
+	request.XianPoker = result.xianPoker
+
+	// 保存路单，和与庄闲的牌区一致
+	winInfo := &pb.BaccaratWinInfo{
+		WinArea:     pb.BaccaratCardArea(result.winner),
+		Winner:      result.winner,
+		ZhuangPoker: result.zhuangPoker,
+		XianPoker:   result.xianPoker,
+	}
+	request.BaccaratWinInfos = append(request.BaccaratWinInfos, winInfo)
+	if len(request.BaccaratWinInfos) > maxWinInfoNum {
+		request.BaccaratWinInfos = request.BaccaratWinInfos[len(request.BaccaratWinInfos)-maxWinInfoNum:]
+	}
+
+	// 推送开牌结果和路单
+	pushPoker := &pb.PushBaccaratPoker{
+		RoomId:      request.GetUuid(),
+		ZhuangPoker: request.ZhuangPoker,
+		XianPoker:   request.XianPoker,
+	}
+	common.RoomBroadcast(request, pushPoker)
+	pushWinInfos := &pb.PushBaccaratWinInfos{
+		RoomId:           request.GetUuid(),
+		BaccaratWinInfos: request.BaccaratWinInfos,
+	}
+	common.RoomBroadcast(request, pushWinInfos)
+
+	// 2.对闲家进行结算
+	var bankerWinBalance int64
+	for _, onePlayer := range request.PlayerInfo {
+		if onePlayer.Uuid == "" || onePlayer.Uuid == request.BankerUuid {
+			continue
+		}
+		if onePlayer.PlayerRoomState != pb.PlayerRoomState_PlayerRoomStatePlay {
+			continue
+		}
+		bankerWinBalance -= obj.settlePlayer(onePlayer, result, odds, commission)
+	}
+
+	// 3.庄家输赢
+	bankerWinBalance = obj.compensation(request, bankerWinBalance)
+	bankerInfo := common.GetRoomPlayerInfo(request, request.BankerUuid)
+	if bankerInfo != nil {
+		water := int64(0)
+		// 计算庄家税收
+		if bankerWinBalance > 0 {
+			water = bankerWinBalance * commission / 100
+			bankerWinBalance -= water
+		}
+		bankerInfo.Balance += bankerWinBalance
+		bankerInfo.WinOrLose = bankerWinBalance
+		bankerInfo.HundredWaterBill = common.AbsInt64(bankerWinBalance)
+		bankerInfo.HundredCommission = water
+	}
+
+	// 4.更新血池
+	var score int64
+	for _, onePlayer := range request.PlayerInfo {
+		if onePlayer.IsRobot || onePlayer.Uuid == "" {
+			continue
+		}
+		score -= onePlayer.WinOrLose + onePlayer.HundredCommission
+	}
+	msgErr = common.BloodIncrease(score, request.GetGameType(), request.GetGameScene())
+	if msgErr != nil {
+		common.LogError("BaccaratSettle Drive BloodIncrease has err:", msgErr)
+		return msgErr
+	}
+
+	// 5.修改玩家真实的Money
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.Uuid == "" || onePlayer.HundredWaterBill == 0 {
+			continue
+		}
+		// 后面协程操作，为避免错误在此处提取金额
+		addMoney := onePlayer.GetGetBonus() + onePlayer.GetWinOrLose()
+		// 机器人不记录游戏记录
+		var gameRecord *pb.GameRecordReport
+		if !onePlayer.IsRobot {
+			gameRecord = obj.getGameRecord(request, onePlayer, winInfo, bankerWinBalance, nowTime)
+		}
+		go obj.saveMoney(request, onePlayer, addMoney, gameRecord)
+	}
+	return nil
+}
+
+// settlePlayer 结算一个闲家，更新玩家的金额、输赢、流水和抽水
+// 返回值：玩家未抽水前的净输赢，用于计算庄家输赢
+func (obj *BaccaratSettle) settlePlayer(onePlayer *pb.RoomPlayerInfo, result *baccaratResult, odds *baccaratOdds, commission int64) int64 {
+	// 返还金额（含本金）
+	var backBalance int64
+	// 个人流水值
+	var waterNum int64
+	// 个人抽水值
+	var commissionNum int64
+	// 未抽水前的净输赢
+	var netWin int64
+	for areaIndex, bet := range onePlayer.PlayerBets {
+		if bet <= 0 {
+			continue
+		}
+		areaBack, winBalance := getAreaResult(areaIndex, bet, result, odds)
+		netWin += areaBack - bet
+		if winBalance > 0 {
+			water := winBalance * commission / 100
+			backBalance += areaBack - water
+			waterNum += winBalance - water
+			commissionNum += water
+			continue
+		}
+		// 输掉的部分（开和时庄闲区域的下注退还）
+		backBalance += areaBack
+		waterNum += bet - areaBack
+	}
+	// 下注时已经从房间金额中扣除，这里加上返还的部分
+	onePlayer.Balance += backBalance
+	onePlayer.WinOrLose += backBalance
+	onePlayer.BaccaratWinMoney = backBalance
+	onePlayer.HundredWaterBill = waterNum
+	onePlayer.HundredCommission = commissionNum
+	return netWin
+}
+
+// compensation 玩家庄家不够赔付时，按照闲家的盈利比例分配庄家的金额
+// 返回值：庄家实际的输赢
+func (obj *BaccaratSettle) compensation(roomInfo *pb.RoomInfo, bankerWinBalance int64) int64 {
+	if bankerWinBalance >= 0 || roomInfo.BankerUuid == "systemBanker" {
+		return bankerWinBalance
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.BankerUuid)
+	if bankerInfo == nil || -bankerWinBalance <= bankerInfo.Balance {
+		return bankerWinBalance
+	}
+	common.LogError("百家乐庄家金币不足结算:", bankerInfo.Balance, bankerWinBalance)
+	loseAmount := -bankerInfo.Balance
+	for _, onePlayer := range roomInfo.PlayerInfo {
+		if onePlayer.WinOrLose <= 0 || onePlayer.Uuid == roomInfo.BankerUuid {
+			continue
+		}
+		// 按照比例计算实际能拿到的盈利
+		realWin := onePlayer.WinOrLose * loseAmount / bankerWinBalance
+		lessNum := onePlayer.WinOrLose - realWin
+		onePlayer.WinOrLose -= lessNum
+		onePlayer.Balance -= lessNum
+		onePlayer.BaccaratWinMoney -= lessNum
+		common.LogError("出现不够赔的情况，用户：", onePlayer.Account, "少赔金额:", lessNum)
+	}
+	return loseAmount
+}
+
+// getGameRecord 生成玩家本局的游戏记录，金额在saveMoney中修改完玩家金币后补全
+func (obj *BaccaratSettle) getGameRecord(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, winInfo *pb.BaccaratWinInfo, bankerWinOrLose int64, settleTime int64) *pb.GameRecordReport {
+	extendData := &pb.GameRecordExtendData{}
+	extendData.ZhuangPoker = roomInfo.GetZhuangPoker()
+	extendData.XianPoker = roomInfo.GetXianPoker()
+	extendData.BaccaratWinArea = winInfo.GetWinArea()
+	extendData.BankerUuid = roomInfo.GetBankerUuid()
+	extendData.BankerWinOrLose = bankerWinOrLose
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo != nil {
+		extendData.BankerShortId = bankerInfo.GetShortId()
+	}
+	// 玩家各区下注
+	extendData.PlayerAllBet = make([]int64, len(onePlayer.GetPlayerBets()))
+	copy(extendData.PlayerAllBet, onePlayer.GetPlayerBets())
+	totalBet := int64(0)
+	for _, bet := range extendData.PlayerAllBet {
+		totalBet += bet
+	}
+
+	gameRecord := &pb.GameRecordReport{}
+	gameRecord.GameType = roomInfo.GetGameType()
+	gameRecord.GameScene = roomInfo.GetGameScene()
+	gameRecord.GameMode = common.GameMode
+	gameRecord.RoomType = roomInfo.GetRoomType()
+	gameRecord.RoomId = roomInfo.GetUuid()
+	gameRecord.RoundId = roomInfo.GetCurrentRoundId()
+	gameRecord.PlayerUuid = onePlayer.GetUuid()
+	gameRecord.PlayerShortId = onePlayer.GetShortId()
+	gameRecord.PlayerAccount = onePlayer.GetAccount()
+	gameRecord.StartTime = roomInfo.GetRoundStartTime()
+	gameRecord.SettleTime = settleTime
+	gameRecord.TotalBet = totalBet
+	gameRecord.WinOrLose = onePlayer.GetWinOrLose()
+	gameRecord.Commission = onePlayer.GetHundredCommission()
+	gameRecord.ExtendData = extendData
+	return gameRecord
+}
+
+// saveMoney 修改玩家金币并推送金币变动
+func (obj *BaccaratSettle) saveMoney(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, addMoney int64, gameRecord *pb.GameRecordReport) {
+	taskConfig := &pb.TaskConfig{}
+	taskConfig.TaskType = pb.TaskType_Task_PlayGame
+	taskConfig.GameType = roomInfo.GetGameType()
+	taskConfig.GameScene = roomInfo.GetGameScene()
+	taskConfig.TaskNum = 1
+	cateGoryList := strings.Split(common.GetRoomConfig(roomInfo, "CateGory"), ",")
+	for _, oneCateGoryStr := range cateGoryList {
+		oneCateGoryInt, err := strconv.Atoi(oneCateGoryStr)
+		if err != nil {
+			common.LogError("BaccaratSettle saveMoney oneCateGoryStr has err", oneCateGoryStr, err)
+		}
+		taskConfig.GameCateGoryType = append(taskConfig.GetGameCateGoryType(), pb.GameCateGoryType(oneCateGoryInt))
+	}
+	afterBalance, msgErr := common.ChangePlayerInfoAfterGameEnd(onePlayer.GetUuid(), addMoney, taskConfig, pb.ResourceChangeReason_BaccaratSettleGold)
+	if msgErr != nil {
+		return
+	}
+	if afterBalance != onePlayer.Balance {
+		common.LogError("BaccaratSettle saveMoney has err: afterBalance != onePlayer.Balance", afterBalance, onePlayer.Balance, "输赢：", onePlayer.WinOrLose)
+	}
+	// 推送游戏记录
+	if gameRecord != nil {
+		gameRecord.BeforeBalance = afterBalance - addMoney
+		gameRecord.SettleBalance = afterBalance
+		msgErr = common.PushGameRecord(gameRecord)
+		if msgErr != nil {
+			common.LogError("BaccaratSettle saveMoney PushGameRecord has err", onePlayer.GetUuid(), msgErr)
+		}
+	}
+	userBalanceChangePush := &pb.PushUserBalanceChange{}
+	userBalanceChangePush.UserId = onePlayer.GetUuid()
+	userBalanceChangePush.Balance = afterBalance
+	common.RoomBroadcast(roomInfo, userBalanceChangePush)
+}
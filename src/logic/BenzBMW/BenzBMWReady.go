@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+)
+
+func init() {
+	common.AllComponentMap["BenzBMWReady"] = &BenzBMWReady{}
+}
+
+// BenzBMWReady 奔驰宝马游戏的准备组件，用于处理准备阶段的逻辑
+type BenzBMWReady struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *BenzBMWReady) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *BenzBMWReady) Start() {
+	obj.Base.Start()
+	common.InitGameConfigTemp(common.BenzBMWGameConfigTemp, pb.GameType_BenzBMW)
+}
+
+// Drive 奔驰宝马准备阶段的主驱动
+func (obj *BenzBMWReady) Drive(request *pb.RoomInfo, extraInfo *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	return common.HundredGameReadyDiver(request, func(roomInfo *pb.RoomInfo) *pb.ErrorMessage {
+		// 下注区域的数量由车标配置决定，公共准备逻辑中没有初始化，这里重置玩家下注
+		slots, msgErr := getBenzBMWSlots(roomInfo)
+		if msgErr != nil {
+			return msgErr
+		}
+		for _, onePlayer := range roomInfo.GetPlayerInfo() {
+			onePlayer.PlayerBets = make([]int64, len(slots.odds))
+		}
+		roomInfo.BenzBMWGameInfo = nil
+		return nil
+	})
+}
@@ -0,0 +1,156 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+)
+
+func init() {
+	common.AllComponentMap["BenzBMWRoute"] = &BenzBMWRoute{}
+}
+
+// BenzBMWRoute 奔驰宝马游戏的功能中转组件，其他服务通过这个组件中转奔驰宝马协议到具体逻辑组件中
+type BenzBMWRoute struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *BenzBMWRoute) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *BenzBMWRoute) Start() {
+	obj.Base.Start()
+
+	// 需要的配置进行模版初始化
+	initGlobleConfigNameArr := []string{
+		"BenzBMWServerNum",
+	}
+	err := common.InitGlobleConfigTemp(initGlobleConfigNameArr)
+	if err != nil {
+		panic("BenzBMWRoute initGlobleConfigNameArr has err")
+	}
+}
+
+// Do 中转协议的具体逻辑(玩家的请求操作通过do路由到driver）
+func (obj *BenzBMWRoute) Do(request *pb.BenzBMWDoRequest, extroInfo *pb.MessageExtroInfo) (proto.Message, *pb.ErrorMessage) {
+	doType := request.GetDoType()
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("BenzBMWRoute Do uuid == nil")
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotLogin, "")
+	}
+	// 选择一个driver线路
+	originMessage := request.GetDoMessageContent()
+	serverNumConfig := common.Configer.GetGlobal("BenzBMWServerNum")
+	serverNumStr := serverNumConfig.GetValue()
+	serverNum, err := strconv.Atoi(serverNumStr)
+	if err != nil {
+		common.LogError("BenzBMWRoute Do Atoi(serverNumStr) has err", serverNumStr)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 获取玩家信息
+	loadPlayerRequest := &pb.LoadPlayerRequest{}
+	loadPlayerRequest.Uuid = uuid
+	loadPlayerReply := &pb.LoadPlayerReply{}
+	msgErr := common.Router.Call("PlayerInfo", "LoadPlayer", loadPlayerRequest, loadPlayerReply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	playerInfo := loadPlayerReply.GetPlayerInfo()
+
+	var requestMessage proto.Message
+	var replyMessage proto.Message
+	//玩家线路
+	var driverServerIndex string
+	//方法名
+	var methodName string
+	switch doType {
+	//进入房间--与其他逻辑不同
+	case pb.BenzBMWDoType_BenzBMW_JoinRoom:
+		requestMessage = &pb.GameJoinRoomRequest{}
+		replyMessage = &pb.GameJoinRoomReply{}
+
+		err := ptypes.UnmarshalAny(originMessage, requestMessage)
+		if err != nil {
+			common.LogError("BenzBMWRoute Do joinRoom ptypes.UnmarshalAny has err", doType, uuid, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		driverServerIndex, msgErr = common.GameJoinRoomJudge(playerInfo, serverNum, requestMessage.(*pb.GameJoinRoomRequest), pb.GameType_BenzBMW)
+		if msgErr != nil {
+			return nil, msgErr
+		}
+
+		methodName = "RequestJoinRoom"
+	//退出房间
+	case pb.BenzBMWDoType_BenzBMW_ExitRoom:
+		requestMessage = &pb.GameExitRoomRequest{}
+		replyMessage = &pb.GameExitRoomReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestExitRoom"
+	//玩家下注
+	case pb.BenzBMWDoType_BenzBMW_PlayerBet:
+		requestMessage = &pb.BenzBMWBetRequest{}
+		replyMessage = &pb.BenzBMWBetReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestPlayerBet"
+	//玩家上庄
+	case pb.BenzBMWDoType_BenzBMW_UpBanker:
+		requestMessage = &pb.BenzBMWUpBankerRequest{}
+		replyMessage = &pb.BenzBMWUpBankerReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestUpBanker"
+	//玩家下庄
+	case pb.BenzBMWDoType_BenzBMW_DownBanker:
+		requestMessage = &pb.BenzBMWDownBankerRequest{}
+		replyMessage = &pb.BenzBMWDownBankerReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestDownBanker"
+
+	default:
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	err = ptypes.UnmarshalAny(originMessage, requestMessage)
+	if err != nil {
+		common.LogError("BenzBMWRoute Do request ptypes.UnmarshalAny has err", doType, uuid, err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	componentName := "BenzBMWDriver" + driverServerIndex
+	// common.Router.Call 都是路由到 driver,  driver 再分发给各个组件
+	msgErr = common.Router.Call(componentName, methodName, requestMessage, replyMessage, extroInfo)
+	if msgErr != nil {
+		common.LogError(msgErr)
+		return nil, msgErr
+	}
+	return replyMessage, nil
+}
+
+// SyncRoomPlayerInfo 同步房间玩家信息
+func (obj *BenzBMWRoute) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo, extroInfo *pb.MessageExtroInfo) (*pb.EmptyMessage, *pb.ErrorMessage) {
+	reply := &pb.EmptyMessage{}
+	componentName := "BenzBMWDriver" + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "SyncRoomPlayerInfo", request, reply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	return reply, nil
+}
+
+// DelRoom 刪除房間
+func (obj *BenzBMWRoute) DelRoom(request *pb.DelRoomByGameTypeRequest, extraInfo *pb.MessageExtroInfo) (*pb.DelRoomByGameTypeReply, *pb.ErrorMessage) {
+	reply := &pb.DelRoomByGameTypeReply{}
+	componentName := "BenzBMWDriver" + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "DelRoom", request, reply, extraInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
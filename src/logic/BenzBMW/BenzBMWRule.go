@@ -0,0 +1,211 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+)
+
+// 血池控制时最多尝试的开奖次数
+const controlTryNum = 30
+
+// benzBMWSlots 奔驰宝马转盘的车标配置，每个车标对应一个下注区域
+type benzBMWSlots struct {
+	// 各车标的赔率（含本金），下标为下注区域-1
+	odds []int64
+	// 各车标开出的权重
+	weights []int32
+}
+
+// getBenzBMWSlots 获取房间的车标赔率和权重配置
+// 配置SlotOdds和SlotWeights都是逗号分隔的列表，长度就是下注区域的数量
+func getBenzBMWSlots(roomInfo *pb.RoomInfo) (*benzBMWSlots, *pb.ErrorMessage) {
+	oddsStr := common.GetRoomConfig(roomInfo, "SlotOdds")
+	weightsStr := common.GetRoomConfig(roomInfo, "SlotWeights")
+	oddsList := strings.Split(oddsStr, ",")
+	weightList := strings.Split(weightsStr, ",")
+	if len(oddsList) < 2 || len(oddsList) != len(weightList) {
+		common.LogError("getBenzBMWSlots config length has err", oddsStr, weightsStr)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	slots := &benzBMWSlots{
+		odds:    make([]int64, len(oddsList)),
+		weights: make([]int32, len(weightList)),
+	}
+	var allWeight int64
+	for index := range oddsList {
+		odds, err := strconv.ParseInt(oddsList[index], 10, 64)
+		if err != nil || odds <= 1 {
+			common.LogError("getBenzBMWSlots SlotOdds has err", oddsStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		weight, err := strconv.ParseInt(weightList[index], 10, 32)
+		if err != nil || weight < 0 {
+			common.LogError("getBenzBMWSlots SlotWeights has err", weightsStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		slots.odds[index] = odds
+		slots.weights[index] = int32(weight)
+		allWeight += weight
+	}
+	if allWeight <= 0 {
+		common.LogError("getBenzBMWSlots SlotWeights all zero", weightsStr)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return slots, nil
+}
+
+// getAreaResult 计算某个区域的下注在开奖结果下的返还金额（含本金）和盈利金额
+// 返回值：返还金额，盈利金额
+func getAreaResult(areaIndex int, bet int64, winIndex int, slots *benzBMWSlots) (int64, int64) {
+	if bet <= 0 || areaIndex != winIndex {
+		return 0, 0
+	}
+	backBalance := bet * slots.odds[areaIndex]
+	return backBalance, backBalance - bet
+}
+
+// getBetsNetWin 计算一组下注在开奖结果下的净输赢（未抽水）
+func getBetsNetWin(bets []int64, winIndex int, slots *benzBMWSlots) int64 {
+	var netWin int64
+	for areaIndex, bet := range bets {
+		if bet <= 0 {
+			continue
+		}
+		backBalance, _ := getAreaResult(areaIndex, bet, winIndex, slots)
+		netWin += backBalance - bet
+	}
+	return netWin
+}
+
+// getBankerMoney 获取当前庄家可用于赔付的金额
+func getBankerMoney(roomInfo *pb.RoomInfo) (int64, *pb.ErrorMessage) {
+	if roomInfo.GetBankerUuid() == "systemBanker" {
+		defaultMoneyStr := common.GetRoomConfig(roomInfo, "DefaultBankerMoney")
+		defaultMoney, err := strconv.ParseInt(defaultMoneyStr, 10, 64)
+		if err != nil {
+			common.LogError("getBankerMoney DefaultBankerMoney has err", defaultMoneyStr, err)
+			return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		return defaultMoney, nil
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo == nil {
+		return 0, nil
+	}
+	return bankerInfo.GetBalance(), nil
+}
+
+// initGameInfo 初始化本局的转盘信息（区域数量、赔率、各区域总注和庄家）
+func initGameInfo(roomInfo *pb.RoomInfo, slots *benzBMWSlots, bankerMoney int64) {
+	gameInfo := &pb.BenzBMWGameInfo{
+		RoomUuid:      roomInfo.GetUuid(),
+		BetAreaCount:  int32(len(slots.odds)),
+		BetAreaRate:   make([]int32, len(slots.odds)),
+		BetAreaAmount: make([]int64, len(slots.odds)),
+		BankerUuid:    roomInfo.GetBankerUuid(),
+		BankerAmount:  bankerMoney,
+	}
+	for index, odds := range slots.odds {
+		gameInfo.BetAreaRate[index] = int32(odds)
+	}
+	roomInfo.BenzBMWGameInfo = gameInfo
+}
+
+// refreshMaxBetRatio 根据庄家金额和房间总注刷新各区域的限红
+// 每个区域的限红是这个车标开出时庄家还能赔付的下注金额
+func refreshMaxBetRatio(roomInfo *pb.RoomInfo, bankerMoney int64, slots *benzBMWSlots) {
+	betAreaNum := len(slots.odds)
+	gameInfo := roomInfo.GetBenzBMWGameInfo()
+	if gameInfo == nil || len(gameInfo.BetAreaAmount) != betAreaNum {
+		initGameInfo(roomInfo, slots, bankerMoney)
+		gameInfo = roomInfo.GetBenzBMWGameInfo()
+	}
+	roomInfo.MaxBetRatio = make([]int64, betAreaNum)
+	for areaIndex := 0; areaIndex < betAreaNum; areaIndex++ {
+		bankerLose := getBetsNetWin(gameInfo.BetAreaAmount, areaIndex, slots)
+		maxBet := (bankerMoney - bankerLose) / (slots.odds[areaIndex] - 1)
+		if maxBet < 0 {
+			maxBet = 0
+		}
+		roomInfo.MaxBetRatio[areaIndex] = maxBet
+	}
+}
+
+// getMinChip 获取最小的筹码值
+func getMinChip(roomInfo *pb.RoomInfo) int64 {
+	var minChip int64
+	for _, chipStr := range strings.Split(common.GetRoomConfig(roomInfo, "Chips"), ",") {
+		chip, err := strconv.ParseInt(chipStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		if minChip == 0 || chip < minChip {
+			minChip = chip
+		}
+	}
+	return minChip
+}
+
+// getSystemScore 计算开奖结果下平台的收益（真实玩家输的钱）
+func getSystemScore(roomInfo *pb.RoomInfo, winIndex int, slots *benzBMWSlots, bankerIsRobot bool) int64 {
+	var playerNetWin int64
+	var bankerNetWin int64
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetUuid() == roomInfo.GetBankerUuid() {
+			continue
+		}
+		netWin := getBetsNetWin(onePlayer.GetPlayerBets(), winIndex, slots)
+		bankerNetWin -= netWin
+		if !onePlayer.GetIsRobot() {
+			playerNetWin += netWin
+		}
+	}
+	score := -playerNetWin
+	if !bankerIsRobot {
+		score -= bankerNetWin
+	}
+	return score
+}
+
+// drawByControl 根据车标权重抽取开奖的车标
+// 血池需要控制时多次按权重抽取，选择平台收益最高（或最低）的结果
+// 返回值：开奖的下注区域下标
+func drawByControl(roomInfo *pb.RoomInfo, bloodState pb.BloodSlotStatus, slots *benzBMWSlots) (int, *pb.ErrorMessage) {
+	bestIndex, err := common.GetRandomIndexByWeight(slots.weights)
+	if err != nil {
+		common.LogError("BenzBMW drawByControl GetRandomIndexByWeight has err", err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	if bloodState != pb.BloodSlotStatus_BloodSlotStatus_Win && bloodState != pb.BloodSlotStatus_BloodSlotStatus_Lose {
+		return bestIndex, nil
+	}
+	bankerIsRobot := isBankerIsRobot(roomInfo)
+	bestScore := getSystemScore(roomInfo, bestIndex, slots, bankerIsRobot)
+	for try := 1; try < controlTryNum; try++ {
+		winIndex, err := common.GetRandomIndexByWeight(slots.weights)
+		if err != nil {
+			continue
+		}
+		score := getSystemScore(roomInfo, winIndex, slots, bankerIsRobot)
+		if (bloodState == pb.BloodSlotStatus_BloodSlotStatus_Win && score > bestScore) ||
+			(bloodState == pb.BloodSlotStatus_BloodSlotStatus_Lose && score < bestScore) {
+			bestScore = score
+			bestIndex = winIndex
+		}
+	}
+	return bestIndex, nil
+}
+
+// isBankerIsRobot 判断庄家是否是机器人
+func isBankerIsRobot(roomInfo *pb.RoomInfo) bool {
+	if roomInfo.GetBankerUuid() == "systemBanker" {
+		return true
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo == nil {
+		return true
+	}
+	return bankerInfo.GetIsRobot()
+}
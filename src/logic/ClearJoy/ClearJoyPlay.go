@@ -0,0 +1,235 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	uuid "github.com/satori/go.uuid"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["ClearJoyPlay"] = &ClearJoyPlay{}
+}
+
+// ClearJoyPlay 消消乐的逻辑组件，处理进入、退出和下注消除
+// 消消乐没有房间，玩家进入后只在玩家信息上记录所在的游戏和场次
+type ClearJoyPlay struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *ClearJoyPlay) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *ClearJoyPlay) Start() {
+	obj.Base.Start()
+	common.InitGameConfigTemp(common.ClearJoyGameConfigTemp, pb.GameType_ClearJoy)
+}
+
+// RequestJoinRoom 玩家进入消消乐
+func (obj *ClearJoyPlay) RequestJoinRoom(request *pb.GameJoinRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameJoinRoomReply, *pb.ErrorMessage) {
+	reply := &pb.GameJoinRoomReply{}
+	playerInfo, msgErr := loadPlayer(extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	if playerInfo.GetRoomId() != "" || (playerInfo.GetGameType() != pb.GameType_None && playerInfo.GetGameType() != pb.GameType_ClearJoy) {
+		common.LogError("ClearJoyPlay RequestJoinRoom PlayerInOtherGame", playerInfo.GetUuid(), playerInfo.GetGameType())
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerInOtherGame, "")
+	}
+	gameKeyMap := common.Configer.GetGameConfigByGameTypeAndScene(pb.GameType_ClearJoy, request.GetGameScene())
+	if gameKeyMap == nil {
+		common.LogError("ClearJoyPlay RequestJoinRoom GetGameConfigByGameTypeAndScene gameKeyMap == nil", request.GetGameScene())
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 判断进入金额
+	_, msgErr = common.GameJoinRoomJudge(playerInfo, 1, request, pb.GameType_ClearJoy)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	playerInfo.GameType = pb.GameType_ClearJoy
+	playerInfo.GameScene = request.GetGameScene()
+	msgErr = savePlayer(playerInfo, false, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	roomInfo := &pb.RoomInfo{}
+	roomInfo.GameType = pb.GameType_ClearJoy
+	roomInfo.GameScene = playerInfo.GetGameScene()
+	for _, oneConfig := range gameKeyMap.Map {
+		roomInfo.Config = append(roomInfo.Config, oneConfig)
+	}
+	reply.RoomInfo = roomInfo
+	return reply, nil
+}
+
+// RequestExitRoom 玩家退出消消乐
+func (obj *ClearJoyPlay) RequestExitRoom(request *pb.GameExitRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameExitRoomReply, *pb.ErrorMessage) {
+	playerInfo, msgErr := loadPlayer(extroInfo)
+	if msgErr != nil {
+		return &pb.GameExitRoomReply{}, msgErr
+	}
+	request.GameType = pb.GameType_ClearJoy
+	return common.GameExitSingleRoom(request, playerInfo, extroInfo, func(playerInfo *pb.PlayerInfo) *pb.ErrorMessage {
+		return nil
+	})
+}
+
+// RequestClearPic 玩家下注消除
+// 图和每次掉落填充都由服务器用随机种子生成，按血池状态选择种子，结算后上报游戏记录
+func (obj *ClearJoyPlay) RequestClearPic(request *pb.ClearJoyClearPicRequest, extroInfo *pb.MessageExtroInfo) (*pb.ClearJoyClearPicReply, *pb.ErrorMessage) {
+	reply := &pb.ClearJoyClearPicReply{}
+	playerInfo, msgErr := loadPlayer(extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	if playerInfo.GetGameType() != pb.GameType_ClearJoy {
+		common.LogError("ClearJoyPlay RequestClearPic player not join ClearJoy", playerInfo.GetUuid(), playerInfo.GetGameType())
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotJoinRoom, "")
+	}
+	gameScene := playerInfo.GetGameScene()
+	wager := request.GetWagerNum()
+	wagerList := getClearJoyConfigInt64List(gameScene, "WagerList")
+	isValidWager := false
+	for _, oneWager := range wagerList {
+		if oneWager == wager {
+			isValidWager = true
+			break
+		}
+	}
+	if !isValidWager {
+		common.LogError("ClearJoyPlay RequestClearPic WagerNum is invalid", playerInfo.GetUuid(), wager)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidRequest, "")
+	}
+	if playerInfo.GetBalance() < wager {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BalanceNotEnough, "")
+	}
+	width := int32(getClearJoyConfigInt64(gameScene, "ImageWidth"))
+	height := int32(getClearJoyConfigInt64(gameScene, "ImageHeight"))
+	if width < 3 || height < 3 {
+		common.LogError("ClearJoyPlay RequestClearPic image size config has err", gameScene, width, height)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	startTime := time.Now().Unix()
+	beforeBalance := playerInfo.GetBalance()
+	msgErr = common.AddResource(pb.RewardType_Golden, -wager, playerInfo, false, extroInfo, false, pb.ResourceChangeReason_ClearJoyWager)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	// 开奖，机器人不走血池
+	bloodState := pb.BloodSlotStatus_BloodSlotStatus_None
+	if !playerInfo.GetIsRobot() {
+		bloodState = common.BloodGetState(pb.GameType_ClearJoy, gameScene)
+	}
+	chainMultiples := getClearJoyConfigInt64List(gameScene, "ChainMultiples")
+	maxChainNum := int(getClearJoyConfigInt64(gameScene, "MaxChainNum"))
+	controlTimes := int(getClearJoyConfigInt64(gameScene, "ControlTimes"))
+	result := playClearJoyWithBlood(wager, width, height, chainMultiples, maxChainNum, bloodState, controlTimes)
+
+	if result.totalWin > 0 {
+		msgErr = common.AddResource(pb.RewardType_Golden, result.totalWin, playerInfo, false, extroInfo, true, pb.ResourceChangeReason_ClearJoySettleGold)
+		if msgErr != nil {
+			common.LogError("ClearJoyPlay RequestClearPic AddResource win has err", playerInfo.GetUuid(), result.totalWin, msgErr)
+		}
+	}
+	msgErr = savePlayer(playerInfo, true, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	if !playerInfo.GetIsRobot() {
+		msgErr = common.BloodIncrease(wager-result.totalWin, pb.GameType_ClearJoy, gameScene)
+		if msgErr != nil {
+			common.LogError("ClearJoyPlay RequestClearPic BloodIncrease has err", msgErr)
+		}
+	}
+
+	gameRecord := &pb.GameRecordReport{}
+	gameRecord.GameType = pb.GameType_ClearJoy
+	gameRecord.GameScene = gameScene
+	gameRecord.GameMode = common.GameMode
+	gameRecord.RoundId = uuid.NewV4().String()
+	gameRecord.PlayerUuid = playerInfo.GetUuid()
+	gameRecord.PlayerShortId = playerInfo.GetShortId()
+	gameRecord.PlayerAccount = playerInfo.GetAccount()
+	gameRecord.StartTime = startTime
+	gameRecord.SettleTime = time.Now().Unix()
+	gameRecord.TotalBet = wager
+	gameRecord.WinOrLose = result.totalWin - wager
+	gameRecord.BeforeBalance = beforeBalance
+	gameRecord.SettleBalance = playerInfo.GetBalance()
+	msgErr = common.PushGameRecord(gameRecord)
+	if msgErr != nil {
+		common.LogError("ClearJoyPlay RequestClearPic PushGameRecord has err", playerInfo.GetUuid(), msgErr)
+	}
+	common.LogDebug("ClearJoyPlay RequestClearPic", playerInfo.GetUuid(), gameRecord.GetRoundId(), "种子：", result.seed, "赢：", result.totalWin)
+
+	reply.Seed = result.seed
+	reply.AllChainInfo = result.allChainInfo
+	reply.FinalImage = result.finalImage
+	reply.Win = result.totalWin
+	return reply, nil
+}
+
+// getClearJoyConfigInt64 获取消消乐的int配置，没有配置或者配置错误时返回0
+func getClearJoyConfigInt64(gameScene int32, name string) int64 {
+	config := common.Configer.GetGameConfig(pb.GameType_ClearJoy, gameScene, name)
+	if config == nil {
+		common.LogError("ClearJoyPlay getClearJoyConfigInt64 config == nil", gameScene, name)
+		return 0
+	}
+	value, err := strconv.ParseInt(config.GetValue(), 10, 64)
+	if err != nil {
+		common.LogError("ClearJoyPlay getClearJoyConfigInt64 ParseInt has err", gameScene, name, config.GetValue(), err)
+		return 0
+	}
+	return value
+}
+
+// getClearJoyConfigInt64List 获取消消乐逗号隔开的int列表配置，配置错误时返回nil
+func getClearJoyConfigInt64List(gameScene int32, name string) []int64 {
+	config := common.Configer.GetGameConfig(pb.GameType_ClearJoy, gameScene, name)
+	if config == nil {
+		common.LogError("ClearJoyPlay getClearJoyConfigInt64List config == nil", gameScene, name)
+		return nil
+	}
+	var valueList []int64
+	for _, oneStr := range strings.Split(config.GetValue(), ",") {
+		value, err := strconv.ParseInt(strings.TrimSpace(oneStr), 10, 64)
+		if err != nil {
+			common.LogError("ClearJoyPlay getClearJoyConfigInt64List ParseInt has err", gameScene, name, config.GetValue(), err)
+			return nil
+		}
+		valueList = append(valueList, value)
+	}
+	return valueList
+}
+
+// loadPlayer 获取请求玩家的信息
+func loadPlayer(extroInfo *pb.MessageExtroInfo) (*pb.PlayerInfo, *pb.ErrorMessage) {
+	loadPlayerRequest := &pb.LoadPlayerRequest{}
+	loadPlayerRequest.Uuid = extroInfo.GetUserId()
+	loadPlayerReply := &pb.LoadPlayerReply{}
+	msgErr := common.Router.Call("PlayerInfo", "LoadPlayer", loadPlayerRequest, loadPlayerReply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	return loadPlayerReply.GetPlayerInfo(), nil
+}
+
+// savePlayer 保存玩家信息
+func savePlayer(playerInfo *pb.PlayerInfo, forceSave bool, extroInfo *pb.MessageExtroInfo) *pb.ErrorMessage {
+	savePlayerRequest := &pb.SavePlayerRequest{}
+	savePlayerRequest.PlayerInfo = playerInfo
+	savePlayerRequest.ForceSave = forceSave
+	return common.Router.Call("PlayerInfo", "SavePlayer", savePlayerRequest, &pb.EmptyMessage{}, extroInfo)
+}
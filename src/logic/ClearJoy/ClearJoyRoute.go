@@ -0,0 +1,79 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+func init() {
+	common.AllComponentMap["ClearJoyRoute"] = &ClearJoyRoute{}
+}
+
+// ClearJoyRoute 消消乐的功能中转组件，其他服务通过这个组件中转消消乐协议到具体逻辑组件中
+// 消消乐是单人游戏，没有房间和driver，所有请求都直接转到ClearJoyPlay
+type ClearJoyRoute struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *ClearJoyRoute) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *ClearJoyRoute) Start() {
+	obj.Base.Start()
+}
+
+// Do 中转协议的具体逻辑
+func (obj *ClearJoyRoute) Do(request *pb.ClearJoyDoRequest, extroInfo *pb.MessageExtroInfo) (proto.Message, *pb.ErrorMessage) {
+	doType := request.GetDoType()
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("ClearJoyRoute Do uuid == nil")
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotLogin, "")
+	}
+	originMessage := request.GetDoMessageContent()
+
+	var requestMessage proto.Message
+	var replyMessage proto.Message
+	//方法名
+	var methodName string
+	switch doType {
+	//进入游戏
+	case pb.ClearJoyDoType_ClearJoyDo_JoinRoom:
+		requestMessage = &pb.GameJoinRoomRequest{}
+		replyMessage = &pb.GameJoinRoomReply{}
+		methodName = "RequestJoinRoom"
+	//退出游戏
+	case pb.ClearJoyDoType_ClearJoyDo_ExitRoom:
+		requestMessage = &pb.GameExitRoomRequest{}
+		replyMessage = &pb.GameExitRoomReply{}
+		methodName = "RequestExitRoom"
+	//下注消除
+	case pb.ClearJoyDoType_ClearJoyDo_ClearPic:
+		requestMessage = &pb.ClearJoyClearPicRequest{}
+		replyMessage = &pb.ClearJoyClearPicReply{}
+		methodName = "RequestClearPic"
+	//消消乐是单人游戏，没有准备状态，ChangeState和未知操作一样返回错误
+	default:
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	err := ptypes.UnmarshalAny(originMessage, requestMessage)
+	if err != nil {
+		common.LogError("ClearJoyRoute Do request ptypes.UnmarshalAny has err", doType, uuid, err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	msgErr := common.Router.Call("ClearJoyPlay", methodName, requestMessage, replyMessage, extroInfo)
+	if msgErr != nil {
+		common.LogError(msgErr)
+		return nil, msgErr
+	}
+	return replyMessage, nil
+}
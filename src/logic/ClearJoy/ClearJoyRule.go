@@ -0,0 +1,236 @@
+package logic
+
+import (
+	"math/rand"
+	"sort"
+
+	pb "gameServer-demo/src/grpc"
+)
+
+// clearJoyPic 消消乐的一种图案
+type clearJoyPic struct {
+	// 图案编号，就是图本体中的值
+	pic int64
+	// 掉落填充时出现的权重
+	weight int
+	// 连成n个时的赔率（下标为n），单位：下注的百分之一
+	odds []int64
+}
+
+// clearJoyPics 所有的图案，越往后越少见，赔率越高
+// 6x6的图、连消倍数为1,2,3,5,8时，不算血池控制返奖率约96%
+var clearJoyPics = []*clearJoyPic{
+	{pic: 1, weight: 30, odds: []int64{0, 0, 0, 11, 22, 45}},
+	{pic: 2, weight: 25, odds: []int64{0, 0, 0, 17, 32, 65}},
+	{pic: 3, weight: 20, odds: []int64{0, 0, 0, 22, 43, 86}},
+	{pic: 4, weight: 15, odds: []int64{0, 0, 0, 32, 65, 130}},
+	{pic: 5, weight: 10, odds: []int64{0, 0, 0, 43, 108, 215}},
+	{pic: 6, weight: 5, odds: []int64{0, 0, 0, 108, 215, 540}},
+}
+
+// clearJoyImage 一张图，按行存放，索引 = y * width + x，y为0的是最上面一行
+type clearJoyImage struct {
+	width  int32
+	height int32
+	body   []int64
+}
+
+// clearJoyResult 一次下注的完整结果
+type clearJoyResult struct {
+	// 随机种子
+	seed int64
+	// 依次每次消除的信息
+	allChainInfo []*pb.ClearJoyChainInfo
+	// 最后的图
+	finalImage *pb.ClearJoyImage
+	// 总赢分
+	totalWin int64
+}
+
+// randomPic 按权重随机一个图案
+func randomPic(r *rand.Rand) int64 {
+	totalWeight := 0
+	for _, onePic := range clearJoyPics {
+		totalWeight += onePic.weight
+	}
+	num := r.Intn(totalWeight)
+	for _, onePic := range clearJoyPics {
+		if num < onePic.weight {
+			return onePic.pic
+		}
+		num -= onePic.weight
+	}
+	return clearJoyPics[0].pic
+}
+
+// getPicOdds 获取图案连成num个时的赔率，超过5个按5个算
+func getPicOdds(pic int64, num int) int64 {
+	for _, onePic := range clearJoyPics {
+		if onePic.pic != pic {
+			continue
+		}
+		if num >= len(onePic.odds) {
+			num = len(onePic.odds) - 1
+		}
+		return onePic.odds[num]
+	}
+	return 0
+}
+
+// newImage 随机生成一张图
+func newImage(r *rand.Rand, width int32, height int32) *clearJoyImage {
+	image := &clearJoyImage{
+		width:  width,
+		height: height,
+		body:   make([]int64, width*height),
+	}
+	for i := range image.body {
+		image.body[i] = randomPic(r)
+	}
+	return image
+}
+
+// toMessage 转成消息，会复制一份图本体
+func (image *clearJoyImage) toMessage() *pb.ClearJoyImage {
+	body := make([]int64, len(image.body))
+	copy(body, image.body)
+	return &pb.ClearJoyImage{
+		Width:  image.width,
+		Height: image.height,
+		Body:   body,
+	}
+}
+
+// getPic 获取某个位置的图案
+func (image *clearJoyImage) getPic(x int32, y int32) int64 {
+	return image.body[y*image.width+x]
+}
+
+// findMatch 找出所有横向和竖向连续3个以上相同的图案，返回要消除的索引和这些连线的总赔率
+// 一个位置同时在横线和竖线上时，两条线都算赔率，但只消除一次
+func (image *clearJoyImage) findMatch() ([]int32, int64) {
+	clearMap := make(map[int32]bool)
+	odds := int64(0)
+	// 检查从(x, y)开始往(dx, dy)方向的连线，返回连线长度
+	checkLine := func(x int32, y int32, dx int32, dy int32) int32 {
+		pic := image.getPic(x, y)
+		num := int32(1)
+		for cx, cy := x+dx, y+dy; cx < image.width && cy < image.height; cx, cy = cx+dx, cy+dy {
+			if image.getPic(cx, cy) != pic {
+				break
+			}
+			num++
+		}
+		if num >= 3 {
+			odds += getPicOdds(pic, int(num))
+			for i := int32(0); i < num; i++ {
+				clearMap[(y+dy*i)*image.width+x+dx*i] = true
+			}
+		}
+		return num
+	}
+	for y := int32(0); y < image.height; y++ {
+		for x := int32(0); x < image.width; {
+			x += checkLine(x, y, 1, 0)
+		}
+	}
+	for x := int32(0); x < image.width; x++ {
+		for y := int32(0); y < image.height; {
+			y += checkLine(x, y, 0, 1)
+		}
+	}
+	clearIndex := make([]int32, 0, len(clearMap))
+	for index := range clearMap {
+		clearIndex = append(clearIndex, index)
+	}
+	sort.Slice(clearIndex, func(i, j int) bool {
+		return clearIndex[i] < clearIndex[j]
+	})
+	return clearIndex, odds
+}
+
+// dropAndFill 消除指定的位置，上面的图案往下掉，空出来的位置用新的随机图案填充
+func (image *clearJoyImage) dropAndFill(r *rand.Rand, clearIndex []int32) {
+	for _, index := range clearIndex {
+		image.body[index] = 0
+	}
+	for x := int32(0); x < image.width; x++ {
+		// 从下往上把没消除的图案依次往下放
+		bottom := image.height - 1
+		for y := image.height - 1; y >= 0; y-- {
+			pic := image.getPic(x, y)
+			if pic == 0 {
+				continue
+			}
+			image.body[y*image.width+x] = 0
+			image.body[bottom*image.width+x] = pic
+			bottom--
+		}
+		for y := bottom; y >= 0; y-- {
+			image.body[y*image.width+x] = randomPic(r)
+		}
+	}
+}
+
+// getChainMultiple 获取第chainIndex次连消的倍数，超过配置的次数时用最后一个倍数
+func getChainMultiple(chainMultiples []int64, chainIndex int) int64 {
+	if len(chainMultiples) == 0 {
+		return 1
+	}
+	if chainIndex >= len(chainMultiples) {
+		return chainMultiples[len(chainMultiples)-1]
+	}
+	return chainMultiples[chainIndex]
+}
+
+// playClearJoy 用随机种子开一局，消除后掉落填充，有新的连线就继续消除，连消次数越多倍数越高
+// 同一个种子和参数总是得到同样的结果
+func playClearJoy(seed int64, wager int64, width int32, height int32, chainMultiples []int64, maxChainNum int) *clearJoyResult {
+	r := rand.New(rand.NewSource(seed))
+	result := &clearJoyResult{seed: seed}
+	image := newImage(r, width, height)
+	for chainIndex := 0; chainIndex < maxChainNum; chainIndex++ {
+		clearIndex, odds := image.findMatch()
+		if len(clearIndex) == 0 {
+			break
+		}
+		multiple := getChainMultiple(chainMultiples, chainIndex)
+		chainInfo := &pb.ClearJoyChainInfo{
+			Image:      image.toMessage(),
+			ClearIndex: clearIndex,
+			Multiple:   multiple,
+			Win:        wager * odds * multiple / 100,
+		}
+		result.allChainInfo = append(result.allChainInfo, chainInfo)
+		result.totalWin += chainInfo.GetWin()
+		image.dropAndFill(r, clearIndex)
+	}
+	result.finalImage = image.toMessage()
+	return result
+}
+
+// playClearJoyWithBlood 根据血池状态开奖，血池需要吃分时尽量让玩家输，需要吐分时尽量让玩家赢，每次重开换一个种子，最多重开controlTimes次
+func playClearJoyWithBlood(wager int64, width int32, height int32, chainMultiples []int64, maxChainNum int, bloodState pb.BloodSlotStatus, controlTimes int) *clearJoyResult {
+	result := playClearJoy(rand.Int63(), wager, width, height, chainMultiples, maxChainNum)
+	for i := 0; i < controlTimes; i++ {
+		switch bloodState {
+		case pb.BloodSlotStatus_BloodSlotStatus_Win:
+			if result.totalWin < wager {
+				return result
+			}
+			if cur := playClearJoy(rand.Int63(), wager, width, height, chainMultiples, maxChainNum); cur.totalWin < result.totalWin {
+				result = cur
+			}
+		case pb.BloodSlotStatus_BloodSlotStatus_Lose:
+			if result.totalWin > wager {
+				return result
+			}
+			if cur := playClearJoy(rand.Int63(), wager, width, height, chainMultiples, maxChainNum); cur.totalWin > result.totalWin {
+				result = cur
+			}
+		default:
+			return result
+		}
+	}
+	return result
+}
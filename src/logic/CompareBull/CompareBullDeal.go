@@ -0,0 +1,103 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["CompareBullDeal"] = &CompareBullDeal{}
+}
+
+// CompareBullDeal 拼牛牛游戏的发牌组件，用于处理发牌阶段的逻辑
+type CompareBullDeal struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *CompareBullDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *CompareBullDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 拼牛牛发牌阶段的主驱动，给每个游戏中的玩家发五张牌
+func (obj *CompareBullDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateDeal {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStatePlay
+		request.NextRoomState = pb.RoomState_RoomStatePlay
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	dealTimeStr := common.GetRoomConfig(request, "DealTime")
+	dealTime, err := strconv.Atoi(dealTimeStr)
+	if err != nil {
+		common.LogError("CompareBullDeal Drive dealTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	typeOdds, msgErr := getCompareBullOdds(request)
+	if msgErr != nil {
+		return request, msgErr
+	}
+	baseScore, msgErr := getBaseScore(request)
+	if msgErr != nil {
+		return request, msgErr
+	}
+	players := getPlayPlayers(request)
+	if len(players)*handPokerNum > deckPokerNum {
+		common.LogError("CompareBullDeal Drive too many players", len(players))
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 推送房间状态 准备<->发牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateReady,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + int64(dealTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	// 发牌，血池需要控制时选择合适的发牌结果
+	bloodState := common.BloodGetState(request.GetGameType(), request.GetGameScene())
+	hands := dealByControl(players, bloodState, typeOdds, baseScore)
+	for index, onePlayer := range players {
+		onePlayer.Pokers = hands[index].pokers
+		onePlayer.OutPokers = nil
+		onePlayer.CompareBullPokerType = hands[index].pokerType
+		onePlayer.CompareBullPokerOdds = uint32(typeOdds[hands[index].pokerType])
+		// 自己能看到手牌和牌型，其他人只知道发了牌
+		pushToSelf := &pb.PushPlayerCardChange{
+			RoomId:               request.GetUuid(),
+			UserId:               onePlayer.GetUuid(),
+			HandPoker:            onePlayer.GetPokers(),
+			CompareBullPokerType: onePlayer.GetCompareBullPokerType(),
+			CompareBullPokerOdds: onePlayer.GetCompareBullPokerOdds(),
+		}
+		pushToOthers := &pb.PushPlayerCardChange{
+			RoomId: request.GetUuid(),
+			UserId: onePlayer.GetUuid(),
+		}
+		msgErr = common.PushRoom(pushToSelf, pushToOthers, onePlayer.GetUuid(), request)
+		if msgErr != nil {
+			common.LogError("CompareBullDeal Drive PushRoom has err", onePlayer.GetUuid(), msgErr)
+		}
+	}
+
+	request.NextRoomState = pb.RoomState_RoomStatePlay
+	request.DoTime = nowTime + int64(dealTime)
+	return request, nil
+}
@@ -0,0 +1,160 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["CompareBullPlay"] = &CompareBullPlay{}
+}
+
+// CompareBullPlay 拼牛牛游戏的开牌组件，用于处理玩家开牌阶段的逻辑
+type CompareBullPlay struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *CompareBullPlay) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *CompareBullPlay) Start() {
+	obj.Base.Start()
+}
+
+// Drive 拼牛牛开牌阶段的主驱动
+// 所有玩家都开牌或者开牌时间到了就进入结算，时间到了还没开牌的玩家由系统帮忙开牌
+func (obj *CompareBullPlay) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStatePlay {
+		if nowTime < request.DoTime && !obj.isAllOpen(request) {
+			return request, nil
+		}
+		for _, onePlayer := range getPlayPlayers(request) {
+			if len(onePlayer.GetOutPokers()) == 0 {
+				obj.openCard(request, onePlayer, onePlayer.GetPokers())
+			}
+		}
+		request.CurRoomState = pb.RoomState_RoomStateSettle
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	openCardTimeStr := common.GetRoomConfig(request, "OpenCardTime")
+	openCardTime, err := strconv.Atoi(openCardTimeStr)
+	if err != nil {
+		common.LogError("CompareBullPlay Drive openCardTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	endTime := nowTime + int64(openCardTime)
+	// 推送房间状态 发牌<->开牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateDeal,
+		AfterState:        pb.RoomState_RoomStatePlay,
+		AfterStateEndTime: endTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+	// 通知游戏中的玩家开牌
+	for _, onePlayer := range getPlayPlayers(request) {
+		pushOpenCard := &pb.PushPlayerOpenCard{
+			RoomId:    request.GetUuid(),
+			HandPoker: onePlayer.GetPokers(),
+			EndTime:   endTime,
+		}
+		common.Pusher.Push(pushOpenCard, onePlayer.GetUuid())
+	}
+
+	request.NextRoomState = pb.RoomState_RoomStateSettle
+	request.DoTime = endTime
+	return request, nil
+}
+
+// isAllOpen 游戏中的玩家是否都开牌了
+func (obj *CompareBullPlay) isAllOpen(roomInfo *pb.RoomInfo) bool {
+	for _, onePlayer := range getPlayPlayers(roomInfo) {
+		if len(onePlayer.GetOutPokers()) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// openCard 玩家开牌并广播开出的牌和牌型
+func (obj *CompareBullPlay) openCard(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, openPokers []*pb.Poker) {
+	onePlayer.OutPokers = openPokers
+	pushCardChange := &pb.PushPlayerCardChange{
+		RoomId:               roomInfo.GetUuid(),
+		UserId:               onePlayer.GetUuid(),
+		OutPoker:             onePlayer.GetOutPokers(),
+		CompareBullPokerType: onePlayer.GetCompareBullPokerType(),
+		CompareBullPokerOdds: onePlayer.GetCompareBullPokerOdds(),
+		IsOpenCard:           true,
+	}
+	common.RoomBroadcast(roomInfo, pushCardChange)
+}
+
+// RequestOpenCard 玩家开牌，开牌的顺序由客户端决定（如先放凑成牛的三张），但必须是自己的五张手牌
+func (obj *CompareBullPlay) RequestOpenCard(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.GameOpenCardRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("CompareBullPlay RequestOpenCard ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("CompareBullPlay RequestOpenCard player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if playerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	if len(playerInfo.GetOutPokers()) != 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	openPokers := realRequest.GetOpenPokers()
+	if len(openPokers) == 0 {
+		openPokers = playerInfo.GetPokers()
+	}
+	if !isSamePokers(openPokers, playerInfo.GetPokers()) {
+		common.LogError("CompareBullPlay RequestOpenCard open pokers not match hand", uid, openPokers)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InValidCard, "")
+	}
+	obj.openCard(roomInfo, playerInfo, openPokers)
+	return packReply(roomInfo, &pb.GameOpenCardReply{})
+}
+
+// RequestExitInGame 玩家在对局中退出房间
+// 玩家本局仍然参与比牌和结算，这里只标记为等待踢出，结算后状态置空由房间的Kick踢出
+// 逃跑者名单QPlayer只用于抢座模式的惩罚结算，拼牛牛不需要
+func (obj *CompareBullPlay) RequestExitInGame(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	playerInfo.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_Exit
+	// 结算阶段本局已经结算完了，可以直接踢出
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStateSettle && roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+	}
+	return packReply(roomInfo, &pb.GameExitRoomReply{})
+}
@@ -0,0 +1,150 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+)
+
+func init() {
+	common.AllComponentMap["CompareBullRoute"] = &CompareBullRoute{}
+}
+
+// CompareBullRoute 拼牛牛游戏的功能中转组件，其他服务通过这个组件中转拼牛牛协议到具体逻辑组件中
+type CompareBullRoute struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *CompareBullRoute) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *CompareBullRoute) Start() {
+	obj.Base.Start()
+
+	// 需要的配置进行模版初始化
+	initGlobleConfigNameArr := []string{
+		"CompareBullServerNum",
+	}
+	err := common.InitGlobleConfigTemp(initGlobleConfigNameArr)
+	if err != nil {
+		panic("CompareBullRoute initGlobleConfigNameArr has err")
+	}
+}
+
+// Do 中转协议的具体逻辑(玩家的请求操作通过do路由到driver）
+func (obj *CompareBullRoute) Do(request *pb.CompareBullDoRequest, extroInfo *pb.MessageExtroInfo) (proto.Message, *pb.ErrorMessage) {
+	doType := request.GetDoType()
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("CompareBullRoute Do uuid == nil")
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotLogin, "")
+	}
+	// 选择一个driver线路
+	originMessage := request.GetDoMessageContent()
+	serverNumConfig := common.Configer.GetGlobal("CompareBullServerNum")
+	serverNumStr := serverNumConfig.GetValue()
+	serverNum, err := strconv.Atoi(serverNumStr)
+	if err != nil {
+		common.LogError("CompareBullRoute Do Atoi(serverNumStr) has err", serverNumStr)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 获取玩家信息
+	loadPlayerRequest := &pb.LoadPlayerRequest{}
+	loadPlayerRequest.Uuid = uuid
+	loadPlayerReply := &pb.LoadPlayerReply{}
+	msgErr := common.Router.Call("PlayerInfo", "LoadPlayer", loadPlayerRequest, loadPlayerReply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	playerInfo := loadPlayerReply.GetPlayerInfo()
+
+	var requestMessage proto.Message
+	var replyMessage proto.Message
+	//玩家线路
+	var driverServerIndex string
+	//方法名
+	var methodName string
+	switch doType {
+	//进入房间--与其他逻辑不同
+	case pb.CompareBullDoType_CompareBullDo_JoinRoom:
+		requestMessage = &pb.GameJoinRoomRequest{}
+		replyMessage = &pb.GameJoinRoomReply{}
+
+		err := ptypes.UnmarshalAny(originMessage, requestMessage)
+		if err != nil {
+			common.LogError("CompareBullRoute Do joinRoom ptypes.UnmarshalAny has err", doType, uuid, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		driverServerIndex, msgErr = common.GameJoinRoomJudge(playerInfo, serverNum, requestMessage.(*pb.GameJoinRoomRequest), pb.GameType_CompareBull)
+		if msgErr != nil {
+			return nil, msgErr
+		}
+
+		methodName = "RequestJoinRoom"
+	//退出房间
+	case pb.CompareBullDoType_CompareBullDo_ExitRoom:
+		requestMessage = &pb.GameExitRoomRequest{}
+		replyMessage = &pb.GameExitRoomReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestExitRoom"
+	//玩家准备或取消准备
+	case pb.CompareBullDoType_CompareBullDo_ChangeState:
+		requestMessage = &pb.GameChangeStateRequest{}
+		replyMessage = &pb.GameChangeStateReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestChangeState"
+	//玩家开牌
+	case pb.CompareBullDoType_CompareBullDo_OpenCard:
+		requestMessage = &pb.GameOpenCardRequest{}
+		replyMessage = &pb.GameOpenCardReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestOpenCard"
+
+	default:
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	err = ptypes.UnmarshalAny(originMessage, requestMessage)
+	if err != nil {
+		common.LogError("CompareBullRoute Do request ptypes.UnmarshalAny has err", doType, uuid, err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	componentName := "CompareBullDriver" + driverServerIndex
+	// common.Router.Call 都是路由到 driver,  driver 再分发给各个组件
+	msgErr = common.Router.Call(componentName, methodName, requestMessage, replyMessage, extroInfo)
+	if msgErr != nil {
+		common.LogError(msgErr)
+		return nil, msgErr
+	}
+	return replyMessage, nil
+}
+
+// SyncRoomPlayerInfo 同步房间玩家信息
+func (obj *CompareBullRoute) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo, extroInfo *pb.MessageExtroInfo) (*pb.EmptyMessage, *pb.ErrorMessage) {
+	reply := &pb.EmptyMessage{}
+	componentName := "CompareBullDriver" + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "SyncRoomPlayerInfo", request, reply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	return reply, nil
+}
+
+// DelRoom 刪除房間
+func (obj *CompareBullRoute) DelRoom(request *pb.DelRoomByGameTypeRequest, extraInfo *pb.MessageExtroInfo) (*pb.DelRoomByGameTypeReply, *pb.ErrorMessage) {
+	reply := &pb.DelRoomByGameTypeReply{}
+	componentName := "CompareBullDriver" + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "DelRoom", request, reply, extraInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
@@ -0,0 +1,243 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+)
+
+// 每个玩家的手牌张数
+const handPokerNum = 5
+
+// 一副牌（不含大小王）的张数，所有玩家的手牌都从一副牌中发出
+const deckPokerNum = 52
+
+// 血池控制时最多尝试的发牌次数
+const controlTryNum = 30
+
+// 参与赔率计算的牌型
+var compareBullPokerTypes = []pb.CompareBullPokerType{
+	pb.CompareBullPokerType_CompareBullCardType_None,
+	pb.CompareBullPokerType_CompareBullCardType_Bull1,
+	pb.CompareBullPokerType_CompareBullCardType_Bull2,
+	pb.CompareBullPokerType_CompareBullCardType_Bull3,
+	pb.CompareBullPokerType_CompareBullCardType_Bull4,
+	pb.CompareBullPokerType_CompareBullCardType_Bull5,
+	pb.CompareBullPokerType_CompareBullCardType_Bull6,
+	pb.CompareBullPokerType_CompareBullCardType_Bull7,
+	pb.CompareBullPokerType_CompareBullCardType_Bull8,
+	pb.CompareBullPokerType_CompareBullCardType_Bull9,
+	pb.CompareBullPokerType_CompareBullCardType_BullBull,
+	pb.CompareBullPokerType_CompareBullCardType_LittleBull,
+}
+
+// bullHand 一个玩家的手牌和牌型
+type bullHand struct {
+	pokers    []*pb.Poker
+	pokerType pb.CompareBullPokerType
+}
+
+// getCompareBullOdds 获取房间的牌型赔率配置，配置名为Odds加上牌型名，如OddsBull1、OddsLittleBull
+func getCompareBullOdds(roomInfo *pb.RoomInfo) (map[pb.CompareBullPokerType]int64, *pb.ErrorMessage) {
+	typeOdds := make(map[pb.CompareBullPokerType]int64)
+	for _, pokerType := range compareBullPokerTypes {
+		oddsName := "Odds" + strings.TrimPrefix(pokerType.String(), "CompareBullCardType_")
+		oddsStr := common.GetRoomConfig(roomInfo, oddsName)
+		oddsNum, err := strconv.ParseInt(oddsStr, 10, 64)
+		if err != nil || oddsNum <= 0 {
+			common.LogError("getCompareBullOdds has err", oddsName, oddsStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		typeOdds[pokerType] = oddsNum
+	}
+	return typeOdds, nil
+}
+
+// getBaseScore 获取房间的底分
+func getBaseScore(roomInfo *pb.RoomInfo) (int64, *pb.ErrorMessage) {
+	baseScoreStr := common.GetRoomConfig(roomInfo, "BaseScore")
+	baseScore, err := strconv.ParseInt(baseScoreStr, 10, 64)
+	if err != nil || baseScore <= 0 {
+		common.LogError("CompareBull getBaseScore has err", baseScoreStr, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return baseScore, nil
+}
+
+// getCompareBullPokerType 获取五张牌的拼牛牛牌型
+// 牌型大小：五小牛>牛牛>有牛（从牛9到牛1）>无牛，拼牛牛没有炸弹牛等特殊牌型
+func getCompareBullPokerType(pokers []*pb.Poker) pb.CompareBullPokerType {
+	if len(pokers) != handPokerNum {
+		return pb.CompareBullPokerType_CompareBullCardType_None
+	}
+	// 五小牛：五张牌都小于5并且点数和不大于10
+	var sum int64
+	isLittle := true
+	for _, poker := range pokers {
+		value := common.GetBullPokerValue(poker)
+		sum += value
+		if value >= 5 {
+			isLittle = false
+		}
+	}
+	if isLittle && sum <= 10 {
+		return pb.CompareBullPokerType_CompareBullCardType_LittleBull
+	}
+	// 任意三张的点数和是10的倍数，剩下两张的点数和的个位数就是牛几
+	for i := 0; i < handPokerNum; i++ {
+		for j := i + 1; j < handPokerNum; j++ {
+			for k := j + 1; k < handPokerNum; k++ {
+				threeSum := common.GetBullPokerValue(pokers[i]) + common.GetBullPokerValue(pokers[j]) + common.GetBullPokerValue(pokers[k])
+				if threeSum%10 != 0 {
+					continue
+				}
+				point := (sum - threeSum) % 10
+				if point == 0 {
+					return pb.CompareBullPokerType_CompareBullCardType_BullBull
+				}
+				return pb.CompareBullPokerType(point)
+			}
+		}
+	}
+	return pb.CompareBullPokerType_CompareBullCardType_None
+}
+
+// newBullHand 根据五张手牌生成手牌信息
+func newBullHand(pokers []*pb.Poker) *bullHand {
+	return &bullHand{
+		pokers:    pokers,
+		pokerType: getCompareBullPokerType(pokers),
+	}
+}
+
+// isBigger 判断a的手牌是否比b大，牌型相同时比较最大的单张牌
+func (a *bullHand) isBigger(b *bullHand) bool {
+	if a.pokerType != b.pokerType {
+		return a.pokerType > b.pokerType
+	}
+	return common.CompareBullPokers(a.pokers, pb.CrazyBullPokerType_CrazyBullCardType_None, b.pokers, pb.CrazyBullPokerType_CrazyBullCardType_None)
+}
+
+// getPlayPlayers 获取本局参与游戏的玩家
+func getPlayPlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+			continue
+		}
+		players = append(players, onePlayer)
+	}
+	return players
+}
+
+// getSettleWinOrLose 计算每个玩家本局的输赢（未抽水）
+// 所有玩家两两比牌，输家按照赢家牌型的赔率乘以底分赔给赢家
+// 输家输的总额超过身上的金额时，按比例缩减赔给每个赢家的金额
+// 参数：players 参与游戏的玩家，hands 与玩家一一对应的手牌
+// 返回值：与玩家一一对应的输赢
+func getSettleWinOrLose(players []*pb.RoomPlayerInfo, hands []*bullHand, typeOdds map[pb.CompareBullPokerType]int64, baseScore int64) []int64 {
+	playerNum := len(players)
+	// pay[loser][winner] 输家赔给赢家的金额
+	pay := make([][]int64, playerNum)
+	for index := range pay {
+		pay[index] = make([]int64, playerNum)
+	}
+	for i := 0; i < playerNum; i++ {
+		for j := i + 1; j < playerNum; j++ {
+			winner, loser := i, j
+			if hands[j].isBigger(hands[i]) {
+				winner, loser = j, i
+			}
+			pay[loser][winner] = baseScore * typeOdds[hands[winner].pokerType]
+		}
+	}
+	winOrLose := make([]int64, playerNum)
+	for loser := 0; loser < playerNum; loser++ {
+		var allLose int64
+		for _, payNum := range pay[loser] {
+			allLose += payNum
+		}
+		balance := players[loser].GetBalance()
+		if balance < 0 {
+			balance = 0
+		}
+		for winner, payNum := range pay[loser] {
+			if payNum <= 0 {
+				continue
+			}
+			if allLose > balance {
+				payNum = payNum * balance / allLose
+			}
+			winOrLose[winner] += payNum
+			winOrLose[loser] -= payNum
+		}
+	}
+	return winOrLose
+}
+
+// getSystemScore 计算发牌结果下平台的收益（真实玩家输的钱）
+func getSystemScore(players []*pb.RoomPlayerInfo, hands []*bullHand, typeOdds map[pb.CompareBullPokerType]int64, baseScore int64) int64 {
+	var score int64
+	for index, winOrLose := range getSettleWinOrLose(players, hands, typeOdds, baseScore) {
+		if players[index].GetIsRobot() {
+			continue
+		}
+		score -= winOrLose
+	}
+	return score
+}
+
+// dealHands 从一副洗好的牌中给每个玩家发五张牌
+func dealHands(playerNum int) []*bullHand {
+	cardHeap := common.GetShufflePokerHeap(1)
+	hands := make([]*bullHand, playerNum)
+	for index := 0; index < playerNum; index++ {
+		hands[index] = newBullHand(cardHeap[index*handPokerNum : (index+1)*handPokerNum])
+	}
+	return hands
+}
+
+// dealByControl 根据血池状态给参与游戏的玩家发牌
+// 不控制时直接发牌，控制时多次洗牌发牌，选择平台收益最高（或最低）的一次
+// 返回值：与玩家一一对应的手牌
+func dealByControl(players []*pb.RoomPlayerInfo, bloodState pb.BloodSlotStatus, typeOdds map[pb.CompareBullPokerType]int64, baseScore int64) []*bullHand {
+	bestHands := dealHands(len(players))
+	if bloodState != pb.BloodSlotStatus_BloodSlotStatus_Win && bloodState != pb.BloodSlotStatus_BloodSlotStatus_Lose {
+		return bestHands
+	}
+	bestScore := getSystemScore(players, bestHands, typeOdds, baseScore)
+	for try := 1; try < controlTryNum; try++ {
+		hands := dealHands(len(players))
+		score := getSystemScore(players, hands, typeOdds, baseScore)
+		if (bloodState == pb.BloodSlotStatus_BloodSlotStatus_Win && score > bestScore) ||
+			(bloodState == pb.BloodSlotStatus_BloodSlotStatus_Lose && score < bestScore) {
+			bestScore = score
+			bestHands = hands
+		}
+	}
+	return bestHands
+}
+
+// isSamePokers 判断两组牌是否是同样的几张牌（不区分顺序）
+func isSamePokers(a []*pb.Poker, b []*pb.Poker) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, aPoker := range a {
+		found := false
+		for index, bPoker := range b {
+			if used[index] || aPoker.GetPokerNum() != bPoker.GetPokerNum() || aPoker.GetPokerColor() != bPoker.GetPokerColor() {
+				continue
+			}
+			used[index] = true
+			found = true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
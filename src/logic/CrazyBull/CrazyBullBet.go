@@ -0,0 +1,144 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["CrazyBullBet"] = &CrazyBullBet{}
+}
+
+// CrazyBullBet 疯狂牛牛游戏的下注组件，用于处理闲家选择下注倍数阶段的逻辑
+type CrazyBullBet struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *CrazyBullBet) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *CrazyBullBet) Start() {
+	obj.Base.Start()
+}
+
+// Drive 疯狂牛牛下注阶段的主驱动
+// 闲家选择下注倍数，所有闲家都选择了或者下注时间到了就进入搓牌阶段
+// 时间到了还没选择的闲家和断线的闲家由系统选择最小的下注倍数
+func (obj *CrazyBullBet) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	betOddsList, msgErr := getOddsList(request, "BetOdds")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	minBetOdds := getMinOdds(betOddsList)
+	if request.NextRoomState != pb.RoomState_RoomStateBet {
+		if nowTime < request.DoTime && !obj.isAllBet(request) {
+			return request, nil
+		}
+		for _, onePlayer := range getPlayPlayers(request) {
+			if !isBanker(request, onePlayer) && !onePlayer.GetIsCrazyBullPlayOdds() {
+				obj.bet(request, onePlayer, minBetOdds)
+			}
+		}
+		request.CurRoomState = pb.RoomState_RoomStateRubbingCards
+		request.NextRoomState = pb.RoomState_RoomStateRubbingCards
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	betTimeStr := common.GetRoomConfig(request, "BetTime")
+	betTime, err := strconv.Atoi(betTimeStr)
+	if err != nil {
+		common.LogError("CrazyBullBet Drive betTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 推送房间状态 抢庄<->下注
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateRushVillage,
+		AfterState:        pb.RoomState_RoomStateBet,
+		AfterStateEndTime: nowTime + int64(betTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	// 断线的闲家不用等待，直接下最小倍数
+	for _, onePlayer := range getPlayPlayers(request) {
+		if !isBanker(request, onePlayer) && !isOnline(onePlayer) {
+			obj.bet(request, onePlayer, minBetOdds)
+		}
+	}
+	request.NextRoomState = pb.RoomState_RoomStateRubbingCards
+	request.DoTime = nowTime + int64(betTime)
+	return request, nil
+}
+
+// isAllBet 游戏中的闲家是否都选择了下注倍数
+func (obj *CrazyBullBet) isAllBet(roomInfo *pb.RoomInfo) bool {
+	for _, onePlayer := range getPlayPlayers(roomInfo) {
+		if !isBanker(roomInfo, onePlayer) && !onePlayer.GetIsCrazyBullPlayOdds() {
+			return false
+		}
+	}
+	return true
+}
+
+// bet 记录闲家的下注倍数并广播
+func (obj *CrazyBullBet) bet(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, odds int64) {
+	onePlayer.CrazyBullPlayOdds = odds
+	onePlayer.IsCrazyBullPlayOdds = true
+	pushPlayerBet := &pb.CrazyBullPlayerBet{
+		AllBet: odds,
+		Uuid:   onePlayer.GetUuid(),
+		RoomId: roomInfo.GetUuid(),
+	}
+	common.RoomBroadcast(roomInfo, pushPlayerBet)
+}
+
+// RequestBet 闲家选择下注倍数，倍数必须是配置BetOdds中的一个
+func (obj *CrazyBullBet) RequestBet(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateBet || roomInfo.GetNextRoomState() == pb.RoomState_RoomStateBet {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInBetTime, "")
+	}
+	realRequest := &pb.CrazyBetRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("CrazyBullBet RequestBet ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("CrazyBullBet RequestBet player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if playerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	if isBanker(roomInfo, playerInfo) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BankerCannotBet, "")
+	}
+	if playerInfo.GetIsCrazyBullPlayOdds() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	betOddsList, msgErr := getOddsList(roomInfo, "BetOdds")
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	if realRequest.GetBetBalance() <= 0 || !isInOddsList(betOddsList, realRequest.GetBetBalance()) {
+		common.LogError("CrazyBullBet RequestBet odds not in config", uid, realRequest.GetBetBalance())
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BetRequestInvalid, "")
+	}
+	obj.bet(roomInfo, playerInfo, realRequest.GetBetBalance())
+	return packReply(roomInfo, &pb.CrazyTigerBetReply{IsSuccess: true})
+}
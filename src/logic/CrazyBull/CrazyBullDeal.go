@@ -0,0 +1,92 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["CrazyBullDeal"] = &CrazyBullDeal{}
+}
+
+// CrazyBullDeal 疯狂牛牛游戏的发牌组件，用于处理发牌阶段的逻辑
+type CrazyBullDeal struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *CrazyBullDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *CrazyBullDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 疯狂牛牛发牌阶段的主驱动
+// 给每个游戏中的玩家发五张牌，玩家只能看到前四张，最后一张在搓牌阶段才发给玩家
+func (obj *CrazyBullDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateDeal {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateRushVillage
+		request.NextRoomState = pb.RoomState_RoomStateRushVillage
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	dealTimeStr := common.GetRoomConfig(request, "DealTime")
+	dealTime, err := strconv.Atoi(dealTimeStr)
+	if err != nil {
+		common.LogError("CrazyBullDeal Drive dealTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	players := getPlayPlayers(request)
+	if len(players)*handPokerNum > deckPokerNum {
+		common.LogError("CrazyBullDeal Drive too many players", len(players))
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 推送房间状态 准备<->发牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateReady,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + int64(dealTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	// 发牌，剩下的牌留在牌堆中，搓牌阶段血池控制时会用来重新分配最后一张牌
+	allPokers, cardHeap := dealPokers(len(players))
+	request.PokerCardHeap = cardHeap
+	for index, onePlayer := range players {
+		onePlayer.Pokers = allPokers[index]
+		onePlayer.OutPokers = nil
+		// 自己只能看到前四张，其他人只知道发了牌
+		pushToSelf := &pb.PushPlayerCardChange{
+			RoomId:    request.GetUuid(),
+			UserId:    onePlayer.GetUuid(),
+			HandPoker: onePlayer.GetPokers()[:publishPokerNum],
+		}
+		pushToOthers := &pb.PushPlayerCardChange{
+			RoomId: request.GetUuid(),
+			UserId: onePlayer.GetUuid(),
+		}
+		msgErr := common.PushRoom(pushToSelf, pushToOthers, onePlayer.GetUuid(), request)
+		if msgErr != nil {
+			common.LogError("CrazyBullDeal Drive PushRoom has err", onePlayer.GetUuid(), msgErr)
+		}
+	}
+
+	request.NextRoomState = pb.RoomState_RoomStateRushVillage
+	request.DoTime = nowTime + int64(dealTime)
+	return request, nil
+}
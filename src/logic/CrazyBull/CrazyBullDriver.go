@@ -0,0 +1,168 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+)
+
+func init() {
+	common.AllComponentMap["CrazyBullDriver"] = &CrazyBullDriver{}
+}
+
+// CrazyBullDriver 疯狂牛牛游戏的房间管理组件，负责处理玩家请求操作
+type CrazyBullDriver struct {
+	base.Base
+	rm *common.RoomManager
+}
+
+var maxRoomNumCfgName = "CrazyBullMaxRoomNumOneServer"
+
+// LoadComponent 加载组件
+func (obj *CrazyBullDriver) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	// 这是一个多线路组件
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *CrazyBullDriver) Start() {
+	obj.Base.Start()
+
+	initGlobleConfigNameArr := []string{
+		maxRoomNumCfgName,
+	}
+	err := common.InitGlobleConfigTemp(initGlobleConfigNameArr)
+	if err != nil {
+		panic(err)
+	}
+	//将模板参数保存到内存中
+	common.InitGameConfigTemp(common.CrazyBullGameConfigTemp, pb.GameType_CrazyBull)
+
+	obj.rm = new(common.RoomManager)
+	tableName := common.GetRoomRedisName(pb.GameType_CrazyBull, common.ServerIndex)
+	obj.rm.InitRoomManager(pb.GameType_CrazyBull, tableName, obj.DriveRoom)
+	//从redis里恢复房间数据（会导致配置不更新, 要刷新房间需要清空redis）
+	err = obj.rm.ReLoadRooms()
+	if err != nil {
+		panic(err)
+	}
+	obj.rm.ReStartRooms()
+	common.LogDebug("❤疯狂牛牛服务初始化❤")
+}
+
+// DriveRoom 这个方法提供房间驱动的具体逻辑
+// (room公共驱动调用到这里，通过这个函数根据房间状态调用相应的房间逻辑)
+func (obj *CrazyBullDriver) DriveRoom(roomInfo *pb.RoomInfo) *pb.RoomInfo {
+	extraInfo := &pb.MessageExtroInfo{}
+	afterRoomInfo := &pb.RoomInfo{}
+
+	// 根据状态获取需要调用的组件名
+	componentName := (*obj.Base.Config)[pb.RoomState_name[int32(roomInfo.CurRoomState)]]
+	if componentName == "" {
+		common.LogError("CrazyBull DriveRoom get componentName has empty")
+		return nil
+	}
+	msgErr := common.Router.Call(componentName, "Drive", roomInfo, afterRoomInfo, extraInfo)
+	if msgErr != nil {
+		common.LogError("CrazyBull DriveRoom call ", componentName, " Drive has err", msgErr)
+		return afterRoomInfo
+	}
+	return afterRoomInfo
+}
+
+// RequestJoinRoom 加入房间逻辑
+func (obj *CrazyBullDriver) RequestJoinRoom(request *pb.GameJoinRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameJoinRoomReply, *pb.ErrorMessage) {
+	reply := &pb.GameJoinRoomReply{}
+	roomInfo, msgErr := common.GameDriverJoinRoom(request, maxRoomNumCfgName, obj.rm, extroInfo)
+	if msgErr != nil {
+		common.LogError("CrazyBullDriver gameDriverJoinRoom get RoomInfo has error:", msgErr)
+		return nil, msgErr
+	}
+	reply.RoomInfo = roomInfo
+	return reply, msgErr
+}
+
+// RequestExitRoom 退出房间逻辑
+// 对战场游戏中的玩家不能直接退出，这时标记为等待踢出，本局结算后由房间的Kick踢出
+func (obj *CrazyBullDriver) RequestExitRoom(request *pb.GameExitRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameExitRoomReply, *pb.ErrorMessage) {
+	reply := &pb.GameExitRoomReply{}
+	msgErr := common.GameDriverExitRoom(obj.rm, extroInfo)
+	if msgErr == nil || msgErr.GetCode() != pb.ErrorCode_NotAllowExitRoom {
+		return reply, msgErr
+	}
+	msgErr = common.GameDriverDo("CrazyBullRubbingCards", "RequestExitInGame", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestChangeState 玩家准备或取消准备逻辑
+func (obj *CrazyBullDriver) RequestChangeState(request *pb.GameChangeStateRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameChangeStateReply, *pb.ErrorMessage) {
+	reply := &pb.GameChangeStateReply{}
+	msgErr := common.GameDriverDo("CrazyBullReady", "RequestChangeState", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestOpenCard 玩家开牌逻辑
+func (obj *CrazyBullDriver) RequestOpenCard(request *pb.GameOpenCardRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameOpenCardReply, *pb.ErrorMessage) {
+	reply := &pb.GameOpenCardReply{}
+	msgErr := common.GameDriverDo("CrazyBullRubbingCards", "RequestOpenCard", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestRushVillage 玩家抢庄逻辑
+func (obj *CrazyBullDriver) RequestRushVillage(request *pb.CrazyUpBankerRequest, extroInfo *pb.MessageExtroInfo) (*pb.CrazyUpBankerReply, *pb.ErrorMessage) {
+	reply := &pb.CrazyUpBankerReply{}
+	msgErr := common.GameDriverDo("CrazyBullRushVillage", "RequestRushVillage", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestBet 闲家下注逻辑
+func (obj *CrazyBullDriver) RequestBet(request *pb.CrazyBetRequest, extroInfo *pb.MessageExtroInfo) (*pb.CrazyTigerBetReply, *pb.ErrorMessage) {
+	reply := &pb.CrazyTigerBetReply{}
+	msgErr := common.GameDriverDo("CrazyBullBet", "RequestBet", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestRubbingCards 玩家搓牌逻辑
+func (obj *CrazyBullDriver) RequestRubbingCards(request *pb.CrazyRubbingCardsRequest, extroInfo *pb.MessageExtroInfo) (*pb.CrazyRubbingCardsReply, *pb.ErrorMessage) {
+	reply := &pb.CrazyRubbingCardsReply{}
+	msgErr := common.GameDriverDo("CrazyBullRubbingCards", "RequestRubbingCards", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// SyncRoomPlayerInfo 同步玩家房间玩家信息
+func (obj *CrazyBullDriver) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo, extroInfo *pb.MessageExtroInfo) (*pb.EmptyMessage, *pb.ErrorMessage) {
+	reply := &pb.EmptyMessage{}
+	msgErr := common.GameDriverSyncRoomPlayerInfo(request, obj.rm, extroInfo)
+	return reply, msgErr
+}
+
+// DelRoom 删除某房间
+func (obj *CrazyBullDriver) DelRoom(request *pb.DelRoomByGameTypeRequest, extraInfo *pb.MessageExtroInfo) (*pb.DelRoomByGameTypeReply, *pb.ErrorMessage) {
+	reply := &pb.DelRoomByGameTypeReply{}
+	// 根据uuid删除房间
+	err := obj.rm.DeleteRoom(request.GetRoomUUID(), true)
+	if err != nil {
+		common.LogError("CrazyBullDriver DelRoom has err:", request.GameType, err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return reply, nil
+}
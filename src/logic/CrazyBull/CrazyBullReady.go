@@ -0,0 +1,259 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	uuid "github.com/satori/go.uuid"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["CrazyBullReady"] = &CrazyBullReady{}
+}
+
+// CrazyBullReady 疯狂牛牛游戏的准备组件，用于处理准备阶段的逻辑
+type CrazyBullReady struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *CrazyBullReady) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *CrazyBullReady) Start() {
+	obj.Base.Start()
+	common.InitGameConfigTemp(common.CrazyBullGameConfigTemp, pb.GameType_CrazyBull)
+}
+
+// Drive 疯狂牛牛准备阶段的主驱动
+// 刚进入准备阶段时初始化玩家，之后每次驱动（包括玩家准备后）判断是否可以开始游戏：
+// 准备的人数达到开始人数，并且所有玩家都准备了或者准备时间已到
+func (obj *CrazyBullReady) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowTime := time.Now().Unix()
+	readyTimeStr := common.GetRoomConfig(request, "ReadyTime")
+	readyTime, err := strconv.Atoi(readyTimeStr)
+	if err != nil {
+		common.LogError("CrazyBullReady Drive readyTimeStr has err", readyTimeStr)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	if request.GetNextRoomState() == pb.RoomState_RoomStateReady {
+		msgErr := obj.initRound(request, nowTime, int64(readyTime))
+		return request, msgErr
+	}
+
+	playerStartNumStr := common.GetRoomConfig(request, "PlayerStartNum")
+	playerStartNum, err := strconv.Atoi(playerStartNumStr)
+	if err != nil {
+		common.LogError("CrazyBullReady Drive playerStartNumStr has err", playerStartNumStr)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	readyNum, seatedNum := 0, 0
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" {
+			continue
+		}
+		seatedNum++
+		if onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStateReady {
+			readyNum++
+		}
+	}
+	isTimeOut := nowTime >= request.GetDoTime()
+	if readyNum >= playerStartNum && (readyNum == seatedNum || isTimeOut) {
+		obj.startRound(request, nowTime)
+		return request, nil
+	}
+	if !isTimeOut {
+		return request, nil
+	}
+
+	// 准备时间到了人数还不够，踢出没有准备的玩家，重新计时等待
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStateReady {
+			continue
+		}
+		onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+		onePlayer.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_KickNotReady
+	}
+	request.DoTime = nowTime + int64(readyTime)
+	pushDoTimeInReady := &pb.PushDoTimeInReady{
+		RoomId: request.GetUuid(),
+		DoTime: request.GetDoTime(),
+	}
+	common.RoomBroadcast(request, pushDoTimeInReady)
+	return request, nil
+}
+
+// initRound 新一局的准备，刷新房间配置，初始化玩家状态并标记需要踢出的玩家
+func (obj *CrazyBullReady) initRound(request *pb.RoomInfo, nowTime int64, readyTime int64) *pb.ErrorMessage {
+	// 准备阶段刷新房间配置
+	gameKeyMap := common.Configer.GetGameConfigByGameTypeAndScene(request.GetGameType(), request.GetGameScene())
+	if gameKeyMap != nil {
+		request.Config = []*pb.GameConfig{}
+		for _, oneConfig := range gameKeyMap.Map {
+			request.Config = append(request.Config, oneConfig)
+		}
+	}
+	enterBalanceStr := common.GetRoomConfig(request, "EnterBalance")
+	enterBalance, err := strconv.ParseInt(enterBalanceStr, 10, 64)
+	if err != nil {
+		common.LogError("CrazyBullReady initRound enterBalanceStr has err", enterBalanceStr)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" {
+			continue
+		}
+		onePlayer.Pokers = nil
+		onePlayer.OutPokers = nil
+		onePlayer.CrazyBullPokerType = pb.CrazyBullPokerType_CrazyBullCardType_None
+		onePlayer.CrazyBullPokerOdds = 0
+		onePlayer.CrazyBullOdds = 0
+		onePlayer.CrazyBullPlayOdds = 0
+		onePlayer.IsCrazyBullPlayOdds = false
+		onePlayer.CrazyBullIsSuccess = false
+		onePlayer.CrazyBullRubbingCards = 0
+		onePlayer.WinOrLose = 0
+		onePlayer.HundredWaterBill = 0
+		onePlayer.HundredCommission = 0
+		// 上一局中途退出的玩家已经在结算时处理
+		if onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+			continue
+		}
+		isOnline, msgErr := common.Pusher.CheckOnline(onePlayer.GetUuid())
+		if msgErr != nil {
+			common.LogError("CrazyBullReady initRound CheckOnline has err", onePlayer.GetUuid(), msgErr)
+			isOnline = false
+		}
+		if !isOnline {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+			onePlayer.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_KickDisconnect
+			continue
+		}
+		if onePlayer.GetBalance() < enterBalance {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+			onePlayer.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_KickNoBalance
+			continue
+		}
+		onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateFree
+		// 不需要准备模式下，直接是准备状态
+		if common.CheckModeOpen(pb.GameMode_GameMode_NoReady) {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateReady
+		}
+	}
+
+	// 结算 < -- > 准备
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateSettle,
+		AfterState:        pb.RoomState_RoomStateReady,
+		AfterStateEndTime: nowTime + readyTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	// 清空上一局的庄家信息和牌堆
+	request.CrazyBullMultiples = nil
+	request.CrazyBullMultipleuuid = ""
+	request.CrazyBullMultipleuuidOdds = 0
+	request.CrazyBankers = nil
+	request.PokerCardHeap = nil
+
+	//金币房每次开始的时候需要清空上一局结算信息
+	if common.GameMode == pb.GameMode_GameMode_Gold {
+		request.AllSettleInfo = []*pb.SettleInfo{}
+	}
+	request.NextRoomState = pb.RoomState_RoomStateDeal
+	request.DoTime = nowTime + readyTime
+	return nil
+}
+
+// startRound 开始游戏，准备的玩家进入游戏状态，没有准备的玩家踢出房间
+func (obj *CrazyBullReady) startRound(request *pb.RoomInfo, nowTime int64) {
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" {
+			continue
+		}
+		if onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStateReady {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStatePlay
+			continue
+		}
+		onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+		onePlayer.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_KickNotReady
+	}
+	request.ReadyPlayerNum = 0
+	request.RoundStartTime = nowTime
+	request.CurrentRoundId = uuid.NewV4().String()
+	request.CurRoomState = pb.RoomState_RoomStateDeal
+	request.NextRoomState = pb.RoomState_RoomStateDeal
+	request.DoTime = nowTime
+}
+
+// RequestChangeState 玩家准备或者取消准备
+func (obj *CrazyBullReady) RequestChangeState(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateReady || roomInfo.GetNextRoomState() == pb.RoomState_RoomStateReady {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.GameChangeStateRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("CrazyBullReady RequestChangeState ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("CrazyBullReady RequestChangeState player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	beforeState := playerInfo.GetPlayerRoomState()
+	wantState := realRequest.GetWantState()
+	// 只能在空闲和准备之间切换
+	if (beforeState != pb.PlayerRoomState_PlayerRoomStateFree && beforeState != pb.PlayerRoomState_PlayerRoomStateReady) ||
+		(wantState != pb.PlayerRoomState_PlayerRoomStateFree && wantState != pb.PlayerRoomState_PlayerRoomStateReady) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotChangePlayerState, "")
+	}
+	if beforeState == wantState {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	playerInfo.PlayerRoomState = wantState
+	common.PlayerStateChangeBroadcast(roomInfo, uid, beforeState, wantState)
+
+	//房间有多少人准备了，推送给所有玩家
+	readyNum := 0
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStateReady {
+			readyNum++
+		}
+	}
+	roomInfo.ReadyPlayerNum = int32(readyNum)
+	pushPlayReady := &pb.RoomPlayerReadyNumMessege{
+		RoomId:   roomInfo.GetUuid(),
+		ReadyNum: int64(readyNum),
+	}
+	common.RoomBroadcast(roomInfo, pushPlayReady)
+
+	return packReply(roomInfo, &pb.GameChangeStateReply{})
+}
+
+// packReply 封装回复给driver的房间信息和回复消息
+func packReply(roomInfo *pb.RoomInfo, realReply proto.Message) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	realReplyAny, err := ptypes.MarshalAny(realReply)
+	if err != nil {
+		common.LogError("CrazyBull packReply MarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	reply.RoomInfo = roomInfo
+	reply.Message = realReplyAny
+	return reply, nil
+}
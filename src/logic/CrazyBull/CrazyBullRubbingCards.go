@@ -0,0 +1,237 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["CrazyBullRubbingCards"] = &CrazyBullRubbingCards{}
+}
+
+// CrazyBullRubbingCards 疯狂牛牛游戏的搓牌组件，用于处理发最后一张牌、搓牌和开牌阶段的逻辑
+type CrazyBullRubbingCards struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *CrazyBullRubbingCards) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *CrazyBullRubbingCards) Start() {
+	obj.Base.Start()
+}
+
+// Drive 疯狂牛牛搓牌阶段的主驱动
+// 刚进入时确定每个玩家的最后一张牌并发给玩家，玩家可以搓牌或者直接看牌，然后开牌
+// 所有玩家都开牌或者搓牌时间到了就进入结算，时间到了还没开牌的玩家和断线的玩家由系统帮忙开牌
+func (obj *CrazyBullRubbingCards) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateRubbingCards {
+		if nowTime < request.DoTime && !obj.isAllOpen(request) {
+			return request, nil
+		}
+		for _, onePlayer := range getPlayPlayers(request) {
+			if len(onePlayer.GetOutPokers()) == 0 {
+				obj.openCard(request, onePlayer, onePlayer.GetPokers())
+			}
+		}
+		request.CurRoomState = pb.RoomState_RoomStateSettle
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	rubbingCardsTimeStr := common.GetRoomConfig(request, "RubbingCardsTime")
+	rubbingCardsTime, err := strconv.Atoi(rubbingCardsTimeStr)
+	if err != nil {
+		common.LogError("CrazyBullRubbingCards Drive rubbingCardsTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	msgErr := obj.dealLastPoker(request)
+	if msgErr != nil {
+		return request, msgErr
+	}
+	endTime := nowTime + int64(rubbingCardsTime)
+	// 推送房间状态 下注<->搓牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateBet,
+		AfterState:        pb.RoomState_RoomStateRubbingCards,
+		AfterStateEndTime: endTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+	// 把完整的手牌发给游戏中的玩家，断线的玩家直接开牌
+	for _, onePlayer := range getPlayPlayers(request) {
+		pushOpenCard := &pb.PushPlayerOpenCard{
+			RoomId:    request.GetUuid(),
+			HandPoker: onePlayer.GetPokers(),
+			EndTime:   endTime,
+		}
+		common.Pusher.Push(pushOpenCard, onePlayer.GetUuid())
+		if !isOnline(onePlayer) {
+			obj.openCard(request, onePlayer, onePlayer.GetPokers())
+		}
+	}
+
+	request.NextRoomState = pb.RoomState_RoomStateSettle
+	request.DoTime = endTime
+	return request, nil
+}
+
+// dealLastPoker 根据血池状态确定每个玩家的最后一张牌，并计算牌型
+func (obj *CrazyBullRubbingCards) dealLastPoker(request *pb.RoomInfo) *pb.ErrorMessage {
+	typeOdds, msgErr := getCrazyBullOdds(request)
+	if msgErr != nil {
+		return msgErr
+	}
+	baseScore, msgErr := getBaseScore(request)
+	if msgErr != nil {
+		return msgErr
+	}
+	players := getPlayPlayers(request)
+	bankerIndex := -1
+	for index, onePlayer := range players {
+		if len(onePlayer.GetPokers()) != handPokerNum {
+			common.LogError("CrazyBullRubbingCards dealLastPoker player pokers has err", onePlayer.GetUuid(), len(onePlayer.GetPokers()))
+			return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		if isBanker(request, onePlayer) {
+			bankerIndex = index
+		}
+	}
+	bloodState := common.BloodGetState(request.GetGameType(), request.GetGameScene())
+	hands, cardHeap := dealLastByControl(players, request.GetPokerCardHeap(), bankerIndex, request.GetCrazyBullMultipleuuidOdds(), bloodState, typeOdds, baseScore)
+	request.PokerCardHeap = cardHeap
+	for index, onePlayer := range players {
+		onePlayer.Pokers = hands[index].pokers
+		onePlayer.CrazyBullPokerType = hands[index].pokerType
+		onePlayer.CrazyBullPokerOdds = uint32(typeOdds[hands[index].pokerType])
+	}
+	return nil
+}
+
+// isAllOpen 游戏中的玩家是否都开牌了
+func (obj *CrazyBullRubbingCards) isAllOpen(roomInfo *pb.RoomInfo) bool {
+	for _, onePlayer := range getPlayPlayers(roomInfo) {
+		if len(onePlayer.GetOutPokers()) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// openCard 玩家开牌并广播开出的牌和牌型
+func (obj *CrazyBullRubbingCards) openCard(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, openPokers []*pb.Poker) {
+	onePlayer.OutPokers = openPokers
+	pushCardChange := &pb.PushPlayerCardChange{
+		RoomId:             roomInfo.GetUuid(),
+		UserId:             onePlayer.GetUuid(),
+		OutPoker:           onePlayer.GetOutPokers(),
+		CrazyBullPokerType: onePlayer.GetCrazyBullPokerType(),
+		CrazyBullPokerOdds: onePlayer.GetCrazyBullPokerOdds(),
+		IsOpenCard:         true,
+	}
+	common.RoomBroadcast(roomInfo, pushCardChange)
+}
+
+// RequestRubbingCards 玩家选择搓牌或者看牌，只是通知其他玩家播放对应的动画，每局只能选择一次
+func (obj *CrazyBullRubbingCards) RequestRubbingCards(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateRubbingCards || roomInfo.GetNextRoomState() == pb.RoomState_RoomStateRubbingCards {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.CrazyRubbingCardsRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("CrazyBullRubbingCards RequestRubbingCards ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("CrazyBullRubbingCards RequestRubbingCards player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if playerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay || len(playerInfo.GetOutPokers()) != 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	if playerInfo.GetCrazyBullRubbingCards() != 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	if realRequest.GetRubbingCards() <= 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+	}
+	playerInfo.CrazyBullRubbingCards = realRequest.GetRubbingCards()
+	pushRubbingCards := &pb.CrazyBullPlayerRubbingCards{
+		RubbingCardsType: realRequest.GetRubbingCards(),
+		Uuid:             uid,
+		RoomId:           roomInfo.GetUuid(),
+	}
+	common.RoomBroadcast(roomInfo, pushRubbingCards)
+	return packReply(roomInfo, &pb.CrazyRubbingCardsReply{IsSuccess: true})
+}
+
+// RequestOpenCard 玩家开牌，开牌的顺序由客户端决定（如先放凑成牛的三张），但必须是自己的五张手牌
+func (obj *CrazyBullRubbingCards) RequestOpenCard(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateRubbingCards || roomInfo.GetNextRoomState() == pb.RoomState_RoomStateRubbingCards {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.GameOpenCardRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("CrazyBullRubbingCards RequestOpenCard ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("CrazyBullRubbingCards RequestOpenCard player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if playerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	if len(playerInfo.GetOutPokers()) != 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	openPokers := realRequest.GetOpenPokers()
+	if len(openPokers) == 0 {
+		openPokers = playerInfo.GetPokers()
+	}
+	if !isSamePokers(openPokers, playerInfo.GetPokers()) {
+		common.LogError("CrazyBullRubbingCards RequestOpenCard open pokers not match hand", uid, openPokers)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InValidCard, "")
+	}
+	obj.openCard(roomInfo, playerInfo, openPokers)
+	return packReply(roomInfo, &pb.GameOpenCardReply{})
+}
+
+// RequestExitInGame 玩家在对局中退出房间
+// 玩家本局仍然参与比牌和结算，没操作的步骤在超时后由系统代为操作，结算后状态置空由房间的Kick踢出
+func (obj *CrazyBullRubbingCards) RequestExitInGame(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	playerInfo.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_Exit
+	// 结算阶段本局已经结算完了，可以直接踢出
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStateSettle && roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+	}
+	return packReply(roomInfo, &pb.GameExitRoomReply{})
+}
@@ -0,0 +1,326 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// 每个玩家的手牌张数
+const handPokerNum = 5
+
+// 抢庄和下注阶段亮出的手牌张数，最后一张在搓牌阶段才发给玩家
+const publishPokerNum = 4
+
+// 一副牌（不含大小王）的张数，所有玩家的手牌都从一副牌中发出
+const deckPokerNum = 52
+
+// 血池控制时最多尝试的最后一张牌组合数量
+const controlTryNum = 30
+
+// 参与赔率计算的牌型
+var crazyBullPokerTypes = []pb.CrazyBullPokerType{
+	pb.CrazyBullPokerType_CrazyBullCardType_None,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull1,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull2,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull3,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull4,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull5,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull6,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull7,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull8,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull9,
+	pb.CrazyBullPokerType_CrazyBullCardType_BullBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_StreakyBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_AlongBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_GourdBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_SameFlowerBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_BoomBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_LittleBull,
+}
+
+// bullHand 一个玩家的手牌和牌型
+type bullHand struct {
+	pokers    []*pb.Poker
+	pokerType pb.CrazyBullPokerType
+}
+
+// getCrazyBullOdds 获取房间的牌型赔率配置，配置名为Odds加上牌型名，如OddsBull1、OddsBoomBull
+func getCrazyBullOdds(roomInfo *pb.RoomInfo) (map[pb.CrazyBullPokerType]int64, *pb.ErrorMessage) {
+	typeOdds := make(map[pb.CrazyBullPokerType]int64)
+	for _, pokerType := range crazyBullPokerTypes {
+		oddsName := "Odds" + strings.TrimPrefix(pokerType.String(), "CrazyBullCardType_")
+		oddsStr := common.GetRoomConfig(roomInfo, oddsName)
+		oddsNum, err := strconv.ParseInt(oddsStr, 10, 64)
+		if err != nil || oddsNum <= 0 {
+			common.LogError("getCrazyBullOdds has err", oddsName, oddsStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		typeOdds[pokerType] = oddsNum
+	}
+	return typeOdds, nil
+}
+
+// getBaseScore 获取房间的底分
+func getBaseScore(roomInfo *pb.RoomInfo) (int64, *pb.ErrorMessage) {
+	baseScoreStr := common.GetRoomConfig(roomInfo, "BaseScore")
+	baseScore, err := strconv.ParseInt(baseScoreStr, 10, 64)
+	if err != nil || baseScore <= 0 {
+		common.LogError("CrazyBull getBaseScore has err", baseScoreStr, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return baseScore, nil
+}
+
+// getOddsList 获取逗号分隔的倍数列表配置，如抢庄倍数RushVillageOdds、下注倍数BetOdds
+func getOddsList(roomInfo *pb.RoomInfo, configName string) ([]int64, *pb.ErrorMessage) {
+	var oddsList []int64
+	for _, oddsStr := range strings.Split(common.GetRoomConfig(roomInfo, configName), ",") {
+		odds, err := strconv.ParseInt(oddsStr, 10, 64)
+		if err != nil || odds < 0 {
+			common.LogError("CrazyBull getOddsList has err", configName, oddsStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		oddsList = append(oddsList, odds)
+	}
+	return oddsList, nil
+}
+
+// isInOddsList 判断倍数是否是配置中可以选择的倍数
+func isInOddsList(oddsList []int64, odds int64) bool {
+	for _, oneOdds := range oddsList {
+		if oneOdds == odds {
+			return true
+		}
+	}
+	return false
+}
+
+// getMinOdds 获取倍数列表中最小的倍数，超时自动操作时使用
+func getMinOdds(oddsList []int64) int64 {
+	var minOdds int64
+	for index, odds := range oddsList {
+		if index == 0 || odds < minOdds {
+			minOdds = odds
+		}
+	}
+	return minOdds
+}
+
+// newBullHand 根据五张手牌生成手牌信息
+func newBullHand(pokers []*pb.Poker) *bullHand {
+	return &bullHand{
+		pokers:    pokers,
+		pokerType: common.GetBullPokerType(pokers),
+	}
+}
+
+// isBigger 判断a的手牌是否比b大
+func (a *bullHand) isBigger(b *bullHand) bool {
+	return common.CompareBullPokers(a.pokers, a.pokerType, b.pokers, b.pokerType)
+}
+
+// getPlayPlayers 获取本局参与游戏的玩家
+func getPlayPlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+			continue
+		}
+		players = append(players, onePlayer)
+	}
+	return players
+}
+
+// isBanker 判断玩家是否是本局的庄家
+func isBanker(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) bool {
+	return roomInfo.GetCrazyBullMultipleuuid() != "" && roomInfo.GetCrazyBullMultipleuuid() == onePlayer.GetUuid()
+}
+
+// isOnline 判断玩家是否在线，出错时按照不在线处理
+func isOnline(onePlayer *pb.RoomPlayerInfo) bool {
+	online, msgErr := common.Pusher.CheckOnline(onePlayer.GetUuid())
+	if msgErr != nil {
+		common.LogError("CrazyBull isOnline CheckOnline has err", onePlayer.GetUuid(), msgErr)
+		return false
+	}
+	return online
+}
+
+// chooseBanker 根据玩家的抢庄倍数选出庄家
+// 抢庄倍数最高的玩家中随机一个坐庄，都不抢时在所有玩家中随机，庄家倍数最低为1
+// 返回值：庄家，庄家倍数，抢庄倍数最高的玩家
+func chooseBanker(players []*pb.RoomPlayerInfo) (*pb.RoomPlayerInfo, int64, []*pb.RoomPlayerInfo) {
+	var maxOdds int64
+	var candidates []*pb.RoomPlayerInfo
+	for _, onePlayer := range players {
+		if onePlayer.GetCrazyBullOdds() > maxOdds {
+			maxOdds = onePlayer.GetCrazyBullOdds()
+			candidates = nil
+		}
+		if onePlayer.GetCrazyBullOdds() == maxOdds {
+			candidates = append(candidates, onePlayer)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, 0, nil
+	}
+	banker := candidates[rand.Intn(len(candidates))]
+	if maxOdds <= 0 {
+		maxOdds = 1
+	}
+	return banker, maxOdds, candidates
+}
+
+// getSettleWinOrLose 计算每个玩家本局的输赢（未抽水）
+// 闲家只和庄家比牌，输赢为底分*庄家倍数*闲家下注倍数*赢家牌型赔率
+// 闲家最多输掉身上的金额；庄家赔付的总额超过身上的金额加上赢到的钱时，按比例缩减赔给每个闲家的金额
+// 参数：players 参与游戏的玩家，hands 与玩家一一对应的手牌，bankerIndex 庄家在players中的下标
+// 返回值：与玩家一一对应的输赢
+func getSettleWinOrLose(players []*pb.RoomPlayerInfo, hands []*bullHand, bankerIndex int, bankerOdds int64, typeOdds map[pb.CrazyBullPokerType]int64, baseScore int64) []int64 {
+	winOrLose := make([]int64, len(players))
+	if bankerIndex < 0 || bankerIndex >= len(players) {
+		return winOrLose
+	}
+	bankerHand := hands[bankerIndex]
+	// 庄家从输家赢到的钱
+	var bankerWin int64
+	// 庄家需要赔给各个赢家的钱
+	bankerPay := make([]int64, len(players))
+	var allBankerPay int64
+	for index, onePlayer := range players {
+		if index == bankerIndex {
+			continue
+		}
+		playOdds := onePlayer.GetCrazyBullPlayOdds()
+		if playOdds <= 0 {
+			playOdds = 1
+		}
+		if hands[index].isBigger(bankerHand) {
+			bankerPay[index] = baseScore * bankerOdds * playOdds * typeOdds[hands[index].pokerType]
+			allBankerPay += bankerPay[index]
+			continue
+		}
+		lose := baseScore * bankerOdds * playOdds * typeOdds[bankerHand.pokerType]
+		if lose > onePlayer.GetBalance() {
+			lose = onePlayer.GetBalance()
+		}
+		if lose < 0 {
+			lose = 0
+		}
+		winOrLose[index] -= lose
+		bankerWin += lose
+	}
+	bankerCanPay := players[bankerIndex].GetBalance() + bankerWin
+	if bankerCanPay < 0 {
+		bankerCanPay = 0
+	}
+	for index, payNum := range bankerPay {
+		if payNum <= 0 {
+			continue
+		}
+		if allBankerPay > bankerCanPay {
+			payNum = payNum * bankerCanPay / allBankerPay
+		}
+		winOrLose[index] += payNum
+		bankerWin -= payNum
+	}
+	winOrLose[bankerIndex] = bankerWin
+	return winOrLose
+}
+
+// getSystemScore 计算比牌结果下平台的收益（真实玩家输的钱）
+func getSystemScore(players []*pb.RoomPlayerInfo, hands []*bullHand, bankerIndex int, bankerOdds int64, typeOdds map[pb.CrazyBullPokerType]int64, baseScore int64) int64 {
+	var score int64
+	for index, winOrLose := range getSettleWinOrLose(players, hands, bankerIndex, bankerOdds, typeOdds, baseScore) {
+		if players[index].GetIsRobot() {
+			continue
+		}
+		score -= winOrLose
+	}
+	return score
+}
+
+// dealPokers 从一副洗好的牌中给每个玩家发五张牌
+// 返回值：与玩家一一对应的手牌，剩下的牌堆
+func dealPokers(playerNum int) ([][]*pb.Poker, []*pb.Poker) {
+	cardHeap := common.GetShufflePokerHeap(1)
+	allPokers := make([][]*pb.Poker, playerNum)
+	for index := 0; index < playerNum; index++ {
+		allPokers[index] = append([]*pb.Poker{}, cardHeap[index*handPokerNum:(index+1)*handPokerNum]...)
+	}
+	return allPokers, cardHeap[playerNum*handPokerNum:]
+}
+
+// redrawLastPokers 把所有玩家的最后一张牌和剩下的牌堆放在一起，重新给每个玩家发最后一张
+func redrawLastPokers(allPokers [][]*pb.Poker, cardHeap []*pb.Poker) ([]*bullHand, []*pb.Poker) {
+	var pool []*pb.Poker
+	for _, pokers := range allPokers {
+		pool = append(pool, pokers[publishPokerNum:]...)
+	}
+	pool = append(pool, cardHeap...)
+	rand.Shuffle(len(pool), func(i, j int) {
+		pool[i], pool[j] = pool[j], pool[i]
+	})
+	hands := make([]*bullHand, len(allPokers))
+	lastNum := handPokerNum - publishPokerNum
+	for index, pokers := range allPokers {
+		newPokers := append([]*pb.Poker{}, pokers[:publishPokerNum]...)
+		newPokers = append(newPokers, pool[index*lastNum:(index+1)*lastNum]...)
+		hands[index] = newBullHand(newPokers)
+	}
+	return hands, pool[len(allPokers)*lastNum:]
+}
+
+// dealLastByControl 根据血池状态确定每个玩家的最后一张牌
+// 前四张牌玩家已经看到了，不做改动；血池需要控制时多次重新分配最后一张牌，选择平台收益最高（或最低）的一次
+// 返回值：与玩家一一对应的手牌，剩下的牌堆
+func dealLastByControl(players []*pb.RoomPlayerInfo, cardHeap []*pb.Poker, bankerIndex int, bankerOdds int64, bloodState pb.BloodSlotStatus, typeOdds map[pb.CrazyBullPokerType]int64, baseScore int64) ([]*bullHand, []*pb.Poker) {
+	allPokers := make([][]*pb.Poker, len(players))
+	bestHands := make([]*bullHand, len(players))
+	for index, onePlayer := range players {
+		allPokers[index] = onePlayer.GetPokers()
+		bestHands[index] = newBullHand(onePlayer.GetPokers())
+	}
+	bestHeap := cardHeap
+	if bloodState != pb.BloodSlotStatus_BloodSlotStatus_Win && bloodState != pb.BloodSlotStatus_BloodSlotStatus_Lose {
+		return bestHands, bestHeap
+	}
+	bestScore := getSystemScore(players, bestHands, bankerIndex, bankerOdds, typeOdds, baseScore)
+	for try := 0; try < controlTryNum; try++ {
+		hands, heap := redrawLastPokers(allPokers, cardHeap)
+		score := getSystemScore(players, hands, bankerIndex, bankerOdds, typeOdds, baseScore)
+		if (bloodState == pb.BloodSlotStatus_BloodSlotStatus_Win && score > bestScore) ||
+			(bloodState == pb.BloodSlotStatus_BloodSlotStatus_Lose && score < bestScore) {
+			bestScore = score
+			bestHands = hands
+			bestHeap = heap
+		}
+	}
+	return bestHands, bestHeap
+}
+
+// isSamePokers 判断两组牌是否是同样的几张牌（不区分顺序）
+func isSamePokers(a []*pb.Poker, b []*pb.Poker) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, aPoker := range a {
+		found := false
+		for index, bPoker := range b {
+			if used[index] || aPoker.GetPokerNum() != bPoker.GetPokerNum() || aPoker.GetPokerColor() != bPoker.GetPokerColor() {
+				continue
+			}
+			used[index] = true
+			found = true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["CrazyBullRushVillage"] = &CrazyBullRushVillage{}
+}
+
+// CrazyBullRushVillage 疯狂牛牛游戏的抢庄组件，用于处理抢庄阶段的逻辑
+type CrazyBullRushVillage struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *CrazyBullRushVillage) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *CrazyBullRushVillage) Start() {
+	obj.Base.Start()
+}
+
+// Drive 疯狂牛牛抢庄阶段的主驱动
+// 玩家看着前四张牌选择抢庄倍数，所有玩家都选择了或者抢庄时间到了就确定庄家进入下注阶段
+// 时间到了还没选择的玩家和断线的玩家由系统选择不抢
+func (obj *CrazyBullRushVillage) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateRushVillage {
+		if nowTime < request.DoTime && !obj.isAllRush(request) {
+			return request, nil
+		}
+		for _, onePlayer := range getPlayPlayers(request) {
+			if !obj.isRush(request, onePlayer) {
+				obj.rushVillage(request, onePlayer, 0)
+			}
+		}
+		obj.confirmBanker(request)
+		request.CurRoomState = pb.RoomState_RoomStateBet
+		request.NextRoomState = pb.RoomState_RoomStateBet
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	rushVillageTimeStr := common.GetRoomConfig(request, "RushVillageTime")
+	rushVillageTime, err := strconv.Atoi(rushVillageTimeStr)
+	if err != nil {
+		common.LogError("CrazyBullRushVillage Drive rushVillageTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 推送房间状态 发牌<->抢庄
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateDeal,
+		AfterState:        pb.RoomState_RoomStateRushVillage,
+		AfterStateEndTime: nowTime + int64(rushVillageTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	request.CrazyBullMultiples = nil
+	// 断线的玩家不用等待，直接不抢
+	for _, onePlayer := range getPlayPlayers(request) {
+		if !isOnline(onePlayer) {
+			obj.rushVillage(request, onePlayer, 0)
+		}
+	}
+	request.NextRoomState = pb.RoomState_RoomStateBet
+	request.DoTime = nowTime + int64(rushVillageTime)
+	return request, nil
+}
+
+// isRush 玩家是否已经选择了抢庄倍数，选择过的玩家记录在CrazyBullMultiples中
+func (obj *CrazyBullRushVillage) isRush(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) bool {
+	for _, uuid := range roomInfo.GetCrazyBullMultiples() {
+		if uuid == onePlayer.GetUuid() {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllRush 游戏中的玩家是否都选择了抢庄倍数
+func (obj *CrazyBullRushVillage) isAllRush(roomInfo *pb.RoomInfo) bool {
+	for _, onePlayer := range getPlayPlayers(roomInfo) {
+		if !obj.isRush(roomInfo, onePlayer) {
+			return false
+		}
+	}
+	return true
+}
+
+// rushVillage 记录玩家的抢庄倍数并广播，倍数为0表示不抢
+func (obj *CrazyBullRushVillage) rushVillage(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, odds int64) {
+	onePlayer.CrazyBullOdds = odds
+	roomInfo.CrazyBullMultiples = append(roomInfo.CrazyBullMultiples, onePlayer.GetUuid())
+	pushChangeBankers := &pb.PushCrazyBullChangeBankers{
+		RoomId:       roomInfo.GetUuid(),
+		CrazyBankers: roomInfo.GetCrazyBullMultiples(),
+		Uuid:         onePlayer.GetUuid(),
+		Odds:         odds,
+	}
+	common.RoomBroadcast(roomInfo, pushChangeBankers)
+}
+
+// confirmBanker 确定本局的庄家并广播
+func (obj *CrazyBullRushVillage) confirmBanker(roomInfo *pb.RoomInfo) {
+	banker, bankerOdds, candidates := chooseBanker(getPlayPlayers(roomInfo))
+	if banker == nil {
+		return
+	}
+	banker.CrazyBullIsSuccess = true
+	roomInfo.CrazyBullMultipleuuid = banker.GetUuid()
+	roomInfo.CrazyBullMultipleuuidOdds = bankerOdds
+	roomInfo.CrazyBankers = nil
+	// 抢庄倍数最高的玩家用座位下标表示，客户端用于播放随机选庄的动画
+	var candidateIndexes []int64
+	for _, oneCandidate := range candidates {
+		roomInfo.CrazyBankers = append(roomInfo.CrazyBankers, oneCandidate.GetUuid())
+		for index, onePlayer := range roomInfo.GetPlayerInfo() {
+			if onePlayer.GetUuid() == oneCandidate.GetUuid() {
+				candidateIndexes = append(candidateIndexes, int64(index))
+				break
+			}
+		}
+	}
+	pushBankers := &pb.PushCrazyBullBankers{
+		RoomId:                roomInfo.GetUuid(),
+		CrazyBullMultipleuuid: banker.GetUuid(),
+		Odds:                  bankerOdds,
+		MultipleuuidUuid:      candidateIndexes,
+	}
+	common.RoomBroadcast(roomInfo, pushBankers)
+}
+
+// RequestRushVillage 玩家选择抢庄倍数，倍数必须是配置RushVillageOdds中的一个，0表示不抢
+func (obj *CrazyBullRushVillage) RequestRushVillage(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateRushVillage || roomInfo.GetNextRoomState() == pb.RoomState_RoomStateRushVillage {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.CrazyUpBankerRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("CrazyBullRushVillage RequestRushVillage ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("CrazyBullRushVillage RequestRushVillage player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if playerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	if obj.isRush(roomInfo, playerInfo) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	rushOddsList, msgErr := getOddsList(roomInfo, "RushVillageOdds")
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	if !isInOddsList(rushOddsList, realRequest.GetRushVillage()) {
+		common.LogError("CrazyBullRushVillage RequestRushVillage odds not in config", uid, realRequest.GetRushVillage())
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+	}
+	obj.rushVillage(roomInfo, playerInfo, realRequest.GetRushVillage())
+	return packReply(roomInfo, &pb.CrazyUpBankerReply{IsSuccess: true})
+}
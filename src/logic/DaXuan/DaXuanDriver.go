@@ -0,0 +1,303 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+)
+
+func init() {
+	common.AllComponentMap["DaXuanDriver"] = &DaXuanDriver{}
+}
+
+// DaXuanDriver 打旋游戏的房间管理组件，负责处理玩家请求操作
+// 打旋只在抢座模式下开放，玩家进入后先旁观，上座带入钵钵后才能参与游戏
+type DaXuanDriver struct {
+	base.Base
+	rm *common.RoomManager
+}
+
+var maxRoomNumCfgName = "DaXuanMaxRoomNumOneServer"
+
+// LoadComponent 加载组件
+func (obj *DaXuanDriver) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	// 这是一个多线路组件
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DaXuanDriver) Start() {
+	obj.Base.Start()
+
+	initGlobleConfigNameArr := []string{
+		maxRoomNumCfgName,
+	}
+	err := common.InitGlobleConfigTemp(initGlobleConfigNameArr)
+	if err != nil {
+		panic(err)
+	}
+	//将模板参数保存到内存中
+	common.InitGameConfigTemp(common.DaXuanGameConfigTemp, pb.GameType_DaXuan)
+
+	obj.rm = new(common.RoomManager)
+	tableName := common.GetRoomRedisName(pb.GameType_DaXuan, common.ServerIndex)
+	obj.rm.InitRoomManager(pb.GameType_DaXuan, tableName, obj.DriveRoom)
+	//从redis里恢复房间数据（会导致配置不更新, 要刷新房间需要清空redis）
+	err = obj.rm.ReLoadRooms()
+	if err != nil {
+		panic(err)
+	}
+	obj.rm.ReStartRooms()
+	common.LogDebug("❤打旋服务初始化❤")
+}
+
+// DriveRoom 这个方法提供房间驱动的具体逻辑
+// (room公共驱动调用到这里，通过这个函数根据房间状态调用相应的房间逻辑)
+func (obj *DaXuanDriver) DriveRoom(roomInfo *pb.RoomInfo) *pb.RoomInfo {
+	extraInfo := &pb.MessageExtroInfo{}
+	afterRoomInfo := &pb.RoomInfo{}
+
+	// 根据状态获取需要调用的组件名
+	componentName := (*obj.Base.Config)[pb.RoomState_name[int32(roomInfo.CurRoomState)]]
+	if componentName == "" {
+		common.LogError("DaXuan DriveRoom get componentName has empty")
+		return nil
+	}
+	msgErr := common.Router.Call(componentName, "Drive", roomInfo, afterRoomInfo, extraInfo)
+	if msgErr != nil {
+		common.LogError("DaXuan DriveRoom call ", componentName, " Drive has err", msgErr)
+		return afterRoomInfo
+	}
+	return afterRoomInfo
+}
+
+// RequestJoinRoom 加入房间逻辑
+// 不指定房间并且不在房间中时创建一个新房间，新房间先放好8个空座位，进入的玩家都从旁观位开始
+func (obj *DaXuanDriver) RequestJoinRoom(request *pb.GameJoinRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameJoinRoomReply, *pb.ErrorMessage) {
+	reply := &pb.GameJoinRoomReply{}
+	if !common.CheckModeOpen(pb.GameMode_GameMode_Grab) {
+		common.LogError("DaXuanDriver RequestJoinRoom grab mode not open")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidRequest, "")
+	}
+	roomInfo, msgErr := common.GameDriverJoinRoomWithCreateRoomFunc(request, maxRoomNumCfgName, obj.rm, extroInfo, func(room *pb.RoomInfo) *pb.ErrorMessage {
+		for index := 0; index < seatNum; index++ {
+			room.PlayerInfo = append(room.PlayerInfo, &pb.RoomPlayerInfo{})
+		}
+		room.DaXuanInRoom = &pb.DaXuanInRoom{}
+		// 抢座模式只能进入指定的房间，创建后直接进入这个房间
+		request.RoomUUID = room.GetUuid()
+		return nil
+	})
+	if msgErr != nil {
+		common.LogError("DaXuanDriver gameDriverJoinRoom get RoomInfo has error:", msgErr)
+		return nil, msgErr
+	}
+	reply.RoomInfo = roomInfo
+	return reply, msgErr
+}
+
+// RequestExitRoom 退出房间逻辑
+// 游戏中的玩家不能直接退出，这时按弃牌处理并标记为等待踢出，本局结算后由房间的Kick踢出
+func (obj *DaXuanDriver) RequestExitRoom(request *pb.GameExitRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameExitRoomReply, *pb.ErrorMessage) {
+	reply := &pb.GameExitRoomReply{}
+	msgErr := common.GameDriverExitRoom(obj.rm, extroInfo)
+	if msgErr == nil || msgErr.GetCode() != pb.ErrorCode_NotAllowExitRoom {
+		return reply, msgErr
+	}
+	msgErr = common.GameDriverDo("DaXuanPlay", "RequestExitInGame", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestFold 玩家弃牌
+func (obj *DaXuanDriver) RequestFold(request *pb.DaXuanFoldRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanFoldReply, *pb.ErrorMessage) {
+	reply := &pb.DaXuanFoldReply{}
+	msgErr := common.GameDriverDo("DaXuanPlay", "RequestFold", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestPass 玩家休（不下注）
+func (obj *DaXuanDriver) RequestPass(request *pb.DaXuanPassRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanPassReply, *pb.ErrorMessage) {
+	reply := &pb.DaXuanPassReply{}
+	msgErr := common.GameDriverDo("DaXuanPlay", "RequestPass", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestCall 玩家本局第一个下注（大）
+func (obj *DaXuanDriver) RequestCall(request *pb.DaXuanCallRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanCallReply, *pb.ErrorMessage) {
+	reply := &pb.DaXuanCallReply{}
+	msgErr := common.GameDriverDo("DaXuanPlay", "RequestCall", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestFollow 玩家跟注
+func (obj *DaXuanDriver) RequestFollow(request *pb.DaXuanFollowRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanFollowReply, *pb.ErrorMessage) {
+	reply := &pb.DaXuanFollowReply{}
+	msgErr := common.GameDriverDo("DaXuanPlay", "RequestFollow", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestPlus 玩家加注
+func (obj *DaXuanDriver) RequestPlus(request *pb.DaXuanPlusRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanPlusReply, *pb.ErrorMessage) {
+	reply := &pb.DaXuanPlusReply{}
+	msgErr := common.GameDriverDo("DaXuanPlay", "RequestPlus", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestAllIn 玩家把钵钵全部押上（梭）
+func (obj *DaXuanDriver) RequestAllIn(request *pb.DaXuanAllInRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanAllInReply, *pb.ErrorMessage) {
+	reply := &pb.DaXuanAllInReply{}
+	msgErr := common.GameDriverDo("DaXuanPlay", "RequestAllIn", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestPart 玩家分牌
+func (obj *DaXuanDriver) RequestPart(request *pb.DaXuanPartRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanPartReply, *pb.ErrorMessage) {
+	reply := &pb.DaXuanPartReply{}
+	msgErr := common.GameDriverDo("DaXuanPart", "RequestPart", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestUpSeat 玩家上座，第一次上座需要带入钵钵
+func (obj *DaXuanDriver) RequestUpSeat(request *pb.DaXuanUpSeatRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanUpSeatReply, *pb.ErrorMessage) {
+	playerInfo, msgErr := loadPlayer(extroInfo)
+	if msgErr != nil {
+		return &pb.DaXuanUpSeatReply{}, msgErr
+	}
+	return obj.rm.PlayerUpSeat(playerInfo, request, extroInfo)
+}
+
+// RequestTopUp 玩家补充钵钵
+func (obj *DaXuanDriver) RequestTopUp(request *pb.DaXuanTopUpRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanTopUpReply, *pb.ErrorMessage) {
+	playerInfo, msgErr := loadPlayer(extroInfo)
+	if msgErr != nil {
+		return &pb.DaXuanTopUpReply{}, msgErr
+	}
+	return obj.rm.TopUp(playerInfo, request, extroInfo)
+}
+
+// RequestDownSeat 玩家下座，游戏中申请下座的本局结束后才下座
+func (obj *DaXuanDriver) RequestDownSeat(request *pb.DaXuanDownSeatRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanDownSeatReply, *pb.ErrorMessage) {
+	reply := &pb.DaXuanDownSeatReply{}
+	msgErr := common.GameDriverDo("DaXuanReady", "RequestDownSeat", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestKeepSeat 玩家保座，保座期间不参与游戏，座位也不会被别人抢走
+func (obj *DaXuanDriver) RequestKeepSeat(request *pb.DaXuanKeepSeatRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanKeepSeatReply, *pb.ErrorMessage) {
+	reply := &pb.DaXuanKeepSeatReply{}
+	msgErr := common.GameDriverDo("DaXuanReady", "RequestKeepSeat", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestBackSeat 玩家取消保座回到座位
+func (obj *DaXuanDriver) RequestBackSeat(request *pb.DaXuanBackSeatRequest, extroInfo *pb.MessageExtroInfo) (*pb.DaXuanBackSeatReply, *pb.ErrorMessage) {
+	reply := &pb.DaXuanBackSeatReply{}
+	msgErr := common.GameDriverDo("DaXuanReady", "RequestBackSeat", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// GetRooms 获取本线路上所有的房间，供大厅房间列表使用，牌的信息都去掉
+func (obj *DaXuanDriver) GetRooms(request *pb.GetRoomsDaXuanRequest, extroInfo *pb.MessageExtroInfo) (*pb.GetRoomsDaXuanReply, *pb.ErrorMessage) {
+	reply := &pb.GetRoomsDaXuanReply{}
+	for _, roomInfo := range obj.rm.GetAllRoomInfo() {
+		if request.GetRoomsType() != pb.DaXuanRoomsType_DaXuanRooms_All && roomInfo.GetGameScene() != int32(request.GetRoomsType())+1 {
+			continue
+		}
+		roomInfo.PokerCardHeap = nil
+		roomInfo.GameReview = nil
+		roomInfo.AllSettleInfo = nil
+		for _, onePlayer := range roomInfo.GetPlayerInfo() {
+			onePlayer.DaXuanPrivatePoker = nil
+			onePlayer.DaXuanPublicPoker = nil
+			onePlayer.Pokers = nil
+			onePlayer.DaXuanPokerLog = nil
+		}
+		reply.Rooms = append(reply.Rooms, roomInfo)
+	}
+	return reply, nil
+}
+
+// GetGameReview 获取玩家所在房间的上局回顾
+func (obj *DaXuanDriver) GetGameReview(request *pb.GetGameReviewRequest, extroInfo *pb.MessageExtroInfo) (*pb.GetGameReviewReply, *pb.ErrorMessage) {
+	reply := &pb.GetGameReviewReply{}
+	msgErr := common.GameDriverDo("DaXuanSettle", "GetGameReview", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// GetRecord 获取玩家所在房间的战绩，座位上的玩家和旁观、已经离开的玩家分开
+func (obj *DaXuanDriver) GetRecord(request *pb.GetRecordRequest, extroInfo *pb.MessageExtroInfo) (*pb.GetRecordReply, *pb.ErrorMessage) {
+	reply := &pb.GetRecordReply{}
+	msgErr := common.GameDriverDo("DaXuanSettle", "GetRecord", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// SyncRoomPlayerInfo 同步玩家房间玩家信息
+func (obj *DaXuanDriver) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo, extroInfo *pb.MessageExtroInfo) (*pb.EmptyMessage, *pb.ErrorMessage) {
+	reply := &pb.EmptyMessage{}
+	msgErr := common.GameDriverSyncRoomPlayerInfo(request, obj.rm, extroInfo)
+	return reply, msgErr
+}
+
+// DelRoom 删除某房间
+func (obj *DaXuanDriver) DelRoom(request *pb.DelRoomByGameTypeRequest, extraInfo *pb.MessageExtroInfo) (*pb.DelRoomByGameTypeReply, *pb.ErrorMessage) {
+	reply := &pb.DelRoomByGameTypeReply{}
+	// 根据uuid删除房间
+	err := obj.rm.DeleteRoom(request.GetRoomUUID(), true)
+	if err != nil {
+		common.LogError("DaXuanDriver DelRoom has err:", request.GameType, err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return reply, nil
+}
+
+// loadPlayer 获取请求玩家的信息
+func loadPlayer(extroInfo *pb.MessageExtroInfo) (*pb.PlayerInfo, *pb.ErrorMessage) {
+	loadPlayerRequest := &pb.LoadPlayerRequest{}
+	loadPlayerRequest.Uuid = extroInfo.GetUserId()
+	loadPlayerReply := &pb.LoadPlayerReply{}
+	msgErr := common.Router.Call("PlayerInfo", "LoadPlayer", loadPlayerRequest, loadPlayerReply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	return loadPlayerReply.GetPlayerInfo(), nil
+}
@@ -0,0 +1,141 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["DaXuanPart"] = &DaXuanPart{}
+}
+
+// DaXuanPart 打旋游戏的分牌组件
+// 没有弃牌的玩家把四张牌分成头牌和尾牌两组，尾牌不能比头牌小，超时没分的由系统自动分牌
+type DaXuanPart struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DaXuanPart) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DaXuanPart) Start() {
+	obj.Base.Start()
+}
+
+// Drive 打旋分牌阶段的主驱动
+func (obj *DaXuanPart) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowTime := time.Now().Unix()
+	nowMilliTime := time.Now().UnixNano() / 1e6
+	if request.GetNextRoomState() == pb.RoomState_RoomStatePart {
+		partTime, msgErr := getRoomConfigInt64(request, "PartTime")
+		if msgErr != nil {
+			return request, msgErr
+		}
+		// 推送房间状态 玩耍<->分牌
+		pushRoomState := &pb.PushRoomStateChange{
+			RoomId:            request.GetUuid(),
+			BeforeState:       pb.RoomState_RoomStatePlay,
+			AfterState:        pb.RoomState_RoomStatePart,
+			AfterStateEndTime: nowTime + partTime,
+		}
+		common.RoomBroadcast(request, pushRoomState)
+
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		request.DoTime = nowTime + partTime
+		request.MilliDoTime = nowMilliTime + partTime*1000
+		return request, nil
+	}
+
+	if nowMilliTime < request.GetMilliDoTime() {
+		return request, nil
+	}
+	// 超时没有分牌的玩家自动分牌
+	for _, onePlayer := range getActivePlayers(request) {
+		if onePlayer.GetDaXuanPokerLog() != nil {
+			continue
+		}
+		partPokers, pokerTypes := autoPart(getHandPokers(onePlayer), request.GetDaXuanIsDiJiuWang())
+		setPart(onePlayer, partPokers, pokerTypes)
+	}
+	request.CurRoomState = pb.RoomState_RoomStateSettle
+	request.NextRoomState = pb.RoomState_RoomStateSettle
+	request.DoTime = nowTime
+	return request, nil
+}
+
+// setPart 记录玩家分好的牌，前两张是头牌，后两张是尾牌
+func setPart(onePlayer *pb.RoomPlayerInfo, partPokers []*pb.Poker, pokerTypes []pb.DaXuanPokerType) {
+	onePlayer.DaXuanPokerLog = &pb.DaXuanPokerLog{
+		PartPokers: partPokers,
+		PokerTypes: pokerTypes,
+		PokerFlag: []pb.DaXuanPartPokerFlag{
+			pb.DaXuanPartPokerFlag_DaXuanPartPokerFlag_Start,
+			pb.DaXuanPartPokerFlag_DaXuanPartPokerFlag_Start,
+			pb.DaXuanPartPokerFlag_DaXuanPartPokerFlag_Tail,
+			pb.DaXuanPartPokerFlag_DaXuanPartPokerFlag_Tail,
+		},
+	}
+}
+
+// RequestPart 玩家分牌，牌型以服务器计算的为准，所有玩家都分好后提前进入结算
+func (obj *DaXuanPart) RequestPart(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePart || roomInfo.GetNextRoomState() == pb.RoomState_RoomStatePart {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.DaXuanPartRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("DaXuanPart RequestPart ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("DaXuanPart RequestPart player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if !isActive(playerInfo) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	if playerInfo.GetDaXuanPokerLog() != nil {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	partPokers := realRequest.GetPartPokers()
+	if !isSamePokers(partPokers, getHandPokers(playerInfo)) {
+		common.LogError("DaXuanPart RequestPart part pokers not in hand", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+	}
+	pokerTypes, isValid := getPartResult(partPokers, roomInfo.GetDaXuanIsDiJiuWang())
+	if !isValid {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+	}
+	setPart(playerInfo, partPokers, pokerTypes)
+
+	allParted := true
+	for _, onePlayer := range getActivePlayers(roomInfo) {
+		if onePlayer.GetDaXuanPokerLog() == nil {
+			allParted = false
+			break
+		}
+	}
+	if allParted {
+		interval, msgErr := getRoomConfigInt64(roomInfo, "OperateIntervalMilli")
+		if msgErr != nil {
+			return reply, msgErr
+		}
+		roomInfo.MilliDoTime = time.Now().UnixNano()/1e6 + interval
+	}
+	return packReply(roomInfo, &pb.DaXuanPartReply{
+		PartPokers: partPokers,
+		PokerTypes: pokerTypes,
+	})
+}
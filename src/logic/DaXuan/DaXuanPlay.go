@@ -0,0 +1,510 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["DaXuanPlay"] = &DaXuanPlay{}
+}
+
+// DaXuanPlay 打旋游戏的玩耍组件，负责下芒果、发牌和三轮说话
+// 先发两张暗牌，然后每说完一轮话发一张明牌，第三轮说完后进入分牌阶段
+type DaXuanPlay struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DaXuanPlay) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DaXuanPlay) Start() {
+	obj.Base.Start()
+}
+
+// Drive 打旋玩耍阶段的主驱动
+func (obj *DaXuanPlay) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowMilliTime := time.Now().UnixNano() / 1e6
+	daXuanInRoom := request.GetDaXuanInRoom()
+	if request.GetNextRoomState() == pb.RoomState_RoomStatePlay {
+		// 推送房间状态 准备<->玩耍
+		pushRoomState := &pb.PushRoomStateChange{
+			RoomId:      request.GetUuid(),
+			BeforeState: pb.RoomState_RoomStateReady,
+			AfterState:  pb.RoomState_RoomStatePlay,
+		}
+		common.RoomBroadcast(request, pushRoomState)
+
+		request.NextRoomState = pb.RoomState_RoomStatePart
+		msgErr := obj.putMongo(request)
+		if msgErr != nil {
+			return request, msgErr
+		}
+		obj.dealPrivate(request)
+		msgErr = obj.waitDeal(request, nowMilliTime)
+		return request, msgErr
+	}
+
+	if nowMilliTime < request.GetMilliDoTime() {
+		return request, nil
+	}
+	switch daXuanInRoom.GetCurDaXuanOperateStep() {
+	case pb.DaXuanOperateStep_DaXuanOperate_DealFirst, pb.DaXuanOperateStep_DaXuanOperate_DealSecond, pb.DaXuanOperateStep_DaXuanOperate_DealThird:
+		// 发完牌开始新一轮说话，从庄家开始
+		daXuanInRoom.CurDaXuanOperateStep++
+		daXuanInRoom.PlayerDoTypeMessegeInfo = nil
+		daXuanInRoom.CurDoUuid = ""
+		request.DoIndex = int32(request.GetDaXuanMultipleIndex()) - 1
+		return request, obj.nextTurn(request, nowMilliTime)
+	}
+	if daXuanInRoom.GetCurDoUuid() != "" {
+		// 操作超时，能休的休，否则弃牌
+		curPlayer := common.GetRoomPlayerInfo(request, daXuanInRoom.GetCurDoUuid())
+		if curPlayer != nil && isActive(curPlayer) {
+			msgErr := obj.autoOperate(request, curPlayer, nowMilliTime)
+			return request, msgErr
+		}
+		daXuanInRoom.CurDoUuid = ""
+	}
+	return request, obj.nextTurn(request, nowMilliTime)
+}
+
+// putMongo 参与游戏的玩家每人下一个芒果到皮池
+func (obj *DaXuanPlay) putMongo(request *pb.RoomInfo) *pb.ErrorMessage {
+	mongo, msgErr := getRoomConfigInt64(request, "Mongo")
+	if msgErr != nil {
+		return msgErr
+	}
+	daXuanInRoom := request.GetDaXuanInRoom()
+	daXuanInRoom.Mongo = mongo
+	for _, onePlayer := range getPlayPlayers(request) {
+		onePlayer.SafeMoney -= mongo
+		onePlayer.PlayerMango = mongo
+		daXuanInRoom.PPool += mongo
+	}
+	return nil
+}
+
+// dealPrivate 给参与游戏的玩家每人发两张暗牌，暗牌只推送给自己
+func (obj *DaXuanPlay) dealPrivate(request *pb.RoomInfo) {
+	heap := getShuffleDaXuanHeap()
+	playPlayers := getPlayPlayers(request)
+	var allChangeId []string
+	for _, onePlayer := range playPlayers {
+		onePlayer.DaXuanPrivatePoker = heap[:2]
+		heap = heap[2:]
+		allChangeId = append(allChangeId, onePlayer.GetUuid())
+	}
+	request.PokerCardHeap = heap
+	request.GetDaXuanInRoom().CurDaXuanOperateStep = pb.DaXuanOperateStep_DaXuanOperate_DealFirst
+	for _, onePlayer := range playPlayers {
+		pushToOthers := &pb.PushPlayerCardChange{
+			RoomId:      request.GetUuid(),
+			UserId:      onePlayer.GetUuid(),
+			BankerIndex: int32(request.GetDaXuanMultipleIndex()),
+			AllChangeId: allChangeId,
+		}
+		pushToSelf := &pb.PushPlayerCardChange{
+			RoomId:      request.GetUuid(),
+			UserId:      onePlayer.GetUuid(),
+			BankerIndex: int32(request.GetDaXuanMultipleIndex()),
+			HandPoker:   onePlayer.GetDaXuanPrivatePoker(),
+			InPoker:     onePlayer.GetDaXuanPrivatePoker(),
+			AllChangeId: allChangeId,
+		}
+		msgErr := common.PushRoom(pushToSelf, pushToOthers, onePlayer.GetUuid(), request)
+		if msgErr != nil {
+			common.LogError("DaXuanPlay dealPrivate PushRoom has err", onePlayer.GetUuid(), msgErr)
+		}
+	}
+}
+
+// dealPublic 给没有弃牌的玩家每人发一张明牌，所有人都能看到
+func (obj *DaXuanPlay) dealPublic(request *pb.RoomInfo) {
+	pushPublic := &pb.PublicPokersMessege{RoomId: request.GetUuid()}
+	for _, onePlayer := range getActivePlayers(request) {
+		if len(request.GetPokerCardHeap()) == 0 {
+			common.LogError("DaXuanPlay dealPublic PokerCardHeap is empty", request.GetUuid())
+			break
+		}
+		onePlayer.DaXuanPublicPoker = append(onePlayer.DaXuanPublicPoker, request.GetPokerCardHeap()[0])
+		request.PokerCardHeap = request.GetPokerCardHeap()[1:]
+		pushPublic.PublicPokers = append(pushPublic.PublicPokers, &pb.PublicPokers{
+			Uuid:   onePlayer.GetUuid(),
+			Pokers: onePlayer.GetDaXuanPublicPoker(),
+		})
+	}
+	common.RoomBroadcast(request, pushPublic)
+}
+
+// waitDeal 等待客户端播放发牌动画
+func (obj *DaXuanPlay) waitDeal(request *pb.RoomInfo, nowMilliTime int64) *pb.ErrorMessage {
+	dealTime, msgErr := getRoomConfigInt64(request, "DealTime")
+	if msgErr != nil {
+		return msgErr
+	}
+	request.MilliDoTime = nowMilliTime + dealTime*1000
+	request.DoTime = request.GetMilliDoTime() / 1000
+	return nil
+}
+
+// toState 本局的说话结束，进入下一个房间状态
+func (obj *DaXuanPlay) toState(request *pb.RoomInfo, roomState pb.RoomState, nowMilliTime int64) {
+	request.GetDaXuanInRoom().CurDoUuid = ""
+	request.CurRoomState = roomState
+	request.NextRoomState = roomState
+	request.DoTime = nowMilliTime / 1000
+}
+
+// canAct 玩家是否还能说话，没有弃牌并且还有钵钵
+func canAct(onePlayer *pb.RoomPlayerInfo) bool {
+	return isActive(onePlayer) && onePlayer.GetSafeMoney() > 0
+}
+
+// needAct 玩家本轮是否还需要说话，本轮还没说过话或者下注没有跟上最大注
+func needAct(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) bool {
+	daXuanInRoom := request.GetDaXuanInRoom()
+	if onePlayer.GetBetsCount() < daXuanInRoom.GetLastBet() {
+		return true
+	}
+	for _, oneMessage := range daXuanInRoom.GetPlayerDoTypeMessegeInfo() {
+		if oneMessage.GetDoUuid() == onePlayer.GetUuid() {
+			return false
+		}
+	}
+	return true
+}
+
+// isSpeakOver 本轮说话是否结束
+// 没有需要说话的玩家，或者只剩一个能说话的玩家并且他已经跟上最大注时本轮结束
+func isSpeakOver(request *pb.RoomInfo) bool {
+	var canActPlayers []*pb.RoomPlayerInfo
+	needActNum := 0
+	for _, onePlayer := range getActivePlayers(request) {
+		if !canAct(onePlayer) {
+			continue
+		}
+		canActPlayers = append(canActPlayers, onePlayer)
+		if needAct(request, onePlayer) {
+			needActNum++
+		}
+	}
+	if needActNum == 0 {
+		return true
+	}
+	return len(canActPlayers) == 1 && canActPlayers[0].GetBetsCount() >= request.GetDaXuanInRoom().GetLastBet()
+}
+
+// nextTurn 轮到下一个需要说话的玩家，本轮说话结束时发下一张牌或者进入下一个阶段
+// 只剩一个玩家没弃牌或者第一轮大家都休时直接结算，第三轮说完后分牌
+func (obj *DaXuanPlay) nextTurn(request *pb.RoomInfo, nowMilliTime int64) *pb.ErrorMessage {
+	daXuanInRoom := request.GetDaXuanInRoom()
+	if len(getActivePlayers(request)) <= 1 {
+		obj.toState(request, pb.RoomState_RoomStateSettle, nowMilliTime)
+		return nil
+	}
+	if isSpeakOver(request) {
+		switch {
+		case daXuanInRoom.GetCurDaXuanOperateStep() == pb.DaXuanOperateStep_DaXuanOperate_SpeakFirst && daXuanInRoom.GetLastBet() == 0:
+			obj.toState(request, pb.RoomState_RoomStateSettle, nowMilliTime)
+		case daXuanInRoom.GetCurDaXuanOperateStep() == pb.DaXuanOperateStep_DaXuanOperate_SpeakThird:
+			obj.toState(request, pb.RoomState_RoomStatePart, nowMilliTime)
+		default:
+			daXuanInRoom.CurDaXuanOperateStep++
+			daXuanInRoom.CurDoUuid = ""
+			obj.dealPublic(request)
+			return obj.waitDeal(request, nowMilliTime)
+		}
+		return nil
+	}
+	for step := 1; step <= seatNum; step++ {
+		index := ((int(request.GetDoIndex())+step)%seatNum + seatNum) % seatNum
+		if index >= len(request.GetPlayerInfo()) {
+			continue
+		}
+		onePlayer := request.GetPlayerInfo()[index]
+		if canAct(onePlayer) && needAct(request, onePlayer) {
+			return obj.startTurn(request, index, nowMilliTime)
+		}
+	}
+	common.LogError("DaXuanPlay nextTurn no player need act", request.GetUuid())
+	obj.toState(request, pb.RoomState_RoomStateSettle, nowMilliTime)
+	return nil
+}
+
+// startTurn 开始座位index的玩家的回合，断线或者已经退出的玩家自动操作
+func (obj *DaXuanPlay) startTurn(request *pb.RoomInfo, index int, nowMilliTime int64) *pb.ErrorMessage {
+	onePlayer := request.GetPlayerInfo()[index]
+	daXuanInRoom := request.GetDaXuanInRoom()
+	request.DoIndex = int32(index)
+	daXuanInRoom.CurDoUuid = onePlayer.GetUuid()
+	if onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None || !isOnline(onePlayer) {
+		return obj.autoOperate(request, onePlayer, nowMilliTime)
+	}
+
+	operateTime, msgErr := getRoomConfigInt64(request, "OperateTime")
+	if msgErr != nil {
+		return msgErr
+	}
+	request.MilliDoTime = nowMilliTime + operateTime*1000
+	request.DoTime = nowMilliTime/1000 + operateTime
+	betBalance := daXuanInRoom.GetLastBet() - onePlayer.GetBetsCount()
+	if daXuanInRoom.GetLastBet() == 0 {
+		betBalance = daXuanInRoom.GetPPool()
+	}
+	pushCurDo := &pb.CurDoPlayerMessege{
+		RoomId:     request.GetUuid(),
+		DoUuid:     onePlayer.GetUuid(),
+		EndTime:    request.GetDoTime(),
+		Operates:   getCanOperates(request, onePlayer),
+		MaxBetOdds: onePlayer.GetSafeMoney(),
+		BetBalance: betBalance,
+	}
+	common.RoomBroadcast(request, pushCurDo)
+	return nil
+}
+
+// getCanOperates 获取轮到说话的玩家可以做的操作
+// 没人下注时可以休或者大，有人下注时可以跟或者敲，钵钵不够跟的只能梭或者丢
+func getCanOperates(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) []pb.DaXuanDoType {
+	daXuanInRoom := request.GetDaXuanInRoom()
+	operates := []pb.DaXuanDoType{pb.DaXuanDoType_DaXuanDo_OperateFold}
+	need := daXuanInRoom.GetLastBet() - onePlayer.GetBetsCount()
+	if need <= 0 {
+		operates = append(operates, pb.DaXuanDoType_DaXuanDo_OperatePass)
+	}
+	if daXuanInRoom.GetLastBet() == 0 {
+		if onePlayer.GetSafeMoney() > daXuanInRoom.GetPPool() {
+			operates = append(operates, pb.DaXuanDoType_DaXuanDo_OperateCall)
+		}
+	} else {
+		if need > 0 && need < onePlayer.GetSafeMoney() {
+			operates = append(operates, pb.DaXuanDoType_DaXuanDo_OperateFollow)
+		}
+		if onePlayer.GetSafeMoney() > need {
+			operates = append(operates, pb.DaXuanDoType_DaXuanDo_OperatePlus)
+		}
+	}
+	return append(operates, pb.DaXuanDoType_DaXuanDo_OperateAllIn)
+}
+
+// autoOperate 玩家超时或者不在线时自动操作，能休的休，否则弃牌
+func (obj *DaXuanPlay) autoOperate(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, nowMilliTime int64) *pb.ErrorMessage {
+	if onePlayer.GetBetsCount() >= request.GetDaXuanInRoom().GetLastBet() {
+		return obj.operate(request, onePlayer, pb.DaXuanDoType_DaXuanDo_OperatePass, 0, nowMilliTime)
+	}
+	return obj.operate(request, onePlayer, pb.DaXuanDoType_DaXuanDo_OperateFold, 0, nowMilliTime)
+}
+
+// operate 轮到说话的玩家进行操作，betBalance是大和敲时玩家下的金额
+// 下注的金额立即从钵钵中扣除，钵钵全部下完的算作梭
+func (obj *DaXuanPlay) operate(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, doType pb.DaXuanDoType, betBalance int64, nowMilliTime int64) *pb.ErrorMessage {
+	daXuanInRoom := request.GetDaXuanInRoom()
+	need := daXuanInRoom.GetLastBet() - onePlayer.GetBetsCount()
+	safeMoney := onePlayer.GetSafeMoney()
+	var amount int64
+	switch doType {
+	case pb.DaXuanDoType_DaXuanDo_OperateFold:
+		onePlayer.IsFold = true
+	case pb.DaXuanDoType_DaXuanDo_OperatePass:
+		if need > 0 {
+			return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+		}
+	case pb.DaXuanDoType_DaXuanDo_OperateCall:
+		if daXuanInRoom.GetLastBet() != 0 {
+			return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+		}
+		if betBalance < daXuanInRoom.GetPPool() || betBalance > safeMoney {
+			return common.GetGrpcErrorMessage(pb.ErrorCode_BetRequestInvalid, "")
+		}
+		amount = betBalance
+	case pb.DaXuanDoType_DaXuanDo_OperateFollow:
+		if need <= 0 || need > safeMoney {
+			return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+		}
+		amount = need
+	case pb.DaXuanDoType_DaXuanDo_OperatePlus:
+		if daXuanInRoom.GetLastBet() == 0 {
+			return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+		}
+		if betBalance <= need || betBalance > safeMoney {
+			return common.GetGrpcErrorMessage(pb.ErrorCode_BetRequestInvalid, "")
+		}
+		amount = betBalance
+	case pb.DaXuanDoType_DaXuanDo_OperateAllIn:
+		amount = safeMoney
+	default:
+		return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+	}
+	if amount > 0 && amount == safeMoney {
+		doType = pb.DaXuanDoType_DaXuanDo_OperateAllIn
+	}
+	onePlayer.SafeMoney -= amount
+	onePlayer.BetsCount += amount
+	onePlayer.LastBet = amount
+	if onePlayer.GetBetsCount() > daXuanInRoom.GetLastBet() {
+		daXuanInRoom.LastBet = onePlayer.GetBetsCount()
+	}
+	obj.broadcastOperate(request, onePlayer, doType, amount)
+
+	interval, msgErr := getRoomConfigInt64(request, "OperateIntervalMilli")
+	if msgErr != nil {
+		return msgErr
+	}
+	daXuanInRoom.CurDoUuid = ""
+	request.MilliDoTime = nowMilliTime + interval
+	return nil
+}
+
+// broadcastOperate 记录玩家本轮的操作并广播
+func (obj *DaXuanPlay) broadcastOperate(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, doType pb.DaXuanDoType, amount int64) {
+	daXuanInRoom := request.GetDaXuanInRoom()
+	daXuanInRoom.LastDoUuid = onePlayer.GetUuid()
+	daXuanInRoom.LastDoType = doType
+	pushOperate := &pb.PlayerDoTypeMessege{
+		RoomId:     request.GetUuid(),
+		DoUuid:     onePlayer.GetUuid(),
+		Operates:   doType,
+		BetBalance: amount,
+		SafeMoney:  onePlayer.GetSafeMoney(),
+		Mongo:      daXuanInRoom.GetMongo(),
+		PPull:      daXuanInRoom.GetPPool(),
+	}
+	daXuanInRoom.PlayerDoTypeMessegeInfo = append(daXuanInRoom.PlayerDoTypeMessegeInfo, pushOperate)
+	common.RoomBroadcast(request, pushOperate)
+}
+
+// requestOperate 玩家说话的公共处理，只有轮到自己时才能操作
+func (obj *DaXuanPlay) requestOperate(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo, doType pb.DaXuanDoType, betBalance int64) (*pb.RoomInfo, *pb.ErrorMessage) {
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	daXuanInRoom := roomInfo.GetDaXuanInRoom()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || roomInfo.GetNextRoomState() == pb.RoomState_RoomStatePlay {
+		return roomInfo, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("DaXuanPlay requestOperate player not in room", uid)
+		return roomInfo, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if !isActive(playerInfo) || daXuanInRoom.GetCurDoUuid() != uid {
+		return roomInfo, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	msgErr := obj.operate(roomInfo, playerInfo, doType, betBalance, time.Now().UnixNano()/1e6)
+	return roomInfo, msgErr
+}
+
+// RequestFold 玩家丢牌
+func (obj *DaXuanPlay) RequestFold(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	roomInfo, msgErr := obj.requestOperate(request, extroInfo, pb.DaXuanDoType_DaXuanDo_OperateFold, 0)
+	if msgErr != nil {
+		return &pb.Driver2GameLogicInfo{}, msgErr
+	}
+	return packReply(roomInfo, &pb.DaXuanFoldReply{IsSuccess: true})
+}
+
+// RequestPass 玩家休
+func (obj *DaXuanPlay) RequestPass(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	roomInfo, msgErr := obj.requestOperate(request, extroInfo, pb.DaXuanDoType_DaXuanDo_OperatePass, 0)
+	if msgErr != nil {
+		return &pb.Driver2GameLogicInfo{}, msgErr
+	}
+	return packReply(roomInfo, &pb.DaXuanPassReply{IsSuccess: true})
+}
+
+// RequestCall 玩家大，本轮第一个下注，至少下皮池的金额
+func (obj *DaXuanPlay) RequestCall(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	realRequest := &pb.DaXuanCallRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("DaXuanPlay RequestCall ptypes.UnmarshalAny has err", err)
+		return &pb.Driver2GameLogicInfo{}, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	roomInfo, msgErr := obj.requestOperate(request, extroInfo, pb.DaXuanDoType_DaXuanDo_OperateCall, realRequest.GetBetBalance())
+	if msgErr != nil {
+		return &pb.Driver2GameLogicInfo{}, msgErr
+	}
+	return packReply(roomInfo, &pb.DaXuanCallReply{IsSuccess: true})
+}
+
+// RequestFollow 玩家跟，跟到本轮最大注
+func (obj *DaXuanPlay) RequestFollow(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	roomInfo, msgErr := obj.requestOperate(request, extroInfo, pb.DaXuanDoType_DaXuanDo_OperateFollow, 0)
+	if msgErr != nil {
+		return &pb.Driver2GameLogicInfo{}, msgErr
+	}
+	return packReply(roomInfo, &pb.DaXuanFollowReply{IsSuccess: true})
+}
+
+// RequestPlus 玩家敲，下注后的总额要超过本轮最大注
+func (obj *DaXuanPlay) RequestPlus(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	realRequest := &pb.DaXuanPlusRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("DaXuanPlay RequestPlus ptypes.UnmarshalAny has err", err)
+		return &pb.Driver2GameLogicInfo{}, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	roomInfo, msgErr := obj.requestOperate(request, extroInfo, pb.DaXuanDoType_DaXuanDo_OperatePlus, realRequest.GetBetBalance())
+	if msgErr != nil {
+		return &pb.Driver2GameLogicInfo{}, msgErr
+	}
+	return packReply(roomInfo, &pb.DaXuanPlusReply{IsSuccess: true})
+}
+
+// RequestAllIn 玩家梭，下完所有的钵钵
+func (obj *DaXuanPlay) RequestAllIn(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	roomInfo, msgErr := obj.requestOperate(request, extroInfo, pb.DaXuanDoType_DaXuanDo_OperateAllIn, 0)
+	if msgErr != nil {
+		return &pb.Driver2GameLogicInfo{}, msgErr
+	}
+	return packReply(roomInfo, &pb.DaXuanAllInReply{IsSuccess: true})
+}
+
+// RequestExitInGame 玩家在对局中退出房间
+// 本局还没结束的玩家标记为等待踢出，说话阶段没弃牌的直接弃牌，结算后由房间的Kick踢出
+// 没有参与本局的玩家直接踢出，带入过钵钵的记录为逃跑玩家，房间解散时再退还钵钵
+func (obj *DaXuanPlay) RequestExitInGame(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	playerInfo.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_Exit
+	curRoomState := roomInfo.GetCurRoomState()
+	inRound := playerInfo.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay &&
+		(curRoomState == pb.RoomState_RoomStatePlay || curRoomState == pb.RoomState_RoomStatePart ||
+			(curRoomState == pb.RoomState_RoomStateSettle && roomInfo.GetNextRoomState() == pb.RoomState_RoomStateSettle))
+	if !inRound {
+		addEscapee(roomInfo, playerInfo)
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+		return packReply(roomInfo, &pb.GameExitRoomReply{})
+	}
+	if curRoomState == pb.RoomState_RoomStatePlay && roomInfo.GetNextRoomState() != pb.RoomState_RoomStatePlay && isActive(playerInfo) {
+		nowMilliTime := time.Now().UnixNano() / 1e6
+		daXuanInRoom := roomInfo.GetDaXuanInRoom()
+		if daXuanInRoom.GetCurDoUuid() == uid {
+			msgErr := obj.operate(roomInfo, playerInfo, pb.DaXuanDoType_DaXuanDo_OperateFold, 0, nowMilliTime)
+			if msgErr != nil {
+				return reply, msgErr
+			}
+			return packReply(roomInfo, &pb.GameExitRoomReply{})
+		}
+		playerInfo.IsFold = true
+		obj.broadcastOperate(roomInfo, playerInfo, pb.DaXuanDoType_DaXuanDo_OperateFold, 0)
+		// 只剩一个玩家时不用再等当前玩家说话
+		if len(getActivePlayers(roomInfo)) <= 1 {
+			daXuanInRoom.CurDoUuid = ""
+			roomInfo.MilliDoTime = nowMilliTime
+		}
+	}
+	return packReply(roomInfo, &pb.GameExitRoomReply{})
+}
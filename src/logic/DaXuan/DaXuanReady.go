@@ -0,0 +1,387 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	uuid "github.com/satori/go.uuid"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["DaXuanReady"] = &DaXuanReady{}
+}
+
+// DaXuanReady 打旋游戏的准备组件，用于处理准备阶段的逻辑和座位操作
+type DaXuanReady struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DaXuanReady) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DaXuanReady) Start() {
+	obj.Base.Start()
+	common.InitGameConfigTemp(common.DaXuanGameConfigTemp, pb.GameType_DaXuan)
+}
+
+// Drive 打旋准备阶段的主驱动
+// 刚进入准备阶段时初始化玩家，之后每次到时间时处理座位上的玩家：
+// 申请下座和保座超时的玩家下座，断线的玩家自动保座，钵钵不够芒果的玩家不能参与，其他座位上的玩家自动准备
+// 准备的人数达到开始人数就开始游戏，房间到了存在时间就解散
+func (obj *DaXuanReady) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowTime := time.Now().Unix()
+	readyTime, msgErr := getRoomConfigInt64(request, "ReadyTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	if request.GetNextRoomState() == pb.RoomState_RoomStateReady {
+		obj.initRound(request, nowTime, readyTime)
+		return request, nil
+	}
+	if nowTime < request.GetDoTime() {
+		return request, nil
+	}
+
+	roomTime, msgErr := getRoomConfigInt64(request, "RoomTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	if roomTime > 0 && nowTime-request.GetCreateTime() >= roomTime*60 {
+		obj.dissolve(request)
+		return request, nil
+	}
+
+	msgErr = obj.checkSeats(request, nowTime)
+	if msgErr != nil {
+		return request, msgErr
+	}
+	playerStartNum, msgErr := getRoomConfigInt64(request, "PlayerStartNum")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	readyNum := 0
+	for _, onePlayer := range getSeatPlayers(request) {
+		if onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStateReady {
+			readyNum++
+		}
+	}
+	request.ReadyPlayerNum = int32(readyNum)
+	if int64(readyNum) >= playerStartNum {
+		obj.startRound(request, nowTime)
+		return request, nil
+	}
+
+	// 人数不够，重新计时等待
+	request.DoTime = nowTime + readyTime
+	pushDoTimeInReady := &pb.PushDoTimeInReady{
+		RoomId: request.GetUuid(),
+		DoTime: request.GetDoTime(),
+	}
+	common.RoomBroadcast(request, pushDoTimeInReady)
+	return request, nil
+}
+
+// initRound 新一局的准备，刷新房间配置，清空玩家上一局的信息
+// 皮池和打芒的记录会带到下一局
+func (obj *DaXuanReady) initRound(request *pb.RoomInfo, nowTime int64, readyTime int64) {
+	// 准备阶段刷新房间配置
+	gameKeyMap := common.Configer.GetGameConfigByGameTypeAndScene(request.GetGameType(), request.GetGameScene())
+	if gameKeyMap != nil {
+		request.Config = []*pb.GameConfig{}
+		for _, oneConfig := range gameKeyMap.Map {
+			request.Config = append(request.Config, oneConfig)
+		}
+	}
+
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" {
+			continue
+		}
+		onePlayer.Pokers = nil
+		onePlayer.DaXuanPrivatePoker = nil
+		onePlayer.DaXuanPublicPoker = nil
+		onePlayer.DaXuanPokerLog = nil
+		onePlayer.IsFold = false
+		onePlayer.BetsCount = 0
+		onePlayer.LastBet = 0
+		onePlayer.BetsLeftTemp = 0
+		onePlayer.EatMongo = 0
+		onePlayer.Refund = 0
+		onePlayer.PlayerMango = 0
+		onePlayer.DaXuanPokerSanHuaType = pb.DaXuanPokerSanHuaType_DaXuanPokerSanHuaType_None
+		onePlayer.DaXuanPokerTypePrizeType = pb.DaXuanPokerTypePrizeType_DaXuanPokerTypePrizeType_None
+		onePlayer.DaXuanPokerTypePrizeAccount = 0
+		onePlayer.WinOrLose = 0
+		onePlayer.HundredWaterBill = 0
+		onePlayer.HundredCommission = 0
+		// 上一局中途退出的玩家已经在结算时处理
+		if onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+			continue
+		}
+		if index >= seatNum {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateWatch
+			continue
+		}
+		if onePlayer.GetKeepSeat() {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateKeepSeat
+			continue
+		}
+		onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateFree
+	}
+
+	// 结算 < -- > 准备
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateSettle,
+		AfterState:        pb.RoomState_RoomStateReady,
+		AfterStateEndTime: nowTime + readyTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	// 皮池和打芒的记录留到下一局，其他的对局信息在开始时重新初始化
+	daXuanInRoom := request.GetDaXuanInRoom()
+	request.DaXuanInRoom = &pb.DaXuanInRoom{
+		PPool:         daXuanInRoom.GetPPool(),
+		LastMongoType: daXuanInRoom.GetLastMongoType(),
+		MongoNum:      daXuanInRoom.GetMongoNum(),
+	}
+	request.PokerCardHeap = nil
+	request.AllSettleInfo = []*pb.SettleInfo{}
+	request.NextRoomState = pb.RoomState_RoomStatePlay
+	request.DoTime = nowTime + readyTime
+}
+
+// checkSeats 处理座位上的玩家，决定哪些玩家可以参与下一局
+func (obj *DaXuanReady) checkSeats(request *pb.RoomInfo, nowTime int64) *pb.ErrorMessage {
+	keepSeatTime, msgErr := getRoomConfigInt64(request, "KeepSeatTime")
+	if msgErr != nil {
+		return msgErr
+	}
+	mongo, msgErr := getRoomConfigInt64(request, "Mongo")
+	if msgErr != nil {
+		return msgErr
+	}
+	for index := 0; index < seatNum && index < len(request.GetPlayerInfo()); index++ {
+		onePlayer := request.GetPlayerInfo()[index]
+		if onePlayer.GetUuid() == "" || onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None {
+			continue
+		}
+		beforeState := onePlayer.GetPlayerRoomState()
+		if onePlayer.GetDownSeatRequest() || (onePlayer.GetKeepSeat() && nowTime-onePlayer.GetKeepSeatTime() >= keepSeatTime) {
+			downSeat(request, index)
+			continue
+		}
+		// 断线的玩家自动保座
+		if !onePlayer.GetKeepSeat() && !isOnline(onePlayer) {
+			onePlayer.KeepSeat = true
+			onePlayer.KeepSeatTime = nowTime
+		}
+		switch {
+		case onePlayer.GetKeepSeat():
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateKeepSeat
+		case onePlayer.GetSafeMoney() <= mongo:
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateFree
+		default:
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateReady
+		}
+		if beforeState != onePlayer.GetPlayerRoomState() {
+			common.PlayerStateChangeBroadcast(request, onePlayer.GetUuid(), beforeState, onePlayer.GetPlayerRoomState())
+		}
+	}
+	return nil
+}
+
+// startRound 开始游戏，准备的玩家进入游戏状态，确定本局的庄家
+// 上一局的最大赢家坐庄，他没有参与本局时由上一个庄家的下一家坐庄
+func (obj *DaXuanReady) startRound(request *pb.RoomInfo, nowTime int64) {
+	for _, onePlayer := range getSeatPlayers(request) {
+		if onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStateReady {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStatePlay
+		}
+	}
+	bankerIndex := getPlayerIndex(request, request.GetDaXuanUpperUuid())
+	if bankerIndex < 0 || bankerIndex >= seatNum || request.GetPlayerInfo()[bankerIndex].GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+		bankerIndex = getNextPlayIndex(request, int(request.GetDaXuanMultipleIndex()))
+	}
+	request.DaXuanMultipleIndex = int64(bankerIndex)
+	pushBankers := &pb.PushDaXuanBankers{
+		RoomId:              request.GetUuid(),
+		DaXuanMultipleIndex: request.GetDaXuanMultipleIndex(),
+	}
+	common.RoomBroadcast(request, pushBankers)
+
+	request.ReadyPlayerNum = 0
+	request.RoundStartTime = nowTime
+	request.CurrentRoundId = uuid.NewV4().String()
+	request.CurRoomState = pb.RoomState_RoomStatePlay
+	request.NextRoomState = pb.RoomState_RoomStatePlay
+	request.DoTime = nowTime
+}
+
+// dissolve 房间到了存在时间，皮池里剩下的钱平分给带入过钵钵的玩家，然后标记房间死亡
+// 玩家的钵钵在房间管理器清理房间时退回
+func (obj *DaXuanReady) dissolve(request *pb.RoomInfo) {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.GetUuid() != "" && onePlayer.GetAllSafeMoney() > 0 {
+			players = append(players, onePlayer)
+		}
+	}
+	daXuanInRoom := request.GetDaXuanInRoom()
+	if len(players) > 0 && daXuanInRoom.GetPPool() > 0 {
+		share := daXuanInRoom.GetPPool() / int64(len(players))
+		remain := daXuanInRoom.GetPPool() - share*int64(len(players))
+		for index, onePlayer := range players {
+			oneShare := share
+			if index == 0 {
+				oneShare += remain
+			}
+			onePlayer.SafeMoney += oneShare
+			onePlayer.AllWinOrLose += oneShare
+		}
+		daXuanInRoom.PPool = 0
+	}
+	common.LogInfo("DaXuanReady dissolve room time out", request.GetUuid())
+	request.Dead = true
+}
+
+// downSeat 座位上的玩家下座成为旁观，钵钵留在玩家身上
+func downSeat(request *pb.RoomInfo, index int) {
+	onePlayer := request.GetPlayerInfo()[index]
+	onePlayer.DownSeatRequest = false
+	onePlayer.KeepSeat = false
+	onePlayer.KeepSeatTime = 0
+	onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateWatch
+	if !common.DownSeat(request, index) {
+		return
+	}
+	pushMsg := &pb.PushTableChange{
+		RoomId:        request.GetUuid(),
+		TableIndex:    int64(index),
+		PlayerUuid:    onePlayer.GetUuid(),
+		IsUpSeat:      false,
+		SafeMoney:     onePlayer.GetSafeMoney(),
+		AllSafeMoney:  onePlayer.GetAllSafeMoney(),
+		PlayerHeadUrl: onePlayer.GetHeadImgUrl(),
+		PlayerName:    onePlayer.GetName(),
+		PlayerShortId: onePlayer.GetShortId(),
+	}
+	common.RoomBroadcast(request, pushMsg)
+}
+
+// getNextPlayIndex 获取座位index之后下一个参与游戏的玩家的座位下标，没有返回-1
+func getNextPlayIndex(roomInfo *pb.RoomInfo, index int) int {
+	for step := 1; step <= seatNum; step++ {
+		nextIndex := (index + step) % seatNum
+		if nextIndex >= len(roomInfo.GetPlayerInfo()) {
+			continue
+		}
+		onePlayer := roomInfo.GetPlayerInfo()[nextIndex]
+		if onePlayer.GetUuid() != "" && onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+			return nextIndex
+		}
+	}
+	return -1
+}
+
+// getSeatPlayer 获取请求的玩家，玩家必须在座位上
+func getSeatPlayer(roomInfo *pb.RoomInfo, uid string) (int, *pb.RoomPlayerInfo, *pb.ErrorMessage) {
+	index := getPlayerIndex(roomInfo, uid)
+	if index == -1 {
+		common.LogError("DaXuan getSeatPlayer player not in room", uid)
+		return index, nil, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if index >= seatNum {
+		return index, nil, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerNotInTable, "")
+	}
+	return index, roomInfo.GetPlayerInfo()[index], nil
+}
+
+// RequestDownSeat 玩家下座
+// 本局游戏中的玩家只能申请下座，本局结束后在准备阶段下座，再次请求取消申请
+func (obj *DaXuanReady) RequestDownSeat(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	roomInfo := request.GetRoomInfo()
+	index, playerInfo, msgErr := getSeatPlayer(roomInfo, extroInfo.GetUserId())
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	realReply := &pb.DaXuanDownSeatReply{IsSuccess: true}
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateReady && playerInfo.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+		playerInfo.DownSeatRequest = !playerInfo.GetDownSeatRequest()
+		realReply.IsRequest = playerInfo.GetDownSeatRequest()
+		return packReply(roomInfo, realReply)
+	}
+	downSeat(roomInfo, index)
+	return packReply(roomInfo, realReply)
+}
+
+// RequestKeepSeat 玩家保座，本局游戏中的玩家打完本局后才开始保座
+func (obj *DaXuanReady) RequestKeepSeat(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	roomInfo := request.GetRoomInfo()
+	_, playerInfo, msgErr := getSeatPlayer(roomInfo, extroInfo.GetUserId())
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	if playerInfo.GetKeepSeat() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	playerInfo.KeepSeat = true
+	playerInfo.KeepSeatTime = time.Now().Unix()
+	beforeState := playerInfo.GetPlayerRoomState()
+	if beforeState != pb.PlayerRoomState_PlayerRoomStatePlay {
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateKeepSeat
+		common.PlayerStateChangeBroadcast(roomInfo, playerInfo.GetUuid(), beforeState, playerInfo.GetPlayerRoomState())
+	}
+	return packReply(roomInfo, &pb.DaXuanKeepSeatReply{IsSuccess: true})
+}
+
+// RequestBackSeat 保座的玩家回到座位，钵钵足够时直接准备
+func (obj *DaXuanReady) RequestBackSeat(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	roomInfo := request.GetRoomInfo()
+	_, playerInfo, msgErr := getSeatPlayer(roomInfo, extroInfo.GetUserId())
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	if !playerInfo.GetKeepSeat() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerNotKeepSeat, "")
+	}
+	mongo, msgErr := getRoomConfigInt64(roomInfo, "Mongo")
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	playerInfo.KeepSeat = false
+	playerInfo.KeepSeatTime = 0
+	beforeState := playerInfo.GetPlayerRoomState()
+	if beforeState == pb.PlayerRoomState_PlayerRoomStateKeepSeat {
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateFree
+		if playerInfo.GetSafeMoney() > mongo {
+			playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateReady
+		}
+		common.PlayerStateChangeBroadcast(roomInfo, playerInfo.GetUuid(), beforeState, playerInfo.GetPlayerRoomState())
+	}
+	return packReply(roomInfo, &pb.DaXuanBackSeatReply{IsSuccess: true})
+}
+
+// packReply 封装回复给driver的房间信息和回复消息
+func packReply(roomInfo *pb.RoomInfo, realReply proto.Message) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	realReplyAny, err := ptypes.MarshalAny(realReply)
+	if err != nil {
+		common.LogError("DaXuan packReply MarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	reply.RoomInfo = roomInfo
+	reply.Message = realReplyAny
+	return reply, nil
+}
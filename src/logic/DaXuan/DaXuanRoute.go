@@ -0,0 +1,269 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+)
+
+func init() {
+	common.AllComponentMap["DaXuanRoute"] = &DaXuanRoute{}
+}
+
+// DaXuanRoute 打旋游戏的功能中转组件，其他服务通过这个组件中转打旋协议到具体逻辑组件中
+type DaXuanRoute struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DaXuanRoute) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DaXuanRoute) Start() {
+	obj.Base.Start()
+
+	// 需要的配置进行模版初始化
+	initGlobleConfigNameArr := []string{
+		"DaXuanServerNum",
+	}
+	err := common.InitGlobleConfigTemp(initGlobleConfigNameArr)
+	if err != nil {
+		panic("DaXuanRoute initGlobleConfigNameArr has err")
+	}
+}
+
+// getServerNum 获取打旋的线路数量
+func (obj *DaXuanRoute) getServerNum() (int, *pb.ErrorMessage) {
+	serverNumConfig := common.Configer.GetGlobal("DaXuanServerNum")
+	serverNumStr := serverNumConfig.GetValue()
+	serverNum, err := strconv.Atoi(serverNumStr)
+	if err != nil {
+		common.LogError("DaXuanRoute getServerNum Atoi(serverNumStr) has err", serverNumStr)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return serverNum, nil
+}
+
+// Do 中转协议的具体逻辑(玩家的请求操作通过do路由到driver）
+func (obj *DaXuanRoute) Do(request *pb.DaXuanDoRequest, extroInfo *pb.MessageExtroInfo) (proto.Message, *pb.ErrorMessage) {
+	doType := request.GetDoType()
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("DaXuanRoute Do uuid == nil")
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotLogin, "")
+	}
+	// 选择一个driver线路
+	originMessage := request.GetDoMessageContent()
+	serverNum, msgErr := obj.getServerNum()
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	// 获取玩家信息
+	playerInfo, msgErr := loadPlayer(extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+
+	var requestMessage proto.Message
+	var replyMessage proto.Message
+	//玩家线路
+	driverServerIndex := common.GetDriverServerIndex(playerInfo, serverNum, true)
+	//方法名
+	var methodName string
+	switch doType {
+	//进入房间--与其他逻辑不同
+	case pb.DaXuanDoType_DaXuanDo_JoinRoom:
+		requestMessage = &pb.GameJoinRoomRequest{}
+		replyMessage = &pb.GameJoinRoomReply{}
+
+		err := ptypes.UnmarshalAny(originMessage, requestMessage)
+		if err != nil {
+			common.LogError("DaXuanRoute Do joinRoom ptypes.UnmarshalAny has err", doType, uuid, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		joinRoomRequest := requestMessage.(*pb.GameJoinRoomRequest)
+		// 从房间列表选择房间进入时，直接进入房间所在的线路，入场金额在加入房间时判断
+		if playerInfo.GetRoomId() == "" && joinRoomRequest.GetRoomUUID() != "" {
+			serverIndex, err := strconv.Atoi(joinRoomRequest.GetServerIndex())
+			if err != nil || serverIndex < 1 || serverIndex > serverNum {
+				common.LogError("DaXuanRoute Do joinRoom ServerIndex has err", uuid, joinRoomRequest.GetServerIndex())
+				return nil, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+			}
+			driverServerIndex = joinRoomRequest.GetServerIndex()
+		} else {
+			driverServerIndex, msgErr = common.GameJoinRoomJudge(playerInfo, serverNum, joinRoomRequest, pb.GameType_DaXuan)
+			if msgErr != nil {
+				return nil, msgErr
+			}
+		}
+
+		methodName = "RequestJoinRoom"
+	//退出房间
+	case pb.DaXuanDoType_DaXuanDo_ExitRoom:
+		requestMessage = &pb.GameExitRoomRequest{}
+		replyMessage = &pb.GameExitRoomReply{}
+		methodName = "RequestExitRoom"
+	//弃牌
+	case pb.DaXuanDoType_DaXuanDo_OperateFold:
+		requestMessage = &pb.DaXuanFoldRequest{}
+		replyMessage = &pb.DaXuanFoldReply{}
+		methodName = "RequestFold"
+	//休
+	case pb.DaXuanDoType_DaXuanDo_OperatePass:
+		requestMessage = &pb.DaXuanPassRequest{}
+		replyMessage = &pb.DaXuanPassReply{}
+		methodName = "RequestPass"
+	//大
+	case pb.DaXuanDoType_DaXuanDo_OperateCall:
+		requestMessage = &pb.DaXuanCallRequest{}
+		replyMessage = &pb.DaXuanCallReply{}
+		methodName = "RequestCall"
+	//跟
+	case pb.DaXuanDoType_DaXuanDo_OperateFollow:
+		requestMessage = &pb.DaXuanFollowRequest{}
+		replyMessage = &pb.DaXuanFollowReply{}
+		methodName = "RequestFollow"
+	//加
+	case pb.DaXuanDoType_DaXuanDo_OperatePlus:
+		requestMessage = &pb.DaXuanPlusRequest{}
+		replyMessage = &pb.DaXuanPlusReply{}
+		methodName = "RequestPlus"
+	//梭
+	case pb.DaXuanDoType_DaXuanDo_OperateAllIn:
+		requestMessage = &pb.DaXuanAllInRequest{}
+		replyMessage = &pb.DaXuanAllInReply{}
+		methodName = "RequestAllIn"
+	//上座
+	case pb.DaXuanDoType_DaXuanDo_UpSeat:
+		requestMessage = &pb.DaXuanUpSeatRequest{}
+		replyMessage = &pb.DaXuanUpSeatReply{}
+		methodName = "RequestUpSeat"
+	//下座
+	case pb.DaXuanDoType_DaXuanDo_DownSeat:
+		requestMessage = &pb.DaXuanDownSeatRequest{}
+		replyMessage = &pb.DaXuanDownSeatReply{}
+		methodName = "RequestDownSeat"
+	//保座
+	case pb.DaXuanDoType_DaXuanDo_KeepSeat:
+		requestMessage = &pb.DaXuanKeepSeatRequest{}
+		replyMessage = &pb.DaXuanKeepSeatReply{}
+		methodName = "RequestKeepSeat"
+	//回座
+	case pb.DaXuanDoType_DaXuanDo_BackSeat:
+		requestMessage = &pb.DaXuanBackSeatRequest{}
+		replyMessage = &pb.DaXuanBackSeatReply{}
+		methodName = "RequestBackSeat"
+	//分牌
+	case pb.DaXuanDoType_DaXuanDo_Part:
+		requestMessage = &pb.DaXuanPartRequest{}
+		replyMessage = &pb.DaXuanPartReply{}
+		methodName = "RequestPart"
+	//补充钵钵
+	case pb.DaXuanDoType_DaXuan_TopUp:
+		requestMessage = &pb.DaXuanTopUpRequest{}
+		replyMessage = &pb.DaXuanTopUpReply{}
+		methodName = "RequestTopUp"
+
+	default:
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	err := ptypes.UnmarshalAny(originMessage, requestMessage)
+	if err != nil {
+		common.LogError("DaXuanRoute Do request ptypes.UnmarshalAny has err", doType, uuid, err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	componentName := "DaXuanDriver" + driverServerIndex
+	// common.Router.Call 都是路由到 driver,  driver 再分发给各个组件
+	msgErr = common.Router.Call(componentName, methodName, requestMessage, replyMessage, extroInfo)
+	if msgErr != nil {
+		common.LogError(msgErr)
+		return nil, msgErr
+	}
+	return replyMessage, nil
+}
+
+// GetRoomsDaXuan 获取所有线路上的打旋房间列表
+func (obj *DaXuanRoute) GetRoomsDaXuan(request *pb.GetRoomsDaXuanRequest, extroInfo *pb.MessageExtroInfo) (*pb.GetRoomsDaXuanReply, *pb.ErrorMessage) {
+	reply := &pb.GetRoomsDaXuanReply{}
+	serverNum, msgErr := obj.getServerNum()
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	for index := 1; index <= serverNum; index++ {
+		oneReply := &pb.GetRoomsDaXuanReply{}
+		msgErr = common.Router.Call("DaXuanDriver"+strconv.Itoa(index), "GetRooms", request, oneReply, extroInfo)
+		if msgErr != nil {
+			common.LogError("DaXuanRoute GetRoomsDaXuan GetRooms has err", index, msgErr)
+			continue
+		}
+		reply.Rooms = append(reply.Rooms, oneReply.GetRooms()...)
+	}
+	return reply, nil
+}
+
+// GetGameReview 获取玩家所在房间的上局回顾
+func (obj *DaXuanRoute) GetGameReview(request *pb.GetGameReviewRequest, extroInfo *pb.MessageExtroInfo) (*pb.GetGameReviewReply, *pb.ErrorMessage) {
+	reply := &pb.GetGameReviewReply{}
+	msgErr := obj.callPlayerDriver("GetGameReview", request, reply, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// GetRecord 获取玩家所在房间的战绩
+func (obj *DaXuanRoute) GetRecord(request *pb.GetRecordRequest, extroInfo *pb.MessageExtroInfo) (*pb.GetRecordReply, *pb.ErrorMessage) {
+	reply := &pb.GetRecordReply{}
+	msgErr := obj.callPlayerDriver("GetRecord", request, reply, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// callPlayerDriver 调用玩家所在线路的driver，玩家不在房间中时返回错误
+func (obj *DaXuanRoute) callPlayerDriver(methodName string, request proto.Message, reply proto.Message, extroInfo *pb.MessageExtroInfo) *pb.ErrorMessage {
+	if extroInfo.GetUserId() == "" {
+		common.LogError("DaXuanRoute callPlayerDriver uuid == nil", methodName)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_UserNotLogin, "")
+	}
+	playerInfo, msgErr := loadPlayer(extroInfo)
+	if msgErr != nil {
+		return msgErr
+	}
+	if playerInfo.GetRoomId() == "" || playerInfo.GetGameType() != pb.GameType_DaXuan {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	componentName := "DaXuanDriver" + playerInfo.GetGameServerIndex()
+	return common.Router.Call(componentName, methodName, request, reply, extroInfo)
+}
+
+// SyncRoomPlayerInfo 同步房间玩家信息
+func (obj *DaXuanRoute) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo, extroInfo *pb.MessageExtroInfo) (*pb.EmptyMessage, *pb.ErrorMessage) {
+	reply := &pb.EmptyMessage{}
+	componentName := "DaXuanDriver" + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "SyncRoomPlayerInfo", request, reply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	return reply, nil
+}
+
+// DelRoom 刪除房間
+func (obj *DaXuanRoute) DelRoom(request *pb.DelRoomByGameTypeRequest, extraInfo *pb.MessageExtroInfo) (*pb.DelRoomByGameTypeReply, *pb.ErrorMessage) {
+	reply := &pb.DelRoomByGameTypeReply{}
+	componentName := "DaXuanDriver" + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "DelRoom", request, reply, extraInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
@@ -0,0 +1,439 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+)
+
+// 座位数，0-7号是座位，8号以后是旁观
+const seatNum = 8
+
+// 每个玩家的手牌张数，两张暗牌两张明牌
+const handPokerNum = 4
+
+// daXuanCard 打旋的牌名，同名的两张牌才能成对
+type daXuanCard int
+
+const (
+	cardNone daXuanCard = iota
+	// 天牌 红Q
+	cardTian
+	// 地牌 红2
+	cardDi
+	// 人牌 红8
+	cardRen
+	// 和牌 红4
+	cardHe
+	// 梅十 黑10
+	cardMeiShi
+	// 长三 黑6
+	cardChangSan
+	// 板凳 黑4
+	cardBanDeng
+	// 虎头 黑J
+	cardHuTou
+	// 苕十 红10
+	cardShaoShi
+	// 猫猫 红6
+	cardMaoMao
+	// 膏药 红7
+	cardGaoYao
+	// 杂九 黑9
+	cardZaJiu
+	// 杂八 黑8
+	cardZaBa
+	// 杂七 黑7
+	cardZaQi
+	// 杂五 黑5
+	cardZaWu
+	// 丁 黑桃3
+	cardDing
+	// 二 大王，按6点算
+	cardEr
+)
+
+// 成对时的牌型，杂牌成对都是对子
+var pairPokerType = map[daXuanCard]pb.DaXuanPokerType{
+	cardTian:     pb.DaXuanPokerType_DaXuanPokerType_TianPai,
+	cardDi:       pb.DaXuanPokerType_DaXuanPokerType_DiPai,
+	cardRen:      pb.DaXuanPokerType_DaXuanPokerType_RenPai,
+	cardHe:       pb.DaXuanPokerType_DaXuanPokerType_HePai,
+	cardMeiShi:   pb.DaXuanPokerType_DaXuanPokerType_MeiShi,
+	cardChangSan: pb.DaXuanPokerType_DaXuanPokerType_ChangSan,
+	cardBanDeng:  pb.DaXuanPokerType_DaXuanPokerType_BanDeng,
+	cardHuTou:    pb.DaXuanPokerType_DaXuanPokerType_HuTou,
+	cardShaoShi:  pb.DaXuanPokerType_DaXuanPokerType_ShaoShi,
+	cardMaoMao:   pb.DaXuanPokerType_DaXuanPokerType_MaoMao,
+	cardGaoYao:   pb.DaXuanPokerType_DaXuanPokerType_GaoYao,
+	cardZaJiu:    pb.DaXuanPokerType_DaXuanPokerType_DuiZi,
+	cardZaBa:     pb.DaXuanPokerType_DaXuanPokerType_DuiZi,
+	cardZaQi:     pb.DaXuanPokerType_DaXuanPokerType_DuiZi,
+	cardZaWu:     pb.DaXuanPokerType_DaXuanPokerType_DuiZi,
+}
+
+// 两张不同的牌组成的特殊牌型
+var comboPokerType = []struct {
+	a         daXuanCard
+	b         daXuanCard
+	pokerType pb.DaXuanPokerType
+}{
+	{cardDing, cardEr, pb.DaXuanPokerType_DaXuanPokerType_DingHuang},
+	{cardTian, cardZaJiu, pb.DaXuanPokerType_DaXuanPokerType_TianWang},
+	{cardTian, cardRen, pb.DaXuanPokerType_DaXuanPokerType_TianGang},
+	{cardTian, cardZaBa, pb.DaXuanPokerType_DaXuanPokerType_TianGang},
+	{cardDi, cardRen, pb.DaXuanPokerType_DaXuanPokerType_DiGang},
+	{cardDi, cardZaBa, pb.DaXuanPokerType_DaXuanPokerType_DiGang},
+	{cardTian, cardGaoYao, pb.DaXuanPokerType_DaXuanPokerType_TianGuanJiu},
+	{cardTian, cardZaQi, pb.DaXuanPokerType_DaXuanPokerType_TianGuanJiu},
+	{cardDi, cardGaoYao, pb.DaXuanPokerType_DaXuanPokerType_DiGuanJiu},
+	{cardDi, cardZaQi, pb.DaXuanPokerType_DaXuanPokerType_DiGuanJiu},
+	{cardRen, cardHuTou, pb.DaXuanPokerType_DaXuanPokerType_DengLongJiu},
+	{cardHe, cardZaWu, pb.DaXuanPokerType_DaXuanPokerType_HeWuJiu},
+	{cardBanDeng, cardZaWu, pb.DaXuanPokerType_DaXuanPokerType_BanWuJiu},
+	{cardDing, cardChangSan, pb.DaXuanPokerType_DaXuanPokerType_DingChangJiu},
+	{cardMeiShi, cardZaJiu, pb.DaXuanPokerType_DaXuanPokerType_MeiShiJiu},
+	{cardDing, cardMaoMao, pb.DaXuanPokerType_DaXuanPokerType_DingMaoJiu},
+	{cardHuTou, cardZaBa, pb.DaXuanPokerType_DaXuanPokerType_WuLongJiu},
+	{cardShaoShi, cardZaJiu, pb.DaXuanPokerType_DaXuanPokerType_ShaoShiJiu},
+}
+
+// 牌堆中除了丁和二以外都是两张一样的牌，红牌用红桃和方块，黑牌用黑桃和梅花
+var redPokerNums = []pb.PokerNum{pb.PokerNum_PokerNumQ, pb.PokerNum_PokerNum2, pb.PokerNum_PokerNum8, pb.PokerNum_PokerNum4, pb.PokerNum_PokerNum10, pb.PokerNum_PokerNum6, pb.PokerNum_PokerNum7}
+var blackPokerNums = []pb.PokerNum{pb.PokerNum_PokerNum10, pb.PokerNum_PokerNum6, pb.PokerNum_PokerNum4, pb.PokerNum_PokerNumJ, pb.PokerNum_PokerNum9, pb.PokerNum_PokerNum8, pb.PokerNum_PokerNum7, pb.PokerNum_PokerNum5}
+
+// getRoomConfigInt64 获取房间的整数配置
+func getRoomConfigInt64(roomInfo *pb.RoomInfo, configName string) (int64, *pb.ErrorMessage) {
+	configStr := common.GetRoomConfig(roomInfo, configName)
+	configNum, err := strconv.ParseInt(configStr, 10, 64)
+	if err != nil {
+		common.LogError("DaXuan getRoomConfigInt64 has err", configName, configStr, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return configNum, nil
+}
+
+// getPrizeRatio 获取牌型奖励从奖池中取的比例，配置格式为 牌型名:比例,牌型名:比例，单位：%
+func getPrizeRatio(roomInfo *pb.RoomInfo, prizeType pb.DaXuanPokerTypePrizeType) int {
+	name := strings.TrimPrefix(pb.DaXuanPokerTypePrizeType_name[int32(prizeType)], "DaXuanPokerTypePrizeType_")
+	prizeRatioStr := common.GetRoomConfig(roomInfo, "PrizeRatio")
+	for _, onePrize := range strings.Split(prizeRatioStr, ",") {
+		nameAndRatio := strings.Split(onePrize, ":")
+		if len(nameAndRatio) != 2 || strings.TrimSpace(nameAndRatio[0]) != name {
+			continue
+		}
+		ratio, err := strconv.Atoi(strings.TrimSpace(nameAndRatio[1]))
+		if err != nil {
+			common.LogError("DaXuan getPrizeRatio has err", prizeRatioStr, err)
+			return 0
+		}
+		return ratio
+	}
+	return 0
+}
+
+// getShuffleDaXuanHeap 获取洗好的打旋牌堆，共32张
+func getShuffleDaXuanHeap() []*pb.Poker {
+	pokers := make([]*pb.Poker, 0, 32)
+	for _, num := range redPokerNums {
+		pokers = append(pokers, &pb.Poker{PokerNum: num, PokerColor: pb.PokerColor_PokerColorHeart})
+		pokers = append(pokers, &pb.Poker{PokerNum: num, PokerColor: pb.PokerColor_PokerColorDiamond})
+	}
+	for _, num := range blackPokerNums {
+		pokers = append(pokers, &pb.Poker{PokerNum: num, PokerColor: pb.PokerColor_PokerColorSpade})
+		pokers = append(pokers, &pb.Poker{PokerNum: num, PokerColor: pb.PokerColor_PokerColorClub})
+	}
+	pokers = append(pokers, &pb.Poker{PokerNum: pb.PokerNum_PokerNum3, PokerColor: pb.PokerColor_PokerColorSpade})
+	pokers = append(pokers, &pb.Poker{PokerNum: pb.PokerNum_PokerNumBigJoker})
+	common.RandSlice(pokers)
+	return pokers
+}
+
+// getCard 获取一张牌的牌名，不是打旋的牌返回cardNone
+func getCard(poker *pb.Poker) daXuanCard {
+	isRed := poker.GetPokerColor() == pb.PokerColor_PokerColorHeart || poker.GetPokerColor() == pb.PokerColor_PokerColorDiamond
+	isBlack := poker.GetPokerColor() == pb.PokerColor_PokerColorSpade || poker.GetPokerColor() == pb.PokerColor_PokerColorClub
+	switch poker.GetPokerNum() {
+	case pb.PokerNum_PokerNumBigJoker:
+		return cardEr
+	case pb.PokerNum_PokerNum3:
+		if poker.GetPokerColor() == pb.PokerColor_PokerColorSpade {
+			return cardDing
+		}
+	}
+	if isRed {
+		switch poker.GetPokerNum() {
+		case pb.PokerNum_PokerNumQ:
+			return cardTian
+		case pb.PokerNum_PokerNum2:
+			return cardDi
+		case pb.PokerNum_PokerNum8:
+			return cardRen
+		case pb.PokerNum_PokerNum4:
+			return cardHe
+		case pb.PokerNum_PokerNum10:
+			return cardShaoShi
+		case pb.PokerNum_PokerNum6:
+			return cardMaoMao
+		case pb.PokerNum_PokerNum7:
+			return cardGaoYao
+		}
+	}
+	if isBlack {
+		switch poker.GetPokerNum() {
+		case pb.PokerNum_PokerNum10:
+			return cardMeiShi
+		case pb.PokerNum_PokerNum6:
+			return cardChangSan
+		case pb.PokerNum_PokerNum4:
+			return cardBanDeng
+		case pb.PokerNum_PokerNumJ:
+			return cardHuTou
+		case pb.PokerNum_PokerNum9:
+			return cardZaJiu
+		case pb.PokerNum_PokerNum8:
+			return cardZaBa
+		case pb.PokerNum_PokerNum7:
+			return cardZaQi
+		case pb.PokerNum_PokerNum5:
+			return cardZaWu
+		}
+	}
+	return cardNone
+}
+
+// getPokerPoint 获取一张牌的点数，二按6点算，其他按牌面算
+func getPokerPoint(poker *pb.Poker) int {
+	if poker.GetPokerNum() == pb.PokerNum_PokerNumBigJoker {
+		return 6
+	}
+	return int(poker.GetPokerNum())
+}
+
+// getPokerType 获取两张牌的牌型
+// 丁二皇最大，然后是成对的牌，然后是王、杠、各种九，其他的按两张牌点数和的个位算
+// 地九王需要房间开启才算，否则按点数算
+func getPokerType(a *pb.Poker, b *pb.Poker, isDiJiuWang bool) pb.DaXuanPokerType {
+	cardA, cardB := getCard(a), getCard(b)
+	if cardA == cardB {
+		if pokerType, ok := pairPokerType[cardA]; ok {
+			return pokerType
+		}
+	}
+	for _, combo := range comboPokerType {
+		if (cardA == combo.a && cardB == combo.b) || (cardA == combo.b && cardB == combo.a) {
+			return combo.pokerType
+		}
+	}
+	if isDiJiuWang && ((cardA == cardDi && cardB == cardZaJiu) || (cardA == cardZaJiu && cardB == cardDi)) {
+		return pb.DaXuanPokerType_DaXuanPokerType_DiJiuWang
+	}
+	return pb.DaXuanPokerType((getPokerPoint(a) + getPokerPoint(b)) % 10)
+}
+
+// getPokerTypeRank 获取牌型的大小，千位只用于区分同样大小的牌型
+func getPokerTypeRank(pokerType pb.DaXuanPokerType) int {
+	return int(pokerType) % 1000
+}
+
+// getPartResult 把四张牌按前两张头牌、后两张尾牌分组，返回两组的牌型，尾牌比头牌小时分牌无效
+func getPartResult(pokers []*pb.Poker, isDiJiuWang bool) ([]pb.DaXuanPokerType, bool) {
+	if len(pokers) != handPokerNum {
+		return nil, false
+	}
+	head := getPokerType(pokers[0], pokers[1], isDiJiuWang)
+	tail := getPokerType(pokers[2], pokers[3], isDiJiuWang)
+	return []pb.DaXuanPokerType{head, tail}, getPokerTypeRank(tail) >= getPokerTypeRank(head)
+}
+
+// autoPart 自动分牌，三种分法中选头牌最大的，头牌一样大时选尾牌大的
+// 返回值：分好的牌和对应的牌型
+func autoPart(pokers []*pb.Poker, isDiJiuWang bool) ([]*pb.Poker, []pb.DaXuanPokerType) {
+	var bestPokers []*pb.Poker
+	var bestTypes []pb.DaXuanPokerType
+	for _, other := range []int{1, 2, 3} {
+		first := []*pb.Poker{pokers[0], pokers[other]}
+		var second []*pb.Poker
+		for index := 1; index < handPokerNum; index++ {
+			if index != other {
+				second = append(second, pokers[index])
+			}
+		}
+		partPokers := append(append([]*pb.Poker{}, first...), second...)
+		pokerTypes, isValid := getPartResult(partPokers, isDiJiuWang)
+		if !isValid {
+			partPokers = append(append([]*pb.Poker{}, second...), first...)
+			pokerTypes, _ = getPartResult(partPokers, isDiJiuWang)
+		}
+		if bestTypes == nil ||
+			getPokerTypeRank(pokerTypes[0]) > getPokerTypeRank(bestTypes[0]) ||
+			(getPokerTypeRank(pokerTypes[0]) == getPokerTypeRank(bestTypes[0]) && getPokerTypeRank(pokerTypes[1]) > getPokerTypeRank(bestTypes[1])) {
+			bestPokers, bestTypes = partPokers, pokerTypes
+		}
+	}
+	return bestPokers, bestTypes
+}
+
+// isSamePokers 判断两组牌是不是同样的牌（不管顺序）
+func isSamePokers(a []*pb.Poker, b []*pb.Poker) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, pokerA := range a {
+		found := false
+		for index, pokerB := range b {
+			if !used[index] && pokerA.GetPokerNum() == pokerB.GetPokerNum() && pokerA.GetPokerColor() == pokerB.GetPokerColor() {
+				used[index] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// compareHand 比较两个玩家分好的牌，头牌和尾牌都比对方大才算赢
+// 返回值：1 a赢，-1 b赢，0 不分输赢
+func compareHand(a *pb.RoomPlayerInfo, b *pb.RoomPlayerInfo) int {
+	typesA := a.GetDaXuanPokerLog().GetPokerTypes()
+	typesB := b.GetDaXuanPokerLog().GetPokerTypes()
+	if len(typesA) != 2 || len(typesB) != 2 {
+		return 0
+	}
+	headA, tailA := getPokerTypeRank(typesA[0]), getPokerTypeRank(typesA[1])
+	headB, tailB := getPokerTypeRank(typesB[0]), getPokerTypeRank(typesB[1])
+	if headA > headB && tailA > tailB {
+		return 1
+	}
+	if headA < headB && tailA < tailB {
+		return -1
+	}
+	return 0
+}
+
+// getSanHuaType 获取四张牌中的三花，三张10点是三花十，三张6点是三花六
+func getSanHuaType(pokers []*pb.Poker) pb.DaXuanPokerSanHuaType {
+	tenNum, sixNum := 0, 0
+	for _, poker := range pokers {
+		switch getPokerPoint(poker) {
+		case 10:
+			tenNum++
+		case 6:
+			sixNum++
+		}
+	}
+	if tenNum >= 3 {
+		return pb.DaXuanPokerSanHuaType_DaXuanPokerSanHuaType_Ten
+	}
+	if sixNum >= 3 {
+		return pb.DaXuanPokerSanHuaType_DaXuanPokerSanHuaType_Six
+	}
+	return pb.DaXuanPokerSanHuaType_DaXuanPokerSanHuaType_None
+}
+
+// getPrizeType 获取四张牌的奖励牌型
+// 天皇：丁二加一对天牌；多皇：丁二加一对；炸弹：四张点数一样；多对：两对
+func getPrizeType(pokers []*pb.Poker) pb.DaXuanPokerTypePrizeType {
+	if len(pokers) != handPokerNum {
+		return pb.DaXuanPokerTypePrizeType_DaXuanPokerTypePrizeType_None
+	}
+	cardNum := make(map[daXuanCard]int)
+	pointNum := make(map[int]int)
+	for _, poker := range pokers {
+		cardNum[getCard(poker)]++
+		pointNum[getPokerPoint(poker)]++
+	}
+	pairNum := 0
+	for card, num := range cardNum {
+		if card != cardDing && card != cardEr && num == 2 {
+			pairNum++
+		}
+	}
+	hasDingHuang := cardNum[cardDing] == 1 && cardNum[cardEr] == 1
+	if hasDingHuang && cardNum[cardTian] == 2 {
+		return pb.DaXuanPokerTypePrizeType_DaXuanPokerTypePrizeType_TianHuang
+	}
+	if hasDingHuang && pairNum == 1 {
+		return pb.DaXuanPokerTypePrizeType_DaXuanPokerTypePrizeType_DuoHuang
+	}
+	if len(pointNum) == 1 {
+		return pb.DaXuanPokerTypePrizeType_DaXuanPokerTypePrizeType_ZhaDan
+	}
+	if pairNum == 2 {
+		return pb.DaXuanPokerTypePrizeType_DaXuanPokerTypePrizeType_DuoDuo
+	}
+	return pb.DaXuanPokerTypePrizeType_DaXuanPokerTypePrizeType_None
+}
+
+// getHandPokers 获取玩家的四张手牌，两张暗牌在前
+func getHandPokers(onePlayer *pb.RoomPlayerInfo) []*pb.Poker {
+	return append(append([]*pb.Poker{}, onePlayer.GetDaXuanPrivatePoker()...), onePlayer.GetDaXuanPublicPoker()...)
+}
+
+// getPlayPlayers 获取本局参与游戏的玩家，只有座位上的玩家可以参与
+func getPlayPlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range getSeatPlayers(roomInfo) {
+		if onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+			players = append(players, onePlayer)
+		}
+	}
+	return players
+}
+
+// getSeatPlayers 获取0-7号座位上的玩家
+func getSeatPlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for index, onePlayer := range roomInfo.GetPlayerInfo() {
+		if index >= seatNum {
+			break
+		}
+		if onePlayer.GetUuid() != "" {
+			players = append(players, onePlayer)
+		}
+	}
+	return players
+}
+
+// isActive 玩家是否还在本局中（参与游戏并且没有弃牌）
+func isActive(onePlayer *pb.RoomPlayerInfo) bool {
+	return onePlayer.GetUuid() != "" &&
+		onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay &&
+		!onePlayer.GetIsFold()
+}
+
+// getActivePlayers 获取本局还没有弃牌的玩家
+func getActivePlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range getPlayPlayers(roomInfo) {
+		if !onePlayer.GetIsFold() {
+			players = append(players, onePlayer)
+		}
+	}
+	return players
+}
+
+// getPlayerIndex 获取玩家的座位下标，不在房间中返回-1
+func getPlayerIndex(roomInfo *pb.RoomInfo, uuid string) int {
+	for index, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() != "" && onePlayer.GetUuid() == uuid {
+			return index
+		}
+	}
+	return -1
+}
+
+// isOnline 玩家是否在线
+func isOnline(onePlayer *pb.RoomPlayerInfo) bool {
+	online, msgErr := common.Pusher.CheckOnline(onePlayer.GetUuid())
+	if msgErr != nil {
+		common.LogError("DaXuan isOnline CheckOnline has err", onePlayer.GetUuid(), msgErr)
+		return false
+	}
+	return online
+}
@@ -0,0 +1,513 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"sort"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["DaXuanSettle"] = &DaXuanSettle{}
+}
+
+// DaXuanSettle 打旋游戏的结算组件，用于处理比牌和结算阶段的逻辑
+// 对局中输赢的都是玩家带入桌子的钵钵，玩家真实的金币在房间解散时才修改
+type DaXuanSettle struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DaXuanSettle) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DaXuanSettle) Start() {
+	obj.Base.Start()
+}
+
+// Drive 打旋结算组件主驱动
+func (obj *DaXuanSettle) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowTime := time.Now().Unix()
+	// 结算 <-> 准备
+	if request.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		if nowTime < request.GetDoTime() {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateReady
+		request.NextRoomState = pb.RoomState_RoomStateReady
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	settleTime, msgErr := getRoomConfigInt64(request, "SettleTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	// 推送房间状态 玩耍/分牌<->结算
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       request.GetLastRoomState(),
+		AfterState:        pb.RoomState_RoomStateSettle,
+		AfterStateEndTime: nowTime + settleTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	msgErr = obj.settle(request, nowTime)
+	if msgErr != nil {
+		return request, msgErr
+	}
+
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" {
+			continue
+		}
+		if onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+			onePlayer.PlayNum++
+		}
+		// 对局中退出的玩家在结算完成后踢出
+		if onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None {
+			addEscapee(request, onePlayer)
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+		}
+	}
+	request.NextRoomState = pb.RoomState_RoomStateReady
+	request.DoTime = nowTime + settleTime
+	return request, nil
+}
+
+// settle 结算本局
+// 1.按玩家下注的多少分层比牌，每层由比所有人都大（头尾都大）的玩家赢走，分不出输赢或者没人可以赢的层退还给下注的玩家
+// 2.一个玩家比所有人都大时吃掉皮池里的芒果，否则芒果留到下一局（走芒）；第一轮大家都休时芒果也留到下一局（休芒）
+// 3.赢家按照抽水比例对赢的部分抽水，抽水的一部分进入奖池
+// 4.没弃牌的玩家拿到三花时其他玩家按芒果的倍数给他钱，拿到奖励牌型时从奖池按比例领奖
+func (obj *DaXuanSettle) settle(request *pb.RoomInfo, nowTime int64) *pb.ErrorMessage {
+	commission, msgErr := getRoomConfigInt64(request, "Commission")
+	if msgErr != nil {
+		return msgErr
+	}
+	bonusRatio, msgErr := getRoomConfigInt64(request, "BonusRatio")
+	if msgErr != nil {
+		return msgErr
+	}
+	daXuanInRoom := request.GetDaXuanInRoom()
+	players := getPlayPlayers(request)
+	activePlayers := getActivePlayers(request)
+	isXiu := len(activePlayers) > 1 && daXuanInRoom.GetLastBet() == 0
+
+	// 1.分层比牌
+	potWin := obj.settlePots(players, activePlayers)
+
+	// 2.芒果
+	if isXiu {
+		daXuanInRoom.LastMongoType = pb.DaXuanMongoType_DaXuanMongoType_Xiu
+		daXuanInRoom.MongoNum++
+	} else if eater := getBiggest(activePlayers); eater != nil {
+		eater.EatMongo = daXuanInRoom.GetPPool()
+		daXuanInRoom.PPool = 0
+		daXuanInRoom.LastMongoType = pb.DaXuanMongoType_DaXuanMongoType_None
+		daXuanInRoom.MongoNum = 0
+	} else {
+		daXuanInRoom.LastMongoType = pb.DaXuanMongoType_DaXuanMongoType_Zou
+		daXuanInRoom.MongoNum++
+	}
+
+	// 3.输赢和抽水
+	var allBonusIn int64
+	for _, onePlayer := range players {
+		gain := potWin[onePlayer.GetUuid()] + onePlayer.GetEatMongo()
+		winOrLose := gain - onePlayer.GetBetsCount() - onePlayer.GetPlayerMango()
+		water := int64(0)
+		if winOrLose > 0 {
+			water = winOrLose * commission / 100
+		}
+		allBonusIn += water * bonusRatio / 100
+		onePlayer.SafeMoney += gain - water
+		onePlayer.WinOrLose = winOrLose - water
+		onePlayer.HundredCommission = water
+	}
+	if allBonusIn > 0 {
+		_, _, msgErr = common.Bonuser.AddBonus(request.GetGameType(), request.GetGameScene(), allBonusIn, pb.ResourceChangeReason_DaXuanBonusIn, false)
+		if msgErr != nil {
+			common.LogError("DaXuanSettle settle AddBonus has err", msgErr)
+		}
+	}
+
+	// 4.三花和奖励牌型
+	msgErr = obj.settleSanHua(request, players, activePlayers)
+	if msgErr != nil {
+		return msgErr
+	}
+	prizeWin := obj.settlePrize(request, activePlayers)
+
+	var upper *pb.RoomPlayerInfo
+	for _, onePlayer := range players {
+		onePlayer.HundredWaterBill = common.AbsInt64(onePlayer.GetWinOrLose())
+		onePlayer.AllWinOrLose += onePlayer.GetWinOrLose()
+		onePlayer.BetsNums++
+		onePlayer.Pokers = getHandPokers(onePlayer)
+		if onePlayer.GetDaXuanPokerLog() == nil {
+			onePlayer.DaXuanPokerLog = &pb.DaXuanPokerLog{}
+		}
+		onePlayer.DaXuanPokerLog.BetsCount = onePlayer.GetBetsCount() + onePlayer.GetPlayerMango()
+		onePlayer.DaXuanPokerLog.WinOrLose = onePlayer.GetWinOrLose()
+		if onePlayer.GetWinOrLose() > 0 && (upper == nil || onePlayer.GetWinOrLose() > upper.GetWinOrLose()) {
+			upper = onePlayer
+		}
+	}
+	// 赢得最多的玩家下一局坐庄
+	if upper != nil {
+		request.DaXuanUpperUuid = upper.GetUuid()
+	}
+
+	// 5.对局回顾和结算信息
+	settleInfo := &pb.SettleInfo{}
+	request.GameReview = nil
+	bankerUuid := ""
+	if int(request.GetDaXuanMultipleIndex()) < len(request.GetPlayerInfo()) {
+		bankerUuid = request.GetPlayerInfo()[request.GetDaXuanMultipleIndex()].GetUuid()
+	}
+	for _, onePlayer := range players {
+		pokerLog := onePlayer.GetDaXuanPokerLog()
+		request.GameReview = append(request.GameReview, &pb.GameReview{
+			PlayerUuid: onePlayer.GetUuid(),
+			WinOrLose:  onePlayer.GetWinOrLose(),
+			Jackpot:    onePlayer.GetDaXuanPokerTypePrizeAccount(),
+			PartPokers: pokerLog.GetPartPokers(),
+			PokerTypes: pokerLog.GetPokerTypes(),
+			PokerFlag:  pokerLog.GetPokerFlag(),
+			Operation:  onePlayer.GetIsFold(),
+			Bet:        onePlayer.GetBetsCount(),
+			Mango:      []int64{onePlayer.GetPlayerMango(), onePlayer.GetEatMongo()},
+		})
+		settleInfo.SettleUUID = append(settleInfo.SettleUUID, onePlayer.GetUuid())
+		settleInfo.SettleWinOrLose = append(settleInfo.SettleWinOrLose, onePlayer.GetWinOrLose())
+		settleInfo.SettleName = append(settleInfo.SettleName, onePlayer.GetName())
+		settleInfo.ImgUrl = append(settleInfo.ImgUrl, onePlayer.GetHeadImgUrl())
+		settleInfo.AfterBalance = append(settleInfo.AfterBalance, onePlayer.GetSafeMoney())
+		settleInfo.ShortId = append(settleInfo.ShortId, onePlayer.GetShortId())
+		settleInfo.DaXuanAllPokers = append(settleInfo.DaXuanAllPokers, &pb.AllPoker{Pokers: onePlayer.GetPokers()})
+		settleInfo.DaXuanPokerNum = append(settleInfo.DaXuanPokerNum, uint32(len(onePlayer.GetPokers())))
+		settleInfo.DaXuanPokerType = append(settleInfo.DaXuanPokerType, &pb.AllPoker{
+			Pokers:          pokerLog.GetPartPokers(),
+			DaXuanPokerType: pokerLog.GetPokerTypes(),
+		})
+		settleInfo.DaXuanPokerFlag = append(settleInfo.DaXuanPokerFlag, &pb.AllPoker{DaXuanPokerFlag: pokerLog.GetPokerFlag()})
+		settleInfo.DaXuanBetsCount = append(settleInfo.DaXuanBetsCount, pokerLog.GetBetsCount())
+		settleInfo.DaXuanAllZuuid = append(settleInfo.DaXuanAllZuuid, bankerUuid)
+	}
+	request.AllSettleInfo = append(request.AllSettleInfo, settleInfo)
+
+	// 推送结算结果，弃牌玩家的暗牌不公开
+	pushSettle := &pb.PushRoomSettleInfo{RoomId: request.GetUuid()}
+	for _, onePlayer := range players {
+		pushPlayer := proto.Clone(onePlayer).(*pb.RoomPlayerInfo)
+		if pushPlayer.GetIsFold() {
+			pushPlayer.DaXuanPrivatePoker = nil
+			pushPlayer.Pokers = pushPlayer.GetDaXuanPublicPoker()
+		}
+		pushSettle.PlayerInfo = append(pushSettle.PlayerInfo, pushPlayer)
+	}
+	common.RoomBroadcast(request, pushSettle)
+	for _, onePlayer := range players {
+		userBalanceChangePush := &pb.PushUserBalanceChange{}
+		userBalanceChangePush.UserId = onePlayer.GetUuid()
+		userBalanceChangePush.Balance = onePlayer.GetSafeMoney()
+		common.RoomBroadcast(request, userBalanceChangePush)
+	}
+
+	// 6.更新血池，奖池发的奖不算在血池里
+	var score int64
+	for _, onePlayer := range players {
+		if onePlayer.GetIsRobot() {
+			continue
+		}
+		score -= onePlayer.GetWinOrLose() - prizeWin[onePlayer.GetUuid()] + onePlayer.GetHundredCommission()
+	}
+	msgErr = common.BloodIncrease(score, request.GetGameType(), request.GetGameScene())
+	if msgErr != nil {
+		common.LogError("DaXuanSettle settle BloodIncrease has err:", msgErr)
+		return msgErr
+	}
+
+	// 7.游戏记录，机器人不记录
+	var gameRecords []*pb.GameRecordReport
+	for _, onePlayer := range players {
+		if !onePlayer.GetIsRobot() {
+			gameRecords = append(gameRecords, obj.getGameRecord(request, onePlayer, settleInfo, nowTime))
+		}
+	}
+	go obj.pushGameRecords(gameRecords)
+	return nil
+}
+
+// settlePots 按下注的多少分层比牌，返回每个玩家从各层赢得（或者退还）的金额
+func (obj *DaXuanSettle) settlePots(players []*pb.RoomPlayerInfo, activePlayers []*pb.RoomPlayerInfo) map[string]int64 {
+	potWin := make(map[string]int64)
+	var levels []int64
+	for _, onePlayer := range players {
+		betsCount := onePlayer.GetBetsCount()
+		if betsCount <= 0 {
+			continue
+		}
+		isExist := false
+		for _, level := range levels {
+			if level == betsCount {
+				isExist = true
+				break
+			}
+		}
+		if !isExist {
+			levels = append(levels, betsCount)
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i] < levels[j]
+	})
+	lastLevel := int64(0)
+	for _, level := range levels {
+		contributions := make(map[string]int64)
+		var pot int64
+		for _, onePlayer := range players {
+			contribution := minInt64(onePlayer.GetBetsCount(), level) - minInt64(onePlayer.GetBetsCount(), lastLevel)
+			if contribution > 0 {
+				contributions[onePlayer.GetUuid()] = contribution
+				pot += contribution
+			}
+		}
+		lastLevel = level
+		var eligible []*pb.RoomPlayerInfo
+		for _, onePlayer := range activePlayers {
+			if onePlayer.GetBetsCount() >= level {
+				eligible = append(eligible, onePlayer)
+			}
+		}
+		if winner := getBiggest(eligible); winner != nil {
+			potWin[winner.GetUuid()] += pot
+			continue
+		}
+		// 没人可以赢这一层，退还给下注的玩家
+		for _, onePlayer := range players {
+			contribution := contributions[onePlayer.GetUuid()]
+			if contribution > 0 {
+				potWin[onePlayer.GetUuid()] += contribution
+				onePlayer.Refund += contribution
+			}
+		}
+	}
+	return potWin
+}
+
+// settleSanHua 没弃牌并且拿到三花的玩家，其他参与本局的玩家每人按芒果的倍数给他钱，最多给完钵钵
+func (obj *DaXuanSettle) settleSanHua(request *pb.RoomInfo, players []*pb.RoomPlayerInfo, activePlayers []*pb.RoomPlayerInfo) *pb.ErrorMessage {
+	sanHuaTen, msgErr := getRoomConfigInt64(request, "SanHuaTen")
+	if msgErr != nil {
+		return msgErr
+	}
+	sanHuaSix, msgErr := getRoomConfigInt64(request, "SanHuaSix")
+	if msgErr != nil {
+		return msgErr
+	}
+	mongo := request.GetDaXuanInRoom().GetMongo()
+	for _, onePlayer := range activePlayers {
+		hand := getHandPokers(onePlayer)
+		if len(hand) != handPokerNum {
+			continue
+		}
+		sanHuaType := getSanHuaType(hand)
+		onePlayer.DaXuanPokerSanHuaType = sanHuaType
+		var multiple int64
+		switch sanHuaType {
+		case pb.DaXuanPokerSanHuaType_DaXuanPokerSanHuaType_Ten:
+			multiple = sanHuaTen
+		case pb.DaXuanPokerSanHuaType_DaXuanPokerSanHuaType_Six:
+			multiple = sanHuaSix
+		default:
+			continue
+		}
+		for _, otherPlayer := range players {
+			if otherPlayer.GetUuid() == onePlayer.GetUuid() {
+				continue
+			}
+			pay := minInt64(multiple*mongo, otherPlayer.GetSafeMoney())
+			if pay <= 0 {
+				continue
+			}
+			otherPlayer.SafeMoney -= pay
+			otherPlayer.WinOrLose -= pay
+			onePlayer.SafeMoney += pay
+			onePlayer.WinOrLose += pay
+		}
+	}
+	return nil
+}
+
+// settlePrize 没弃牌并且拿到奖励牌型的玩家从奖池按配置的比例领奖，返回每个玩家领到的奖
+func (obj *DaXuanSettle) settlePrize(request *pb.RoomInfo, activePlayers []*pb.RoomPlayerInfo) map[string]int64 {
+	prizeWin := make(map[string]int64)
+	for _, onePlayer := range activePlayers {
+		prizeType := getPrizeType(getHandPokers(onePlayer))
+		if prizeType == pb.DaXuanPokerTypePrizeType_DaXuanPokerTypePrizeType_None {
+			continue
+		}
+		onePlayer.DaXuanPokerTypePrizeType = prizeType
+		ratio := getPrizeRatio(request, prizeType)
+		if ratio <= 0 {
+			continue
+		}
+		win, bonusRecord, msgErr := common.Bonuser.GetBonusByGameTypeAndGameScene(request.GetGameType(), request.GetGameScene(), ratio, pb.ResourceChangeReason_DaXuanPrizeBonus)
+		if msgErr != nil {
+			common.LogError("DaXuanSettle settlePrize GetBonusByGameTypeAndGameScene has err", msgErr)
+			continue
+		}
+		if bonusRecord != nil {
+			bonusRecord.Uuid = onePlayer.GetUuid()
+			bonusRecord.ShortId = onePlayer.GetShortId()
+			msgErr = common.PushBonusRecord(bonusRecord)
+			if msgErr != nil {
+				common.LogError("DaXuanSettle settlePrize PushBonusRecord has err", msgErr)
+			}
+		}
+		onePlayer.DaXuanPokerTypePrizeAccount = win
+		onePlayer.SafeMoney += win
+		onePlayer.WinOrLose += win
+		prizeWin[onePlayer.GetUuid()] = win
+	}
+	return prizeWin
+}
+
+// getBiggest 获取比其他所有玩家都大的玩家，只有一个玩家时就是他，没有返回nil
+func getBiggest(players []*pb.RoomPlayerInfo) *pb.RoomPlayerInfo {
+	for _, onePlayer := range players {
+		isBiggest := true
+		for _, otherPlayer := range players {
+			if otherPlayer.GetUuid() != onePlayer.GetUuid() && compareHand(onePlayer, otherPlayer) != 1 {
+				isBiggest = false
+				break
+			}
+		}
+		if isBiggest {
+			return onePlayer
+		}
+	}
+	return nil
+}
+
+// minInt64 返回两个数中较小的一个
+func minInt64(a int64, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// getGameRecord 生成玩家本局的游戏记录
+func (obj *DaXuanSettle) getGameRecord(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, settleInfo *pb.SettleInfo, settleTime int64) *pb.GameRecordReport {
+	extendData := &pb.GameRecordExtendData{}
+	extendData.Pokers = onePlayer.GetPokers()
+	extendData.AllSettleInfo = []*pb.SettleInfo{settleInfo}
+
+	gameRecord := &pb.GameRecordReport{}
+	gameRecord.GameType = roomInfo.GetGameType()
+	gameRecord.GameScene = roomInfo.GetGameScene()
+	gameRecord.GameMode = common.GameMode
+	gameRecord.RoomType = roomInfo.GetRoomType()
+	gameRecord.RoomId = roomInfo.GetUuid()
+	gameRecord.RoundId = roomInfo.GetCurrentRoundId()
+	gameRecord.PlayerUuid = onePlayer.GetUuid()
+	gameRecord.PlayerShortId = onePlayer.GetShortId()
+	gameRecord.PlayerAccount = onePlayer.GetAccount()
+	gameRecord.StartTime = roomInfo.GetRoundStartTime()
+	gameRecord.SettleTime = settleTime
+	gameRecord.TotalBet = onePlayer.GetBetsCount() + onePlayer.GetPlayerMango()
+	gameRecord.WinOrLose = onePlayer.GetWinOrLose()
+	gameRecord.Commission = onePlayer.GetHundredCommission()
+	// 钵钵在房间解散时才退回，对局中玩家的金币不变
+	gameRecord.BeforeBalance = onePlayer.GetBalance()
+	gameRecord.SettleBalance = onePlayer.GetBalance()
+	gameRecord.ExtendData = extendData
+	return gameRecord
+}
+
+// pushGameRecords 推送游戏记录
+func (obj *DaXuanSettle) pushGameRecords(gameRecords []*pb.GameRecordReport) {
+	for _, gameRecord := range gameRecords {
+		msgErr := common.PushGameRecord(gameRecord)
+		if msgErr != nil {
+			common.LogError("DaXuanSettle pushGameRecords PushGameRecord has err", gameRecord.GetPlayerUuid(), msgErr)
+		}
+	}
+}
+
+// addEscapee 带入过钵钵的玩家离开房间时记录为逃跑玩家，房间解散时退还钵钵和计算惩罚
+func addEscapee(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) {
+	if onePlayer.GetAllSafeMoney() <= 0 {
+		return
+	}
+	escapee := &pb.Escapee{
+		PlayerUuid:   onePlayer.GetUuid(),
+		AllWinOrLose: onePlayer.GetAllWinOrLose(),
+		PunishTime:   onePlayer.GetPunishTime(),
+		SafeMoney:    onePlayer.GetSafeMoney(),
+		BetNum:       onePlayer.GetBetsNums(),
+		AllSafeMoney: onePlayer.GetAllSafeMoney(),
+	}
+	for index, oneEscapee := range roomInfo.GetQPlayer() {
+		if oneEscapee.GetPlayerUuid() == onePlayer.GetUuid() {
+			roomInfo.QPlayer[index] = escapee
+			return
+		}
+	}
+	roomInfo.QPlayer = append(roomInfo.QPlayer, escapee)
+}
+
+// GetGameReview 获取上一局的对局回顾
+func (obj *DaXuanSettle) GetGameReview(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	roomInfo := request.GetRoomInfo()
+	return packReply(roomInfo, &pb.GetGameReviewReply{
+		RoomInfo: &pb.RoomInfo{
+			Uuid:       roomInfo.GetUuid(),
+			GameReview: roomInfo.GetGameReview(),
+		},
+	})
+}
+
+// GetRecord 获取房间的战绩，座位上的玩家和旁观（包括已经离开）的玩家分开
+func (obj *DaXuanSettle) GetRecord(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	roomInfo := request.GetRoomInfo()
+	realReply := &pb.GetRecordReply{}
+	for index, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" {
+			continue
+		}
+		recordPlayer := &pb.RoomPlayerInfo{
+			Uuid:         onePlayer.GetUuid(),
+			ShortId:      onePlayer.GetShortId(),
+			Name:         onePlayer.GetName(),
+			HeadImgUrl:   onePlayer.GetHeadImgUrl(),
+			SafeMoney:    onePlayer.GetSafeMoney(),
+			AllSafeMoney: onePlayer.GetAllSafeMoney(),
+			AllWinOrLose: onePlayer.GetAllWinOrLose(),
+			BetsNums:     onePlayer.GetBetsNums(),
+		}
+		if index < seatNum {
+			realReply.PlayerInfo = append(realReply.PlayerInfo, recordPlayer)
+		} else {
+			realReply.SidelinesPlayerInfo = append(realReply.SidelinesPlayerInfo, recordPlayer)
+		}
+	}
+	for _, oneEscapee := range roomInfo.GetQPlayer() {
+		realReply.SidelinesPlayerInfo = append(realReply.SidelinesPlayerInfo, &pb.RoomPlayerInfo{
+			Uuid:         oneEscapee.GetPlayerUuid(),
+			SafeMoney:    oneEscapee.GetSafeMoney(),
+			AllSafeMoney: oneEscapee.GetAllSafeMoney(),
+			AllWinOrLose: oneEscapee.GetAllWinOrLose(),
+			BetsNums:     oneEscapee.GetBetNum(),
+		})
+	}
+	return packReply(roomInfo, realReply)
+}
@@ -0,0 +1,98 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["DoubleLinkedDeal"] = &DoubleLinkedDeal{}
+}
+
+// DoubleLinkedDeal 双连游戏的发牌组件，用于处理发牌阶段的逻辑
+type DoubleLinkedDeal struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DoubleLinkedDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DoubleLinkedDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 双连发牌阶段的主驱动
+// 庄家和下注倍数都确定后，根据血池状态给每个游戏中的玩家发两张牌，手牌在搓牌阶段才发给玩家自己
+func (obj *DoubleLinkedDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateDeal {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateRubbingCards
+		request.NextRoomState = pb.RoomState_RoomStateRubbingCards
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	dealTime, msgErr := getStateTime(request, "DealTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	typeOdds, msgErr := getDoubleLinkedOdds(request)
+	if msgErr != nil {
+		return request, msgErr
+	}
+	baseScore, msgErr := getBaseScore(request)
+	if msgErr != nil {
+		return request, msgErr
+	}
+	players := getPlayPlayers(request)
+	// 每个玩家最多捞一张牌，牌堆要够所有人捞
+	if len(players)*maxPokerNum > deckPokerNum {
+		common.LogError("DoubleLinkedDeal Drive too many players", len(players))
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	bankerIndex := -1
+	for index, onePlayer := range players {
+		if isBanker(request, onePlayer) {
+			bankerIndex = index
+		}
+	}
+
+	// 推送房间状态 下注<->发牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateBet,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + dealTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	// 发牌，剩下的牌留在牌堆中用于捞牌
+	bloodState := common.BloodGetState(request.GetGameType(), request.GetGameScene())
+	allPokers, cardHeap := dealByControl(players, bankerIndex, request.GetDoubleLinkedMultipleuuidOdds(), bloodState, typeOdds, baseScore)
+	request.PokerCardHeap = cardHeap
+	for index, onePlayer := range players {
+		onePlayer.Pokers = allPokers[index]
+		onePlayer.OutPokers = nil
+		updateHandInfo(onePlayer, typeOdds)
+		// 只通知发了牌，手牌在搓牌阶段发给玩家自己
+		pushCardChange := &pb.PushPlayerCardChange{
+			RoomId: request.GetUuid(),
+			UserId: onePlayer.GetUuid(),
+		}
+		common.RoomBroadcast(request, pushCardChange)
+	}
+
+	request.NextRoomState = pb.RoomState_RoomStateRubbingCards
+	request.DoTime = nowTime + dealTime
+	return request, nil
+}
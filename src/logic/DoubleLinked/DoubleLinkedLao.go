@@ -0,0 +1,244 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["DoubleLinkedLao"] = &DoubleLinkedLao{}
+}
+
+// DoubleLinkedLao 双连游戏的捞牌组件，用于处理闲家捞牌、不捞或者炸牌阶段的逻辑
+type DoubleLinkedLao struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DoubleLinkedLao) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DoubleLinkedLao) Start() {
+	obj.Base.Start()
+}
+
+// Drive 双连闲家捞牌阶段的主驱动
+// 刚进入时告诉每个玩家能不能炸牌；庄家两张牌能炸时直接炸牌，闲家不用再捞，直接进入庄家阶段比牌
+// 闲家选择捞一张牌、不捞或者炸牌，所有闲家都选择了或者捞牌时间到了就进入庄家捞牌阶段
+// 时间到了还没选择的闲家和断线的闲家由系统选择：能炸就炸，否则不捞
+func (obj *DoubleLinkedLao) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	typeOdds, msgErr := getDoubleLinkedOdds(request)
+	if msgErr != nil {
+		return request, msgErr
+	}
+	if request.NextRoomState != pb.RoomState_RoomStateLao {
+		if nowTime < request.DoTime && !obj.isAllLao(request) {
+			return request, nil
+		}
+		for _, onePlayer := range getPlayPlayers(request) {
+			if !isBanker(request, onePlayer) && onePlayer.GetDoubleLinkedLao() == 0 {
+				doLao(request, onePlayer, getAutoLaoType(onePlayer), typeOdds)
+			}
+		}
+		request.CurRoomState = pb.RoomState_RoomStateZhuangLao
+		request.NextRoomState = pb.RoomState_RoomStateZhuangLao
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	banker := getBanker(request)
+	if banker == nil {
+		common.LogError("DoubleLinkedLao Drive banker not found", request.GetUuid(), request.GetDoubleLinkedMultipleuuid())
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	laoTime, msgErr := getStateTime(request, "LaoTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	endTime := nowTime + laoTime
+	// 推送房间状态 搓牌<->捞牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateRubbingCards,
+		AfterState:        pb.RoomState_RoomStateLao,
+		AfterStateEndTime: endTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+	for _, onePlayer := range getPlayPlayers(request) {
+		pushIsBoom := &pb.PushIsDoubleLinkedBoom{
+			RoomId:   request.GetUuid(),
+			Uuid:     onePlayer.GetUuid(),
+			Isboom:   canBoom(onePlayer.GetPokers()),
+			EndTime:  endTime,
+			PokerNum: int64(onePlayer.GetDoubleLinkedPokerNum()),
+		}
+		common.Pusher.Push(pushIsBoom, onePlayer.GetUuid())
+	}
+
+	// 庄家炸牌，能炸的闲家跟着炸，其他闲家不能再捞牌
+	if canBoom(banker.GetPokers()) {
+		doLao(request, banker, laoTypeBoom, typeOdds)
+		for _, onePlayer := range getPlayPlayers(request) {
+			if !isBanker(request, onePlayer) {
+				doLao(request, onePlayer, getAutoLaoType(onePlayer), typeOdds)
+			}
+		}
+		request.NextRoomState = pb.RoomState_RoomStateZhuangLao
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	// 断线的闲家不用等待，直接由系统选择
+	for _, onePlayer := range getPlayPlayers(request) {
+		if !isBanker(request, onePlayer) && !isOnline(onePlayer) {
+			doLao(request, onePlayer, getAutoLaoType(onePlayer), typeOdds)
+		}
+	}
+	request.NextRoomState = pb.RoomState_RoomStateZhuangLao
+	request.DoTime = endTime
+	return request, nil
+}
+
+// isAllLao 游戏中的闲家是否都选择了捞的操作
+func (obj *DoubleLinkedLao) isAllLao(roomInfo *pb.RoomInfo) bool {
+	for _, onePlayer := range getPlayPlayers(roomInfo) {
+		if !isBanker(roomInfo, onePlayer) && onePlayer.GetDoubleLinkedLao() == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// getAutoLaoType 超时或者断线时系统帮玩家选择的捞操作，能炸就炸，否则不捞
+func getAutoLaoType(onePlayer *pb.RoomPlayerInfo) int64 {
+	if canBoom(onePlayer.GetPokers()) {
+		return laoTypeBoom
+	}
+	return laoTypeNotLao
+}
+
+// doLao 记录玩家捞的操作并广播
+// 捞牌时从牌堆摸一张牌，新的手牌只推送给自己；炸牌时直接亮牌
+func doLao(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, laoType int64, typeOdds map[pb.DoubleLinkedPokerType]int64) {
+	onePlayer.DoubleLinkedLao = laoType
+	switch laoType {
+	case laoTypeLao:
+		if !drawPoker(roomInfo, onePlayer) {
+			common.LogError("DoubleLinked doLao drawPoker failed", roomInfo.GetUuid(), onePlayer.GetUuid(), len(roomInfo.GetPokerCardHeap()))
+			onePlayer.DoubleLinkedLao = laoTypeNotLao
+			break
+		}
+		updateHandInfo(onePlayer, typeOdds)
+		onePlayer.OutPokers = onePlayer.GetPokers()
+		pushToSelf := &pb.PushPlayerCardChange{
+			RoomId:                roomInfo.GetUuid(),
+			UserId:                onePlayer.GetUuid(),
+			HandPoker:             onePlayer.GetPokers(),
+			DoubleLinkedPokerType: onePlayer.GetDoubleLinkedPokerType(),
+			DoubleLinkedPokerOdds: onePlayer.GetDoubleLinkedPokerOdds(),
+			DoubleLinkedPokerNum:  onePlayer.GetDoubleLinkedPokerNum(),
+			DoubleLinkedSlOrSl:    getSlOrSl(onePlayer.GetDoubleLinkedPokerType()),
+			IsSlOrSl:              onePlayer.GetIsSlOrSl(),
+		}
+		pushToOthers := &pb.PushPlayerCardChange{
+			RoomId: roomInfo.GetUuid(),
+			UserId: onePlayer.GetUuid(),
+		}
+		msgErr := common.PushRoom(pushToSelf, pushToOthers, onePlayer.GetUuid(), roomInfo)
+		if msgErr != nil {
+			common.LogError("DoubleLinked doLao PushRoom has err", onePlayer.GetUuid(), msgErr)
+		}
+	case laoTypeBoom:
+		pushBoomPlayer := &pb.PushIsDoubleLinkedBoomPlayer{
+			RoomId:   roomInfo.GetUuid(),
+			Uuid:     onePlayer.GetUuid(),
+			Isboom:   true,
+			PokerNum: int64(onePlayer.GetDoubleLinkedPokerNum()),
+		}
+		common.RoomBroadcast(roomInfo, pushBoomPlayer)
+		pushCardChange := &pb.PushPlayerCardChange{
+			RoomId:                roomInfo.GetUuid(),
+			UserId:                onePlayer.GetUuid(),
+			OutPoker:              onePlayer.GetPokers(),
+			DoubleLinkedIsBoom:    true,
+			DoubleLinkedPokerType: onePlayer.GetDoubleLinkedPokerType(),
+			DoubleLinkedPokerOdds: onePlayer.GetDoubleLinkedPokerOdds(),
+			DoubleLinkedPokerNum:  onePlayer.GetDoubleLinkedPokerNum(),
+			DoubleLinkedSlOrSl:    getSlOrSl(onePlayer.GetDoubleLinkedPokerType()),
+			IsSlOrSl:              onePlayer.GetIsSlOrSl(),
+			IsOpenCard:            true,
+		}
+		common.RoomBroadcast(roomInfo, pushCardChange)
+	}
+
+	if isBanker(roomInfo, onePlayer) {
+		pushZhuangLao := &pb.DoubleLinkedPlayerZhuangLao{
+			Lao:    onePlayer.GetDoubleLinkedLao(),
+			Uuid:   onePlayer.GetUuid(),
+			RoomId: roomInfo.GetUuid(),
+			Isboom: onePlayer.GetDoubleLinkedLao() == laoTypeBoom,
+		}
+		common.RoomBroadcast(roomInfo, pushZhuangLao)
+		return
+	}
+	pushLao := &pb.DoubleLinkedPlayerLao{
+		Lao:    onePlayer.GetDoubleLinkedLao(),
+		Uuid:   onePlayer.GetUuid(),
+		RoomId: roomInfo.GetUuid(),
+	}
+	if onePlayer.GetDoubleLinkedLao() == laoTypeBoom {
+		pushLao.PokerNum = int64(onePlayer.GetDoubleLinkedPokerNum())
+	}
+	common.RoomBroadcast(roomInfo, pushLao)
+}
+
+// RequestLao 玩家选择捞牌、不捞或者炸牌，每局只能选择一次
+// 捞牌阶段由闲家选择，庄家捞牌阶段由庄家选择；只有两张牌8点或9点时才能炸牌
+func (obj *DoubleLinkedLao) RequestLao(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	isLaoState := roomInfo.GetCurRoomState() == pb.RoomState_RoomStateLao && roomInfo.GetNextRoomState() != pb.RoomState_RoomStateLao
+	isZhuangLaoState := roomInfo.GetCurRoomState() == pb.RoomState_RoomStateZhuangLao && roomInfo.GetNextRoomState() != pb.RoomState_RoomStateZhuangLao
+	if !isLaoState && !isZhuangLaoState {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.DoubleLinkedLaoRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("DoubleLinkedLao RequestLao ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("DoubleLinkedLao RequestLao player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if playerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay || isBanker(roomInfo, playerInfo) != isZhuangLaoState {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	if playerInfo.GetDoubleLinkedLao() != 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	laoType := realRequest.GetLao()
+	if laoType != laoTypeLao && laoType != laoTypeNotLao && laoType != laoTypeBoom {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+	}
+	if laoType == laoTypeBoom && !canBoom(playerInfo.GetPokers()) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+	}
+	typeOdds, msgErr := getDoubleLinkedOdds(roomInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	doLao(roomInfo, playerInfo, laoType, typeOdds)
+	return packReply(roomInfo, &pb.DoubleLinkedLaoReply{IsSuccess: true})
+}
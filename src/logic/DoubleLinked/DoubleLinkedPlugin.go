@@ -0,0 +1,102 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+)
+
+// 双连通过注册描述生成DoubleLinkedRoute和DoubleLinkedDriver组件
+func init() {
+	common.RegisterGamePlugin(&common.GamePlugin{
+		GameType:   pb.GameType_DoubleLinked,
+		CnName:     "双连",
+		ConfigTemp: common.DoubleLinkedGameConfigTemp,
+		DoList: map[int32]*common.GamePluginDo{
+			//进入房间
+			int32(pb.DoubleLinkedDoType_DoubleLinkedDo_JoinRoom): {
+				Kind:    common.GamePluginDoJoinRoom,
+				Request: &pb.GameJoinRoomRequest{},
+				Reply:   &pb.GameJoinRoomReply{},
+			},
+			//退出房间
+			int32(pb.DoubleLinkedDoType_DoubleLinkedDo_ExitRoom): {
+				Kind:    common.GamePluginDoExitRoom,
+				Request: &pb.GameExitRoomRequest{},
+				Reply:   &pb.GameExitRoomReply{},
+			},
+			//玩家准备或取消准备
+			int32(pb.DoubleLinkedDoType_DoubleLinkedDo_ChangeState): {
+				Request:   &pb.GameChangeStateRequest{},
+				Reply:     &pb.GameChangeStateReply{},
+				Component: "DoubleLinkedReady",
+				Method:    "RequestChangeState",
+			},
+			//玩家开牌
+			int32(pb.DoubleLinkedDoType_DoubleLinkedDo_OpenCard): {
+				Request:   &pb.GameOpenCardRequest{},
+				Reply:     &pb.GameOpenCardReply{},
+				Component: "DoubleLinkedRubbingCards",
+				Method:    "RequestOpenCard",
+			},
+			//玩家抢庄
+			int32(pb.DoubleLinkedDoType_DoubleLinkedDo_RushVillage): {
+				Request:   &pb.DoubleLinkedUpBankerRequest{},
+				Reply:     &pb.DoubleLinkedUpBankerReply{},
+				Component: "DoubleLinkedRushVillage",
+				Method:    "RequestRushVillage",
+			},
+			//闲家下注
+			int32(pb.DoubleLinkedDoType_DoubleLinkedDo_Bets): {
+				Request:   &pb.DoubleLinkedBetRequest{},
+				Reply:     &pb.DoubleLinkedBetReply{},
+				Component: "DoubleLinkedBet",
+				Method:    "RequestBet",
+			},
+			//玩家搓牌
+			int32(pb.DoubleLinkedDoType_DoubleLinkedDo_RubbingCards): {
+				Request:   &pb.DoubleRubbingCardsRequest{},
+				Reply:     &pb.DoubleRubbingCardsReply{},
+				Component: "DoubleLinkedRubbingCards",
+				Method:    "RequestRubbingCards",
+			},
+			//闲家或者庄家捞牌
+			int32(pb.DoubleLinkedDoType_DoubleLinkedDo_Lao): {
+				Request:   &pb.DoubleLinkedLaoRequest{},
+				Reply:     &pb.DoubleLinkedLaoReply{},
+				Component: "DoubleLinkedLao",
+				Method:    "RequestLao",
+			},
+			//庄家比牌
+			int32(pb.DoubleLinkedDoType_DoubleLinkedDo_Poker): {
+				Request:   &pb.DoubleLinkedPokerRequest{},
+				Reply:     &pb.DoubleLinkedPokerReply{},
+				Component: "DoubleLinkedZhuangLao",
+				Method:    "RequestPoker",
+			},
+			//房卡场创建自建房
+			int32(pb.DoubleLinkedDoType_DoubleLinkedDo_CreateRoom): {
+				Kind:    common.GamePluginDoCreateRoom,
+				Request: &pb.GameCreateRoomRequest{},
+				Reply:   &pb.GameCreateRoomReply{},
+			},
+			//自建房房主开始、踢人和解散
+			int32(pb.DoubleLinkedDoType_DoubleLinkedDo_StartRoom): {
+				Kind:    common.GamePluginDoRoomMaster,
+				Request: &pb.GameStartRoomRequest{},
+				Reply:   &pb.GameStartRoomReply{},
+			},
+		},
+		StateComponents: map[pb.RoomState]string{
+			pb.RoomState_RoomStateReady:        "DoubleLinkedReady",
+			pb.RoomState_RoomStateRushVillage:  "DoubleLinkedRushVillage",
+			pb.RoomState_RoomStateBet:          "DoubleLinkedBet",
+			pb.RoomState_RoomStateDeal:         "DoubleLinkedDeal",
+			pb.RoomState_RoomStateRubbingCards: "DoubleLinkedRubbingCards",
+			pb.RoomState_RoomStateLao:          "DoubleLinkedLao",
+			pb.RoomState_RoomStateZhuangLao:    "DoubleLinkedZhuangLao",
+			pb.RoomState_RoomStateSettle:       "DoubleLinkedSettle",
+		},
+		// 对局中退出时本局仍然参与比牌和结算，结算后再踢出
+		ExitInGameComponent: "DoubleLinkedRubbingCards",
+	})
+}
@@ -0,0 +1,200 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["DoubleLinkedRubbingCards"] = &DoubleLinkedRubbingCards{}
+}
+
+// DoubleLinkedRubbingCards 双连游戏的搓牌组件，用于处理玩家搓牌和看牌阶段的逻辑
+type DoubleLinkedRubbingCards struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DoubleLinkedRubbingCards) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DoubleLinkedRubbingCards) Start() {
+	obj.Base.Start()
+}
+
+// Drive 双连搓牌阶段的主驱动
+// 刚进入时把两张手牌发给玩家自己，玩家可以搓牌或者直接看牌，看完后开牌（只有自己能看到牌）
+// 所有玩家都开牌或者搓牌时间到了就进入捞牌阶段，时间到了还没开牌的玩家和断线的玩家由系统帮忙开牌
+func (obj *DoubleLinkedRubbingCards) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateRubbingCards {
+		if nowTime < request.DoTime && !obj.isAllOpen(request) {
+			return request, nil
+		}
+		for _, onePlayer := range getPlayPlayers(request) {
+			if len(onePlayer.GetOutPokers()) == 0 {
+				obj.openCard(request, onePlayer)
+			}
+		}
+		request.CurRoomState = pb.RoomState_RoomStateLao
+		request.NextRoomState = pb.RoomState_RoomStateLao
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	rubbingCardsTime, msgErr := getStateTime(request, "RubbingCardsTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	endTime := nowTime + rubbingCardsTime
+	// 推送房间状态 发牌<->搓牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateDeal,
+		AfterState:        pb.RoomState_RoomStateRubbingCards,
+		AfterStateEndTime: endTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+	// 把手牌发给游戏中的玩家自己，断线的玩家直接开牌
+	for _, onePlayer := range getPlayPlayers(request) {
+		pushOpenCard := &pb.PushPlayerOpenCard{
+			RoomId:    request.GetUuid(),
+			HandPoker: onePlayer.GetPokers(),
+			EndTime:   endTime,
+		}
+		common.Pusher.Push(pushOpenCard, onePlayer.GetUuid())
+		if !isOnline(onePlayer) {
+			obj.openCard(request, onePlayer)
+		}
+	}
+
+	request.NextRoomState = pb.RoomState_RoomStateLao
+	request.DoTime = endTime
+	return request, nil
+}
+
+// isAllOpen 游戏中的玩家是否都开牌了
+func (obj *DoubleLinkedRubbingCards) isAllOpen(roomInfo *pb.RoomInfo) bool {
+	for _, onePlayer := range getPlayPlayers(roomInfo) {
+		if len(onePlayer.GetOutPokers()) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// openCard 玩家开牌，牌、牌型和点数只推送给自己，其他人只知道玩家开了牌，要到比牌时才亮出来
+func (obj *DoubleLinkedRubbingCards) openCard(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) {
+	onePlayer.OutPokers = onePlayer.GetPokers()
+	pushToSelf := &pb.PushPlayerCardChange{
+		RoomId:                roomInfo.GetUuid(),
+		UserId:                onePlayer.GetUuid(),
+		OutPoker:              onePlayer.GetOutPokers(),
+		DoubleLinkedIsBoom:    canBoom(onePlayer.GetPokers()),
+		DoubleLinkedPokerType: onePlayer.GetDoubleLinkedPokerType(),
+		DoubleLinkedPokerOdds: onePlayer.GetDoubleLinkedPokerOdds(),
+		DoubleLinkedPokerNum:  onePlayer.GetDoubleLinkedPokerNum(),
+		DoubleLinkedSlOrSl:    getSlOrSl(onePlayer.GetDoubleLinkedPokerType()),
+		IsSlOrSl:              onePlayer.GetIsSlOrSl(),
+		IsOpenCard:            true,
+	}
+	pushToOthers := &pb.PushPlayerCardChange{
+		RoomId:     roomInfo.GetUuid(),
+		UserId:     onePlayer.GetUuid(),
+		IsOpenCard: true,
+	}
+	msgErr := common.PushRoom(pushToSelf, pushToOthers, onePlayer.GetUuid(), roomInfo)
+	if msgErr != nil {
+		common.LogError("DoubleLinkedRubbingCards openCard PushRoom has err", onePlayer.GetUuid(), msgErr)
+	}
+}
+
+// RequestRubbingCards 玩家选择搓牌或者看牌，只是通知其他玩家播放对应的动画，每局只能选择一次
+func (obj *DoubleLinkedRubbingCards) RequestRubbingCards(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateRubbingCards || roomInfo.GetNextRoomState() == pb.RoomState_RoomStateRubbingCards {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.DoubleRubbingCardsRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("DoubleLinkedRubbingCards RequestRubbingCards ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("DoubleLinkedRubbingCards RequestRubbingCards player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if playerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay || len(playerInfo.GetOutPokers()) != 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	if playerInfo.GetDoubleLinkedRubbingCards() != 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	if realRequest.GetRubbingCards() <= 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+	}
+	playerInfo.DoubleLinkedRubbingCards = realRequest.GetRubbingCards()
+	pushRubbingCards := &pb.DoubleLinkedPlayerRubbingCards{
+		RubbingCardsType: realRequest.GetRubbingCards(),
+		Uuid:             uid,
+		RoomId:           roomInfo.GetUuid(),
+	}
+	common.RoomBroadcast(roomInfo, pushRubbingCards)
+	return packReply(roomInfo, &pb.DoubleRubbingCardsReply{IsSuccess: true})
+}
+
+// RequestOpenCard 玩家看完牌后开牌
+func (obj *DoubleLinkedRubbingCards) RequestOpenCard(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateRubbingCards || roomInfo.GetNextRoomState() == pb.RoomState_RoomStateRubbingCards {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("DoubleLinkedRubbingCards RequestOpenCard player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if playerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	if len(playerInfo.GetOutPokers()) != 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	obj.openCard(roomInfo, playerInfo)
+	return packReply(roomInfo, &pb.GameOpenCardReply{})
+}
+
+// RequestExitInGame 玩家在对局中退出房间
+// 玩家本局仍然参与比牌和结算，没操作的步骤在超时后由系统代为操作，结算后状态置空由房间的Kick踢出
+func (obj *DoubleLinkedRubbingCards) RequestExitInGame(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	// 自建房打完所有局数前不能退出
+	if common.IsPlayerRoom(roomInfo) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotAllowExitRoom, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	playerInfo.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_Exit
+	// 结算阶段本局已经结算完了，可以直接踢出
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStateSettle && roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+	}
+	return packReply(roomInfo, &pb.GameExitRoomReply{})
+}
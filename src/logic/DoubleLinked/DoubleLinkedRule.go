@@ -0,0 +1,476 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// 发牌阶段每个玩家的手牌张数
+const dealPokerNum = 2
+
+// 捞牌后手牌的最大张数
+const maxPokerNum = 3
+
+// 一副牌（不含大小王）的张数，所有玩家的手牌和捞的牌都从一副牌中发出
+const deckPokerNum = 52
+
+// 两张牌达到这个点数及以上时可以炸牌（8点和9点）
+const boomPokerNum = 8
+
+// 血池控制时最多尝试的发牌组合数量
+const controlTryNum = 30
+
+// 捞的操作类型，记录在玩家的DoubleLinkedLao中，0表示还没有操作
+const (
+	// 捞一张牌
+	laoTypeLao int64 = 1
+	// 不捞
+	laoTypeNotLao int64 = 2
+	// 两张牌8点或9点时炸牌，直接亮牌
+	laoTypeBoom int64 = 3
+)
+
+// 参与赔率计算的牌型
+var doubleLinkedPokerTypes = []pb.DoubleLinkedPokerType{
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_None,
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_DoubleLink,
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_Trio,
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_Pair,
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_3GaLa,
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_TuoLaJi,
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_QingTuoLaJi,
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_3P,
+}
+
+// 三张牌的特殊牌型从大到小，特殊牌型比任何点数都大，但比炸牌小
+var specialPokerTypes = []pb.DoubleLinkedPokerType{
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_3GaLa,
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_QingTuoLaJi,
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_TuoLaJi,
+	pb.DoubleLinkedPokerType_DoubleLinkedCardType_3P,
+}
+
+// doubleLinkedHand 一个玩家的手牌、牌型和点数
+type doubleLinkedHand struct {
+	pokers    []*pb.Poker
+	pokerType pb.DoubleLinkedPokerType
+	pokerNum  int64
+	isBoom    bool
+}
+
+// getPokerPoint 获取一张牌的点数，A为1点，10和JQK为0点
+func getPokerPoint(poker *pb.Poker) int64 {
+	if poker.GetPokerNum() >= pb.PokerNum_PokerNum10 {
+		return 0
+	}
+	return int64(poker.GetPokerNum())
+}
+
+// getPokerNum 计算手牌的点数，所有牌点数相加取个位
+func getPokerNum(pokers []*pb.Poker) int64 {
+	var sum int64
+	for _, poker := range pokers {
+		sum += getPokerPoint(poker)
+	}
+	return sum % 10
+}
+
+// canBoom 两张牌8点或9点时可以炸牌
+func canBoom(pokers []*pb.Poker) bool {
+	return len(pokers) == dealPokerNum && getPokerNum(pokers) >= boomPokerNum
+}
+
+// isSameColor 手牌是否都是同一个花色
+func isSameColor(pokers []*pb.Poker) bool {
+	for _, poker := range pokers {
+		if poker.GetPokerColor() != pokers[0].GetPokerColor() {
+			return false
+		}
+	}
+	return true
+}
+
+// isStraight 三张牌是否是顺子，A可以当1也可以当14，即A23和QKA都是顺子
+func isStraight(pokers []*pb.Poker) bool {
+	if len(pokers) != maxPokerNum {
+		return false
+	}
+	var nums []int
+	for _, poker := range pokers {
+		nums = append(nums, int(poker.GetPokerNum()))
+	}
+	for i := 0; i < len(nums); i++ {
+		for j := i + 1; j < len(nums); j++ {
+			if nums[j] < nums[i] {
+				nums[i], nums[j] = nums[j], nums[i]
+			}
+		}
+	}
+	if nums[0]+1 == nums[1] && nums[1]+1 == nums[2] {
+		return true
+	}
+	return nums[0] == int(pb.PokerNum_PokerNum1) && nums[1] == int(pb.PokerNum_PokerNumQ) && nums[2] == int(pb.PokerNum_PokerNumK)
+}
+
+// getDoubleLinkedPokerType 获取手牌的牌型
+// 两张牌：对子、双联（同花色）；三张牌：三嘎啦（三张相同）、清拖拉机（同花顺）、杂色拖拉机（顺子）、3P（三张JQK）、三联（同花色）
+func getDoubleLinkedPokerType(pokers []*pb.Poker) pb.DoubleLinkedPokerType {
+	switch len(pokers) {
+	case dealPokerNum:
+		if pokers[0].GetPokerNum() == pokers[1].GetPokerNum() {
+			return pb.DoubleLinkedPokerType_DoubleLinkedCardType_Pair
+		}
+		if isSameColor(pokers) {
+			return pb.DoubleLinkedPokerType_DoubleLinkedCardType_DoubleLink
+		}
+	case maxPokerNum:
+		if pokers[0].GetPokerNum() == pokers[1].GetPokerNum() && pokers[1].GetPokerNum() == pokers[2].GetPokerNum() {
+			return pb.DoubleLinkedPokerType_DoubleLinkedCardType_3GaLa
+		}
+		if isStraight(pokers) {
+			if isSameColor(pokers) {
+				return pb.DoubleLinkedPokerType_DoubleLinkedCardType_QingTuoLaJi
+			}
+			return pb.DoubleLinkedPokerType_DoubleLinkedCardType_TuoLaJi
+		}
+		isAllP := true
+		for _, poker := range pokers {
+			if poker.GetPokerNum() < pb.PokerNum_PokerNumJ || poker.GetPokerNum() > pb.PokerNum_PokerNumK {
+				isAllP = false
+				break
+			}
+		}
+		if isAllP {
+			return pb.DoubleLinkedPokerType_DoubleLinkedCardType_3P
+		}
+		if isSameColor(pokers) {
+			return pb.DoubleLinkedPokerType_DoubleLinkedCardType_Trio
+		}
+	}
+	return pb.DoubleLinkedPokerType_DoubleLinkedCardType_None
+}
+
+// getSlOrSl 获取需要展示的双联或三联，双联为2，三联为3，其他为0
+func getSlOrSl(pokerType pb.DoubleLinkedPokerType) uint32 {
+	switch pokerType {
+	case pb.DoubleLinkedPokerType_DoubleLinkedCardType_DoubleLink:
+		return 2
+	case pb.DoubleLinkedPokerType_DoubleLinkedCardType_Trio:
+		return 3
+	}
+	return 0
+}
+
+// newDoubleLinkedHand 根据手牌生成手牌信息
+func newDoubleLinkedHand(pokers []*pb.Poker) *doubleLinkedHand {
+	return &doubleLinkedHand{
+		pokers:    pokers,
+		pokerType: getDoubleLinkedPokerType(pokers),
+		pokerNum:  getPokerNum(pokers),
+		isBoom:    canBoom(pokers),
+	}
+}
+
+// getRank 获取手牌的大小，炸牌最大（9点比8点大），其次是三张牌的特殊牌型，最后按点数比较
+func (hand *doubleLinkedHand) getRank() int64 {
+	if hand.isBoom {
+		return 200 + hand.pokerNum
+	}
+	for index, pokerType := range specialPokerTypes {
+		if hand.pokerType == pokerType {
+			return 100 + int64(len(specialPokerTypes)-index)
+		}
+	}
+	return hand.pokerNum
+}
+
+// isBiggerThanBanker 判断闲家的手牌是否比庄家大，大小相同时庄家赢
+func (hand *doubleLinkedHand) isBiggerThanBanker(bankerHand *doubleLinkedHand) bool {
+	return hand.getRank() > bankerHand.getRank()
+}
+
+// getDoubleLinkedOdds 获取房间的牌型赔率配置，配置名为Odds加上牌型名，如OddsNone、OddsDoubleLink
+func getDoubleLinkedOdds(roomInfo *pb.RoomInfo) (map[pb.DoubleLinkedPokerType]int64, *pb.ErrorMessage) {
+	typeOdds := make(map[pb.DoubleLinkedPokerType]int64)
+	for _, pokerType := range doubleLinkedPokerTypes {
+		oddsName := "Odds" + strings.TrimPrefix(pokerType.String(), "DoubleLinkedCardType_")
+		oddsStr := common.GetRoomConfig(roomInfo, oddsName)
+		oddsNum, err := strconv.ParseInt(oddsStr, 10, 64)
+		if err != nil || oddsNum <= 0 {
+			common.LogError("getDoubleLinkedOdds has err", oddsName, oddsStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		typeOdds[pokerType] = oddsNum
+	}
+	return typeOdds, nil
+}
+
+// getBaseScore 获取房间的底分
+func getBaseScore(roomInfo *pb.RoomInfo) (int64, *pb.ErrorMessage) {
+	baseScoreStr := common.GetRoomConfig(roomInfo, "BaseScore")
+	baseScore, err := strconv.ParseInt(baseScoreStr, 10, 64)
+	if err != nil || baseScore <= 0 {
+		common.LogError("DoubleLinked getBaseScore has err", baseScoreStr, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return baseScore, nil
+}
+
+// getStateTime 获取房间阶段时间的配置，如ReadyTime、LaoTime
+func getStateTime(roomInfo *pb.RoomInfo, configName string) (int64, *pb.ErrorMessage) {
+	timeStr := common.GetRoomConfig(roomInfo, configName)
+	stateTime, err := strconv.ParseInt(timeStr, 10, 64)
+	if err != nil || stateTime < 0 {
+		common.LogError("DoubleLinked getStateTime has err", configName, timeStr, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return stateTime, nil
+}
+
+// getOddsList 获取逗号分隔的倍数列表配置，如抢庄倍数RushVillageOdds、下注倍数BetOdds
+func getOddsList(roomInfo *pb.RoomInfo, configName string) ([]int64, *pb.ErrorMessage) {
+	var oddsList []int64
+	for _, oddsStr := range strings.Split(common.GetRoomConfig(roomInfo, configName), ",") {
+		odds, err := strconv.ParseInt(oddsStr, 10, 64)
+		if err != nil || odds < 0 {
+			common.LogError("DoubleLinked getOddsList has err", configName, oddsStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		oddsList = append(oddsList, odds)
+	}
+	return oddsList, nil
+}
+
+// isInOddsList 判断倍数是否是配置中可以选择的倍数
+func isInOddsList(oddsList []int64, odds int64) bool {
+	for _, oneOdds := range oddsList {
+		if oneOdds == odds {
+			return true
+		}
+	}
+	return false
+}
+
+// getMinOdds 获取倍数列表中最小的倍数，超时自动操作时使用
+func getMinOdds(oddsList []int64) int64 {
+	var minOdds int64
+	for index, odds := range oddsList {
+		if index == 0 || odds < minOdds {
+			minOdds = odds
+		}
+	}
+	return minOdds
+}
+
+// getPlayPlayers 获取本局参与游戏的玩家
+func getPlayPlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+			continue
+		}
+		players = append(players, onePlayer)
+	}
+	return players
+}
+
+// getBanker 获取本局的庄家
+func getBanker(roomInfo *pb.RoomInfo) *pb.RoomPlayerInfo {
+	if roomInfo.GetDoubleLinkedMultipleuuid() == "" {
+		return nil
+	}
+	return common.GetRoomPlayerInfo(roomInfo, roomInfo.GetDoubleLinkedMultipleuuid())
+}
+
+// isBanker 判断玩家是否是本局的庄家
+func isBanker(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) bool {
+	return roomInfo.GetDoubleLinkedMultipleuuid() != "" && roomInfo.GetDoubleLinkedMultipleuuid() == onePlayer.GetUuid()
+}
+
+// isOnline 判断玩家是否在线，出错时按照不在线处理
+func isOnline(onePlayer *pb.RoomPlayerInfo) bool {
+	online, msgErr := common.Pusher.CheckOnline(onePlayer.GetUuid())
+	if msgErr != nil {
+		common.LogError("DoubleLinked isOnline CheckOnline has err", onePlayer.GetUuid(), msgErr)
+		return false
+	}
+	return online
+}
+
+// chooseBanker 根据玩家的抢庄倍数选出庄家
+// 抢庄倍数最高的玩家中随机一个坐庄，都不抢时在所有玩家中随机，庄家倍数最低为1
+// 返回值：庄家，庄家倍数，抢庄倍数最高的玩家
+func chooseBanker(players []*pb.RoomPlayerInfo) (*pb.RoomPlayerInfo, int64, []*pb.RoomPlayerInfo) {
+	var maxOdds int64
+	var candidates []*pb.RoomPlayerInfo
+	for _, onePlayer := range players {
+		if onePlayer.GetDoubleLinkedOdds() > maxOdds {
+			maxOdds = onePlayer.GetDoubleLinkedOdds()
+			candidates = nil
+		}
+		if onePlayer.GetDoubleLinkedOdds() == maxOdds {
+			candidates = append(candidates, onePlayer)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, 0, nil
+	}
+	banker := candidates[rand.Intn(len(candidates))]
+	if maxOdds <= 0 {
+		maxOdds = 1
+	}
+	return banker, maxOdds, candidates
+}
+
+// getPlayerHand 获取玩家比牌时的手牌，只有选择了炸牌的才按炸牌算
+func getPlayerHand(onePlayer *pb.RoomPlayerInfo) *doubleLinkedHand {
+	hand := newDoubleLinkedHand(onePlayer.GetPokers())
+	hand.isBoom = hand.isBoom && onePlayer.GetDoubleLinkedLao() == laoTypeBoom
+	return hand
+}
+
+// getBankerHand 获取和闲家比牌时庄家的手牌
+// 庄家捞牌前和闲家比过牌的，按比牌时庄家的牌张数（记录在闲家的DoubleLinkedIsPokerPlayer中）计算
+func getBankerHand(banker *pb.RoomPlayerInfo, onePlayer *pb.RoomPlayerInfo) *doubleLinkedHand {
+	bankerPokers := banker.GetPokers()
+	pokerNum := int(onePlayer.GetDoubleLinkedIsPokerPlayer())
+	if onePlayer.GetDoubleLinkedIsPoker() && pokerNum > 0 && pokerNum < len(bankerPokers) {
+		bankerPokers = bankerPokers[:pokerNum]
+	}
+	hand := newDoubleLinkedHand(bankerPokers)
+	hand.isBoom = hand.isBoom && banker.GetDoubleLinkedLao() == laoTypeBoom
+	return hand
+}
+
+// getSettleWinOrLose 计算每个玩家本局的输赢（未抽水）
+// 闲家只和庄家比牌，输赢为底分*庄家倍数*闲家下注倍数*赢家牌型赔率
+// 闲家最多输掉身上的金额；庄家赔付的总额超过身上的金额加上赢到的钱时，按比例缩减赔给每个闲家的金额
+// 参数：players 参与游戏的玩家，hands 与玩家一一对应的手牌，bankerHands 与玩家一一对应的和该闲家比牌的庄家手牌
+// 返回值：与玩家一一对应的输赢
+func getSettleWinOrLose(players []*pb.RoomPlayerInfo, hands []*doubleLinkedHand, bankerHands []*doubleLinkedHand, bankerIndex int, bankerOdds int64, typeOdds map[pb.DoubleLinkedPokerType]int64, baseScore int64) []int64 {
+	winOrLose := make([]int64, len(players))
+	if bankerIndex < 0 || bankerIndex >= len(players) {
+		return winOrLose
+	}
+	// 庄家从输家赢到的钱
+	var bankerWin int64
+	// 庄家需要赔给各个赢家的钱
+	bankerPay := make([]int64, len(players))
+	var allBankerPay int64
+	for index, onePlayer := range players {
+		if index == bankerIndex {
+			continue
+		}
+		playOdds := onePlayer.GetDoubleLinkedPlayOdds()
+		if playOdds <= 0 {
+			playOdds = 1
+		}
+		if hands[index].isBiggerThanBanker(bankerHands[index]) {
+			bankerPay[index] = baseScore * bankerOdds * playOdds * typeOdds[hands[index].pokerType]
+			allBankerPay += bankerPay[index]
+			continue
+		}
+		lose := baseScore * bankerOdds * playOdds * typeOdds[bankerHands[index].pokerType]
+		if lose > onePlayer.GetBalance() {
+			lose = onePlayer.GetBalance()
+		}
+		if lose < 0 {
+			lose = 0
+		}
+		winOrLose[index] -= lose
+		bankerWin += lose
+	}
+	bankerCanPay := players[bankerIndex].GetBalance() + bankerWin
+	if bankerCanPay < 0 {
+		bankerCanPay = 0
+	}
+	for index, payNum := range bankerPay {
+		if payNum <= 0 {
+			continue
+		}
+		if allBankerPay > bankerCanPay {
+			payNum = payNum * bankerCanPay / allBankerPay
+		}
+		winOrLose[index] += payNum
+		bankerWin -= payNum
+	}
+	winOrLose[bankerIndex] = bankerWin
+	return winOrLose
+}
+
+// getSystemScore 计算只看前两张牌时平台的收益（真实玩家输的钱），发牌血池控制时使用
+// 两张牌能炸牌的都按炸牌算（超时和断线时系统也会帮忙炸牌）
+func getSystemScore(players []*pb.RoomPlayerInfo, allPokers [][]*pb.Poker, bankerIndex int, bankerOdds int64, typeOdds map[pb.DoubleLinkedPokerType]int64, baseScore int64) int64 {
+	hands := make([]*doubleLinkedHand, len(players))
+	bankerHands := make([]*doubleLinkedHand, len(players))
+	for index, pokers := range allPokers {
+		hands[index] = newDoubleLinkedHand(pokers)
+	}
+	for index := range players {
+		bankerHands[index] = hands[bankerIndex]
+	}
+	var score int64
+	for index, winOrLose := range getSettleWinOrLose(players, hands, bankerHands, bankerIndex, bankerOdds, typeOdds, baseScore) {
+		if players[index].GetIsRobot() {
+			continue
+		}
+		score -= winOrLose
+	}
+	return score
+}
+
+// dealPokers 从一副洗好的牌中给每个玩家发两张牌
+// 返回值：与玩家一一对应的手牌，剩下的牌堆（捞牌时从这里发）
+func dealPokers(playerNum int) ([][]*pb.Poker, []*pb.Poker) {
+	cardHeap := common.GetShufflePokerHeap(1)
+	allPokers := make([][]*pb.Poker, playerNum)
+	for index := 0; index < playerNum; index++ {
+		allPokers[index] = append([]*pb.Poker{}, cardHeap[index*dealPokerNum:(index+1)*dealPokerNum]...)
+	}
+	return allPokers, cardHeap[playerNum*dealPokerNum:]
+}
+
+// dealByControl 根据血池状态发牌
+// 血池需要控制时多次重新发牌，选择只看前两张牌时平台收益最高（或最低）的一次
+// 返回值：与玩家一一对应的手牌，剩下的牌堆
+func dealByControl(players []*pb.RoomPlayerInfo, bankerIndex int, bankerOdds int64, bloodState pb.BloodSlotStatus, typeOdds map[pb.DoubleLinkedPokerType]int64, baseScore int64) ([][]*pb.Poker, []*pb.Poker) {
+	bestPokers, bestHeap := dealPokers(len(players))
+	if bankerIndex < 0 || (bloodState != pb.BloodSlotStatus_BloodSlotStatus_Win && bloodState != pb.BloodSlotStatus_BloodSlotStatus_Lose) {
+		return bestPokers, bestHeap
+	}
+	bestScore := getSystemScore(players, bestPokers, bankerIndex, bankerOdds, typeOdds, baseScore)
+	for try := 0; try < controlTryNum; try++ {
+		allPokers, cardHeap := dealPokers(len(players))
+		score := getSystemScore(players, allPokers, bankerIndex, bankerOdds, typeOdds, baseScore)
+		if (bloodState == pb.BloodSlotStatus_BloodSlotStatus_Win && score > bestScore) ||
+			(bloodState == pb.BloodSlotStatus_BloodSlotStatus_Lose && score < bestScore) {
+			bestScore = score
+			bestPokers = allPokers
+			bestHeap = cardHeap
+		}
+	}
+	return bestPokers, bestHeap
+}
+
+// drawPoker 从牌堆中摸一张牌给玩家
+func drawPoker(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) bool {
+	if len(roomInfo.GetPokerCardHeap()) == 0 || len(onePlayer.GetPokers()) >= maxPokerNum {
+		return false
+	}
+	onePlayer.Pokers = append(onePlayer.Pokers, roomInfo.PokerCardHeap[0])
+	roomInfo.PokerCardHeap = roomInfo.PokerCardHeap[1:]
+	return true
+}
+
+// updateHandInfo 根据玩家当前的手牌更新牌型、倍数和点数
+func updateHandInfo(onePlayer *pb.RoomPlayerInfo, typeOdds map[pb.DoubleLinkedPokerType]int64) *doubleLinkedHand {
+	hand := newDoubleLinkedHand(onePlayer.GetPokers())
+	onePlayer.DoubleLinkedPokerType = hand.pokerType
+	onePlayer.DoubleLinkedPokerOdds = uint32(typeOdds[hand.pokerType])
+	onePlayer.DoubleLinkedPokerNum = uint32(hand.pokerNum)
+	onePlayer.IsSlOrSl = getSlOrSl(hand.pokerType) != 0
+	return hand
+}
@@ -0,0 +1,157 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["DoubleLinkedZhuangLao"] = &DoubleLinkedZhuangLao{}
+}
+
+// DoubleLinkedZhuangLao 双连游戏的庄家捞牌组件，用于处理庄家比牌和捞牌阶段的逻辑
+type DoubleLinkedZhuangLao struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DoubleLinkedZhuangLao) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DoubleLinkedZhuangLao) Start() {
+	obj.Base.Start()
+}
+
+// Drive 双连庄家捞牌阶段的主驱动
+// 庄家可以先用两张牌和任意闲家比牌，再选择捞牌或者不捞，选择后和剩下的闲家一起比牌，然后进入结算
+// 庄家已经炸牌、断线或者时间到了还没选择时，系统帮庄家选择不捞
+func (obj *DoubleLinkedZhuangLao) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	banker := getBanker(request)
+	if banker == nil {
+		common.LogError("DoubleLinkedZhuangLao Drive banker not found", request.GetUuid(), request.GetDoubleLinkedMultipleuuid())
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	typeOdds, msgErr := getDoubleLinkedOdds(request)
+	if msgErr != nil {
+		return request, msgErr
+	}
+	if request.NextRoomState != pb.RoomState_RoomStateZhuangLao {
+		if nowTime < request.DoTime && banker.GetDoubleLinkedLao() == 0 {
+			return request, nil
+		}
+		if banker.GetDoubleLinkedLao() == 0 {
+			doLao(request, banker, laoTypeNotLao, typeOdds)
+		}
+		for _, onePlayer := range getPlayPlayers(request) {
+			if !isBanker(request, onePlayer) && !onePlayer.GetDoubleLinkedIsPoker() {
+				obj.comparePoker(request, banker, onePlayer)
+			}
+		}
+		request.CurRoomState = pb.RoomState_RoomStateSettle
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	zhuangLaoTime, msgErr := getStateTime(request, "ZhuangLaoTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	endTime := nowTime + zhuangLaoTime
+	// 庄家已经炸牌时不用等待
+	if banker.GetDoubleLinkedLao() != 0 {
+		endTime = nowTime
+	}
+	// 推送房间状态 捞牌<->庄家捞牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateLao,
+		AfterState:        pb.RoomState_RoomStateZhuangLao,
+		AfterStateEndTime: endTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+	if banker.GetDoubleLinkedLao() == 0 && !isOnline(banker) {
+		doLao(request, banker, laoTypeNotLao, typeOdds)
+	}
+	request.NextRoomState = pb.RoomState_RoomStateSettle
+	request.DoTime = endTime
+	return request, nil
+}
+
+// isAllCompared 游戏中的闲家是否都和庄家比过牌了
+func (obj *DoubleLinkedZhuangLao) isAllCompared(roomInfo *pb.RoomInfo) bool {
+	for _, onePlayer := range getPlayPlayers(roomInfo) {
+		if !isBanker(roomInfo, onePlayer) && !onePlayer.GetDoubleLinkedIsPoker() {
+			return false
+		}
+	}
+	return true
+}
+
+// comparePoker 庄家和一个闲家比牌并广播结果，记录比牌时庄家的牌张数，结算时按这时的牌计算
+func (obj *DoubleLinkedZhuangLao) comparePoker(roomInfo *pb.RoomInfo, banker *pb.RoomPlayerInfo, onePlayer *pb.RoomPlayerInfo) *pb.DoubleLinkedPokerReply {
+	onePlayer.DoubleLinkedIsPoker = true
+	onePlayer.DoubleLinkedIsPokerPlayer = uint32(len(banker.GetPokers()))
+	onePlayer.OutPokers = onePlayer.GetPokers()
+	bankerHand := getBankerHand(banker, onePlayer)
+	winUuid, lostUuid := banker.GetUuid(), onePlayer.GetUuid()
+	if getPlayerHand(onePlayer).isBiggerThanBanker(bankerHand) {
+		winUuid, lostUuid = lostUuid, winUuid
+	}
+	pokerReply := &pb.DoubleLinkedPokerReply{
+		RoomId:    roomInfo.GetUuid(),
+		Uuid:      onePlayer.GetUuid(),
+		Zuuid:     banker.GetUuid(),
+		Winuuid:   winUuid,
+		Lostuuid:  lostUuid,
+		ZPoker:    bankerHand.pokers,
+		XPoker:    onePlayer.GetPokers(),
+		IsBPoker:  obj.isAllCompared(roomInfo),
+		XPokerNum: int64(onePlayer.GetDoubleLinkedPokerNum()),
+	}
+	common.RoomBroadcast(roomInfo, pokerReply)
+	return pokerReply
+}
+
+// RequestPoker 庄家在捞牌之前用两张牌和一个闲家比牌，每个闲家只能比一次
+func (obj *DoubleLinkedZhuangLao) RequestPoker(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateZhuangLao || roomInfo.GetNextRoomState() == pb.RoomState_RoomStateZhuangLao {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.DoubleLinkedPokerRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("DoubleLinkedZhuangLao RequestPoker ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	banker := common.GetRoomPlayerInfo(roomInfo, uid)
+	if banker == nil {
+		common.LogError("DoubleLinkedZhuangLao RequestPoker player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	// 只有庄家在捞牌前可以主动比牌
+	if !isBanker(roomInfo, banker) || banker.GetDoubleLinkedLao() != 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, realRequest.GetUuid())
+	if playerInfo == nil || isBanker(roomInfo, playerInfo) || playerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+		common.LogError("DoubleLinkedZhuangLao RequestPoker compare player invalid", uid, realRequest.GetUuid())
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+	}
+	if playerInfo.GetDoubleLinkedIsPoker() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	pokerReply := obj.comparePoker(roomInfo, banker, playerInfo)
+	return packReply(roomInfo, pokerReply)
+}
@@ -0,0 +1,76 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["DragonTigerFightDeal"] = &DragonTigerFightDeal{}
+}
+
+// DragonTigerFightDeal 龙虎斗游戏组件，用于处理发牌阶段的逻辑
+type DragonTigerFightDeal struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DragonTigerFightDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DragonTigerFightDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 房间发牌状态的驱动逻辑
+func (obj *DragonTigerFightDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	//获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateDeal {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateBet
+		request.NextRoomState = pb.RoomState_RoomStateBet
+		request.DoTime = nowTime
+		return request, nil
+	}
+	dealTimeStr := common.GetRoomConfig(request, "DealTime")
+	dealTime, err := strconv.Atoi(dealTimeStr)
+	if err != nil {
+		common.LogError("DragonTigerFightDeal Drive dealTimeStr has err", dealTimeStr)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 洗牌，开牌在结算阶段根据下注情况从牌堆中取
+	request.PokerCardHeap = common.GetShufflePokerHeap(1)
+
+	//推送消息
+	roomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateLocation,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + int64(dealTime),
+	}
+	common.RoomBroadcast(request, roomState)
+	// 龙虎各发一张暗牌
+	pushPoker := &pb.PushDragonTigerPoker{
+		RoomId: request.GetUuid(),
+		DragonTigerPoker: []*pb.Poker{
+			{PokerNum: pb.PokerNum_PokerNumNone, PokerColor: pb.PokerColor_PokerColorNone},
+			{PokerNum: pb.PokerNum_PokerNumNone, PokerColor: pb.PokerColor_PokerColorNone},
+		},
+	}
+	common.RoomBroadcast(request, pushPoker)
+
+	//下个状态
+	request.NextRoomState = pb.RoomState_RoomStateBet
+	request.DoTime = nowTime + int64(dealTime)
+	return request, nil
+}
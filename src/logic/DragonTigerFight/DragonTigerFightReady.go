@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+)
+
+func init() {
+	common.AllComponentMap["DragonTigerFightReady"] = &DragonTigerFightReady{}
+}
+
+// DragonTigerFightReady 龙虎斗游戏的准备组件，用于处理准备阶段的逻辑
+type DragonTigerFightReady struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DragonTigerFightReady) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DragonTigerFightReady) Start() {
+	obj.Base.Start()
+	common.InitGameConfigTemp(common.DragonTigerFightGameConfigTemp, pb.GameType_DragonTigerFight)
+}
+
+// Drive 龙虎斗准备阶段的主驱动
+func (obj *DragonTigerFightReady) Drive(request *pb.RoomInfo, extraInfo *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	return common.HundredGameReadyDiver(request, func(roomInfo *pb.RoomInfo) *pb.ErrorMessage {
+		// 新回合的下注信息推送给房间所有人
+		pushReadyInit := &pb.PushDragonTigerReadyInit{
+			RoomId:            roomInfo.GetUuid(),
+			PlayerInfo:        roomInfo.GetPlayerInfo(),
+			DragonTigerAllBet: roomInfo.GetDragonTigerAllBet(),
+		}
+		common.RoomBroadcast(roomInfo, pushReadyInit)
+		return nil
+	})
+}
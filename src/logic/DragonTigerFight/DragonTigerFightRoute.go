@@ -0,0 +1,156 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+)
+
+func init() {
+	common.AllComponentMap["DragonTigerFightRoute"] = &DragonTigerFightRoute{}
+}
+
+// DragonTigerFightRoute 龙虎斗游戏的功能中转组件，其他服务通过这个组件中转龙虎斗协议到具体逻辑组件中
+type DragonTigerFightRoute struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *DragonTigerFightRoute) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *DragonTigerFightRoute) Start() {
+	obj.Base.Start()
+
+	// 需要的配置进行模版初始化
+	initGlobleConfigNameArr := []string{
+		"DragonTigerFightServerNum",
+	}
+	err := common.InitGlobleConfigTemp(initGlobleConfigNameArr)
+	if err != nil {
+		panic("DragonTigerFightRoute initGlobleConfigNameArr has err")
+	}
+}
+
+// Do 中转协议的具体逻辑(玩家的请求操作通过do路由到driver）
+func (obj *DragonTigerFightRoute) Do(request *pb.DragonTigerFightDoRequest, extroInfo *pb.MessageExtroInfo) (proto.Message, *pb.ErrorMessage) {
+	doType := request.GetDoType()
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("DragonTigerFightRoute Do uuid == nil")
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotLogin, "")
+	}
+	// 选择一个driver线路
+	originMessage := request.GetDoMessageContent()
+	serverNumConfig := common.Configer.GetGlobal("DragonTigerFightServerNum")
+	serverNumStr := serverNumConfig.GetValue()
+	serverNum, err := strconv.Atoi(serverNumStr)
+	if err != nil {
+		common.LogError("DragonTigerFightRoute Do Atoi(serverNumStr) has err", serverNumStr)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 获取玩家信息
+	loadPlayerRequest := &pb.LoadPlayerRequest{}
+	loadPlayerRequest.Uuid = uuid
+	loadPlayerReply := &pb.LoadPlayerReply{}
+	msgErr := common.Router.Call("PlayerInfo", "LoadPlayer", loadPlayerRequest, loadPlayerReply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	playerInfo := loadPlayerReply.GetPlayerInfo()
+
+	var requestMessage proto.Message
+	var replyMessage proto.Message
+	//玩家线路
+	var driverServerIndex string
+	//方法名
+	var methodName string
+	switch doType {
+	//进入房间--与其他逻辑不同
+	case pb.DragonTigerFightDoType_DragonTigerFight_JoinRoom:
+		requestMessage = &pb.GameJoinRoomRequest{}
+		replyMessage = &pb.GameJoinRoomReply{}
+
+		err := ptypes.UnmarshalAny(originMessage, requestMessage)
+		if err != nil {
+			common.LogError("DragonTigerFightRoute Do joinRoom ptypes.UnmarshalAny has err", doType, uuid, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		driverServerIndex, msgErr = common.GameJoinRoomJudge(playerInfo, serverNum, requestMessage.(*pb.GameJoinRoomRequest), pb.GameType_DragonTigerFight)
+		if msgErr != nil {
+			return nil, msgErr
+		}
+
+		methodName = "RequestJoinRoom"
+	//退出房间
+	case pb.DragonTigerFightDoType_DragonTigerFight_ExitRoom:
+		requestMessage = &pb.GameExitRoomRequest{}
+		replyMessage = &pb.GameExitRoomReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestExitRoom"
+	//玩家下注
+	case pb.DragonTigerFightDoType_DragonTigerFight_PlayerBet:
+		requestMessage = &pb.DragonTigerBetRequest{}
+		replyMessage = &pb.DragonTigerBetReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestPlayerBet"
+	//玩家上庄
+	case pb.DragonTigerFightDoType_DragonTigerFight_UpBanker:
+		requestMessage = &pb.DragonTigerUpBankerRequest{}
+		replyMessage = &pb.DragonTigerUpBankerReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestUpBanker"
+	//玩家下庄
+	case pb.DragonTigerFightDoType_DragonTigerFight_DownBanker:
+		requestMessage = &pb.DragonTigerDownBankerRequest{}
+		replyMessage = &pb.DragonTigerDownBankerReply{}
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+		methodName = "RequestDownBanker"
+
+	default:
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	err = ptypes.UnmarshalAny(originMessage, requestMessage)
+	if err != nil {
+		common.LogError("DragonTigerFightRoute Do request ptypes.UnmarshalAny has err", doType, uuid, err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	componentName := "DragonTigerFightDriver" + driverServerIndex
+	// common.Router.Call 都是路由到 driver,  driver 再分发给各个组件
+	msgErr = common.Router.Call(componentName, methodName, requestMessage, replyMessage, extroInfo)
+	if msgErr != nil {
+		common.LogError(msgErr)
+		return nil, msgErr
+	}
+	return replyMessage, nil
+}
+
+// SyncRoomPlayerInfo 同步房间玩家信息
+func (obj *DragonTigerFightRoute) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo, extroInfo *pb.MessageExtroInfo) (*pb.EmptyMessage, *pb.ErrorMessage) {
+	reply := &pb.EmptyMessage{}
+	componentName := "DragonTigerFightDriver" + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "SyncRoomPlayerInfo", request, reply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	return reply, nil
+}
+
+// DelRoom 刪除房間
+func (obj *DragonTigerFightRoute) DelRoom(request *pb.DelRoomByGameTypeRequest, extraInfo *pb.MessageExtroInfo) (*pb.DelRoomByGameTypeReply, *pb.ErrorMessage) {
+	reply := &pb.DelRoomByGameTypeReply{}
+	componentName := "DragonTigerFightDriver" + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "DelRoom", request, reply, extraInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
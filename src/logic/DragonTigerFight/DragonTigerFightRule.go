@@ -0,0 +1,243 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+)
+
+// 下注区域的数量（龙、虎、和）
+const betAreaNum = 3
+
+// 下注区域对应的下标
+const (
+	dragonIndex = int(pb.DragonTigerCardArea_AreaDragon) - 1
+	tigerIndex  = int(pb.DragonTigerCardArea_AreaTiger) - 1
+	drawIndex   = int(pb.DragonTigerCardArea_AreaDraw) - 1
+)
+
+// dragonTigerOdds 龙虎斗的赔率配置
+type dragonTigerOdds struct {
+	// 各区域赔率，下标为区域-1
+	areaOdds []int64
+	// 开和时龙、虎区域下注的退还比例 单位：%
+	drawReturnRatio int64
+}
+
+// getDragonTigerOdds 获取房间的赔率配置
+func getDragonTigerOdds(roomInfo *pb.RoomInfo) (*dragonTigerOdds, *pb.ErrorMessage) {
+	odds := &dragonTigerOdds{
+		areaOdds: make([]int64, betAreaNum),
+	}
+	oddsNames := []string{"OddsDragon", "OddsTiger", "OddsDraw"}
+	for index, oddsName := range oddsNames {
+		oddsStr := common.GetRoomConfig(roomInfo, oddsName)
+		oddsNum, err := strconv.ParseInt(oddsStr, 10, 64)
+		if err != nil || oddsNum <= 0 {
+			common.LogError("getDragonTigerOdds has err", oddsName, oddsStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		odds.areaOdds[index] = oddsNum
+	}
+	returnRatioStr := common.GetRoomConfig(roomInfo, "DrawReturnRatio")
+	returnRatio, err := strconv.ParseInt(returnRatioStr, 10, 64)
+	if err != nil || returnRatio < 0 || returnRatio > 100 {
+		common.LogError("getDragonTigerOdds DrawReturnRatio has err", returnRatioStr, err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	odds.drawReturnRatio = returnRatio
+	return odds, nil
+}
+
+// getWinner 比较龙虎两张牌的点数，K最大A最小，点数相同为和
+func getWinner(dragonPoker *pb.Poker, tigerPoker *pb.Poker) pb.DragonTigerType {
+	if dragonPoker.GetPokerNum() > tigerPoker.GetPokerNum() {
+		return pb.DragonTigerType_TypeDragon
+	}
+	if dragonPoker.GetPokerNum() < tigerPoker.GetPokerNum() {
+		return pb.DragonTigerType_TypeTiger
+	}
+	return pb.DragonTigerType_TypeDraw
+}
+
+// getAreaIndexByWinner 获取赢家对应的下注区域下标
+func getAreaIndexByWinner(winner pb.DragonTigerType) int {
+	switch winner {
+	case pb.DragonTigerType_TypeDragon:
+		return dragonIndex
+	case pb.DragonTigerType_TypeTiger:
+		return tigerIndex
+	}
+	return drawIndex
+}
+
+// getAreaResult 计算某个区域的下注在开奖结果下的返还金额（含本金）和盈利金额
+// 返回值：返还金额，盈利金额
+func getAreaResult(areaIndex int, bet int64, winner pb.DragonTigerType, odds *dragonTigerOdds) (int64, int64) {
+	if bet <= 0 {
+		return 0, 0
+	}
+	winIndex := getAreaIndexByWinner(winner)
+	if areaIndex == winIndex {
+		winBalance := bet * odds.areaOdds[areaIndex]
+		return bet + winBalance, winBalance
+	}
+	// 开和时龙、虎区域按比例退还下注
+	if winner == pb.DragonTigerType_TypeDraw {
+		return bet * odds.drawReturnRatio / 100, 0
+	}
+	return 0, 0
+}
+
+// getBetsNetWin 计算一组下注在开奖结果下的净输赢（未抽水）
+func getBetsNetWin(bets []int64, winner pb.DragonTigerType, odds *dragonTigerOdds) int64 {
+	var netWin int64
+	for areaIndex, bet := range bets {
+		if bet <= 0 {
+			continue
+		}
+		backBalance, _ := getAreaResult(areaIndex, bet, winner, odds)
+		netWin += backBalance - bet
+	}
+	return netWin
+}
+
+// getBankerMoney 获取当前庄家可用于赔付的金额
+func getBankerMoney(roomInfo *pb.RoomInfo) (int64, *pb.ErrorMessage) {
+	if roomInfo.GetBankerUuid() == "systemBanker" {
+		defaultMoneyStr := common.GetRoomConfig(roomInfo, "DefaultBankerMoney")
+		defaultMoney, err := strconv.ParseInt(defaultMoneyStr, 10, 64)
+		if err != nil {
+			common.LogError("getBankerMoney DefaultBankerMoney has err", defaultMoneyStr, err)
+			return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		return defaultMoney, nil
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo == nil {
+		return 0, nil
+	}
+	return bankerInfo.GetBalance(), nil
+}
+
+// refreshMaxBetRatio 根据庄家金额和房间总注刷新各区域的限红
+// 每个区域的限红是这个区域开奖时庄家还能赔付的下注金额
+func refreshMaxBetRatio(roomInfo *pb.RoomInfo, bankerMoney int64, odds *dragonTigerOdds) {
+	if len(roomInfo.DragonTigerAllBet) != betAreaNum {
+		roomInfo.DragonTigerAllBet = make([]int64, betAreaNum)
+	}
+	roomInfo.MaxBetRatio = make([]int64, betAreaNum)
+	winners := []pb.DragonTigerType{pb.DragonTigerType_TypeDragon, pb.DragonTigerType_TypeTiger, pb.DragonTigerType_TypeDraw}
+	for _, winner := range winners {
+		areaIndex := getAreaIndexByWinner(winner)
+		bankerLose := getBetsNetWin(roomInfo.DragonTigerAllBet, winner, odds)
+		maxBet := (bankerMoney - bankerLose) / odds.areaOdds[areaIndex]
+		if maxBet < 0 {
+			maxBet = 0
+		}
+		roomInfo.MaxBetRatio[areaIndex] = maxBet
+	}
+}
+
+// getMinChip 获取最小的筹码值
+func getMinChip(roomInfo *pb.RoomInfo) int64 {
+	var minChip int64
+	for _, chipStr := range strings.Split(common.GetRoomConfig(roomInfo, "Chips"), ",") {
+		chip, err := strconv.ParseInt(chipStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		if minChip == 0 || chip < minChip {
+			minChip = chip
+		}
+	}
+	return minChip
+}
+
+// getControlWinner 血池控制下选出平台收益最高（或最低）的开奖结果
+// 参数：bloodState 血池状态
+// 返回值：开奖结果，没有可控制的下注时返回TypeNone
+func getControlWinner(roomInfo *pb.RoomInfo, bloodState pb.BloodSlotStatus, odds *dragonTigerOdds) pb.DragonTigerType {
+	if bloodState != pb.BloodSlotStatus_BloodSlotStatus_Win && bloodState != pb.BloodSlotStatus_BloodSlotStatus_Lose {
+		return pb.DragonTigerType_TypeNone
+	}
+	bankerIsRobot := isBankerIsRobot(roomInfo)
+	winners := []pb.DragonTigerType{pb.DragonTigerType_TypeDragon, pb.DragonTigerType_TypeTiger, pb.DragonTigerType_TypeDraw}
+	// 各开奖结果下平台的收益（真实玩家输的钱）
+	systemScores := make([]int64, len(winners))
+	for index, winner := range winners {
+		var playerNetWin int64
+		var bankerNetWin int64
+		for _, onePlayer := range roomInfo.GetPlayerInfo() {
+			if onePlayer.GetUuid() == "" || onePlayer.GetUuid() == roomInfo.GetBankerUuid() {
+				continue
+			}
+			netWin := getBetsNetWin(onePlayer.GetPlayerBets(), winner, odds)
+			bankerNetWin -= netWin
+			if !onePlayer.GetIsRobot() {
+				playerNetWin += netWin
+			}
+		}
+		systemScores[index] = -playerNetWin
+		if !bankerIsRobot {
+			systemScores[index] -= bankerNetWin
+		}
+	}
+
+	bestScore := systemScores[0]
+	for _, score := range systemScores {
+		if (bloodState == pb.BloodSlotStatus_BloodSlotStatus_Win && score > bestScore) ||
+			(bloodState == pb.BloodSlotStatus_BloodSlotStatus_Lose && score < bestScore) {
+			bestScore = score
+		}
+	}
+	var bestWinners []pb.DragonTigerType
+	for index, score := range systemScores {
+		if score == bestScore {
+			bestWinners = append(bestWinners, winners[index])
+		}
+	}
+	// 所有结果收益相同，说明没有需要控制的下注
+	if len(bestWinners) == len(winners) {
+		return pb.DragonTigerType_TypeNone
+	}
+	return bestWinners[common.GetRandomNum(0, len(bestWinners)-1)]
+}
+
+// dealPokerByWinner 从牌堆中取出符合开奖结果的龙虎两张牌
+// 返回值：龙牌，虎牌，剩余牌堆
+func dealPokerByWinner(cardHeap []*pb.Poker, winner pb.DragonTigerType) (*pb.Poker, *pb.Poker, []*pb.Poker) {
+	for i := 0; i < len(cardHeap); i++ {
+		for j := 0; j < len(cardHeap); j++ {
+			if i == j || getWinner(cardHeap[i], cardHeap[j]) != winner {
+				continue
+			}
+			dragonPoker := cardHeap[i]
+			tigerPoker := cardHeap[j]
+			remainHeap := make([]*pb.Poker, 0, len(cardHeap)-2)
+			for index, onePoker := range cardHeap {
+				if index == i || index == j {
+					continue
+				}
+				remainHeap = append(remainHeap, onePoker)
+			}
+			return dragonPoker, tigerPoker, remainHeap
+		}
+	}
+	// 匹配不到相应结果时按顺序发牌
+	common.LogDebug("龙虎斗无法匹配相应开奖结果：", winner)
+	return cardHeap[0], cardHeap[1], cardHeap[2:]
+}
+
+// isBankerIsRobot 判断庄家是否是机器人
+func isBankerIsRobot(roomInfo *pb.RoomInfo) bool {
+	if roomInfo.GetBankerUuid() == "systemBanker" {
+		return true
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo == nil {
+		return true
+	}
+	return bankerInfo.GetIsRobot()
+}
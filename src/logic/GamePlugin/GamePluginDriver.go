@@ -0,0 +1,206 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// GamePluginDriver 注册游戏的房间管理组件，负责处理玩家请求操作
+// 组件名是 游戏名+"Driver"，由Init根据注册描述生成，是一个多线路组件
+type GamePluginDriver struct {
+	base.Base
+	plugin *common.GamePlugin
+	rm     *common.RoomManager
+}
+
+// LoadComponent 加载组件
+func (obj *GamePluginDriver) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	// 这是一个多线路组件
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *GamePluginDriver) Start() {
+	obj.Base.Start()
+
+	initGlobleConfigNameArr := []string{
+		obj.plugin.GetMaxRoomNumCfgName(),
+	}
+	// 开放了自建房的游戏还需要总局数和房卡数的配置
+	if obj.plugin.HasPlayerRoom() {
+		initGlobleConfigNameArr = append(initGlobleConfigNameArr, obj.plugin.GetPlayerRoomCfgNames()...)
+	}
+	err := common.InitGlobleConfigTemp(initGlobleConfigNameArr)
+	if err != nil {
+		panic(err)
+	}
+	//将模板参数保存到内存中
+	if obj.plugin.ConfigTemp != nil {
+		common.InitGameConfigTemp(obj.plugin.ConfigTemp, obj.plugin.GameType)
+	}
+
+	obj.rm = new(common.RoomManager)
+	tableName := common.GetRoomRedisName(obj.plugin.GameType, common.ServerIndex)
+	obj.rm.InitRoomManager(obj.plugin.GameType, tableName, obj.DriveRoom)
+	//从redis里恢复房间数据（会导致配置不更新, 要刷新房间需要清空redis）
+	err = obj.rm.ReLoadRooms()
+	if err != nil {
+		panic(err)
+	}
+	obj.rm.ReStartRooms()
+	common.LogDebug("❤" + obj.plugin.CnName + "服务初始化❤")
+}
+
+// DriveRoom 这个方法提供房间驱动的具体逻辑
+// (room公共驱动调用到这里，通过这个函数根据房间状态调用相应的房间逻辑)
+func (obj *GamePluginDriver) DriveRoom(roomInfo *pb.RoomInfo) *pb.RoomInfo {
+	extraInfo := &pb.MessageExtroInfo{}
+	afterRoomInfo := &pb.RoomInfo{}
+
+	// 已经解散的房间等待清除
+	if roomInfo.GetDead() {
+		return nil
+	}
+	// 自建房开始前等待房主开始，打完所有局数后总结算
+	if common.PlayerRoomWaitStart(roomInfo) {
+		return nil
+	}
+	if roomInfo.CurRoomState == pb.RoomState_RoomStateAllSettle {
+		return common.PlayerRoomAllSettle(roomInfo)
+	}
+
+	// 根据状态获取需要调用的组件名
+	beforeState := roomInfo.CurRoomState
+	componentName := obj.plugin.GetStateComponent(roomInfo.CurRoomState, obj.Base.Config)
+	if componentName == "" {
+		common.LogError(obj.plugin.GetName(), "DriveRoom get componentName has empty", roomInfo.CurRoomState)
+		return nil
+	}
+	msgErr := common.Router.Call(componentName, "Drive", roomInfo, afterRoomInfo, extraInfo)
+	if msgErr != nil {
+		common.LogError(obj.plugin.GetName(), "DriveRoom call ", componentName, " Drive has err", msgErr)
+		return afterRoomInfo
+	}
+	common.PlayerRoomAfterDrive(beforeState, afterRoomInfo)
+	return afterRoomInfo
+}
+
+// Do 玩家的请求操作，根据注册描述中DoType对应的操作分发给各个组件
+func (obj *GamePluginDriver) Do(request proto.Message, extroInfo *pb.MessageExtroInfo) (proto.Message, *pb.ErrorMessage) {
+	driverName := obj.plugin.GetDriverName()
+	doType, originMessage, err := common.GetGameDoRequestInfo(request)
+	if err != nil {
+		common.LogError(driverName, "Do GetGameDoRequestInfo has err", err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	oneDo, ok := obj.plugin.DoList[doType]
+	if !ok {
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	if oneDo.Kind == common.GamePluginDoReject {
+		return nil, common.GetGrpcErrorMessage(oneDo.RejectCode, "")
+	}
+	// 房主操作的请求内容有多种类型，按内容的实际类型解析
+	if oneDo.Kind == common.GamePluginDoRoomMaster {
+		dynamicMessage := &ptypes.DynamicAny{}
+		err = ptypes.UnmarshalAny(originMessage, dynamicMessage)
+		if err != nil {
+			common.LogError(driverName, "Do roomMaster ptypes.UnmarshalAny has err", doType, extroInfo.GetUserId(), err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidRequest, "")
+		}
+		msgErr := common.GameDriverPlayerRoomMasterDo(dynamicMessage.Message, obj.rm, extroInfo)
+		if msgErr != nil {
+			return nil, msgErr
+		}
+		return oneDo.NewReply(), nil
+	}
+	requestMessage := oneDo.NewRequest()
+	err = ptypes.UnmarshalAny(originMessage, requestMessage)
+	if err != nil {
+		common.LogError(driverName, "Do request ptypes.UnmarshalAny has err", doType, extroInfo.GetUserId(), err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	replyMessage := oneDo.NewReply()
+
+	switch oneDo.Kind {
+	case common.GamePluginDoJoinRoom:
+		joinRequest, ok := requestMessage.(*pb.GameJoinRoomRequest)
+		if !ok {
+			common.LogError(driverName, "Do joinRoom request is not GameJoinRoomRequest", doType)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		joinReply, msgErr := obj.joinRoom(joinRequest, extroInfo)
+		if msgErr != nil {
+			return nil, msgErr
+		}
+		return joinReply, nil
+	case common.GamePluginDoCreateRoom:
+		createRequest, ok := requestMessage.(*pb.GameCreateRoomRequest)
+		if !ok {
+			common.LogError(driverName, "Do createRoom request is not GameCreateRoomRequest", doType)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		createReply, msgErr := common.GameDriverCreatePlayerRoom(createRequest, obj.plugin.GetMaxRoomNumCfgName(), obj.rm, extroInfo)
+		if msgErr != nil {
+			return nil, msgErr
+		}
+		return createReply, nil
+	case common.GamePluginDoExitRoom:
+		msgErr := obj.exitRoom(requestMessage, replyMessage, extroInfo)
+		if msgErr != nil {
+			return replyMessage, msgErr
+		}
+		return replyMessage, nil
+	}
+
+	msgErr := common.GameDriverDo(oneDo.Component, oneDo.Method, requestMessage, replyMessage, obj.rm, extroInfo)
+	if msgErr != nil {
+		return replyMessage, msgErr
+	}
+	return replyMessage, nil
+}
+
+// joinRoom 加入房间逻辑
+func (obj *GamePluginDriver) joinRoom(request *pb.GameJoinRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameJoinRoomReply, *pb.ErrorMessage) {
+	reply := &pb.GameJoinRoomReply{}
+	roomInfo, msgErr := common.GameDriverJoinRoom(request, obj.plugin.GetMaxRoomNumCfgName(), obj.rm, extroInfo)
+	if msgErr != nil {
+		common.LogError(obj.plugin.GetDriverName(), "gameDriverJoinRoom get RoomInfo has error:", msgErr)
+		return nil, msgErr
+	}
+	reply.RoomInfo = roomInfo
+	return reply, msgErr
+}
+
+// exitRoom 退出房间逻辑
+// 对战场游戏中的玩家不能直接退出，这时交给注册描述中的ExitInGameComponent处理
+func (obj *GamePluginDriver) exitRoom(request proto.Message, reply proto.Message, extroInfo *pb.MessageExtroInfo) *pb.ErrorMessage {
+	msgErr := common.GameDriverExitRoom(obj.rm, extroInfo)
+	if msgErr == nil || msgErr.GetCode() != pb.ErrorCode_NotAllowExitRoom || obj.plugin.ExitInGameComponent == "" {
+		return msgErr
+	}
+	return common.GameDriverDo(obj.plugin.ExitInGameComponent, "RequestExitInGame", request, reply, obj.rm, extroInfo)
+}
+
+// SyncRoomPlayerInfo 同步玩家房间玩家信息
+func (obj *GamePluginDriver) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo, extroInfo *pb.MessageExtroInfo) (*pb.EmptyMessage, *pb.ErrorMessage) {
+	reply := &pb.EmptyMessage{}
+	msgErr := common.GameDriverSyncRoomPlayerInfo(request, obj.rm, extroInfo)
+	return reply, msgErr
+}
+
+// DelRoom 删除某房间
+func (obj *GamePluginDriver) DelRoom(request *pb.DelRoomByGameTypeRequest, extraInfo *pb.MessageExtroInfo) (*pb.DelRoomByGameTypeReply, *pb.ErrorMessage) {
+	reply := &pb.DelRoomByGameTypeReply{}
+	// 根据uuid删除房间
+	err := obj.rm.DeleteRoom(request.GetRoomUUID(), true)
+	if err != nil {
+		common.LogError(obj.plugin.GetDriverName(), "DelRoom has err:", request.GameType, err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return reply, nil
+}
@@ -0,0 +1,128 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+)
+
+// GamePluginRoute 注册游戏的功能中转组件，其他服务通过这个组件中转游戏协议到driver中
+// 组件名是 游戏名+"Route"，由Init根据注册描述生成
+type GamePluginRoute struct {
+	base.Base
+	plugin *common.GamePlugin
+}
+
+// LoadComponent 加载组件
+func (obj *GamePluginRoute) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *GamePluginRoute) Start() {
+	obj.Base.Start()
+
+	// 需要的配置进行模版初始化
+	initGlobleConfigNameArr := []string{
+		obj.plugin.GetServerNumCfgName(),
+	}
+	err := common.InitGlobleConfigTemp(initGlobleConfigNameArr)
+	if err != nil {
+		panic(obj.plugin.GetRouteName() + " initGlobleConfigNameArr has err")
+	}
+}
+
+// Do 中转协议的具体逻辑(玩家的请求操作通过do路由到driver）
+// 请求是各游戏自己的DoRequest，根据注册描述中DoType对应的操作选择线路，原样转发给driver的Do
+func (obj *GamePluginRoute) Do(request proto.Message, extroInfo *pb.MessageExtroInfo) (proto.Message, *pb.ErrorMessage) {
+	routeName := obj.plugin.GetRouteName()
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError(routeName, "Do uuid == nil")
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotLogin, "")
+	}
+	doType, originMessage, err := common.GetGameDoRequestInfo(request)
+	if err != nil {
+		common.LogError(routeName, "Do GetGameDoRequestInfo has err", uuid, err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	oneDo, ok := obj.plugin.DoList[doType]
+	if !ok {
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	if oneDo.Kind == common.GamePluginDoReject {
+		return nil, common.GetGrpcErrorMessage(oneDo.RejectCode, "")
+	}
+	// 选择一个driver线路
+	serverNumConfig := common.Configer.GetGlobal(obj.plugin.GetServerNumCfgName())
+	serverNumStr := serverNumConfig.GetValue()
+	serverNum, err := strconv.Atoi(serverNumStr)
+	if err != nil {
+		common.LogError(routeName, "Do Atoi(serverNumStr) has err", serverNumStr)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 获取玩家信息
+	loadPlayerRequest := &pb.LoadPlayerRequest{}
+	loadPlayerRequest.Uuid = uuid
+	loadPlayerReply := &pb.LoadPlayerReply{}
+	msgErr := common.Router.Call("PlayerInfo", "LoadPlayer", loadPlayerRequest, loadPlayerReply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	playerInfo := loadPlayerReply.GetPlayerInfo()
+
+	//玩家线路
+	var driverServerIndex string
+	switch oneDo.Kind {
+	//进入房间--与其他逻辑不同
+	case common.GamePluginDoJoinRoom:
+		joinRequest := &pb.GameJoinRoomRequest{}
+		err := ptypes.UnmarshalAny(originMessage, joinRequest)
+		if err != nil {
+			common.LogError(routeName, "Do joinRoom ptypes.UnmarshalAny has err", doType, uuid, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		driverServerIndex, msgErr = common.GameJoinRoomJudge(playerInfo, serverNum, joinRequest, obj.plugin.GameType)
+		if msgErr != nil {
+			return nil, msgErr
+		}
+	default:
+		driverServerIndex = common.GetDriverServerIndex(playerInfo, serverNum, true)
+	}
+
+	replyMessage := oneDo.NewReply()
+	componentName := obj.plugin.GetDriverName() + driverServerIndex
+	// common.Router.Call 都是路由到 driver,  driver 再分发给各个组件
+	msgErr = common.Router.Call(componentName, "Do", request, replyMessage, extroInfo)
+	if msgErr != nil {
+		common.LogError(msgErr)
+		return nil, msgErr
+	}
+	return replyMessage, nil
+}
+
+// SyncRoomPlayerInfo 同步房间玩家信息
+func (obj *GamePluginRoute) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo, extroInfo *pb.MessageExtroInfo) (*pb.EmptyMessage, *pb.ErrorMessage) {
+	reply := &pb.EmptyMessage{}
+	componentName := obj.plugin.GetDriverName() + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "SyncRoomPlayerInfo", request, reply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	return reply, nil
+}
+
+// DelRoom 刪除房間
+func (obj *GamePluginRoute) DelRoom(request *pb.DelRoomByGameTypeRequest, extraInfo *pb.MessageExtroInfo) (*pb.DelRoomByGameTypeReply, *pb.ErrorMessage) {
+	reply := &pb.DelRoomByGameTypeReply{}
+	componentName := obj.plugin.GetDriverName() + request.GetServerIndex()
+	msgErr := common.Router.Call(componentName, "DelRoom", request, reply, extraInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
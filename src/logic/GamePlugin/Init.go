@@ -0,0 +1,14 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+)
+
+// Init 用于方便包被外部引用的函数，同时在这里引用子包
+// 为每个通过common.RegisterGamePlugin注册的游戏生成route和driver组件
+func Init() {
+	for _, plugin := range common.GamePluginMap {
+		common.AllComponentMap[plugin.GetRouteName()] = &GamePluginRoute{plugin: plugin}
+		common.AllComponentMap[plugin.GetDriverName()] = &GamePluginDriver{plugin: plugin}
+	}
+}
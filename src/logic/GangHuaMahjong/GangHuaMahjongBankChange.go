@@ -0,0 +1,182 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	Mahjong "gameServer-demo/src/logic/Mahjong"
+	"github.com/golang/protobuf/ptypes"
+	"math/rand"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["GangHuaMahjongBankChange"] = &GangHuaMahjongBankChange{}
+}
+
+// GangHuaMahjongBankChange 杠花麻将游戏的定庄组件，用于处理定庄和庄家下庄的逻辑
+type GangHuaMahjongBankChange struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *GangHuaMahjongBankChange) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *GangHuaMahjongBankChange) Start() {
+	obj.Base.Start()
+}
+
+// Drive 杠花麻将定庄阶段的主驱动
+// 上一局的庄家还在游戏中就继续坐庄，否则随机一个庄家；连庄次数达到上限时强制下庄。
+// 连庄的庄家可以在定庄时间内选择是否下庄，没有选择的默认不下庄，第一次坐庄不用选择
+func (obj *GangHuaMahjongBankChange) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowTime := time.Now().Unix()
+	if request.GetNextRoomState() != pb.RoomState_RoomStateBankChange {
+		if nowTime < request.GetDoTime() {
+			return request, nil
+		}
+		banker := request.GetPlayerInfo()[request.GetBankerIndex()]
+		if banker.GetGhMahjongDownBankerRequest() == 0 {
+			obj.downBanker(request, stayBanker)
+		}
+		request.CurRoomState = request.GetNextRoomState()
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	bankChangeTime, msgErr := Mahjong.GetRoomConfigInt64(request, "BankChangeTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	maxBankerTimes, msgErr := Mahjong.GetRoomConfigInt64(request, "MaxBankerTimes")
+	if msgErr != nil {
+		return request, msgErr
+	}
+
+	// 推送房间状态 准备<->定庄
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateReady,
+		AfterState:        pb.RoomState_RoomStateBankChange,
+		AfterStateEndTime: nowTime + bankChangeTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	obj.chooseBanker(request)
+	banker := request.GetPlayerInfo()[request.GetBankerIndex()]
+	// 连庄次数达到上限强制下庄，MaxBankerTimes为0时不限制
+	if maxBankerTimes > 0 && int64(banker.GetGhMahjongBrankerTimes()) >= maxBankerTimes {
+		setBanker(request, getNextPlayIndex(request, int32(request.GetBankerIndex())))
+		banker = request.GetPlayerInfo()[request.GetBankerIndex()]
+	}
+
+	request.NextRoomState = pb.RoomState_RoomStateBet
+	request.DoTime = nowTime
+	if banker.GetGhMahjongBrankerTimes() > 0 {
+		waitTime, msgErr := getWaitTime(request, banker, "BankChangeTime")
+		if msgErr != nil {
+			return request, msgErr
+		}
+		request.DoTime = nowTime + waitTime
+	} else {
+		banker.GhMahjongDownBankerRequest = stayBanker
+	}
+	pushBankerTimes := &pb.PushGangHuaMahjongBankerTimes{
+		RoomId:      request.GetUuid(),
+		BankerTimes: banker.GetGhMahjongBrankerTimes(),
+		BankerIndex: int32(request.GetBankerIndex()),
+		DoTime:      request.GetDoTime(),
+	}
+	common.RoomBroadcast(request, pushBankerTimes)
+	return request, nil
+}
+
+// chooseBanker 定庄：上一局结算时定下的庄家还在游戏中就由他坐庄，否则随机一个庄家
+func (obj *GangHuaMahjongBankChange) chooseBanker(request *pb.RoomInfo) {
+	lastBanker := request.GetGhMahjongInRoom().GetLastBanker()
+	var playIndexes []int32
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if !Mahjong.IsPlaying(onePlayer) {
+			continue
+		}
+		if lastBanker != "" && onePlayer.GetUuid() == lastBanker {
+			request.BankerIndex = int64(index)
+			request.BankerUuid = lastBanker
+			return
+		}
+		playIndexes = append(playIndexes, int32(index))
+	}
+	setBanker(request, playIndexes[rand.Intn(len(playIndexes))])
+}
+
+// setBanker 换庄，新庄家从第一次坐庄开始计算连庄次数
+func setBanker(request *pb.RoomInfo, index int32) {
+	for _, onePlayer := range request.GetPlayerInfo() {
+		onePlayer.GhMahjongBrankerTimes = 0
+	}
+	request.BankerIndex = int64(index)
+	request.BankerUuid = request.GetPlayerInfo()[index].GetUuid()
+	request.GhMahjongInRoom.LastBanker = request.GetBankerUuid()
+}
+
+// downBanker 庄家选择是否下庄，下庄后由下家坐庄，并推送新的庄家
+func (obj *GangHuaMahjongBankChange) downBanker(request *pb.RoomInfo, downBankerType int32) {
+	bankerIndex := int32(request.GetBankerIndex())
+	request.GetPlayerInfo()[bankerIndex].GhMahjongDownBankerRequest = downBankerType
+	pushBetInfo := &pb.PushGangHuaMahjongBetInfo{
+		RoomId:     request.GetUuid(),
+		UserIndex:  bankerIndex,
+		DownBanker: downBankerType,
+	}
+	common.RoomBroadcast(request, pushBetInfo)
+	if downBankerType != downBanker {
+		return
+	}
+	setBanker(request, getNextPlayIndex(request, bankerIndex))
+	// 新庄家是第一次坐庄，不用再选择是否下庄
+	request.GetPlayerInfo()[request.GetBankerIndex()].GhMahjongDownBankerRequest = stayBanker
+	pushBankerTimes := &pb.PushGangHuaMahjongBankerTimes{
+		RoomId:      request.GetUuid(),
+		BankerIndex: int32(request.GetBankerIndex()),
+		DoTime:      request.GetDoTime(),
+	}
+	common.RoomBroadcast(request, pushBankerTimes)
+}
+
+// RequestXiaZhuang 连庄的庄家选择是否下庄，1下庄，-1不下庄
+func (obj *GangHuaMahjongBankChange) RequestXiaZhuang(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateBankChange || roomInfo.GetNextRoomState() == pb.RoomState_RoomStateBankChange {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.GangHuaMahjongXiaZhuangRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("GangHuaMahjongBankChange RequestXiaZhuang ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	index := Mahjong.GetPlayerIndex(roomInfo, uid)
+	if index < 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	if int64(index) != roomInfo.GetBankerIndex() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_GangHuaMahjongErrCodePlayerHaveNoPermissionsOpt, "")
+	}
+	if roomInfo.GetPlayerInfo()[index].GetGhMahjongDownBankerRequest() != 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	downBankerType := realRequest.GetDownBanker()
+	if downBankerType != downBanker && downBankerType != stayBanker {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidRequest, "")
+	}
+	obj.downBanker(roomInfo, downBankerType)
+	// 庄家选择完直接进入买点阶段
+	roomInfo.DoTime = time.Now().Unix()
+	return packReply(roomInfo, &pb.GangHuaMahjongXiaZhuangReply{DownBanker: downBankerType})
+}
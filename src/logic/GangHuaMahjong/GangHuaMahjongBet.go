@@ -0,0 +1,132 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	Mahjong "gameServer-demo/src/logic/Mahjong"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["GangHuaMahjongBet"] = &GangHuaMahjongBet{}
+}
+
+// GangHuaMahjongBet 杠花麻将游戏的买点组件，用于处理闲家向庄家买点的逻辑
+type GangHuaMahjongBet struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *GangHuaMahjongBet) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *GangHuaMahjongBet) Start() {
+	obj.Base.Start()
+}
+
+// Drive 杠花麻将买点阶段的主驱动
+// 闲家在买点时间内选择买1到MaxPoints点或者不买，买的点数在庄闲之间胡牌时加到倍数上；
+// 所有闲家都选择了或者时间到了进入发牌阶段，时间到了还没有选择的闲家默认不买
+func (obj *GangHuaMahjongBet) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowTime := time.Now().Unix()
+	if request.GetNextRoomState() != pb.RoomState_RoomStateBet {
+		if nowTime < request.GetDoTime() && !isAllBet(request) {
+			return request, nil
+		}
+		for index, onePlayer := range request.GetPlayerInfo() {
+			if Mahjong.IsPlaying(onePlayer) && int64(index) != request.GetBankerIndex() && onePlayer.GetGhMahjongPoints() == 0 {
+				buyPoint(request, int32(index), noBuyPoint)
+			}
+		}
+		request.CurRoomState = request.GetNextRoomState()
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	betTime, msgErr := Mahjong.GetRoomConfigInt64(request, "BetTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	// 推送房间状态 定庄<->买点
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateBankChange,
+		AfterState:        pb.RoomState_RoomStateBet,
+		AfterStateEndTime: nowTime + betTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	// 断线或者已经退出的闲家默认不买
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if Mahjong.IsPlaying(onePlayer) && int64(index) != request.GetBankerIndex() && Mahjong.IsAutoOperate(onePlayer) {
+			buyPoint(request, int32(index), noBuyPoint)
+		}
+	}
+	request.DoTime = nowTime + betTime
+	request.NextRoomState = pb.RoomState_RoomStateDeal
+	return request, nil
+}
+
+// isAllBet 是否所有闲家都已经选择了买点
+func isAllBet(request *pb.RoomInfo) bool {
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if Mahjong.IsPlaying(onePlayer) && int64(index) != request.GetBankerIndex() && onePlayer.GetGhMahjongPoints() == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// buyPoint 闲家买点并推送给房间所有人，points为-1时表示不买
+func buyPoint(request *pb.RoomInfo, index int32, points int32) {
+	request.GetPlayerInfo()[index].GhMahjongPoints = points
+	pushBetInfo := &pb.PushGangHuaMahjongBetInfo{
+		RoomId:     request.GetUuid(),
+		UserIndex:  index,
+		Points:     points,
+		IsBuyPoint: true,
+	}
+	common.RoomBroadcast(request, pushBetInfo)
+}
+
+// RequestMaiDian 闲家买点，买1到MaxPoints点，-1不买
+func (obj *GangHuaMahjongBet) RequestMaiDian(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateBet || roomInfo.GetNextRoomState() == pb.RoomState_RoomStateBet {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.GangHuaMahjongMaiDianRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("GangHuaMahjongBet RequestMaiDian ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	index := Mahjong.GetPlayerIndex(roomInfo, uid)
+	if index < 0 || !Mahjong.IsPlaying(roomInfo.GetPlayerInfo()[index]) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	// 庄家不能买点
+	if int64(index) == roomInfo.GetBankerIndex() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_GangHuaMahjongErrCodePlayerHaveNoPermissionsOpt, "")
+	}
+	if roomInfo.GetPlayerInfo()[index].GetGhMahjongPoints() != 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	maxPoints, msgErr := Mahjong.GetRoomConfigInt64(roomInfo, "MaxPoints")
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	points := realRequest.GetPoints()
+	if points != noBuyPoint && (points < 1 || int64(points) > maxPoints) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidRequest, "")
+	}
+	buyPoint(roomInfo, index, points)
+	return packReply(roomInfo, &pb.GangHuaMahjongMaiDianReply{Points: points})
+}
@@ -0,0 +1,115 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	Mahjong "gameServer-demo/src/logic/Mahjong"
+	"math/rand"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["GangHuaMahjongDeal"] = &GangHuaMahjongDeal{}
+}
+
+// GangHuaMahjongDeal 杠花麻将游戏的发牌组件，用于处理打骰子、发牌和留明牌的逻辑
+type GangHuaMahjongDeal struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *GangHuaMahjongDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *GangHuaMahjongDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 杠花麻将发牌阶段的主驱动
+// 洗牌后先留出牌堆最后4张作为明牌，两张一墩，每墩上面的一张亮出来；
+// 打骰子后每人发十三张牌，庄家多发一张，发牌时间到了以后开始打牌
+func (obj *GangHuaMahjongDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowTime := time.Now().Unix()
+	if request.GetNextRoomState() != pb.RoomState_RoomStateDeal {
+		if nowTime < request.GetDoTime() {
+			return request, nil
+		}
+		request.CurRoomState = request.GetNextRoomState()
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	dealTime, msgErr := Mahjong.GetRoomConfigInt64(request, "DealTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	// 推送房间状态 买点<->发牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateBet,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + dealTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	wall := Mahjong.GetShuffleMahjongWall(gangHuaColors)
+	ghInRoom := request.GetGhMahjongInRoom()
+	ghInRoom.GangHuaMahjongLast4Card = append([]*pb.Mahjong{}, wall[len(wall)-lastCardNum:]...)
+	ghInRoom.GhMahjongOpenCards = lastCardNum
+	ghInRoom.OpenCardS = []*pb.OpenCard{
+		{OpenLocal: 0, Card: ghInRoom.GetGangHuaMahjongLast4Card()[0]},
+		{OpenLocal: 2, Card: ghInRoom.GetGangHuaMahjongLast4Card()[2]},
+	}
+	wall = wall[:len(wall)-lastCardNum]
+
+	request.Dice = []int32{rand.Int31n(6) + 1, rand.Int31n(6) + 1}
+	inMahjongs := make([]*pb.Mahjong, len(request.GetPlayerInfo()))
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if !Mahjong.IsPlaying(onePlayer) {
+			continue
+		}
+		dealNum := handMahjongNum
+		if int64(index) == request.GetBankerIndex() {
+			dealNum++
+		}
+		hand := append([]*pb.Mahjong{}, wall[:dealNum]...)
+		wall = wall[dealNum:]
+		// 庄家的第十四张牌当作新摸的牌，放在手牌最后不参与排序
+		if dealNum > handMahjongNum {
+			inMahjongs[index] = hand[handMahjongNum]
+		}
+		Mahjong.SortMahjongs(hand[:handMahjongNum])
+		onePlayer.HandMahjongs = hand
+	}
+	request.GangHuaMahjongCardHeap = wall
+	ghInRoom.RemainCardNum = int32(len(wall))
+	ghInRoom.Running = true
+
+	// 每个玩家只能看到自己的手牌
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if !Mahjong.IsPlaying(onePlayer) {
+			continue
+		}
+		pushCardChange := &pb.PushPlayerGangHuaMahjongCardChange{
+			RoomId:        request.GetUuid(),
+			UserId:        onePlayer.GetUuid(),
+			BankerIndex:   int32(request.GetBankerIndex()),
+			HandMahjong:   onePlayer.GetHandMahjongs(),
+			InMahjong:     inMahjongs[index],
+			Dice:          request.GetDice(),
+			RemainCardNum: ghInRoom.GetRemainCardNum(),
+			OpenCardS:     ghInRoom.GetOpenCardS(),
+			RemainMahjong: ghInRoom.GetGhMahjongOpenCards(),
+			EndTime:       nowTime + dealTime,
+		}
+		common.Pusher.Push(pushCardChange, onePlayer.GetUuid())
+	}
+
+	request.NextRoomState = pb.RoomState_RoomStatePlay
+	request.DoTime = nowTime + dealTime
+	return request, nil
+}
@@ -0,0 +1,168 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+)
+
+func init() {
+	common.AllComponentMap["GangHuaMahjongDriver"] = &GangHuaMahjongDriver{}
+}
+
+// GangHuaMahjongDriver 杠花麻将游戏的房间管理组件，负责处理玩家请求操作
+type GangHuaMahjongDriver struct {
+	base.Base
+	rm *common.RoomManager
+}
+
+var maxRoomNumCfgName = "GangHuaMahjongMaxRoomNumOneServer"
+
+// LoadComponent 加载组件
+func (obj *GangHuaMahjongDriver) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	// 这是一个多线路组件
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *GangHuaMahjongDriver) Start() {
+	obj.Base.Start()
+
+	initGlobleConfigNameArr := []string{
+		maxRoomNumCfgName,
+	}
+	err := common.InitGlobleConfigTemp(initGlobleConfigNameArr)
+	if err != nil {
+		panic(err)
+	}
+	//将模板参数保存到内存中
+	common.InitGameConfigTemp(common.GangHuaMahjongGameConfigTemp, pb.GameType_GangHuaMahjong)
+
+	obj.rm = new(common.RoomManager)
+	tableName := common.GetRoomRedisName(pb.GameType_GangHuaMahjong, common.ServerIndex)
+	obj.rm.InitRoomManager(pb.GameType_GangHuaMahjong, tableName, obj.DriveRoom)
+	//从redis里恢复房间数据（会导致配置不更新, 要刷新房间需要清空redis）
+	err = obj.rm.ReLoadRooms()
+	if err != nil {
+		panic(err)
+	}
+	obj.rm.ReStartRooms()
+	common.LogDebug("❤杠花麻将服务初始化❤")
+}
+
+// DriveRoom 这个方法提供房间驱动的具体逻辑
+// (room公共驱动调用到这里，通过这个函数根据房间状态调用相应的房间逻辑)
+func (obj *GangHuaMahjongDriver) DriveRoom(roomInfo *pb.RoomInfo) *pb.RoomInfo {
+	extraInfo := &pb.MessageExtroInfo{}
+	afterRoomInfo := &pb.RoomInfo{}
+
+	// 根据状态获取需要调用的组件名
+	componentName := (*obj.Base.Config)[pb.RoomState_name[int32(roomInfo.CurRoomState)]]
+	if componentName == "" {
+		common.LogError("GangHuaMahjong DriveRoom get componentName has empty")
+		return nil
+	}
+	msgErr := common.Router.Call(componentName, "Drive", roomInfo, afterRoomInfo, extraInfo)
+	if msgErr != nil {
+		common.LogError("GangHuaMahjong DriveRoom call ", componentName, " Drive has err", msgErr)
+		return afterRoomInfo
+	}
+	return afterRoomInfo
+}
+
+// RequestJoinRoom 加入房间逻辑
+func (obj *GangHuaMahjongDriver) RequestJoinRoom(request *pb.GameJoinRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameJoinRoomReply, *pb.ErrorMessage) {
+	reply := &pb.GameJoinRoomReply{}
+	roomInfo, msgErr := common.GameDriverJoinRoom(request, maxRoomNumCfgName, obj.rm, extroInfo)
+	if msgErr != nil {
+		common.LogError("GangHuaMahjongDriver gameDriverJoinRoom get RoomInfo has error:", msgErr)
+		return nil, msgErr
+	}
+	reply.RoomInfo = roomInfo
+	return reply, msgErr
+}
+
+// RequestExitRoom 退出房间逻辑
+// 对战场游戏中的玩家不能直接退出，这时标记为等待踢出并由系统自动操作，本局结算后由房间的Kick踢出
+func (obj *GangHuaMahjongDriver) RequestExitRoom(request *pb.GameExitRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameExitRoomReply, *pb.ErrorMessage) {
+	reply := &pb.GameExitRoomReply{}
+	msgErr := common.GameDriverExitRoom(obj.rm, extroInfo)
+	if msgErr == nil || msgErr.GetCode() != pb.ErrorCode_NotAllowExitRoom {
+		return reply, msgErr
+	}
+	msgErr = common.GameDriverDo("GangHuaMahjongPlay", "RequestExitInGame", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestChangeState 玩家准备或取消准备逻辑
+func (obj *GangHuaMahjongDriver) RequestChangeState(request *pb.GameChangeStateRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameChangeStateReply, *pb.ErrorMessage) {
+	reply := &pb.GameChangeStateReply{}
+	msgErr := common.GameDriverDo("GangHuaMahjongReady", "RequestChangeState", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestOperate 玩家出牌、碰、杠、胡、过、摸明牌等操作逻辑
+func (obj *GangHuaMahjongDriver) RequestOperate(request *pb.GangHuaMahjongOperateRequest, extroInfo *pb.MessageExtroInfo) (*pb.GangHuaMahjongOperateReply, *pb.ErrorMessage) {
+	reply := &pb.GangHuaMahjongOperateReply{}
+	msgErr := common.GameDriverDo("GangHuaMahjongPlay", "RequestOperate", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestBeforeOperate 玩家出牌前查询能否暗杠、补杠、自摸
+func (obj *GangHuaMahjongDriver) RequestBeforeOperate(request *pb.MahjongBeforeOperateRequest, extroInfo *pb.MessageExtroInfo) (*pb.MahjongBeforeOperateReply, *pb.ErrorMessage) {
+	reply := &pb.MahjongBeforeOperateReply{}
+	msgErr := common.GameDriverDo("GangHuaMahjongPlay", "RequestBeforeOperate", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestXiaZhuang 连庄的庄家选择是否下庄
+func (obj *GangHuaMahjongDriver) RequestXiaZhuang(request *pb.GangHuaMahjongXiaZhuangRequest, extroInfo *pb.MessageExtroInfo) (*pb.GangHuaMahjongXiaZhuangReply, *pb.ErrorMessage) {
+	reply := &pb.GangHuaMahjongXiaZhuangReply{}
+	msgErr := common.GameDriverDo("GangHuaMahjongBankChange", "RequestXiaZhuang", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestMaiDian 闲家买点
+func (obj *GangHuaMahjongDriver) RequestMaiDian(request *pb.GangHuaMahjongMaiDianRequest, extroInfo *pb.MessageExtroInfo) (*pb.GangHuaMahjongMaiDianReply, *pb.ErrorMessage) {
+	reply := &pb.GangHuaMahjongMaiDianReply{}
+	msgErr := common.GameDriverDo("GangHuaMahjongBet", "RequestMaiDian", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// SyncRoomPlayerInfo 同步玩家房间玩家信息
+func (obj *GangHuaMahjongDriver) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo, extroInfo *pb.MessageExtroInfo) (*pb.EmptyMessage, *pb.ErrorMessage) {
+	reply := &pb.EmptyMessage{}
+	msgErr := common.GameDriverSyncRoomPlayerInfo(request, obj.rm, extroInfo)
+	return reply, msgErr
+}
+
+// DelRoom 删除某房间
+func (obj *GangHuaMahjongDriver) DelRoom(request *pb.DelRoomByGameTypeRequest, extraInfo *pb.MessageExtroInfo) (*pb.DelRoomByGameTypeReply, *pb.ErrorMessage) {
+	reply := &pb.DelRoomByGameTypeReply{}
+	// 根据uuid删除房间
+	err := obj.rm.DeleteRoom(request.GetRoomUUID(), true)
+	if err != nil {
+		common.LogError("GangHuaMahjongDriver DelRoom has err:", request.GameType, err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return reply, nil
+}
@@ -0,0 +1,664 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	Mahjong "gameServer-demo/src/logic/Mahjong"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["GangHuaMahjongPlay"] = &GangHuaMahjongPlay{}
+}
+
+// GangHuaMahjongPlay 杠花麻将游戏的玩耍组件，用于处理摸牌、出牌、碰杠胡和选明牌阶段的逻辑
+// 房间的GhMahjongInRoom中BeforeOutCardOpt不为空时轮到DoIndex的玩家出牌(或者暗杠、补杠、自摸)；
+// AfterOutCardOptes不为空时在等待其他玩家响应LastPlayerUuid打出的牌；
+// LastPushReqSelOpenCard不为空时在等待DoIndex的玩家杠牌后选一张明牌
+type GangHuaMahjongPlay struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *GangHuaMahjongPlay) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *GangHuaMahjongPlay) Start() {
+	obj.Base.Start()
+}
+
+// Drive 杠花麻将玩耍阶段的主驱动
+// 庄家先出牌，之后按座位顺序摸牌出牌；有人打出牌时，其他玩家按胡、杠、碰的优先级响应，
+// 一炮只有一个人胡，从出牌玩家的下家开始第一个能胡的玩家截胡；有人胡牌或者牌堆摸完时本局结束进入结算
+func (obj *GangHuaMahjongPlay) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowTime := time.Now().Unix()
+	if request.GetNextRoomState() == pb.RoomState_RoomStatePlay {
+		// 推送房间状态 发牌<->玩耍
+		pushRoomState := &pb.PushRoomStateChange{
+			RoomId:      request.GetUuid(),
+			BeforeState: pb.RoomState_RoomStateDeal,
+			AfterState:  pb.RoomState_RoomStatePlay,
+		}
+		common.RoomBroadcast(request, pushRoomState)
+
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		msgErr := obj.startTurn(request, int32(request.GetBankerIndex()), false, nowTime)
+		return request, msgErr
+	}
+
+	if nowTime < request.GetDoTime() {
+		return request, nil
+	}
+	// 操作超时由系统自动操作
+	ghInRoom := request.GetGhMahjongInRoom()
+	switch {
+	case ghInRoom.GetLastPushReqSelOpenCard() != nil:
+		msgErr := obj.selectOpenCard(request, request.GetDoIndex(), ghInRoom.GetOpenCardS()[0].GetCard(), nowTime)
+		return request, msgErr
+	case ghInRoom.GetAfterOutCardOptes() != nil:
+		for _, oneOpte := range ghInRoom.GetAfterOutCardOptes().GetOptes() {
+			if !oneOpte.GetOperated() {
+				obj.autoResponse(oneOpte)
+			}
+		}
+		msgErr := obj.resolve(request, nowTime)
+		return request, msgErr
+	default:
+		msgErr := obj.autoOperate(request, nowTime)
+		return request, msgErr
+	}
+}
+
+// startTurn 轮到座位index的玩家出牌，同时告诉他是否可以自摸、暗杠或者补杠，刚碰完牌的玩家只能出牌
+func (obj *GangHuaMahjongPlay) startTurn(request *pb.RoomInfo, index int32, isAfterPong bool, nowTime int64) *pb.ErrorMessage {
+	onePlayer := request.GetPlayerInfo()[index]
+	outputTime, msgErr := getWaitTime(request, onePlayer, "OutputTime")
+	if msgErr != nil {
+		return msgErr
+	}
+	ghInRoom := request.GetGhMahjongInRoom()
+	ghInRoom.BeforeOutCardOpt = &pb.PushPlayerGangHuaMahjongReqOutCard{
+		DoIndex:        index,
+		BeforeOptReply: getBeforeOperateReply(request, onePlayer, isAfterPong),
+		EndTime:        nowTime + outputTime,
+		RoomId:         request.GetUuid(),
+	}
+	ghInRoom.CurPlayerUuid = onePlayer.GetUuid()
+	request.DoIndex = index
+	request.DoTime = nowTime + outputTime
+
+	// 只有出牌的玩家能看到自己可以进行的操作
+	pushOthers := &pb.PushPlayerGangHuaMahjongReqOutCard{
+		DoIndex: index,
+		EndTime: request.GetDoTime(),
+		RoomId:  request.GetUuid(),
+	}
+	for _, otherPlayer := range request.GetPlayerInfo() {
+		if otherPlayer.GetUuid() == "" {
+			continue
+		}
+		if otherPlayer == onePlayer {
+			common.Pusher.Push(ghInRoom.GetBeforeOutCardOpt(), otherPlayer.GetUuid())
+			continue
+		}
+		common.Pusher.Push(pushOthers, otherPlayer.GetUuid())
+	}
+	return nil
+}
+
+// draw 座位index的玩家从牌堆摸一张牌，牌堆摸完时流局
+func (obj *GangHuaMahjongPlay) draw(request *pb.RoomInfo, index int32, nowTime int64) *pb.ErrorMessage {
+	if len(request.GetGangHuaMahjongCardHeap()) == 0 {
+		obj.deuce(request, nowTime)
+		return nil
+	}
+	onePlayer := request.GetPlayerInfo()[index]
+	onePlayer.GhSelOpenCard = false
+	onePlayer.GhSelOpenCardTimes = 0
+	onePlayer.GhMahjongJustKong = false
+	obj.takeFromHeap(request, index)
+	return obj.startTurn(request, index, false, nowTime)
+}
+
+// takeFromHeap 从牌堆摸一张牌放到手牌最后，摸到的牌只推送给自己
+func (obj *GangHuaMahjongPlay) takeFromHeap(request *pb.RoomInfo, index int32) {
+	onePlayer := request.GetPlayerInfo()[index]
+	newMahjong := request.GetGangHuaMahjongCardHeap()[0]
+	request.GangHuaMahjongCardHeap = request.GetGangHuaMahjongCardHeap()[1:]
+	onePlayer.HandMahjongs = append(onePlayer.GetHandMahjongs(), newMahjong)
+	request.GetGhMahjongInRoom().RemainCardNum = int32(len(request.GetGangHuaMahjongCardHeap()))
+	obj.pushSendCard(request, index, newMahjong, false)
+}
+
+// pushSendCard 推送玩家摸牌，isPublic为true时摸到的牌所有人都能看到(明牌)
+func (obj *GangHuaMahjongPlay) pushSendCard(request *pb.RoomInfo, index int32, newMahjong *pb.Mahjong, isPublic bool) {
+	for otherIndex, otherPlayer := range request.GetPlayerInfo() {
+		if otherPlayer.GetUuid() == "" {
+			continue
+		}
+		pushSendCard := &pb.PushPlayerGangHuaMahjongSendCard{
+			RoomId:        request.GetUuid(),
+			DoIndex:       index,
+			RemainCardNum: request.GetGhMahjongInRoom().GetRemainCardNum(),
+		}
+		if isPublic || int32(otherIndex) == index {
+			pushSendCard.NewMahjong = newMahjong
+		}
+		common.Pusher.Push(pushSendCard, otherPlayer.GetUuid())
+	}
+}
+
+// deuce 牌堆摸完了还没有人胡牌，本局流局
+func (obj *GangHuaMahjongPlay) deuce(request *pb.RoomInfo, nowTime int64) {
+	request.GetGhMahjongInRoom().GhDeuce = true
+	obj.gameOver(request, nowTime)
+}
+
+// gameOver 本局结束，进入结算
+func (obj *GangHuaMahjongPlay) gameOver(request *pb.RoomInfo, nowTime int64) {
+	ghInRoom := request.GetGhMahjongInRoom()
+	ghInRoom.Running = false
+	ghInRoom.GhMahjongGameOver = true
+	ghInRoom.BeforeOutCardOpt = nil
+	ghInRoom.AfterOutCardOptes = nil
+	ghInRoom.LastPushReqSelOpenCard = nil
+	request.CurRoomState = pb.RoomState_RoomStateSettle
+	request.NextRoomState = pb.RoomState_RoomStateSettle
+	request.DoTime = nowTime
+}
+
+// autoOperate 出牌超时由系统自动操作：能自摸就胡牌，否则打出手牌最后一张(刚摸到的牌)
+func (obj *GangHuaMahjongPlay) autoOperate(request *pb.RoomInfo, nowTime int64) *pb.ErrorMessage {
+	index := request.GetDoIndex()
+	hand := request.GetPlayerInfo()[index].GetHandMahjongs()
+	if request.GetGhMahjongInRoom().GetBeforeOutCardOpt().GetBeforeOptReply().GetCanWin() {
+		return obj.win(request, index, hand[len(hand)-1], true, nowTime)
+	}
+	return obj.discard(request, index, hand[len(hand)-1], nowTime)
+}
+
+// pushOperateReply 推送玩家的操作结果，手牌只推送给操作的玩家自己
+func (obj *GangHuaMahjongPlay) pushOperateReply(request *pb.RoomInfo, index int32, operateType pb.GangHuaMahjongOperateType, preIndex int32) {
+	onePlayer := request.GetPlayerInfo()[index]
+	for _, otherPlayer := range request.GetPlayerInfo() {
+		if otherPlayer.GetUuid() == "" {
+			continue
+		}
+		pushOperateReply := &pb.PushGangHuaMahjongOperateReply{
+			OperateType:     operateType,
+			DoIndex:         index,
+			OutMahjongs:     onePlayer.GetOutMahjongs(),
+			RoomId:          request.GetUuid(),
+			Tripletes:       onePlayer.GetTripletes(),
+			QuadrupletesAn:  onePlayer.GetQuadrupletesAn(),
+			QuadrupletesZhi: onePlayer.GetQuadrupletesZhi(),
+			QuadrupletesBu:  onePlayer.GetQuadrupletesBu(),
+			PreDoIndex:      preIndex,
+		}
+		if preIndex >= 0 {
+			pushOperateReply.PreOutMahjongs = request.GetPlayerInfo()[preIndex].GetOutMahjongs()
+		}
+		if otherPlayer == onePlayer {
+			pushOperateReply.HandMahjongs = onePlayer.GetHandMahjongs()
+		}
+		common.Pusher.Push(pushOperateReply, otherPlayer.GetUuid())
+	}
+}
+
+// discard 玩家出牌，其他玩家可以胡、杠、碰这张牌；没有人可以响应时下一个玩家摸牌
+func (obj *GangHuaMahjongPlay) discard(request *pb.RoomInfo, index int32, mahjong *pb.Mahjong, nowTime int64) *pb.ErrorMessage {
+	onePlayer := request.GetPlayerInfo()[index]
+	hand, ok := Mahjong.RemoveMahjong(onePlayer.GetHandMahjongs(), mahjong, 1)
+	if !ok {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_InValidCard, "")
+	}
+	Mahjong.SortMahjongs(hand)
+	onePlayer.HandMahjongs = hand
+	onePlayer.OutMahjongs = append(onePlayer.GetOutMahjongs(), mahjong)
+	ghInRoom := request.GetGhMahjongInRoom()
+	ghInRoom.LastPlayerUuid = onePlayer.GetUuid()
+	ghInRoom.BeforeOutCardOpt = nil
+	obj.pushOperateReply(request, index, pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Discard, -1)
+
+	operateTime, msgErr := Mahjong.GetRoomConfigInt64(request, "OperateTime")
+	if msgErr != nil {
+		return msgErr
+	}
+	optes := &pb.PushGangHuaMahjongOperates{
+		RoomId: request.GetUuid(),
+		CurIdx: index,
+	}
+	ghInRoom.GhCanWinIndex = -1
+	for otherIndex := getNextPlayIndex(request, index); otherIndex != index; otherIndex = getNextPlayIndex(request, otherIndex) {
+		otherPlayer := request.GetPlayerInfo()[otherIndex]
+		oneOpte := &pb.PushGangHuaMahjongOperate{
+			OperateType:    []pb.GangHuaMahjongOperateType{pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_DoNothing},
+			EndTime:        nowTime + operateTime,
+			AnotherMahjong: mahjong,
+			OptIndex:       otherIndex,
+		}
+		// 一炮只有一个人胡，第一个能胡的玩家截胡
+		if ghInRoom.GetGhCanWinIndex() < 0 && canPlayerWin(otherPlayer, mahjong) {
+			ghInRoom.GhCanWinIndex = int64(otherIndex)
+			oneOpte.CanWin = true
+			oneOpte.OperateType = append(oneOpte.GetOperateType(), pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Win)
+		}
+		if canKong(request) && Mahjong.CanKongOutput(otherPlayer.GetHandMahjongs(), mahjong) {
+			oneOpte.KongType = pb.GangHuaMahjongKongType_GangHuaMahjongKongType_Zhi
+			oneOpte.OperateType = append(oneOpte.GetOperateType(), pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Kong)
+		}
+		if Mahjong.CanPong(otherPlayer.GetHandMahjongs(), mahjong) {
+			oneOpte.OperateType = append(oneOpte.GetOperateType(), pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Pong)
+		}
+		if len(oneOpte.GetOperateType()) == 1 {
+			continue
+		}
+		optes.Optes = append(optes.GetOptes(), oneOpte)
+		optes.MultiOptIndex = append(optes.GetMultiOptIndex(), otherIndex)
+	}
+	if len(optes.GetOptes()) == 0 {
+		return obj.draw(request, getNextPlayIndex(request, index), nowTime)
+	}
+
+	ghInRoom.AfterOutCardOptes = optes
+	request.DoTime = nowTime + operateTime
+	// 每个玩家只能看到自己可以进行的操作，断线或者已经退出的玩家由系统直接响应
+	for _, oneOpte := range optes.GetOptes() {
+		otherPlayer := request.GetPlayerInfo()[oneOpte.GetOptIndex()]
+		if Mahjong.IsAutoOperate(otherPlayer) {
+			obj.autoResponse(oneOpte)
+			continue
+		}
+		pushOptes := &pb.PushGangHuaMahjongOperates{
+			RoomId:        request.GetUuid(),
+			Optes:         []*pb.PushGangHuaMahjongOperate{oneOpte},
+			MultiOptIndex: optes.GetMultiOptIndex(),
+			CurIdx:        index,
+		}
+		common.Pusher.Push(pushOptes, otherPlayer.GetUuid())
+	}
+	if obj.isAllResponded(request) {
+		return obj.resolve(request, nowTime)
+	}
+	return nil
+}
+
+// getOpte 获取玩家对最后打出的牌可以进行的操作，不能操作返回nil
+func (obj *GangHuaMahjongPlay) getOpte(request *pb.RoomInfo, index int32) *pb.PushGangHuaMahjongOperate {
+	for _, oneOpte := range request.GetGhMahjongInRoom().GetAfterOutCardOptes().GetOptes() {
+		if oneOpte.GetOptIndex() == index {
+			return oneOpte
+		}
+	}
+	return nil
+}
+
+// autoResponse 系统替玩家响应：能胡就胡，否则过牌
+func (obj *GangHuaMahjongPlay) autoResponse(oneOpte *pb.PushGangHuaMahjongOperate) {
+	oneOpte.Operated = true
+	oneOpte.SelectedOpt = pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_DoNothing
+	if oneOpte.GetCanWin() {
+		oneOpte.SelectedOpt = pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Win
+	}
+}
+
+// isAllResponded 可以响应的玩家是否都已经响应了，有人胡牌时不用再等其他人
+func (obj *GangHuaMahjongPlay) isAllResponded(request *pb.RoomInfo) bool {
+	for _, oneOpte := range request.GetGhMahjongInRoom().GetAfterOutCardOptes().GetOptes() {
+		if oneOpte.GetSelectedOpt() == pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Win {
+			return true
+		}
+		if !oneOpte.GetOperated() {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve 按胡、杠、碰的优先级处理所有玩家的响应，都过牌时出牌玩家的下家摸牌
+func (obj *GangHuaMahjongPlay) resolve(request *pb.RoomInfo, nowTime int64) *pb.ErrorMessage {
+	ghInRoom := request.GetGhMahjongInRoom()
+	optes := ghInRoom.GetAfterOutCardOptes()
+	ghInRoom.AfterOutCardOptes = nil
+	discardIndex := optes.GetCurIdx()
+	discardPlayer := request.GetPlayerInfo()[discardIndex]
+	mahjong := discardPlayer.GetOutMahjongs()[len(discardPlayer.GetOutMahjongs())-1]
+
+	var selected *pb.PushGangHuaMahjongOperate
+	priorities := map[pb.GangHuaMahjongOperateType]int{
+		pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Win:  3,
+		pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Kong: 2,
+		pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Pong: 1,
+	}
+	for _, oneOpte := range optes.GetOptes() {
+		if priorities[oneOpte.GetSelectedOpt()] == 0 {
+			continue
+		}
+		if selected == nil || priorities[oneOpte.GetSelectedOpt()] > priorities[selected.GetSelectedOpt()] {
+			selected = oneOpte
+		}
+	}
+	if selected == nil {
+		return obj.draw(request, getNextPlayIndex(request, discardIndex), nowTime)
+	}
+
+	index := selected.GetOptIndex()
+	pushOperateOver := &pb.PushGangHuaMahjongOperateOver{
+		RoomId:      request.GetUuid(),
+		UserId:      request.GetPlayerInfo()[index].GetUuid(),
+		ToUserId:    discardPlayer.GetUuid(),
+		OperateType: selected.GetSelectedOpt(),
+	}
+	common.RoomBroadcast(request, pushOperateOver)
+	switch selected.GetSelectedOpt() {
+	case pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Win:
+		return obj.win(request, index, mahjong, false, nowTime)
+	case pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Kong:
+		return obj.kong(request, index, pb.GangHuaMahjongKongType_GangHuaMahjongKongType_Zhi, mahjong, discardIndex, nowTime)
+	default:
+		return obj.pong(request, index, mahjong, discardIndex, nowTime)
+	}
+}
+
+// takeLastOutput 最后打出的牌被碰杠胡拿走了，从出牌玩家的出牌区去掉
+func (obj *GangHuaMahjongPlay) takeLastOutput(request *pb.RoomInfo, discardIndex int32) {
+	discardPlayer := request.GetPlayerInfo()[discardIndex]
+	discardPlayer.OutMahjongs = discardPlayer.GetOutMahjongs()[:len(discardPlayer.GetOutMahjongs())-1]
+}
+
+// pong 玩家碰牌，碰牌后轮到他出牌
+func (obj *GangHuaMahjongPlay) pong(request *pb.RoomInfo, index int32, mahjong *pb.Mahjong, discardIndex int32, nowTime int64) *pb.ErrorMessage {
+	onePlayer := request.GetPlayerInfo()[index]
+	hand, ok := Mahjong.RemoveMahjong(onePlayer.GetHandMahjongs(), mahjong, 2)
+	if !ok {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+	}
+	onePlayer.HandMahjongs = hand
+	onePlayer.Tripletes = append(onePlayer.GetTripletes(), mahjong, mahjong, mahjong)
+	onePlayer.GhSelOpenCard = false
+	onePlayer.GhSelOpenCardTimes = 0
+	onePlayer.GhMahjongJustKong = false
+	obj.takeLastOutput(request, discardIndex)
+	obj.pushOperateReply(request, index, pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Pong, discardIndex)
+	return obj.startTurn(request, index, true, nowTime)
+}
+
+// kong 玩家杠牌，直杠拿走别人打出的牌，补杠把手里的第四张牌加到碰牌上，暗杠亮出手里的四张牌
+// 杠牌后选一张明牌，明牌选完了从牌堆摸牌，都没有了流局
+func (obj *GangHuaMahjongPlay) kong(request *pb.RoomInfo, index int32, kongType pb.GangHuaMahjongKongType, mahjong *pb.Mahjong, discardIndex int32, nowTime int64) *pb.ErrorMessage {
+	onePlayer := request.GetPlayerInfo()[index]
+	// 直杠要用手里的三张，补杠用一张，暗杠用四张
+	handNum := map[pb.GangHuaMahjongKongType]int{
+		pb.GangHuaMahjongKongType_GangHuaMahjongKongType_Zhi: 3,
+		pb.GangHuaMahjongKongType_GangHuaMahjongKongType_Bu:  1,
+		pb.GangHuaMahjongKongType_GangHuaMahjongKongType_An:  4,
+	}[kongType]
+	hand, ok := Mahjong.RemoveMahjong(onePlayer.GetHandMahjongs(), mahjong, handNum)
+	if !ok {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+	}
+	onePlayer.HandMahjongs = hand
+	kongMahjongs := []*pb.Mahjong{mahjong, mahjong, mahjong, mahjong}
+	switch kongType {
+	case pb.GangHuaMahjongKongType_GangHuaMahjongKongType_Zhi:
+		onePlayer.QuadrupletesZhi = append(onePlayer.GetQuadrupletesZhi(), kongMahjongs...)
+		obj.takeLastOutput(request, discardIndex)
+	case pb.GangHuaMahjongKongType_GangHuaMahjongKongType_Bu:
+		onePlayer.Tripletes, _ = Mahjong.RemoveMahjong(onePlayer.GetTripletes(), mahjong, 3)
+		onePlayer.QuadrupletesBu = append(onePlayer.GetQuadrupletesBu(), kongMahjongs...)
+	default:
+		onePlayer.QuadrupletesAn = append(onePlayer.GetQuadrupletesAn(), kongMahjongs...)
+	}
+	Mahjong.SortMahjongs(onePlayer.GetHandMahjongs())
+	onePlayer.GhMahjongJustKong = true
+	request.GetGhMahjongInRoom().BeforeOutCardOpt = nil
+	obj.pushOperateReply(request, index, pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Kong, discardIndex)
+	return obj.kongDraw(request, index, nowTime)
+}
+
+// kongDraw 杠牌后摸牌：还有明牌时等待玩家选一张明牌，明牌选完了从牌堆摸牌，牌堆也摸完了流局
+func (obj *GangHuaMahjongPlay) kongDraw(request *pb.RoomInfo, index int32, nowTime int64) *pb.ErrorMessage {
+	ghInRoom := request.GetGhMahjongInRoom()
+	onePlayer := request.GetPlayerInfo()[index]
+	if ghInRoom.GetGhMahjongOpenCards() > 0 {
+		operateTime, msgErr := getWaitTime(request, onePlayer, "OperateTime")
+		if msgErr != nil {
+			return msgErr
+		}
+		ghInRoom.LastPushReqSelOpenCard = &pb.PushGangHuaMahjongReqSelectOpenCard{
+			RoomId:  request.GetUuid(),
+			DoIndex: index,
+		}
+		ghInRoom.CurPlayerUuid = onePlayer.GetUuid()
+		request.DoIndex = index
+		request.DoTime = nowTime + operateTime
+		common.RoomBroadcast(request, ghInRoom.GetLastPushReqSelOpenCard())
+		return nil
+	}
+	if len(request.GetGangHuaMahjongCardHeap()) == 0 {
+		obj.deuce(request, nowTime)
+		return nil
+	}
+	onePlayer.GhSelOpenCard = true
+	onePlayer.GhSelOpenCardTimes++
+	obj.takeFromHeap(request, index)
+	return obj.startTurn(request, index, false, nowTime)
+}
+
+// selectOpenCard 玩家杠牌后选一张明牌，拿走的明牌下面那张再亮出来
+func (obj *GangHuaMahjongPlay) selectOpenCard(request *pb.RoomInfo, index int32, mahjong *pb.Mahjong, nowTime int64) *pb.ErrorMessage {
+	ghInRoom := request.GetGhMahjongInRoom()
+	selectedIndex := -1
+	for i, oneOpenCard := range ghInRoom.GetOpenCardS() {
+		if Mahjong.IsSameMahjong(oneOpenCard.GetCard(), mahjong) {
+			selectedIndex = i
+			break
+		}
+	}
+	if selectedIndex < 0 {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_InValidCard, "")
+	}
+	openLocal := ghInRoom.GetOpenCardS()[selectedIndex].GetOpenLocal()
+	openCards := append([]*pb.OpenCard{}, ghInRoom.GetOpenCardS()[:selectedIndex]...)
+	openCards = append(openCards, ghInRoom.GetOpenCardS()[selectedIndex+1:]...)
+	// 每墩上面的牌位置是偶数，拿走后亮出下面那张
+	if openLocal%2 == 0 {
+		openCards = append(openCards, &pb.OpenCard{
+			OpenLocal: openLocal + 1,
+			Card:      ghInRoom.GetGangHuaMahjongLast4Card()[openLocal+1],
+		})
+	}
+	ghInRoom.OpenCardS = openCards
+	ghInRoom.GhMahjongOpenCards--
+	ghInRoom.LastPushReqSelOpenCard = nil
+	pushOpenCards := &pb.PushGangHuaMahjongOpenCards{
+		RoomId:    request.GetUuid(),
+		OpenCardS: ghInRoom.GetOpenCardS(),
+	}
+	common.RoomBroadcast(request, pushOpenCards)
+
+	onePlayer := request.GetPlayerInfo()[index]
+	onePlayer.HandMahjongs = append(onePlayer.GetHandMahjongs(), mahjong)
+	onePlayer.GhSelOpenCard = true
+	onePlayer.GhSelOpenCardTimes++
+	obj.pushSendCard(request, index, mahjong, true)
+	return obj.startTurn(request, index, false, nowTime)
+}
+
+// win 玩家胡牌，本局结束，mahjong为胡的牌，自摸时是手牌最后一张
+func (obj *GangHuaMahjongPlay) win(request *pb.RoomInfo, index int32, mahjong *pb.Mahjong, isOwnDraw bool, nowTime int64) *pb.ErrorMessage {
+	onePlayer := request.GetPlayerInfo()[index]
+	winReply := getWinReply(request, index, mahjong, isOwnDraw)
+	if !isOwnDraw {
+		discardIndex := Mahjong.GetPlayerIndex(request, request.GetGhMahjongInRoom().GetLastPlayerUuid())
+		obj.takeLastOutput(request, discardIndex)
+		onePlayer.HandMahjongs = append(onePlayer.GetHandMahjongs(), mahjong)
+	}
+	onePlayer.GhMahjongWinReply = winReply
+	ghInRoom := request.GetGhMahjongInRoom()
+	ghInRoom.MahjongWinReply = winReply
+	ghInRoom.GhWinName = getWinName(winReply)
+	ghInRoom.GhWinByOwnDraw = isOwnDraw
+	ghInRoom.GhCanWinIndex = int64(index)
+	request.LastWinnerIndex = index
+	obj.pushOperateReply(request, index, pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Win, -1)
+	obj.gameOver(request, nowTime)
+	return nil
+}
+
+// RequestOperate 玩家出牌、碰、杠、胡、过和选明牌
+func (obj *GangHuaMahjongPlay) RequestOperate(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.GangHuaMahjongOperateRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("GangHuaMahjongPlay RequestOperate ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	index := Mahjong.GetPlayerIndex(roomInfo, uid)
+	if index < 0 || !Mahjong.IsPlaying(roomInfo.GetPlayerInfo()[index]) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_GangHuaMahjongErrCodePlayerHaveNoPermissionsOpt, "")
+	}
+	nowTime := time.Now().Unix()
+	ghInRoom := roomInfo.GetGhMahjongInRoom()
+	var msgErr *pb.ErrorMessage
+	switch {
+	case ghInRoom.GetLastPushReqSelOpenCard() != nil:
+		if index != roomInfo.GetDoIndex() || realRequest.GetOperateType() != pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_SelectOpenCard {
+			return reply, common.GetGrpcErrorMessage(pb.ErrorCode_GangHuaMahjongErrCodePlayerHaveNoPermissionsOpt, "")
+		}
+		msgErr = obj.selectOpenCard(roomInfo, index, realRequest.GetMahjong(), nowTime)
+	case ghInRoom.GetAfterOutCardOptes() != nil:
+		msgErr = obj.doResponseOperate(roomInfo, index, realRequest, nowTime)
+	default:
+		if index != roomInfo.GetDoIndex() {
+			return reply, common.GetGrpcErrorMessage(pb.ErrorCode_GangHuaMahjongErrCodePlayerHaveNoPermissionsOpt, "")
+		}
+		msgErr = obj.doTurnOperate(roomInfo, index, realRequest, nowTime)
+	}
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return packReply(roomInfo, &pb.GangHuaMahjongOperateReply{})
+}
+
+// doTurnOperate 轮到自己时的操作：出牌、暗杠、补杠、自摸
+func (obj *GangHuaMahjongPlay) doTurnOperate(request *pb.RoomInfo, index int32, realRequest *pb.GangHuaMahjongOperateRequest, nowTime int64) *pb.ErrorMessage {
+	beforeOptReply := request.GetGhMahjongInRoom().GetBeforeOutCardOpt().GetBeforeOptReply()
+	mahjong := realRequest.GetMahjong()
+	switch realRequest.GetOperateType() {
+	case pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Discard:
+		return obj.discard(request, index, mahjong, nowTime)
+	case pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Kong:
+		for _, anKongMahjong := range beforeOptReply.GetMahjongsKongAn() {
+			if Mahjong.IsSameMahjong(anKongMahjong, mahjong) {
+				return obj.kong(request, index, pb.GangHuaMahjongKongType_GangHuaMahjongKongType_An, mahjong, -1, nowTime)
+			}
+		}
+		if beforeOptReply.GetMahjongKongBu() != nil && Mahjong.IsSameMahjong(beforeOptReply.GetMahjongKongBu(), mahjong) {
+			return obj.kong(request, index, pb.GangHuaMahjongKongType_GangHuaMahjongKongType_Bu, mahjong, -1, nowTime)
+		}
+	case pb.GangHuaMahjongOperateType_GangHuaMahjongOperateType_Win:
+		if beforeOptReply.GetCanWin() {
+			hand := request.GetPlayerInfo()[index].GetHandMahjongs()
+			return obj.win(request, index, hand[len(hand)-1], true, nowTime)
+		}
+	}
+	return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+}
+
+// doResponseOperate 响应别人打出的牌：胡、杠、碰、过
+func (obj *GangHuaMahjongPlay) doResponseOperate(request *pb.RoomInfo, index int32, realRequest *pb.GangHuaMahjongOperateRequest, nowTime int64) *pb.ErrorMessage {
+	oneOpte := obj.getOpte(request, index)
+	if oneOpte == nil {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_GangHuaMahjongErrCodePlayerHaveNoPermissionsOpt, "")
+	}
+	if oneOpte.GetOperated() {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	canOperate := false
+	for _, operateType := range oneOpte.GetOperateType() {
+		if operateType == realRequest.GetOperateType() {
+			canOperate = true
+			break
+		}
+	}
+	if !canOperate {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+	}
+	oneOpte.Operated = true
+	oneOpte.SelectedOpt = realRequest.GetOperateType()
+	if obj.isAllResponded(request) {
+		return obj.resolve(request, nowTime)
+	}
+	return nil
+}
+
+// RequestBeforeOperate 玩家查询出牌前可以进行的操作，没有轮到自己时返回空
+func (obj *GangHuaMahjongPlay) RequestBeforeOperate(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	index := Mahjong.GetPlayerIndex(roomInfo, uid)
+	if index < 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	beforeOutCardOpt := roomInfo.GetGhMahjongInRoom().GetBeforeOutCardOpt()
+	beforeOptReply := &pb.MahjongBeforeOperateReply{}
+	if beforeOutCardOpt != nil && beforeOutCardOpt.GetDoIndex() == index {
+		beforeOptReply = beforeOutCardOpt.GetBeforeOptReply()
+	}
+	return packReply(roomInfo, beforeOptReply)
+}
+
+// RequestExitInGame 游戏中的玩家退出，标记为等待踢出并由系统自动操作
+func (obj *GangHuaMahjongPlay) RequestExitInGame(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	index := Mahjong.GetPlayerIndex(roomInfo, uid)
+	if index < 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	playerInfo := roomInfo.GetPlayerInfo()[index]
+	playerInfo.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_Exit
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStatePlay && roomInfo.GetNextRoomState() == pb.RoomState_RoomStateSettle && Mahjong.IsPlaying(playerInfo) {
+		nowTime := time.Now().Unix()
+		ghInRoom := roomInfo.GetGhMahjongInRoom()
+		if ghInRoom.GetAfterOutCardOptes() == nil && index == roomInfo.GetDoIndex() {
+			// 轮到自己时退出，缩短等待时间尽快自动操作
+			autoTime, msgErr := Mahjong.GetRoomConfigInt64(roomInfo, "AutoOperateTime")
+			if msgErr != nil {
+				return reply, msgErr
+			}
+			if nowTime+autoTime < roomInfo.GetDoTime() {
+				roomInfo.DoTime = nowTime + autoTime
+			}
+		} else if oneOpte := obj.getOpte(roomInfo, index); oneOpte != nil && !oneOpte.GetOperated() {
+			obj.autoResponse(oneOpte)
+			if obj.isAllResponded(roomInfo) {
+				if msgErr := obj.resolve(roomInfo, nowTime); msgErr != nil {
+					return reply, msgErr
+				}
+			}
+		}
+	}
+	// 结算阶段本局已经结算完了，可以直接踢出
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStateSettle && roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+	}
+	return packReply(roomInfo, &pb.GameExitRoomReply{})
+}
@@ -0,0 +1,302 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	Mahjong "gameServer-demo/src/logic/Mahjong"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strings"
+)
+
+// 杠花麻将：只有筒条万108张牌，可以碰杠不能吃，一炮只有一个人胡(截胡)，有人胡牌本局就结束。
+// 牌堆最后4张不参与摸牌，分成两墩，每墩上面的一张亮出来作为明牌；杠牌后不摸牌，而是选一张明牌，
+// 拿走的明牌下面那张再亮出来。摸明牌后自摸就是杠上花，连续杠牌再摸明牌自摸是两杠上花、三杠上花。
+// 开局前连庄的庄家可以选择下庄，闲家可以向庄家买点，买的点数加在庄闲之间的胡牌分数上
+
+const (
+	// handMahjongNum 每个玩家发牌的张数，庄家多发一张
+	handMahjongNum = 13
+	// lastCardNum 牌堆最后留作明牌的张数，两张一墩
+	lastCardNum = 4
+	// noBuyPoint 闲家不买点
+	noBuyPoint = -1
+	// downBanker 庄家下庄
+	downBanker = 1
+	// stayBanker 庄家不下庄
+	stayBanker = -1
+)
+
+// gangHuaColors 杠花麻将牌堆的花色
+var gangHuaColors = []pb.MahjongColor{
+	pb.MahjongColor_MahjongColorDot,
+	pb.MahjongColor_MahjongColorBamboo,
+	pb.MahjongColor_MahjongColorCharacter,
+}
+
+// winTypeNames 胡牌类型的名字，用于拼出胡牌的名字
+var winTypeNames = map[pb.GangHuaMahjongWinType]string{
+	pb.GangHuaMahjongWinType_normalWin:                  "平胡",
+	pb.GangHuaMahjongWinType_WinningWithAllPairedTiles:  "对对胡",
+	pb.GangHuaMahjongWinType_SevenPairs:                 "小七对",
+	pb.GangHuaMahjongWinType_SevenPairsOfDragonClawBack: "七对龙爪背",
+}
+
+// winKindNames 胡牌番种的名字，用于拼出胡牌的名字
+var winKindNames = map[pb.GangHuaMahjongWinKind]string{
+	pb.GangHuaMahjongWinKind_NaturalWin:     "天胡",
+	pb.GangHuaMahjongWinKind_EarthlyHand:    "地胡",
+	pb.GangHuaMahjongWinKind_AfterAGang:     "杠上花",
+	pb.GangHuaMahjongWinKind_AfterTwoGang:   "两杠上花",
+	pb.GangHuaMahjongWinKind_AfterThreeGang: "三杠上花",
+	pb.GangHuaMahjongWinKind_UniqueKaZhang:  "绝卡张",
+	pb.GangHuaMahjongWinKind_AllOfOneSuit:   "清一色",
+}
+
+// packReply 封装回复给driver的房间信息和回复消息
+func packReply(roomInfo *pb.RoomInfo, realReply proto.Message) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	realReplyAny, err := ptypes.MarshalAny(realReply)
+	if err != nil {
+		common.LogError("GangHuaMahjong packReply MarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	reply.RoomInfo = roomInfo
+	reply.Message = realReplyAny
+	return reply, nil
+}
+
+// getPlayPlayers 获取本局参与游戏的玩家
+func getPlayPlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if Mahjong.IsPlaying(onePlayer) {
+			players = append(players, onePlayer)
+		}
+	}
+	return players
+}
+
+// getNextPlayIndex 获取座位index之后下一个游戏中的玩家的座位下标
+func getNextPlayIndex(roomInfo *pb.RoomInfo, index int32) int32 {
+	seatNum := int32(len(roomInfo.GetPlayerInfo()))
+	for step := int32(1); step <= seatNum; step++ {
+		nextIndex := (index + step) % seatNum
+		if Mahjong.IsPlaying(roomInfo.GetPlayerInfo()[nextIndex]) {
+			return nextIndex
+		}
+	}
+	return index
+}
+
+// getWaitTime 获取玩家操作的等待时长，断线或者已经退出的玩家等待AutoOperateTime后自动操作
+func getWaitTime(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, timeName string) (int64, *pb.ErrorMessage) {
+	if Mahjong.IsAutoOperate(onePlayer) {
+		timeName = "AutoOperateTime"
+	}
+	return Mahjong.GetRoomConfigInt64(roomInfo, timeName)
+}
+
+// getMeldMahjongs 获取玩家碰牌区和杠牌区所有的牌
+func getMeldMahjongs(onePlayer *pb.RoomPlayerInfo) []*pb.Mahjong {
+	var meldMahjongs []*pb.Mahjong
+	meldMahjongs = append(meldMahjongs, onePlayer.GetTripletes()...)
+	meldMahjongs = append(meldMahjongs, onePlayer.GetQuadrupletesZhi()...)
+	meldMahjongs = append(meldMahjongs, onePlayer.GetQuadrupletesBu()...)
+	meldMahjongs = append(meldMahjongs, onePlayer.GetQuadrupletesAn()...)
+	return meldMahjongs
+}
+
+// hasAnyMeld 房间里是否已经有人碰过或者杠过牌
+func hasAnyMeld(roomInfo *pb.RoomInfo) bool {
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if len(getMeldMahjongs(onePlayer)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getBuKongMahjong 获取手牌中可以补杠的牌：碰牌区有三张，手里还有第四张，没有返回nil
+func getBuKongMahjong(onePlayer *pb.RoomPlayerInfo) *pb.Mahjong {
+	for _, onePong := range Mahjong.GetDistinctMahjongs(onePlayer.GetTripletes()) {
+		if Mahjong.CountMahjong(onePlayer.GetHandMahjongs(), onePong) > 0 {
+			return onePong
+		}
+	}
+	return nil
+}
+
+// canWin 一手牌(3n+2张)能否胡牌：基本胡牌牌型或者七对
+func canWin(hand []*pb.Mahjong) bool {
+	counts := Mahjong.GetTileCounts(hand)
+	return Mahjong.CanWinNormal(counts) || Mahjong.IsSevenPairs(counts)
+}
+
+// canPlayerWin 玩家手牌加上mahjong能否胡牌，mahjong为空时判断手牌自摸
+func canPlayerWin(onePlayer *pb.RoomPlayerInfo, mahjong *pb.Mahjong) bool {
+	hand := onePlayer.GetHandMahjongs()
+	if mahjong != nil {
+		hand = append(append([]*pb.Mahjong{}, hand...), mahjong)
+	}
+	return len(hand)%3 == 2 && canWin(hand)
+}
+
+// getBeforeOperateReply 玩家出牌前可以进行的操作：暗杠、补杠和自摸，刚碰完牌的玩家只能出牌
+// 牌堆和明牌都没有了时不能再杠
+func getBeforeOperateReply(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, isAfterPong bool) *pb.MahjongBeforeOperateReply {
+	reply := &pb.MahjongBeforeOperateReply{}
+	if isAfterPong {
+		return reply
+	}
+	reply.CanWin = canPlayerWin(onePlayer, nil)
+	if !canKong(roomInfo) {
+		return reply
+	}
+	reply.MahjongsKongAn = Mahjong.GetAnKongMahjongs(onePlayer.GetHandMahjongs())
+	reply.MahjongKongBu = getBuKongMahjong(onePlayer)
+	return reply
+}
+
+// canKong 杠牌后要摸明牌，明牌摸完了从牌堆摸牌，都没有了不能再杠
+func canKong(roomInfo *pb.RoomInfo) bool {
+	return roomInfo.GetGhMahjongInRoom().GetGhMahjongOpenCards() > 0 || len(roomInfo.GetGangHuaMahjongCardHeap()) > 0
+}
+
+// getWinType 获取胡牌类型：七对里有四张一样的牌是七对龙爪背，全是刻子是对对胡
+func getWinType(hand []*pb.Mahjong) pb.GangHuaMahjongWinType {
+	counts := Mahjong.GetTileCounts(hand)
+	if Mahjong.IsSevenPairs(counts) {
+		if Mahjong.GetQuadNum(counts) > 0 {
+			return pb.GangHuaMahjongWinType_SevenPairsOfDragonClawBack
+		}
+		return pb.GangHuaMahjongWinType_SevenPairs
+	}
+	for _, oneSplit := range Mahjong.GetWinSplits(counts) {
+		if Mahjong.IsAllTriplet(oneSplit) {
+			return pb.GangHuaMahjongWinType_WinningWithAllPairedTiles
+		}
+	}
+	return pb.GangHuaMahjongWinType_normalWin
+}
+
+// getVisibleNum 从胡牌玩家的角度统计某张牌看得见的张数：所有人的出牌区、碰杠区和自己的手牌
+func getVisibleNum(roomInfo *pb.RoomInfo, hand []*pb.Mahjong, mahjong *pb.Mahjong) int {
+	visibleNum := Mahjong.CountMahjong(hand, mahjong)
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		visibleNum += Mahjong.CountMahjong(onePlayer.GetOutMahjongs(), mahjong)
+		visibleNum += Mahjong.CountMahjong(getMeldMahjongs(onePlayer), mahjong)
+	}
+	return visibleNum
+}
+
+// isUniqueKaZhang 绝卡张：胡的牌只能在顺子的中间，并且另外三张都已经看得见了
+// 点炮时胡的牌在出牌玩家的出牌区里，自摸时在手牌里，都要从看得见的张数中去掉
+func isUniqueKaZhang(roomInfo *pb.RoomInfo, hand []*pb.Mahjong, winMahjong *pb.Mahjong, isOwnDraw bool) bool {
+	visibleNum := getVisibleNum(roomInfo, hand, winMahjong) - 1
+	if !isOwnDraw {
+		visibleNum--
+	}
+	if visibleNum != 3 {
+		return false
+	}
+	winIndex := Mahjong.GetTileIndex(winMahjong)
+	for _, oneSplit := range Mahjong.GetWinSplits(Mahjong.GetTileCounts(hand)) {
+		for _, oneMeld := range oneSplit.Melds {
+			if oneMeld.Type == Mahjong.MeldSequence && oneMeld.Index+1 == winIndex {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getWinKinds 获取胡牌的番种
+// 天胡：庄家第一手自摸；地胡：闲家还没出过牌、也没有人碰杠过时胡牌；
+// 杠上花：杠牌后摸明牌自摸，按连续摸明牌的次数分为杠上花、两杠上花、三杠上花
+func getWinKinds(roomInfo *pb.RoomInfo, index int32, hand []*pb.Mahjong, winMahjong *pb.Mahjong, isOwnDraw bool) []pb.GangHuaMahjongWinKind {
+	var winKinds []pb.GangHuaMahjongWinKind
+	onePlayer := roomInfo.GetPlayerInfo()[index]
+	isFirstTurn := len(onePlayer.GetOutMahjongs()) == 0 && !hasAnyMeld(roomInfo)
+	if isFirstTurn && int64(index) == roomInfo.GetBankerIndex() && isOwnDraw {
+		winKinds = append(winKinds, pb.GangHuaMahjongWinKind_NaturalWin)
+	}
+	if isFirstTurn && int64(index) != roomInfo.GetBankerIndex() {
+		winKinds = append(winKinds, pb.GangHuaMahjongWinKind_EarthlyHand)
+	}
+	if isOwnDraw && onePlayer.GetGhSelOpenCard() {
+		switch onePlayer.GetGhSelOpenCardTimes() {
+		case 1:
+			winKinds = append(winKinds, pb.GangHuaMahjongWinKind_AfterAGang)
+		case 2:
+			winKinds = append(winKinds, pb.GangHuaMahjongWinKind_AfterTwoGang)
+		default:
+			winKinds = append(winKinds, pb.GangHuaMahjongWinKind_AfterThreeGang)
+		}
+	}
+	if isUniqueKaZhang(roomInfo, hand, winMahjong, isOwnDraw) {
+		winKinds = append(winKinds, pb.GangHuaMahjongWinKind_UniqueKaZhang)
+	}
+	if Mahjong.IsSameColor(append(append([]*pb.Mahjong{}, hand...), getMeldMahjongs(onePlayer)...)) {
+		winKinds = append(winKinds, pb.GangHuaMahjongWinKind_AllOfOneSuit)
+	}
+	return winKinds
+}
+
+// getWinReply 生成玩家胡牌的信息，winMahjong为胡的那张牌，点炮胡时不在手牌中
+// 出牌玩家是刚摸明牌后打出的牌时是杠上炮
+func getWinReply(roomInfo *pb.RoomInfo, index int32, winMahjong *pb.Mahjong, isOwnDraw bool) *pb.MahjongWinReply {
+	onePlayer := roomInfo.GetPlayerInfo()[index]
+	hand := onePlayer.GetHandMahjongs()
+	justKong := false
+	if !isOwnDraw {
+		hand = append(append([]*pb.Mahjong{}, hand...), winMahjong)
+		discardIndex := Mahjong.GetPlayerIndex(roomInfo, roomInfo.GetGhMahjongInRoom().GetLastPlayerUuid())
+		justKong = discardIndex >= 0 && roomInfo.GetPlayerInfo()[discardIndex].GetGhSelOpenCard()
+	}
+	return &pb.MahjongWinReply{
+		Can:      true,
+		WinType:  getWinType(hand),
+		WinKinds: getWinKinds(roomInfo, index, hand, winMahjong, isOwnDraw),
+		OwnDraw:  isOwnDraw,
+		JustKong: justKong,
+	}
+}
+
+// getWinName 拼出胡牌的名字，例如"杠上花自摸小七对"
+func getWinName(winReply *pb.MahjongWinReply) string {
+	var names []string
+	for _, oneKind := range winReply.GetWinKinds() {
+		names = append(names, winKindNames[oneKind])
+	}
+	if winReply.GetOwnDraw() {
+		names = append(names, "自摸")
+	} else if winReply.GetJustKong() {
+		names = append(names, "杠上炮")
+	}
+	names = append(names, winTypeNames[winReply.GetWinType()])
+	return strings.Join(names, "")
+}
+
+// getWinMultiple 计算胡牌的倍数：胡牌类型的倍数乘以每个番种的倍数，倍数表由房间配置
+func getWinMultiple(roomInfo *pb.RoomInfo, winReply *pb.MahjongWinReply) (int64, *pb.ErrorMessage) {
+	winTypeScores, msgErr := Mahjong.GetRoomConfigScoreTable(roomInfo, "WinTypeScore", pb.GangHuaMahjongWinType_value)
+	if msgErr != nil {
+		return 0, msgErr
+	}
+	winKindScores, msgErr := Mahjong.GetRoomConfigScoreTable(roomInfo, "WinKindScore", pb.GangHuaMahjongWinKind_value)
+	if msgErr != nil {
+		return 0, msgErr
+	}
+	multiple := winTypeScores[int32(winReply.GetWinType())]
+	if multiple <= 0 {
+		multiple = 1
+	}
+	for _, oneKind := range winReply.GetWinKinds() {
+		// 倍数表中没有配置或者配置为0的番种不参与计算
+		if kindScore := winKindScores[int32(oneKind)]; kindScore > 0 {
+			multiple *= kindScore
+		}
+	}
+	return multiple, nil
+}
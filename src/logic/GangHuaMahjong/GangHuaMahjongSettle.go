@@ -0,0 +1,385 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	Mahjong "gameServer-demo/src/logic/Mahjong"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["GangHuaMahjongSettle"] = &GangHuaMahjongSettle{}
+}
+
+// GangHuaMahjongSettle 杠花麻将游戏的结算组件，用于处理结算阶段的逻辑
+type GangHuaMahjongSettle struct {
+	base.Base
+}
+
+// kongScoreConfigNames 每种杠的分数(底分的倍数)对应的房间配置名
+var kongScoreConfigNames = map[pb.GangHuaMahjongKongType]string{
+	pb.GangHuaMahjongKongType_GangHuaMahjongKongType_Zhi: "ZhiKongScore",
+	pb.GangHuaMahjongKongType_GangHuaMahjongKongType_Bu:  "BuKongScore",
+	pb.GangHuaMahjongKongType_GangHuaMahjongKongType_An:  "AnKongScore",
+}
+
+// LoadComponent 加载组件
+func (obj *GangHuaMahjongSettle) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *GangHuaMahjongSettle) Start() {
+	obj.Base.Start()
+}
+
+// Drive 杠花麻将结算组件主驱动
+func (obj *GangHuaMahjongSettle) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowTime := time.Now().Unix()
+	// 结算 <-> 准备
+	if request.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		if nowTime < request.GetDoTime() {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateReady
+		request.NextRoomState = pb.RoomState_RoomStateReady
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	settleTime, msgErr := Mahjong.GetRoomConfigInt64(request, "SettleTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	// 推送房间状态 玩耍<->结算
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStatePlay,
+		AfterState:        pb.RoomState_RoomStateSettle,
+		AfterStateEndTime: nowTime + settleTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	msgErr = obj.settle(request, nowTime)
+	if msgErr != nil {
+		return request, msgErr
+	}
+	obj.changeBanker(request)
+
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" {
+			continue
+		}
+		if onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+			onePlayer.PlayNum++
+		}
+		// 对局中退出的玩家在结算完成后踢出
+		if onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+		}
+	}
+	request.NextRoomState = pb.RoomState_RoomStateReady
+	request.DoTime = nowTime + settleTime
+	return request, nil
+}
+
+// settle 结算胡牌和杠牌的分数，流局时不结算
+// 胡牌分数 = 底分 × 胡牌倍数，庄闲之间再加上庄家的连庄次数和闲家买的点数；自摸时其他玩家都要付，点炮时只有点炮的玩家付
+// 每个杠其他玩家都要付杠分，付的分数不超过玩家剩余的金币，赢家按照抽水比例对赢的部分抽水，修改玩家金币
+func (obj *GangHuaMahjongSettle) settle(request *pb.RoomInfo, nowTime int64) *pb.ErrorMessage {
+	commission, msgErr := Mahjong.GetRoomConfigInt64(request, "Commission")
+	if msgErr != nil {
+		return msgErr
+	}
+	baseScore, msgErr := Mahjong.GetRoomConfigInt64(request, "BaseScore")
+	if msgErr != nil {
+		return msgErr
+	}
+	players := getPlayPlayers(request)
+	ghInRoom := request.GetGhMahjongInRoom()
+
+	// 1.计算输赢和抽水
+	if !ghInRoom.GetGhDeuce() {
+		msgErr = obj.settleWin(request, baseScore)
+		if msgErr != nil {
+			return msgErr
+		}
+		msgErr = obj.settleKong(request, baseScore)
+		if msgErr != nil {
+			return msgErr
+		}
+	}
+	for _, onePlayer := range players {
+		if onePlayer.GetWinOrLose() <= 0 {
+			continue
+		}
+		water := onePlayer.GetWinOrLose() * commission / 100
+		onePlayer.WinOrLose -= water
+		onePlayer.HundredCommission = water
+	}
+
+	settleInfo := &pb.SettleInfo{}
+	for _, onePlayer := range players {
+		winOrLose := onePlayer.GetWinOrLose()
+		onePlayer.Balance += winOrLose
+		onePlayer.HundredWaterBill = common.AbsInt64(winOrLose)
+
+		settleInfo.SettleUUID = append(settleInfo.SettleUUID, onePlayer.GetUuid())
+		settleInfo.SettleWinOrLose = append(settleInfo.SettleWinOrLose, winOrLose)
+		settleInfo.SettleName = append(settleInfo.SettleName, onePlayer.GetName())
+		settleInfo.ImgUrl = append(settleInfo.ImgUrl, onePlayer.GetHeadImgUrl())
+		settleInfo.AfterBalance = append(settleInfo.AfterBalance, onePlayer.GetBalance())
+		settleInfo.ShortId = append(settleInfo.ShortId, onePlayer.GetShortId())
+		settleInfo.MahjongPlayerInfo = append(settleInfo.MahjongPlayerInfo, obj.getSettleMahjongs(request, onePlayer))
+	}
+	settleInfo.RoomId = []string{request.GetUuid()}
+	request.AllSettleInfo = append(request.AllSettleInfo, settleInfo)
+
+	// 推送结算结果
+	pushSettle := &pb.PushRoomSettleInfo{
+		RoomId:     request.GetUuid(),
+		PlayerInfo: players,
+	}
+	common.RoomBroadcast(request, pushSettle)
+
+	// 2.更新血池
+	var score int64
+	for _, onePlayer := range players {
+		if onePlayer.GetIsRobot() {
+			continue
+		}
+		score -= onePlayer.GetWinOrLose() + onePlayer.GetHundredCommission()
+	}
+	msgErr = common.BloodIncrease(score, request.GetGameType(), request.GetGameScene())
+	if msgErr != nil {
+		common.LogError("GangHuaMahjongSettle settle BloodIncrease has err:", msgErr)
+		return msgErr
+	}
+
+	// 3.修改玩家真实的Money
+	for _, onePlayer := range players {
+		// 后面协程操作，为避免错误在此处提取金额
+		addMoney := onePlayer.GetWinOrLose()
+		// 机器人不记录游戏记录
+		var gameRecord *pb.GameRecordReport
+		if !onePlayer.GetIsRobot() {
+			gameRecord = obj.getGameRecord(request, onePlayer, settleInfo, nowTime)
+		}
+		go obj.saveMoney(request, onePlayer, addMoney, gameRecord)
+	}
+	return nil
+}
+
+// pay 付款玩家向收款玩家付分，付的分数不超过付款玩家剩余的金币，返回实际付的分数
+func pay(payer *pb.RoomPlayerInfo, receiver *pb.RoomPlayerInfo, amount int64) int64 {
+	remain := payer.GetBalance() + payer.GetWinOrLose()
+	if amount > remain {
+		amount = remain
+	}
+	if amount <= 0 {
+		return 0
+	}
+	payer.WinOrLose -= amount
+	receiver.WinOrLose += amount
+	return amount
+}
+
+// settleWin 结算胡牌的分数，并记录每个玩家本局的状态
+func (obj *GangHuaMahjongSettle) settleWin(request *pb.RoomInfo, baseScore int64) *pb.ErrorMessage {
+	ghInRoom := request.GetGhMahjongInRoom()
+	winIndex := int32(ghInRoom.GetGhCanWinIndex())
+	winner := request.GetPlayerInfo()[winIndex]
+	multiple, msgErr := getWinMultiple(request, ghInRoom.GetMahjongWinReply())
+	if msgErr != nil {
+		return msgErr
+	}
+	discardIndex := Mahjong.GetPlayerIndex(request, ghInRoom.GetLastPlayerUuid())
+	bankerIndex := int32(request.GetBankerIndex())
+	banker := request.GetPlayerInfo()[bankerIndex]
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if int32(index) == winIndex || !Mahjong.IsPlaying(onePlayer) {
+			continue
+		}
+		onePlayer.GhPlayerLastStatus = pb.GangHuaMahjongUserLastStatus_lose
+		if !ghInRoom.GetGhWinByOwnDraw() && int32(index) != discardIndex {
+			continue
+		}
+		if !ghInRoom.GetGhWinByOwnDraw() {
+			onePlayer.GhPlayerLastStatus = pb.GangHuaMahjongUserLastStatus_discard
+		}
+		oneMultiple := multiple
+		// 庄闲之间加上庄家的连庄次数和闲家买的点数
+		if winIndex == bankerIndex || int32(index) == bankerIndex {
+			idle := onePlayer
+			if int32(index) == bankerIndex {
+				idle = winner
+			}
+			oneMultiple += int64(banker.GetGhMahjongBrankerTimes())
+			if idle.GetGhMahjongPoints() > 0 {
+				oneMultiple += int64(idle.GetGhMahjongPoints())
+			}
+		}
+		pay(onePlayer, winner, oneMultiple*baseScore)
+	}
+	winner.GhPlayerLastStatus = pb.GangHuaMahjongUserLastStatus_winByDiscard
+	if ghInRoom.GetGhWinByOwnDraw() {
+		winner.GhPlayerLastStatus = pb.GangHuaMahjongUserLastStatus_winBySelfDrawn
+	}
+	return nil
+}
+
+// settleKong 结算杠牌的分数，每个杠其他玩家都要付杠分，每种杠的分数是底分的倍数，由房间配置决定
+func (obj *GangHuaMahjongSettle) settleKong(request *pb.RoomInfo, baseScore int64) *pb.ErrorMessage {
+	kongScores := map[pb.GangHuaMahjongKongType]int64{}
+	for kongType, configName := range kongScoreConfigNames {
+		kongScore, msgErr := Mahjong.GetRoomConfigInt64(request, configName)
+		if msgErr != nil {
+			return msgErr
+		}
+		kongScores[kongType] = kongScore
+	}
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if !Mahjong.IsPlaying(onePlayer) {
+			continue
+		}
+		kongNum := map[pb.GangHuaMahjongKongType]int64{
+			pb.GangHuaMahjongKongType_GangHuaMahjongKongType_Zhi: int64(len(onePlayer.GetQuadrupletesZhi()) / 4),
+			pb.GangHuaMahjongKongType_GangHuaMahjongKongType_Bu:  int64(len(onePlayer.GetQuadrupletesBu()) / 4),
+			pb.GangHuaMahjongKongType_GangHuaMahjongKongType_An:  int64(len(onePlayer.GetQuadrupletesAn()) / 4),
+		}
+		var kongScore int64
+		for kongType, num := range kongNum {
+			kongScore += num * kongScores[kongType] * baseScore
+		}
+		if kongScore == 0 {
+			continue
+		}
+		for otherIndex, otherPlayer := range request.GetPlayerInfo() {
+			if otherIndex != index && Mahjong.IsPlaying(otherPlayer) {
+				pay(otherPlayer, onePlayer, kongScore)
+			}
+		}
+	}
+	return nil
+}
+
+// changeBanker 定下一局的庄家：庄家胡牌连庄，流局庄家不变，闲家胡牌由胡牌的玩家坐庄
+func (obj *GangHuaMahjongSettle) changeBanker(request *pb.RoomInfo) {
+	ghInRoom := request.GetGhMahjongInRoom()
+	if ghInRoom.GetGhDeuce() {
+		return
+	}
+	winIndex := int32(ghInRoom.GetGhCanWinIndex())
+	banker := request.GetPlayerInfo()[request.GetBankerIndex()]
+	if int64(winIndex) == request.GetBankerIndex() {
+		banker.GhMahjongBrankerTimes++
+		return
+	}
+	banker.GhMahjongBrankerTimes = 0
+	request.BankerUuid = request.GetPlayerInfo()[winIndex].GetUuid()
+	ghInRoom.LastBanker = request.GetBankerUuid()
+}
+
+// getSettleMahjongs 生成玩家本局结算时的牌，用于结算记录展示
+func (obj *GangHuaMahjongSettle) getSettleMahjongs(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) *pb.MahjongPlayerInfo {
+	mahjongPlayer := &pb.MahjongPlayerInfo{
+		HandRegion:   onePlayer.GetHandMahjongs(),
+		HandCardsNum: int32(len(onePlayer.GetHandMahjongs())),
+		OutputRegion: onePlayer.GetOutMahjongs(),
+	}
+	index := Mahjong.GetPlayerIndex(request, onePlayer.GetUuid())
+	for _, onePong := range Mahjong.GetDistinctMahjongs(onePlayer.GetTripletes()) {
+		mahjongPlayer.PongRegion = append(mahjongPlayer.PongRegion, &pb.MahjongPongInfo{
+			PongPlayerIndex: index,
+			PongPlayerUuid:  onePlayer.GetUuid(),
+			PongMahjongCard: onePong,
+		})
+	}
+	for _, oneKong := range []struct {
+		kong     pb.MahjongKongEnum
+		mahjongs []*pb.Mahjong
+	}{
+		{pb.MahjongKongEnum_KongZhi, onePlayer.GetQuadrupletesZhi()},
+		{pb.MahjongKongEnum_KongBa, onePlayer.GetQuadrupletesBu()},
+		{pb.MahjongKongEnum_KongAn, onePlayer.GetQuadrupletesAn()},
+	} {
+		for _, kongMahjong := range Mahjong.GetDistinctMahjongs(oneKong.mahjongs) {
+			mahjongPlayer.KongRegion = append(mahjongPlayer.KongRegion, &pb.MahjongKongInfo{
+				KongPlayerIndex: index,
+				KongPlayerUuid:  onePlayer.GetUuid(),
+				Kong:            oneKong.kong,
+				KongMahjongCard: kongMahjong,
+			})
+		}
+	}
+	// 胡牌的玩家手牌最后一张是胡的牌
+	if onePlayer.GetGhMahjongWinReply().GetCan() && len(onePlayer.GetHandMahjongs()) > 0 {
+		mahjongPlayer.WinRegion = onePlayer.GetHandMahjongs()[len(onePlayer.GetHandMahjongs())-1:]
+	}
+	return mahjongPlayer
+}
+
+// getGameRecord 生成玩家本局的游戏记录，金额在saveMoney中修改完玩家金币后补全
+func (obj *GangHuaMahjongSettle) getGameRecord(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, settleInfo *pb.SettleInfo, settleTime int64) *pb.GameRecordReport {
+	extendData := &pb.GameRecordExtendData{}
+	extendData.AllSettleInfo = []*pb.SettleInfo{settleInfo}
+	extendData.BankerIndex = roomInfo.GetBankerIndex()
+
+	gameRecord := &pb.GameRecordReport{}
+	gameRecord.GameType = roomInfo.GetGameType()
+	gameRecord.GameScene = roomInfo.GetGameScene()
+	gameRecord.GameMode = common.GameMode
+	gameRecord.RoomType = roomInfo.GetRoomType()
+	gameRecord.RoomId = roomInfo.GetUuid()
+	gameRecord.RoundId = roomInfo.GetCurrentRoundId()
+	gameRecord.PlayerUuid = onePlayer.GetUuid()
+	gameRecord.PlayerShortId = onePlayer.GetShortId()
+	gameRecord.PlayerAccount = onePlayer.GetAccount()
+	gameRecord.StartTime = roomInfo.GetRoundStartTime()
+	gameRecord.SettleTime = settleTime
+	gameRecord.WinOrLose = onePlayer.GetWinOrLose()
+	gameRecord.Commission = onePlayer.GetHundredCommission()
+	gameRecord.ExtendData = extendData
+	return gameRecord
+}
+
+// saveMoney 修改玩家金币并推送金币变动
+func (obj *GangHuaMahjongSettle) saveMoney(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, addMoney int64, gameRecord *pb.GameRecordReport) {
+	taskConfig := &pb.TaskConfig{}
+	taskConfig.TaskType = pb.TaskType_Task_PlayGame
+	taskConfig.GameType = roomInfo.GetGameType()
+	taskConfig.GameScene = roomInfo.GetGameScene()
+	taskConfig.TaskNum = 1
+	cateGoryList := strings.Split(common.GetRoomConfig(roomInfo, "CateGory"), ",")
+	for _, oneCateGoryStr := range cateGoryList {
+		oneCateGoryInt, err := strconv.Atoi(oneCateGoryStr)
+		if err != nil {
+			common.LogError("GangHuaMahjongSettle saveMoney oneCateGoryStr has err", oneCateGoryStr, err)
+		}
+		taskConfig.GameCateGoryType = append(taskConfig.GetGameCateGoryType(), pb.GameCateGoryType(oneCateGoryInt))
+	}
+	afterBalance, msgErr := common.ChangePlayerInfoAfterGameEnd(onePlayer.GetUuid(), addMoney, taskConfig, pb.ResourceChangeReason_GangHuaMahjongSettleGold)
+	if msgErr != nil {
+		return
+	}
+	if afterBalance != onePlayer.Balance {
+		common.LogError("GangHuaMahjongSettle saveMoney has err: afterBalance != onePlayer.Balance", afterBalance, onePlayer.Balance, "输赢：", onePlayer.WinOrLose)
+	}
+	// 推送游戏记录
+	if gameRecord != nil {
+		gameRecord.BeforeBalance = afterBalance - addMoney
+		gameRecord.SettleBalance = afterBalance
+		msgErr = common.PushGameRecord(gameRecord)
+		if msgErr != nil {
+			common.LogError("GangHuaMahjongSettle saveMoney PushGameRecord has err", onePlayer.GetUuid(), msgErr)
+		}
+	}
+	userBalanceChangePush := &pb.PushUserBalanceChange{}
+	userBalanceChangePush.UserId = onePlayer.GetUuid()
+	userBalanceChangePush.Balance = afterBalance
+	common.RoomBroadcast(roomInfo, userBalanceChangePush)
+}
@@ -0,0 +1,194 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["GemWarsBet"] = &GemWarsBet{}
+}
+
+// GemWarsBet 宝石战争游戏的下注组件，用于处理下注阶段的逻辑
+type GemWarsBet struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *GemWarsBet) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *GemWarsBet) Start() {
+	obj.Base.Start()
+}
+
+// Drive 宝石战争下注阶段的主驱动
+func (obj *GemWarsBet) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateBet {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateSettle
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	// 获取下注阶段时长
+	betTimeStr := common.GetRoomConfig(request, "BetTime")
+	betTime, err := strconv.Atoi(betTimeStr)
+	if err != nil {
+		common.LogError("GemWarsBet Drive betTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 推送房间状态改变的信息
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateReady,
+		AfterState:        pb.RoomState_RoomStateBet,
+		AfterStateEndTime: nowTime + int64(betTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+	// 推送本局各区域的限红
+	obj.pushBetInfo(request)
+
+	//下个状态
+	request.NextRoomState = pb.RoomState_RoomStateSettle
+	request.DoTime = nowTime + int64(betTime)
+	return request, nil
+}
+
+// RequestPlayerBet 玩家下注(区域：五种宝石和1到8号数字）
+func (obj *GemWarsBet) RequestPlayerBet(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	//获取用户id
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("GemWarsBet RequestPlayerBet uuid == nil")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	roomInfo := request.GetRoomInfo()
+
+	//必须是下注状态才能下注
+	if roomInfo.CurRoomState != pb.RoomState_RoomStateBet {
+		common.LogError("GemWarsBet RequestPlayerBet Room State not is Bet")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInBetTime, "")
+	}
+
+	realRequest := &pb.GemWarsBetRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("GemWarsBet RequestPlayerBet ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	betArea := realRequest.GetBetArea()
+	betBalance := realRequest.GetBetBalance()
+	if betArea <= pb.GemWarsBetArea_GemWarsBetArea_None || int(betArea) > betAreaNum || betBalance <= 0 {
+		common.LogError("GemWarsBet RequestPlayerBet request invalid", betArea, betBalance)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BetRequestInvalid, "")
+	}
+	areaIndex := int(betArea) - 1
+
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uuid)
+	//玩家不在房间里面，这是错误的
+	if playerInfo == nil {
+		common.LogError("GemWarsBet RequestPlayerBet player not in room", uuid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+
+	// 判断玩家身上的钱是否够这次下注的钱
+	if playerInfo.Balance < betBalance {
+		common.LogError("宝石战争玩家下注金额不足", uuid, playerInfo.Balance, betBalance)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerBalanceNotEnough, "")
+	}
+
+	// 判断限红
+	if len(roomInfo.MaxBetRatio) != betAreaNum || betBalance > roomInfo.MaxBetRatio[areaIndex] {
+		common.LogError("宝石战争玩家下注超出限红", betArea, betBalance)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BetRatioNotEnough, "")
+	}
+	odds, msgErr := getGemWarsOdds(roomInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	bankerMoney, msgErr := getBankerMoney(roomInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	// 1.将下注金额累加到玩家下注与总注，并重新计算限红
+	if len(playerInfo.PlayerBets) != betAreaNum {
+		playerInfo.PlayerBets = make([]int64, betAreaNum)
+	}
+	if len(roomInfo.AllBet) != betAreaNum {
+		roomInfo.AllBet = make([]int64, betAreaNum)
+	}
+	playerInfo.PlayerBets[areaIndex] += betBalance
+	roomInfo.AllBet[areaIndex] += betBalance
+	refreshMaxBetRatio(roomInfo, bankerMoney, odds)
+
+	// 2.减去房间信息里面玩家新增下注的金额 -- 最后结算才将金额从玩家表扣除
+	playerInfo.Balance -= betBalance
+	playerInfo.WinOrLose -= betBalance
+
+	// 3.将下注成功的玩家状态改变成游戏中
+	playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStatePlay
+
+	realReply := &pb.GemWarsBetReply{
+		Success: true,
+		RoomId:  roomInfo.GetUuid(),
+	}
+
+	// 推送各区域的总注和限红
+	obj.pushBetInfo(roomInfo)
+
+	// 所有区域都无法再下最小筹码时，直接开奖结算
+	minChip := getMinChip(roomInfo)
+	canBet := false
+	for _, maxBet := range roomInfo.MaxBetRatio {
+		if maxBet >= minChip {
+			canBet = true
+			break
+		}
+	}
+	if !canBet {
+		common.LogDebug("筹码已经达到庄家限红，直接开奖结算")
+		roomInfo.DoTime = time.Now().Unix()
+	}
+
+	return obj.packReply(roomInfo, realReply)
+}
+
+// pushBetInfo 推送各区域的总注和限红
+// 协议中没有宝石战争专用的推送消息，这里只推送房间信息中的相关字段
+func (obj *GemWarsBet) pushBetInfo(roomInfo *pb.RoomInfo) {
+	pushBetInfo := &pb.RoomInfo{
+		Uuid:        roomInfo.GetUuid(),
+		AllBet:      roomInfo.GetAllBet(),
+		MaxBetRatio: roomInfo.GetMaxBetRatio(),
+	}
+	common.RoomBroadcast(roomInfo, pushBetInfo)
+}
+
+// packReply 封装回复给driver的房间信息和回复消息
+func (obj *GemWarsBet) packReply(roomInfo *pb.RoomInfo, realReply proto.Message) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	realReplyAny, err := ptypes.MarshalAny(realReply)
+	if err != nil {
+		common.LogError("GemWarsBet packReply MarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	reply.RoomInfo = roomInfo
+	reply.Message = realReplyAny
+	return reply, nil
+}
@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+)
+
+func init() {
+	common.AllComponentMap["GemWarsReady"] = &GemWarsReady{}
+}
+
+// GemWarsReady 宝石战争游戏的准备组件，用于处理准备阶段的逻辑
+type GemWarsReady struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *GemWarsReady) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *GemWarsReady) Start() {
+	obj.Base.Start()
+	common.InitGameConfigTemp(common.GemWarsGameConfigTemp, pb.GameType_GemWars)
+}
+
+// Drive 宝石战争准备阶段的主驱动
+func (obj *GemWarsReady) Drive(request *pb.RoomInfo, extraInfo *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 宝石战争只有系统当庄，准备阶段结束后直接进入下注阶段
+	return common.HundredGameReadyDiverNextState(request, func(roomInfo *pb.RoomInfo) *pb.ErrorMessage {
+		odds, msgErr := getGemWarsOdds(roomInfo)
+		if msgErr != nil {
+			return msgErr
+		}
+		bankerMoney, msgErr := getBankerMoney(roomInfo)
+		if msgErr != nil {
+			return msgErr
+		}
+		// 公共准备逻辑中没有初始化宝石战争的下注区域，这里重置玩家下注和各区域总注
+		for _, onePlayer := range roomInfo.GetPlayerInfo() {
+			onePlayer.PlayerBets = make([]int64, betAreaNum)
+		}
+		roomInfo.AllBet = make([]int64, betAreaNum)
+		roomInfo.BankerUuid = "systemBanker"
+		refreshMaxBetRatio(roomInfo, bankerMoney, odds)
+		return nil
+	}, pb.RoomState_RoomStateBet)
+}
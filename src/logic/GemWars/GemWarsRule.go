@@ -0,0 +1,239 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+)
+
+// 宝石区域的数量（红、绿、黄、蓝、金）
+const gemAreaNum = int(pb.GemWarsBetArea_GemWars_Gold)
+
+// 数字区域的数量（1到8号）
+const numAreaNum = int(pb.GemWarsBetArea_GemWars_Num8) - gemAreaNum
+
+// 下注区域的数量，下标为下注区域-1，前面是宝石区域，后面是数字区域
+const betAreaNum = gemAreaNum + numAreaNum
+
+// 血池控制时最多尝试的开奖次数
+const controlTryNum = 30
+
+// gemWarsOdds 宝石战争的赔率和开奖权重配置
+type gemWarsOdds struct {
+	// 各区域的赔率（含本金），下标为下注区域-1
+	areaOdds []int64
+	// 各宝石开出的权重
+	gemWeights []int32
+	// 各数字开出的权重
+	numWeights []int32
+}
+
+// gemWarsResult 一局的开奖结果，每局开出一种宝石和一个数字
+type gemWarsResult struct {
+	// 开出宝石的区域下标
+	gemIndex int
+	// 开出数字的区域下标
+	numIndex int
+}
+
+// getGemWarsOdds 获取房间的赔率和权重配置
+func getGemWarsOdds(roomInfo *pb.RoomInfo) (*gemWarsOdds, *pb.ErrorMessage) {
+	gemOdds, msgErr := getConfigNums(roomInfo, "GemOdds", gemAreaNum)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	numOdds, msgErr := getConfigNums(roomInfo, "NumOdds", numAreaNum)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	gemWeights, msgErr := getConfigNums(roomInfo, "GemWeights", gemAreaNum)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	numWeights, msgErr := getConfigNums(roomInfo, "NumWeights", numAreaNum)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	odds := &gemWarsOdds{
+		areaOdds:   append(gemOdds, numOdds...),
+		gemWeights: make([]int32, gemAreaNum),
+		numWeights: make([]int32, numAreaNum),
+	}
+	for _, oneOdds := range odds.areaOdds {
+		if oneOdds <= 1 {
+			common.LogError("getGemWarsOdds odds must more than 1", odds.areaOdds)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+	}
+	var allGemWeight, allNumWeight int64
+	for index, weight := range gemWeights {
+		odds.gemWeights[index] = int32(weight)
+		allGemWeight += weight
+	}
+	for index, weight := range numWeights {
+		odds.numWeights[index] = int32(weight)
+		allNumWeight += weight
+	}
+	if allGemWeight <= 0 || allNumWeight <= 0 {
+		common.LogError("getGemWarsOdds weights all zero", gemWeights, numWeights)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return odds, nil
+}
+
+// getConfigNums 获取逗号分隔的数字配置，数量必须和num一致且不能为负数
+func getConfigNums(roomInfo *pb.RoomInfo, configName string, num int) ([]int64, *pb.ErrorMessage) {
+	configStr := common.GetRoomConfig(roomInfo, configName)
+	configList := strings.Split(configStr, ",")
+	if len(configList) != num {
+		common.LogError("GemWars getConfigNums config length has err", configName, configStr)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	nums := make([]int64, num)
+	for index, oneStr := range configList {
+		oneNum, err := strconv.ParseInt(oneStr, 10, 64)
+		if err != nil || oneNum < 0 {
+			common.LogError("GemWars getConfigNums has err", configName, configStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		nums[index] = oneNum
+	}
+	return nums, nil
+}
+
+// isWinArea 判断区域是否在开奖结果中
+func (result *gemWarsResult) isWinArea(areaIndex int) bool {
+	return areaIndex == result.gemIndex || areaIndex == result.numIndex
+}
+
+// getAreaResult 计算某个区域的下注在开奖结果下的返还金额（含本金）和盈利金额
+// 返回值：返还金额，盈利金额
+func getAreaResult(areaIndex int, bet int64, result *gemWarsResult, odds *gemWarsOdds) (int64, int64) {
+	if bet <= 0 || !result.isWinArea(areaIndex) {
+		return 0, 0
+	}
+	backBalance := bet * odds.areaOdds[areaIndex]
+	return backBalance, backBalance - bet
+}
+
+// getBetsNetWin 计算一组下注在开奖结果下的净输赢（未抽水）
+func getBetsNetWin(bets []int64, result *gemWarsResult, odds *gemWarsOdds) int64 {
+	var netWin int64
+	for areaIndex, bet := range bets {
+		if bet <= 0 {
+			continue
+		}
+		backBalance, _ := getAreaResult(areaIndex, bet, result, odds)
+		netWin += backBalance - bet
+	}
+	return netWin
+}
+
+// getBankerMoney 获取系统庄家可用于赔付的金额，宝石战争只有系统当庄
+func getBankerMoney(roomInfo *pb.RoomInfo) (int64, *pb.ErrorMessage) {
+	defaultMoneyStr := common.GetRoomConfig(roomInfo, "DefaultBankerMoney")
+	defaultMoney, err := strconv.ParseInt(defaultMoneyStr, 10, 64)
+	if err != nil {
+		common.LogError("GemWars getBankerMoney DefaultBankerMoney has err", defaultMoneyStr, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return defaultMoney, nil
+}
+
+// refreshMaxBetRatio 根据庄家金额和房间总注刷新各区域的限红
+// 每个区域按照这个区域中奖时庄家输得最多的开奖结果计算还能赔付的下注金额
+func refreshMaxBetRatio(roomInfo *pb.RoomInfo, bankerMoney int64, odds *gemWarsOdds) {
+	if len(roomInfo.AllBet) != betAreaNum {
+		roomInfo.AllBet = make([]int64, betAreaNum)
+	}
+	maxBankerLose := make([]int64, betAreaNum)
+	for gemIndex := 0; gemIndex < gemAreaNum; gemIndex++ {
+		for numIndex := gemAreaNum; numIndex < betAreaNum; numIndex++ {
+			result := &gemWarsResult{gemIndex: gemIndex, numIndex: numIndex}
+			bankerLose := getBetsNetWin(roomInfo.AllBet, result, odds)
+			if bankerLose > maxBankerLose[gemIndex] {
+				maxBankerLose[gemIndex] = bankerLose
+			}
+			if bankerLose > maxBankerLose[numIndex] {
+				maxBankerLose[numIndex] = bankerLose
+			}
+		}
+	}
+	roomInfo.MaxBetRatio = make([]int64, betAreaNum)
+	for areaIndex := 0; areaIndex < betAreaNum; areaIndex++ {
+		maxBet := (bankerMoney - maxBankerLose[areaIndex]) / (odds.areaOdds[areaIndex] - 1)
+		if maxBet < 0 {
+			maxBet = 0
+		}
+		roomInfo.MaxBetRatio[areaIndex] = maxBet
+	}
+}
+
+// getMinChip 获取最小的筹码值
+func getMinChip(roomInfo *pb.RoomInfo) int64 {
+	var minChip int64
+	for _, chipStr := range strings.Split(common.GetRoomConfig(roomInfo, "Chips"), ",") {
+		chip, err := strconv.ParseInt(chipStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		if minChip == 0 || chip < minChip {
+			minChip = chip
+		}
+	}
+	return minChip
+}
+
+// getSystemScore 计算开奖结果下平台的收益（真实玩家输的钱）
+func getSystemScore(roomInfo *pb.RoomInfo, result *gemWarsResult, odds *gemWarsOdds) int64 {
+	var playerNetWin int64
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetIsRobot() {
+			continue
+		}
+		playerNetWin += getBetsNetWin(onePlayer.GetPlayerBets(), result, odds)
+	}
+	return -playerNetWin
+}
+
+// drawResult 按照权重抽取一次开奖结果
+func drawResult(odds *gemWarsOdds) (*gemWarsResult, *pb.ErrorMessage) {
+	gemIndex, err := common.GetRandomIndexByWeight(odds.gemWeights)
+	if err != nil {
+		common.LogError("GemWars drawResult gem GetRandomIndexByWeight has err", err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	numIndex, err := common.GetRandomIndexByWeight(odds.numWeights)
+	if err != nil {
+		common.LogError("GemWars drawResult num GetRandomIndexByWeight has err", err)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return &gemWarsResult{gemIndex: gemIndex, numIndex: gemAreaNum + numIndex}, nil
+}
+
+// drawByControl 根据血池状态抽取开奖结果
+// 血池需要控制时多次按权重抽取，选择平台收益最高（或最低）的结果
+func drawByControl(roomInfo *pb.RoomInfo, bloodState pb.BloodSlotStatus, odds *gemWarsOdds) (*gemWarsResult, *pb.ErrorMessage) {
+	bestResult, msgErr := drawResult(odds)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	if bloodState != pb.BloodSlotStatus_BloodSlotStatus_Win && bloodState != pb.BloodSlotStatus_BloodSlotStatus_Lose {
+		return bestResult, nil
+	}
+	bestScore := getSystemScore(roomInfo, bestResult, odds)
+	for try := 1; try < controlTryNum; try++ {
+		result, msgErr := drawResult(odds)
+		if msgErr != nil {
+			continue
+		}
+		score := getSystemScore(roomInfo, result, odds)
+		if (bloodState == pb.BloodSlotStatus_BloodSlotStatus_Win && score > bestScore) ||
+			(bloodState == pb.BloodSlotStatus_BloodSlotStatus_Lose && score < bestScore) {
+			bestScore = score
+			bestResult = result
+		}
+	}
+	return bestResult, nil
+}
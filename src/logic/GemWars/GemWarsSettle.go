@@ -0,0 +1,239 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["GemWarsSettle"] = &GemWarsSettle{}
+}
+
+// GemWarsSettle 宝石战争游戏的结算组件，用于处理开牌和结算阶段的逻辑
+type GemWarsSettle struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *GemWarsSettle) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *GemWarsSettle) Start() {
+	obj.Base.Start()
+}
+
+// Drive 宝石战争结算组件主驱动
+func (obj *GemWarsSettle) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	return common.HundredGameSettleDiver(request, obj.realDrive)
+}
+
+// realDrive 宝石战争结算组件主logic
+func (obj *GemWarsSettle) realDrive(request *pb.RoomInfo) *pb.ErrorMessage {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+
+	// 获取抽数比例
+	commissionStr := common.GetRoomConfig(request, "Commission")
+	commission, err := strconv.ParseInt(commissionStr, 10, 64)
+	if err != nil {
+		common.LogError("GemWarsSettle Drive commissionStr has err", err)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	odds, msgErr := getGemWarsOdds(request)
+	if msgErr != nil {
+		return msgErr
+	}
+	winLogNumStr := common.GetRoomConfig(request, "WinLogNum")
+	winLogNum, err := strconv.Atoi(winLogNumStr)
+	if err != nil {
+		common.LogError("GemWarsSettle Drive winLogNumStr has err", err)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 1.按照宝石和数字的权重开奖，血池需要控制时选择控制结果
+	bloodState := common.BloodGetState(request.GetGameType(), request.GetGameScene())
+	result, msgErr := drawByControl(request, bloodState, odds)
+	if msgErr != nil {
+		return msgErr
+	}
+	// 保存开奖走势，宝石战争没有骰子，Dice中每局依次保存开出的宝石区域和数字区域（最新的在前）
+	gemArea := int32(result.gemIndex + 1)
+	numArea := int32(result.numIndex + 1)
+	request.LastWinnerIndex = gemArea
+	request.Dice = append([]int32{gemArea, numArea}, request.Dice...)
+	if len(request.Dice) > winLogNum*2 {
+		request.Dice = request.Dice[:winLogNum*2]
+	}
+
+	// 推送开奖结果和开奖走势
+	pushResult := &pb.RoomInfo{
+		Uuid:            request.GetUuid(),
+		LastWinnerIndex: request.GetLastWinnerIndex(),
+		Dice:            request.GetDice(),
+		AllBet:          request.GetAllBet(),
+	}
+	common.RoomBroadcast(request, pushResult)
+
+	// 2.对闲家进行结算，宝石战争只有系统当庄，庄家输赢就是闲家输赢的相反数
+	var bankerWinBalance int64
+	for _, onePlayer := range request.PlayerInfo {
+		if onePlayer.Uuid == "" || onePlayer.Uuid == request.BankerUuid {
+			continue
+		}
+		if onePlayer.PlayerRoomState != pb.PlayerRoomState_PlayerRoomStatePlay {
+			continue
+		}
+		bankerWinBalance -= obj.settlePlayer(onePlayer, result, odds, commission)
+	}
+
+	// 推送本局所有玩家和庄家的输赢
+	pushSettle := &pb.PushRoomSettleInfo{
+		RoomId:          request.GetUuid(),
+		PlayerInfo:      request.GetPlayerInfo(),
+		BankerWinOrLose: bankerWinBalance,
+	}
+	common.RoomBroadcast(request, pushSettle)
+
+	// 4.更新血池
+	var score int64
+	for _, onePlayer := range request.PlayerInfo {
+		if onePlayer.IsRobot || onePlayer.Uuid == "" {
+			continue
+		}
+		score -= onePlayer.WinOrLose + onePlayer.HundredCommission
+	}
+	msgErr = common.BloodIncrease(score, request.GetGameType(), request.GetGameScene())
+	if msgErr != nil {
+		common.LogError("GemWarsSettle Drive BloodIncrease has err:", msgErr)
+		return msgErr
+	}
+
+	// 5.修改玩家真实的Money
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.Uuid == "" || onePlayer.HundredWaterBill == 0 {
+			continue
+		}
+		// 后面协程操作，为避免错误在此处提取金额
+		addMoney := onePlayer.GetGetBonus() + onePlayer.GetWinOrLose()
+		// 机器人不记录游戏记录
+		var gameRecord *pb.GameRecordReport
+		if !onePlayer.IsRobot {
+			gameRecord = obj.getGameRecord(request, onePlayer, bankerWinBalance, nowTime)
+		}
+		go obj.saveMoney(request, onePlayer, addMoney, gameRecord)
+	}
+	return nil
+}
+
+// settlePlayer 结算一个闲家，更新玩家的金额、输赢、流水和抽水
+// 返回值：玩家未抽水前的净输赢，用于计算庄家输赢
+func (obj *GemWarsSettle) settlePlayer(onePlayer *pb.RoomPlayerInfo, result *gemWarsResult, odds *gemWarsOdds, commission int64) int64 {
+	// 返还金额（含本金）
+	var backBalance int64
+	// 个人流水值
+	var waterNum int64
+	// 个人抽水值
+	var commissionNum int64
+	// 未抽水前的净输赢
+	var netWin int64
+	for areaIndex, bet := range onePlayer.PlayerBets {
+		if bet <= 0 {
+			continue
+		}
+		areaBack, winBalance := getAreaResult(areaIndex, bet, result, odds)
+		netWin += areaBack - bet
+		if winBalance > 0 {
+			water := winBalance * commission / 100
+			backBalance += areaBack - water
+			waterNum += winBalance - water
+			commissionNum += water
+			continue
+		}
+		// 输掉的部分
+		backBalance += areaBack
+		waterNum += bet - areaBack
+	}
+	// 下注时已经从房间金额中扣除，这里加上返还的部分
+	onePlayer.Balance += backBalance
+	onePlayer.WinOrLose += backBalance
+	onePlayer.HundredWaterBill = waterNum
+	onePlayer.HundredCommission = commissionNum
+	return netWin
+}
+
+// getGameRecord 生成玩家本局的游戏记录，金额在saveMoney中修改完玩家金币后补全
+func (obj *GemWarsSettle) getGameRecord(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, bankerWinOrLose int64, settleTime int64) *pb.GameRecordReport {
+	extendData := &pb.GameRecordExtendData{}
+	extendData.BankerUuid = roomInfo.GetBankerUuid()
+	extendData.BankerWinOrLose = bankerWinOrLose
+	// 玩家各区下注
+	extendData.PlayerAllBet = make([]int64, len(onePlayer.GetPlayerBets()))
+	copy(extendData.PlayerAllBet, onePlayer.GetPlayerBets())
+	totalBet := int64(0)
+	for _, bet := range extendData.PlayerAllBet {
+		totalBet += bet
+	}
+
+	gameRecord := &pb.GameRecordReport{}
+	gameRecord.GameType = roomInfo.GetGameType()
+	gameRecord.GameScene = roomInfo.GetGameScene()
+	gameRecord.GameMode = common.GameMode
+	gameRecord.RoomType = roomInfo.GetRoomType()
+	gameRecord.RoomId = roomInfo.GetUuid()
+	gameRecord.RoundId = roomInfo.GetCurrentRoundId()
+	gameRecord.PlayerUuid = onePlayer.GetUuid()
+	gameRecord.PlayerShortId = onePlayer.GetShortId()
+	gameRecord.PlayerAccount = onePlayer.GetAccount()
+	gameRecord.StartTime = roomInfo.GetRoundStartTime()
+	gameRecord.SettleTime = settleTime
+	gameRecord.TotalBet = totalBet
+	gameRecord.WinOrLose = onePlayer.GetWinOrLose()
+	gameRecord.Commission = onePlayer.GetHundredCommission()
+	gameRecord.ExtendData = extendData
+	return gameRecord
+}
+
+// saveMoney 修改玩家金币并推送金币变动
+func (obj *GemWarsSettle) saveMoney(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, addMoney int64, gameRecord *pb.GameRecordReport) {
+	taskConfig := &pb.TaskConfig{}
+	taskConfig.TaskType = pb.TaskType_Task_PlayGame
+	taskConfig.GameType = roomInfo.GetGameType()
+	taskConfig.GameScene = roomInfo.GetGameScene()
+	taskConfig.TaskNum = 1
+	cateGoryList := strings.Split(common.GetRoomConfig(roomInfo, "CateGory"), ",")
+	for _, oneCateGoryStr := range cateGoryList {
+		oneCateGoryInt, err := strconv.Atoi(oneCateGoryStr)
+		if err != nil {
+			common.LogError("GemWarsSettle saveMoney oneCateGoryStr has err", oneCateGoryStr, err)
+		}
+		taskConfig.GameCateGoryType = append(taskConfig.GetGameCateGoryType(), pb.GameCateGoryType(oneCateGoryInt))
+	}
+	// 协议中还没有宝石战争专用的金币变动原因
+	afterBalance, msgErr := common.ChangePlayerInfoAfterGameEnd(onePlayer.GetUuid(), addMoney, taskConfig, pb.ResourceChangeReason_ReasonNone)
+	if msgErr != nil {
+		return
+	}
+	if afterBalance != onePlayer.Balance {
+		common.LogError("GemWarsSettle saveMoney has err: afterBalance != onePlayer.Balance", afterBalance, onePlayer.Balance, "输赢：", onePlayer.WinOrLose)
+	}
+	// 推送游戏记录
+	if gameRecord != nil {
+		gameRecord.BeforeBalance = afterBalance - addMoney
+		gameRecord.SettleBalance = afterBalance
+		msgErr = common.PushGameRecord(gameRecord)
+		if msgErr != nil {
+			common.LogError("GemWarsSettle saveMoney PushGameRecord has err", onePlayer.GetUuid(), msgErr)
+		}
+	}
+	userBalanceChangePush := &pb.PushUserBalanceChange{}
+	userBalanceChangePush.UserId = onePlayer.GetUuid()
+	userBalanceChangePush.Balance = afterBalance
+	common.RoomBroadcast(roomInfo, userBalanceChangePush)
+}
@@ -0,0 +1,390 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["HundredBullBet"] = &HundredBullBet{}
+}
+
+// HundredBullBet 百人牛牛游戏的下注组件，用于处理下注阶段的逻辑和玩家上下庄
+type HundredBullBet struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *HundredBullBet) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *HundredBullBet) Start() {
+	obj.Base.Start()
+}
+
+// Drive 百人牛牛下注阶段的主驱动
+func (obj *HundredBullBet) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	// 100ms 推送一次
+	nowNanoTime := time.Now().UnixNano()
+	if (nowNanoTime-request.LastPushBetTime)/1e6 > 100 && len(request.HundredBullBetPushes) > 0 {
+		obj.pushPlayerBets(request)
+		request.LastPushBetTime = nowNanoTime
+	}
+
+	if request.NextRoomState != pb.RoomState_RoomStateBet {
+		if nowTime < request.DoTime {
+			request.MilliDoTime = nowNanoTime/1e6 + 100
+			return request, nil
+		}
+		// 这个时候还有消息没推送就推送
+		if len(request.HundredBullBetPushes) > 0 {
+			obj.pushPlayerBets(request)
+		}
+		request.CurRoomState = pb.RoomState_RoomStateSettle
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	// 获取下注阶段时长
+	betTimeStr := common.GetRoomConfig(request, "BetTime")
+	betTime, err := strconv.Atoi(betTimeStr)
+	if err != nil {
+		common.LogError("HundredBullBet Drive betTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 推送房间状态改变的信息
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateDeal,
+		AfterState:        pb.RoomState_RoomStateBet,
+		AfterStateEndTime: nowTime + int64(betTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	//下个状态
+	request.NextRoomState = pb.RoomState_RoomStateSettle
+	request.DoTime = nowTime + int64(betTime)
+	request.MilliDoTime = nowNanoTime/1e6 + 100 //下注区别与其他 100ms驱动一次
+	return request, nil
+}
+
+// pushPlayerBets 合并推送这段时间内的玩家下注
+func (obj *HundredBullBet) pushPlayerBets(request *pb.RoomInfo) {
+	realPushMsg := &pb.PushHundredBullPlayerBets{
+		RoomId:   request.GetUuid(),
+		PushBets: request.GetHundredBullBetPushes(),
+	}
+	common.RoomBroadcast(request, realPushMsg)
+	// 字段置零
+	request.HundredBullBetPushes = make([]*pb.PushHundredBullPlayerBet, 0)
+}
+
+// RequestPlayerBet 玩家下注(区域：天地玄黄以及天地玄黄翻倍）
+func (obj *HundredBullBet) RequestPlayerBet(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	//获取用户id
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("HundredBullBet RequestPlayerBet uuid == nil")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	roomInfo := request.GetRoomInfo()
+
+	//庄家不能下注
+	if uuid == roomInfo.BankerUuid {
+		common.LogError("HundredBullBet RequestPlayerBet BankerUuid can not bet")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BankerCannotBet, "")
+	}
+
+	//必须是下注状态才能下注
+	if roomInfo.CurRoomState != pb.RoomState_RoomStateBet {
+		common.LogError("HundredBullBet RequestPlayerBet Room State not is Bet")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInBetTime, "")
+	}
+
+	realRequest := &pb.HundredBullBetRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("HundredBullBet RequestPlayerBet ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	betArea := realRequest.GetBetArea()
+	betBalance := realRequest.GetBetBalance()
+	if betArea < pb.HundredBullBetArea_Sky || betArea > pb.HundredBullBetArea_YellowDouble || betBalance <= 0 {
+		common.LogError("HundredBullBet RequestPlayerBet request invalid", betArea, betBalance)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BetRequestInvalid, "")
+	}
+	areaIndex := int(betArea)
+
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uuid)
+	//玩家不在房间里面，这是错误的
+	if playerInfo == nil {
+		common.LogError("HundredBullBet RequestPlayerBet player not in room", uuid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+
+	odds, msgErr := getHundredBullOdds(roomInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	bankerMoney, msgErr := getBankerMoney(roomInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	// 判断玩家身上的钱是否够这次下注预扣的钱（翻倍区域按照最大赔率预扣）
+	reserveBalance := getAreaReserve(areaIndex, betBalance, odds)
+	if playerInfo.Balance < reserveBalance {
+		common.LogError("百人牛牛玩家下注金额不足", uuid, playerInfo.Balance, reserveBalance)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerBalanceNotEnough, "")
+	}
+
+	// 判断限红
+	if len(roomInfo.MaxBetRatio) != betAreaNum || betBalance > roomInfo.MaxBetRatio[areaIndex] {
+		common.LogError("百人牛牛玩家下注超出限红", betArea, betBalance)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BetRatioNotEnough, "")
+	}
+
+	// 1.将下注金额累加到玩家下注与总注，并重新计算限红
+	if len(playerInfo.PlayerBets) != betAreaNum {
+		playerInfo.PlayerBets = make([]int64, betAreaNum)
+	}
+	playerInfo.PlayerBets[areaIndex] += betBalance
+	roomInfo.HundredBullAllBet[areaIndex] += betBalance
+	refreshMaxBetRatio(roomInfo, bankerMoney, odds)
+
+	// 2.减去房间信息里面玩家预扣的金额 -- 最后结算才将金额从玩家表扣除
+	playerInfo.Balance -= reserveBalance
+	playerInfo.WinOrLose -= reserveBalance
+
+	// 3.将下注成功的玩家状态改变成游戏中
+	playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStatePlay
+
+	realReply := &pb.HundredBullBetReply{
+		IsSuccess: true,
+		RoomId:    roomInfo.GetUuid(),
+	}
+
+	// 下注放入推送队列，在驱动中合并推送
+	pushMsg := &pb.PushHundredBullPlayerBet{
+		AllBet:        roomInfo.HundredBullAllBet,
+		Uuid:          uuid,
+		PlayerBet:     playerInfo.PlayerBets,
+		MaxBetRatio:   roomInfo.MaxBetRatio,
+		RoomId:        roomInfo.GetUuid(),
+		BetArea:       betArea,
+		CurrentBet:    betBalance,
+		Playerbalance: playerInfo.Balance,
+	}
+	roomInfo.HundredBullBetPushes = append(roomInfo.HundredBullBetPushes, pushMsg)
+
+	// 所有区域都无法再下最小筹码时，直接开牌结算
+	minChip := getMinChip(roomInfo)
+	canBet := false
+	for _, maxBet := range roomInfo.MaxBetRatio {
+		if maxBet >= minChip {
+			canBet = true
+			break
+		}
+	}
+	if !canBet {
+		common.LogDebug("筹码已经达到庄家限红，直接开牌结算")
+		roomInfo.DoTime = time.Now().Unix()
+	}
+
+	return obj.packReply(roomInfo, realReply)
+}
+
+// RequestUpBanker 玩家上庄
+func (obj *HundredBullBet) RequestUpBanker(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	//获取用户id
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("HundredBullBet RequestUpBanker uuid == nil")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	roomInfo := request.GetRoomInfo()
+
+	realRequest := &pb.HundredBullUpBankerRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("HundredBullBet RequestUpBanker ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 获取上庄最小金额，上庄玩家列表最大长度
+	userBankerMoneyStr := common.GetRoomConfig(roomInfo, "UserBankerMoney")
+	userBankerMoney, err := strconv.ParseInt(userBankerMoneyStr, 10, 64)
+	if err != nil {
+		common.LogError("HundredBullBet RequestUpBanker userBankerMoney has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	bankersLengthStr := common.GetRoomConfig(roomInfo, "BankersLength")
+	bankersLength, err := strconv.Atoi(bankersLengthStr)
+	if err != nil {
+		common.LogError("HundredBullBet RequestUpBanker bankersLength has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uuid)
+	if playerInfo == nil {
+		common.LogError("HundredBullBet RequestUpBanker player not in room", uuid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+
+	// 判断上庄玩家是否是庄家或者已经在申请列表里
+	if uuid == roomInfo.BankerUuid || common.PlayerIsInBankers(uuid, roomInfo) {
+		common.LogError("HundredBullBet RequestUpBanker player already in Bankers,uuid = ", uuid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerAlreadyInBanker, "")
+	}
+
+	// 判断上庄玩家金额够否
+	if playerInfo.Balance < userBankerMoney {
+		common.LogError("HundredBullBet RequestUpBanker player Balance is not enough,uuid = ", uuid, " balance = ", playerInfo.Balance)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerBalanceNotEnough, "")
+	}
+
+	// 判断上庄玩家列表是否有空位
+	if len(roomInfo.Bankers) >= bankersLength {
+		common.LogError("HundredBullBet RequestUpBanker bankers length >= ", bankersLength)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BankersIsFull, "")
+	}
+
+	// 将用户加入到申请庄家列表，将玩家状态改变成游戏中
+	roomInfo.Bankers = append(roomInfo.Bankers, uuid)
+	playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStatePlay
+
+	// 广播现在庄家申请队列
+	pushMsg := &pb.PushHundredBullChangeBankers{
+		RoomId:  roomInfo.GetUuid(),
+		Bankers: roomInfo.Bankers,
+	}
+	common.RoomBroadcast(roomInfo, pushMsg)
+
+	realReply := &pb.HundredBullUpBankerReply{
+		IsSuccess: true,
+	}
+	return obj.packReply(roomInfo, realReply)
+}
+
+// RequestDownBanker 玩家下庄
+func (obj *HundredBullBet) RequestDownBanker(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	//获取用户id
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("HundredBullBet RequestDownBanker uuid == nil")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	roomInfo := request.GetRoomInfo()
+
+	realRequest := &pb.HundredBullDownBankerRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("HundredBullBet RequestDownBanker ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	realReply := &pb.HundredBullDownBankerReply{}
+
+	// 1.玩家在庄家申请列表，就将他删除+广播
+	for index, bankerUuid := range roomInfo.Bankers {
+		if bankerUuid != uuid {
+			continue
+		}
+		roomInfo.Bankers = append(roomInfo.Bankers[:index], roomInfo.Bankers[index+1:]...)
+		realReply.IsSuccess = true
+		tempInfo := common.GetRoomPlayerInfo(roomInfo, uuid)
+		if tempInfo != nil {
+			tempInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateFree
+		}
+		pushMsg := &pb.PushHundredBullChangeBankers{
+			RoomId:  roomInfo.GetUuid(),
+			Bankers: roomInfo.Bankers,
+		}
+		common.RoomBroadcast(roomInfo, pushMsg)
+		break
+	}
+
+	// 2.如果玩家是庄家,设置庄家申请了下庄,在下一回合定庄阶段将庄家改变
+	if uuid == roomInfo.BankerUuid {
+		roomInfo.DownBankerQuest = true
+		realReply.IsSuccess = true
+	}
+	return obj.packReply(roomInfo, realReply)
+}
+
+// RequestRoomWinLogs 获取房间输赢记录
+func (obj *HundredBullBet) RequestRoomWinLogs(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	//获取用户id
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("HundredBullBet RequestRoomWinLogs uuid == nil")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	roomInfo := request.GetRoomInfo()
+
+	realRequest := &pb.HundredBullGetRoomWinLogRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("HundredBullBet RequestRoomWinLogs ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	realReply := &pb.HundredBullGetRoomWinLogReply{
+		IsSuccess: true,
+		WinRecord: &pb.HundredBullWinRecord{
+			RoomId:              roomInfo.GetUuid(),
+			HundredBullWinInfos: roomInfo.GetHundredBullWinInfos(),
+		},
+	}
+	return obj.packReply(roomInfo, realReply)
+}
+
+// RequestGetRoomPlayerList 获取房间玩家列表
+func (obj *HundredBullBet) RequestGetRoomPlayerList(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	//获取用户id
+	uuid := extroInfo.GetUserId()
+	if uuid == "" {
+		common.LogError("HundredBullBet RequestGetRoomPlayerList uuid == nil")
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	roomInfo := request.GetRoomInfo()
+
+	realRequest := &pb.HundredBullGetRoomPlayersRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("HundredBullBet RequestGetRoomPlayerList ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	realReply := &pb.HundredBullGetRoomPlayersReply{
+		IsSuccess:  true,
+		PlayerInfo: roomInfo.GetPlayerInfo(),
+	}
+	return obj.packReply(roomInfo, realReply)
+}
+
+// packReply 封装回复给driver的房间信息和回复消息
+func (obj *HundredBullBet) packReply(roomInfo *pb.RoomInfo, realReply proto.Message) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	realReplyAny, err := ptypes.MarshalAny(realReply)
+	if err != nil {
+		common.LogError("HundredBullBet packReply MarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	reply.RoomInfo = roomInfo
+	reply.Message = realReplyAny
+	return reply, nil
+}
@@ -0,0 +1,82 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["HundredBullDeal"] = &HundredBullDeal{}
+}
+
+// HundredBullDeal 百人牛牛游戏组件，用于处理发牌阶段的逻辑
+type HundredBullDeal struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *HundredBullDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *HundredBullDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 房间发牌状态的驱动逻辑
+func (obj *HundredBullDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	//获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateDeal {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateBet
+		request.NextRoomState = pb.RoomState_RoomStateBet
+		request.DoTime = nowTime
+		return request, nil
+	}
+	dealTimeStr := common.GetRoomConfig(request, "DealTime")
+	dealTime, err := strconv.Atoi(dealTimeStr)
+	if err != nil {
+		common.LogError("HundredBullDeal Drive dealTimeStr has err", dealTimeStr)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 洗牌后每个牌区发四张明牌，剩下的牌留在牌堆中，结算时再给每个牌区发一张扣牌
+	cardHeap := common.GetShufflePokerHeap(1)
+	request.HundredBullPokerList = make([]*pb.HundredBullPokerCard, 0, pokerAreaNum)
+	for index := 0; index < pokerAreaNum; index++ {
+		request.HundredBullPokerList = append(request.HundredBullPokerList, &pb.HundredBullPokerCard{
+			PokerPublish: &pb.HundredBullPokerPublish{
+				PokerList: cardHeap[:publishPokerNum],
+			},
+		})
+		cardHeap = cardHeap[publishPokerNum:]
+	}
+	request.PokerCardHeap = cardHeap
+
+	//推送消息
+	roomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateLocation,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + int64(dealTime),
+	}
+	pushSendPoker := &pb.PushHundredBullSendPoker{
+		RoomStateChange: roomState,
+		PokerList:       request.HundredBullPokerList,
+		RoomId:          request.GetUuid(),
+	}
+	common.RoomBroadcast(request, pushSendPoker)
+
+	//下个状态
+	request.NextRoomState = pb.RoomState_RoomStateBet
+	request.DoTime = nowTime + int64(dealTime)
+	return request, nil
+}
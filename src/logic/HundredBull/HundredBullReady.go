@@ -0,0 +1,56 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["HundredBullReady"] = &HundredBullReady{}
+}
+
+// HundredBullReady 百人牛牛游戏的准备组件，用于处理准备阶段的逻辑
+type HundredBullReady struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *HundredBullReady) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *HundredBullReady) Start() {
+	obj.Base.Start()
+	common.InitGameConfigTemp(common.HundredBullGameConfigTemp, pb.GameType_HundredBull)
+}
+
+// Drive 百人牛牛准备阶段的主驱动
+func (obj *HundredBullReady) Drive(request *pb.RoomInfo, extraInfo *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	return common.HundredGameReadyDiver(request, func(roomInfo *pb.RoomInfo) *pb.ErrorMessage {
+		readyTimeStr := common.GetRoomConfig(roomInfo, "ReadyTime")
+		readyTime, err := strconv.Atoi(readyTimeStr)
+		if err != nil {
+			common.LogError("HundredBullReady Drive readyTimeStr has err", readyTimeStr)
+			return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		// 随机本局第一张牌的发牌位置，对应庄、天、地、玄、黄
+		roomState := &pb.PushRoomStateChange{
+			RoomId:            roomInfo.GetUuid(),
+			BeforeState:       pb.RoomState_RoomStateSettle,
+			AfterState:        pb.RoomState_RoomStateReady,
+			AfterStateEndTime: time.Now().Unix() + int64(readyTime),
+		}
+		pushLocation := &pb.PushHundredBullLocation{
+			RoomStateChange: roomState,
+			Index:           pb.HundredBullCardArea(common.GetRandomNum(1, pokerAreaNum)),
+			RoomId:          roomInfo.GetUuid(),
+		}
+		common.RoomBroadcast(roomInfo, pushLocation)
+		return nil
+	})
+}
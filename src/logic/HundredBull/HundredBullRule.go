@@ -0,0 +1,266 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+)
+
+// 下注区域的数量（天地玄黄以及天地玄黄翻倍）
+const betAreaNum = 8
+
+// 闲家牌区的数量（天地玄黄）
+const playerAreaNum = 4
+
+// 牌区的数量（庄天地玄黄）
+const pokerAreaNum = 5
+
+// 庄家牌区的下标
+const bankerPokerIndex = int(pb.HundredBullCardArea_AreaBanker) - 1
+
+// 每个牌区发牌阶段亮出的明牌数量，剩下一张在结算时开出
+const publishPokerNum = 4
+
+// 血池控制时最多尝试的开牌组合数量
+const controlTryNum = 50
+
+// 参与赔率计算的牌型
+var bullPokerTypes = []pb.CrazyBullPokerType{
+	pb.CrazyBullPokerType_CrazyBullCardType_None,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull1,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull2,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull3,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull4,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull5,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull6,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull7,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull8,
+	pb.CrazyBullPokerType_CrazyBullCardType_Bull9,
+	pb.CrazyBullPokerType_CrazyBullCardType_BullBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_StreakyBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_AlongBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_GourdBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_SameFlowerBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_BoomBull,
+	pb.CrazyBullPokerType_CrazyBullCardType_LittleBull,
+}
+
+// hundredBullOdds 百人牛牛的牌型赔率配置
+type hundredBullOdds struct {
+	// 各牌型的翻倍赔率
+	typeOdds map[pb.CrazyBullPokerType]int64
+	// 最大的翻倍赔率，翻倍区域下注时按照这个赔率预扣金额
+	maxOdds int64
+}
+
+// bullHand 一个牌区的开牌结果
+type bullHand struct {
+	pokers    []*pb.Poker
+	pokerType pb.CrazyBullPokerType
+}
+
+// getHundredBullOdds 获取房间的牌型赔率配置，配置名为Odds加上牌型名，如OddsBull1、OddsBullBull
+func getHundredBullOdds(roomInfo *pb.RoomInfo) (*hundredBullOdds, *pb.ErrorMessage) {
+	odds := &hundredBullOdds{
+		typeOdds: make(map[pb.CrazyBullPokerType]int64),
+	}
+	for _, pokerType := range bullPokerTypes {
+		oddsName := "Odds" + strings.TrimPrefix(pokerType.String(), "CrazyBullCardType_")
+		oddsStr := common.GetRoomConfig(roomInfo, oddsName)
+		oddsNum, err := strconv.ParseInt(oddsStr, 10, 64)
+		if err != nil || oddsNum <= 0 {
+			common.LogError("getHundredBullOdds has err", oddsName, oddsStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		odds.typeOdds[pokerType] = oddsNum
+		if oddsNum > odds.maxOdds {
+			odds.maxOdds = oddsNum
+		}
+	}
+	return odds, nil
+}
+
+// getPokerIndexByBetArea 获取下注区域对应的牌区下标
+func getPokerIndexByBetArea(areaIndex int) int {
+	return areaIndex%playerAreaNum + 1
+}
+
+// isDoubleArea 是否是翻倍区域，翻倍区域按照牌型赔率输赢，普通区域按照1倍输赢
+func isDoubleArea(areaIndex int) bool {
+	return areaIndex >= playerAreaNum
+}
+
+// getAreaReserve 获取下注时需要预扣的金额，翻倍区域按照最大赔率预扣
+func getAreaReserve(areaIndex int, bet int64, odds *hundredBullOdds) int64 {
+	if isDoubleArea(areaIndex) {
+		return bet * odds.maxOdds
+	}
+	return bet
+}
+
+// isPlayerAreaWin 判断闲家牌区是否赢了庄家
+func isPlayerAreaWin(hands []*bullHand, pokerIndex int) bool {
+	hand := hands[pokerIndex]
+	bankerHand := hands[bankerPokerIndex]
+	return common.CompareBullPokers(hand.pokers, hand.pokerType, bankerHand.pokers, bankerHand.pokerType)
+}
+
+// getAreaNetWin 计算某个区域的下注在开牌结果下的净输赢（未抽水）
+func getAreaNetWin(areaIndex int, bet int64, hands []*bullHand, odds *hundredBullOdds) int64 {
+	if bet <= 0 {
+		return 0
+	}
+	pokerIndex := getPokerIndexByBetArea(areaIndex)
+	isWin := isPlayerAreaWin(hands, pokerIndex)
+	if !isDoubleArea(areaIndex) {
+		if isWin {
+			return bet
+		}
+		return -bet
+	}
+	// 翻倍区域赢了按照闲家牌型赔率，输了按照庄家牌型赔率
+	if isWin {
+		return bet * odds.typeOdds[hands[pokerIndex].pokerType]
+	}
+	return -bet * odds.typeOdds[hands[bankerPokerIndex].pokerType]
+}
+
+// getBetsNetWin 计算一组下注在开牌结果下的净输赢（未抽水）
+func getBetsNetWin(bets []int64, hands []*bullHand, odds *hundredBullOdds) int64 {
+	var netWin int64
+	for areaIndex, bet := range bets {
+		netWin += getAreaNetWin(areaIndex, bet, hands, odds)
+	}
+	return netWin
+}
+
+// getBankerMoney 获取当前庄家可用于赔付的金额
+func getBankerMoney(roomInfo *pb.RoomInfo) (int64, *pb.ErrorMessage) {
+	if roomInfo.GetBankerUuid() == "systemBanker" {
+		defaultMoneyStr := common.GetRoomConfig(roomInfo, "DefaultBankerMoney")
+		defaultMoney, err := strconv.ParseInt(defaultMoneyStr, 10, 64)
+		if err != nil {
+			common.LogError("getBankerMoney DefaultBankerMoney has err", defaultMoneyStr, err)
+			return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		return defaultMoney, nil
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo == nil {
+		return 0, nil
+	}
+	return bankerInfo.GetBalance(), nil
+}
+
+// refreshMaxBetRatio 根据庄家金额和房间总注刷新各区域的限红
+// 按照所有闲家区域都以最大赔率赢庄家计算庄家还能赔付的金额
+func refreshMaxBetRatio(roomInfo *pb.RoomInfo, bankerMoney int64, odds *hundredBullOdds) {
+	if len(roomInfo.HundredBullAllBet) != betAreaNum {
+		roomInfo.HundredBullAllBet = make([]int64, betAreaNum)
+	}
+	var maxLose int64
+	for areaIndex, bet := range roomInfo.HundredBullAllBet {
+		maxLose += getAreaReserve(areaIndex, bet, odds)
+	}
+	roomInfo.MaxBetRatio = make([]int64, betAreaNum)
+	for areaIndex := 0; areaIndex < betAreaNum; areaIndex++ {
+		maxBet := (bankerMoney - maxLose) / getAreaReserve(areaIndex, 1, odds)
+		if maxBet < 0 {
+			maxBet = 0
+		}
+		roomInfo.MaxBetRatio[areaIndex] = maxBet
+	}
+}
+
+// getMinChip 获取最小的筹码值
+func getMinChip(roomInfo *pb.RoomInfo) int64 {
+	var minChip int64
+	for _, chipStr := range strings.Split(common.GetRoomConfig(roomInfo, "Chips"), ",") {
+		chip, err := strconv.ParseInt(chipStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		if minChip == 0 || chip < minChip {
+			minChip = chip
+		}
+	}
+	return minChip
+}
+
+// getHands 将每个牌区的明牌和扣牌组成一手牌并计算牌型
+func getHands(pokerList []*pb.HundredBullPokerCard, hiddenPokers []*pb.Poker) []*bullHand {
+	hands := make([]*bullHand, pokerAreaNum)
+	for index := 0; index < pokerAreaNum; index++ {
+		pokers := make([]*pb.Poker, 0, publishPokerNum+1)
+		pokers = append(pokers, pokerList[index].GetPokerPublish().GetPokerList()...)
+		pokers = append(pokers, hiddenPokers[index])
+		hands[index] = &bullHand{
+			pokers:    pokers,
+			pokerType: common.GetBullPokerType(pokers),
+		}
+	}
+	return hands
+}
+
+// getSystemScore 计算开牌结果下平台的收益（真实玩家输的钱）
+func getSystemScore(roomInfo *pb.RoomInfo, hands []*bullHand, odds *hundredBullOdds, bankerIsRobot bool) int64 {
+	var playerNetWin int64
+	var bankerNetWin int64
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetUuid() == roomInfo.GetBankerUuid() {
+			continue
+		}
+		netWin := getBetsNetWin(onePlayer.GetPlayerBets(), hands, odds)
+		bankerNetWin -= netWin
+		if !onePlayer.GetIsRobot() {
+			playerNetWin += netWin
+		}
+	}
+	score := -playerNetWin
+	if !bankerIsRobot {
+		score -= bankerNetWin
+	}
+	return score
+}
+
+// dealByControl 根据血池状态从牌堆中给每个牌区发最后一张扣牌
+// 不控制时按顺序发牌，控制时随机尝试多种扣牌组合，选择平台收益最高（或最低）的组合
+// 返回值：每个牌区的开牌结果，每个牌区的扣牌
+func dealByControl(roomInfo *pb.RoomInfo, bloodState pb.BloodSlotStatus, odds *hundredBullOdds) ([]*bullHand, []*pb.Poker) {
+	cardHeap := roomInfo.GetPokerCardHeap()
+	bestHidden := cardHeap[:pokerAreaNum]
+	bestHands := getHands(roomInfo.GetHundredBullPokerList(), bestHidden)
+	if bloodState != pb.BloodSlotStatus_BloodSlotStatus_Win && bloodState != pb.BloodSlotStatus_BloodSlotStatus_Lose {
+		return bestHands, bestHidden
+	}
+	bankerIsRobot := isBankerIsRobot(roomInfo)
+	bestScore := getSystemScore(roomInfo, bestHands, odds, bankerIsRobot)
+	for try := 1; try < controlTryNum; try++ {
+		hidden := make([]*pb.Poker, 0, pokerAreaNum)
+		for _, heapIndex := range common.GenerateRandomNumber(0, len(cardHeap), pokerAreaNum) {
+			hidden = append(hidden, cardHeap[heapIndex])
+		}
+		hands := getHands(roomInfo.GetHundredBullPokerList(), hidden)
+		score := getSystemScore(roomInfo, hands, odds, bankerIsRobot)
+		if (bloodState == pb.BloodSlotStatus_BloodSlotStatus_Win && score > bestScore) ||
+			(bloodState == pb.BloodSlotStatus_BloodSlotStatus_Lose && score < bestScore) {
+			bestScore = score
+			bestHidden = hidden
+			bestHands = hands
+		}
+	}
+	return bestHands, bestHidden
+}
+
+// isBankerIsRobot 判断庄家是否是机器人
+func isBankerIsRobot(roomInfo *pb.RoomInfo) bool {
+	if roomInfo.GetBankerUuid() == "systemBanker" {
+		return true
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo == nil {
+		return true
+	}
+	return bankerInfo.GetIsRobot()
+}
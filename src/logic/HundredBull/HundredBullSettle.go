@@ -0,0 +1,306 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["HundredBullSettle"] = &HundredBullSettle{}
+}
+
+// HundredBullSettle 百人牛牛游戏的结算组件，用于处理开牌和结算阶段的逻辑
+type HundredBullSettle struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *HundredBullSettle) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *HundredBullSettle) Start() {
+	obj.Base.Start()
+}
+
+// Drive 百人牛牛结算组件主驱动
+func (obj *HundredBullSettle) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	return common.HundredGameSettleDiver(request, obj.realDrive)
+}
+
+// realDrive 百人牛牛结算组件主logic
+func (obj *HundredBullSettle) realDrive(request *pb.RoomInfo) *pb.ErrorMessage {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+
+	// 获取抽数比例
+	commissionStr := common.GetRoomConfig(request, "Commission")
+	commission, err := strconv.ParseInt(commissionStr, 10, 64)
+	if err != nil {
+		common.LogError("HundredBullSettle Drive commissionStr has err", err)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	odds, msgErr := getHundredBullOdds(request)
+	if msgErr != nil {
+		return msgErr
+	}
+
+	winLogNumStr := common.GetRoomConfig(request, "WinLogNum")
+	winLogNum, err := strconv.Atoi(winLogNumStr)
+	if err != nil {
+		common.LogError("HundredBullSettle Drive WinLogNum has err", err)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 1.开牌，给每个牌区发最后一张扣牌，血池需要控制时选择合适的扣牌组合
+	if len(request.GetHundredBullPokerList()) != pokerAreaNum || len(request.GetPokerCardHeap()) < pokerAreaNum {
+		common.LogError("HundredBullSettle Drive poker list has err", len(request.GetHundredBullPokerList()), len(request.GetPokerCardHeap()))
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	bloodState := common.BloodGetState(request.GetGameType(), request.GetGameScene())
+	hands, hiddenPokers := dealByControl(request, bloodState, odds)
+	for index, hand := range hands {
+		request.HundredBullPokerList[index].PokerHidden = &pb.HundredBullPokerHidden{
+			PokerList: []*pb.Poker{hiddenPokers[index]},
+		}
+		request.HundredBullPokerList[index].PokerType = hand.pokerType
+	}
+	// 清理牌堆
+	request.PokerCardHeap = []*pb.Poker{}
+
+	// 保存输赢记录，1为闲家赢 2为闲家输
+	winInfo := &pb.HundredBullWinInfo{
+		RoomRound: request.GetRoomRound(),
+		Time:      nowTime,
+	}
+	for pokerIndex := 1; pokerIndex < pokerAreaNum; pokerIndex++ {
+		winOrLose := int64(2)
+		if isPlayerAreaWin(hands, pokerIndex) {
+			winOrLose = 1
+		}
+		winInfo.AllAreaWin = append(winInfo.AllAreaWin, winOrLose)
+		winInfo.AllAreaOdds = append(winInfo.AllAreaOdds, odds.typeOdds[hands[pokerIndex].pokerType])
+		winInfo.AllAreaType = append(winInfo.AllAreaType, int64(hands[pokerIndex].pokerType))
+	}
+	request.HundredBullWinInfos = append([]*pb.HundredBullWinInfo{winInfo}, request.HundredBullWinInfos...)
+	if len(request.HundredBullWinInfos) > winLogNum {
+		request.HundredBullWinInfos = request.HundredBullWinInfos[:winLogNum]
+	}
+
+	// 2.对闲家进行结算
+	var bankerWinBalance int64
+	for _, onePlayer := range request.PlayerInfo {
+		if onePlayer.Uuid == "" || onePlayer.Uuid == request.BankerUuid {
+			continue
+		}
+		if onePlayer.PlayerRoomState != pb.PlayerRoomState_PlayerRoomStatePlay {
+			continue
+		}
+		bankerWinBalance -= obj.settlePlayer(onePlayer, hands, odds, commission)
+	}
+
+	// 3.庄家输赢
+	bankerWinBalance = obj.compensation(request, bankerWinBalance)
+	bankerInfo := common.GetRoomPlayerInfo(request, request.BankerUuid)
+	if bankerInfo != nil {
+		water := int64(0)
+		// 计算庄家税收
+		if bankerWinBalance > 0 {
+			water = bankerWinBalance * commission / 100
+			bankerWinBalance -= water
+		}
+		bankerInfo.Balance += bankerWinBalance
+		bankerInfo.WinOrLose = bankerWinBalance
+		bankerInfo.HundredWaterBill = common.AbsInt64(bankerWinBalance)
+		bankerInfo.HundredCommission = water
+	}
+
+	// 推送开牌和结算结果
+	pushSettle := &pb.PushHundredBullSettle{
+		PokerList:       request.HundredBullPokerList,
+		WinInfos:        winInfo,
+		RoomId:          request.GetUuid(),
+		BankerWinOrLose: bankerWinBalance,
+	}
+	for _, onePlayer := range request.PlayerInfo {
+		if onePlayer.Uuid == "" || onePlayer.HundredWaterBill == 0 {
+			continue
+		}
+		pushSettle.RoomPlayerInfo = append(pushSettle.RoomPlayerInfo, onePlayer)
+	}
+	common.RoomBroadcast(request, pushSettle)
+
+	// 4.更新血池
+	var score int64
+	for _, onePlayer := range request.PlayerInfo {
+		if onePlayer.IsRobot || onePlayer.Uuid == "" {
+			continue
+		}
+		score -= onePlayer.WinOrLose + onePlayer.HundredCommission
+	}
+	msgErr = common.BloodIncrease(score, request.GetGameType(), request.GetGameScene())
+	if msgErr != nil {
+		common.LogError("HundredBullSettle Drive BloodIncrease has err:", msgErr)
+		return msgErr
+	}
+
+	// 5.修改玩家真实的Money
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.Uuid == "" || onePlayer.HundredWaterBill == 0 {
+			continue
+		}
+		// 后面协程操作，为避免错误在此处提取金额
+		addMoney := onePlayer.GetGetBonus() + onePlayer.GetWinOrLose()
+		// 机器人不记录游戏记录
+		var gameRecord *pb.GameRecordReport
+		if !onePlayer.IsRobot {
+			gameRecord = obj.getGameRecord(request, onePlayer, winInfo, bankerWinBalance, nowTime)
+		}
+		go obj.saveMoney(request, onePlayer, addMoney, gameRecord)
+	}
+	return nil
+}
+
+// settlePlayer 结算一个闲家，更新玩家的金额、输赢、流水和抽水
+// 返回值：玩家未抽水前的净输赢，用于计算庄家输赢
+func (obj *HundredBullSettle) settlePlayer(onePlayer *pb.RoomPlayerInfo, hands []*bullHand, odds *hundredBullOdds, commission int64) int64 {
+	// 返还金额（含预扣的金额）
+	var backBalance int64
+	// 个人流水值
+	var waterNum int64
+	// 个人抽水值
+	var commissionNum int64
+	// 未抽水前的净输赢
+	var netWin int64
+	for areaIndex, bet := range onePlayer.PlayerBets {
+		if bet <= 0 {
+			continue
+		}
+		reserveBalance := getAreaReserve(areaIndex, bet, odds)
+		areaNetWin := getAreaNetWin(areaIndex, bet, hands, odds)
+		netWin += areaNetWin
+		if areaNetWin > 0 {
+			water := areaNetWin * commission / 100
+			backBalance += reserveBalance + areaNetWin - water
+			waterNum += areaNetWin - water
+			commissionNum += water
+			continue
+		}
+		// 输掉的部分从预扣的金额中扣除
+		backBalance += reserveBalance + areaNetWin
+		waterNum -= areaNetWin
+	}
+	// 下注时已经从房间金额中预扣，这里加上返还的部分
+	onePlayer.Balance += backBalance
+	onePlayer.WinOrLose += backBalance
+	onePlayer.HundredWaterBill = waterNum
+	onePlayer.HundredCommission = commissionNum
+	return netWin
+}
+
+// compensation 玩家庄家不够赔付时，按照闲家的盈利比例分配庄家的金额
+// 返回值：庄家实际的输赢
+func (obj *HundredBullSettle) compensation(roomInfo *pb.RoomInfo, bankerWinBalance int64) int64 {
+	if bankerWinBalance >= 0 || roomInfo.BankerUuid == "systemBanker" {
+		return bankerWinBalance
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.BankerUuid)
+	if bankerInfo == nil || -bankerWinBalance <= bankerInfo.Balance {
+		return bankerWinBalance
+	}
+	common.LogError("百人牛牛庄家金币不足结算:", bankerInfo.Balance, bankerWinBalance)
+	loseAmount := -bankerInfo.Balance
+	for _, onePlayer := range roomInfo.PlayerInfo {
+		if onePlayer.WinOrLose <= 0 || onePlayer.Uuid == roomInfo.BankerUuid {
+			continue
+		}
+		// 按照比例计算实际能拿到的盈利
+		realWin := onePlayer.WinOrLose * loseAmount / bankerWinBalance
+		lessNum := onePlayer.WinOrLose - realWin
+		onePlayer.WinOrLose -= lessNum
+		onePlayer.Balance -= lessNum
+		common.LogError("出现不够赔的情况，用户：", onePlayer.Account, "少赔金额:", lessNum)
+	}
+	return loseAmount
+}
+
+// getGameRecord 生成玩家本局的游戏记录，金额在saveMoney中修改完玩家金币后补全
+func (obj *HundredBullSettle) getGameRecord(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, winInfo *pb.HundredBullWinInfo, bankerWinOrLose int64, settleTime int64) *pb.GameRecordReport {
+	extendData := &pb.GameRecordExtendData{}
+	extendData.HundredBullPokerList = roomInfo.GetHundredBullPokerList()
+	extendData.HundredBullWinInfos = winInfo
+	extendData.BankerUuid = roomInfo.GetBankerUuid()
+	extendData.BankerWinOrLose = bankerWinOrLose
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo != nil {
+		extendData.BankerShortId = bankerInfo.GetShortId()
+	}
+	// 玩家各区下注
+	extendData.PlayerAllBet = make([]int64, len(onePlayer.GetPlayerBets()))
+	copy(extendData.PlayerAllBet, onePlayer.GetPlayerBets())
+	totalBet := int64(0)
+	for _, bet := range extendData.PlayerAllBet {
+		totalBet += bet
+	}
+
+	gameRecord := &pb.GameRecordReport{}
+	gameRecord.GameType = roomInfo.GetGameType()
+	gameRecord.GameScene = roomInfo.GetGameScene()
+	gameRecord.GameMode = common.GameMode
+	gameRecord.RoomType = roomInfo.GetRoomType()
+	gameRecord.RoomId = roomInfo.GetUuid()
+	gameRecord.RoundId = roomInfo.GetCurrentRoundId()
+	gameRecord.PlayerUuid = onePlayer.GetUuid()
+	gameRecord.PlayerShortId = onePlayer.GetShortId()
+	gameRecord.PlayerAccount = onePlayer.GetAccount()
+	gameRecord.StartTime = roomInfo.GetRoundStartTime()
+	gameRecord.SettleTime = settleTime
+	gameRecord.TotalBet = totalBet
+	gameRecord.WinOrLose = onePlayer.GetWinOrLose()
+	gameRecord.Commission = onePlayer.GetHundredCommission()
+	gameRecord.ExtendData = extendData
+	return gameRecord
+}
+
+// saveMoney 修改玩家金币并推送金币变动
+func (obj *HundredBullSettle) saveMoney(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, addMoney int64, gameRecord *pb.GameRecordReport) {
+	taskConfig := &pb.TaskConfig{}
+	taskConfig.TaskType = pb.TaskType_Task_PlayGame
+	taskConfig.GameType = roomInfo.GetGameType()
+	taskConfig.GameScene = roomInfo.GetGameScene()
+	taskConfig.TaskNum = 1
+	cateGoryList := strings.Split(common.GetRoomConfig(roomInfo, "CateGory"), ",")
+	for _, oneCateGoryStr := range cateGoryList {
+		oneCateGoryInt, err := strconv.Atoi(oneCateGoryStr)
+		if err != nil {
+			common.LogError("HundredBullSettle saveMoney oneCateGoryStr has err", oneCateGoryStr, err)
+		}
+		taskConfig.GameCateGoryType = append(taskConfig.GetGameCateGoryType(), pb.GameCateGoryType(oneCateGoryInt))
+	}
+	afterBalance, msgErr := common.ChangePlayerInfoAfterGameEnd(onePlayer.GetUuid(), addMoney, taskConfig, pb.ResourceChangeReason_HundredBullSettleGold)
+	if msgErr != nil {
+		return
+	}
+	if afterBalance != onePlayer.Balance {
+		common.LogError("HundredBullSettle saveMoney has err: afterBalance != onePlayer.Balance", afterBalance, onePlayer.Balance, "输赢：", onePlayer.WinOrLose)
+	}
+	// 推送游戏记录
+	if gameRecord != nil {
+		gameRecord.BeforeBalance = afterBalance - addMoney
+		gameRecord.SettleBalance = afterBalance
+		msgErr = common.PushGameRecord(gameRecord)
+		if msgErr != nil {
+			common.LogError("HundredBullSettle saveMoney PushGameRecord has err", onePlayer.GetUuid(), msgErr)
+		}
+	}
+	userBalanceChangePush := &pb.PushUserBalanceChange{}
+	userBalanceChangePush.UserId = onePlayer.GetUuid()
+	userBalanceChangePush.Balance = afterBalance
+	common.RoomBroadcast(roomInfo, userBalanceChangePush)
+}
@@ -1,14 +1,54 @@
 package logic
 
 import (
+	Baccarat "gameServer-demo/src/logic/Baccarat"
+	BenzBMW "gameServer-demo/src/logic/BenzBMW"
+	ClearJoy "gameServer-demo/src/logic/ClearJoy"
+	CompareBull "gameServer-demo/src/logic/CompareBull"
+	CrazyBull "gameServer-demo/src/logic/CrazyBull"
+	DaXuan "gameServer-demo/src/logic/DaXuan"
+	DoubleLinked "gameServer-demo/src/logic/DoubleLinked"
+	DragonTigerFight "gameServer-demo/src/logic/DragonTigerFight"
+	GamePlugin "gameServer-demo/src/logic/GamePlugin"
+	GangHuaMahjong "gameServer-demo/src/logic/GangHuaMahjong"
+	GemWars "gameServer-demo/src/logic/GemWars"
 	Hall "gameServer-demo/src/logic/Hall"
+	HundredBull "gameServer-demo/src/logic/HundredBull"
+	Jinhua "gameServer-demo/src/logic/Jinhua"
+	LinCangMahjong "gameServer-demo/src/logic/LinCangMahjong"
+	LineGame "gameServer-demo/src/logic/LineGame"
+	LinkUp "gameServer-demo/src/logic/LinkUp"
 	PushBobbin "gameServer-demo/src/logic/PushBobbin"
+	RedBlack "gameServer-demo/src/logic/RedBlack"
 	Robot "gameServer-demo/src/logic/Robot"
+	RunFast "gameServer-demo/src/logic/RunFast"
+	ShiSanShui "gameServer-demo/src/logic/ShiSanShui"
+	XueZhanMahjong "gameServer-demo/src/logic/XueZhanMahjong"
 )
 
 // Init 用于方便包被外部引用的函数，同时在这里引用子包
 func Init() {
 	PushBobbin.Init()
+	DragonTigerFight.Init()
+	Baccarat.Init()
+	HundredBull.Init()
+	RedBlack.Init()
+	BenzBMW.Init()
+	GemWars.Init()
+	CompareBull.Init()
+	CrazyBull.Init()
+	Jinhua.Init()
+	RunFast.Init()
+	ShiSanShui.Init()
+	XueZhanMahjong.Init()
+	LinCangMahjong.Init()
+	GangHuaMahjong.Init()
+	LinkUp.Init()
+	LineGame.Init()
+	ClearJoy.Init()
+	DaXuan.Init()
+	DoubleLinked.Init()
+	GamePlugin.Init()
 	Hall.Init()
 	Robot.Init()
 }
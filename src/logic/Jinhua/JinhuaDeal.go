@@ -0,0 +1,154 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["JinhuaDeal"] = &JinhuaDeal{}
+}
+
+// JinhuaDeal 炸金花游戏的发牌组件，用于处理定庄、下底注和发牌阶段的逻辑
+type JinhuaDeal struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *JinhuaDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *JinhuaDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 炸金花发牌阶段的主驱动
+// 根据庄家类型确定庄家，游戏中的玩家下底注，然后给每个玩家发三张牌，发的牌不推送给玩家，需要看牌才能知道
+func (obj *JinhuaDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateDeal {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStatePlay
+		request.NextRoomState = pb.RoomState_RoomStatePlay
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	dealTimeStr := common.GetRoomConfig(request, "DealTime")
+	dealTime, err := strconv.Atoi(dealTimeStr)
+	if err != nil {
+		common.LogError("JinhuaDeal Drive dealTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	msgErr := obj.initJinhuaInRoom(request)
+	if msgErr != nil {
+		return request, msgErr
+	}
+	players := getPlayPlayers(request)
+	if len(players)*handPokerNum > deckPokerNum {
+		common.LogError("JinhuaDeal Drive too many players", len(players))
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 推送房间状态 准备<->发牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateReady,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + int64(dealTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	obj.chooseBanker(request, players)
+
+	// 下底注，发牌，血池需要控制时选择合适的发牌结果
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	bloodState := common.BloodGetState(request.GetGameType(), request.GetGameScene())
+	hands := dealByControl(players, bloodState)
+	for index, onePlayer := range players {
+		onePlayer.JinhuaBets = jinhuaInRoom.GetCurrentAntes()
+		onePlayer.JinhuaTotalConsumption = jinhuaInRoom.GetCurrentAntes()
+		jinhuaInRoom.Jackpot += jinhuaInRoom.GetCurrentAntes()
+
+		onePlayer.Pokers = hands[index]
+		onePlayer.CompareJinhuaPokerType = getCompareJinhuaPokerType(onePlayer.GetPokers())
+		// 闷牌，所有人都只知道发了牌
+		pushCardChange := &pb.PushPlayerCardChange{
+			RoomId: request.GetUuid(),
+			UserId: onePlayer.GetUuid(),
+		}
+		common.RoomBroadcast(request, pushCardChange)
+	}
+
+	request.NextRoomState = pb.RoomState_RoomStatePlay
+	request.DoTime = nowTime + int64(dealTime)
+	return request, nil
+}
+
+// initJinhuaInRoom 根据房间配置初始化本局的炸金花信息
+// 激情模式的最大轮数使用PassionMaxRound，普通模式使用MaxRound
+func (obj *JinhuaDeal) initJinhuaInRoom(request *pb.RoomInfo) *pb.ErrorMessage {
+	raiseValue, msgErr := getRaiseValue(request)
+	if msgErr != nil {
+		return msgErr
+	}
+	jinhuaMode, msgErr := getRoomConfigInt64(request, "JinhuaMode")
+	if msgErr != nil {
+		return msgErr
+	}
+	bankerType, msgErr := getRoomConfigInt64(request, "BankerType")
+	if msgErr != nil {
+		return msgErr
+	}
+	maxRoundName := "MaxRound"
+	if pb.JinhuaMode(jinhuaMode) == pb.JinhuaMode_JinhuaMode_PassionMode {
+		maxRoundName = "PassionMaxRound"
+	}
+	maxRound, msgErr := getRoomConfigInt64(request, maxRoundName)
+	if msgErr != nil {
+		return msgErr
+	}
+
+	request.JinhuaInRoom = &pb.JinhuaInRoom{
+		LastWinnerUuid:   request.GetJinhuaInRoom().GetLastWinnerUuid(),
+		CurrentAntes:     int64(raiseValue[0]),
+		CurrentRaiseType: pb.JinhuaRaiseType_JinhuaRaiseType_Level1,
+		JinhuaMode:       pb.JinhuaMode(jinhuaMode),
+		BankerType:       pb.JinhuaBankerType(bankerType),
+		MaxRound:         int32(maxRound),
+		PlayerRounds:     make([]int32, len(request.GetPlayerInfo())),
+		RaiseValue:       raiseValue,
+		WaitType:         pb.JinhuaWaitOperateType_JinhuaWaitOperateType_None,
+	}
+	return nil
+}
+
+// chooseBanker 根据庄家类型确定庄家
+// 赢家坐庄：上一局的赢家还在游戏中就由他坐庄，否则随机；轮流坐庄：上一局庄家的下一家坐庄；随机坐庄
+func (obj *JinhuaDeal) chooseBanker(request *pb.RoomInfo, players []*pb.RoomPlayerInfo) {
+	bankerIndex := int32(-1)
+	switch request.GetJinhuaInRoom().GetBankerType() {
+	case pb.JinhuaBankerType_JinhuaBankerType_Winner:
+		winnerIndex := getPlayerIndex(request, request.GetJinhuaInRoom().GetLastWinnerUuid())
+		if winnerIndex >= 0 && isActive(request.GetPlayerInfo()[winnerIndex]) {
+			bankerIndex = winnerIndex
+		}
+	case pb.JinhuaBankerType_JinhuaBankerType_ByTurn:
+		bankerIndex = getNextActiveIndex(request, int32(request.GetBankerIndex()))
+	}
+	if bankerIndex < 0 {
+		banker := players[common.GetRandomNum(0, len(players)-1)]
+		bankerIndex = getPlayerIndex(request, banker.GetUuid())
+	}
+	request.BankerIndex = int64(bankerIndex)
+	request.DoIndex = bankerIndex
+}
@@ -0,0 +1,561 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["JinhuaPlay"] = &JinhuaPlay{}
+}
+
+// JinhuaPlay 炸金花游戏的玩耍组件，用于处理玩家轮流下注、看牌、比牌阶段的逻辑
+type JinhuaPlay struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *JinhuaPlay) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *JinhuaPlay) Start() {
+	obj.Base.Start()
+}
+
+// Drive 炸金花玩耍阶段的主驱动
+// 玩家按座位顺序轮流操作，轮次的时间都由毫秒级的MilliDoTime定时：
+// WaitType为Player时等待当前玩家操作，超时按弃牌处理；WaitType为Server时等待客户端播放操作动画，然后轮到下一个玩家
+// 只剩一个玩家或者达到最大轮数由系统比牌后，本局结束进入结算
+func (obj *JinhuaPlay) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	nowMilliTime := time.Now().UnixNano() / 1e6
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	if request.NextRoomState == pb.RoomState_RoomStatePlay {
+		// 推送房间状态 发牌<->玩耍
+		pushRoomState := &pb.PushRoomStateChange{
+			RoomId:      request.GetUuid(),
+			BeforeState: pb.RoomState_RoomStateDeal,
+			AfterState:  pb.RoomState_RoomStatePlay,
+		}
+		common.RoomBroadcast(request, pushRoomState)
+
+		jinhuaInRoom.Running = true
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		// 庄家的下一家先操作
+		msgErr := obj.nextTurn(request, nowMilliTime)
+		return request, msgErr
+	}
+
+	if nowMilliTime < request.GetMilliDoTime() {
+		return request, nil
+	}
+	if jinhuaInRoom.GetJinhuaGameOver() {
+		request.CurRoomState = pb.RoomState_RoomStateSettle
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		request.DoTime = nowTime
+		return request, nil
+	}
+	if jinhuaInRoom.GetWaitType() == pb.JinhuaWaitOperateType_JinhuaWaitOperateType_Player {
+		// 操作超时按弃牌处理
+		curPlayer := request.GetPlayerInfo()[request.GetDoIndex()]
+		if isActive(curPlayer) {
+			obj.fold(request, curPlayer)
+		}
+		msgErr := obj.endTurn(request, nowMilliTime, "OperateIntervalMilli")
+		return request, msgErr
+	}
+	msgErr := obj.nextTurn(request, nowMilliTime)
+	return request, msgErr
+}
+
+// nextTurn 轮到下一个没有出局的玩家操作，只剩一个玩家时本局结束
+func (obj *JinhuaPlay) nextTurn(request *pb.RoomInfo, nowMilliTime int64) *pb.ErrorMessage {
+	if len(getActivePlayers(request)) <= 1 {
+		request.GetJinhuaInRoom().JinhuaGameOver = true
+		request.MilliDoTime = nowMilliTime
+		return nil
+	}
+	return obj.startTurn(request, getNextActiveIndex(request, request.GetDoIndex()), nowMilliTime)
+}
+
+// startTurn 开始座位index的玩家的回合
+// 玩家已经达到最大轮数时系统比牌结束本局；断线或者已经退出的玩家直接弃牌；自动跟注的玩家直接跟注
+func (obj *JinhuaPlay) startTurn(request *pb.RoomInfo, index int32, nowMilliTime int64) *pb.ErrorMessage {
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	if jinhuaInRoom.GetPlayerRounds()[index] >= jinhuaInRoom.GetMaxRound() {
+		obj.compareAll(request)
+		return obj.endTurn(request, nowMilliTime, "CompareIntervalMilli")
+	}
+
+	onePlayer := request.GetPlayerInfo()[index]
+	request.DoIndex = index
+	jinhuaInRoom.CurPlayerUuid = onePlayer.GetUuid()
+	jinhuaInRoom.CurrentPlayerOperated = false
+	jinhuaInRoom.PlayerRounds[index]++
+	onePlayer.JinhuaRound++
+	jinhuaInRoom.WaitType = pb.JinhuaWaitOperateType_JinhuaWaitOperateType_Player
+	jinhuaInRoom.CanOperateTypes = obj.getCanOperateTypes(request, onePlayer)
+
+	if onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None || !isOnline(onePlayer) {
+		obj.fold(request, onePlayer)
+		return obj.endTurn(request, nowMilliTime, "OperateIntervalMilli")
+	}
+	// 自动跟注只跟到设置时的加注等级，有人加注超过了就取消自动跟注
+	if onePlayer.GetJinhuaIsCallingStation() {
+		if jinhuaInRoom.GetCurrentRaiseType() <= onePlayer.GetJinhuaCallingStationRaiseType() &&
+			containsOperateType(jinhuaInRoom.GetCanOperateTypes(), pb.JinhuaOperateType_JinhuaOperateType_Call) {
+			obj.bet(request, onePlayer, getCallCost(request, onePlayer), pb.JinhuaOperateType_JinhuaOperateType_Call)
+			return obj.endTurn(request, nowMilliTime, "OperateIntervalMilli")
+		}
+		obj.setCallingStation(request, onePlayer, false)
+	}
+
+	operateTime, msgErr := getRoomConfigInt64(request, "OperateTime")
+	if msgErr != nil {
+		return msgErr
+	}
+	request.MilliDoTime = nowMilliTime + operateTime*1000
+	request.DoTime = nowMilliTime/1000 + operateTime
+	obj.pushReqOperate(request)
+	return nil
+}
+
+// endTurn 结束当前玩家的回合，等待intervalName配置的毫秒数（客户端播放动画）后轮到下一个玩家
+func (obj *JinhuaPlay) endTurn(request *pb.RoomInfo, nowMilliTime int64, intervalName string) *pb.ErrorMessage {
+	interval, msgErr := getRoomConfigInt64(request, intervalName)
+	if msgErr != nil {
+		return msgErr
+	}
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	jinhuaInRoom.CurrentPlayerOperated = true
+	jinhuaInRoom.WaitType = pb.JinhuaWaitOperateType_JinhuaWaitOperateType_Server
+	jinhuaInRoom.CanOperateTypes = nil
+	if len(getActivePlayers(request)) <= 1 {
+		jinhuaInRoom.JinhuaGameOver = true
+	}
+	request.MilliDoTime = nowMilliTime + interval
+	return nil
+}
+
+// pushReqOperate 通知房间内的玩家当前轮到谁操作以及可以做的操作
+func (obj *JinhuaPlay) pushReqOperate(request *pb.RoomInfo) {
+	pushReqOperate := &pb.PushPlayerJinhuaReqOperate{
+		DoIndex:        request.GetDoIndex(),
+		CanOperateType: request.GetJinhuaInRoom().GetCanOperateTypes(),
+		EndTime:        request.GetDoTime(),
+		RoomId:         request.GetUuid(),
+	}
+	common.RoomBroadcast(request, pushReqOperate)
+}
+
+// getCanOperateTypes 获取轮到操作的玩家可以做的操作
+// 对手全押后只能全押或者弃牌；不够跟注时只能孤注一掷或者弃牌；只剩两个玩家时才能全押
+func (obj *JinhuaPlay) getCanOperateTypes(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) []pb.JinhuaOperateType {
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	canOperateTypes := []pb.JinhuaOperateType{pb.JinhuaOperateType_JinhuaOperateType_Fold}
+	if obj.canCheck(request, onePlayer) {
+		canOperateTypes = append(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_Check)
+	}
+	if len(jinhuaInRoom.GetAllInUuidSequence()) > 0 {
+		return append(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_AllIn)
+	}
+	availableBalance := getAvailableBalance(onePlayer)
+	if availableBalance < getCallCost(request, onePlayer) {
+		return append(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_LastThrow)
+	}
+	canOperateTypes = append(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_Call)
+	if onePlayer.GetJinhuaIsCallingStation() {
+		canOperateTypes = append(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_CancelCallingStation)
+	} else {
+		canOperateTypes = append(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_CallingStation)
+	}
+	nextRaiseType := jinhuaInRoom.GetCurrentRaiseType() + 1
+	if nextRaiseType <= pb.JinhuaRaiseType_JinhuaRaiseType_LevelMax && availableBalance >= obj.getRaiseCost(request, onePlayer, nextRaiseType) {
+		canOperateTypes = append(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_Raise)
+	}
+	if obj.canCompare(request, onePlayer) {
+		canOperateTypes = append(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_Compare)
+	}
+	if len(getActivePlayers(request)) == 2 {
+		canOperateTypes = append(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_AllIn)
+	}
+	return canOperateTypes
+}
+
+// canCheck 玩家是否可以看牌，普通模式下前MustBlindRound轮必须闷牌
+func (obj *JinhuaPlay) canCheck(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) bool {
+	if onePlayer.GetJinhuaIsChecked() {
+		return false
+	}
+	if request.GetJinhuaInRoom().GetJinhuaMode() == pb.JinhuaMode_JinhuaMode_PassionMode {
+		return true
+	}
+	mustBlindRound, msgErr := getRoomConfigInt64(request, "MustBlindRound")
+	if msgErr != nil {
+		return false
+	}
+	return int64(onePlayer.GetJinhuaRound()) > mustBlindRound
+}
+
+// canCompare 玩家是否可以发起比牌，普通模式下第CompareMinRound轮开始才能比牌，激情模式第一轮就可以比牌
+func (obj *JinhuaPlay) canCompare(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) bool {
+	if request.GetJinhuaInRoom().GetJinhuaMode() == pb.JinhuaMode_JinhuaMode_PassionMode {
+		return true
+	}
+	compareMinRound, msgErr := getRoomConfigInt64(request, "CompareMinRound")
+	if msgErr != nil {
+		return false
+	}
+	return int64(onePlayer.GetJinhuaRound()) >= compareMinRound
+}
+
+// getRaiseCost 玩家加注到raiseType等级需要的金额，看过牌的玩家需要下双倍
+func (obj *JinhuaPlay) getRaiseCost(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, raiseType pb.JinhuaRaiseType) int64 {
+	cost := int64(request.GetJinhuaInRoom().GetRaiseValue()[raiseType-1])
+	if onePlayer.GetJinhuaIsChecked() {
+		cost *= 2
+	}
+	return cost
+}
+
+// containsOperateType 操作列表中是否包含某个操作
+func containsOperateType(operateTypes []pb.JinhuaOperateType, operateType pb.JinhuaOperateType) bool {
+	for _, oneType := range operateTypes {
+		if oneType == operateType {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcastOperate 记录玩家的操作并广播，amount是本次操作的下注金额
+func (obj *JinhuaPlay) broadcastOperate(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, operateType pb.JinhuaOperateType, amount int64) {
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	jinhuaInRoom.LastPlayerUuid = onePlayer.GetUuid()
+	jinhuaInRoom.LastPlayerOperateType = operateType
+	pushOperate := &pb.JinhuaOperateBroadcast{
+		OperateType:            operateType,
+		DoUuid:                 onePlayer.GetUuid(),
+		DoIndex:                getPlayerIndex(request, onePlayer.GetUuid()),
+		CurrentAntes:           jinhuaInRoom.GetCurrentAntes(),
+		PlayerBets:             amount,
+		PlayerTotalBets:        onePlayer.GetJinhuaBets(),
+		PlayerTotalConsumption: onePlayer.GetJinhuaTotalConsumption(),
+		CurrentRaiseType:       jinhuaInRoom.GetCurrentRaiseType(),
+		RoomId:                 request.GetUuid(),
+		RoomJackpot:            jinhuaInRoom.GetJackpot(),
+		CurrentPlayerCallRound: onePlayer.GetJinhuaRound(),
+	}
+	common.RoomBroadcast(request, pushOperate)
+}
+
+// bet 玩家下注并广播，下注的金额在结算时才从玩家身上扣除
+func (obj *JinhuaPlay) bet(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, amount int64, operateType pb.JinhuaOperateType) {
+	onePlayer.JinhuaBets += amount
+	onePlayer.JinhuaTotalConsumption += amount
+	onePlayer.JinhuaCallRound++
+	request.GetJinhuaInRoom().Jackpot += amount
+	obj.broadcastOperate(request, onePlayer, operateType, amount)
+}
+
+// fold 玩家弃牌出局
+func (obj *JinhuaPlay) fold(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) {
+	onePlayer.JinhuaOutType = pb.JinhuaOutType_JinhuaOutType_Fold
+	onePlayer.JinhuaIsCallingStation = false
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	jinhuaInRoom.OutPlayerUuids = append(jinhuaInRoom.OutPlayerUuids, onePlayer.GetUuid())
+	obj.broadcastOperate(request, onePlayer, pb.JinhuaOperateType_JinhuaOperateType_Fold, 0)
+}
+
+// check 玩家看牌，手牌和牌型只推送给自己，其他人只知道他看了牌
+func (obj *JinhuaPlay) check(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, rubbingCards bool) {
+	onePlayer.JinhuaIsChecked = true
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	jinhuaInRoom.LastPlayerUuid = onePlayer.GetUuid()
+	jinhuaInRoom.LastPlayerOperateType = pb.JinhuaOperateType_JinhuaOperateType_Check
+	pushToOthers := &pb.JinhuaOperateBroadcast{
+		OperateType:            pb.JinhuaOperateType_JinhuaOperateType_Check,
+		DoUuid:                 onePlayer.GetUuid(),
+		DoIndex:                getPlayerIndex(request, onePlayer.GetUuid()),
+		CurrentAntes:           jinhuaInRoom.GetCurrentAntes(),
+		PlayerTotalBets:        onePlayer.GetJinhuaBets(),
+		PlayerTotalConsumption: onePlayer.GetJinhuaTotalConsumption(),
+		CurrentRaiseType:       jinhuaInRoom.GetCurrentRaiseType(),
+		RoomId:                 request.GetUuid(),
+		RoomJackpot:            jinhuaInRoom.GetJackpot(),
+		CurrentPlayerCallRound: onePlayer.GetJinhuaRound(),
+		RubbingCards:           rubbingCards,
+	}
+	pushToSelf := proto.Clone(pushToOthers).(*pb.JinhuaOperateBroadcast)
+	pushToSelf.Pokers = onePlayer.GetPokers()
+	pushToSelf.PokerType = onePlayer.GetCompareJinhuaPokerType().GetJinhuaPokerType()
+	msgErr := common.PushRoom(pushToSelf, pushToOthers, onePlayer.GetUuid(), request)
+	if msgErr != nil {
+		common.LogError("JinhuaPlay check PushRoom has err", onePlayer.GetUuid(), msgErr)
+	}
+}
+
+// setCallingStation 设置或者取消玩家的自动跟注并广播
+func (obj *JinhuaPlay) setCallingStation(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, isCallingStation bool) {
+	onePlayer.JinhuaIsCallingStation = isCallingStation
+	operateType := pb.JinhuaOperateType_JinhuaOperateType_CancelCallingStation
+	if isCallingStation {
+		onePlayer.JinhuaCallingStationRaiseType = request.GetJinhuaInRoom().GetCurrentRaiseType()
+		operateType = pb.JinhuaOperateType_JinhuaOperateType_CallingStation
+	}
+	obj.broadcastOperate(request, onePlayer, operateType, 0)
+}
+
+// compare 玩家发起比牌，需要比所有目标玩家都大才算赢，一样大时发起者输
+// 发起者赢了目标玩家都出局，否则发起者出局
+func (obj *JinhuaPlay) compare(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, targets []*pb.RoomPlayerInfo, isLastThrow bool) {
+	isWin := true
+	for _, target := range targets {
+		if !isBigger(onePlayer, target) {
+			isWin = false
+			break
+		}
+	}
+	winners, losers := []*pb.RoomPlayerInfo{onePlayer}, targets
+	if !isWin {
+		winners, losers = targets, []*pb.RoomPlayerInfo{onePlayer}
+	}
+	obj.compareOut(request, onePlayer, winners, losers, isLastThrow)
+}
+
+// compareAll 达到最大轮数时系统让所有没出局的玩家比牌，最大的玩家赢，一样大时座位靠前的赢
+func (obj *JinhuaPlay) compareAll(request *pb.RoomInfo) {
+	activePlayers := getActivePlayers(request)
+	winnerIndex := 0
+	for index, onePlayer := range activePlayers {
+		if isBigger(onePlayer, activePlayers[winnerIndex]) {
+			winnerIndex = index
+		}
+	}
+	var losers []*pb.RoomPlayerInfo
+	for index, onePlayer := range activePlayers {
+		if index != winnerIndex {
+			losers = append(losers, onePlayer)
+		}
+	}
+	obj.compareOut(request, nil, []*pb.RoomPlayerInfo{activePlayers[winnerIndex]}, losers, false)
+}
+
+// compareOut 比牌输的玩家出局并广播比牌结果，doPlayer为空表示系统比牌
+func (obj *JinhuaPlay) compareOut(request *pb.RoomInfo, doPlayer *pb.RoomPlayerInfo, winners []*pb.RoomPlayerInfo, losers []*pb.RoomPlayerInfo, isLastThrow bool) {
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	pushCompareResult := &pb.PushJinhuaCompareResult{
+		RoomId:                 request.GetUuid(),
+		DoUuid:                 doPlayer.GetUuid(),
+		DoIndex:                getPlayerIndex(request, doPlayer.GetUuid()),
+		CurrentAntes:           jinhuaInRoom.GetCurrentAntes(),
+		PlayerTotalBets:        doPlayer.GetJinhuaBets(),
+		PlayerTotalConsumption: doPlayer.GetJinhuaTotalConsumption(),
+		RoomJackpot:            jinhuaInRoom.GetJackpot(),
+		CurrentPlayerCallRound: doPlayer.GetJinhuaRound(),
+		IsLastThrow:            isLastThrow,
+	}
+	for _, winner := range winners {
+		pushCompareResult.WinnerUuids = append(pushCompareResult.WinnerUuids, winner.GetUuid())
+	}
+	for _, loser := range losers {
+		loser.JinhuaOutType = pb.JinhuaOutType_JinhuaOutType_Compare
+		loser.JinhuaIsCallingStation = false
+		jinhuaInRoom.OutPlayerUuids = append(jinhuaInRoom.OutPlayerUuids, loser.GetUuid())
+		pushCompareResult.LoserUuids = append(pushCompareResult.LoserUuids, loser.GetUuid())
+	}
+	common.RoomBroadcast(request, pushCompareResult)
+}
+
+// allIn 玩家全押，只剩两个玩家时才能全押，金额是两个玩家可下注金额中较少的一个
+// 第一个全押的玩家的对手只能全押或者弃牌，对手也全押后两人直接比牌
+// 返回值：是否进行了比牌
+func (obj *JinhuaPlay) allIn(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) bool {
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	var amount int64
+	var firstAllIn *pb.RoomPlayerInfo
+	if len(jinhuaInRoom.GetAllInUuidSequence()) == 0 {
+		amount = getAvailableBalance(onePlayer)
+		for _, otherPlayer := range getActivePlayers(request) {
+			if otherPlayer.GetUuid() != onePlayer.GetUuid() && getAvailableBalance(otherPlayer) < amount {
+				amount = getAvailableBalance(otherPlayer)
+			}
+		}
+	} else {
+		firstAllIn = common.GetRoomPlayerInfo(request, jinhuaInRoom.GetAllInUuidSequence()[0])
+		amount = firstAllIn.GetJinhuaJackpot()
+	}
+	onePlayer.IsAllIn = true
+	onePlayer.IsJinhuaStopAddJackpot = true
+	onePlayer.JinhuaJackpot = amount
+	jinhuaInRoom.AllInUuidSequence = append(jinhuaInRoom.AllInUuidSequence, onePlayer.GetUuid())
+	obj.bet(request, onePlayer, amount, pb.JinhuaOperateType_JinhuaOperateType_AllIn)
+	if firstAllIn == nil {
+		return false
+	}
+	obj.compare(request, firstAllIn, []*pb.RoomPlayerInfo{onePlayer}, false)
+	return true
+}
+
+// turnOperate 轮到操作的玩家进行跟注、加注、比牌、全押、孤注一掷，操作后结束玩家的回合
+func (obj *JinhuaPlay) turnOperate(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, realRequest *pb.JinhuaOperateRequest, nowMilliTime int64) *pb.ErrorMessage {
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	switch realRequest.GetOperateType() {
+	case pb.JinhuaOperateType_JinhuaOperateType_Call:
+		obj.bet(request, onePlayer, getCallCost(request, onePlayer), pb.JinhuaOperateType_JinhuaOperateType_Call)
+		return obj.endTurn(request, nowMilliTime, "OperateIntervalMilli")
+	case pb.JinhuaOperateType_JinhuaOperateType_Raise:
+		raiseType := realRequest.GetRaiseType()
+		if raiseType <= jinhuaInRoom.GetCurrentRaiseType() || raiseType > pb.JinhuaRaiseType_JinhuaRaiseType_LevelMax {
+			return common.GetGrpcErrorMessage(pb.ErrorCode_JinhuaErrCodeErrorRaiseType, "")
+		}
+		cost := obj.getRaiseCost(request, onePlayer, raiseType)
+		if cost > getAvailableBalance(onePlayer) {
+			return common.GetGrpcErrorMessage(pb.ErrorCode_JinhuaErrCodeHasCallGreaterThanMax, "")
+		}
+		jinhuaInRoom.CurrentRaiseType = raiseType
+		jinhuaInRoom.CurrentAntes = int64(jinhuaInRoom.GetRaiseValue()[raiseType-1])
+		obj.bet(request, onePlayer, cost, pb.JinhuaOperateType_JinhuaOperateType_Raise)
+		return obj.endTurn(request, nowMilliTime, "OperateIntervalMilli")
+	case pb.JinhuaOperateType_JinhuaOperateType_Compare:
+		target := common.GetRoomPlayerInfo(request, realRequest.GetCompareUuid())
+		if target == nil || target.GetUuid() == onePlayer.GetUuid() || !isActive(target) {
+			return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidParameters, "")
+		}
+		obj.bet(request, onePlayer, getCallCost(request, onePlayer), pb.JinhuaOperateType_JinhuaOperateType_Compare)
+		obj.compare(request, onePlayer, []*pb.RoomPlayerInfo{target}, false)
+		return obj.endTurn(request, nowMilliTime, "CompareIntervalMilli")
+	case pb.JinhuaOperateType_JinhuaOperateType_AllIn:
+		if obj.allIn(request, onePlayer) {
+			return obj.endTurn(request, nowMilliTime, "CompareIntervalMilli")
+		}
+		return obj.endTurn(request, nowMilliTime, "OperateIntervalMilli")
+	case pb.JinhuaOperateType_JinhuaOperateType_LastThrow:
+		var targets []*pb.RoomPlayerInfo
+		for _, otherPlayer := range getActivePlayers(request) {
+			if otherPlayer.GetUuid() != onePlayer.GetUuid() {
+				targets = append(targets, otherPlayer)
+			}
+		}
+		obj.bet(request, onePlayer, getAvailableBalance(onePlayer), pb.JinhuaOperateType_JinhuaOperateType_LastThrow)
+		obj.compare(request, onePlayer, targets, true)
+		return obj.endTurn(request, nowMilliTime, "CompareIntervalMilli")
+	}
+	return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+}
+
+// RequestOperate 玩家在游戏中的操作
+// 弃牌、看牌、设置和取消自动跟注任何时候都可以操作，其他操作只有轮到自己并且在可操作列表中才可以
+func (obj *JinhuaPlay) RequestOperate(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	jinhuaInRoom := roomInfo.GetJinhuaInRoom()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || roomInfo.GetNextRoomState() == pb.RoomState_RoomStatePlay || jinhuaInRoom.GetJinhuaGameOver() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.JinhuaOperateRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("JinhuaPlay RequestOperate ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("JinhuaPlay RequestOperate player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if !isActive(playerInfo) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_JinhuaErrCodePlayerHaveNoPermissionsOpt, "")
+	}
+
+	nowMilliTime := time.Now().UnixNano() / 1e6
+	isTurn := jinhuaInRoom.GetWaitType() == pb.JinhuaWaitOperateType_JinhuaWaitOperateType_Player && jinhuaInRoom.GetCurPlayerUuid() == uid
+	var msgErr *pb.ErrorMessage
+	switch realRequest.GetOperateType() {
+	case pb.JinhuaOperateType_JinhuaOperateType_Fold:
+		obj.fold(roomInfo, playerInfo)
+		if isTurn || len(getActivePlayers(roomInfo)) <= 1 {
+			msgErr = obj.endTurn(roomInfo, nowMilliTime, "OperateIntervalMilli")
+		}
+	case pb.JinhuaOperateType_JinhuaOperateType_Check:
+		if !obj.canCheck(roomInfo, playerInfo) {
+			return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+		}
+		obj.check(roomInfo, playerInfo, realRequest.GetRubbingCards())
+		// 看牌后跟注的金额变了，重新通知可以做的操作
+		if isTurn {
+			jinhuaInRoom.CanOperateTypes = obj.getCanOperateTypes(roomInfo, playerInfo)
+			obj.pushReqOperate(roomInfo)
+		}
+	case pb.JinhuaOperateType_JinhuaOperateType_CallingStation:
+		if playerInfo.GetJinhuaIsCallingStation() {
+			return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+		}
+		obj.setCallingStation(roomInfo, playerInfo, true)
+		// 轮到自己时设置自动跟注，本轮直接跟注
+		if isTurn && containsOperateType(jinhuaInRoom.GetCanOperateTypes(), pb.JinhuaOperateType_JinhuaOperateType_Call) {
+			obj.bet(roomInfo, playerInfo, getCallCost(roomInfo, playerInfo), pb.JinhuaOperateType_JinhuaOperateType_Call)
+			msgErr = obj.endTurn(roomInfo, nowMilliTime, "OperateIntervalMilli")
+		}
+	case pb.JinhuaOperateType_JinhuaOperateType_CancelCallingStation:
+		if !playerInfo.GetJinhuaIsCallingStation() {
+			return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+		}
+		obj.setCallingStation(roomInfo, playerInfo, false)
+	default:
+		if !isTurn {
+			return reply, common.GetGrpcErrorMessage(pb.ErrorCode_JinhuaErrCodePlayerHaveNoPermissionsOpt, "")
+		}
+		if !containsOperateType(jinhuaInRoom.GetCanOperateTypes(), realRequest.GetOperateType()) {
+			return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+		}
+		msgErr = obj.turnOperate(roomInfo, playerInfo, realRequest, nowMilliTime)
+	}
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return packReply(roomInfo, &pb.JinhuaOperateReply{
+		OperateType: realRequest.GetOperateType(),
+		DoUuid:      uid,
+	})
+}
+
+// RequestExitInGame 玩家在对局中退出房间
+// 玩耍阶段还没出局的玩家直接弃牌，发牌阶段退出的玩家轮到他时弃牌
+// 这里只标记为等待踢出，结算后状态置空由房间的Kick踢出
+func (obj *JinhuaPlay) RequestExitInGame(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	playerInfo.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_Exit
+	jinhuaInRoom := roomInfo.GetJinhuaInRoom()
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStatePlay && roomInfo.GetNextRoomState() != pb.RoomState_RoomStatePlay &&
+		!jinhuaInRoom.GetJinhuaGameOver() && isActive(playerInfo) {
+		isTurn := jinhuaInRoom.GetWaitType() == pb.JinhuaWaitOperateType_JinhuaWaitOperateType_Player && jinhuaInRoom.GetCurPlayerUuid() == uid
+		obj.fold(roomInfo, playerInfo)
+		if isTurn || len(getActivePlayers(roomInfo)) <= 1 {
+			msgErr := obj.endTurn(roomInfo, time.Now().UnixNano()/1e6, "OperateIntervalMilli")
+			if msgErr != nil {
+				return reply, msgErr
+			}
+		}
+	}
+	// 结算阶段本局已经结算完了，可以直接踢出
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStateSettle && roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+	}
+	return packReply(roomInfo, &pb.GameExitRoomReply{})
+}
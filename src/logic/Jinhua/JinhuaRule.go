@@ -0,0 +1,242 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 每个玩家的手牌张数
+const handPokerNum = 3
+
+// 一副牌（不含大小王）的张数，所有玩家的手牌都从一副牌中发出
+const deckPokerNum = 52
+
+// 血池控制时最多尝试的发牌次数
+const controlTryNum = 30
+
+// getRoomConfigInt64 获取房间的整数配置
+func getRoomConfigInt64(roomInfo *pb.RoomInfo, configName string) (int64, *pb.ErrorMessage) {
+	configStr := common.GetRoomConfig(roomInfo, configName)
+	configNum, err := strconv.ParseInt(configStr, 10, 64)
+	if err != nil {
+		common.LogError("Jinhua getRoomConfigInt64 has err", configName, configStr, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return configNum, nil
+}
+
+// getRaiseValue 获取房间的加注等级对应的下注值，配置RaiseValue依次对应Level1到LevelMax，第一个值同时是底注
+func getRaiseValue(roomInfo *pb.RoomInfo) ([]int32, *pb.ErrorMessage) {
+	raiseValueStr := common.GetRoomConfig(roomInfo, "RaiseValue")
+	var raiseValue []int32
+	for _, oneValueStr := range strings.Split(raiseValueStr, ",") {
+		oneValue, err := strconv.Atoi(oneValueStr)
+		if err != nil || oneValue <= 0 {
+			common.LogError("Jinhua getRaiseValue has err", raiseValueStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		raiseValue = append(raiseValue, int32(oneValue))
+	}
+	if len(raiseValue) != int(pb.JinhuaRaiseType_JinhuaRaiseType_LevelMax) {
+		common.LogError("Jinhua getRaiseValue length has err", raiseValueStr)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return raiseValue, nil
+}
+
+// getJinhuaPokerValue 获取单张牌比大小时的点数，A最大
+func getJinhuaPokerValue(poker *pb.Poker) int {
+	if poker.GetPokerNum() == pb.PokerNum_PokerNum1 {
+		return 14
+	}
+	return int(poker.GetPokerNum())
+}
+
+// getCompareJinhuaPokerType 获取三张牌的牌型和得分
+// 牌型大小：豹子>同花顺>同花>顺子>对子>单张，A23是最小的顺子
+// 得分先比牌型，再依次比点数，对子先比对子的点数再比单张
+func getCompareJinhuaPokerType(pokers []*pb.Poker) *pb.CompareJinhuaPokerType {
+	if len(pokers) != handPokerNum {
+		return &pb.CompareJinhuaPokerType{JinhuaPokerType: pb.JinhuaPokerType_JinhuaCardType_None}
+	}
+	values := make([]int, 0, handPokerNum)
+	isFlush := true
+	for _, poker := range pokers {
+		values = append(values, getJinhuaPokerValue(poker))
+		if poker.GetPokerColor() != pokers[0].GetPokerColor() {
+			isFlush = false
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(values)))
+	// A23按照321计算
+	if values[0] == 14 && values[1] == 3 && values[2] == 2 {
+		values = []int{3, 2, 1}
+	}
+	isStraight := values[0]-values[1] == 1 && values[1]-values[2] == 1
+
+	var pokerType pb.JinhuaPokerType
+	switch {
+	case values[0] == values[2]:
+		pokerType = pb.JinhuaPokerType_JinhuaCardType_Trip
+	case isStraight && isFlush:
+		pokerType = pb.JinhuaPokerType_JinhuaCardType_StraightFlush
+	case isFlush:
+		pokerType = pb.JinhuaPokerType_JinhuaCardType_Flush
+	case isStraight:
+		pokerType = pb.JinhuaPokerType_JinhuaCardType_Straight
+	case values[0] == values[1]:
+		pokerType = pb.JinhuaPokerType_JinhuaCardType_Pair
+	case values[1] == values[2]:
+		pokerType = pb.JinhuaPokerType_JinhuaCardType_Pair
+		values = []int{values[1], values[2], values[0]}
+	default:
+		pokerType = pb.JinhuaPokerType_JinhuaCardType_Single
+	}
+
+	score := (7 - int(pokerType)) * 1000000
+	score += values[0]*10000 + values[1]*100 + values[2]
+	maxPokerNum := pb.PokerNum(values[0])
+	if values[0] == 14 {
+		maxPokerNum = pb.PokerNum_PokerNum1
+	}
+	return &pb.CompareJinhuaPokerType{
+		JinhuaPokerType: pokerType,
+		PokerScore:      float32(score),
+		MaxPokerNum:     maxPokerNum,
+	}
+}
+
+// isBigger 判断a玩家的手牌是否比b玩家大，一样大时返回false
+func isBigger(a *pb.RoomPlayerInfo, b *pb.RoomPlayerInfo) bool {
+	return a.GetCompareJinhuaPokerType().GetPokerScore() > b.GetCompareJinhuaPokerType().GetPokerScore()
+}
+
+// getPlayPlayers 获取本局参与游戏的玩家
+func getPlayPlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+			continue
+		}
+		players = append(players, onePlayer)
+	}
+	return players
+}
+
+// isActive 玩家是否还在本局中（没有弃牌也没有比牌输掉）
+func isActive(onePlayer *pb.RoomPlayerInfo) bool {
+	return onePlayer.GetUuid() != "" &&
+		onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay &&
+		onePlayer.GetJinhuaOutType() == pb.JinhuaOutType_JinhuaOutType_None
+}
+
+// getActivePlayers 获取本局还没有出局的玩家
+func getActivePlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if isActive(onePlayer) {
+			players = append(players, onePlayer)
+		}
+	}
+	return players
+}
+
+// getPlayerIndex 获取玩家的座位下标，不在房间中返回-1
+func getPlayerIndex(roomInfo *pb.RoomInfo, uuid string) int32 {
+	for index, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() != "" && onePlayer.GetUuid() == uuid {
+			return int32(index)
+		}
+	}
+	return -1
+}
+
+// getNextActiveIndex 获取座位index之后下一个没有出局的玩家的座位下标，没有返回-1
+func getNextActiveIndex(roomInfo *pb.RoomInfo, index int32) int32 {
+	playerNum := int32(len(roomInfo.GetPlayerInfo()))
+	for step := int32(1); step <= playerNum; step++ {
+		nextIndex := (index + step) % playerNum
+		if isActive(roomInfo.GetPlayerInfo()[nextIndex]) {
+			return nextIndex
+		}
+	}
+	return -1
+}
+
+// isOnline 玩家是否在线
+func isOnline(onePlayer *pb.RoomPlayerInfo) bool {
+	online, msgErr := common.Pusher.CheckOnline(onePlayer.GetUuid())
+	if msgErr != nil {
+		common.LogError("Jinhua isOnline CheckOnline has err", onePlayer.GetUuid(), msgErr)
+		return false
+	}
+	return online
+}
+
+// getAvailableBalance 玩家本局还可以下注的金额，本局的下注在结算时才扣除
+func getAvailableBalance(onePlayer *pb.RoomPlayerInfo) int64 {
+	return onePlayer.GetBalance() - onePlayer.GetJinhuaBets()
+}
+
+// getCallCost 玩家跟注需要的金额，看过牌的玩家需要下双倍
+func getCallCost(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) int64 {
+	cost := roomInfo.GetJinhuaInRoom().GetCurrentAntes()
+	if onePlayer.GetJinhuaIsChecked() {
+		cost *= 2
+	}
+	return cost
+}
+
+// dealHands 从一副洗好的牌中给每个玩家发三张牌
+func dealHands(playerNum int) [][]*pb.Poker {
+	cardHeap := common.GetShufflePokerHeap(1)
+	hands := make([][]*pb.Poker, playerNum)
+	for index := 0; index < playerNum; index++ {
+		hands[index] = cardHeap[index*handPokerNum : (index+1)*handPokerNum]
+	}
+	return hands
+}
+
+// isControlMatch 发牌结果是否满足血池控制：
+// 平台需要赢时最大的牌在机器人手上，平台需要输时最大的牌在真实玩家手上
+func isControlMatch(players []*pb.RoomPlayerInfo, hands [][]*pb.Poker, bloodState pb.BloodSlotStatus) bool {
+	maxIndex := 0
+	var maxScore float32
+	for index, hand := range hands {
+		score := getCompareJinhuaPokerType(hand).GetPokerScore()
+		if score > maxScore {
+			maxIndex = index
+			maxScore = score
+		}
+	}
+	if bloodState == pb.BloodSlotStatus_BloodSlotStatus_Win {
+		return players[maxIndex].GetIsRobot()
+	}
+	return !players[maxIndex].GetIsRobot()
+}
+
+// dealByControl 根据血池状态给参与游戏的玩家发牌
+// 不控制或者只有机器人、只有真实玩家时直接发牌，控制时多次洗牌发牌，直到最大的牌落到需要的一方
+// 返回值：与玩家一一对应的手牌
+func dealByControl(players []*pb.RoomPlayerInfo, bloodState pb.BloodSlotStatus) [][]*pb.Poker {
+	hands := dealHands(len(players))
+	if bloodState != pb.BloodSlotStatus_BloodSlotStatus_Win && bloodState != pb.BloodSlotStatus_BloodSlotStatus_Lose {
+		return hands
+	}
+	robotNum := 0
+	for _, onePlayer := range players {
+		if onePlayer.GetIsRobot() {
+			robotNum++
+		}
+	}
+	if robotNum == 0 || robotNum == len(players) {
+		return hands
+	}
+	for try := 1; try < controlTryNum && !isControlMatch(players, hands, bloodState); try++ {
+		hands = dealHands(len(players))
+	}
+	return hands
+}
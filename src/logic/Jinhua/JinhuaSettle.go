@@ -0,0 +1,226 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["JinhuaSettle"] = &JinhuaSettle{}
+}
+
+// JinhuaSettle 炸金花游戏的结算组件，用于处理比牌和结算阶段的逻辑
+type JinhuaSettle struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *JinhuaSettle) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *JinhuaSettle) Start() {
+	obj.Base.Start()
+}
+
+// Drive 炸金花结算组件主驱动
+func (obj *JinhuaSettle) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	// 结算 <-> 准备
+	if request.NextRoomState != pb.RoomState_RoomStateSettle {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateReady
+		request.NextRoomState = pb.RoomState_RoomStateReady
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	settleTimeStr := common.GetRoomConfig(request, "SettleTime")
+	settleTime, err := strconv.Atoi(settleTimeStr)
+	if err != nil {
+		common.LogError("JinhuaSettle Drive settleTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 推送房间状态 玩耍<->结算
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStatePlay,
+		AfterState:        pb.RoomState_RoomStateSettle,
+		AfterStateEndTime: nowTime + int64(settleTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	msgErr := obj.settle(request, nowTime)
+	if msgErr != nil {
+		return request, msgErr
+	}
+
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" {
+			continue
+		}
+		if onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+			onePlayer.PlayNum++
+		}
+		// 对局中退出的玩家在结算完成后踢出
+		if onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+		}
+	}
+	request.NextRoomState = pb.RoomState_RoomStateReady
+	request.DoTime = nowTime + int64(settleTime)
+	return request, nil
+}
+
+// settle 最后没有出局的玩家赢得奖池，其他玩家输掉本局的下注，赢家按照抽水比例对赢的部分抽水，修改玩家金币
+func (obj *JinhuaSettle) settle(request *pb.RoomInfo, nowTime int64) *pb.ErrorMessage {
+	commissionStr := common.GetRoomConfig(request, "Commission")
+	commission, err := strconv.ParseInt(commissionStr, 10, 64)
+	if err != nil {
+		common.LogError("JinhuaSettle settle commissionStr has err", err)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	jinhuaInRoom := request.GetJinhuaInRoom()
+	players := getPlayPlayers(request)
+	activePlayers := getActivePlayers(request)
+	if len(activePlayers) != 1 {
+		common.LogError("JinhuaSettle settle winner num has err", len(activePlayers))
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	winner := activePlayers[0]
+	jinhuaInRoom.LastWinnerUuid = winner.GetUuid()
+	request.LastWinnerIndex = getPlayerIndex(request, winner.GetUuid())
+
+	// 1.计算输赢和抽水
+	settleInfo := &pb.SettleInfo{}
+	for _, onePlayer := range players {
+		winOrLose := -onePlayer.GetJinhuaBets()
+		water := int64(0)
+		if onePlayer.GetUuid() == winner.GetUuid() {
+			winOrLose += jinhuaInRoom.GetJackpot()
+			water = winOrLose * commission / 100
+			winOrLose -= water
+		}
+		onePlayer.Balance += winOrLose
+		onePlayer.WinOrLose = winOrLose
+		onePlayer.HundredCommission = water
+		onePlayer.HundredWaterBill = common.AbsInt64(winOrLose)
+
+		settleInfo.SettleUUID = append(settleInfo.SettleUUID, onePlayer.GetUuid())
+		settleInfo.SettleWinOrLose = append(settleInfo.SettleWinOrLose, winOrLose)
+		settleInfo.SettleName = append(settleInfo.SettleName, onePlayer.GetName())
+		settleInfo.ImgUrl = append(settleInfo.ImgUrl, onePlayer.GetHeadImgUrl())
+		settleInfo.AfterBalance = append(settleInfo.AfterBalance, onePlayer.GetBalance())
+		settleInfo.ShortId = append(settleInfo.ShortId, onePlayer.GetShortId())
+		settleInfo.AllPokers = append(settleInfo.AllPokers, &pb.AllPoker{
+			Pokers:                 onePlayer.GetPokers(),
+			CompareJinhuaPokerType: onePlayer.GetCompareJinhuaPokerType(),
+		})
+		settleInfo.CompareJinhuaPokerType = append(settleInfo.CompareJinhuaPokerType, onePlayer.GetCompareJinhuaPokerType())
+	}
+	request.AllSettleInfo = append(request.AllSettleInfo, settleInfo)
+
+	// 推送结算结果
+	pushSettle := &pb.PushRoomSettleInfo{
+		RoomId:     request.GetUuid(),
+		PlayerInfo: players,
+	}
+	common.RoomBroadcast(request, pushSettle)
+
+	// 2.更新血池
+	var score int64
+	for _, onePlayer := range players {
+		if onePlayer.GetIsRobot() {
+			continue
+		}
+		score -= onePlayer.GetWinOrLose() + onePlayer.GetHundredCommission()
+	}
+	msgErr := common.BloodIncrease(score, request.GetGameType(), request.GetGameScene())
+	if msgErr != nil {
+		common.LogError("JinhuaSettle settle BloodIncrease has err:", msgErr)
+		return msgErr
+	}
+
+	// 3.修改玩家真实的Money
+	for _, onePlayer := range players {
+		// 后面协程操作，为避免错误在此处提取金额
+		addMoney := onePlayer.GetWinOrLose()
+		// 机器人不记录游戏记录
+		var gameRecord *pb.GameRecordReport
+		if !onePlayer.GetIsRobot() {
+			gameRecord = obj.getGameRecord(request, onePlayer, settleInfo, nowTime)
+		}
+		go obj.saveMoney(request, onePlayer, addMoney, gameRecord)
+	}
+	return nil
+}
+
+// getGameRecord 生成玩家本局的游戏记录，金额在saveMoney中修改完玩家金币后补全
+func (obj *JinhuaSettle) getGameRecord(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, settleInfo *pb.SettleInfo, settleTime int64) *pb.GameRecordReport {
+	extendData := &pb.GameRecordExtendData{}
+	extendData.Pokers = onePlayer.GetPokers()
+	extendData.AllSettleInfo = []*pb.SettleInfo{settleInfo}
+
+	gameRecord := &pb.GameRecordReport{}
+	gameRecord.GameType = roomInfo.GetGameType()
+	gameRecord.GameScene = roomInfo.GetGameScene()
+	gameRecord.GameMode = common.GameMode
+	gameRecord.RoomType = roomInfo.GetRoomType()
+	gameRecord.RoomId = roomInfo.GetUuid()
+	gameRecord.RoundId = roomInfo.GetCurrentRoundId()
+	gameRecord.PlayerUuid = onePlayer.GetUuid()
+	gameRecord.PlayerShortId = onePlayer.GetShortId()
+	gameRecord.PlayerAccount = onePlayer.GetAccount()
+	gameRecord.StartTime = roomInfo.GetRoundStartTime()
+	gameRecord.SettleTime = settleTime
+	gameRecord.WinOrLose = onePlayer.GetWinOrLose()
+	gameRecord.Commission = onePlayer.GetHundredCommission()
+	gameRecord.ExtendData = extendData
+	return gameRecord
+}
+
+// saveMoney 修改玩家金币并推送金币变动
+func (obj *JinhuaSettle) saveMoney(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, addMoney int64, gameRecord *pb.GameRecordReport) {
+	taskConfig := &pb.TaskConfig{}
+	taskConfig.TaskType = pb.TaskType_Task_PlayGame
+	taskConfig.GameType = roomInfo.GetGameType()
+	taskConfig.GameScene = roomInfo.GetGameScene()
+	taskConfig.TaskNum = 1
+	cateGoryList := strings.Split(common.GetRoomConfig(roomInfo, "CateGory"), ",")
+	for _, oneCateGoryStr := range cateGoryList {
+		oneCateGoryInt, err := strconv.Atoi(oneCateGoryStr)
+		if err != nil {
+			common.LogError("JinhuaSettle saveMoney oneCateGoryStr has err", oneCateGoryStr, err)
+		}
+		taskConfig.GameCateGoryType = append(taskConfig.GetGameCateGoryType(), pb.GameCateGoryType(oneCateGoryInt))
+	}
+	afterBalance, msgErr := common.ChangePlayerInfoAfterGameEnd(onePlayer.GetUuid(), addMoney, taskConfig, pb.ResourceChangeReason_JinhuaSettleGold)
+	if msgErr != nil {
+		return
+	}
+	if afterBalance != onePlayer.Balance {
+		common.LogError("JinhuaSettle saveMoney has err: afterBalance != onePlayer.Balance", afterBalance, onePlayer.Balance, "输赢：", onePlayer.WinOrLose)
+	}
+	// 推送游戏记录
+	if gameRecord != nil {
+		gameRecord.BeforeBalance = afterBalance - addMoney
+		gameRecord.SettleBalance = afterBalance
+		msgErr = common.PushGameRecord(gameRecord)
+		if msgErr != nil {
+			common.LogError("JinhuaSettle saveMoney PushGameRecord has err", onePlayer.GetUuid(), msgErr)
+		}
+	}
+	userBalanceChangePush := &pb.PushUserBalanceChange{}
+	userBalanceChangePush.UserId = onePlayer.GetUuid()
+	userBalanceChangePush.Balance = afterBalance
+	common.RoomBroadcast(roomInfo, userBalanceChangePush)
+}
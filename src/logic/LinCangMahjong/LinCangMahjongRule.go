@@ -0,0 +1,293 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	Mahjong "gameServer-demo/src/logic/Mahjong"
+	"github.com/golang/protobuf/proto"
+)
+
+func init() {
+	common.AllComponentMap["LinCangMahjongReady"] = &Mahjong.MahjongReady{Variant: linCangVariant}
+	common.AllComponentMap["LinCangMahjongDeal"] = &Mahjong.MahjongDeal{Variant: linCangVariant}
+	common.AllComponentMap["LinCangMahjongPlay"] = &Mahjong.MahjongPlay{Variant: linCangVariant}
+	common.AllComponentMap["LinCangMahjongSettle"] = &Mahjong.MahjongSettle{Variant: linCangVariant}
+}
+
+// linCangVariant 临沧麻将的规则：筒条万、字牌加八张花牌，摸到花牌补花，可以碰杠不能吃，不定缺；
+// 有人胡牌本局就结束，一炮多响时只有出牌玩家下家方向的第一个玩家胡牌(截胡)。
+// 七星、十老头、烂牌、五梅花、卡绝张、边绝张等玩法由房间配置的开关决定，
+// 胡牌类型、胡牌来源的分数和分数相加还是相乘也都由房间配置决定
+var linCangVariant = &Mahjong.Variant{
+	Name:       "LinCangMahjong",
+	GameType:   pb.GameType_LinCangMahjong,
+	ConfigTemp: &common.LinCangMahjongGameConfigTemp,
+	Colors: []pb.MahjongColor{
+		pb.MahjongColor_MahjongColorDot,
+		pb.MahjongColor_MahjongColorBamboo,
+		pb.MahjongColor_MahjongColorCharacter,
+		pb.MahjongColor_MahjongColorWind,
+		pb.MahjongColor_MahjongColorDragon,
+		pb.MahjongColor_MahjongColorFlower,
+	},
+	KongReason: pb.ResourceChangeReason_LinCangMahjongSettleGold,
+	WinReason:  pb.ResourceChangeReason_LinCangMahjongSettleGold,
+	ErrorCodes: Mahjong.ErrorCodes{
+		NoPermissionOutput: pb.ErrorCode_LinCangMahjongErrorCodeNoPermissionOutput,
+		Output:             pb.ErrorCode_LinCangMahjongErrorCodeNoOutputCard,
+		NoPermissionPong:   pb.ErrorCode_LinCangMahjongErrorCodeNoPermissionPong,
+		NoPermissionKong:   pb.ErrorCode_LinCangMahjongErrorCodeNoPermissionKong,
+		Kong:               pb.ErrorCode_LinCangMahjongErrorCodeNoKongCard,
+		NoPermissionWin:    pb.ErrorCode_LinCangMahjongErrorCodeNoPermissionWin,
+	},
+	NewOperateRequest: func() Mahjong.OperateRequest {
+		return &pb.LinCangMahjongOperateRequest{}
+	},
+	NewOperateReply: func(roomId string, playerIndex int32) proto.Message {
+		return &pb.LinCangMahjongOperateReply{RoomId: roomId, PlayerIndex: playerIndex}
+	},
+	GetWinChecker: getWinChecker,
+	GetWin:        getWin,
+	ChooseWinners: chooseWinners,
+	AfterSettle:   afterSettle,
+}
+
+// 房间配置中的玩法开关，和开房配置LinCangMahjongCardCreateRoomConfig的字段同名，1为开启
+const (
+	// configNoPongKong 门清
+	configNoPongKong = "BNoPongKong"
+	// configFivePlumBlossom 五梅花
+	configFivePlumBlossom = "BFivePlumBlossom"
+	// configSevenStars 七星
+	configSevenStars = "BSevenStars"
+	// configTenOldMen 十老头
+	configTenOldMen = "BTenOldMen"
+	// configDrawSky 天胡
+	configDrawSky = "BDrawSky"
+	// configDiscardLand 地胡
+	configDiscardLand = "BDiscardLand"
+	// configHardBad 硬烂(烂牌可以胡)
+	configHardBad = "BHardBad"
+	// configMiddleLast 卡绝张
+	configMiddleLast = "BMiddleLast"
+	// configSideLast 边绝张
+	configSideLast = "BSideLast"
+	// configPlus 胡牌分数相加，关闭时相乘
+	configPlus = "BPlus"
+	// configSevenStarsAndTenOldMenFirst 七星、十老头优先于其他玩家胡牌
+	configSevenStarsAndTenOldMenFirst = "BSevenStarsAndTenOldMenFirst"
+)
+
+// honorKindNum 字牌的种类数，七星要七种字牌各一张
+const honorKindNum = 7
+
+// isSevenStars 七星：烂牌并且东南西北中发白七种字牌都有
+func isSevenStars(counts Mahjong.TileCounts) bool {
+	return Mahjong.IsBadCards(counts) && Mahjong.GetHonorKindNum(counts) == honorKindNum
+}
+
+// isTenOldMen 十老头：基本胡牌牌型，手牌、碰牌和杠牌都是一、九或者字牌
+func isTenOldMen(mahjongPlayer *pb.MahjongPlayerInfo, hand []*pb.Mahjong) bool {
+	return Mahjong.CanWinNormal(Mahjong.GetTileCounts(hand)) && Mahjong.IsAllOneNineOrHonor(Mahjong.GetAllMahjongs(mahjongPlayer, hand))
+}
+
+// getWinChecker 临沧麻将的胡牌牌型：基本胡牌牌型、七对、十三幺，开启硬烂时可以胡烂牌，开启七星时可以胡七星
+func getWinChecker(roomInfo *pb.RoomInfo) Mahjong.WinChecker {
+	isHardBad := Mahjong.IsRoomConfigOpen(roomInfo, configHardBad)
+	isSevenStarsOpen := Mahjong.IsRoomConfigOpen(roomInfo, configSevenStars)
+	return func(counts Mahjong.TileCounts) bool {
+		return Mahjong.CanWinNormal(counts) || Mahjong.IsSevenPairs(counts) || Mahjong.IsThirteenOrphans(counts) ||
+			(isHardBad && Mahjong.IsBadCards(counts)) || (isSevenStarsOpen && isSevenStars(counts))
+	}
+}
+
+// isSpecialWin 玩家胡这张牌是不是七星或者十老头(需要开启对应的玩法)
+func isSpecialWin(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, mahjong *pb.Mahjong) bool {
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	hand := append(append([]*pb.Mahjong{}, mahjongPlayer.GetHandRegion()...), mahjong)
+	return (Mahjong.IsRoomConfigOpen(roomInfo, configSevenStars) && isSevenStars(Mahjong.GetTileCounts(hand))) ||
+		(Mahjong.IsRoomConfigOpen(roomInfo, configTenOldMen) && isTenOldMen(mahjongPlayer, hand))
+}
+
+// chooseWinners 多个玩家胡同一张牌时只有一个玩家胡牌：开启七星、十老头优先时先选胡七星、十老头的玩家，
+// 否则按出牌玩家之后的座位顺序选第一个
+func chooseWinners(roomInfo *pb.RoomInfo, winners []int32, mahjong *pb.Mahjong) []int32 {
+	if Mahjong.IsRoomConfigOpen(roomInfo, configSevenStarsAndTenOldMenFirst) {
+		for _, winner := range winners {
+			if isSpecialWin(roomInfo, roomInfo.GetPlayerInfo()[winner], mahjong) {
+				return []int32{winner}
+			}
+		}
+	}
+	return winners[:1]
+}
+
+// isLastMahjong 胡的牌是不是绝张：另外三张都已经出现了
+// 点炮时胡的那张牌还在出牌区里，要从看得见的牌中去掉
+func isLastMahjong(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, winContext *Mahjong.WinContext) bool {
+	remainNum := Mahjong.GetRemainNum(roomInfo, onePlayer, winContext.WinMahjong)
+	if winContext.WinSource == pb.MahjongWinSourceEnum_Discard || winContext.WinSource == pb.MahjongWinSourceEnum_DiscardAfterKong {
+		remainNum++
+	}
+	return remainNum == 1
+}
+
+// getSequenceWinTypes 根据胡的牌在顺子中的位置判断卡绝张、边绝张和五梅花
+// 卡张是胡顺子中间的一张，边张是胡一二三的三或者七八九的七，五梅花是五筒胡在四五六筒的中间
+func getSequenceWinTypes(roomInfo *pb.RoomInfo, splits []*Mahjong.WinSplit, winIndex int, isLast bool) []pb.MahjongWinEnum {
+	isMiddle, isSide := false, false
+	for _, oneSplit := range splits {
+		for _, oneMeld := range oneSplit.Melds {
+			if oneMeld.Type != Mahjong.MeldSequence {
+				continue
+			}
+			isMiddle = isMiddle || oneMeld.Index+1 == winIndex
+			isSide = isSide || (oneMeld.Index+2 == winIndex && Mahjong.GetIndexNum(oneMeld.Index) == 1) ||
+				(oneMeld.Index == winIndex && Mahjong.GetIndexNum(winIndex) == 7)
+		}
+	}
+	var winTypes []pb.MahjongWinEnum
+	if isLast && isMiddle && Mahjong.IsRoomConfigOpen(roomInfo, configMiddleLast) {
+		winTypes = append(winTypes, pb.MahjongWinEnum_MiddleLastCard)
+	}
+	if isLast && isSide && Mahjong.IsRoomConfigOpen(roomInfo, configSideLast) {
+		winTypes = append(winTypes, pb.MahjongWinEnum_SideLastCard)
+	}
+	fiveDot := &pb.Mahjong{MahjongColor: pb.MahjongColor_MahjongColorDot, MahjongNum: pb.MahjongNum(5)}
+	if isMiddle && winIndex == Mahjong.GetTileIndex(fiveDot) && Mahjong.IsRoomConfigOpen(roomInfo, configFivePlumBlossom) {
+		winTypes = append(winTypes, pb.MahjongWinEnum_FivePlumBlossom)
+	}
+	return winTypes
+}
+
+// getWinTypes 获取胡牌类型：七星、十三幺、烂牌、七对这些特殊牌型优先，不再看面子；
+// 基本胡牌牌型再看碰碰胡、十老头、卡绝张、边绝张和五梅花。清一色、门清、海底、天胡、地胡可以和其他类型叠加
+func getWinTypes(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, winContext *Mahjong.WinContext) []pb.MahjongWinEnum {
+	var winTypes []pb.MahjongWinEnum
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	hand := winContext.Hand
+	counts := Mahjong.GetTileCounts(hand)
+	allMahjongs := Mahjong.GetAllMahjongs(mahjongPlayer, hand)
+
+	switch {
+	case Mahjong.IsRoomConfigOpen(roomInfo, configSevenStars) && isSevenStars(counts):
+		winTypes = append(winTypes, pb.MahjongWinEnum_MahjongWinEnumSevenStars)
+	case Mahjong.IsThirteenOrphans(counts):
+		winTypes = append(winTypes, pb.MahjongWinEnum_ThirteenMao)
+	case Mahjong.IsRoomConfigOpen(roomInfo, configHardBad) && Mahjong.IsBadCards(counts):
+		winTypes = append(winTypes, pb.MahjongWinEnum_BadCards)
+	case Mahjong.IsSevenPairs(counts):
+		winTypes = append(winTypes, pb.MahjongWinEnum_SevenPair)
+	default:
+		splits := Mahjong.GetWinSplits(counts)
+		for _, oneSplit := range splits {
+			if Mahjong.IsAllTriplet(oneSplit) {
+				winTypes = append(winTypes, pb.MahjongWinEnum_PongPong)
+				break
+			}
+		}
+		if Mahjong.IsRoomConfigOpen(roomInfo, configTenOldMen) && isTenOldMen(mahjongPlayer, hand) {
+			winTypes = append(winTypes, pb.MahjongWinEnum_MahjongWinEnumTenOldMen)
+		}
+		isLast := isLastMahjong(roomInfo, onePlayer, winContext)
+		winTypes = append(winTypes, getSequenceWinTypes(roomInfo, splits, Mahjong.GetTileIndex(winContext.WinMahjong), isLast)...)
+	}
+	if Mahjong.IsSameColor(allMahjongs) {
+		winTypes = append(winTypes, pb.MahjongWinEnum_FullFlush)
+	}
+	// 门清：没有碰牌和明杠，暗杠不影响
+	isNoPongOrKong := len(mahjongPlayer.GetPongRegion()) == 0
+	for _, oneKong := range mahjongPlayer.GetKongRegion() {
+		isNoPongOrKong = isNoPongOrKong && oneKong.GetKong() == pb.MahjongKongEnum_KongAn
+	}
+	if isNoPongOrKong && Mahjong.IsRoomConfigOpen(roomInfo, configNoPongKong) {
+		winTypes = append(winTypes, pb.MahjongWinEnum_NoPongOrKong)
+	}
+	if winContext.IsSeabed {
+		winTypes = append(winTypes, pb.MahjongWinEnum_Seabed)
+	}
+	if winContext.IsDrawSky && Mahjong.IsRoomConfigOpen(roomInfo, configDrawSky) {
+		winTypes = append(winTypes, pb.MahjongWinEnum_DrawSky)
+	}
+	if winContext.IsDiscardLand && Mahjong.IsRoomConfigOpen(roomInfo, configDiscardLand) {
+		winTypes = append(winTypes, pb.MahjongWinEnum_DiscardLand)
+	}
+	if len(winTypes) == 0 {
+		winTypes = append(winTypes, pb.MahjongWinEnum_Ping)
+	}
+	return winTypes
+}
+
+// getWinSource 点炮胡七星、十老头时胡牌来源记为七星、十老头，其他情况不变
+func getWinSource(winTypes []pb.MahjongWinEnum, winSource pb.MahjongWinSourceEnum) pb.MahjongWinSourceEnum {
+	if winSource != pb.MahjongWinSourceEnum_Discard && winSource != pb.MahjongWinSourceEnum_DiscardAfterKong {
+		return winSource
+	}
+	for _, oneType := range winTypes {
+		switch oneType {
+		case pb.MahjongWinEnum_MahjongWinEnumSevenStars:
+			return pb.MahjongWinSourceEnum_SevenStars
+		case pb.MahjongWinEnum_MahjongWinEnumTenOldMen:
+			return pb.MahjongWinSourceEnum_TenOldMen
+		}
+	}
+	return winSource
+}
+
+// getWin 计算胡牌的类型和分数
+// 胡牌类型和胡牌来源的分数查房间配置的分数表，开启胡分相加时全部相加，否则全部相乘；
+// 再加上每张花牌的分数，不超过封顶倍数，最后乘以底分
+func getWin(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, winContext *Mahjong.WinContext) (*Mahjong.WinResult, *pb.ErrorMessage) {
+	winTypeScores, msgErr := Mahjong.GetRoomConfigScoreTable(roomInfo, "WinTypeScore", pb.MahjongWinEnum_value)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	winSourceScores, msgErr := Mahjong.GetRoomConfigScoreTable(roomInfo, "WinSourceScore", pb.MahjongWinSourceEnum_value)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	baseScore, msgErr := Mahjong.GetRoomConfigInt64(roomInfo, "BaseScore")
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	flowerScore, msgErr := Mahjong.GetRoomConfigInt64(roomInfo, "FlowerScore")
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	maxMultiple, msgErr := Mahjong.GetRoomConfigInt64(roomInfo, "MaxMultiple")
+	if msgErr != nil {
+		return nil, msgErr
+	}
+
+	winTypes := getWinTypes(roomInfo, onePlayer, winContext)
+	winSource := getWinSource(winTypes, winContext.WinSource)
+	scores := []int64{winSourceScores[int32(winSource)]}
+	for _, oneType := range winTypes {
+		scores = append(scores, winTypeScores[int32(oneType)])
+	}
+	isPlus := Mahjong.IsRoomConfigOpen(roomInfo, configPlus)
+	multiple := int64(1)
+	if isPlus {
+		multiple = 0
+	}
+	for _, oneScore := range scores {
+		if isPlus {
+			multiple += oneScore
+			continue
+		}
+		// 相乘时分数表中没有配置或者配置为0的项不参与计算
+		if oneScore > 0 {
+			multiple *= oneScore
+		}
+	}
+	flowerNum := Mahjong.CountColor(onePlayer.GetMahjongPlayerInfo().GetChowRegion(), pb.MahjongColor_MahjongColorFlower)
+	multiple += int64(flowerNum) * flowerScore
+	if maxMultiple > 0 && multiple > maxMultiple {
+		multiple = maxMultiple
+	}
+	return &Mahjong.WinResult{
+		WinTypes:  winTypes,
+		WinSource: winSource,
+		WinScore:  baseScore * multiple,
+	}, nil
+}
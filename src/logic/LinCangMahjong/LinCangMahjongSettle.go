@@ -0,0 +1,46 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	Mahjong "gameServer-demo/src/logic/Mahjong"
+)
+
+// afterSettle 生成临沧麻将每个玩家的结算信息(手牌、胡牌信息、杠牌和胡牌的输赢分数)并推送给房间里的玩家
+func afterSettle(roomInfo *pb.RoomInfo, settleInfo *pb.SettleInfo, players []*pb.RoomPlayerInfo) {
+	gameInfo := roomInfo.GetMahjongGameInfo()
+	linCangSettle := &pb.LinCangMahjongSettle{}
+	for _, onePlayer := range players {
+		playerIndex := Mahjong.GetPlayerIndex(roomInfo, onePlayer.GetUuid())
+		mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+		playerSettle := &pb.LinCangMahjongPlayerSettle{
+			PlayerIndex: playerIndex,
+			PlayerUuid:  onePlayer.GetUuid(),
+			ShortId:     onePlayer.GetShortId(),
+			HandCards:   mahjongPlayer.GetHandRegion(),
+			TotalScore:  int32(onePlayer.GetWinOrLose()),
+		}
+		for _, oneWin := range gameInfo.GetWinInfo() {
+			if oneWin.GetWinPlayerIndex() == playerIndex {
+				playerSettle.WinInfo = oneWin
+			}
+		}
+		for _, oneDetail := range mahjongPlayer.GetSettleDetail() {
+			switch {
+			case oneDetail.GetKongInfo() != nil:
+				playerSettle.KongScore += int32(oneDetail.GetAmountChange())
+			case oneDetail.GetWinInfo() != nil:
+				playerSettle.WinScore += int32(oneDetail.GetAmountChange())
+			}
+		}
+		linCangSettle.PlayerSettle = append(linCangSettle.PlayerSettle, playerSettle)
+	}
+	settleInfo.LinCangMahjongSettle = append(settleInfo.GetLinCangMahjongSettle(), linCangSettle)
+
+	pushSettle := &pb.LinCangMahjongSettleNotice{
+		RoomId:      roomInfo.GetUuid(),
+		BankerIndex: int32(roomInfo.GetBankerIndex()),
+		SettleInfo:  linCangSettle,
+	}
+	common.RoomBroadcast(roomInfo, pushSettle)
+}
@@ -0,0 +1,266 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	uuid "github.com/satori/go.uuid"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["LineGamePlay"] = &LineGamePlay{}
+}
+
+// LineGamePlay 连线游戏的逻辑组件，处理进入、退出和下注开奖
+// 连线游戏没有房间，玩家进入后只在玩家信息上记录所在的游戏、场次和子游戏
+type LineGamePlay struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *LineGamePlay) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *LineGamePlay) Start() {
+	obj.Base.Start()
+	common.InitGameConfigTemp(common.LineGameGameConfigTemp, pb.GameType_LineGame)
+}
+
+// RequestJoinRoom 玩家进入连线游戏的某个子游戏
+func (obj *LineGamePlay) RequestJoinRoom(request *pb.GameJoinRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameJoinRoomReply, *pb.ErrorMessage) {
+	reply := &pb.GameJoinRoomReply{}
+	playerInfo, msgErr := loadPlayer(extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	if playerInfo.GetRoomId() != "" || (playerInfo.GetGameType() != pb.GameType_None && playerInfo.GetGameType() != pb.GameType_LineGame) {
+		common.LogError("LineGamePlay RequestJoinRoom PlayerInOtherGame", playerInfo.GetUuid(), playerInfo.GetGameType())
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerInOtherGame, "")
+	}
+	if _, ok := lineGameDefines[request.GetWantLineGameSubType()]; !ok {
+		common.LogError("LineGamePlay RequestJoinRoom WantLineGameSubType not exist", request.GetWantLineGameSubType())
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidRequest, "")
+	}
+	gameKeyMap := common.Configer.GetGameConfigByGameTypeAndScene(pb.GameType_LineGame, request.GetGameScene())
+	if gameKeyMap == nil {
+		common.LogError("LineGamePlay RequestJoinRoom GetGameConfigByGameTypeAndScene gameKeyMap == nil", request.GetGameScene())
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 判断进入金额
+	_, msgErr = common.GameJoinRoomJudge(playerInfo, 1, request, pb.GameType_LineGame)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	playerInfo.GameType = pb.GameType_LineGame
+	playerInfo.GameScene = request.GetGameScene()
+	playerInfo.LineGameSubType = request.GetWantLineGameSubType()
+	msgErr = savePlayer(playerInfo, false, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	roomInfo := &pb.RoomInfo{}
+	roomInfo.GameType = pb.GameType_LineGame
+	roomInfo.GameScene = playerInfo.GetGameScene()
+	roomInfo.LineGameSubType = playerInfo.GetLineGameSubType()
+	for _, oneConfig := range gameKeyMap.Map {
+		roomInfo.Config = append(roomInfo.Config, oneConfig)
+	}
+	reply.RoomInfo = roomInfo
+	return reply, nil
+}
+
+// RequestExitRoom 玩家退出连线游戏
+func (obj *LineGamePlay) RequestExitRoom(request *pb.GameExitRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameExitRoomReply, *pb.ErrorMessage) {
+	playerInfo, msgErr := loadPlayer(extroInfo)
+	if msgErr != nil {
+		return &pb.GameExitRoomReply{}, msgErr
+	}
+	request.GameType = pb.GameType_LineGame
+	return common.GameExitSingleRoom(request, playerInfo, extroInfo, func(playerInfo *pb.PlayerInfo) *pb.ErrorMessage {
+		playerInfo.LineGameSubType = pb.LineGameSubType_LineGameSubType_None
+		return nil
+	})
+}
+
+// RequestPlay 玩家下注开奖
+// 下注先扣除，按血池状态开奖，下注的一部分放入奖池，全wild的线中奖池，最后加上赢分并上报游戏记录
+func (obj *LineGamePlay) RequestPlay(request *pb.LineGamePlayRequest, extroInfo *pb.MessageExtroInfo) (*pb.LineGamePlayReply, *pb.ErrorMessage) {
+	reply := &pb.LineGamePlayReply{}
+	playerInfo, msgErr := loadPlayer(extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	if playerInfo.GetGameType() != pb.GameType_LineGame || playerInfo.GetLineGameSubType() != request.GetSubGame() {
+		common.LogError("LineGamePlay RequestPlay player not join the sub game", playerInfo.GetUuid(), playerInfo.GetGameType(), playerInfo.GetLineGameSubType(), request.GetSubGame())
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotJoinRoom, "")
+	}
+	define, ok := lineGameDefines[request.GetSubGame()]
+	if !ok {
+		common.LogError("LineGamePlay RequestPlay SubGame not exist", request.GetSubGame())
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidRequest, "")
+	}
+	gameScene := playerInfo.GetGameScene()
+	wager := request.GetWagerNum()
+	if !isValidWager(gameScene, wager) {
+		common.LogError("LineGamePlay RequestPlay WagerNum is invalid", playerInfo.GetUuid(), wager)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidRequest, "")
+	}
+	if playerInfo.GetBalance() < wager {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_BalanceNotEnough, "")
+	}
+	startTime := time.Now().Unix()
+	beforeBalance := playerInfo.GetBalance()
+	msgErr = common.AddResource(pb.RewardType_Golden, -wager, playerInfo, false, extroInfo, false, pb.ResourceChangeReason_LineGameWager)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	// 开奖，机器人不走血池
+	bloodState := pb.BloodSlotStatus_BloodSlotStatus_None
+	if !playerInfo.GetIsRobot() {
+		bloodState = common.BloodGetState(pb.GameType_LineGame, gameScene)
+	}
+	result := playGameWithBlood(define, wager, int32(getLineGameConfigInt64(gameScene, "MaxFreeSpinNum")), bloodState, int(getLineGameConfigInt64(gameScene, "ControlTimes")))
+
+	// 奖池
+	bonusIn := int64(0)
+	jackpotWin := int64(0)
+	if !playerInfo.GetIsRobot() {
+		bonusIn = wager * getLineGameConfigInt64(gameScene, "BonusRatio") / 100
+		if bonusIn > 0 {
+			_, _, msgErr = common.Bonuser.AddBonus(pb.GameType_LineGame, gameScene, bonusIn, pb.ResourceChangeReason_LineGameWager, false)
+			if msgErr != nil {
+				common.LogError("LineGamePlay RequestPlay AddBonus has err", msgErr)
+				bonusIn = 0
+			}
+		}
+		if result.isJackpot {
+			jackpotWin = getJackpot(playerInfo, gameScene)
+		}
+	}
+
+	totalWin := result.totalWin + jackpotWin
+	if totalWin > 0 {
+		msgErr = common.AddResource(pb.RewardType_Golden, totalWin, playerInfo, false, extroInfo, true, pb.ResourceChangeReason_LineGameSettleGold)
+		if msgErr != nil {
+			common.LogError("LineGamePlay RequestPlay AddResource win has err", playerInfo.GetUuid(), totalWin, msgErr)
+		}
+	}
+	msgErr = savePlayer(playerInfo, true, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	// 奖池赢的分不是从血池里出的，下注进奖池的部分也不进血池
+	if !playerInfo.GetIsRobot() {
+		msgErr = common.BloodIncrease(wager-bonusIn-result.totalWin, pb.GameType_LineGame, gameScene)
+		if msgErr != nil {
+			common.LogError("LineGamePlay RequestPlay BloodIncrease has err", msgErr)
+		}
+	}
+
+	gameRecord := &pb.GameRecordReport{}
+	gameRecord.GameType = pb.GameType_LineGame
+	gameRecord.GameScene = gameScene
+	gameRecord.GameMode = common.GameMode
+	gameRecord.RoundId = uuid.NewV4().String()
+	gameRecord.PlayerUuid = playerInfo.GetUuid()
+	gameRecord.PlayerShortId = playerInfo.GetShortId()
+	gameRecord.PlayerAccount = playerInfo.GetAccount()
+	gameRecord.StartTime = startTime
+	gameRecord.SettleTime = time.Now().Unix()
+	gameRecord.TotalBet = wager
+	gameRecord.WinOrLose = totalWin - wager
+	gameRecord.BeforeBalance = beforeBalance
+	gameRecord.SettleBalance = playerInfo.GetBalance()
+	msgErr = common.PushGameRecord(gameRecord)
+	if msgErr != nil {
+		common.LogError("LineGamePlay RequestPlay PushGameRecord has err", playerInfo.GetUuid(), msgErr)
+	}
+
+	reply.SubGame = request.GetSubGame()
+	reply.AllInfo = result.allInfo
+	return reply, nil
+}
+
+// getJackpot 中奖池，按配置的比例从奖池中取分，返回取到的分
+func getJackpot(playerInfo *pb.PlayerInfo, gameScene int32) int64 {
+	jackpotRatio := getLineGameConfigInt64(gameScene, "JackpotRatio")
+	jackpotWin, bonusRecord, msgErr := common.Bonuser.GetBonusByGameTypeAndGameScene(pb.GameType_LineGame, gameScene, int(jackpotRatio), pb.ResourceChangeReason_LineGameSettleGold)
+	if msgErr != nil {
+		common.LogError("LineGamePlay getJackpot GetBonusByGameTypeAndGameScene has err", msgErr)
+		return 0
+	}
+	if bonusRecord != nil {
+		bonusRecord.Uuid = playerInfo.GetUuid()
+		bonusRecord.ShortId = playerInfo.GetShortId()
+		msgErr = common.PushBonusRecord(bonusRecord)
+		if msgErr != nil {
+			common.LogError("LineGamePlay getJackpot PushBonusRecord has err", msgErr)
+		}
+	}
+	return jackpotWin
+}
+
+// isValidWager 下注金额是否在配置的可选下注中
+func isValidWager(gameScene int32, wager int64) bool {
+	config := common.Configer.GetGameConfig(pb.GameType_LineGame, gameScene, "WagerList")
+	if config == nil {
+		common.LogError("LineGamePlay isValidWager WagerList config == nil", gameScene)
+		return false
+	}
+	for _, oneStr := range strings.Split(config.GetValue(), ",") {
+		oneWager, err := strconv.ParseInt(strings.TrimSpace(oneStr), 10, 64)
+		if err != nil {
+			common.LogError("LineGamePlay isValidWager WagerList has err", config.GetValue(), err)
+			return false
+		}
+		if oneWager == wager {
+			return true
+		}
+	}
+	return false
+}
+
+// getLineGameConfigInt64 获取连线游戏的int配置，没有配置或者配置错误时返回0
+func getLineGameConfigInt64(gameScene int32, name string) int64 {
+	config := common.Configer.GetGameConfig(pb.GameType_LineGame, gameScene, name)
+	if config == nil {
+		common.LogError("LineGamePlay getLineGameConfigInt64 config == nil", gameScene, name)
+		return 0
+	}
+	value, err := strconv.ParseInt(config.GetValue(), 10, 64)
+	if err != nil {
+		common.LogError("LineGamePlay getLineGameConfigInt64 ParseInt has err", gameScene, name, config.GetValue(), err)
+		return 0
+	}
+	return value
+}
+
+// loadPlayer 获取请求玩家的信息
+func loadPlayer(extroInfo *pb.MessageExtroInfo) (*pb.PlayerInfo, *pb.ErrorMessage) {
+	loadPlayerRequest := &pb.LoadPlayerRequest{}
+	loadPlayerRequest.Uuid = extroInfo.GetUserId()
+	loadPlayerReply := &pb.LoadPlayerReply{}
+	msgErr := common.Router.Call("PlayerInfo", "LoadPlayer", loadPlayerRequest, loadPlayerReply, extroInfo)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	return loadPlayerReply.GetPlayerInfo(), nil
+}
+
+// savePlayer 保存玩家信息
+func savePlayer(playerInfo *pb.PlayerInfo, forceSave bool, extroInfo *pb.MessageExtroInfo) *pb.ErrorMessage {
+	savePlayerRequest := &pb.SavePlayerRequest{}
+	savePlayerRequest.PlayerInfo = playerInfo
+	savePlayerRequest.ForceSave = forceSave
+	return common.Router.Call("PlayerInfo", "SavePlayer", savePlayerRequest, &pb.EmptyMessage{}, extroInfo)
+}
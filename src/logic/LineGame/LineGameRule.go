@@ -0,0 +1,201 @@
+package logic
+
+import (
+	"math/rand"
+
+	pb "gameServer-demo/src/grpc"
+)
+
+// lineGameResult 一次下注的完整开奖结果，包含普通游戏和触发的免费游戏
+type lineGameResult struct {
+	// 每一局的信息，第一局是普通游戏，后面的是免费游戏
+	allInfo []*pb.LineGameRoundInfo
+	// 所有局的总赢分
+	totalWin int64
+	// 是否中了奖池（有线全部是wild）
+	isJackpot bool
+}
+
+// isNormalElement 是否是可以被wild替代的普通元素
+func isNormalElement(element pb.LineGameElementType) bool {
+	return element != pb.LineGameElementType_LineGameElementType_Wild &&
+		element != pb.LineGameElementType_LineGameElementType_Bouns
+}
+
+// spinMap 每个转轮随机停一个位置，得到显示出来的元素，返回的二维数组以[x][y]索引，x是列，y是行
+func spinMap(define *lineGameDefine) [][]pb.LineGameElementType {
+	elements := make([][]pb.LineGameElementType, define.columnNum)
+	for x := int32(0); x < define.columnNum; x++ {
+		reel := define.reels[x]
+		stop := rand.Intn(len(reel))
+		elements[x] = make([]pb.LineGameElementType, define.rowNum)
+		for y := int32(0); y < define.rowNum; y++ {
+			elements[x][y] = reel[(stop+int(y))%len(reel)]
+		}
+	}
+	return elements
+}
+
+// getGameMap 把开出的元素转成消息
+func getGameMap(define *lineGameDefine, elements [][]pb.LineGameElementType) *pb.LineGameMap {
+	gameMap := &pb.LineGameMap{
+		RowNum:    define.rowNum,
+		ColumnNum: define.columnNum,
+	}
+	for y := int32(0); y < define.rowNum; y++ {
+		for x := int32(0); x < define.columnNum; x++ {
+			gameMap.ElementInfo = append(gameMap.ElementInfo, &pb.LineGamePosition{
+				X:       x,
+				Y:       y,
+				Element: elements[x][y],
+			})
+		}
+	}
+	return gameMap
+}
+
+// getPayOdds 获取元素连续出现num个时的赔率
+func getPayOdds(define *lineGameDefine, element pb.LineGameElementType, num int32) int64 {
+	odds, ok := define.payTable[element]
+	if !ok || int(num) >= len(odds) {
+		return 0
+	}
+	return odds[num]
+}
+
+// getLineBingo 计算一条线的中奖，从最左边开始连续，wild可以替代普通元素
+// 同时算只有wild的赔率和wild替代后的赔率，取大的那个
+func getLineBingo(define *lineGameDefine, elements [][]pb.LineGameElementType, lineIndex int32) *pb.LineGameBingoInfo {
+	line := define.lines[lineIndex]
+	// 开头连续的wild数量
+	wildNum := int32(0)
+	for x := int32(0); x < define.columnNum; x++ {
+		if elements[x][line[x]] != pb.LineGameElementType_LineGameElementType_Wild {
+			break
+		}
+		wildNum++
+	}
+	// wild替代后的元素和连续数量
+	element := pb.LineGameElementType_LineGameElementType_Wild
+	num := wildNum
+	if wildNum < define.columnNum && isNormalElement(elements[wildNum][line[wildNum]]) {
+		element = elements[wildNum][line[wildNum]]
+		for x := wildNum; x < define.columnNum; x++ {
+			cur := elements[x][line[x]]
+			if cur != element && cur != pb.LineGameElementType_LineGameElementType_Wild {
+				break
+			}
+			num = x + 1
+		}
+	}
+	wildOdds := getPayOdds(define, pb.LineGameElementType_LineGameElementType_Wild, wildNum)
+	odds := getPayOdds(define, element, num)
+	if wildOdds >= odds {
+		element = pb.LineGameElementType_LineGameElementType_Wild
+		num = wildNum
+		odds = wildOdds
+	}
+	if odds <= 0 {
+		return nil
+	}
+	bingoInfo := &pb.LineGameBingoInfo{
+		LineIndex: lineIndex,
+		Num:       num,
+		Element:   element,
+		Odds:      int32(odds),
+	}
+	for x := int32(0); x < num; x++ {
+		bingoInfo.Position = append(bingoInfo.Position, &pb.LineGamePosition{
+			X:       x,
+			Y:       line[x],
+			Element: elements[x][line[x]],
+		})
+	}
+	return bingoInfo
+}
+
+// getBounsNum 获取bouns元素的数量，bouns不需要在线上，出现在任意位置都算
+func getBounsNum(elements [][]pb.LineGameElementType) int32 {
+	num := int32(0)
+	for _, column := range elements {
+		for _, element := range column {
+			if element == pb.LineGameElementType_LineGameElementType_Bouns {
+				num++
+			}
+		}
+	}
+	return num
+}
+
+// playRound 开一局，返回这局的信息、获得的免费游戏次数和是否中了奖池
+// 每条线的中奖值 = 下注 / 线数 * 赔率 * 倍数
+func playRound(define *lineGameDefine, wager int64, multiple int64) (*pb.LineGameRoundInfo, int32, bool) {
+	elements := spinMap(define)
+	roundInfo := &pb.LineGameRoundInfo{
+		GameMap: getGameMap(define, elements),
+	}
+	isJackpot := false
+	lineNum := int64(len(define.lines))
+	for lineIndex := range define.lines {
+		bingoInfo := getLineBingo(define, elements, int32(lineIndex))
+		if bingoInfo == nil {
+			continue
+		}
+		roundInfo.BingoInfo = append(roundInfo.BingoInfo, bingoInfo)
+		roundInfo.Win += wager * int64(bingoInfo.GetOdds()) * multiple / lineNum
+		if bingoInfo.GetElement() == pb.LineGameElementType_LineGameElementType_Wild && bingoInfo.GetNum() == define.columnNum {
+			isJackpot = true
+		}
+	}
+	bounsNum := getBounsNum(elements)
+	if int(bounsNum) >= len(define.freeSpinTable) {
+		bounsNum = int32(len(define.freeSpinTable) - 1)
+	}
+	return roundInfo, define.freeSpinTable[bounsNum], isJackpot
+}
+
+// playGame 开一次下注的结果，普通游戏触发的免费游戏会接着开完，免费游戏中还可以再触发，总次数不超过maxFreeSpinNum
+func playGame(define *lineGameDefine, wager int64, maxFreeSpinNum int32) *lineGameResult {
+	result := &lineGameResult{}
+	roundInfo, freeSpinNum, isJackpot := playRound(define, wager, 1)
+	result.allInfo = append(result.allInfo, roundInfo)
+	result.totalWin += roundInfo.GetWin()
+	result.isJackpot = isJackpot
+	playedNum := int32(0)
+	for freeSpinNum > 0 && playedNum < maxFreeSpinNum {
+		freeSpinNum--
+		playedNum++
+		roundInfo, addNum, isJackpot := playRound(define, wager, define.freeSpinMultiple)
+		result.allInfo = append(result.allInfo, roundInfo)
+		result.totalWin += roundInfo.GetWin()
+		result.isJackpot = result.isJackpot || isJackpot
+		freeSpinNum += addNum
+	}
+	return result
+}
+
+// playGameWithBlood 根据血池状态开奖，血池需要吃分时尽量让玩家输，需要吐分时尽量让玩家赢，最多重开controlTimes次
+func playGameWithBlood(define *lineGameDefine, wager int64, maxFreeSpinNum int32, bloodState pb.BloodSlotStatus, controlTimes int) *lineGameResult {
+	result := playGame(define, wager, maxFreeSpinNum)
+	for i := 0; i < controlTimes; i++ {
+		switch bloodState {
+		case pb.BloodSlotStatus_BloodSlotStatus_Win:
+			if result.totalWin < wager {
+				return result
+			}
+			if cur := playGame(define, wager, maxFreeSpinNum); cur.totalWin < result.totalWin {
+				result = cur
+			}
+		case pb.BloodSlotStatus_BloodSlotStatus_Lose:
+			if result.totalWin > wager {
+				return result
+			}
+			if cur := playGame(define, wager, maxFreeSpinNum); cur.totalWin > result.totalWin {
+				result = cur
+			}
+		default:
+			return result
+		}
+	}
+	return result
+}
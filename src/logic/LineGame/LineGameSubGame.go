@@ -0,0 +1,71 @@
+package logic
+
+import (
+	pb "gameServer-demo/src/grpc"
+)
+
+// lineGameDefine 连线子游戏的数据定义，转轮、线和赔率都在这里配置，引擎按照这些数据开奖
+type lineGameDefine struct {
+	// 行数
+	rowNum int32
+	// 列数，也就是转轮的数量
+	columnNum int32
+	// 每一列的转轮带，开奖时每个转轮随机停在一个位置，从这个位置往下连续rowNum个元素显示出来
+	reels [][]pb.LineGameElementType
+	// 所有的线，每条线依次是每一列上的行索引
+	lines [][]int32
+	// 赔率表，元素从左往右连续出现n个时的赔率（下标为n），赔率乘以单线下注是这条线的中奖值
+	payTable map[pb.LineGameElementType][]int64
+	// 免费游戏表，bouns元素出现n个时获得的免费游戏次数（下标为n）
+	freeSpinTable []int32
+	// 免费游戏中奖值的倍数
+	freeSpinMultiple int64
+}
+
+// lineGameDefines 所有的连线子游戏
+var lineGameDefines = map[pb.LineGameSubType]*lineGameDefine{
+	pb.LineGameSubType_LineGameSubType_RomanArena: romanArenaDefine(),
+}
+
+// romanArenaDefine 罗马竞技场：3行5列20条线，wild可以替代普通元素，3个以上bouns触发免费游戏
+// 不算奖池时返奖率约93%
+func romanArenaDefine() *lineGameDefine {
+	n1 := pb.LineGameElementType_LineGameElementType_Normal1
+	n2 := pb.LineGameElementType_LineGameElementType_Normal2
+	n3 := pb.LineGameElementType_LineGameElementType_Normal3
+	n4 := pb.LineGameElementType_LineGameElementType_Normal4
+	n5 := pb.LineGameElementType_LineGameElementType_Normal5
+	n6 := pb.LineGameElementType_LineGameElementType_Normal6
+	n7 := pb.LineGameElementType_LineGameElementType_Normal7
+	w := pb.LineGameElementType_LineGameElementType_Wild
+	b := pb.LineGameElementType_LineGameElementType_Bouns
+	return &lineGameDefine{
+		rowNum:    3,
+		columnNum: 5,
+		reels: [][]pb.LineGameElementType{
+			{n1, n2, n3, n1, n4, n2, n5, n1, n3, n6, n2, n1, w, n4, n3, n1, n2, n7, n5, n1, b, n3, n2, n4, n1, n6, n2, n3, n5, n1},
+			{n2, n1, n4, n3, n1, n5, n2, w, n1, n3, n6, n2, n4, n1, n7, n3, n2, b, n1, n5, n4, n2, n1, n6, n3, n2, w, n1, n4, n3},
+			{n3, n1, n2, n5, n1, n4, w, n2, n3, n1, n6, n2, b, n1, n4, n3, n7, n2, n1, n5, n3, w, n2, n4, n1, n6, n3, n2, n1, n4},
+			{n1, n4, n2, n3, n6, n1, n2, w, n5, n1, n3, n2, n4, b, n1, n7, n3, n2, n1, n5, n4, n2, n3, n1, n6, n2, n4, n1, n3, n5},
+			{n2, n3, n1, n5, n2, n4, n1, n6, n3, w, n2, n1, n4, n3, b, n1, n2, n5, n7, n1, n3, n4, n2, n1, n6, n3, n1, n2, n5, n4},
+		},
+		lines: [][]int32{
+			{1, 1, 1, 1, 1}, {0, 0, 0, 0, 0}, {2, 2, 2, 2, 2}, {0, 1, 2, 1, 0}, {2, 1, 0, 1, 2},
+			{0, 0, 1, 2, 2}, {2, 2, 1, 0, 0}, {1, 0, 0, 0, 1}, {1, 2, 2, 2, 1}, {0, 1, 1, 1, 0},
+			{2, 1, 1, 1, 2}, {1, 0, 1, 2, 1}, {1, 2, 1, 0, 1}, {0, 1, 0, 1, 0}, {2, 1, 2, 1, 2},
+			{1, 1, 0, 1, 1}, {1, 1, 2, 1, 1}, {0, 2, 0, 2, 0}, {2, 0, 2, 0, 2}, {0, 2, 2, 2, 0},
+		},
+		payTable: map[pb.LineGameElementType][]int64{
+			n1: {0, 0, 0, 6, 12, 30},
+			n2: {0, 0, 0, 6, 18, 36},
+			n3: {0, 0, 0, 12, 24, 60},
+			n4: {0, 0, 0, 12, 30, 90},
+			n5: {0, 0, 0, 18, 48, 120},
+			n6: {0, 0, 0, 24, 60, 180},
+			n7: {0, 0, 0, 30, 120, 360},
+			w:  {0, 0, 0, 50, 200, 1000},
+		},
+		freeSpinTable:    []int32{0, 0, 0, 10, 15, 20},
+		freeSpinMultiple: 2,
+	}
+}
@@ -0,0 +1,246 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["LinkUpPlay"] = &LinkUpPlay{}
+}
+
+// LinkUpPlay 连连看游戏的玩耍组件，用于处理生成图、消除图标和观战的逻辑
+type LinkUpPlay struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *LinkUpPlay) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *LinkUpPlay) Start() {
+	obj.Base.Start()
+}
+
+// Drive 连连看玩耍阶段的主驱动
+// 开始时生成一张可以全部消除的图，所有玩家拿到同样的图比赛消除；
+// 有玩家全部消除完或者玩耍时间到了进入结算阶段
+func (obj *LinkUpPlay) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowTime := time.Now().Unix()
+	if request.GetNextRoomState() != pb.RoomState_RoomStatePlay {
+		if nowTime < request.GetDoTime() && !isAnyFinished(request) {
+			return request, nil
+		}
+		request.CurRoomState = request.GetNextRoomState()
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	playTime, msgErr := getRoomConfigInt64(request, "PlayTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	boardRow, msgErr := getRoomConfigInt64(request, "BoardRow")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	boardColumn, msgErr := getRoomConfigInt64(request, "BoardColumn")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	iconKindNum, msgErr := getRoomConfigInt64(request, "IconKindNum")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	// 推送房间状态 准备<->玩耍
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateReady,
+		AfterState:        pb.RoomState_RoomStatePlay,
+		AfterStateEndTime: nowTime + playTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	board := generateBoard(int32(boardRow), int32(boardColumn), int(iconKindNum))
+	request.IconPoints = board.getIconPoints()
+	// 机器人根据血池状态控制消除的速度
+	request.LinkUpBloodSlotStatus = common.BloodGetState(request.GetGameType(), request.GetGameScene())
+	for _, onePlayer := range getPlayPlayers(request) {
+		onePlayer.IconPoints = board.getIconPoints()
+		onePlayer.LinkUpCompletionRates = 0
+		pushIconsChange := &pb.PushPlayerIconsChange{
+			RoomId:    request.GetUuid(),
+			RestIcons: onePlayer.GetIconPoints(),
+		}
+		common.Pusher.Push(pushIconsChange, onePlayer.GetUuid())
+	}
+
+	request.NextRoomState = pb.RoomState_RoomStateSettle
+	request.DoTime = nowTime + playTime
+	return request, nil
+}
+
+// isAnyFinished 是否有玩家已经全部消除完了
+func isAnyFinished(request *pb.RoomInfo) bool {
+	for _, onePlayer := range getPlayPlayers(request) {
+		if onePlayer.GetLinkUpCompletionRates() >= fullCompletionRates {
+			return true
+		}
+	}
+	return false
+}
+
+// pushPlayerInfoChange 推送所有游戏中玩家的完成率
+func pushPlayerInfoChange(request *pb.RoomInfo) {
+	pushPlayerInfo := &pb.LinkUpPlayerInfoChange{
+		RoomId:     request.GetUuid(),
+		PlayerInfo: getPlayPlayers(request),
+	}
+	common.RoomBroadcast(request, pushPlayerInfo)
+}
+
+// RequestClearIcon 玩家消除一对图标
+// 两个图标一样并且可以用拐弯不超过两次的线连起来才能消除，消除后没有可以消除的图标时自动洗牌，
+// 消除的结果推送给正在观战这个玩家的人，完成率推送给房间所有人
+func (obj *LinkUpPlay) RequestClearIcon(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || roomInfo.GetNextRoomState() == pb.RoomState_RoomStatePlay {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.LinkUpClearIconRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("LinkUpPlay RequestClearIcon ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	if playerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay ||
+		playerInfo.GetLinkUpCompletionRates() >= fullCompletionRates {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+	}
+	boardRow, msgErr := getRoomConfigInt64(roomInfo, "BoardRow")
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	boardColumn, msgErr := getRoomConfigInt64(roomInfo, "BoardColumn")
+	if msgErr != nil {
+		return reply, msgErr
+	}
+
+	board := newBoardByIconPoints(int32(boardRow), int32(boardColumn), playerInfo.GetIconPoints())
+	pointA, pointB := realRequest.GetIconPointA(), realRequest.GetIconPointB()
+	iconA, iconB := board.getIcon(pointA.GetX(), pointA.GetY()), board.getIcon(pointB.GetX(), pointB.GetY())
+	clearReply := &pb.LinkUpClearIconReply{
+		CompletionRates: playerInfo.GetLinkUpCompletionRates(),
+		RestIcons:       playerInfo.GetIconPoints(),
+	}
+	if !isSameIcon(iconA, iconB) {
+		return packReply(roomInfo, clearReply)
+	}
+	path := board.findPath(pointA.GetX(), pointA.GetY(), pointB.GetX(), pointB.GetY())
+	if path == nil {
+		return packReply(roomInfo, clearReply)
+	}
+	path[0].Icon = iconA
+	path[len(path)-1].Icon = iconB
+	board.cells[pointA.GetX()+1][pointA.GetY()+1] = nil
+	board.cells[pointB.GetX()+1][pointB.GetY()+1] = nil
+	if board.findMove() == nil {
+		board.shuffle()
+	}
+
+	playerInfo.IconPoints = board.getIconPoints()
+	playerInfo.LinkUpCompletionRates = getCompletionRates(len(roomInfo.GetIconPoints()), len(playerInfo.GetIconPoints()))
+	clearReply.IsSuccess = true
+	clearReply.IconPointLine = path
+	clearReply.CompletionRates = playerInfo.GetLinkUpCompletionRates()
+	clearReply.RestIcons = playerInfo.GetIconPoints()
+
+	// 观战的玩家实时看到被观战玩家的图
+	pushIconsChange := &pb.PushPlayerIconsChange{
+		RoomId:    roomInfo.GetUuid(),
+		RestIcons: playerInfo.GetIconPoints(),
+		LineIcons: path,
+	}
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() != "" && onePlayer.GetLinkUpWatchPlayerUuid() == uid {
+			common.Pusher.Push(pushIconsChange, onePlayer.GetUuid())
+		}
+	}
+	pushPlayerInfoChange(roomInfo)
+
+	// 全部消除完了直接进入结算
+	if playerInfo.GetLinkUpCompletionRates() >= fullCompletionRates {
+		roomInfo.DoTime = time.Now().Unix()
+	}
+	return packReply(roomInfo, clearReply)
+}
+
+// RequestWatchPlayer 观战其他玩家，uuid为空时取消观战
+// 正在比赛的玩家不能观战，已经全部消除完的玩家和没有参加本局的玩家可以观战游戏中的其他玩家
+func (obj *LinkUpPlay) RequestWatchPlayer(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	realRequest := &pb.LinkUpWatchPlayerRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("LinkUpPlay RequestWatchPlayer ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	if realRequest.GetUuid() == "" {
+		playerInfo.LinkUpWatchPlayerUuid = ""
+		return packReply(roomInfo, &pb.LinkUpWatchPlayerReply{Success: true})
+	}
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || roomInfo.GetNextRoomState() == pb.RoomState_RoomStatePlay {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	if playerInfo.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay &&
+		playerInfo.GetLinkUpCompletionRates() < fullCompletionRates {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_LinkUpErrCodeErrorWatchInPlay, "")
+	}
+	watchPlayerInfo := common.GetRoomPlayerInfo(roomInfo, realRequest.GetUuid())
+	if watchPlayerInfo == nil || realRequest.GetUuid() == uid ||
+		watchPlayerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_LinkUpErrCodeErrorWatchPlayNotExit, "")
+	}
+	playerInfo.LinkUpWatchPlayerUuid = realRequest.GetUuid()
+	return packReply(roomInfo, &pb.LinkUpWatchPlayerReply{
+		Success:         true,
+		WatchPlayerInfo: watchPlayerInfo,
+	})
+}
+
+// RequestExitInGame 玩家在对局中退出房间
+// 这里只标记为等待踢出，按照退出时的完成率参与结算，结算后状态置空由房间的Kick踢出
+func (obj *LinkUpPlay) RequestExitInGame(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	playerInfo.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_Exit
+	playerInfo.LinkUpWatchPlayerUuid = ""
+	// 结算阶段本局已经结算完了，可以直接踢出
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStateSettle && roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+	}
+	return packReply(roomInfo, &pb.GameExitRoomReply{})
+}
@@ -0,0 +1,316 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// 完成率的满值，完成率按百分比计算
+const fullCompletionRates = 100
+
+// 生成可解的图和洗牌的最大尝试次数
+const maxTryTimes = 100
+
+// linkUpBoard 连连看的图，四周各留一圈空位，消除路径可以从图的外面绕过去
+// 坐标X是列，Y是行，cells按[X+1][Y+1]保存图标，没有图标的位置为nil
+type linkUpBoard struct {
+	row    int32
+	column int32
+	cells  [][]*pb.Icon
+}
+
+// getRoomConfigInt64 获取房间配置中的整数配置
+func getRoomConfigInt64(roomInfo *pb.RoomInfo, configName string) (int64, *pb.ErrorMessage) {
+	configStr := common.GetRoomConfig(roomInfo, configName)
+	configNum, err := strconv.ParseInt(configStr, 10, 64)
+	if err != nil {
+		common.LogError("LinkUp getRoomConfigInt64 has err", configName, configStr, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return configNum, nil
+}
+
+// getRoomConfigInt64List 获取房间配置中以逗号分隔的整数列表配置
+func getRoomConfigInt64List(roomInfo *pb.RoomInfo, configName string) ([]int64, *pb.ErrorMessage) {
+	configStr := common.GetRoomConfig(roomInfo, configName)
+	var configList []int64
+	for _, oneStr := range strings.Split(configStr, ",") {
+		configNum, err := strconv.ParseInt(oneStr, 10, 64)
+		if err != nil {
+			common.LogError("LinkUp getRoomConfigInt64List has err", configName, configStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		configList = append(configList, configNum)
+	}
+	return configList, nil
+}
+
+// getPlayPlayers 获取本局游戏中的玩家
+func getPlayPlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+			continue
+		}
+		players = append(players, onePlayer)
+	}
+	return players
+}
+
+// newBoard 创建一个row行column列的空图
+func newBoard(row int32, column int32) *linkUpBoard {
+	board := &linkUpBoard{row: row, column: column}
+	board.cells = make([][]*pb.Icon, column+2)
+	for x := range board.cells {
+		board.cells[x] = make([]*pb.Icon, row+2)
+	}
+	return board
+}
+
+// newBoardByIconPoints 用玩家剩余的图标还原出图
+func newBoardByIconPoints(row int32, column int32, iconPoints []*pb.IconPoint) *linkUpBoard {
+	board := newBoard(row, column)
+	for _, onePoint := range iconPoints {
+		if board.isInBoard(onePoint.GetX(), onePoint.GetY()) {
+			board.cells[onePoint.GetX()+1][onePoint.GetY()+1] = onePoint.GetIcon()
+		}
+	}
+	return board
+}
+
+// isInBoard 坐标是否在图内（不包括四周的空位）
+func (b *linkUpBoard) isInBoard(x int32, y int32) bool {
+	return x >= 0 && x < b.column && y >= 0 && y < b.row
+}
+
+// getIcon 获取坐标上的图标，超出图和四周空位的范围也返回nil
+func (b *linkUpBoard) getIcon(x int32, y int32) *pb.Icon {
+	if x < -1 || x > b.column || y < -1 || y > b.row {
+		return nil
+	}
+	return b.cells[x+1][y+1]
+}
+
+// isEmpty 坐标是否可以通过，四周的空位可以通过，再往外就不行了
+func (b *linkUpBoard) isEmpty(x int32, y int32) bool {
+	if x < -1 || x > b.column || y < -1 || y > b.row {
+		return false
+	}
+	return b.cells[x+1][y+1] == nil
+}
+
+// getIconPoints 获取图上剩余的所有图标
+func (b *linkUpBoard) getIconPoints() []*pb.IconPoint {
+	var iconPoints []*pb.IconPoint
+	for x := int32(0); x < b.column; x++ {
+		for y := int32(0); y < b.row; y++ {
+			if icon := b.getIcon(x, y); icon != nil {
+				iconPoints = append(iconPoints, &pb.IconPoint{X: x, Y: y, Icon: icon})
+			}
+		}
+	}
+	return iconPoints
+}
+
+// isLineClear 两个点在同一行或同一列上，并且中间没有图标挡住
+func (b *linkUpBoard) isLineClear(ax int32, ay int32, bx int32, by int32) bool {
+	if ax != bx && ay != by {
+		return false
+	}
+	stepX, stepY := getStep(ax, bx), getStep(ay, by)
+	for x, y := ax+stepX, ay+stepY; x != bx || y != by; x, y = x+stepX, y+stepY {
+		if !b.isEmpty(x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// getStep 从from走向to每一步的方向
+func getStep(from int32, to int32) int32 {
+	switch {
+	case to > from:
+		return 1
+	case to < from:
+		return -1
+	}
+	return 0
+}
+
+// findPath 查找两个点之间拐弯不超过两次的路径，两个点本身不检查是否为空
+// 找到时返回按顺序连接的起点、拐点和终点，两次拐弯的路径有多条时返回最短的一条；找不到返回nil
+func (b *linkUpBoard) findPath(ax int32, ay int32, bx int32, by int32) []*pb.IconPoint {
+	if ax == bx && ay == by {
+		return nil
+	}
+	start, end := &pb.IconPoint{X: ax, Y: ay}, &pb.IconPoint{X: bx, Y: by}
+	// 直连
+	if b.isLineClear(ax, ay, bx, by) {
+		return []*pb.IconPoint{start, end}
+	}
+	// 一次拐弯
+	if corner := b.findOneCorner(ax, ay, bx, by); corner != nil {
+		return []*pb.IconPoint{start, corner, end}
+	}
+	// 两次拐弯：从起点沿着横竖方向走到一个空位，再从这个空位一次拐弯到终点
+	var bestPath []*pb.IconPoint
+	bestLength := int32(-1)
+	for x := int32(-1); x <= b.column; x++ {
+		for y := int32(-1); y <= b.row; y++ {
+			if (x != ax && y != ay) || (x == ax && y == ay) || !b.isEmpty(x, y) || !b.isLineClear(ax, ay, x, y) {
+				continue
+			}
+			corner := b.findOneCorner(x, y, bx, by)
+			if corner == nil {
+				continue
+			}
+			length := absInt32(x-ax) + absInt32(y-ay) + absInt32(corner.GetX()-x) + absInt32(corner.GetY()-y) +
+				absInt32(bx-corner.GetX()) + absInt32(by-corner.GetY())
+			if bestLength < 0 || length < bestLength {
+				bestLength = length
+				bestPath = []*pb.IconPoint{start, {X: x, Y: y}, corner, end}
+			}
+		}
+	}
+	return bestPath
+}
+
+// findOneCorner 查找两个点之间只拐一次弯的拐点，找不到返回nil
+func (b *linkUpBoard) findOneCorner(ax int32, ay int32, bx int32, by int32) *pb.IconPoint {
+	if ax == bx || ay == by {
+		return nil
+	}
+	for _, corner := range []*pb.IconPoint{{X: ax, Y: by}, {X: bx, Y: ay}} {
+		if b.isEmpty(corner.GetX(), corner.GetY()) &&
+			b.isLineClear(ax, ay, corner.GetX(), corner.GetY()) && b.isLineClear(corner.GetX(), corner.GetY(), bx, by) {
+			return corner
+		}
+	}
+	return nil
+}
+
+// isSameIcon 两个图标是否一样
+func isSameIcon(a *pb.Icon, b *pb.Icon) bool {
+	return a != nil && b != nil && a.GetIconType() == b.GetIconType() && a.GetIconNum() == b.GetIconNum()
+}
+
+// findMove 查找图上一对可以消除的图标，没有可以消除的返回nil
+func (b *linkUpBoard) findMove() []*pb.IconPoint {
+	iconPoints := b.getIconPoints()
+	for i := 0; i < len(iconPoints); i++ {
+		for j := i + 1; j < len(iconPoints); j++ {
+			pointA, pointB := iconPoints[i], iconPoints[j]
+			if !isSameIcon(pointA.GetIcon(), pointB.GetIcon()) {
+				continue
+			}
+			if b.findPath(pointA.GetX(), pointA.GetY(), pointB.GetX(), pointB.GetY()) != nil {
+				return []*pb.IconPoint{pointA, pointB}
+			}
+		}
+	}
+	return nil
+}
+
+// shuffle 打乱图上剩余图标的位置（位置不变，只交换图标），直到有可以消除的图标为止
+// 尝试maxTryTimes次还没有可以消除的就用最后一次的结果，下一次消除后会继续洗牌
+func (b *linkUpBoard) shuffle() {
+	iconPoints := b.getIconPoints()
+	if len(iconPoints) == 0 {
+		return
+	}
+	icons := make([]*pb.Icon, len(iconPoints))
+	for index, onePoint := range iconPoints {
+		icons[index] = onePoint.GetIcon()
+	}
+	for tryTimes := 0; tryTimes < maxTryTimes; tryTimes++ {
+		rand.Shuffle(len(icons), func(i, j int) {
+			icons[i], icons[j] = icons[j], icons[i]
+		})
+		for index, onePoint := range iconPoints {
+			b.cells[onePoint.GetX()+1][onePoint.GetY()+1] = icons[index]
+		}
+		if b.findMove() != nil {
+			return
+		}
+	}
+}
+
+// getAllIcons 获取所有可以使用的图标，每种图标类型都有IconNum1到IconNum8
+func getAllIcons() []*pb.Icon {
+	var icons []*pb.Icon
+	for iconType := pb.IconType_IconTypeAnimal; iconType <= pb.IconType_IconTypeGreens; iconType++ {
+		for iconNum := pb.IconNum_IconNum1; iconNum <= pb.IconNum_IconNum8; iconNum++ {
+			icons = append(icons, &pb.Icon{IconType: iconType, IconNum: iconNum})
+		}
+	}
+	return icons
+}
+
+// generateBoard 生成一张可以全部消除的图
+// 随机摆放成对的图标后模拟一遍消除，能消完才使用，消不完就重新生成
+func generateBoard(row int32, column int32, iconKindNum int) *linkUpBoard {
+	allIcons := getAllIcons()
+	if iconKindNum <= 0 || iconKindNum > len(allIcons) {
+		iconKindNum = len(allIcons)
+	}
+	pairNum := int(row*column) / 2
+	var board *linkUpBoard
+	for tryTimes := 0; tryTimes < maxTryTimes; tryTimes++ {
+		rand.Shuffle(len(allIcons), func(i, j int) {
+			allIcons[i], allIcons[j] = allIcons[j], allIcons[i]
+		})
+		icons := make([]*pb.Icon, 0, pairNum*2)
+		for index := 0; index < pairNum; index++ {
+			icons = append(icons, allIcons[index%iconKindNum], allIcons[index%iconKindNum])
+		}
+		rand.Shuffle(len(icons), func(i, j int) {
+			icons[i], icons[j] = icons[j], icons[i]
+		})
+		board = newBoard(row, column)
+		for index, oneIcon := range icons {
+			board.cells[int32(index)%column+1][int32(index)/column+1] = oneIcon
+		}
+		if board.isSolvable() {
+			return board
+		}
+	}
+	// 一直生成失败的话用最后一次的图，保证开局有可以消除的图标，之后消不动了会自动洗牌
+	common.LogError("LinkUp generateBoard failed, use random board", row, column, iconKindNum)
+	if board.findMove() == nil {
+		board.shuffle()
+	}
+	return board
+}
+
+// isSolvable 在图的副本上按顺序一直消除能消除的图标，判断是否可以全部消完
+func (b *linkUpBoard) isSolvable() bool {
+	board := newBoardByIconPoints(b.row, b.column, b.getIconPoints())
+	for {
+		move := board.findMove()
+		if move == nil {
+			return len(board.getIconPoints()) == 0
+		}
+		for _, onePoint := range move {
+			board.cells[onePoint.GetX()+1][onePoint.GetY()+1] = nil
+		}
+	}
+}
+
+// getCompletionRates 根据剩余的图标数计算完成率
+func getCompletionRates(totalNum int, restNum int) int32 {
+	if totalNum <= 0 {
+		return fullCompletionRates
+	}
+	return int32((totalNum - restNum) * fullCompletionRates / totalNum)
+}
+
+// absInt32 取绝对值
+func absInt32(num int32) int32 {
+	if num < 0 {
+		return -num
+	}
+	return num
+}
@@ -0,0 +1,256 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["LinkUpSettle"] = &LinkUpSettle{}
+}
+
+// LinkUpSettle 连连看游戏的结算组件，用于处理结算阶段的逻辑
+type LinkUpSettle struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *LinkUpSettle) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *LinkUpSettle) Start() {
+	obj.Base.Start()
+}
+
+// Drive 连连看结算组件主驱动
+func (obj *LinkUpSettle) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	// 结算 <-> 准备
+	if request.NextRoomState != pb.RoomState_RoomStateSettle {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateReady
+		request.NextRoomState = pb.RoomState_RoomStateReady
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	settleTimeStr := common.GetRoomConfig(request, "SettleTime")
+	settleTime, err := strconv.Atoi(settleTimeStr)
+	if err != nil {
+		common.LogError("LinkUpSettle Drive settleTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	// 推送房间状态 玩耍<->结算
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStatePlay,
+		AfterState:        pb.RoomState_RoomStateSettle,
+		AfterStateEndTime: nowTime + int64(settleTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	msgErr := obj.settle(request, nowTime)
+	if msgErr != nil {
+		return request, msgErr
+	}
+
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" {
+			continue
+		}
+		if onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+			onePlayer.PlayNum++
+		}
+		// 对局中退出的玩家在结算完成后踢出
+		if onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None {
+			onePlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+		}
+	}
+	request.NextRoomState = pb.RoomState_RoomStateReady
+	request.DoTime = nowTime + int64(settleTime)
+	return request, nil
+}
+
+// settle 游戏中的玩家每人出底分（不超过玩家的金币）放进奖池，按完成率排名，
+// 奖池按照RankRates配置的名次比例分给排名靠前的玩家，人数少于配置的名次时只按有人的名次比例分配，
+// 完成率一样的按座位顺序排名；赢的部分按照抽水比例抽水，修改玩家金币
+func (obj *LinkUpSettle) settle(request *pb.RoomInfo, nowTime int64) *pb.ErrorMessage {
+	commission, msgErr := getRoomConfigInt64(request, "Commission")
+	if msgErr != nil {
+		return msgErr
+	}
+	baseScore, msgErr := getRoomConfigInt64(request, "BaseScore")
+	if msgErr != nil {
+		return msgErr
+	}
+	rankRates, msgErr := getRoomConfigInt64List(request, "RankRates")
+	if msgErr != nil {
+		return msgErr
+	}
+	players := getPlayPlayers(request)
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].GetLinkUpCompletionRates() > players[j].GetLinkUpCompletionRates()
+	})
+	if len(rankRates) > len(players) {
+		rankRates = rankRates[:len(players)]
+	}
+	var totalRates int64
+	for _, oneRate := range rankRates {
+		totalRates += oneRate
+	}
+
+	// 1.计算输赢和抽水
+	var pool int64
+	antes := make([]int64, len(players))
+	for index, onePlayer := range players {
+		antes[index] = baseScore
+		if antes[index] > onePlayer.GetBalance() {
+			antes[index] = onePlayer.GetBalance()
+		}
+		pool += antes[index]
+	}
+	rewards := make([]int64, len(players))
+	if totalRates > 0 {
+		var rewardSum int64
+		for index, oneRate := range rankRates {
+			rewards[index] = pool * oneRate / totalRates
+			rewardSum += rewards[index]
+		}
+		// 除不尽的部分给第一名
+		rewards[0] += pool - rewardSum
+	}
+	for index, onePlayer := range players {
+		winOrLose := rewards[index] - antes[index]
+		if winOrLose > 0 {
+			water := winOrLose * commission / 100
+			winOrLose -= water
+			onePlayer.HundredCommission = water
+		}
+		onePlayer.WinOrLose = winOrLose
+	}
+	topNum := len(players)
+	if topNum > 3 {
+		topNum = 3
+	}
+	request.LinkUpTopThree = players[:topNum]
+
+	settleInfo := &pb.SettleInfo{}
+	for _, onePlayer := range players {
+		winOrLose := onePlayer.GetWinOrLose()
+		onePlayer.Balance += winOrLose
+		onePlayer.HundredWaterBill = common.AbsInt64(winOrLose)
+
+		settleInfo.SettleUUID = append(settleInfo.SettleUUID, onePlayer.GetUuid())
+		settleInfo.SettleWinOrLose = append(settleInfo.SettleWinOrLose, winOrLose)
+		settleInfo.SettleName = append(settleInfo.SettleName, onePlayer.GetName())
+		settleInfo.ImgUrl = append(settleInfo.ImgUrl, onePlayer.GetHeadImgUrl())
+		settleInfo.AfterBalance = append(settleInfo.AfterBalance, onePlayer.GetBalance())
+		settleInfo.ShortId = append(settleInfo.ShortId, onePlayer.GetShortId())
+	}
+	request.AllSettleInfo = append(request.AllSettleInfo, settleInfo)
+
+	// 推送结算结果
+	pushSettle := &pb.PushRoomSettleInfo{
+		RoomId:     request.GetUuid(),
+		PlayerInfo: players,
+	}
+	common.RoomBroadcast(request, pushSettle)
+
+	// 2.更新血池
+	var score int64
+	for _, onePlayer := range players {
+		if onePlayer.GetIsRobot() {
+			continue
+		}
+		score -= onePlayer.GetWinOrLose() + onePlayer.GetHundredCommission()
+	}
+	msgErr = common.BloodIncrease(score, request.GetGameType(), request.GetGameScene())
+	if msgErr != nil {
+		common.LogError("LinkUpSettle settle BloodIncrease has err:", msgErr)
+		return msgErr
+	}
+
+	// 3.修改玩家真实的Money
+	for _, onePlayer := range players {
+		// 后面协程操作，为避免错误在此处提取金额
+		addMoney := onePlayer.GetWinOrLose()
+		// 机器人不记录游戏记录
+		var gameRecord *pb.GameRecordReport
+		if !onePlayer.GetIsRobot() {
+			gameRecord = obj.getGameRecord(request, onePlayer, settleInfo, nowTime)
+		}
+		go obj.saveMoney(request, onePlayer, addMoney, gameRecord)
+	}
+	return nil
+}
+
+// getGameRecord 生成玩家本局的游戏记录，金额在saveMoney中修改完玩家金币后补全
+func (obj *LinkUpSettle) getGameRecord(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, settleInfo *pb.SettleInfo, settleTime int64) *pb.GameRecordReport {
+	extendData := &pb.GameRecordExtendData{}
+	extendData.AllSettleInfo = []*pb.SettleInfo{settleInfo}
+
+	gameRecord := &pb.GameRecordReport{}
+	gameRecord.GameType = roomInfo.GetGameType()
+	gameRecord.GameScene = roomInfo.GetGameScene()
+	gameRecord.GameMode = common.GameMode
+	gameRecord.RoomType = roomInfo.GetRoomType()
+	gameRecord.RoomId = roomInfo.GetUuid()
+	gameRecord.RoundId = roomInfo.GetCurrentRoundId()
+	gameRecord.PlayerUuid = onePlayer.GetUuid()
+	gameRecord.PlayerShortId = onePlayer.GetShortId()
+	gameRecord.PlayerAccount = onePlayer.GetAccount()
+	gameRecord.StartTime = roomInfo.GetRoundStartTime()
+	gameRecord.SettleTime = settleTime
+	gameRecord.WinOrLose = onePlayer.GetWinOrLose()
+	gameRecord.Commission = onePlayer.GetHundredCommission()
+	gameRecord.ExtendData = extendData
+	return gameRecord
+}
+
+// saveMoney 修改玩家金币并推送金币变动
+func (obj *LinkUpSettle) saveMoney(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, addMoney int64, gameRecord *pb.GameRecordReport) {
+	taskConfig := &pb.TaskConfig{}
+	taskConfig.TaskType = pb.TaskType_Task_PlayGame
+	taskConfig.GameType = roomInfo.GetGameType()
+	taskConfig.GameScene = roomInfo.GetGameScene()
+	taskConfig.TaskNum = 1
+	cateGoryList := strings.Split(common.GetRoomConfig(roomInfo, "CateGory"), ",")
+	for _, oneCateGoryStr := range cateGoryList {
+		oneCateGoryInt, err := strconv.Atoi(oneCateGoryStr)
+		if err != nil {
+			common.LogError("LinkUpSettle saveMoney oneCateGoryStr has err", oneCateGoryStr, err)
+		}
+		taskConfig.GameCateGoryType = append(taskConfig.GetGameCateGoryType(), pb.GameCateGoryType(oneCateGoryInt))
+	}
+	afterBalance, msgErr := common.ChangePlayerInfoAfterGameEnd(onePlayer.GetUuid(), addMoney, taskConfig, pb.ResourceChangeReason_LinkUpSettleGold)
+	if msgErr != nil {
+		return
+	}
+	if afterBalance != onePlayer.Balance {
+		common.LogError("LinkUpSettle saveMoney has err: afterBalance != onePlayer.Balance", afterBalance, onePlayer.Balance, "输赢：", onePlayer.WinOrLose)
+	}
+	// 推送游戏记录
+	if gameRecord != nil {
+		gameRecord.BeforeBalance = afterBalance - addMoney
+		gameRecord.SettleBalance = afterBalance
+		msgErr = common.PushGameRecord(gameRecord)
+		if msgErr != nil {
+			common.LogError("LinkUpSettle saveMoney PushGameRecord has err", onePlayer.GetUuid(), msgErr)
+		}
+	}
+	userBalanceChangePush := &pb.PushUserBalanceChange{}
+	userBalanceChangePush.UserId = onePlayer.GetUuid()
+	userBalanceChangePush.Balance = afterBalance
+	common.RoomBroadcast(roomInfo, userBalanceChangePush)
+}
@@ -0,0 +1,349 @@
+package logic
+
+import (
+	pb "gameServer-demo/src/grpc"
+)
+
+// MeldType 面子的类型
+type MeldType int32
+
+const (
+	// MeldSequence 顺子
+	MeldSequence MeldType = 1
+	// MeldTriplet 刻子
+	MeldTriplet MeldType = 2
+)
+
+// Meld 手牌中的一个面子，Index为刻子的牌或者顺子第一张牌的下标
+type Meld struct {
+	Type  MeldType
+	Index int
+}
+
+// WinSplit 胡牌时手牌的一种拆分方式：一个将对加若干个面子
+type WinSplit struct {
+	PairIndex int
+	Melds     []Meld
+}
+
+// WinChecker 判断一手牌(3n+2张)是否胡牌，不同的麻将规则提供不同的判断
+type WinChecker func(counts TileCounts) bool
+
+// CanWinNormal 是否是基本胡牌牌型：一个将对加若干个面子
+func CanWinNormal(counts TileCounts) bool {
+	total := 0
+	for _, num := range counts {
+		total += num
+	}
+	if total%3 != 2 {
+		return false
+	}
+	for index := range counts {
+		if counts[index] < 2 {
+			continue
+		}
+		counts[index] -= 2
+		canSplit := canSplitMelds(&counts, 0)
+		counts[index] += 2
+		if canSplit {
+			return true
+		}
+	}
+	return false
+}
+
+// canSplitMelds 从下标from开始，剩下的牌能否全部拆成面子
+func canSplitMelds(counts *TileCounts, from int) bool {
+	for from < TileKindNum && counts[from] == 0 {
+		from++
+	}
+	if from == TileKindNum {
+		return true
+	}
+	if counts[from] >= 3 {
+		counts[from] -= 3
+		canSplit := canSplitMelds(counts, from)
+		counts[from] += 3
+		if canSplit {
+			return true
+		}
+	}
+	if canSequence(counts, from) {
+		counts[from]--
+		counts[from+1]--
+		counts[from+2]--
+		canSplit := canSplitMelds(counts, from)
+		counts[from]++
+		counts[from+1]++
+		counts[from+2]++
+		if canSplit {
+			return true
+		}
+	}
+	return false
+}
+
+// canSequence 以下标index开头能否组成顺子，只有同一花色的序数牌能组成顺子
+func canSequence(counts *TileCounts, index int) bool {
+	if !IsSuitIndex(index) || index%9 > 6 {
+		return false
+	}
+	return counts[index] > 0 && counts[index+1] > 0 && counts[index+2] > 0
+}
+
+// GetWinSplits 获取基本胡牌牌型的所有拆分方式，用于判断碰碰胡、幺九等需要看面子的番型
+func GetWinSplits(counts TileCounts) []*WinSplit {
+	var splits []*WinSplit
+	for index := range counts {
+		if counts[index] < 2 {
+			continue
+		}
+		counts[index] -= 2
+		pairIndex := index
+		collectSplits(&counts, 0, nil, func(melds []Meld) {
+			splits = append(splits, &WinSplit{PairIndex: pairIndex, Melds: append([]Meld{}, melds...)})
+		})
+		counts[index] += 2
+	}
+	return splits
+}
+
+// collectSplits 递归拆出所有的面子组合，每拆完一种调用一次fn
+func collectSplits(counts *TileCounts, from int, melds []Meld, fn func(melds []Meld)) {
+	for from < TileKindNum && counts[from] == 0 {
+		from++
+	}
+	if from == TileKindNum {
+		fn(melds)
+		return
+	}
+	if counts[from] >= 3 {
+		counts[from] -= 3
+		collectSplits(counts, from, append(melds, Meld{Type: MeldTriplet, Index: from}), fn)
+		counts[from] += 3
+	}
+	if canSequence(counts, from) {
+		counts[from]--
+		counts[from+1]--
+		counts[from+2]--
+		collectSplits(counts, from, append(melds, Meld{Type: MeldSequence, Index: from}), fn)
+		counts[from]++
+		counts[from+1]++
+		counts[from+2]++
+	}
+}
+
+// IsSevenPairs 是否是七对，四张一样的牌算两对
+func IsSevenPairs(counts TileCounts) bool {
+	total := 0
+	for _, num := range counts {
+		if num%2 != 0 {
+			return false
+		}
+		total += num
+	}
+	return total == 14
+}
+
+// IsThirteenOrphans 是否是十三幺：筒条万的一和九、七种字牌各一张，其中一种再多一张做将
+func IsThirteenOrphans(counts TileCounts) bool {
+	total, pairNum := 0, 0
+	for index, num := range counts {
+		isOrphan := !IsSuitIndex(index) || isOneNineIndex(index)
+		if num > 2 || (isOrphan && num == 0) || (!isOrphan && num > 0) {
+			return false
+		}
+		if num == 2 {
+			pairNum++
+		}
+		total += num
+	}
+	return total == 14 && pairNum == 1
+}
+
+// IsBadCards 是否是烂牌(十三烂)：十四张牌各不相同，同一花色的序数牌之间至少相差三个点数，字牌不限
+func IsBadCards(counts TileCounts) bool {
+	total, lastSuitIndex := 0, -1
+	for index, num := range counts {
+		if num > 1 {
+			return false
+		}
+		if num == 0 {
+			continue
+		}
+		total++
+		if !IsSuitIndex(index) {
+			continue
+		}
+		if IsSuitIndex(lastSuitIndex) && lastSuitIndex/9 == index/9 && index-lastSuitIndex < 3 {
+			return false
+		}
+		lastSuitIndex = index
+	}
+	return total == 14
+}
+
+// GetHonorKindNum 获取字牌的种类数
+func GetHonorKindNum(counts TileCounts) int {
+	kindNum := 0
+	for index, num := range counts {
+		if !IsSuitIndex(index) && num > 0 {
+			kindNum++
+		}
+	}
+	return kindNum
+}
+
+// IsAllOneNineOrHonor 牌是否都是序数牌的一、九或者字牌
+func IsAllOneNineOrHonor(mahjongs []*pb.Mahjong) bool {
+	for _, oneMahjong := range mahjongs {
+		index := GetTileIndex(oneMahjong)
+		if index < 0 || (IsSuitIndex(index) && !isOneNineIndex(index)) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetQuadNum 获取四张一样的牌的种类数
+func GetQuadNum(counts TileCounts) int {
+	quadNum := 0
+	for _, num := range counts {
+		if num == 4 {
+			quadNum++
+		}
+	}
+	return quadNum
+}
+
+// IsAllTriplet 拆分是否全是刻子
+func IsAllTriplet(split *WinSplit) bool {
+	for _, oneMeld := range split.Melds {
+		if oneMeld.Type != MeldTriplet {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAllWithOneNine 拆分的将对和每个面子是否都带有一或者九
+func IsAllWithOneNine(split *WinSplit) bool {
+	if !isOneNineIndex(split.PairIndex) {
+		return false
+	}
+	for _, oneMeld := range split.Melds {
+		if oneMeld.Type == MeldTriplet && !isOneNineIndex(oneMeld.Index) {
+			return false
+		}
+		if oneMeld.Type == MeldSequence && !isOneNineIndex(oneMeld.Index) && !isOneNineIndex(oneMeld.Index+2) {
+			return false
+		}
+	}
+	return true
+}
+
+// isOneNineIndex 下标是否是序数牌的一或者九
+func isOneNineIndex(index int) bool {
+	num := GetIndexNum(index)
+	return num == 1 || num == 9
+}
+
+// IsAllNum 牌是否都是指定点数的序数牌
+func IsAllNum(mahjongs []*pb.Mahjong, nums ...int) bool {
+	for _, oneMahjong := range mahjongs {
+		index := GetTileIndex(oneMahjong)
+		isMatch := false
+		for _, num := range nums {
+			if IsSuitIndex(index) && GetIndexNum(index) == num {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSameColor 牌是否都是同一种花色
+func IsSameColor(mahjongs []*pb.Mahjong) bool {
+	for _, oneMahjong := range mahjongs {
+		if oneMahjong.GetMahjongColor() != mahjongs[0].GetMahjongColor() {
+			return false
+		}
+	}
+	return true
+}
+
+// GetReadyMahjongs 获取手牌(3n+1张)听的所有牌，手里已经有四张的牌不能再胡
+func GetReadyMahjongs(hand []*pb.Mahjong, checker WinChecker) []*pb.Mahjong {
+	var readyMahjongs []*pb.Mahjong
+	counts := GetTileCounts(hand)
+	for index := range counts {
+		if counts[index] >= 4 {
+			continue
+		}
+		counts[index]++
+		if checker(counts) {
+			readyMahjongs = append(readyMahjongs, GetTileByIndex(index))
+		}
+		counts[index]--
+	}
+	return readyMahjongs
+}
+
+// GetIfOutputInfo 分析手牌(3n+2张)打出每一张牌后能听的牌
+// remainNum 返回某张牌还剩多少张没有出现，由具体的游戏根据场上的牌计算
+func GetIfOutputInfo(hand []*pb.Mahjong, checker WinChecker, remainNum func(mahjong *pb.Mahjong) int32) []*pb.MahjongReadyInfoIfOutput {
+	var ifOutputInfo []*pb.MahjongReadyInfoIfOutput
+	for _, outputMahjong := range GetDistinctMahjongs(hand) {
+		afterHand, _ := RemoveMahjong(hand, outputMahjong, 1)
+		readyMahjongs := GetReadyMahjongs(afterHand, checker)
+		if len(readyMahjongs) == 0 {
+			continue
+		}
+		oneInfo := &pb.MahjongReadyInfoIfOutput{IfOutputCard: outputMahjong}
+		for _, readyMahjong := range readyMahjongs {
+			oneInfo.ReadyDetail = append(oneInfo.ReadyDetail, &pb.MahjongReadyDetail{
+				WinCard:       readyMahjong,
+				RemainCardNum: remainNum(readyMahjong),
+			})
+		}
+		ifOutputInfo = append(ifOutputInfo, oneInfo)
+	}
+	return ifOutputInfo
+}
+
+// CanPong 手牌能否碰别人打出的牌
+func CanPong(hand []*pb.Mahjong, mahjong *pb.Mahjong) bool {
+	return CountMahjong(hand, mahjong) >= 2
+}
+
+// CanKongOutput 手牌能否直杠别人打出的牌
+func CanKongOutput(hand []*pb.Mahjong, mahjong *pb.Mahjong) bool {
+	return CountMahjong(hand, mahjong) == 3
+}
+
+// GetAnKongMahjongs 获取手牌中可以暗杠的牌
+func GetAnKongMahjongs(hand []*pb.Mahjong) []*pb.Mahjong {
+	var kongMahjongs []*pb.Mahjong
+	counts := GetTileCounts(hand)
+	for index, num := range counts {
+		if num == 4 {
+			kongMahjongs = append(kongMahjongs, GetTileByIndex(index))
+		}
+	}
+	return kongMahjongs
+}
+
+// GetBaKongMahjongs 获取手牌中可以巴杠(补杠已经碰了的牌)的牌
+func GetBaKongMahjongs(hand []*pb.Mahjong, pongRegion []*pb.MahjongPongInfo) []*pb.Mahjong {
+	var kongMahjongs []*pb.Mahjong
+	for _, onePong := range pongRegion {
+		if CountMahjong(hand, onePong.GetPongMahjongCard()) > 0 {
+			kongMahjongs = append(kongMahjongs, onePong.GetPongMahjongCard())
+		}
+	}
+	return kongMahjongs
+}
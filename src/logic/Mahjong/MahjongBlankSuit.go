@@ -0,0 +1,207 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+// MahjongBlankSuit 麻将游戏的定缺组件，用于处理定缺阶段的逻辑
+type MahjongBlankSuit struct {
+	base.Base
+	Variant *Variant
+}
+
+// LoadComponent 加载组件
+func (obj *MahjongBlankSuit) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *MahjongBlankSuit) Start() {
+	obj.Base.Start()
+}
+
+// Drive 麻将定缺阶段的主驱动
+// 每个玩家选择一种不要的花色，胡牌前必须把这种花色的牌打完；所有人都定缺或者时间到了，
+// 没有定缺的玩家使用推荐的花色，然后公布所有人的定缺并进入打牌阶段
+func (obj *MahjongBlankSuit) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState == pb.RoomState_RoomStateBlankSuit {
+		blankSuitTime, msgErr := GetRoomConfigInt64(request, "BlankSuitTime")
+		if msgErr != nil {
+			return request, msgErr
+		}
+		// 推送房间状态 换三张(发牌)<->定缺
+		beforeState := pb.RoomState_RoomStateDeal
+		if IsRoomConfigOpen(request, "IsChangeThreeCards") {
+			beforeState = pb.RoomState_RoomStateChangeThreeCards
+		}
+		pushRoomState := &pb.PushRoomStateChange{
+			RoomId:            request.GetUuid(),
+			BeforeState:       beforeState,
+			AfterState:        pb.RoomState_RoomStateBlankSuit,
+			AfterStateEndTime: nowTime + blankSuitTime,
+		}
+		common.RoomBroadcast(request, pushRoomState)
+
+		request.NextRoomState = pb.RoomState_RoomStatePlay
+		request.DoTime = nowTime + blankSuitTime
+		for index, onePlayer := range request.GetPlayerInfo() {
+			if !IsPlaying(onePlayer) {
+				continue
+			}
+			defaultBlankSuit := obj.getDefaultBlankSuit(onePlayer.GetMahjongPlayerInfo().GetHandRegion())
+			if IsAutoOperate(onePlayer) {
+				obj.setBlankSuit(request, int32(index), defaultBlankSuit)
+				continue
+			}
+			pushBlankSuit := &pb.MahjongBlankSuitNotice{
+				RoomId:      request.GetUuid(),
+				PlayerIndex: int32(index),
+				BlankSuit:   defaultBlankSuit,
+				WaitTime:    blankSuitTime,
+			}
+			common.Pusher.Push(pushBlankSuit, onePlayer.GetUuid())
+		}
+		obj.checkAllBlankSuit(request, nowTime)
+		return request, nil
+	}
+
+	if nowTime < request.GetDoTime() {
+		return request, nil
+	}
+	gameInfo := request.GetMahjongGameInfo()
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if !IsPlaying(onePlayer) {
+			continue
+		}
+		mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+		if mahjongPlayer.GetBlankSuit() == pb.MahjongColor_MahjongColorNone {
+			obj.setBlankSuit(request, int32(index), obj.getDefaultBlankSuit(mahjongPlayer.GetHandRegion()))
+		}
+		gameInfo.BlankSuit[index] = mahjongPlayer.GetBlankSuit()
+	}
+	gameInfo.HasBeenBlankSuit = true
+	pushAllBlankSuit := &pb.MahjongAllPlayerBlankSuitNotice{
+		RoomId:    request.GetUuid(),
+		BlankSuit: gameInfo.GetBlankSuit(),
+	}
+	common.RoomBroadcast(request, pushAllBlankSuit)
+
+	request.CurRoomState = pb.RoomState_RoomStatePlay
+	request.NextRoomState = pb.RoomState_RoomStatePlay
+	request.DoTime = nowTime
+	return request, nil
+}
+
+// setBlankSuit 记录玩家的定缺，定缺的花色只告诉自己，其他玩家只知道他已经定缺
+func (obj *MahjongBlankSuit) setBlankSuit(request *pb.RoomInfo, index int32, blankSuit pb.MahjongColor) {
+	onePlayer := request.GetPlayerInfo()[index]
+	onePlayer.GetMahjongPlayerInfo().BlankSuit = blankSuit
+	selfMessage := &pb.MahjongBlankSuitReply{
+		RoomId:      request.GetUuid(),
+		PlayerIndex: index,
+		BlankSuit:   blankSuit,
+	}
+	othersMessage := &pb.MahjongBlankSuitReply{
+		RoomId:      request.GetUuid(),
+		PlayerIndex: index,
+	}
+	msgErr := common.PushRoom(selfMessage, othersMessage, onePlayer.GetUuid(), request)
+	if msgErr != nil {
+		common.LogError(obj.Variant.Name+"BlankSuit setBlankSuit PushRoom has err", msgErr)
+	}
+}
+
+// checkAllBlankSuit 所有玩家都定缺后不再等待
+func (obj *MahjongBlankSuit) checkAllBlankSuit(request *pb.RoomInfo, nowTime int64) {
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if IsPlaying(onePlayer) && onePlayer.GetMahjongPlayerInfo().GetBlankSuit() == pb.MahjongColor_MahjongColorNone {
+			return
+		}
+	}
+	request.DoTime = nowTime
+}
+
+// doBlankSuit 校验并处理玩家的定缺请求
+func (obj *MahjongBlankSuit) doBlankSuit(roomInfo *pb.RoomInfo, uid string, blankSuit pb.MahjongColor) (int32, *pb.ErrorMessage) {
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateBlankSuit || roomInfo.GetNextRoomState() != pb.RoomState_RoomStatePlay {
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	index := GetPlayerIndex(roomInfo, uid)
+	if index < 0 || !IsPlaying(roomInfo.GetPlayerInfo()[index]) {
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	isSuitColor := false
+	for _, color := range obj.Variant.getSuitColors() {
+		isSuitColor = isSuitColor || color == blankSuit
+	}
+	if !isSuitColor {
+		return 0, common.GetGrpcErrorMessage(obj.Variant.ErrorCodes.BlankSuitColor, "")
+	}
+	if roomInfo.GetPlayerInfo()[index].GetMahjongPlayerInfo().GetBlankSuit() != pb.MahjongColor_MahjongColorNone {
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	obj.setBlankSuit(roomInfo, index, blankSuit)
+	obj.checkAllBlankSuit(roomInfo, time.Now().Unix())
+	return index, nil
+}
+
+// RequestBlankSuit 玩家定缺
+func (obj *MahjongBlankSuit) RequestBlankSuit(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	roomInfo := request.GetRoomInfo()
+	realRequest := &pb.MahjongBlankSuitRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError(obj.Variant.Name+"BlankSuit RequestBlankSuit ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	index, msgErr := obj.doBlankSuit(roomInfo, extroInfo.GetUserId(), realRequest.GetBlankSuit())
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return packReply(roomInfo, &pb.MahjongBlankSuitReply{
+		RoomId:      roomInfo.GetUuid(),
+		PlayerIndex: index,
+		BlankSuit:   realRequest.GetBlankSuit(),
+	})
+}
+
+// RequestOperate 玩家通过操作请求定缺
+func (obj *MahjongBlankSuit) RequestOperate(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	roomInfo := request.GetRoomInfo()
+	realRequest := obj.Variant.NewOperateRequest()
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError(obj.Variant.Name+"BlankSuit RequestOperate ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	blankSuitRequest, ok := realRequest.(interface{ GetBlankSuit() pb.MahjongColor })
+	if !ok {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+	}
+	index, msgErr := obj.doBlankSuit(roomInfo, extroInfo.GetUserId(), blankSuitRequest.GetBlankSuit())
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return packReply(roomInfo, obj.Variant.NewOperateReply(roomInfo.GetUuid(), index))
+}
+
+// getDefaultBlankSuit 推荐定缺的花色：手牌中张数最少的花色
+func (obj *MahjongBlankSuit) getDefaultBlankSuit(hand []*pb.Mahjong) pb.MahjongColor {
+	suitColors := obj.Variant.getSuitColors()
+	blankSuit := suitColors[0]
+	for _, color := range suitColors {
+		if CountColor(hand, color) < CountColor(hand, blankSuit) {
+			blankSuit = color
+		}
+	}
+	return blankSuit
+}
@@ -0,0 +1,212 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"math/rand"
+	"time"
+)
+
+// MahjongChangeThreeCards 麻将游戏的换三张组件，用于处理换三张阶段的逻辑
+// 换三张的麻将没有吃牌，换三张阶段用玩家的吃牌区暂存选中的牌，交换完成后清空
+type MahjongChangeThreeCards struct {
+	base.Base
+	Variant *Variant
+}
+
+// LoadComponent 加载组件
+func (obj *MahjongChangeThreeCards) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *MahjongChangeThreeCards) Start() {
+	obj.Base.Start()
+}
+
+// Drive 麻将换三张阶段的主驱动
+// 每个玩家选三张同花色的牌，断线或者已经退出的玩家由系统选牌；所有人都选好或者时间到了，
+// 随机按顺时针、逆时针或者对家的方向交换，然后进入定缺阶段(不定缺时直接开始打牌)
+func (obj *MahjongChangeThreeCards) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState == pb.RoomState_RoomStateChangeThreeCards {
+		changeTime, msgErr := GetRoomConfigInt64(request, "ChangeThreeCardsTime")
+		if msgErr != nil {
+			return request, msgErr
+		}
+		// 推送房间状态 发牌<->换三张
+		pushRoomState := &pb.PushRoomStateChange{
+			RoomId:            request.GetUuid(),
+			BeforeState:       pb.RoomState_RoomStateDeal,
+			AfterState:        pb.RoomState_RoomStateChangeThreeCards,
+			AfterStateEndTime: nowTime + changeTime,
+		}
+		common.RoomBroadcast(request, pushRoomState)
+
+		request.NextRoomState = obj.getAfterState()
+		request.DoTime = nowTime + changeTime
+		for index, onePlayer := range request.GetPlayerInfo() {
+			if IsPlaying(onePlayer) && IsAutoOperate(onePlayer) {
+				obj.autoSelect(request, int32(index))
+			}
+		}
+		obj.checkAllSelected(request, nowTime)
+		return request, nil
+	}
+
+	if nowTime < request.GetDoTime() {
+		return request, nil
+	}
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if IsPlaying(onePlayer) {
+			obj.autoSelect(request, int32(index))
+		}
+	}
+	obj.change(request)
+	request.CurRoomState = obj.getAfterState()
+	request.NextRoomState = obj.getAfterState()
+	request.DoTime = nowTime
+	return request, nil
+}
+
+// getAfterState 换三张之后的阶段
+func (obj *MahjongChangeThreeCards) getAfterState() pb.RoomState {
+	if obj.Variant.HasBlankSuit {
+		return pb.RoomState_RoomStateBlankSuit
+	}
+	return pb.RoomState_RoomStatePlay
+}
+
+// autoSelect 系统替玩家选牌：从张数最少(至少三张)的花色里补齐三张
+func (obj *MahjongChangeThreeCards) autoSelect(request *pb.RoomInfo, index int32) {
+	mahjongPlayer := request.GetPlayerInfo()[index].GetMahjongPlayerInfo()
+	selected := mahjongPlayer.GetChowRegion()
+	if len(selected) >= changeMahjongNum {
+		return
+	}
+	hand := mahjongPlayer.GetHandRegion()
+	color := pb.MahjongColor_MahjongColorNone
+	if len(selected) > 0 {
+		color = selected[0].GetMahjongColor()
+	} else {
+		for _, oneColor := range obj.Variant.getSuitColors() {
+			colorNum := CountColor(hand, oneColor)
+			if colorNum >= changeMahjongNum && (color == pb.MahjongColor_MahjongColorNone || colorNum < CountColor(hand, color)) {
+				color = oneColor
+			}
+		}
+	}
+	for _, oneMahjong := range hand {
+		if len(selected) >= changeMahjongNum {
+			break
+		}
+		if oneMahjong.GetMahjongColor() == color && CountMahjong(selected, oneMahjong) < CountMahjong(hand, oneMahjong) {
+			selected = append(selected, oneMahjong)
+		}
+	}
+	mahjongPlayer.ChowRegion = selected
+	obj.pushSelected(request, index)
+}
+
+// pushSelected 玩家选好三张牌后通知所有人
+func (obj *MahjongChangeThreeCards) pushSelected(request *pb.RoomInfo, index int32) {
+	common.RoomBroadcast(request, obj.Variant.NewOperateReply(request.GetUuid(), index))
+}
+
+// checkAllSelected 所有玩家都选好三张牌后不再等待
+func (obj *MahjongChangeThreeCards) checkAllSelected(request *pb.RoomInfo, nowTime int64) {
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if IsPlaying(onePlayer) && len(onePlayer.GetMahjongPlayerInfo().GetChowRegion()) < changeMahjongNum {
+			return
+		}
+	}
+	request.DoTime = nowTime
+}
+
+// change 交换三张牌：随机顺时针、逆时针交换，四个人时还可以和对家交换，交换后把新的手牌推送给玩家
+func (obj *MahjongChangeThreeCards) change(request *pb.RoomInfo) {
+	var playIndexes []int
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if IsPlaying(onePlayer) {
+			playIndexes = append(playIndexes, index)
+		}
+	}
+	playNum := len(playIndexes)
+	offsets := []int{1, playNum - 1}
+	if playNum == 4 {
+		offsets = append(offsets, 2)
+	}
+	offset := offsets[rand.Intn(len(offsets))]
+
+	players := request.GetPlayerInfo()
+	for _, index := range playIndexes {
+		mahjongPlayer := players[index].GetMahjongPlayerInfo()
+		hand := mahjongPlayer.GetHandRegion()
+		for _, oneMahjong := range mahjongPlayer.GetChowRegion() {
+			hand, _ = RemoveMahjong(hand, oneMahjong, 1)
+		}
+		mahjongPlayer.HandRegion = hand
+	}
+	for i, index := range playIndexes {
+		giver := players[playIndexes[(i-offset+playNum)%playNum]].GetMahjongPlayerInfo()
+		mahjongPlayer := players[index].GetMahjongPlayerInfo()
+		SetHandRegion(mahjongPlayer, append(mahjongPlayer.GetHandRegion(), giver.GetChowRegion()...))
+	}
+	for _, index := range playIndexes {
+		mahjongPlayer := players[index].GetMahjongPlayerInfo()
+		mahjongPlayer.ChowRegion = nil
+		// 庄家的第十四张牌可能被换走了，之后打哪一张都可以
+		mahjongPlayer.NewMahjong = nil
+		pushDeal := &pb.MahjongDealNotice{
+			RoomId:               request.GetUuid(),
+			BankerIndex:          request.GetBankerIndex(),
+			PlayerIndex:          int32(index),
+			PlayerUuid:           players[index].GetUuid(),
+			HandCards:            mahjongPlayer.GetHandRegion(),
+			OthersHandCardNum:    handMahjongNum,
+			OpterateType:         pb.MahjongOperateEnum_ChangeThreeCards,
+			MahjongCardWallCount: request.GetMahjongGameInfo().GetMahjongCardWallCount(),
+		}
+		common.Pusher.Push(pushDeal, players[index].GetUuid())
+	}
+}
+
+// RequestOperate 玩家选一张要换出去的牌，三张牌必须是同一种花色，选够三张后不能再修改
+func (obj *MahjongChangeThreeCards) RequestOperate(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateChangeThreeCards || roomInfo.GetNextRoomState() != obj.getAfterState() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := obj.Variant.NewOperateRequest()
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError(obj.Variant.Name+"ChangeThreeCards RequestOperate ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	index := GetPlayerIndex(roomInfo, uid)
+	if index < 0 || !IsPlaying(roomInfo.GetPlayerInfo()[index]) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	mahjongPlayer := roomInfo.GetPlayerInfo()[index].GetMahjongPlayerInfo()
+	selected := mahjongPlayer.GetChowRegion()
+	if len(selected) >= changeMahjongNum {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	mahjong := realRequest.GetMahjong()
+	if CountMahjong(selected, mahjong) >= CountMahjong(mahjongPlayer.GetHandRegion(), mahjong) ||
+		(len(selected) > 0 && selected[0].GetMahjongColor() != mahjong.GetMahjongColor()) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+	}
+	mahjongPlayer.ChowRegion = append(selected, mahjong)
+	if len(mahjongPlayer.GetChowRegion()) == changeMahjongNum {
+		obj.pushSelected(roomInfo, index)
+		obj.checkAllSelected(roomInfo, time.Now().Unix())
+	}
+	return packReply(roomInfo, obj.Variant.NewOperateReply(roomInfo.GetUuid(), index))
+}
@@ -0,0 +1,176 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"math/rand"
+	"time"
+)
+
+// MahjongDeal 麻将游戏的发牌组件，用于处理定庄、打骰子和发牌阶段的逻辑
+type MahjongDeal struct {
+	base.Base
+	Variant *Variant
+}
+
+// LoadComponent 加载组件
+func (obj *MahjongDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *MahjongDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 麻将发牌阶段的主驱动
+// 上一局第一个胡牌的玩家坐庄，没有的话随机一个庄家；打骰子后每人发十三张牌，庄家多发一张，
+// 有花牌的麻将从庄家开始依次补花。发牌时间到了以后，开启换三张时进入换三张阶段，
+// 否则需要定缺的进入定缺阶段，不需要的直接开始打牌
+func (obj *MahjongDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateDeal {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = request.GetNextRoomState()
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	dealTime, msgErr := GetRoomConfigInt64(request, "DealTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+
+	// 推送房间状态 准备<->发牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateReady,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + dealTime,
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	obj.chooseBanker(request)
+	request.Dice = []int32{rand.Int31n(6) + 1, rand.Int31n(6) + 1}
+	pushDices := &pb.MahjongPlayDicesNotice{
+		RoomId:      request.GetUuid(),
+		BankerIndex: request.GetBankerIndex(),
+		WaitTime:    dealTime,
+		Dices:       request.GetDice(),
+	}
+
+	wall := GetShuffleMahjongWall(obj.Variant.Colors)
+	gameInfo := request.GetMahjongGameInfo()
+	gameInfo.Kong = make([]pb.MahjongKongEnum, len(request.GetPlayerInfo()))
+	gameInfo.BlankSuit = make([]pb.MahjongColor, len(request.GetPlayerInfo()))
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if !IsPlaying(onePlayer) {
+			continue
+		}
+		dealNum := handMahjongNum
+		if int64(index) == request.GetBankerIndex() {
+			dealNum++
+		}
+		hand := append([]*pb.Mahjong{}, wall[:dealNum]...)
+		wall = wall[dealNum:]
+		mahjongPlayer := &pb.MahjongPlayerInfo{}
+		// 庄家的第十四张牌当作新摸的牌
+		if dealNum > handMahjongNum {
+			mahjongPlayer.NewMahjong = hand[handMahjongNum]
+		}
+		SetHandRegion(mahjongPlayer, hand)
+		onePlayer.MahjongPlayerInfo = mahjongPlayer
+	}
+	// 从庄家开始补花，补到的还是花牌就继续补
+	playerNum := len(request.GetPlayerInfo())
+	for i := 0; i < playerNum; i++ {
+		onePlayer := request.GetPlayerInfo()[(int(request.GetBankerIndex())+i)%playerNum]
+		if IsPlaying(onePlayer) {
+			wall = replaceFlowers(onePlayer.GetMahjongPlayerInfo(), wall)
+		}
+	}
+	gameInfo.MahjongCards = wall
+	gameInfo.MahjongCardWallCount = int32(len(wall))
+	pushDices.MahjongCardWallCount = gameInfo.GetMahjongCardWallCount()
+	common.RoomBroadcast(request, pushDices)
+
+	// 每个玩家只能看到自己的手牌，亮出来的花牌在吃牌区，重连时所有人都能看到
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if !IsPlaying(onePlayer) {
+			continue
+		}
+		mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+		pushDeal := &pb.MahjongDealNotice{
+			RoomId:               request.GetUuid(),
+			BankerIndex:          request.GetBankerIndex(),
+			PlayerIndex:          int32(index),
+			PlayerUuid:           onePlayer.GetUuid(),
+			HandCards:            mahjongPlayer.GetHandRegion(),
+			OthersHandCardNum:    handMahjongNum,
+			OpterateType:         pb.MahjongOperateEnum_send,
+			MahjongCardWallCount: gameInfo.GetMahjongCardWallCount(),
+			WaitTime:             dealTime,
+			NewMahjong:           mahjongPlayer.GetNewMahjong(),
+		}
+		common.Pusher.Push(pushDeal, onePlayer.GetUuid())
+	}
+
+	switch {
+	case IsRoomConfigOpen(request, "IsChangeThreeCards"):
+		request.NextRoomState = pb.RoomState_RoomStateChangeThreeCards
+	case obj.Variant.HasBlankSuit:
+		request.NextRoomState = pb.RoomState_RoomStateBlankSuit
+	default:
+		request.NextRoomState = pb.RoomState_RoomStatePlay
+	}
+	request.DoTime = nowTime + dealTime
+	return request, nil
+}
+
+// chooseBanker 定庄：上一局第一个胡牌的玩家还在游戏中就由他坐庄，否则随机一个庄家
+func (obj *MahjongDeal) chooseBanker(request *pb.RoomInfo) {
+	var playIndexes []int64
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if !IsPlaying(onePlayer) {
+			continue
+		}
+		if onePlayer.GetUuid() == request.GetMahjongLastWinnerUuid() {
+			request.BankerIndex = int64(index)
+			return
+		}
+		playIndexes = append(playIndexes, int64(index))
+	}
+	request.BankerIndex = playIndexes[rand.Intn(len(playIndexes))]
+}
+
+// replaceFlowers 发牌后补花：手牌中的花牌亮到吃牌区，再从牌墙尾部补同样张数的牌，返回补花后的牌墙
+// 有花牌的麻将都没有吃牌，吃牌区用来放玩家亮出来的花牌
+func replaceFlowers(mahjongPlayer *pb.MahjongPlayerInfo, wall []*pb.Mahjong) []*pb.Mahjong {
+	hand := mahjongPlayer.GetHandRegion()
+	for {
+		flowerNum := CountColor(hand, pb.MahjongColor_MahjongColorFlower)
+		if flowerNum == 0 || len(wall) < flowerNum {
+			break
+		}
+		var remain []*pb.Mahjong
+		for _, oneMahjong := range hand {
+			if oneMahjong.GetMahjongColor() == pb.MahjongColor_MahjongColorFlower {
+				mahjongPlayer.ChowRegion = append(mahjongPlayer.GetChowRegion(), oneMahjong)
+				continue
+			}
+			remain = append(remain, oneMahjong)
+		}
+		hand = append(remain, wall[len(wall)-flowerNum:]...)
+		wall = wall[:len(wall)-flowerNum]
+	}
+	if mahjongPlayer.GetNewMahjong().GetMahjongColor() == pb.MahjongColor_MahjongColorFlower {
+		mahjongPlayer.NewMahjong = nil
+	}
+	SetHandRegion(mahjongPlayer, hand)
+	return wall
+}
@@ -0,0 +1,917 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+// MahjongPlay 麻将游戏的玩耍组件，用于处理摸牌、出牌和碰杠胡阶段的逻辑
+// 牌局信息中WaitOperateRecord为空时轮到DoIndex的玩家出牌(或者暗杠、巴杠、自摸)；
+// 不为空时在等待其他玩家响应DoIndex打出的牌，HasBeenOperatedRecord记录已经响应的操作。
+// 巴杠被抢杠胡时，HasBeenOperatedRecord中会先记录一条杠牌玩家自己的巴杠(PlayerIndex为DoIndex)
+type MahjongPlay struct {
+	base.Base
+	Variant *Variant
+}
+
+// LoadComponent 加载组件
+func (obj *MahjongPlay) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *MahjongPlay) Start() {
+	obj.Base.Start()
+}
+
+// Drive 麻将玩耍阶段的主驱动
+// 庄家先出牌，之后按座位顺序摸牌出牌；有人打出牌时，其他玩家按胡、杠、碰的优先级响应，
+// 多个玩家胡同一张牌时由规则选出胡牌的玩家。血战到底的麻将胡了牌的玩家不再参与后面的对局，
+// 只剩一个玩家没胡时本局结束，其他麻将有人胡牌本局就结束；牌墙摸完时本局结束进入结算
+func (obj *MahjongPlay) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState == pb.RoomState_RoomStatePlay {
+		// 推送房间状态 定缺(换三张、发牌)<->玩耍
+		beforeState := pb.RoomState_RoomStateDeal
+		if obj.Variant.HasBlankSuit {
+			beforeState = pb.RoomState_RoomStateBlankSuit
+		} else if IsRoomConfigOpen(request, "IsChangeThreeCards") {
+			beforeState = pb.RoomState_RoomStateChangeThreeCards
+		}
+		pushRoomState := &pb.PushRoomStateChange{
+			RoomId:      request.GetUuid(),
+			BeforeState: beforeState,
+			AfterState:  pb.RoomState_RoomStatePlay,
+		}
+		common.RoomBroadcast(request, pushRoomState)
+
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		msgErr := obj.startTurn(request, int32(request.GetBankerIndex()), nowTime)
+		return request, msgErr
+	}
+
+	if nowTime < request.GetDoTime() {
+		return request, nil
+	}
+	// 操作超时由系统自动操作
+	if len(request.GetMahjongGameInfo().GetWaitOperateRecord()) == 0 {
+		msgErr := obj.autoOperate(request, nowTime)
+		return request, msgErr
+	}
+	for _, oneRecord := range request.GetMahjongGameInfo().GetWaitOperateRecord() {
+		obj.autoResponse(request, oneRecord.GetPlayerIndex())
+	}
+	msgErr := obj.resolve(request, nowTime)
+	return request, msgErr
+}
+
+// getTurnTime 获取玩家操作的等待时长，断线或者已经退出的玩家等待AutoOperateTime后自动操作
+func (obj *MahjongPlay) getTurnTime(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, timeName string) (int64, *pb.ErrorMessage) {
+	if IsAutoOperate(onePlayer) {
+		timeName = "AutoOperateTime"
+	}
+	return GetRoomConfigInt64(request, timeName)
+}
+
+// startTurn 轮到座位index的玩家出牌，同时告诉他是否可以自摸、暗杠或者巴杠
+// 刚碰完牌的玩家只能出牌和杠牌，牌墙摸完了不能再杠
+func (obj *MahjongPlay) startTurn(request *pb.RoomInfo, index int32, nowTime int64) *pb.ErrorMessage {
+	onePlayer := request.GetPlayerInfo()[index]
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	gameInfo := request.GetMahjongGameInfo()
+	outputTime, msgErr := obj.getTurnTime(request, onePlayer, "OutputTime")
+	if msgErr != nil {
+		return msgErr
+	}
+
+	operates := []pb.MahjongOperateEnum{pb.MahjongOperateEnum_output}
+	if !gameInfo.GetBPong() && obj.Variant.CanPlayerWin(request, onePlayer, nil) {
+		operates = append(operates, pb.MahjongOperateEnum_Win)
+	}
+	var canKongCard []*pb.Mahjong
+	if gameInfo.GetMahjongCardWallCount() > 0 {
+		hand := mahjongPlayer.GetHandRegion()
+		kongMahjongs := append(GetAnKongMahjongs(hand), GetBaKongMahjongs(hand, mahjongPlayer.GetPongRegion())...)
+		for _, oneMahjong := range kongMahjongs {
+			if !obj.Variant.isBlankSuit(oneMahjong, mahjongPlayer) {
+				canKongCard = append(canKongCard, oneMahjong)
+			}
+		}
+	}
+	if len(canKongCard) > 0 {
+		operates = append(operates, pb.MahjongOperateEnum_Kong)
+	}
+	mahjongPlayer.WaitChoice = &pb.MahjongWaitChoiceNotice{
+		RoomId:       request.GetUuid(),
+		BankerIndex:  request.GetBankerIndex(),
+		PlayerIndex:  index,
+		PointToIndex: index,
+		WaitTime:     outputTime,
+		Operates:     operates,
+		CanKongCard:  canKongCard,
+	}
+	common.Pusher.Push(mahjongPlayer.GetWaitChoice(), onePlayer.GetUuid())
+
+	request.DoIndex = index
+	request.DoTime = nowTime + outputTime
+	pushDoTime := &pb.PushRoomDoTimeChange{
+		RoomId:  request.GetUuid(),
+		EndTime: request.GetDoTime(),
+	}
+	common.RoomBroadcast(request, pushDoTime)
+	return nil
+}
+
+// draw 座位index的玩家摸一张牌，杠牌后从牌墙尾部补牌；牌墙摸完时本局结束
+// 摸到花牌时亮出来放到吃牌区，并从牌墙尾部补一张，补到的还是花牌就继续补
+func (obj *MahjongPlay) draw(request *pb.RoomInfo, index int32, fromTail bool, nowTime int64) *pb.ErrorMessage {
+	gameInfo := request.GetMahjongGameInfo()
+	gameInfo.BPong = false
+	onePlayer := request.GetPlayerInfo()[index]
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	if !fromTail {
+		// 不是杠后补牌，杠上开花、杠上炮的标记失效
+		gameInfo.Kong[index] = pb.MahjongKongEnum_MahjongKongEnumUndefine
+	}
+	var mahjong *pb.Mahjong
+	for mahjong == nil {
+		wall := gameInfo.GetMahjongCards()
+		if len(wall) == 0 {
+			obj.gameOver(request, nowTime)
+			return nil
+		}
+		if fromTail {
+			mahjong, wall = wall[len(wall)-1], wall[:len(wall)-1]
+		} else {
+			mahjong, wall = wall[0], wall[1:]
+		}
+		gameInfo.MahjongCards = wall
+		gameInfo.MahjongCardWallCount = int32(len(wall))
+		if mahjong.GetMahjongColor() == pb.MahjongColor_MahjongColorFlower {
+			obj.showFlower(request, index, mahjong)
+			mahjong, fromTail = nil, true
+		}
+	}
+	gameInfo.NewMahjong = mahjong
+	mahjongPlayer.NewMahjong = mahjong
+	SetHandRegion(mahjongPlayer, append(mahjongPlayer.GetHandRegion(), mahjong))
+
+	outputTime, msgErr := obj.getTurnTime(request, onePlayer, "OutputTime")
+	if msgErr != nil {
+		return msgErr
+	}
+	othersMessage := &pb.MahjongPlayerSendCardNotice{
+		RoomId:               request.GetUuid(),
+		BankerIndex:          request.GetBankerIndex(),
+		PlayerIndex:          index,
+		OpterateType:         pb.MahjongOperateEnum_send,
+		MahjongCardWallCount: gameInfo.GetMahjongCardWallCount(),
+		WaitTime:             outputTime,
+		KongCardNum:          gameInfo.GetKongCardNum(),
+	}
+	selfMessage := &pb.MahjongPlayerSendCardNotice{
+		RoomId:               othersMessage.GetRoomId(),
+		BankerIndex:          othersMessage.GetBankerIndex(),
+		PlayerIndex:          index,
+		OpterateType:         othersMessage.GetOpterateType(),
+		MahjongCardWallCount: othersMessage.GetMahjongCardWallCount(),
+		WaitTime:             outputTime,
+		KongCardNum:          othersMessage.GetKongCardNum(),
+		Card:                 mahjong,
+		IfOutputInfo:         obj.Variant.getIfOutputInfo(request, onePlayer),
+	}
+	msgErr = common.PushRoom(selfMessage, othersMessage, onePlayer.GetUuid(), request)
+	if msgErr != nil {
+		common.LogError(obj.Variant.Name+"Play draw PushRoom has err", msgErr)
+	}
+	return obj.startTurn(request, index, nowTime)
+}
+
+// showFlower 玩家亮出摸到的花牌，花牌是公开的，所有人都能看到
+func (obj *MahjongPlay) showFlower(request *pb.RoomInfo, index int32, flower *pb.Mahjong) {
+	mahjongPlayer := request.GetPlayerInfo()[index].GetMahjongPlayerInfo()
+	mahjongPlayer.ChowRegion = append(mahjongPlayer.GetChowRegion(), flower)
+	pushFlower := &pb.MahjongPlayerSendCardNotice{
+		RoomId:               request.GetUuid(),
+		BankerIndex:          request.GetBankerIndex(),
+		PlayerIndex:          index,
+		OpterateType:         pb.MahjongOperateEnum_send,
+		MahjongCardWallCount: request.GetMahjongGameInfo().GetMahjongCardWallCount(),
+		Card:                 flower,
+	}
+	common.RoomBroadcast(request, pushFlower)
+}
+
+// gameOver 本局结束，进入结算
+func (obj *MahjongPlay) gameOver(request *pb.RoomInfo, nowTime int64) {
+	request.CurRoomState = pb.RoomState_RoomStateSettle
+	request.NextRoomState = pb.RoomState_RoomStateSettle
+	request.DoTime = nowTime
+}
+
+// nextDraw 下一个还没胡牌的玩家摸牌，只剩一个玩家没胡或者不是血战到底的麻将有人胡牌时本局结束
+func (obj *MahjongPlay) nextDraw(request *pb.RoomInfo, index int32, nowTime int64) *pb.ErrorMessage {
+	hasWinner := len(request.GetMahjongGameInfo().GetWinInfo()) > 0
+	if GetActiveNum(request) <= 1 || (!obj.Variant.IsBloodBattle && hasWinner) {
+		obj.gameOver(request, nowTime)
+		return nil
+	}
+	return obj.draw(request, getNextActiveIndex(request, index), false, nowTime)
+}
+
+// autoOperate 出牌超时由系统自动操作：能自摸就胡牌，否则先打定缺的牌，再打刚摸到的牌
+func (obj *MahjongPlay) autoOperate(request *pb.RoomInfo, nowTime int64) *pb.ErrorMessage {
+	index := request.GetDoIndex()
+	onePlayer := request.GetPlayerInfo()[index]
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	if !request.GetMahjongGameInfo().GetBPong() && obj.Variant.CanPlayerWin(request, onePlayer, nil) {
+		return obj.selfWin(request, index, nowTime)
+	}
+	hand := mahjongPlayer.GetHandRegion()
+	mahjong := hand[len(hand)-1]
+	if mahjongPlayer.GetNewMahjong() != nil {
+		mahjong = mahjongPlayer.GetNewMahjong()
+	}
+	for _, oneMahjong := range hand {
+		if obj.Variant.isBlankSuit(oneMahjong, mahjongPlayer) {
+			mahjong = oneMahjong
+			break
+		}
+	}
+	return obj.output(request, index, mahjong, nowTime)
+}
+
+// output 玩家出牌，其他还没胡牌的玩家可以胡、杠、碰这张牌；没有人可以响应时下一个玩家摸牌
+func (obj *MahjongPlay) output(request *pb.RoomInfo, index int32, mahjong *pb.Mahjong, nowTime int64) *pb.ErrorMessage {
+	gameInfo := request.GetMahjongGameInfo()
+	onePlayer := request.GetPlayerInfo()[index]
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	hand, _ := RemoveMahjong(mahjongPlayer.GetHandRegion(), mahjong, 1)
+	SetHandRegion(mahjongPlayer, hand)
+	mahjongPlayer.NewMahjong = nil
+	mahjongPlayer.WaitChoice = nil
+	mahjongPlayer.OutputRegion = append(mahjongPlayer.GetOutputRegion(), mahjong)
+	gameInfo.NewMahjong = nil
+	gameInfo.BPong = false
+	gameInfo.OutputInfo = append(gameInfo.GetOutputInfo(), &pb.MahjongOutputInfo{
+		OutputPlayerIndex: index,
+		OutputPlayerUuid:  onePlayer.GetUuid(),
+		OutputMahjongCard: mahjong,
+	})
+	// 其他玩家的杠牌标记在有人出牌后失效，自己的标记用于判断杠上炮
+	for i := range gameInfo.GetKong() {
+		if int32(i) != index {
+			gameInfo.Kong[i] = pb.MahjongKongEnum_MahjongKongEnumUndefine
+		}
+	}
+
+	othersMessage := &pb.MahjongOutputNotice{
+		RoomId:            request.GetUuid(),
+		BankerIndex:       request.GetBankerIndex(),
+		OutputPlayerIndex: index,
+		OutputMahjongCard: mahjong,
+		HandCardsNum:      mahjongPlayer.GetHandCardsNum(),
+		OutputCards:       mahjongPlayer.GetOutputRegion(),
+	}
+	selfMessage := &pb.MahjongOutputNotice{
+		RoomId:            othersMessage.GetRoomId(),
+		BankerIndex:       othersMessage.GetBankerIndex(),
+		OutputPlayerIndex: index,
+		OutputMahjongCard: mahjong,
+		HandCards:         mahjongPlayer.GetHandRegion(),
+		HandCardsNum:      mahjongPlayer.GetHandCardsNum(),
+		OutputCards:       mahjongPlayer.GetOutputRegion(),
+	}
+	msgErr := common.PushRoom(selfMessage, othersMessage, onePlayer.GetUuid(), request)
+	if msgErr != nil {
+		common.LogError(obj.Variant.Name+"Play output PushRoom has err", msgErr)
+	}
+
+	var records []*pb.MahjongWaitOperateRecord
+	for i, otherPlayer := range request.GetPlayerInfo() {
+		if int32(i) == index || !IsActive(otherPlayer) {
+			continue
+		}
+		otherHand := otherPlayer.GetMahjongPlayerInfo().GetHandRegion()
+		isBlankSuit := obj.Variant.isBlankSuit(mahjong, otherPlayer.GetMahjongPlayerInfo())
+		if obj.Variant.CanPlayerWin(request, otherPlayer, mahjong) {
+			records = append(records, &pb.MahjongWaitOperateRecord{PlayerIndex: int32(i), OperateType: pb.MahjongOperateEnum_Win})
+		}
+		if !isBlankSuit && gameInfo.GetMahjongCardWallCount() > 0 && CanKongOutput(otherHand, mahjong) {
+			records = append(records, &pb.MahjongWaitOperateRecord{PlayerIndex: int32(i), OperateType: pb.MahjongOperateEnum_Kong})
+		}
+		if !isBlankSuit && CanPong(otherHand, mahjong) {
+			records = append(records, &pb.MahjongWaitOperateRecord{PlayerIndex: int32(i), OperateType: pb.MahjongOperateEnum_Pong})
+		}
+	}
+	if len(records) == 0 {
+		return obj.nextDraw(request, index, nowTime)
+	}
+	return obj.waitResponse(request, records, nowTime)
+}
+
+// waitResponse 等待其他玩家响应碰杠胡，断线或者已经退出的玩家由系统直接响应
+func (obj *MahjongPlay) waitResponse(request *pb.RoomInfo, records []*pb.MahjongWaitOperateRecord, nowTime int64) *pb.ErrorMessage {
+	gameInfo := request.GetMahjongGameInfo()
+	gameInfo.WaitOperateRecord = records
+	operateTime, msgErr := GetRoomConfigInt64(request, "OperateTime")
+	if msgErr != nil {
+		return msgErr
+	}
+	waitChoices := map[int32]*pb.MahjongWaitChoiceNotice{}
+	for _, oneRecord := range records {
+		index := oneRecord.GetPlayerIndex()
+		if waitChoices[index] == nil {
+			waitChoices[index] = &pb.MahjongWaitChoiceNotice{
+				RoomId:       request.GetUuid(),
+				BankerIndex:  request.GetBankerIndex(),
+				PlayerIndex:  index,
+				PointToIndex: request.GetDoIndex(),
+				WaitTime:     operateTime,
+				Operates:     []pb.MahjongOperateEnum{pb.MahjongOperateEnum_Pass},
+			}
+		}
+		waitChoices[index].Operates = append(waitChoices[index].GetOperates(), oneRecord.GetOperateType())
+		if oneRecord.GetOperateType() == pb.MahjongOperateEnum_Kong {
+			waitChoices[index].CanKongCard = []*pb.Mahjong{obj.getTargetMahjong(request)}
+		}
+	}
+	for index, waitChoice := range waitChoices {
+		onePlayer := request.GetPlayerInfo()[index]
+		if IsAutoOperate(onePlayer) {
+			obj.autoResponse(request, index)
+			continue
+		}
+		onePlayer.GetMahjongPlayerInfo().WaitChoice = waitChoice
+		common.Pusher.Push(waitChoice, onePlayer.GetUuid())
+	}
+	if obj.isAllResponded(request) {
+		return obj.resolve(request, nowTime)
+	}
+	request.DoTime = nowTime + operateTime
+	pushDoTime := &pb.PushRoomDoTimeChange{
+		RoomId:  request.GetUuid(),
+		EndTime: request.GetDoTime(),
+	}
+	common.RoomBroadcast(request, pushDoTime)
+	return nil
+}
+
+// getRobKongRecord 获取正在被抢杠胡的巴杠记录，没有返回nil
+func (obj *MahjongPlay) getRobKongRecord(request *pb.RoomInfo) *pb.MahjongHasBeenOperatedRecord {
+	for _, oneRecord := range request.GetMahjongGameInfo().GetHasBeenOperatedRecord() {
+		if oneRecord.GetPlayerIndex() == request.GetDoIndex() {
+			return oneRecord
+		}
+	}
+	return nil
+}
+
+// getTargetMahjong 获取其他玩家正在响应的牌：被抢杠的牌或者最后打出的牌
+func (obj *MahjongPlay) getTargetMahjong(request *pb.RoomInfo) *pb.Mahjong {
+	robKongRecord := obj.getRobKongRecord(request)
+	if robKongRecord != nil {
+		return robKongRecord.GetKongCard()
+	}
+	outputInfo := request.GetMahjongGameInfo().GetOutputInfo()
+	return outputInfo[len(outputInfo)-1].GetOutputMahjongCard()
+}
+
+// getResponse 获取玩家已经响应的操作，还没有响应返回nil
+func (obj *MahjongPlay) getResponse(request *pb.RoomInfo, index int32) *pb.MahjongHasBeenOperatedRecord {
+	if index == request.GetDoIndex() {
+		return nil
+	}
+	for _, oneRecord := range request.GetMahjongGameInfo().GetHasBeenOperatedRecord() {
+		if oneRecord.GetPlayerIndex() == index {
+			return oneRecord
+		}
+	}
+	return nil
+}
+
+// canResponse 玩家是否可以进行某种响应，过牌总是可以的
+func (obj *MahjongPlay) canResponse(request *pb.RoomInfo, index int32, operateType pb.MahjongOperateEnum) bool {
+	hasRecord := false
+	for _, oneRecord := range request.GetMahjongGameInfo().GetWaitOperateRecord() {
+		if oneRecord.GetPlayerIndex() != index {
+			continue
+		}
+		hasRecord = true
+		if oneRecord.GetOperateType() == operateType {
+			return true
+		}
+	}
+	return hasRecord && operateType == pb.MahjongOperateEnum_Pass
+}
+
+// response 记录玩家的响应
+func (obj *MahjongPlay) response(request *pb.RoomInfo, index int32, operateType pb.MahjongOperateEnum) {
+	gameInfo := request.GetMahjongGameInfo()
+	gameInfo.HasBeenOperatedRecord = append(gameInfo.GetHasBeenOperatedRecord(), &pb.MahjongHasBeenOperatedRecord{
+		PlayerIndex:      index,
+		HasBeOperateType: operateType,
+	})
+	request.GetPlayerInfo()[index].GetMahjongPlayerInfo().WaitChoice = nil
+}
+
+// autoResponse 系统替玩家响应：能胡就胡，否则过牌
+func (obj *MahjongPlay) autoResponse(request *pb.RoomInfo, index int32) {
+	if obj.getResponse(request, index) != nil {
+		return
+	}
+	operateType := pb.MahjongOperateEnum_Pass
+	if obj.canResponse(request, index, pb.MahjongOperateEnum_Win) {
+		operateType = pb.MahjongOperateEnum_Win
+	}
+	obj.response(request, index, operateType)
+}
+
+// isAllResponded 可以响应的玩家是否都已经响应了
+func (obj *MahjongPlay) isAllResponded(request *pb.RoomInfo) bool {
+	for _, oneRecord := range request.GetMahjongGameInfo().GetWaitOperateRecord() {
+		if obj.getResponse(request, oneRecord.GetPlayerIndex()) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve 按胡、杠、碰的优先级处理所有玩家的响应，多个玩家胡牌时由规则选出胡牌的玩家；都过牌时下一个玩家摸牌，
+// 抢杠胡时没有人胡，杠牌的玩家继续完成巴杠
+func (obj *MahjongPlay) resolve(request *pb.RoomInfo, nowTime int64) *pb.ErrorMessage {
+	gameInfo := request.GetMahjongGameInfo()
+	doIndex := request.GetDoIndex()
+	mahjong := obj.getTargetMahjong(request)
+	robKongRecord := obj.getRobKongRecord(request)
+	responses := map[pb.MahjongOperateEnum][]int32{}
+	playerNum := int32(len(request.GetPlayerInfo()))
+	// 按出牌玩家之后的座位顺序处理，一炮多响时最后一个胡牌玩家的下家摸牌
+	for i := int32(1); i < playerNum; i++ {
+		index := (doIndex + i) % playerNum
+		oneResponse := obj.getResponse(request, index)
+		if oneResponse != nil {
+			responses[oneResponse.GetHasBeOperateType()] = append(responses[oneResponse.GetHasBeOperateType()], index)
+		}
+	}
+	gameInfo.WaitOperateRecord = nil
+	gameInfo.HasBeenOperatedRecord = nil
+	for _, onePlayer := range request.GetPlayerInfo() {
+		if onePlayer.GetMahjongPlayerInfo() != nil {
+			onePlayer.GetMahjongPlayerInfo().WaitChoice = nil
+		}
+	}
+
+	if winners := responses[pb.MahjongOperateEnum_Win]; len(winners) > 0 {
+		if obj.Variant.ChooseWinners != nil {
+			winners = obj.Variant.ChooseWinners(request, winners, mahjong)
+		}
+		winSource := pb.MahjongWinSourceEnum_Discard
+		if robKongRecord != nil {
+			winSource = pb.MahjongWinSourceEnum_GrabKong
+		} else if gameInfo.GetKong()[doIndex] != pb.MahjongKongEnum_MahjongKongEnumUndefine {
+			winSource = pb.MahjongWinSourceEnum_DiscardAfterKong
+		}
+		for _, winner := range winners {
+			msgErr := obj.win(request, winner, mahjong, winSource, doIndex)
+			if msgErr != nil {
+				return msgErr
+			}
+		}
+		if robKongRecord != nil {
+			// 被抢杠的牌从杠牌玩家手里拿走
+			konger := request.GetPlayerInfo()[doIndex].GetMahjongPlayerInfo()
+			hand, _ := RemoveMahjong(konger.GetHandRegion(), mahjong, 1)
+			SetHandRegion(konger, hand)
+			konger.NewMahjong = nil
+		} else {
+			obj.hideLastOutput(request)
+		}
+		return obj.nextDraw(request, winners[len(winners)-1], nowTime)
+	}
+	if robKongRecord != nil {
+		return obj.kong(request, doIndex, pb.MahjongKongEnum_KongBa, mahjong, doIndex, nowTime)
+	}
+	if kongers := responses[pb.MahjongOperateEnum_Kong]; len(kongers) > 0 {
+		obj.hideLastOutput(request)
+		return obj.kong(request, kongers[0], pb.MahjongKongEnum_KongZhi, mahjong, doIndex, nowTime)
+	}
+	if pongers := responses[pb.MahjongOperateEnum_Pong]; len(pongers) > 0 {
+		obj.hideLastOutput(request)
+		return obj.pong(request, pongers[0], mahjong, doIndex, nowTime)
+	}
+	return obj.nextDraw(request, doIndex, nowTime)
+}
+
+// hideLastOutput 最后打出的牌被碰杠胡拿走了，出牌区不再显示
+func (obj *MahjongPlay) hideLastOutput(request *pb.RoomInfo) {
+	outputInfo := request.GetMahjongGameInfo().GetOutputInfo()
+	outputInfo[len(outputInfo)-1].BHide = true
+}
+
+// pong 玩家碰牌，碰牌后轮到他出牌
+func (obj *MahjongPlay) pong(request *pb.RoomInfo, index int32, mahjong *pb.Mahjong, outputIndex int32, nowTime int64) *pb.ErrorMessage {
+	gameInfo := request.GetMahjongGameInfo()
+	onePlayer := request.GetPlayerInfo()[index]
+	outputPlayer := request.GetPlayerInfo()[outputIndex]
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	hand, _ := RemoveMahjong(mahjongPlayer.GetHandRegion(), mahjong, 2)
+	SetHandRegion(mahjongPlayer, hand)
+	pongInfo := &pb.MahjongPongInfo{
+		PongPlayerIndex:     index,
+		PongPlayerUuid:      onePlayer.GetUuid(),
+		PongMahjongCard:     mahjong,
+		MakePongPlayerIndex: outputIndex,
+		MakePongPlayerUuid:  outputPlayer.GetUuid(),
+	}
+	mahjongPlayer.PongRegion = append(mahjongPlayer.GetPongRegion(), pongInfo)
+	gameInfo.PongInfo = append(gameInfo.GetPongInfo(), pongInfo)
+	gameInfo.BPong = true
+
+	othersMessage := &pb.MahjongPongNotice{
+		RoomId:                  request.GetUuid(),
+		BankerIndex:             request.GetBankerIndex(),
+		OutputPlayerIndex:       outputIndex,
+		OutputCards:             outputPlayer.GetMahjongPlayerInfo().GetOutputRegion(),
+		PongPlayerIndex:         index,
+		PongPlayerHandCardLenth: mahjongPlayer.GetHandCardsNum(),
+		PongInfo:                mahjongPlayer.GetPongRegion(),
+	}
+	selfMessage := &pb.MahjongPongNotice{
+		RoomId:                  othersMessage.GetRoomId(),
+		BankerIndex:             othersMessage.GetBankerIndex(),
+		OutputPlayerIndex:       outputIndex,
+		OutputCards:             othersMessage.GetOutputCards(),
+		PongPlayerIndex:         index,
+		HandCards:               mahjongPlayer.GetHandRegion(),
+		PongPlayerHandCardLenth: mahjongPlayer.GetHandCardsNum(),
+		PongInfo:                mahjongPlayer.GetPongRegion(),
+		IfOutputInfo:            obj.Variant.getIfOutputInfo(request, onePlayer),
+	}
+	msgErr := common.PushRoom(selfMessage, othersMessage, onePlayer.GetUuid(), request)
+	if msgErr != nil {
+		common.LogError(obj.Variant.Name+"Play pong PushRoom has err", msgErr)
+	}
+	return obj.startTurn(request, index, nowTime)
+}
+
+// tryBaKong 玩家巴杠，其他玩家可以胡这张牌时先等待抢杠胡，没有人抢杠才完成巴杠
+func (obj *MahjongPlay) tryBaKong(request *pb.RoomInfo, index int32, mahjong *pb.Mahjong, nowTime int64) *pb.ErrorMessage {
+	var records []*pb.MahjongWaitOperateRecord
+	for i, otherPlayer := range request.GetPlayerInfo() {
+		if int32(i) != index && IsActive(otherPlayer) && obj.Variant.CanPlayerWin(request, otherPlayer, mahjong) {
+			records = append(records, &pb.MahjongWaitOperateRecord{PlayerIndex: int32(i), OperateType: pb.MahjongOperateEnum_Win})
+		}
+	}
+	if len(records) == 0 {
+		return obj.kong(request, index, pb.MahjongKongEnum_KongBa, mahjong, index, nowTime)
+	}
+	gameInfo := request.GetMahjongGameInfo()
+	gameInfo.HasBeenOperatedRecord = []*pb.MahjongHasBeenOperatedRecord{{
+		PlayerIndex:      index,
+		HasBeOperateType: pb.MahjongOperateEnum_Kong,
+		KongCard:         mahjong,
+	}}
+	request.GetPlayerInfo()[index].GetMahjongPlayerInfo().WaitChoice = nil
+	for _, oneRecord := range records {
+		pushAfterKong := &pb.MahjongAfterKongNotice{
+			RoomId:           request.GetUuid(),
+			PlayerIndex:      index,
+			BCanWinByRobKong: true,
+		}
+		common.Pusher.Push(pushAfterKong, request.GetPlayerInfo()[oneRecord.GetPlayerIndex()].GetUuid())
+	}
+	return obj.waitResponse(request, records, nowTime)
+}
+
+// kong 玩家杠牌并实时收取杠钱，然后从牌墙尾部补一张牌
+// 直杠由点杠的玩家付钱，巴杠和暗杠每个没胡的玩家都要付钱，每种杠的分数是底分的倍数，由房间配置决定
+func (obj *MahjongPlay) kong(request *pb.RoomInfo, index int32, kongType pb.MahjongKongEnum, mahjong *pb.Mahjong, makeIndex int32, nowTime int64) *pb.ErrorMessage {
+	baseScore, msgErr := GetRoomConfigInt64(request, "BaseScore")
+	if msgErr != nil {
+		return msgErr
+	}
+	kongMultiple, msgErr := GetRoomConfigInt64(request, kongScoreConfigNames[kongType])
+	if msgErr != nil {
+		return msgErr
+	}
+	gameInfo := request.GetMahjongGameInfo()
+	onePlayer := request.GetPlayerInfo()[index]
+	makePlayer := request.GetPlayerInfo()[makeIndex]
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	removeNum := map[pb.MahjongKongEnum]int{
+		pb.MahjongKongEnum_KongZhi: 3,
+		pb.MahjongKongEnum_KongBa:  1,
+		pb.MahjongKongEnum_KongAn:  4,
+	}[kongType]
+	hand, _ := RemoveMahjong(mahjongPlayer.GetHandRegion(), mahjong, removeNum)
+	SetHandRegion(mahjongPlayer, hand)
+	mahjongPlayer.NewMahjong = nil
+	mahjongPlayer.WaitChoice = nil
+	if kongType == pb.MahjongKongEnum_KongBa {
+		var pongRegion []*pb.MahjongPongInfo
+		for _, onePong := range mahjongPlayer.GetPongRegion() {
+			if IsSameMahjong(onePong.GetPongMahjongCard(), mahjong) {
+				makePlayer = request.GetPlayerInfo()[onePong.GetMakePongPlayerIndex()]
+				makeIndex = onePong.GetMakePongPlayerIndex()
+				continue
+			}
+			pongRegion = append(pongRegion, onePong)
+		}
+		mahjongPlayer.PongRegion = pongRegion
+	}
+	kongInfo := &pb.MahjongKongInfo{
+		KongPlayerIndex:     index,
+		KongPlayerUuid:      onePlayer.GetUuid(),
+		Kong:                kongType,
+		KongMahjongCard:     mahjong,
+		MakeKongPlayerIndex: makeIndex,
+		MakeKongPlayerUuid:  makePlayer.GetUuid(),
+	}
+	mahjongPlayer.KongRegion = append(mahjongPlayer.GetKongRegion(), kongInfo)
+	gameInfo.KongInfo = append(gameInfo.GetKongInfo(), kongInfo)
+	gameInfo.Kong[index] = kongType
+
+	beforeBalances := GetSeatBalances(request)
+	detail := &pb.MahjongSettleDetail{KongInfo: kongInfo}
+	kongScore := kongMultiple * baseScore
+	for i, otherPlayer := range request.GetPlayerInfo() {
+		if int32(i) == index || !IsActive(otherPlayer) || (kongType == pb.MahjongKongEnum_KongZhi && int32(i) != makeIndex) {
+			continue
+		}
+		Transfer(request, int32(i), index, kongScore, detail, obj.Variant.KongReason)
+	}
+	remainMoney := GetSeatBalances(request)
+	changeMoney := make([]int64, len(remainMoney))
+	for i := range remainMoney {
+		changeMoney[i] = remainMoney[i] - beforeBalances[i]
+	}
+	pushKongMoney := &pb.MahjongKongChangeMoneyNotice{
+		RoomId:      request.GetUuid(),
+		ChangeMoney: changeMoney,
+		RemainMoney: remainMoney,
+	}
+	common.RoomBroadcast(request, pushKongMoney)
+
+	othersMessage := &pb.MahjongKongNotice{
+		RoomId:                  request.GetUuid(),
+		BankerIndex:             request.GetBankerIndex(),
+		KongPlayerIndex:         index,
+		KongType:                kongType,
+		KongPlayerHandCardLenth: mahjongPlayer.GetHandCardsNum(),
+		KongInfos:               mahjongPlayer.GetKongRegion(),
+		PongInfos:               mahjongPlayer.GetPongRegion(),
+	}
+	if kongType == pb.MahjongKongEnum_KongZhi {
+		othersMessage.OutputPlayerIndex = makeIndex
+		othersMessage.OutputCards = makePlayer.GetMahjongPlayerInfo().GetOutputRegion()
+	}
+	selfMessage := &pb.MahjongKongNotice{
+		RoomId:                  othersMessage.GetRoomId(),
+		BankerIndex:             othersMessage.GetBankerIndex(),
+		KongPlayerIndex:         index,
+		KongType:                kongType,
+		HandCards:               mahjongPlayer.GetHandRegion(),
+		KongPlayerHandCardLenth: mahjongPlayer.GetHandCardsNum(),
+		KongInfos:               othersMessage.GetKongInfos(),
+		PongInfos:               othersMessage.GetPongInfos(),
+		OutputPlayerIndex:       othersMessage.GetOutputPlayerIndex(),
+		OutputCards:             othersMessage.GetOutputCards(),
+	}
+	msgErr = common.PushRoom(selfMessage, othersMessage, onePlayer.GetUuid(), request)
+	if msgErr != nil {
+		common.LogError(obj.Variant.Name+"Play kong PushRoom has err", msgErr)
+	}
+
+	gameInfo.KongCardNum++
+	return obj.draw(request, index, true, nowTime)
+}
+
+// selfWin 玩家自摸，每个还没胡牌的玩家都要付钱
+func (obj *MahjongPlay) selfWin(request *pb.RoomInfo, index int32, nowTime int64) *pb.ErrorMessage {
+	gameInfo := request.GetMahjongGameInfo()
+	mahjongPlayer := request.GetPlayerInfo()[index].GetMahjongPlayerInfo()
+	hand := mahjongPlayer.GetHandRegion()
+	mahjong := hand[len(hand)-1]
+	if mahjongPlayer.GetNewMahjong() != nil {
+		mahjong = mahjongPlayer.GetNewMahjong()
+	}
+	winSource := pb.MahjongWinSourceEnum_Draw
+	if gameInfo.GetKong()[index] != pb.MahjongKongEnum_MahjongKongEnumUndefine {
+		winSource = pb.MahjongWinSourceEnum_DrawAfterKong
+	}
+	hand, _ = RemoveMahjong(hand, mahjong, 1)
+	SetHandRegion(mahjongPlayer, hand)
+	msgErr := obj.win(request, index, mahjong, winSource, index)
+	if msgErr != nil {
+		return msgErr
+	}
+	return obj.nextDraw(request, index, nowTime)
+}
+
+// win 玩家胡牌并实时结算，玩家的手牌中不包括胡的那张牌；自摸时payerIndex为自己
+// 胡牌类型和分数由规则计算，这里给出海底、天胡、地胡的牌局情况：
+// 天胡是庄家第一手自摸，地胡是闲家第一次摸牌自摸
+func (obj *MahjongPlay) win(request *pb.RoomInfo, index int32, mahjong *pb.Mahjong, winSource pb.MahjongWinSourceEnum, payerIndex int32) *pb.ErrorMessage {
+	gameInfo := request.GetMahjongGameInfo()
+	onePlayer := request.GetPlayerInfo()[index]
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	isFirstRound := len(gameInfo.GetPongInfo()) == 0 && len(gameInfo.GetKongInfo()) == 0 && len(mahjongPlayer.GetOutputRegion()) == 0
+	isSelfDraw := winSource == pb.MahjongWinSourceEnum_Draw
+	winContext := &WinContext{
+		Hand:          append(append([]*pb.Mahjong{}, mahjongPlayer.GetHandRegion()...), mahjong),
+		WinMahjong:    mahjong,
+		WinSource:     winSource,
+		IsSeabed:      gameInfo.GetMahjongCardWallCount() == 0,
+		IsDrawSky:     isSelfDraw && isFirstRound && int64(index) == request.GetBankerIndex() && len(gameInfo.GetOutputInfo()) == 0,
+		IsDiscardLand: isSelfDraw && isFirstRound && int64(index) != request.GetBankerIndex(),
+	}
+	winResult, msgErr := obj.Variant.GetWin(request, onePlayer, winContext)
+	if msgErr != nil {
+		return msgErr
+	}
+	winScore := winResult.WinScore
+
+	winInfo := &pb.MahjongWinInfo{
+		WinPlayerIndex: index,
+		WinPlayerUuid:  onePlayer.GetUuid(),
+		WinCard:        mahjong,
+		WinType:        winResult.WinTypes,
+		WinSource:      winResult.WinSource,
+		WinScore:       winScore,
+	}
+	if payerIndex != index {
+		payer := request.GetPlayerInfo()[payerIndex].GetMahjongPlayerInfo()
+		winInfo.OutputPlayerIndex = payerIndex
+		if winSource == pb.MahjongWinSourceEnum_GrabKong {
+			winInfo.PongInfo = payer.GetPongRegion()
+			winInfo.KongInfo = payer.GetKongRegion()
+		} else {
+			winInfo.OutputCards = payer.GetOutputRegion()
+		}
+	}
+	detail := &pb.MahjongSettleDetail{WinInfo: winInfo}
+	for i, otherPlayer := range request.GetPlayerInfo() {
+		if int32(i) == index || !IsActive(otherPlayer) || (payerIndex != index && int32(i) != payerIndex) {
+			continue
+		}
+		Transfer(request, int32(i), index, winScore, detail, obj.Variant.WinReason)
+	}
+
+	mahjongPlayer.WinRegion = append(mahjongPlayer.GetWinRegion(), mahjong)
+	mahjongPlayer.NewMahjong = nil
+	mahjongPlayer.WaitChoice = nil
+	// 本局第一个胡牌的玩家下一局坐庄
+	if len(gameInfo.GetWinInfo()) == 0 {
+		request.MahjongLastWinnerIndex = int64(index)
+		request.MahjongLastWinnerUuid = onePlayer.GetUuid()
+	}
+	gameInfo.WinInfo = append(gameInfo.GetWinInfo(), winInfo)
+	pushWin := &pb.MahjongWinNotice{
+		RoomId:  request.GetUuid(),
+		WinInfo: []*pb.MahjongWinInfo{winInfo},
+	}
+	common.RoomBroadcast(request, pushWin)
+	return nil
+}
+
+// RequestOperate 玩家出牌、碰、杠、胡、过
+func (obj *MahjongPlay) RequestOperate(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := obj.Variant.NewOperateRequest()
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError(obj.Variant.Name+"Play RequestOperate ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	index := GetPlayerIndex(roomInfo, uid)
+	if index < 0 || !IsActive(roomInfo.GetPlayerInfo()[index]) {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	nowTime := time.Now().Unix()
+	var msgErr *pb.ErrorMessage
+	if len(roomInfo.GetMahjongGameInfo().GetWaitOperateRecord()) == 0 {
+		msgErr = obj.doTurnOperate(roomInfo, index, realRequest, nowTime)
+	} else {
+		msgErr = obj.doResponseOperate(roomInfo, index, realRequest, nowTime)
+	}
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return packReply(roomInfo, obj.Variant.NewOperateReply(roomInfo.GetUuid(), index))
+}
+
+// doTurnOperate 轮到自己时出牌、暗杠、巴杠或者自摸
+func (obj *MahjongPlay) doTurnOperate(roomInfo *pb.RoomInfo, index int32, realRequest OperateRequest, nowTime int64) *pb.ErrorMessage {
+	gameInfo := roomInfo.GetMahjongGameInfo()
+	onePlayer := roomInfo.GetPlayerInfo()[index]
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	hand := mahjongPlayer.GetHandRegion()
+	mahjong := realRequest.GetMahjong()
+	isMyTurn := index == roomInfo.GetDoIndex()
+	switch realRequest.GetOperateType() {
+	case pb.MahjongOperateEnum_output:
+		if !isMyTurn {
+			return common.GetGrpcErrorMessage(obj.Variant.ErrorCodes.NoPermissionOutput, "")
+		}
+		// 手里有定缺的牌时必须先打定缺的牌
+		if CountMahjong(hand, mahjong) == 0 ||
+			(obj.Variant.hasBlankSuit(hand, mahjongPlayer) && !obj.Variant.isBlankSuit(mahjong, mahjongPlayer)) {
+			return common.GetGrpcErrorMessage(obj.Variant.ErrorCodes.Output, "")
+		}
+		return obj.output(roomInfo, index, mahjong, nowTime)
+	case pb.MahjongOperateEnum_Kong:
+		if !isMyTurn || gameInfo.GetMahjongCardWallCount() == 0 {
+			return common.GetGrpcErrorMessage(obj.Variant.ErrorCodes.NoPermissionKong, "")
+		}
+		if obj.Variant.isBlankSuit(mahjong, mahjongPlayer) {
+			return common.GetGrpcErrorMessage(obj.Variant.ErrorCodes.Kong, "")
+		}
+		if CountMahjong(hand, mahjong) == 4 {
+			return obj.kong(roomInfo, index, pb.MahjongKongEnum_KongAn, mahjong, index, nowTime)
+		}
+		for _, baKongMahjong := range GetBaKongMahjongs(hand, mahjongPlayer.GetPongRegion()) {
+			if IsSameMahjong(baKongMahjong, mahjong) {
+				return obj.tryBaKong(roomInfo, index, mahjong, nowTime)
+			}
+		}
+		return common.GetGrpcErrorMessage(obj.Variant.ErrorCodes.Kong, "")
+	case pb.MahjongOperateEnum_Win:
+		if !isMyTurn || gameInfo.GetBPong() || !obj.Variant.CanPlayerWin(roomInfo, onePlayer, nil) {
+			return common.GetGrpcErrorMessage(obj.Variant.ErrorCodes.NoPermissionWin, "")
+		}
+		return obj.selfWin(roomInfo, index, nowTime)
+	}
+	return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+}
+
+// doResponseOperate 响应别人打出的牌或者巴杠的牌，所有人都响应后按优先级处理
+func (obj *MahjongPlay) doResponseOperate(roomInfo *pb.RoomInfo, index int32, realRequest OperateRequest, nowTime int64) *pb.ErrorMessage {
+	operateType := realRequest.GetOperateType()
+	if !obj.canResponse(roomInfo, index, operateType) {
+		switch operateType {
+		case pb.MahjongOperateEnum_output:
+			return common.GetGrpcErrorMessage(obj.Variant.ErrorCodes.NoPermissionOutput, "")
+		case pb.MahjongOperateEnum_Pong:
+			return common.GetGrpcErrorMessage(obj.Variant.ErrorCodes.NoPermissionPong, "")
+		case pb.MahjongOperateEnum_Kong:
+			return common.GetGrpcErrorMessage(obj.Variant.ErrorCodes.NoPermissionKong, "")
+		case pb.MahjongOperateEnum_Win:
+			return common.GetGrpcErrorMessage(obj.Variant.ErrorCodes.NoPermissionWin, "")
+		}
+		return common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	if obj.getResponse(roomInfo, index) != nil {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	obj.response(roomInfo, index, operateType)
+	if !obj.isAllResponded(roomInfo) {
+		return nil
+	}
+	return obj.resolve(roomInfo, nowTime)
+}
+
+// RequestExitInGame 玩家在对局中退出房间
+// 这里只标记为等待踢出，之后由系统自动操作，结算后状态置空由房间的Kick踢出
+func (obj *MahjongPlay) RequestExitInGame(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	index := GetPlayerIndex(roomInfo, uid)
+	if index < 0 {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	playerInfo := roomInfo.GetPlayerInfo()[index]
+	playerInfo.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_Exit
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStatePlay && roomInfo.GetNextRoomState() == pb.RoomState_RoomStateSettle && IsActive(playerInfo) {
+		nowTime := time.Now().Unix()
+		var msgErr *pb.ErrorMessage
+		if len(roomInfo.GetMahjongGameInfo().GetWaitOperateRecord()) == 0 && index == roomInfo.GetDoIndex() {
+			// 轮到自己时退出，缩短等待时间尽快自动出牌
+			msgErr = obj.startTurn(roomInfo, index, nowTime)
+		} else if obj.canResponse(roomInfo, index, pb.MahjongOperateEnum_Pass) && obj.getResponse(roomInfo, index) == nil {
+			obj.autoResponse(roomInfo, index)
+			if obj.isAllResponded(roomInfo) {
+				msgErr = obj.resolve(roomInfo, nowTime)
+			}
+		}
+		if msgErr != nil {
+			return reply, msgErr
+		}
+	}
+	// 结算阶段本局已经结算完了，可以直接踢出
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStateSettle && roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+	}
+	return packReply(roomInfo, &pb.GameExitRoomReply{})
+}
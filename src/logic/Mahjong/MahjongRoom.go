@@ -0,0 +1,254 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+	"strings"
+)
+
+const (
+	// handMahjongNum 每个玩家发牌的张数，庄家多发一张
+	handMahjongNum = 13
+	// changeMahjongNum 换三张时交换的张数
+	changeMahjongNum = 3
+)
+
+// kongScoreConfigNames 每种杠的分数(底分的倍数)对应的房间配置名
+var kongScoreConfigNames = map[pb.MahjongKongEnum]string{
+	pb.MahjongKongEnum_KongZhi: "ZhiKongScore",
+	pb.MahjongKongEnum_KongBa:  "BaKongScore",
+	pb.MahjongKongEnum_KongAn:  "AnKongScore",
+}
+
+// GetRoomConfigInt64 获取房间的整数配置
+func GetRoomConfigInt64(roomInfo *pb.RoomInfo, configName string) (int64, *pb.ErrorMessage) {
+	configStr := common.GetRoomConfig(roomInfo, configName)
+	configNum, err := strconv.ParseInt(configStr, 10, 64)
+	if err != nil {
+		common.LogError("Mahjong GetRoomConfigInt64 has err", roomInfo.GetGameType(), configName, configStr, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return configNum, nil
+}
+
+// GetRoomConfigScoreTable 获取房间配置的分数表，配置格式为"名字:分数,名字:分数"，
+// 名字是枚举的名字，nameValue为枚举的名字到值的映射(例如pb.MahjongWinEnum_value)，没有配置的项不在表中
+func GetRoomConfigScoreTable(roomInfo *pb.RoomInfo, configName string, nameValue map[string]int32) (map[int32]int64, *pb.ErrorMessage) {
+	configStr := common.GetRoomConfig(roomInfo, configName)
+	scoreTable := map[int32]int64{}
+	for _, oneItem := range strings.Split(configStr, ",") {
+		if oneItem == "" {
+			continue
+		}
+		nameScore := strings.Split(oneItem, ":")
+		if len(nameScore) != 2 {
+			common.LogError("Mahjong GetRoomConfigScoreTable item has err", roomInfo.GetGameType(), configName, oneItem)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		value, ok := nameValue[nameScore[0]]
+		score, err := strconv.ParseInt(nameScore[1], 10, 64)
+		if !ok || err != nil {
+			common.LogError("Mahjong GetRoomConfigScoreTable item has err", roomInfo.GetGameType(), configName, oneItem, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		scoreTable[value] = score
+	}
+	return scoreTable, nil
+}
+
+// IsRoomConfigOpen 房间的玩法开关是否打开，配置为1时打开，没有配置时关闭
+func IsRoomConfigOpen(roomInfo *pb.RoomInfo, configName string) bool {
+	return common.GetRoomConfig(roomInfo, configName) == "1"
+}
+
+// SetHandRegion 整理并设置玩家的手牌
+func SetHandRegion(mahjongPlayer *pb.MahjongPlayerInfo, hand []*pb.Mahjong) {
+	SortMahjongs(hand)
+	mahjongPlayer.HandRegion = hand
+	mahjongPlayer.HandCardsNum = int32(len(hand))
+}
+
+// GetMeldMahjongs 获取玩家碰牌和杠牌区每一组牌的牌，每组一张
+func GetMeldMahjongs(mahjongPlayer *pb.MahjongPlayerInfo) []*pb.Mahjong {
+	var meldMahjongs []*pb.Mahjong
+	for _, onePong := range mahjongPlayer.GetPongRegion() {
+		meldMahjongs = append(meldMahjongs, onePong.GetPongMahjongCard())
+	}
+	for _, oneKong := range mahjongPlayer.GetKongRegion() {
+		meldMahjongs = append(meldMahjongs, oneKong.GetKongMahjongCard())
+	}
+	return meldMahjongs
+}
+
+// GetAllMahjongs 获取玩家手牌、碰牌、杠牌区所有的牌，hand为胡牌时的手牌
+func GetAllMahjongs(mahjongPlayer *pb.MahjongPlayerInfo, hand []*pb.Mahjong) []*pb.Mahjong {
+	allMahjongs := append([]*pb.Mahjong{}, hand...)
+	for _, onePong := range mahjongPlayer.GetPongRegion() {
+		for i := 0; i < 3; i++ {
+			allMahjongs = append(allMahjongs, onePong.GetPongMahjongCard())
+		}
+	}
+	for _, oneKong := range mahjongPlayer.GetKongRegion() {
+		for i := 0; i < 4; i++ {
+			allMahjongs = append(allMahjongs, oneKong.GetKongMahjongCard())
+		}
+	}
+	return allMahjongs
+}
+
+// GetRemainNum 从玩家的角度计算某张牌还剩多少张没有出现
+func GetRemainNum(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, mahjong *pb.Mahjong) int32 {
+	visibleNum := CountMahjong(onePlayer.GetMahjongPlayerInfo().GetHandRegion(), mahjong)
+	for _, oneOutput := range roomInfo.GetMahjongGameInfo().GetOutputInfo() {
+		if !oneOutput.GetBHide() && IsSameMahjong(oneOutput.GetOutputMahjongCard(), mahjong) {
+			visibleNum++
+		}
+	}
+	for _, otherPlayer := range roomInfo.GetPlayerInfo() {
+		mahjongPlayer := otherPlayer.GetMahjongPlayerInfo()
+		for _, onePong := range mahjongPlayer.GetPongRegion() {
+			if IsSameMahjong(onePong.GetPongMahjongCard(), mahjong) {
+				visibleNum += 3
+			}
+		}
+		for _, oneKong := range mahjongPlayer.GetKongRegion() {
+			if IsSameMahjong(oneKong.GetKongMahjongCard(), mahjong) {
+				visibleNum += 4
+			}
+		}
+		visibleNum += CountMahjong(mahjongPlayer.GetWinRegion(), mahjong)
+	}
+	if visibleNum > 4 {
+		return 0
+	}
+	return int32(4 - visibleNum)
+}
+
+// IsPlaying 玩家是否在本局游戏中
+func IsPlaying(onePlayer *pb.RoomPlayerInfo) bool {
+	return onePlayer.GetUuid() != "" && onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay
+}
+
+// IsActive 玩家是否还在打牌，胡了牌的玩家不再参与后面的对局
+func IsActive(onePlayer *pb.RoomPlayerInfo) bool {
+	return IsPlaying(onePlayer) && len(onePlayer.GetMahjongPlayerInfo().GetWinRegion()) == 0
+}
+
+// GetActiveNum 获取还在打牌的玩家数量
+func GetActiveNum(roomInfo *pb.RoomInfo) int {
+	activeNum := 0
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if IsActive(onePlayer) {
+			activeNum++
+		}
+	}
+	return activeNum
+}
+
+// getNextActiveIndex 获取座位index之后下一个还在打牌的玩家座位
+func getNextActiveIndex(roomInfo *pb.RoomInfo, index int32) int32 {
+	playerNum := int32(len(roomInfo.GetPlayerInfo()))
+	for i := int32(1); i <= playerNum; i++ {
+		nextIndex := (index + i) % playerNum
+		if IsActive(roomInfo.GetPlayerInfo()[nextIndex]) {
+			return nextIndex
+		}
+	}
+	return index
+}
+
+// GetPlayerIndex 获取玩家在房间中的座位，不在房间中返回-1
+func GetPlayerIndex(roomInfo *pb.RoomInfo, uid string) int32 {
+	for index, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == uid {
+			return int32(index)
+		}
+	}
+	return -1
+}
+
+// isOnline 玩家是否在线
+func isOnline(onePlayer *pb.RoomPlayerInfo) bool {
+	online, msgErr := common.Pusher.CheckOnline(onePlayer.GetUuid())
+	if msgErr != nil {
+		common.LogError("Mahjong isOnline CheckOnline has err", onePlayer.GetUuid(), msgErr)
+		return false
+	}
+	return online
+}
+
+// IsAutoOperate 断线或者已经退出的玩家由系统自动操作
+func IsAutoOperate(onePlayer *pb.RoomPlayerInfo) bool {
+	return onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None || !isOnline(onePlayer)
+}
+
+// Transfer 玩家之间实时转账，付款的金额不超过付款玩家身上的金币，返回实际转账的金额
+// detail为这笔转账的原因(杠、胡、流局前的结算)，付款和收款双方各记录一条流水明细
+func Transfer(roomInfo *pb.RoomInfo, payerIndex int32, receiverIndex int32, amount int64, detail *pb.MahjongSettleDetail, reason pb.ResourceChangeReason) int64 {
+	payer := roomInfo.GetPlayerInfo()[payerIndex]
+	receiver := roomInfo.GetPlayerInfo()[receiverIndex]
+	if amount > payer.GetBalance() {
+		amount = payer.GetBalance()
+	}
+	if amount <= 0 {
+		return 0
+	}
+	payer.Balance -= amount
+	payer.WinOrLose -= amount
+	receiver.Balance += amount
+	receiver.WinOrLose += amount
+
+	gameInfo := roomInfo.GetMahjongGameInfo()
+	for _, one := range []struct {
+		index  int32
+		player *pb.RoomPlayerInfo
+		change int64
+	}{{payerIndex, payer, -amount}, {receiverIndex, receiver, amount}} {
+		oneDetail := proto.Clone(detail).(*pb.MahjongSettleDetail)
+		oneDetail.PlayerIndex = one.index
+		oneDetail.PlayerUuid = one.player.GetUuid()
+		oneDetail.AmountChange = one.change
+		one.player.MahjongPlayerInfo.SettleDetail = append(one.player.GetMahjongPlayerInfo().GetSettleDetail(), oneDetail)
+		gameInfo.SettleDetail = append(gameInfo.GetSettleDetail(), oneDetail)
+		go saveBalance(roomInfo, one.player.GetUuid(), one.change, one.player.GetBalance(), reason)
+	}
+	return amount
+}
+
+// saveBalance 修改玩家真实的金币并推送金币变动
+func saveBalance(roomInfo *pb.RoomInfo, uuid string, changeBalance int64, afterBalance int64, reason pb.ResourceChangeReason) {
+	msgErr := common.ChangeOtherBalance(uuid, changeBalance, false, true, reason)
+	if msgErr != nil {
+		common.LogError("Mahjong saveBalance ChangeOtherBalance has err", uuid, changeBalance, msgErr)
+		return
+	}
+	userBalanceChangePush := &pb.PushUserBalanceChange{}
+	userBalanceChangePush.UserId = uuid
+	userBalanceChangePush.Balance = afterBalance
+	common.RoomBroadcast(roomInfo, userBalanceChangePush)
+}
+
+// GetSeatBalances 获取每个座位玩家的金币，用于推送给前端展示
+func GetSeatBalances(roomInfo *pb.RoomInfo) []int64 {
+	balances := make([]int64, len(roomInfo.GetPlayerInfo()))
+	for index, onePlayer := range roomInfo.GetPlayerInfo() {
+		balances[index] = onePlayer.GetBalance()
+	}
+	return balances
+}
+
+// packReply 封装回复给driver的房间信息和回复消息
+func packReply(roomInfo *pb.RoomInfo, realReply proto.Message) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	realReplyAny, err := ptypes.MarshalAny(realReply)
+	if err != nil {
+		common.LogError("Mahjong packReply MarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	reply.RoomInfo = roomInfo
+	reply.Message = realReplyAny
+	return reply, nil
+}
@@ -0,0 +1,177 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"sort"
+)
+
+// 麻将引擎，提供各个麻将游戏通用的牌型、牌墙和手牌分析，不包含任何组件
+// 牌的种类用下标表示：0-26为筒条万(每种花色9张)，27-30为东南西北，31-33为中发白，花牌不参与胡牌分析
+
+// TileKindNum 参与胡牌分析的牌的种类数
+const TileKindNum = 34
+
+// suitColors 序数牌的花色，顺序决定了牌的下标
+var suitColors = []pb.MahjongColor{
+	pb.MahjongColor_MahjongColorDot,
+	pb.MahjongColor_MahjongColorBamboo,
+	pb.MahjongColor_MahjongColorCharacter,
+}
+
+// TileCounts 手牌中每种牌的数量，下标为牌的下标
+type TileCounts [TileKindNum]int
+
+// GetTileIndex 获取麻将牌的下标，花牌和无效的牌返回-1
+func GetTileIndex(mahjong *pb.Mahjong) int {
+	num := int(mahjong.GetMahjongNum())
+	switch mahjong.GetMahjongColor() {
+	case pb.MahjongColor_MahjongColorWind:
+		if num >= 1 && num <= 4 {
+			return 27 + num - 1
+		}
+		return -1
+	case pb.MahjongColor_MahjongColorDragon:
+		if num >= 1 && num <= 3 {
+			return 31 + num - 1
+		}
+		return -1
+	}
+	for colorIndex, color := range suitColors {
+		if mahjong.GetMahjongColor() == color && num >= 1 && num <= 9 {
+			return colorIndex*9 + num - 1
+		}
+	}
+	return -1
+}
+
+// GetTileByIndex 根据下标获取麻将牌
+func GetTileByIndex(index int) *pb.Mahjong {
+	switch {
+	case index < 27:
+		return &pb.Mahjong{MahjongColor: suitColors[index/9], MahjongNum: pb.MahjongNum(index%9 + 1)}
+	case index < 31:
+		return &pb.Mahjong{MahjongColor: pb.MahjongColor_MahjongColorWind, MahjongNum: pb.MahjongNum(index - 27 + 1)}
+	default:
+		return &pb.Mahjong{MahjongColor: pb.MahjongColor_MahjongColorDragon, MahjongNum: pb.MahjongNum(index - 31 + 1)}
+	}
+}
+
+// IsSuitIndex 下标是否是序数牌（筒条万）
+func IsSuitIndex(index int) bool {
+	return index >= 0 && index < 27
+}
+
+// GetIndexNum 获取序数牌下标对应的点数，字牌返回0
+func GetIndexNum(index int) int {
+	if !IsSuitIndex(index) {
+		return 0
+	}
+	return index%9 + 1
+}
+
+// IsSameMahjong 两张麻将牌是否相同
+func IsSameMahjong(a *pb.Mahjong, b *pb.Mahjong) bool {
+	return a.GetMahjongColor() == b.GetMahjongColor() && a.GetMahjongNum() == b.GetMahjongNum()
+}
+
+// GetMahjongWall 获取由指定花色组成的牌墙，序数牌和字牌每种4张，花牌8张各1张，牌墙未洗牌
+func GetMahjongWall(colors []pb.MahjongColor) []*pb.Mahjong {
+	var wall []*pb.Mahjong
+	for _, color := range colors {
+		maxNum, copyNum := 9, 4
+		switch color {
+		case pb.MahjongColor_MahjongColorWind:
+			maxNum = 4
+		case pb.MahjongColor_MahjongColorDragon:
+			maxNum = 3
+		case pb.MahjongColor_MahjongColorFlower:
+			maxNum, copyNum = 8, 1
+		}
+		for num := 1; num <= maxNum; num++ {
+			for i := 0; i < copyNum; i++ {
+				wall = append(wall, &pb.Mahjong{MahjongColor: color, MahjongNum: pb.MahjongNum(num)})
+			}
+		}
+	}
+	return wall
+}
+
+// GetShuffleMahjongWall 获取由指定花色组成并且已经洗好的牌墙
+func GetShuffleMahjongWall(colors []pb.MahjongColor) []*pb.Mahjong {
+	wall := GetMahjongWall(colors)
+	common.RandSlice(wall)
+	return wall
+}
+
+// SortMahjongs 按花色和点数整理麻将牌
+func SortMahjongs(mahjongs []*pb.Mahjong) {
+	sort.Slice(mahjongs, func(i, j int) bool {
+		if mahjongs[i].GetMahjongColor() != mahjongs[j].GetMahjongColor() {
+			return mahjongs[i].GetMahjongColor() < mahjongs[j].GetMahjongColor()
+		}
+		return mahjongs[i].GetMahjongNum() < mahjongs[j].GetMahjongNum()
+	})
+}
+
+// CountMahjong 统计某张牌在牌中的数量
+func CountMahjong(mahjongs []*pb.Mahjong, mahjong *pb.Mahjong) int {
+	num := 0
+	for _, oneMahjong := range mahjongs {
+		if IsSameMahjong(oneMahjong, mahjong) {
+			num++
+		}
+	}
+	return num
+}
+
+// CountColor 统计某种花色在牌中的数量
+func CountColor(mahjongs []*pb.Mahjong, color pb.MahjongColor) int {
+	num := 0
+	for _, oneMahjong := range mahjongs {
+		if oneMahjong.GetMahjongColor() == color {
+			num++
+		}
+	}
+	return num
+}
+
+// RemoveMahjong 从牌中去掉num张指定的牌，返回去掉后的新切片，牌的数量不够时返回false并且不修改
+func RemoveMahjong(mahjongs []*pb.Mahjong, mahjong *pb.Mahjong, num int) ([]*pb.Mahjong, bool) {
+	if CountMahjong(mahjongs, mahjong) < num {
+		return mahjongs, false
+	}
+	remain := make([]*pb.Mahjong, 0, len(mahjongs)-num)
+	for _, oneMahjong := range mahjongs {
+		if num > 0 && IsSameMahjong(oneMahjong, mahjong) {
+			num--
+			continue
+		}
+		remain = append(remain, oneMahjong)
+	}
+	return remain, true
+}
+
+// GetTileCounts 统计牌中每种牌的数量，花牌不统计
+func GetTileCounts(mahjongs []*pb.Mahjong) TileCounts {
+	var counts TileCounts
+	for _, oneMahjong := range mahjongs {
+		index := GetTileIndex(oneMahjong)
+		if index >= 0 {
+			counts[index]++
+		}
+	}
+	return counts
+}
+
+// GetDistinctMahjongs 获取牌中不重复的牌，按牌的下标顺序返回
+func GetDistinctMahjongs(mahjongs []*pb.Mahjong) []*pb.Mahjong {
+	var distinct []*pb.Mahjong
+	counts := GetTileCounts(mahjongs)
+	for index, num := range counts {
+		if num > 0 {
+			distinct = append(distinct, GetTileByIndex(index))
+		}
+	}
+	return distinct
+}
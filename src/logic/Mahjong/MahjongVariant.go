@@ -0,0 +1,167 @@
+package logic
+
+import (
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+)
+
+// 各地麻将共用准备、发牌、换三张、定缺、打牌和结算的流程组件(MahjongReady、MahjongDeal等)，
+// 流程中各地规则不同的地方由Variant描述：牌墙组成、是否定缺、是否血战到底这些用数据描述，
+// 胡牌牌型和计分用函数描述，分数和玩法开关从房间配置中读取。
+// 具体的游戏用自己的Variant创建这些组件并注册成自己的组件名，例如：
+// common.AllComponentMap["XueZhanMahjongPlay"] = &Mahjong.MahjongPlay{Variant: xueZhanVariant}
+
+// OperateRequest 各个麻将游戏自己的操作请求，都包含操作类型和操作的牌
+type OperateRequest interface {
+	proto.Message
+	GetOperateType() pb.MahjongOperateEnum
+	GetMahjong() *pb.Mahjong
+}
+
+// ErrorCodes 各个麻将游戏自己的操作错误码
+type ErrorCodes struct {
+	// NoPermissionOutput 没有出牌权限
+	NoPermissionOutput pb.ErrorCode
+	// Output 出的牌不对
+	Output pb.ErrorCode
+	// NoPermissionPong 没有碰牌权限
+	NoPermissionPong pb.ErrorCode
+	// NoPermissionKong 没有杠牌权限
+	NoPermissionKong pb.ErrorCode
+	// Kong 杠的牌不对
+	Kong pb.ErrorCode
+	// NoPermissionWin 没有胡牌权限
+	NoPermissionWin pb.ErrorCode
+	// BlankSuitColor 定缺的花色不对
+	BlankSuitColor pb.ErrorCode
+}
+
+// WinContext 玩家胡牌时的牌局情况，用于计算胡牌类型和分数
+type WinContext struct {
+	// Hand 胡牌时的手牌，包含胡的那张牌
+	Hand []*pb.Mahjong
+	// WinMahjong 胡的那张牌
+	WinMahjong *pb.Mahjong
+	// WinSource 胡牌来源
+	WinSource pb.MahjongWinSourceEnum
+	// IsSeabed 牌墙已经摸完(海底)
+	IsSeabed bool
+	// IsDrawSky 庄家第一手自摸(天胡)
+	IsDrawSky bool
+	// IsDiscardLand 闲家第一次摸牌自摸(地胡)
+	IsDiscardLand bool
+}
+
+// WinResult 胡牌的结果，WinScore为每个付款玩家要付的分数
+type WinResult struct {
+	WinTypes  []pb.MahjongWinEnum
+	WinSource pb.MahjongWinSourceEnum
+	WinScore  int64
+}
+
+// Variant 一种地方麻将的规则
+type Variant struct {
+	// Name 游戏名，用作日志的前缀
+	Name string
+	// GameType 游戏类型
+	GameType pb.GameType
+	// ConfigTemp 游戏的配置模板
+	ConfigTemp *map[string]*pb.GameConfig
+	// Colors 牌墙包含的花色，包含花牌时摸到花牌要亮出来并从牌墙尾部补牌
+	Colors []pb.MahjongColor
+	// HasBlankSuit 是否定缺：定缺的花色不能碰杠，要先打完，手里有定缺的牌不能胡牌
+	HasBlankSuit bool
+	// IsBloodBattle 是否血战到底：胡了牌的玩家不再参与后面的对局，其他玩家继续打到只剩一个人；
+	// 否则有人胡牌本局就结束
+	IsBloodBattle bool
+	// KongReason 杠牌收钱的金币变动原因
+	KongReason pb.ResourceChangeReason
+	// WinReason 胡牌和结算的金币变动原因
+	WinReason pb.ResourceChangeReason
+	// ErrorCodes 操作的错误码
+	ErrorCodes ErrorCodes
+	// NewOperateRequest 创建游戏自己的操作请求，用于解析玩家的操作
+	NewOperateRequest func() OperateRequest
+	// NewOperateReply 创建游戏自己的操作回复
+	NewOperateReply func(roomId string, playerIndex int32) proto.Message
+	// GetWinChecker 根据房间的玩法配置获取胡牌牌型的判断，判断的牌不包含花牌
+	GetWinChecker func(roomInfo *pb.RoomInfo) WinChecker
+	// GetWin 计算玩家胡牌的类型和分数
+	GetWin func(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, winContext *WinContext) (*WinResult, *pb.ErrorMessage)
+	// ChooseWinners 多个玩家胡同一张牌时选出真正胡牌的玩家，winners按出牌玩家之后的座位顺序排列；
+	// 为空时所有人都胡(一炮多响)
+	ChooseWinners func(roomInfo *pb.RoomInfo, winners []int32, mahjong *pb.Mahjong) []int32
+	// BeforeSettle 结算前的额外结算(例如流局时查花猪、查大叫)，可以为空
+	BeforeSettle func(roomInfo *pb.RoomInfo) *pb.ErrorMessage
+	// AfterSettle 结算信息生成后补充游戏自己的结算信息和推送，可以为空
+	AfterSettle func(roomInfo *pb.RoomInfo, settleInfo *pb.SettleInfo, players []*pb.RoomPlayerInfo)
+}
+
+// hasFlower 牌墙中是否有花牌
+func (v *Variant) hasFlower() bool {
+	for _, color := range v.Colors {
+		if color == pb.MahjongColor_MahjongColorFlower {
+			return true
+		}
+	}
+	return false
+}
+
+// getSuitColors 牌墙中的序数牌花色，也是可以定缺的花色
+func (v *Variant) getSuitColors() []pb.MahjongColor {
+	var colors []pb.MahjongColor
+	for _, color := range v.Colors {
+		for _, suitColor := range suitColors {
+			if color == suitColor {
+				colors = append(colors, color)
+			}
+		}
+	}
+	return colors
+}
+
+// hasBlankSuit 牌中是否还有玩家定缺花色的牌，不定缺的麻将总是没有
+func (v *Variant) hasBlankSuit(mahjongs []*pb.Mahjong, mahjongPlayer *pb.MahjongPlayerInfo) bool {
+	return v.HasBlankSuit && CountColor(mahjongs, mahjongPlayer.GetBlankSuit()) > 0
+}
+
+// isBlankSuit 牌是否是玩家定缺的花色
+func (v *Variant) isBlankSuit(mahjong *pb.Mahjong, mahjongPlayer *pb.MahjongPlayerInfo) bool {
+	return v.HasBlankSuit && mahjong.GetMahjongColor() == mahjongPlayer.GetBlankSuit()
+}
+
+// CanPlayerWin 玩家手牌加上mahjong能否胡牌，mahjong为空时判断手牌自摸
+func (v *Variant) CanPlayerWin(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, mahjong *pb.Mahjong) bool {
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	hand := mahjongPlayer.GetHandRegion()
+	if mahjong != nil {
+		hand = append(append([]*pb.Mahjong{}, hand...), mahjong)
+	}
+	if len(hand)%3 != 2 || v.hasBlankSuit(hand, mahjongPlayer) {
+		return false
+	}
+	return v.GetWinChecker(roomInfo)(GetTileCounts(hand))
+}
+
+// getIfOutputInfo 分析玩家打出每一张牌后能听的牌
+func (v *Variant) getIfOutputInfo(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) []*pb.MahjongReadyInfoIfOutput {
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	hand := mahjongPlayer.GetHandRegion()
+	// 手里还有两张以上定缺的牌，打出一张也不能听牌
+	if v.HasBlankSuit && CountColor(hand, mahjongPlayer.GetBlankSuit()) > 1 {
+		return nil
+	}
+	checker := v.GetWinChecker(roomInfo)
+	return GetIfOutputInfo(hand, func(counts TileCounts) bool {
+		if v.HasBlankSuit {
+			for index, num := range counts {
+				if num > 0 && GetTileByIndex(index).GetMahjongColor() == mahjongPlayer.GetBlankSuit() {
+					return false
+				}
+			}
+		}
+		return checker(counts)
+	}, func(mahjong *pb.Mahjong) int32 {
+		return GetRemainNum(roomInfo, onePlayer, mahjong)
+	})
+}
@@ -312,7 +312,12 @@ func (obj *PushBobbinSettle) realDrive(request *pb.RoomInfo) *pb.ErrorMessage {
 			// 后面协程操作，为避免错误在此处提取金额
 			addMoney := onePlayerInfo.GetGetBonus() + onePlayerInfo.GetWinOrLose()
 			//common.LogDebug("同步金币：", onePlayerInfo.Account, "奖金：", onePlayerInfo.GetGetBonus(), "纯输赢：", onePlayerInfo.GetWinOrLose())
-			go obj.saveMoney(request, onePlayerInfo, addMoney, winInfos)
+			// 机器人不记录游戏记录
+			var gameRecord *pb.GameRecordReport
+			if !onePlayerInfo.IsRobot {
+				gameRecord = obj.getGameRecord(request, onePlayerInfo, &winInfos, BankerWinBalance, nowTime)
+			}
+			go obj.saveMoney(request, onePlayerInfo, addMoney, winInfos, gameRecord)
 		}
 	}
 
@@ -442,8 +447,48 @@ func (obj *PushBobbinSettle) getCompareType(a []int64, b []int64) bool {
 	}
 }
 
+// getGameRecord 生成玩家本局的游戏记录，金额在saveMoney中修改完玩家金币后补全
+func (obj *PushBobbinSettle) getGameRecord(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, winInfos *pb.PushBobbinWinInfo, bankerWinOrLose int64, settleTime int64) *pb.GameRecordReport {
+	extendData := &pb.GameRecordExtendData{}
+	extendData.BankerUuid = roomInfo.GetBankerUuid()
+	extendData.BankerWinOrLose = bankerWinOrLose
+	extendData.PushBobbinMahjongList = roomInfo.GetPushBobbinMahjongList()
+	extendData.PushBobbinWinInfos = winInfos
+	for _, playerInfo := range roomInfo.GetPlayerInfo() {
+		if playerInfo.Uuid == roomInfo.GetBankerUuid() {
+			extendData.BankerShortId = playerInfo.GetShortId()
+			break
+		}
+	}
+	// 玩家各区下注
+	extendData.PlayerAllBet = make([]int64, len(onePlayer.GetPlayerBets()))
+	copy(extendData.PlayerAllBet, onePlayer.GetPlayerBets())
+	totalBet := int64(0)
+	for _, bet := range extendData.PlayerAllBet {
+		totalBet += bet
+	}
+
+	gameRecord := &pb.GameRecordReport{}
+	gameRecord.GameType = roomInfo.GetGameType()
+	gameRecord.GameScene = roomInfo.GetGameScene()
+	gameRecord.GameMode = common.GameMode
+	gameRecord.RoomType = roomInfo.GetRoomType()
+	gameRecord.RoomId = roomInfo.GetUuid()
+	gameRecord.RoundId = roomInfo.GetCurrentRoundId()
+	gameRecord.PlayerUuid = onePlayer.GetUuid()
+	gameRecord.PlayerShortId = onePlayer.GetShortId()
+	gameRecord.PlayerAccount = onePlayer.GetAccount()
+	gameRecord.StartTime = roomInfo.GetRoundStartTime()
+	gameRecord.SettleTime = settleTime
+	gameRecord.TotalBet = totalBet
+	gameRecord.WinOrLose = onePlayer.GetWinOrLose()
+	gameRecord.Commission = onePlayer.GetHundredCommission()
+	gameRecord.ExtendData = extendData
+	return gameRecord
+}
+
 // 推送金币变动
-func (obj *PushBobbinSettle) saveMoney(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, addMoney int64, winInfos pb.PushBobbinWinInfo) {
+func (obj *PushBobbinSettle) saveMoney(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, addMoney int64, winInfos pb.PushBobbinWinInfo, gameRecord *pb.GameRecordReport) {
 	taskConfig := &pb.TaskConfig{}
 	taskConfig.TaskType = pb.TaskType_Task_PlayGame
 	taskConfig.GameType = roomInfo.GetGameType()
@@ -465,6 +510,15 @@ func (obj *PushBobbinSettle) saveMoney(roomInfo *pb.RoomInfo, onePlayer *pb.Room
 	if afterBalance != onePlayer.Balance {
 		common.LogError("PushBobbinSettle saveMoney has err: afterBalance != onePlayer.Balance", afterBalance, onePlayer.Balance, "输赢：", onePlayer.WinOrLose)
 	}
+	// 推送游戏记录
+	if gameRecord != nil {
+		gameRecord.BeforeBalance = afterBalance - addMoney
+		gameRecord.SettleBalance = afterBalance
+		msgErr = common.PushGameRecord(gameRecord)
+		if msgErr != nil {
+			common.LogError("PushBobbinSettle saveMoney PushGameRecord has err", onePlayer.GetUuid(), msgErr)
+		}
+	}
 	userBalanceChangePush := &pb.PushUserBalanceChange{}
 	userBalanceChangePush.UserId = onePlayer.GetUuid()
 	userBalanceChangePush.Balance = afterBalance
@@ -0,0 +1,77 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["RedBlackDeal"] = &RedBlackDeal{}
+}
+
+// RedBlackDeal 红黑大战游戏组件，用于处理发牌阶段的逻辑
+type RedBlackDeal struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *RedBlackDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *RedBlackDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 房间发牌状态的驱动逻辑
+func (obj *RedBlackDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	//获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateDeal {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateBet
+		request.NextRoomState = pb.RoomState_RoomStateBet
+		request.DoTime = nowTime
+		return request, nil
+	}
+	dealTimeStr := common.GetRoomConfig(request, "DealTime")
+	dealTime, err := strconv.Atoi(dealTimeStr)
+	if err != nil {
+		common.LogError("RedBlackDeal Drive dealTimeStr has err", dealTimeStr)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 洗牌，开牌在结算阶段根据下注情况从牌堆中取
+	request.PokerCardHeap = common.GetShufflePokerHeap(1)
+
+	//推送消息
+	roomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateLocation,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + int64(dealTime),
+	}
+	common.RoomBroadcast(request, roomState)
+	// 红黑各发三张暗牌
+	hiddenPokers := make([]*pb.Poker, 0, handPokerNum*2)
+	for index := 0; index < handPokerNum*2; index++ {
+		hiddenPokers = append(hiddenPokers, &pb.Poker{PokerNum: pb.PokerNum_PokerNumNone, PokerColor: pb.PokerColor_PokerColorNone})
+	}
+	pushPoker := &pb.PushRedBlackPoker{
+		RoomId:        request.GetUuid(),
+		RedBlackPoker: hiddenPokers,
+	}
+	common.RoomBroadcast(request, pushPoker)
+
+	//下个状态
+	request.NextRoomState = pb.RoomState_RoomStateBet
+	request.DoTime = nowTime + int64(dealTime)
+	return request, nil
+}
@@ -0,0 +1,143 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["RedBlackLocation"] = &RedBlackLocation{}
+}
+
+// RedBlackLocation 红黑大战游戏的房间状态组件，用于处理定庄阶段的逻辑(回合的第一个阶段）
+type RedBlackLocation struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *RedBlackLocation) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *RedBlackLocation) Start() {
+	obj.Base.Start()
+}
+
+// Drive 定庄阶段的主驱动
+func (obj *RedBlackLocation) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateLocation {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStateDeal
+		request.NextRoomState = pb.RoomState_RoomStateDeal
+		request.DoTime = nowTime
+		return request, nil
+	}
+	//玩家当庄最低金额
+	minMoneyStr := common.GetRoomConfig(request, "UserBankerMoney")
+	minMoney, err := strconv.ParseInt(minMoneyStr, 10, 64)
+	if err != nil {
+		common.LogError("RedBlackLocation Drive UserBankerMoney has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	//玩家当庄最大回合数
+	maxRoundStr := common.GetRoomConfig(request, "UserBankerRound")
+	maxRound, err := strconv.ParseInt(maxRoundStr, 10, 64)
+	if err != nil {
+		common.LogError("RedBlackLocation Drive UserBankerRound has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	odds, msgErr := getRedBlackOdds(request)
+	if msgErr != nil {
+		return request, msgErr
+	}
+
+	// 1.检测更换庄家
+	pushBanker := &pb.PushRedBlackBankerMessage{
+		RoomId:              request.GetUuid(),
+		BeforeBanker:        request.GetBankerUuid(),
+		ReplaceBankerReason: pb.KickBankerReason_KickBankerNone,
+	}
+
+	// 更新庄家坐庄次数
+	request.BankerNowRound++
+	var tempBankers []string
+	// 检测庄家队列中金币小于上庄最低金额的玩家
+	for _, bankerUuid := range request.Bankers {
+		if !common.PlayerMoneyEnoughOrInRoom(bankerUuid, minMoney, request) {
+			//更新移除队列中的玩家的状态为空闲
+			tempPlayer := common.GetRoomPlayerInfo(request, bankerUuid)
+			if tempPlayer != nil {
+				tempPlayer.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateFree
+			}
+			continue
+		}
+		tempBankers = append(tempBankers, bankerUuid)
+	}
+	request.Bankers = tempBankers
+
+	// 1.1当房间庄家是玩家时
+	if request.GetBankerUuid() != "" && request.GetBankerUuid() != "systemBanker" {
+		// 容错庄家离线被kick
+		pushBanker.ReplaceBankerReason = pb.KickBankerReason_KickBankerByRoom
+		bankerInfo := common.GetRoomPlayerInfo(request, request.GetBankerUuid())
+		if bankerInfo != nil {
+			pushBanker.ReplaceBankerReason = pb.KickBankerReason_KickBankerNone
+			// 钱不够就赋值庄家改变原因 是 钱不够
+			if bankerInfo.GetBalance() < minMoney && !request.DownBankerQuest {
+				pushBanker.ReplaceBankerReason = pb.KickBankerReason_KickBankerByMoney
+			}
+		}
+		if request.GetBankerNowRound() >= maxRound {
+			//坐庄回合达到最高回合次数
+			pushBanker.ReplaceBankerReason = pb.KickBankerReason_KickBankerByRound
+		} else if request.DownBankerQuest {
+			// 庄家主动申请下庄
+			pushBanker.ReplaceBankerReason = pb.KickBankerReason_KickBankerBySelf
+		}
+	}
+	// 1.2 根据庄家是否需要改变进行充填
+	// 庄家为系统或者空时也需要改变，先将庄家改变为默认系统，再根据申请庄家队列是否有人来取人
+	if pushBanker.ReplaceBankerReason != pb.KickBankerReason_KickBankerNone || request.BankerUuid == "" || request.BankerUuid == "systemBanker" {
+		if pushBanker.ReplaceBankerReason != pb.KickBankerReason_KickBankerNone && request.BankerUuid != "" {
+			tempInfo := common.GetRoomPlayerInfo(request, request.BankerUuid)
+			if tempInfo != nil {
+				tempInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateFree
+			}
+		}
+		request.BankerNowRound = 0
+		request.BankerUuid = "systemBanker"
+		request.DownBankerQuest = false
+		// 当庄家申请队列里面有人时，取队列第一个人，并将其从申请庄家队列删除
+		if len(request.Bankers) >= 1 {
+			request.BankerUuid = request.Bankers[0]
+			request.Bankers = request.Bankers[1:]
+		}
+	}
+
+	// 2.根据庄家金额计算各区域限红
+	bankerMoney, msgErr := getBankerMoney(request)
+	if msgErr != nil {
+		return request, msgErr
+	}
+	request.RedBlackAllBet = make([]int64, betAreaNum)
+	refreshMaxBetRatio(request, bankerMoney, odds)
+
+	// 3.庄家信息推送
+	pushBanker.Bankers = request.Bankers
+	pushBanker.AfterBanker = request.BankerUuid
+	pushBanker.NowRound = request.BankerNowRound
+	pushBanker.MaxBetRatio = request.MaxBetRatio
+	common.RoomBroadcast(request, pushBanker)
+
+	//下个状态
+	request.NextRoomState = pb.RoomState_RoomStateDeal
+	return request, nil
+}
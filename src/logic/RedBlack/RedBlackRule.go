@@ -0,0 +1,271 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+)
+
+// 下注区域的数量（红、黑、幸运一击）
+const betAreaNum = 3
+
+// 下注区域对应的下标
+const (
+	redIndex   = int(pb.RedBlackCardArea_RedBlackCardArea_Red) - 1
+	blackIndex = int(pb.RedBlackCardArea_RedBlackCardArea_Black) - 1
+	luckIndex  = int(pb.RedBlackCardArea_RedBlackCardArea_Luck) - 1
+)
+
+// 红黑每方的牌数
+const handPokerNum = 3
+
+// 幸运一击中对子的最小点数（9到A）
+const luckPairMinValue = 9
+
+// 血池控制时最多尝试的开牌组合数量
+const controlTryNum = 30
+
+// 幸运一击有赔率的牌型
+var luckPokerTypes = []pb.RedBlackPokerType{
+	pb.RedBlackPokerType_RedBlackCardType_Pair,
+	pb.RedBlackPokerType_RedBlackCardType_Straight,
+	pb.RedBlackPokerType_RedBlackCardType_Flush,
+	pb.RedBlackPokerType_RedBlackCardType_StraightFlush,
+	pb.RedBlackPokerType_RedBlackCardType_Trip,
+}
+
+// redBlackOdds 红黑大战的赔率配置
+type redBlackOdds struct {
+	// 红、黑区域的赔率，下标为区域-1
+	areaOdds []int64
+	// 幸运一击各牌型的赔率
+	luckOdds map[pb.RedBlackPokerType]int64
+	// 幸运一击最大的赔率，用于计算限红
+	maxLuckOdds int64
+}
+
+// redBlackResult 一局的开牌结果
+type redBlackResult struct {
+	// 红方和黑方的牌型信息
+	hands []*pb.CompareRedBlackPoker
+	// 获胜的区域（红|黑）
+	winArea pb.RedBlackCardArea
+	// 幸运一击的赔率，为0时幸运一击未中
+	luckOdds int64
+}
+
+// getRedBlackOdds 获取房间的赔率配置，幸运一击的配置名为OddsLuck加上牌型名，如OddsLuckPair
+func getRedBlackOdds(roomInfo *pb.RoomInfo) (*redBlackOdds, *pb.ErrorMessage) {
+	odds := &redBlackOdds{
+		areaOdds: make([]int64, luckIndex),
+		luckOdds: make(map[pb.RedBlackPokerType]int64),
+	}
+	oddsNames := []string{"OddsRed", "OddsBlack"}
+	for _, pokerType := range luckPokerTypes {
+		oddsNames = append(oddsNames, "OddsLuck"+strings.TrimPrefix(pokerType.String(), "RedBlackCardType_"))
+	}
+	for index, oddsName := range oddsNames {
+		oddsStr := common.GetRoomConfig(roomInfo, oddsName)
+		oddsNum, err := strconv.ParseInt(oddsStr, 10, 64)
+		if err != nil || oddsNum <= 0 {
+			common.LogError("getRedBlackOdds has err", oddsName, oddsStr, err)
+			return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		if index < luckIndex {
+			odds.areaOdds[index] = oddsNum
+			continue
+		}
+		odds.luckOdds[luckPokerTypes[index-luckIndex]] = oddsNum
+		if oddsNum > odds.maxLuckOdds {
+			odds.maxLuckOdds = oddsNum
+		}
+	}
+	return odds, nil
+}
+
+// getLuckOdds 获取赢家牌型对应的幸运一击赔率，对子需要9到A才算中奖
+// 返回值：赔率，未中奖时返回0
+func getLuckOdds(winHand *pb.CompareRedBlackPoker, odds *redBlackOdds) int64 {
+	if winHand.GetRedBlackPokerType() == pb.RedBlackPokerType_RedBlackCardType_Pair &&
+		common.GetThreePokerValue(winHand.GetTypePoker()) < luckPairMinValue {
+		return 0
+	}
+	return odds.luckOdds[winHand.GetRedBlackPokerType()]
+}
+
+// getRedBlackResult 根据六张牌计算开牌结果，前三张为红方，后三张为黑方
+func getRedBlackResult(pokers []*pb.Poker, odds *redBlackOdds) *redBlackResult {
+	redHand := common.GetThreePokerCompare(pokers[:handPokerNum])
+	blackHand := common.GetThreePokerCompare(pokers[handPokerNum : handPokerNum*2])
+	result := &redBlackResult{
+		hands:   []*pb.CompareRedBlackPoker{redHand, blackHand},
+		winArea: pb.RedBlackCardArea_RedBlackCardArea_Red,
+	}
+	winHand := redHand
+	if blackHand.GetPokerScore() > redHand.GetPokerScore() {
+		result.winArea = pb.RedBlackCardArea_RedBlackCardArea_Black
+		winHand = blackHand
+	}
+	result.luckOdds = getLuckOdds(winHand, odds)
+	return result
+}
+
+// getWinHand 获取赢家的牌型信息
+func (result *redBlackResult) getWinHand() *pb.CompareRedBlackPoker {
+	return result.hands[int(result.winArea)-1]
+}
+
+// getAreaResult 计算某个区域的下注在开牌结果下的返还金额（含本金）和盈利金额
+// 返回值：返还金额，盈利金额
+func getAreaResult(areaIndex int, bet int64, winArea pb.RedBlackCardArea, luckOdds int64, odds *redBlackOdds) (int64, int64) {
+	if bet <= 0 {
+		return 0, 0
+	}
+	if areaIndex == luckIndex {
+		if luckOdds <= 0 {
+			return 0, 0
+		}
+		winBalance := bet * luckOdds
+		return bet + winBalance, winBalance
+	}
+	if areaIndex == int(winArea)-1 {
+		winBalance := bet * odds.areaOdds[areaIndex]
+		return bet + winBalance, winBalance
+	}
+	return 0, 0
+}
+
+// getBetsNetWin 计算一组下注在开牌结果下的净输赢（未抽水）
+func getBetsNetWin(bets []int64, winArea pb.RedBlackCardArea, luckOdds int64, odds *redBlackOdds) int64 {
+	var netWin int64
+	for areaIndex, bet := range bets {
+		if bet <= 0 {
+			continue
+		}
+		backBalance, _ := getAreaResult(areaIndex, bet, winArea, luckOdds, odds)
+		netWin += backBalance - bet
+	}
+	return netWin
+}
+
+// getBankerMoney 获取当前庄家可用于赔付的金额
+func getBankerMoney(roomInfo *pb.RoomInfo) (int64, *pb.ErrorMessage) {
+	if roomInfo.GetBankerUuid() == "systemBanker" {
+		defaultMoneyStr := common.GetRoomConfig(roomInfo, "DefaultBankerMoney")
+		defaultMoney, err := strconv.ParseInt(defaultMoneyStr, 10, 64)
+		if err != nil {
+			common.LogError("getBankerMoney DefaultBankerMoney has err", defaultMoneyStr, err)
+			return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		return defaultMoney, nil
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo == nil {
+		return 0, nil
+	}
+	return bankerInfo.GetBalance(), nil
+}
+
+// refreshMaxBetRatio 根据庄家金额和房间总注刷新各区域的限红
+// 按照红或黑获胜并且幸运一击以最大赔率中奖计算庄家还能赔付的金额
+func refreshMaxBetRatio(roomInfo *pb.RoomInfo, bankerMoney int64, odds *redBlackOdds) {
+	if len(roomInfo.RedBlackAllBet) != betAreaNum {
+		roomInfo.RedBlackAllBet = make([]int64, betAreaNum)
+	}
+	roomInfo.MaxBetRatio = make([]int64, betAreaNum)
+	var maxBankerLose int64
+	for _, winArea := range []pb.RedBlackCardArea{pb.RedBlackCardArea_RedBlackCardArea_Red, pb.RedBlackCardArea_RedBlackCardArea_Black} {
+		areaIndex := int(winArea) - 1
+		bankerLose := getBetsNetWin(roomInfo.RedBlackAllBet, winArea, odds.maxLuckOdds, odds)
+		if bankerLose > maxBankerLose {
+			maxBankerLose = bankerLose
+		}
+		maxBet := (bankerMoney - bankerLose) / odds.areaOdds[areaIndex]
+		if maxBet < 0 {
+			maxBet = 0
+		}
+		roomInfo.MaxBetRatio[areaIndex] = maxBet
+	}
+	maxBet := (bankerMoney - maxBankerLose) / odds.maxLuckOdds
+	if maxBet < 0 {
+		maxBet = 0
+	}
+	roomInfo.MaxBetRatio[luckIndex] = maxBet
+}
+
+// getMinChip 获取最小的筹码值
+func getMinChip(roomInfo *pb.RoomInfo) int64 {
+	var minChip int64
+	for _, chipStr := range strings.Split(common.GetRoomConfig(roomInfo, "Chips"), ",") {
+		chip, err := strconv.ParseInt(chipStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		if minChip == 0 || chip < minChip {
+			minChip = chip
+		}
+	}
+	return minChip
+}
+
+// getSystemScore 计算开牌结果下平台的收益（真实玩家输的钱）
+func getSystemScore(roomInfo *pb.RoomInfo, result *redBlackResult, odds *redBlackOdds, bankerIsRobot bool) int64 {
+	var playerNetWin int64
+	var bankerNetWin int64
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetUuid() == roomInfo.GetBankerUuid() {
+			continue
+		}
+		netWin := getBetsNetWin(onePlayer.GetPlayerBets(), result.winArea, result.luckOdds, odds)
+		bankerNetWin -= netWin
+		if !onePlayer.GetIsRobot() {
+			playerNetWin += netWin
+		}
+	}
+	score := -playerNetWin
+	if !bankerIsRobot {
+		score -= bankerNetWin
+	}
+	return score
+}
+
+// dealByControl 根据血池状态从牌堆中发红黑各三张牌
+// 不控制时按顺序发牌，控制时随机尝试多种发牌组合，选择平台收益最高（或最低）的组合
+// 返回值：六张牌（前三张红方，后三张黑方），开牌结果
+func dealByControl(roomInfo *pb.RoomInfo, cardHeap []*pb.Poker, bloodState pb.BloodSlotStatus, odds *redBlackOdds) ([]*pb.Poker, *redBlackResult) {
+	bestPokers := cardHeap[:handPokerNum*2]
+	bestResult := getRedBlackResult(bestPokers, odds)
+	if bloodState != pb.BloodSlotStatus_BloodSlotStatus_Win && bloodState != pb.BloodSlotStatus_BloodSlotStatus_Lose {
+		return bestPokers, bestResult
+	}
+	bankerIsRobot := isBankerIsRobot(roomInfo)
+	bestScore := getSystemScore(roomInfo, bestResult, odds, bankerIsRobot)
+	for try := 1; try < controlTryNum; try++ {
+		pokers := make([]*pb.Poker, 0, handPokerNum*2)
+		for _, heapIndex := range common.GenerateRandomNumber(0, len(cardHeap), handPokerNum*2) {
+			pokers = append(pokers, cardHeap[heapIndex])
+		}
+		result := getRedBlackResult(pokers, odds)
+		score := getSystemScore(roomInfo, result, odds, bankerIsRobot)
+		if (bloodState == pb.BloodSlotStatus_BloodSlotStatus_Win && score > bestScore) ||
+			(bloodState == pb.BloodSlotStatus_BloodSlotStatus_Lose && score < bestScore) {
+			bestScore = score
+			bestPokers = pokers
+			bestResult = result
+		}
+	}
+	return bestPokers, bestResult
+}
+
+// isBankerIsRobot 判断庄家是否是机器人
+func isBankerIsRobot(roomInfo *pb.RoomInfo) bool {
+	if roomInfo.GetBankerUuid() == "systemBanker" {
+		return true
+	}
+	bankerInfo := common.GetRoomPlayerInfo(roomInfo, roomInfo.GetBankerUuid())
+	if bankerInfo == nil {
+		return true
+	}
+	return bankerInfo.GetIsRobot()
+}
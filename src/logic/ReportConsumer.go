@@ -0,0 +1,321 @@
+package logic
+
+import (
+	"database/sql"
+	"errors"
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+func init() {
+	common.AllComponentMap["ReportConsumer"] = &ReportConsumer{}
+}
+
+// ReportConsumer 报表消费组件，将MQ报表队列中的数据写入mysql分表
+// 同一批消息在一个事务中入库，消息id记录在已消费表中保证重复投递不会重复入库
+type ReportConsumer struct {
+	base.Base
+	db *sql.DB
+	// 每批消费的消息数量
+	batchSize int
+	// 已消费消息记录保留天数
+	keepDays int
+}
+
+// reportColumns 各报表表的入库字段
+var reportColumns = map[string][]string{
+	common.MysqlReportGameRecord: {
+		"room_id", "round_id", "game_type", "game_scene", "game_mode", "room_type",
+		"player_uuid", "player_shortId", "player_account", "start_time", "settle_time",
+		"before_balance", "total_bet", "win_or_lose", "settle_balance",
+		"commission", "jackpot_commission", "extend_data",
+	},
+	common.MysqlReportBalanceChangeRecord: {
+		"player_uuid", "player_shortId", "player_account", "change_time", "change_reason",
+		"before_balance", "change_amount", "final_balance",
+	},
+	common.MysqlReportBonusRecord: {
+		"player_uuid", "player_shortId", "player_change_balance", "game_type", "game_scene", "change_time",
+		"before_bonus_num", "change_bonus_num", "after_bonus_num",
+		"before_system_num", "change_system_num", "after_system_num",
+		"system_ratio", "bonus_name", "change_reason",
+	},
+}
+
+// reportRow 一条待入库的报表数据
+type reportRow struct {
+	// 原表名
+	tableName string
+	// 数据时间，用于选择分表
+	timestamp int64
+	values    []interface{}
+}
+
+// reportBatch 同一张分表的待入库数据
+type reportBatch struct {
+	tableName string
+	rows      [][]interface{}
+}
+
+// LoadComponent 加载组件
+func (obj *ReportConsumer) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+
+	dbTemp, err := sql.Open("mysql", (*obj.Config)["connect_string"])
+	if err != nil {
+		common.LogError("ReportConsumer connect mysql err", err)
+		panic(err)
+	}
+	dbTemp.SetConnMaxLifetime(600 * time.Second)
+	dbTemp.SetMaxIdleConns(0)
+	obj.db = dbTemp
+
+	obj.batchSize = 100
+	if batchSize, err := strconv.Atoi((*obj.Config)["batch_size"]); err == nil && batchSize > 0 {
+		obj.batchSize = batchSize
+	}
+	obj.keepDays = 7
+	if keepDays, err := strconv.Atoi((*obj.Config)["keep_days"]); err == nil && keepDays > 0 {
+		obj.keepDays = keepDays
+	}
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *ReportConsumer) Start() {
+	obj.Base.Start()
+	err := obj.checkTable()
+	if err != nil {
+		common.LogError("ReportConsumer Start checkTable has err", err)
+		return
+	}
+	consumerTag := common.ServerName + ":" + common.ServerIndex + ":" + obj.ComponentName
+	err = common.MQer.BindReportBatch(consumerTag, obj.batchSize, obj.consume)
+	if err != nil {
+		common.LogError("ReportConsumer Start BindReportBatch has err", err)
+		return
+	}
+	obj.clearConsumedTask()
+}
+
+// checkTable 检测表是否存在并创建，还没有分表时数据写入原表
+func (obj *ReportConsumer) checkTable() error {
+	checkSqls := []string{
+		common.MysqlCheckReportGameRecord,
+		common.MysqlCheckReportBalanceChangeRecord,
+		common.MysqlCheckReportBonusRecord,
+		common.MysqlCheckReportConsumedMessage,
+		common.MysqlCheckReportDeadLetter,
+	}
+	for _, checkSql := range checkSqls {
+		_, err := obj.db.Exec(checkSql)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearConsumedTask 定时清理过期的已消费消息记录
+func (obj *ReportConsumer) clearConsumedTask() {
+	common.StartTimer(time.Hour, false, func() bool {
+		expireTime := time.Now().Unix() - int64(obj.keepDays)*24*3600
+		_, err := obj.db.Exec("DELETE FROM "+common.MysqlReportConsumedMessage+" WHERE consumed_time < ?", expireTime)
+		if err != nil {
+			common.LogError("ReportConsumer clearConsumedTask has err", err)
+		}
+		return true
+	})
+}
+
+// consume 消费一批报表消息，返回错误时整批重新入队
+func (obj *ReportConsumer) consume(msgList []*common.ReportMsgDelivery) error {
+	now := time.Now().Unix()
+	tx, err := obj.db.Begin()
+	if err != nil {
+		common.LogError("ReportConsumer consume Begin has err", err)
+		return err
+	}
+	// 分表名缓存 原表名:时间 -> 分表名
+	splitTableNames := make(map[string]string)
+	batchMap := make(map[string]*reportBatch)
+	for _, msg := range msgList {
+		messageID := msg.MessageId
+		if messageID == "" {
+			messageID = common.Md5(string(msg.Body))
+		}
+		result, err := tx.Exec("INSERT IGNORE INTO "+common.MysqlReportConsumedMessage+" (message_id,consumed_time) VALUES (?,?)", messageID, now)
+		if err != nil {
+			common.LogError("ReportConsumer consume insert consumed message has err", messageID, err)
+			tx.Rollback()
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			common.LogError("ReportConsumer consume RowsAffected has err", messageID, err)
+			tx.Rollback()
+			return err
+		}
+		// 已经消费过的消息
+		if affected == 0 {
+			continue
+		}
+
+		row, reportType, err := decodeReport(msg.Body)
+		if err != nil {
+			// 无法解析的消息写入死信表，不再重新入队
+			common.LogError("ReportConsumer consume decodeReport has err", messageID, reportType, err)
+			_, err = tx.Exec(
+				"INSERT INTO "+common.MysqlReportDeadLetter+" (message_id,report_type,reason,content,created_time) VALUES (?,?,?,?,?)",
+				messageID, int32(reportType), err.Error(), msg.Body, now)
+			if err != nil {
+				common.LogError("ReportConsumer consume insert dead letter has err", messageID, err)
+				tx.Rollback()
+				return err
+			}
+			continue
+		}
+
+		cacheKey := row.tableName + ":" + strconv.FormatInt(row.timestamp, 10)
+		splitName, ok := splitTableNames[cacheKey]
+		if !ok {
+			splitName, err = obj.getSplitTableName(row.tableName, row.timestamp)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			splitTableNames[cacheKey] = splitName
+		}
+		batch, ok := batchMap[splitName]
+		if !ok {
+			batch = &reportBatch{tableName: row.tableName}
+			batchMap[splitName] = batch
+		}
+		batch.rows = append(batch.rows, row.values)
+	}
+
+	for splitName, batch := range batchMap {
+		columns := reportColumns[batch.tableName]
+		placeholder := "(" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ")"
+		placeholders := make([]string, 0, len(batch.rows))
+		args := make([]interface{}, 0, len(batch.rows)*len(columns))
+		for _, values := range batch.rows {
+			placeholders = append(placeholders, placeholder)
+			args = append(args, values...)
+		}
+		_, err = tx.Exec("INSERT INTO "+splitName+" ("+strings.Join(columns, ",")+") VALUES "+strings.Join(placeholders, ","), args...)
+		if err != nil {
+			common.LogError("ReportConsumer consume insert report has err", splitName, len(batch.rows), err)
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		common.LogError("ReportConsumer consume Commit has err", err)
+		return err
+	}
+	return nil
+}
+
+// getSplitTableName 获取某个时间的数据所在的分表，还没有分表时返回原表
+func (obj *ReportConsumer) getSplitTableName(tableName string, timestamp int64) (string, error) {
+	request := &pb.GetSplitTablesRequest{}
+	request.TableName = tableName
+	request.StartTimestamp = timestamp
+	request.EndTimestamp = timestamp
+	reply := &pb.GetSplitTablesReply{}
+	msgErr := common.Router.Call("SplitTable", "GetSplitTableNames", request, reply, &pb.MessageExtroInfo{})
+	if msgErr != nil {
+		common.LogError("ReportConsumer getSplitTableName GetSplitTableNames has err", tableName, timestamp, msgErr)
+		return "", errors.New("get split table names err")
+	}
+	tableNames := reply.GetTableNames()
+	if len(tableNames) <= 0 {
+		return tableName, nil
+	}
+	return tableNames[len(tableNames)-1], nil
+}
+
+// decodeReport 解析报表消息为待入库数据
+func decodeReport(body []byte) (*reportRow, pb.ReportType, error) {
+	reportMsg := &pb.ReportMessage{}
+	err := proto.Unmarshal(body, reportMsg)
+	if err != nil {
+		return nil, pb.ReportType_ReportType_None, err
+	}
+	reportType := reportMsg.GetReportType()
+	if reportMsg.GetReportContent() == nil {
+		return nil, reportType, errors.New("report content is nil")
+	}
+
+	switch reportType {
+	case pb.ReportType_ReportType_GameRecord:
+		record := &pb.GameRecordReport{}
+		err = ptypes.UnmarshalAny(reportMsg.GetReportContent(), record)
+		if err != nil {
+			return nil, reportType, err
+		}
+		extendData := record.GetExtendData()
+		if extendData == nil {
+			extendData = &pb.GameRecordExtendData{}
+		}
+		extendByte, err := proto.Marshal(extendData)
+		if err != nil {
+			return nil, reportType, err
+		}
+		return &reportRow{
+			tableName: common.MysqlReportGameRecord,
+			timestamp: record.GetSettleTime(),
+			values: []interface{}{
+				record.GetRoomId(), record.GetRoundId(), int32(record.GetGameType()), record.GetGameScene(),
+				int32(record.GetGameMode()), int32(record.GetRoomType()),
+				record.GetPlayerUuid(), record.GetPlayerShortId(), record.GetPlayerAccount(),
+				record.GetStartTime(), record.GetSettleTime(),
+				record.GetBeforeBalance(), record.GetTotalBet(), record.GetWinOrLose(), record.GetSettleBalance(),
+				record.GetCommission(), record.GetJackpotCommission(), extendByte,
+			},
+		}, reportType, nil
+	case pb.ReportType_ReportType_BalanceChangeRecord:
+		record := &pb.BalanceChangeRecordReport{}
+		err = ptypes.UnmarshalAny(reportMsg.GetReportContent(), record)
+		if err != nil {
+			return nil, reportType, err
+		}
+		return &reportRow{
+			tableName: common.MysqlReportBalanceChangeRecord,
+			timestamp: record.GetChangeTime(),
+			values: []interface{}{
+				record.GetPlayerUuid(), record.GetPlayerShortId(), record.GetPlayerAccount(),
+				record.GetChangeTime(), int32(record.GetChangeReason()),
+				record.GetBeforeBalance(), record.GetChangeAmount(), record.GetFinalBalance(),
+			},
+		}, reportType, nil
+	case pb.ReportType_ReportType_BonusRecordReport:
+		record := &pb.BonusRecordReport{}
+		err = ptypes.UnmarshalAny(reportMsg.GetReportContent(), record)
+		if err != nil {
+			return nil, reportType, err
+		}
+		return &reportRow{
+			tableName: common.MysqlReportBonusRecord,
+			timestamp: record.GetChangeTime(),
+			values: []interface{}{
+				record.GetUuid(), record.GetShortId(), record.GetBonusReward(),
+				int32(record.GetGameType()), record.GetGameScene(), record.GetChangeTime(),
+				record.GetBeforeBonusChangeNum(), record.GetBonusChangeNum(), record.GetAfterBonusChangeNum(),
+				record.GetBeforeSystemBonus(), record.GetChangeSystemBonus(), record.GetAfterSystemBonus(),
+				record.GetSystemRatio(), record.GetBonusName(), int32(record.GetChangeReason()),
+			},
+		}, reportType, nil
+	}
+	return nil, reportType, errors.New("unsupported report type")
+}
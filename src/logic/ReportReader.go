@@ -0,0 +1,394 @@
+package logic
+
+import (
+	"database/sql"
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func init() {
+	common.AllComponentMap["ReportReader"] = &ReportReader{}
+}
+
+// ReportReader 报表查询组件，按时间范围跨分表分页查询报表数据
+// 分表按时间切分互不重叠，从新到旧依次查询各分表即可得到按时间倒序的分页结果
+type ReportReader struct {
+	base.Base
+	db *sql.DB
+}
+
+// reportPageQuery 报表分页查询条件
+type reportPageQuery struct {
+	// 原表名
+	tableName string
+	// 时间字段
+	timeColumn string
+	// 查询字段
+	columns   string
+	where     []string
+	args      []interface{}
+	start     int64
+	end       int64
+	pageIndex int32
+	pageSize  int32
+}
+
+// addWhere 增加查询条件
+func (query *reportPageQuery) addWhere(where string, args ...interface{}) {
+	query.where = append(query.where, where)
+	query.args = append(query.args, args...)
+}
+
+// LoadComponent 加载组件
+func (obj *ReportReader) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+
+	dbTemp, err := sql.Open("mysql", (*obj.Config)["connect_string"])
+	if err != nil {
+		common.LogError("ReportReader connect mysql err", err)
+		panic(err)
+	}
+	dbTemp.SetConnMaxLifetime(600 * time.Second)
+	dbTemp.SetMaxIdleConns(0)
+	obj.db = dbTemp
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *ReportReader) Start() {
+	obj.Base.Start()
+	obj.checkTable()
+}
+
+// checkTable 检测原表是否存在并创建，还没有分表时的数据在原表中
+func (obj *ReportReader) checkTable() {
+	checkSqls := []string{
+		common.MysqlCheckReportGameRecord,
+		common.MysqlCheckReportBalanceChangeRecord,
+		common.MysqlCheckReportBonusRecord,
+	}
+	for _, checkSql := range checkSqls {
+		_, err := obj.db.Exec(checkSql)
+		if err != nil {
+			common.LogError("ReportReader checkTable has err", err)
+		}
+	}
+}
+
+// getTableNames 获取时间范围内的表，从新到旧排列，原表放在最后
+func (obj *ReportReader) getTableNames(tableName string, start int64, end int64) ([]string, *pb.ErrorMessage) {
+	request := &pb.GetSplitTablesRequest{}
+	request.TableName = tableName
+	request.StartTimestamp = start
+	request.EndTimestamp = end
+	reply := &pb.GetSplitTablesReply{}
+	msgErr := common.Router.Call("SplitTable", "GetSplitTableNames", request, reply, &pb.MessageExtroInfo{})
+	if msgErr != nil {
+		common.LogError("ReportReader getTableNames GetSplitTableNames has err", tableName, msgErr)
+		return nil, msgErr
+	}
+	splitNames := reply.GetTableNames()
+	tableNames := make([]string, 0, len(splitNames)+1)
+	for i := len(splitNames) - 1; i >= 0; i-- {
+		if splitNames[i] != tableName {
+			tableNames = append(tableNames, splitNames[i])
+		}
+	}
+	tableNames = append(tableNames, tableName)
+	return tableNames, nil
+}
+
+// queryPageList 跨分表分页查询，scanFunc处理每一行数据
+// 返回：总记录数，错误信息
+func (obj *ReportReader) queryPageList(query *reportPageQuery, scanFunc func(rows *sql.Rows) error) (int32, *pb.ErrorMessage) {
+	if query.pageIndex <= 0 || query.pageSize <= 0 {
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ErrorDataFormat, "")
+	}
+	if query.end <= 0 {
+		query.end = time.Now().Unix()
+	}
+	tableNames, msgErr := obj.getTableNames(query.tableName, query.start, query.end)
+	if msgErr != nil {
+		return 0, msgErr
+	}
+
+	where := append([]string{query.timeColumn + ">=?", query.timeColumn + "<=?"}, query.where...)
+	args := append([]interface{}{query.start, query.end}, query.args...)
+	whereSQL := " where " + strings.Join(where, " and ")
+
+	// 统计每张表的记录数
+	var recordCount int32
+	tableCounts := make([]int32, len(tableNames))
+	for i, tableName := range tableNames {
+		err := obj.db.QueryRow("select count(1) cnt from "+tableName+whereSQL, args...).Scan(&tableCounts[i])
+		if err != nil {
+			common.LogError("ReportReader queryPageList count has err", tableName, err)
+			return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		recordCount += tableCounts[i]
+	}
+
+	// 跳过前面页的记录，从所在的表开始取够一页
+	offset := query.pageSize * (query.pageIndex - 1)
+	need := query.pageSize
+	for i, tableName := range tableNames {
+		if need <= 0 {
+			break
+		}
+		if offset >= tableCounts[i] {
+			offset -= tableCounts[i]
+			continue
+		}
+		queryListSQL := "select " + query.columns + " from " + tableName + whereSQL +
+			" order by " + query.timeColumn + " desc,auto_id desc limit " +
+			strconv.FormatInt(int64(offset), 10) + "," + strconv.FormatInt(int64(need), 10)
+		rows, err := obj.db.Query(queryListSQL, args...)
+		if err != nil {
+			common.LogError("ReportReader queryPageList has err", tableName, err)
+			return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+		}
+		for rows.Next() {
+			err = scanFunc(rows)
+			if err != nil {
+				common.LogError("ReportReader queryPageList scan has err", tableName, err)
+				continue
+			}
+			need--
+		}
+		rows.Close()
+		offset = 0
+	}
+	return recordCount, nil
+}
+
+// gameRecordQuery 游戏记录查询条件
+func gameRecordQuery(request *pb.GetGameRecordPageListRequest) *reportPageQuery {
+	query := &reportPageQuery{
+		tableName:  common.MysqlReportGameRecord,
+		timeColumn: "settle_time",
+		columns: "auto_id,room_id,round_id,game_type,game_scene,game_mode,room_type,player_uuid,player_shortId,player_account," +
+			"start_time,settle_time,before_balance,total_bet,win_or_lose,settle_balance,commission,jackpot_commission,extend_data",
+		start:     request.Start,
+		end:       request.End,
+		pageIndex: request.PageIndex,
+		pageSize:  request.PageSize,
+	}
+	if request.Account != "" {
+		query.addWhere("player_account=?", request.Account)
+	}
+	if request.ShortId != "" {
+		query.addWhere("player_shortId=?", request.ShortId)
+	}
+	if request.GameType != pb.GameType_None {
+		query.addWhere("game_type=?", int32(request.GameType))
+	}
+	if request.GameMode != pb.GameMode_GameMode_None {
+		query.addWhere("game_mode=?", int32(request.GameMode))
+	}
+	if request.RoomType != pb.RoomType_RoomType_None {
+		query.addWhere("room_type=?", int32(request.RoomType))
+	}
+	return query
+}
+
+// getGameRecordPageList 查询游戏记录
+func (obj *ReportReader) getGameRecordPageList(request *pb.GetGameRecordPageListRequest, query *reportPageQuery) (*pb.GetGameRecordPageListReply, *pb.ErrorMessage) {
+	reply := &pb.GetGameRecordPageListReply{}
+	reply.PageIndex = request.PageIndex
+	reply.PageSize = request.PageSize
+	reply.GameType = request.GameType
+	reply.GameMode = request.GameMode
+	reply.RoomType = request.RoomType
+	reply.Data = make([]*pb.GameRecordReport, 0)
+	recordCount, msgErr := obj.queryPageList(query, func(rows *sql.Rows) error {
+		out := &pb.GameRecordReport{}
+		extendByte := make([]byte, 0)
+		err := rows.Scan(
+			&out.AutoId,
+			&out.RoomId,
+			&out.RoundId,
+			&out.GameType,
+			&out.GameScene,
+			&out.GameMode,
+			&out.RoomType,
+			&out.PlayerUuid,
+			&out.PlayerShortId,
+			&out.PlayerAccount,
+			&out.StartTime,
+			&out.SettleTime,
+			&out.BeforeBalance,
+			&out.TotalBet,
+			&out.WinOrLose,
+			&out.SettleBalance,
+			&out.Commission,
+			&out.JackpotCommission,
+			&extendByte)
+		if err != nil {
+			return err
+		}
+		out.ExtendData = &pb.GameRecordExtendData{}
+		err = proto.Unmarshal(extendByte, out.ExtendData)
+		if err != nil {
+			return err
+		}
+		reply.Data = append(reply.Data, out)
+		return nil
+	})
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	reply.RecordCount = recordCount
+	return reply, nil
+}
+
+// GetGameRecordPageList 分页查询游戏记录
+func (obj *ReportReader) GetGameRecordPageList(request *pb.GetGameRecordPageListRequest, extroInfo *pb.MessageExtroInfo) (*pb.GetGameRecordPageListReply, *pb.ErrorMessage) {
+	return obj.getGameRecordPageList(request, gameRecordQuery(request))
+}
+
+// GetSelfGameRecordPageList 分页查询自己的游戏记录
+func (obj *ReportReader) GetSelfGameRecordPageList(request *pb.GetGameRecordPageListRequest, extroInfo *pb.MessageExtroInfo) (*pb.GetGameRecordPageListReply, *pb.ErrorMessage) {
+	query := gameRecordQuery(request)
+	query.addWhere("player_uuid=?", extroInfo.GetUserId())
+	return obj.getGameRecordPageList(request, query)
+}
+
+// balanceChangeRecordQuery 金额变动记录查询条件
+func balanceChangeRecordQuery(request *pb.GetBalanceChangeRecordPageListRequest) *reportPageQuery {
+	query := &reportPageQuery{
+		tableName:  common.MysqlReportBalanceChangeRecord,
+		timeColumn: "change_time",
+		columns:    "auto_id,player_uuid,player_shortId,player_account,change_time,change_reason,before_balance,change_amount,final_balance",
+		start:      request.Start,
+		end:        request.End,
+		pageIndex:  request.PageIndex,
+		pageSize:   request.PageSize,
+	}
+	if request.Account != "" {
+		query.addWhere("player_account=?", request.Account)
+	}
+	if request.ShortId != "" {
+		query.addWhere("player_shortId=?", request.ShortId)
+	}
+	if request.ChangeReason != pb.ResourceChangeReason_ReasonNone {
+		query.addWhere("change_reason=?", int32(request.ChangeReason))
+	}
+	if len(request.Reasons) > 0 {
+		reasonArgs := make([]interface{}, 0, len(request.Reasons))
+		for _, reason := range request.Reasons {
+			reasonArgs = append(reasonArgs, int32(reason))
+		}
+		query.addWhere("change_reason in ("+strings.TrimSuffix(strings.Repeat("?,", len(reasonArgs)), ",")+")", reasonArgs...)
+	}
+	return query
+}
+
+// getBalanceChangeRecordPageList 查询金额变动记录
+func (obj *ReportReader) getBalanceChangeRecordPageList(request *pb.GetBalanceChangeRecordPageListRequest, query *reportPageQuery) (*pb.GetBalanceChangeRecordPageListReply, *pb.ErrorMessage) {
+	reply := &pb.GetBalanceChangeRecordPageListReply{}
+	reply.PageIndex = request.PageIndex
+	reply.PageSize = request.PageSize
+	reply.Data = make([]*pb.BalanceChangeRecordReport, 0)
+	recordCount, msgErr := obj.queryPageList(query, func(rows *sql.Rows) error {
+		out := &pb.BalanceChangeRecordReport{}
+		err := rows.Scan(
+			&out.AutoId,
+			&out.PlayerUuid,
+			&out.PlayerShortId,
+			&out.PlayerAccount,
+			&out.ChangeTime,
+			&out.ChangeReason,
+			&out.BeforeBalance,
+			&out.ChangeAmount,
+			&out.FinalBalance)
+		if err != nil {
+			return err
+		}
+		reply.Data = append(reply.Data, out)
+		return nil
+	})
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	reply.RecordCount = recordCount
+	return reply, nil
+}
+
+// GetBalanceChangeRecordPageList 分页查询金额变动记录
+func (obj *ReportReader) GetBalanceChangeRecordPageList(request *pb.GetBalanceChangeRecordPageListRequest, extroInfo *pb.MessageExtroInfo) (*pb.GetBalanceChangeRecordPageListReply, *pb.ErrorMessage) {
+	return obj.getBalanceChangeRecordPageList(request, balanceChangeRecordQuery(request))
+}
+
+// GetSelfBalanceChangeRecordPageList 分页查询自己的金额变动记录
+func (obj *ReportReader) GetSelfBalanceChangeRecordPageList(request *pb.GetBalanceChangeRecordPageListRequest, extroInfo *pb.MessageExtroInfo) (*pb.GetBalanceChangeRecordPageListReply, *pb.ErrorMessage) {
+	query := balanceChangeRecordQuery(request)
+	query.addWhere("player_uuid=?", extroInfo.GetUserId())
+	return obj.getBalanceChangeRecordPageList(request, query)
+}
+
+// GetBonusRecordPageList 分页查询奖池变动记录
+func (obj *ReportReader) GetBonusRecordPageList(request *pb.GetBonusChangeRecordPageListRequest, extroInfo *pb.MessageExtroInfo) (*pb.GetBonusChangeRecordPageListReply, *pb.ErrorMessage) {
+	reply := &pb.GetBonusChangeRecordPageListReply{}
+	reply.PageIndex = request.PageIndex
+	reply.PageSize = request.PageSize
+	reply.Data = make([]*pb.BonusRecordReport, 0)
+	query := &reportPageQuery{
+		tableName:  common.MysqlReportBonusRecord,
+		timeColumn: "change_time",
+		columns: "auto_id,player_uuid,player_shortId,player_change_balance,game_type,game_scene,change_time," +
+			"before_bonus_num,change_bonus_num,after_bonus_num,before_system_num,change_system_num,after_system_num," +
+			"system_ratio,bonus_name,change_reason",
+		start:     request.Start,
+		end:       request.End,
+		pageIndex: request.PageIndex,
+		pageSize:  request.PageSize,
+	}
+	if request.ShortId != "" {
+		query.addWhere("player_shortId=?", request.ShortId)
+	}
+	if request.PlayerUUID != "" {
+		query.addWhere("player_uuid=?", request.PlayerUUID)
+	}
+	if request.GameType != pb.GameType_None {
+		query.addWhere("game_type=?", int32(request.GameType))
+	}
+	if request.GameScene != 0 {
+		query.addWhere("game_scene=?", request.GameScene)
+	}
+	recordCount, msgErr := obj.queryPageList(query, func(rows *sql.Rows) error {
+		out := &pb.BonusRecordReport{}
+		err := rows.Scan(
+			&out.AutoId,
+			&out.Uuid,
+			&out.ShortId,
+			&out.BonusReward,
+			&out.GameType,
+			&out.GameScene,
+			&out.ChangeTime,
+			&out.BeforeBonusChangeNum,
+			&out.BonusChangeNum,
+			&out.AfterBonusChangeNum,
+			&out.BeforeSystemBonus,
+			&out.ChangeSystemBonus,
+			&out.AfterSystemBonus,
+			&out.SystemRatio,
+			&out.BonusName,
+			&out.ChangeReason)
+		if err != nil {
+			return err
+		}
+		reply.Data = append(reply.Data, out)
+		return nil
+	})
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	reply.RecordCount = recordCount
+	return reply, nil
+}
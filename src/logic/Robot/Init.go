@@ -1,6 +1,15 @@
 package logic
 
+import (
+	"gameServer-demo/src/common"
+)
+
 // Init 用于方便包被外部引用的函数，同时在这里引用子包
 func Init() {
-
+	// 通过common.RegisterGamePlugin注册的游戏的机器人行为
+	for _, plugin := range common.GamePluginMap {
+		for robotAction, oneAction := range plugin.RobotActions {
+			ActionList[robotAction] = oneAction
+		}
+	}
 }
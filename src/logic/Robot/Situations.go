@@ -19,6 +19,44 @@ func init() {
 	ActionList[pb.RobotAction_RobotAction_PushBobbin_Play] = &action.PushBobbinPlay{}
 	ActionList[pb.RobotAction_RobotAction_PushBobbinBank_Play] = &action.PushBobbinBankPlay{}
 	ActionList[pb.RobotAction_RobotAction_PushBobbin_ExitRoom] = &action.PushBobbinExitRoom{}
+	// 龙虎斗
+	ActionList[pb.RobotAction_RobotAction_DragonTiger_JoinRoom] = &action.DragonTigerJoinRoom{}
+	ActionList[pb.RobotAction_RobotAction_DragonTiger_Play] = &action.DragonTigerPlay{}
+	ActionList[pb.RobotAction_RobotAction_DragonTigerBank_Play] = &action.DragonTigerBankPlay{}
+	ActionList[pb.RobotAction_RobotAction_DragonTiger_ExitRoom] = &action.DragonTigerExitRoom{}
+	// 百家乐
+	ActionList[pb.RobotAction_RobotAction_Baccarat_JoinRoom] = &action.BaccaratJoinRoom{}
+	ActionList[pb.RobotAction_RobotAction_Baccarat_Play] = &action.BaccaratPlay{}
+	ActionList[pb.RobotAction_RobotAction_BaccaratBank_Play] = &action.BaccaratBankPlay{}
+	ActionList[pb.RobotAction_RobotAction_Baccarat_ExitRoom] = &action.BaccaratExitRoom{}
+	// 百人牛牛
+	ActionList[pb.RobotAction_RobotAction_HundredBull_JoinRoom] = &action.HundredBullJoinRoom{}
+	ActionList[pb.RobotAction_RobotAction_HundredBull_Play] = &action.HundredBullPlay{}
+	ActionList[pb.RobotAction_RobotAction_HundredBullBank_Play] = &action.HundredBullBankPlay{}
+	ActionList[pb.RobotAction_RobotAction_HundredBull_ExitRoom] = &action.HundredBullExitRoom{}
+	// 红黑大战
+	ActionList[pb.RobotAction_RobotAction_RedBlack_JoinRoom] = &action.RedBlackJoinRoom{}
+	ActionList[pb.RobotAction_RobotAction_RedBlack_Play] = &action.RedBlackPlay{}
+	ActionList[pb.RobotAction_RobotAction_RedBlackBank_Play] = &action.RedBlackBankPlay{}
+	ActionList[pb.RobotAction_RobotAction_RedBlack_ExitRoom] = &action.RedBlackExitRoom{}
+	// 宝石战争
+	ActionList[common.RobotActionGemWarsJoinRoom] = &action.GemWarsJoinRoom{}
+	ActionList[common.RobotActionGemWarsPlay] = &action.GemWarsPlay{}
+	ActionList[common.RobotActionGemWarsExitRoom] = &action.GemWarsExitRoom{}
+	// 拼牛牛
+	ActionList[pb.RobotAction_RobotAction_CompareBull_JoinRoom] = &action.CompareBullJoinRoom{}
+	ActionList[pb.RobotAction_RobotAction_CompareBull_Play] = &action.CompareBullPlay{}
+	ActionList[pb.RobotAction_RobotAction_CompareBull_ExitRoom] = &action.CompareBullExitRoom{}
+	// 疯狂牛牛
+	ActionList[pb.RobotAction_RobotAction_CrazyBull_JoinRoom] = &action.CrazyBullJoinRoom{}
+	ActionList[pb.RobotAction_RobotAction_CrazyBull_Play] = &action.CrazyBullPlay{}
+	ActionList[pb.RobotAction_RobotAction_CrazyBull_ExitRoom] = &action.CrazyBullExitRoom{}
+	ActionList[pb.RobotAction_RobotAction_Jinhua_JoinRoom] = &action.JinhuaJoinRoom{}
+	ActionList[pb.RobotAction_RobotAction_Jinhua_Play] = &action.JinhuaPlay{}
+	ActionList[pb.RobotAction_RobotAction_Jinhua_ExitRoom] = &action.JinhuaExitRoom{}
+	ActionList[pb.RobotAction_RobotAction_LinkUp_JoinRoom] = &action.LinkUpJoinRoom{}
+	ActionList[pb.RobotAction_RobotAction_LinkUp_Play] = &action.LinkUpPlay{}
+	ActionList[pb.RobotAction_RobotAction_LinkUp_ExitRoom] = &action.LinkUpExitRoom{}
 }
 
 // InitRobotConfigByOpenAction 通开放的行为初始化配置
@@ -40,6 +78,93 @@ func InitRobotConfigByOpenAction(oneOpenAction pb.RobotAction) {
 		})
 		_ = common.InitRobotActionGroupConfigTemp([]string{"default-push-bobbin-robot"})
 		_ = common.InitRobotActionGroupConfigTemp([]string{"default-push-bobbin-bank-robot"})
+	// 龙虎斗
+	case pb.RobotAction_RobotAction_DragonTiger_JoinRoom:
+		_ = common.InitRobotActionConfigTemp([]string{
+			"default-dragontiger-joinRoom",
+			"default-dragontiger-exitRoom",
+			"default-dragontiger-play",
+			"default-dragontigerBank-play",
+		})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-dragon-tiger-robot"})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-dragon-tiger-bank-robot"})
+	// 百家乐
+	case pb.RobotAction_RobotAction_Baccarat_JoinRoom:
+		_ = common.InitRobotActionConfigTemp([]string{
+			"default-baccarat-joinRoom",
+			"default-baccarat-exitRoom",
+			"default-baccarat-play",
+			"default-baccaratBank-play",
+		})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-baccarat-robot"})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-baccarat-bank-robot"})
+	// 百人牛牛
+	case pb.RobotAction_RobotAction_HundredBull_JoinRoom:
+		_ = common.InitRobotActionConfigTemp([]string{
+			"default-hundredbull-joinRoom",
+			"default-hundredbull-exitRoom",
+			"default-hundredbull-play",
+			"default-hundredbullBank-play",
+		})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-hundred-bull-robot"})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-hundred-bull-bank-robot"})
+	// 红黑大战
+	case pb.RobotAction_RobotAction_RedBlack_JoinRoom:
+		_ = common.InitRobotActionConfigTemp([]string{
+			"default-redblack-joinRoom",
+			"default-redblack-exitRoom",
+			"default-redblack-play",
+			"default-redblackBank-play",
+		})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-red-black-robot"})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-red-black-bank-robot"})
+	// 宝石战争
+	case common.RobotActionGemWarsJoinRoom:
+		_ = common.InitRobotActionConfigTemp([]string{
+			"default-gemwars-joinRoom",
+			"default-gemwars-exitRoom",
+			"default-gemwars-play",
+		})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-gem-wars-robot"})
+	// 拼牛牛
+	case pb.RobotAction_RobotAction_CompareBull_JoinRoom:
+		_ = common.InitRobotActionConfigTemp([]string{
+			"default-comparebull-joinRoom",
+			"default-comparebull-exitRoom",
+			"default-comparebull-play",
+		})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-compare-bull-robot"})
+	// 疯狂牛牛
+	case pb.RobotAction_RobotAction_CrazyBull_JoinRoom:
+		_ = common.InitRobotActionConfigTemp([]string{
+			"default-crazybull-joinRoom",
+			"default-crazybull-exitRoom",
+			"default-crazybull-play",
+		})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-crazy-bull-robot"})
+	// 炸金花
+	case pb.RobotAction_RobotAction_Jinhua_JoinRoom:
+		_ = common.InitRobotActionConfigTemp([]string{
+			"default-jinhua-joinRoom",
+			"default-jinhua-exitRoom",
+			"default-jinhua-play",
+		})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-jinhua-robot"})
+	// 连连看
+	case pb.RobotAction_RobotAction_LinkUp_JoinRoom:
+		_ = common.InitRobotActionConfigTemp([]string{
+			"default-linkup-joinRoom",
+			"default-linkup-exitRoom",
+			"default-linkup-play",
+		})
+		_ = common.InitRobotActionGroupConfigTemp([]string{"default-linkup-robot"})
+	// 通过注册描述接入的游戏
+	default:
+		plugin := common.GetGamePluginByRobotJoinAction(oneOpenAction)
+		if plugin != nil {
+			_ = common.InitRobotActionConfigTemp(plugin.RobotActionConfigNames)
+			_ = common.InitRobotActionGroupConfigTemp(plugin.RobotActionGroupConfigNames)
+		}
 	}
 
 }
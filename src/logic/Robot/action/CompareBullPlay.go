@@ -0,0 +1,121 @@
+package action
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+func init() {
+}
+
+// CompareBullPlay 拼牛牛机器人玩耍行为
+type CompareBullPlay struct {
+}
+
+// Action 触发行为
+//
+// 传入参数是机器人信息playerInfo可修改，且修改的信息在外部会保存
+// roomInfo都是只读的，修改是无用的
+// playerInfo也是只读的，修改需要自己保存一次，底层已对player加锁
+//
+// 返回值依次是：
+//
+// 行为是否结束，这里的行为结束意味着整个完成了，可以进行下一个行为了，而不是单次行为有没有完成
+// 比如下注在玩耍行为下会有多次，而不是一次，如果传结束了，则整个玩耍行为会结束
+//
+// 行为是否出错，同一行为出错的话外部会进行累积，如果累积到一定数字，则认为机器人卡死，会在底层作出处理
+//
+// 下次行为在多少秒之后，用于顶层tick
+func (o *CompareBullPlay) Action(playerInfo *pb.PlayerInfo, roomInfo *pb.RoomInfo, actionConfig *pb.RobotActionConfig, extraInfo *pb.MessageExtroInfo) (bool, bool, int64) {
+	// 当玩家房间信息为空时，结束当前行为
+	if roomInfo == nil {
+		return true, false, 5
+	}
+
+	roomPlayerInfo := common.GetRoomPlayerInfo(roomInfo, playerInfo.GetUuid())
+	if roomPlayerInfo == nil {
+		common.LogError("CompareBullPlay Action player not in room", playerInfo.GetUuid())
+		return true, false, 5
+	}
+
+	// 游戏中只需要开牌
+	if roomPlayerInfo.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+		if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || len(roomPlayerInfo.GetOutPokers()) != 0 {
+			return false, false, int64(common.GetRandomNum(1, 2))
+		}
+		// 随缘思考一会再开牌
+		if int64(common.GetRandomNum(1, 3)) == 1 {
+			return false, false, 1
+		}
+		// 开牌的牌为空时按照发的手牌开牌
+		msgErr := o.callDo(pb.CompareBullDoType_CompareBullDo_OpenCard, &pb.GameOpenCardRequest{}, &pb.GameOpenCardReply{}, extraInfo)
+		if msgErr != nil {
+			common.LogError("CompareBullPlay Action open card call do err", msgErr)
+			return false, true, 5
+		}
+		return false, false, int64(common.GetRandomNum(1, 2))
+	}
+
+	// 当机器人没得什么钱了，就退出去充钱
+	if roomPlayerInfo.Balance < actionConfig.MinBalance {
+		return true, false, int64(common.GetRandomNum(1, 3))
+	}
+
+	// 如果被标记为下岗，则结束行为
+	if playerInfo.GetRobotExtroInfo().GetIsLaidOff() == true {
+		return true, false, 1
+	}
+
+	// 不是准备阶段或者已经准备了，随缘加载
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateReady ||
+		roomInfo.GetNextRoomState() == pb.RoomState_RoomStateReady ||
+		roomPlayerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStateFree {
+		return false, false, int64(common.GetRandomNum(1, 2))
+	}
+
+	// 如果大于了最大玩耍局数，则结束行为
+	if roomPlayerInfo.GetPlayNum() >= actionConfig.GetMaxPlayNum() {
+		return true, false, 1
+	}
+
+	// 如果介于最大和最小局数之间，则随机结束行为
+	if roomPlayerInfo.GetPlayNum() >= actionConfig.GetMinPlayNum() &&
+		roomPlayerInfo.GetPlayNum() < actionConfig.GetMaxPlayNum() {
+		randomNum := common.GetRandomNum(1, 100)
+		if int32(randomNum) <= actionConfig.GetPlayEndPre() {
+			return true, false, 1
+		}
+	}
+
+	// 随缘延迟
+	if int64(common.GetRandomNum(1, 3)) == 1 {
+		return false, false, 1
+	}
+
+	// 准备
+	changeStateRequest := &pb.GameChangeStateRequest{
+		WantState: pb.PlayerRoomState_PlayerRoomStateReady,
+	}
+	msgErr := o.callDo(pb.CompareBullDoType_CompareBullDo_ChangeState, changeStateRequest, &pb.GameChangeStateReply{}, extraInfo)
+	if msgErr != nil {
+		common.LogError("CompareBullPlay Action ready call do err", msgErr)
+		return false, true, 5
+	}
+	return false, false, int64(common.GetRandomNum(1, 3))
+}
+
+// callDo 封装拼牛牛的操作请求并调用路由
+func (o *CompareBullPlay) callDo(doType pb.CompareBullDoType, realRequest proto.Message, realReply proto.Message, extraInfo *pb.MessageExtroInfo) *pb.ErrorMessage {
+	compareBullDoContent, err := ptypes.MarshalAny(realRequest)
+	if err != nil {
+		common.LogError("CompareBullPlay callDo MarshalAny err", err)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	request := &pb.CompareBullDoRequest{
+		DoType:           doType,
+		DoMessageContent: compareBullDoContent,
+	}
+	return common.Router.Call("CompareBullRoute", "Do", request, realReply, extraInfo)
+}
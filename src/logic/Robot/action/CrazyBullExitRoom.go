@@ -0,0 +1,75 @@
+package action
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+
+	"github.com/golang/protobuf/ptypes"
+)
+
+func init() {
+}
+
+// CrazyBullExitRoom 疯狂牛牛机器人退出房间行为
+type CrazyBullExitRoom struct {
+}
+
+// Action 触发行为
+//
+// 传入参数是机器人信息playerInfo可修改，且修改的信息在外部会保存
+// roomInfo都是只读的，修改是无用的
+// playerInfo也是只读的，修改需要自己保存一次，底层已对player加锁
+//
+// 返回值依次是：
+//
+// 行为是否结束，这里的行为结束意味着整个完成了，可以进行下一个行为了，而不是单次行为有没有完成
+// 比如下注在玩耍行为下会有多次，而不是一次，如果传结束了，则整个玩耍行为会结束
+//
+// 行为是否出错，同一行为出错的话外部会进行累积，如果累积到一定数字，则认为机器人卡死，会在底层作出处理
+//
+// 下次行为在多少秒之后，用于顶层tick
+func (o *CrazyBullExitRoom) Action(playerInfo *pb.PlayerInfo, roomInfo *pb.RoomInfo, actionConfig *pb.RobotActionConfig, extraInfo *pb.MessageExtroInfo) (bool, bool, int64) {
+	if roomInfo == nil {
+		return true, false, 1
+	}
+	// 如果被标记为下岗，则结束行为
+	if playerInfo.GetRobotExtroInfo().GetIsLaidOff() == true {
+		return true, false, 1
+	}
+
+	//获取玩家在房间的索引
+	var playerIndex = -1
+	for v, k := range roomInfo.PlayerInfo {
+		if k.GetUuid() == playerInfo.GetUuid() {
+			playerIndex = v
+			break
+		}
+	}
+	if playerIndex == -1 { // 此处应该提交报错，出现这个错误有可能锁卡了?
+		common.LogError("CrazyBullExitRoom Action playerIndex == -1,but roomInfo != nil!")
+		return false, true, 1
+	}
+
+	// 如果玩家不在游戏状态即可退出
+	if roomInfo.PlayerInfo[playerIndex].GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+		gameExitRoomRequest := &pb.GameExitRoomRequest{}
+		gameExitRoomReply := &pb.GameExitRoomReply{}
+
+		crazyBullDoContent, err := ptypes.MarshalAny(gameExitRoomRequest)
+		if err != nil {
+			common.LogError("CrazyBullExitRoom Action MarshalAny err", err)
+			return false, true, 5
+		}
+		crazyBullDoRequest := &pb.CrazyBullDoRequest{}
+		crazyBullDoRequest.DoType = pb.CrazyBullDoType_CrazyBullDo_ExitRoom
+		crazyBullDoRequest.DoMessageContent = crazyBullDoContent
+		msgErr := common.Router.Call("CrazyBullRoute", "Do", crazyBullDoRequest, gameExitRoomReply, extraInfo)
+		if msgErr != nil {
+			common.LogError("CrazyBullExitRoom Action call do err", msgErr)
+			return false, true, 5
+		}
+		common.LogDebug("robot CrazyBull ExitRoom  ok", playerInfo.GetUuid())
+		return true, false, 1
+	}
+	return false, false, 5
+}
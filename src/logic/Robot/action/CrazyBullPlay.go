@@ -0,0 +1,169 @@
+package action
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"strconv"
+	"strings"
+)
+
+func init() {
+}
+
+// CrazyBullPlay 疯狂牛牛机器人玩耍行为
+type CrazyBullPlay struct {
+}
+
+// Action 触发行为
+//
+// 传入参数是机器人信息playerInfo可修改，且修改的信息在外部会保存
+// roomInfo都是只读的，修改是无用的
+// playerInfo也是只读的，修改需要自己保存一次，底层已对player加锁
+//
+// 返回值依次是：
+//
+// 行为是否结束，这里的行为结束意味着整个完成了，可以进行下一个行为了，而不是单次行为有没有完成
+// 比如下注在玩耍行为下会有多次，而不是一次，如果传结束了，则整个玩耍行为会结束
+//
+// 行为是否出错，同一行为出错的话外部会进行累积，如果累积到一定数字，则认为机器人卡死，会在底层作出处理
+//
+// 下次行为在多少秒之后，用于顶层tick
+func (o *CrazyBullPlay) Action(playerInfo *pb.PlayerInfo, roomInfo *pb.RoomInfo, actionConfig *pb.RobotActionConfig, extraInfo *pb.MessageExtroInfo) (bool, bool, int64) {
+	// 当玩家房间信息为空时，结束当前行为
+	if roomInfo == nil {
+		return true, false, 5
+	}
+
+	roomPlayerInfo := common.GetRoomPlayerInfo(roomInfo, playerInfo.GetUuid())
+	if roomPlayerInfo == nil {
+		common.LogError("CrazyBullPlay Action player not in room", playerInfo.GetUuid())
+		return true, false, 5
+	}
+
+	// 游戏中按照房间阶段操作
+	if roomPlayerInfo.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+		return o.play(roomInfo, roomPlayerInfo, extraInfo)
+	}
+
+	// 当机器人没得什么钱了，就退出去充钱
+	if roomPlayerInfo.Balance < actionConfig.MinBalance {
+		return true, false, int64(common.GetRandomNum(1, 3))
+	}
+
+	// 如果被标记为下岗，则结束行为
+	if playerInfo.GetRobotExtroInfo().GetIsLaidOff() == true {
+		return true, false, 1
+	}
+
+	// 不是准备阶段或者已经准备了，随缘加载
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateReady ||
+		roomInfo.GetNextRoomState() == pb.RoomState_RoomStateReady ||
+		roomPlayerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStateFree {
+		return false, false, int64(common.GetRandomNum(1, 2))
+	}
+
+	// 如果大于了最大玩耍局数，则结束行为
+	if roomPlayerInfo.GetPlayNum() >= actionConfig.GetMaxPlayNum() {
+		return true, false, 1
+	}
+
+	// 如果介于最大和最小局数之间，则随机结束行为
+	if roomPlayerInfo.GetPlayNum() >= actionConfig.GetMinPlayNum() &&
+		roomPlayerInfo.GetPlayNum() < actionConfig.GetMaxPlayNum() {
+		randomNum := common.GetRandomNum(1, 100)
+		if int32(randomNum) <= actionConfig.GetPlayEndPre() {
+			return true, false, 1
+		}
+	}
+
+	// 随缘延迟
+	if int64(common.GetRandomNum(1, 3)) == 1 {
+		return false, false, 1
+	}
+
+	// 准备
+	changeStateRequest := &pb.GameChangeStateRequest{
+		WantState: pb.PlayerRoomState_PlayerRoomStateReady,
+	}
+	msgErr := o.callDo(pb.CrazyBullDoType_CrazyBullDo_ChangeState, changeStateRequest, &pb.GameChangeStateReply{}, extraInfo)
+	if msgErr != nil {
+		common.LogError("CrazyBullPlay Action ready call do err", msgErr)
+		return false, true, 5
+	}
+	return false, false, int64(common.GetRandomNum(1, 3))
+}
+
+// play 机器人在游戏中的操作：抢庄、下注、开牌
+func (o *CrazyBullPlay) play(roomInfo *pb.RoomInfo, roomPlayerInfo *pb.RoomPlayerInfo, extraInfo *pb.MessageExtroInfo) (bool, bool, int64) {
+	// 阶段刚切换还没初始化完，或者随缘思考一会
+	if roomInfo.GetCurRoomState() == roomInfo.GetNextRoomState() || int64(common.GetRandomNum(1, 3)) == 1 {
+		return false, false, 1
+	}
+
+	var msgErr *pb.ErrorMessage
+	switch roomInfo.GetCurRoomState() {
+	case pb.RoomState_RoomStateRushVillage:
+		for _, uuid := range roomInfo.GetCrazyBullMultiples() {
+			if uuid == roomPlayerInfo.GetUuid() {
+				return false, false, int64(common.GetRandomNum(1, 2))
+			}
+		}
+		rushRequest := &pb.CrazyUpBankerRequest{
+			RushVillage: o.getRandomOdds(roomInfo, "RushVillageOdds"),
+		}
+		msgErr = o.callDo(pb.CrazyBullDoType_CrazyBullDo_RushVillage, rushRequest, &pb.CrazyUpBankerReply{}, extraInfo)
+	case pb.RoomState_RoomStateBet:
+		if roomInfo.GetCrazyBullMultipleuuid() == roomPlayerInfo.GetUuid() || roomPlayerInfo.GetIsCrazyBullPlayOdds() {
+			return false, false, int64(common.GetRandomNum(1, 2))
+		}
+		betRequest := &pb.CrazyBetRequest{
+			BetBalance: o.getRandomOdds(roomInfo, "BetOdds"),
+		}
+		msgErr = o.callDo(pb.CrazyBullDoType_CrazyBullDo_Bets, betRequest, &pb.CrazyTigerBetReply{}, extraInfo)
+	case pb.RoomState_RoomStateRubbingCards:
+		if len(roomPlayerInfo.GetOutPokers()) != 0 {
+			return false, false, int64(common.GetRandomNum(1, 2))
+		}
+		// 开牌的牌为空时按照发的手牌开牌
+		msgErr = o.callDo(pb.CrazyBullDoType_CrazyBullDo_OpenCard, &pb.GameOpenCardRequest{}, &pb.GameOpenCardReply{}, extraInfo)
+	default:
+		return false, false, int64(common.GetRandomNum(1, 2))
+	}
+	if msgErr != nil {
+		common.LogError("CrazyBullPlay play call do err", roomInfo.GetCurRoomState(), msgErr)
+		return false, true, 5
+	}
+	return false, false, int64(common.GetRandomNum(1, 2))
+}
+
+// getRandomOdds 从房间配置的倍数列表中随机选择一个倍数
+func (o *CrazyBullPlay) getRandomOdds(roomInfo *pb.RoomInfo, configName string) int64 {
+	var oddsList []int64
+	for _, oddsStr := range strings.Split(common.GetRoomConfig(roomInfo, configName), ",") {
+		odds, err := strconv.ParseInt(oddsStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		oddsList = append(oddsList, odds)
+	}
+	if len(oddsList) == 0 {
+		return 0
+	}
+	return oddsList[common.GetRandomNum(0, len(oddsList)-1)]
+}
+
+// callDo 封装疯狂牛牛的操作请求并调用路由
+func (o *CrazyBullPlay) callDo(doType pb.CrazyBullDoType, realRequest proto.Message, realReply proto.Message, extraInfo *pb.MessageExtroInfo) *pb.ErrorMessage {
+	crazyBullDoContent, err := ptypes.MarshalAny(realRequest)
+	if err != nil {
+		common.LogError("CrazyBullPlay callDo MarshalAny err", err)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	request := &pb.CrazyBullDoRequest{
+		DoType:           doType,
+		DoMessageContent: crazyBullDoContent,
+	}
+	return common.Router.Call("CrazyBullRoute", "Do", request, realReply, extraInfo)
+}
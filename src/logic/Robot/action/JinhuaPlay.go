@@ -0,0 +1,186 @@
+package action
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+func init() {
+}
+
+// JinhuaPlay 炸金花机器人玩耍行为
+type JinhuaPlay struct {
+}
+
+// Action 触发行为
+//
+// 传入参数是机器人信息playerInfo可修改，且修改的信息在外部会保存
+// roomInfo都是只读的，修改是无用的
+// playerInfo也是只读的，修改需要自己保存一次，底层已对player加锁
+//
+// 返回值依次是：
+//
+// 行为是否结束，这里的行为结束意味着整个完成了，可以进行下一个行为了，而不是单次行为有没有完成
+// 比如下注在玩耍行为下会有多次，而不是一次，如果传结束了，则整个玩耍行为会结束
+//
+// 行为是否出错，同一行为出错的话外部会进行累积，如果累积到一定数字，则认为机器人卡死，会在底层作出处理
+//
+// 下次行为在多少秒之后，用于顶层tick
+func (o *JinhuaPlay) Action(playerInfo *pb.PlayerInfo, roomInfo *pb.RoomInfo, actionConfig *pb.RobotActionConfig, extraInfo *pb.MessageExtroInfo) (bool, bool, int64) {
+	// 当玩家房间信息为空时，结束当前行为
+	if roomInfo == nil {
+		return true, false, 5
+	}
+
+	roomPlayerInfo := common.GetRoomPlayerInfo(roomInfo, playerInfo.GetUuid())
+	if roomPlayerInfo == nil {
+		common.LogError("JinhuaPlay Action player not in room", playerInfo.GetUuid())
+		return true, false, 5
+	}
+
+	// 游戏中按照房间阶段操作
+	if roomPlayerInfo.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+		return o.play(roomInfo, roomPlayerInfo, extraInfo)
+	}
+
+	// 当机器人没得什么钱了，就退出去充钱
+	if roomPlayerInfo.Balance < actionConfig.MinBalance {
+		return true, false, int64(common.GetRandomNum(1, 3))
+	}
+
+	// 如果被标记为下岗，则结束行为
+	if playerInfo.GetRobotExtroInfo().GetIsLaidOff() == true {
+		return true, false, 1
+	}
+
+	// 不是准备阶段或者已经准备了，随缘加载
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateReady ||
+		roomInfo.GetNextRoomState() == pb.RoomState_RoomStateReady ||
+		roomPlayerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStateFree {
+		return false, false, int64(common.GetRandomNum(1, 2))
+	}
+
+	// 如果大于了最大玩耍局数，则结束行为
+	if roomPlayerInfo.GetPlayNum() >= actionConfig.GetMaxPlayNum() {
+		return true, false, 1
+	}
+
+	// 如果介于最大和最小局数之间，则随机结束行为
+	if roomPlayerInfo.GetPlayNum() >= actionConfig.GetMinPlayNum() &&
+		roomPlayerInfo.GetPlayNum() < actionConfig.GetMaxPlayNum() {
+		randomNum := common.GetRandomNum(1, 100)
+		if int32(randomNum) <= actionConfig.GetPlayEndPre() {
+			return true, false, 1
+		}
+	}
+
+	// 随缘延迟
+	if int64(common.GetRandomNum(1, 3)) == 1 {
+		return false, false, 1
+	}
+
+	// 准备
+	changeStateRequest := &pb.GameChangeStateRequest{
+		WantState: pb.PlayerRoomState_PlayerRoomStateReady,
+	}
+	msgErr := o.callDo(pb.JinhuaDoType_JinhuaDo_ChangeState, changeStateRequest, &pb.GameChangeStateReply{}, extraInfo)
+	if msgErr != nil {
+		common.LogError("JinhuaPlay Action ready call do err", msgErr)
+		return false, true, 5
+	}
+	return false, false, int64(common.GetRandomNum(1, 3))
+}
+
+// play 机器人在游戏中的操作，只有轮到自己时才操作
+// 牌型是对子以上的算好牌：好牌会加注、全押、孤注一掷，差牌看过之后有一定概率弃牌，几轮之后随缘比牌
+func (o *JinhuaPlay) play(roomInfo *pb.RoomInfo, roomPlayerInfo *pb.RoomPlayerInfo, extraInfo *pb.MessageExtroInfo) (bool, bool, int64) {
+	jinhuaInRoom := roomInfo.GetJinhuaInRoom()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || roomInfo.GetNextRoomState() == pb.RoomState_RoomStatePlay ||
+		jinhuaInRoom.GetWaitType() != pb.JinhuaWaitOperateType_JinhuaWaitOperateType_Player ||
+		jinhuaInRoom.GetCurPlayerUuid() != roomPlayerInfo.GetUuid() ||
+		roomPlayerInfo.GetJinhuaOutType() != pb.JinhuaOutType_JinhuaOutType_None {
+		return false, false, 1
+	}
+	// 随缘思考一会
+	if int64(common.GetRandomNum(1, 3)) == 1 {
+		return false, false, 1
+	}
+
+	canOperateTypes := jinhuaInRoom.GetCanOperateTypes()
+	isGoodPokers := roomPlayerInfo.GetCompareJinhuaPokerType().GetJinhuaPokerType() <= pb.JinhuaPokerType_JinhuaCardType_Pair
+	operateRequest := &pb.JinhuaOperateRequest{}
+	switch {
+	case o.canOperate(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_Check) && common.GetRandomNum(1, 3) == 1:
+		operateRequest.OperateType = pb.JinhuaOperateType_JinhuaOperateType_Check
+	case o.canOperate(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_LastThrow):
+		operateRequest.OperateType = pb.JinhuaOperateType_JinhuaOperateType_Fold
+		if isGoodPokers {
+			operateRequest.OperateType = pb.JinhuaOperateType_JinhuaOperateType_LastThrow
+		}
+	case len(jinhuaInRoom.GetAllInUuidSequence()) > 0:
+		operateRequest.OperateType = pb.JinhuaOperateType_JinhuaOperateType_Fold
+		if isGoodPokers {
+			operateRequest.OperateType = pb.JinhuaOperateType_JinhuaOperateType_AllIn
+		}
+	case roomPlayerInfo.GetJinhuaIsChecked() && !isGoodPokers && common.GetRandomNum(1, 100) <= 40:
+		operateRequest.OperateType = pb.JinhuaOperateType_JinhuaOperateType_Fold
+	case o.canOperate(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_Compare) &&
+		roomPlayerInfo.GetJinhuaRound() >= 3 && common.GetRandomNum(1, 3) == 1:
+		operateRequest.OperateType = pb.JinhuaOperateType_JinhuaOperateType_Compare
+		operateRequest.CompareUuid = o.getRandomCompareUuid(roomInfo, roomPlayerInfo)
+	case o.canOperate(canOperateTypes, pb.JinhuaOperateType_JinhuaOperateType_Raise) && isGoodPokers && common.GetRandomNum(1, 4) == 1:
+		operateRequest.OperateType = pb.JinhuaOperateType_JinhuaOperateType_Raise
+		operateRequest.RaiseType = jinhuaInRoom.GetCurrentRaiseType() + 1
+	default:
+		operateRequest.OperateType = pb.JinhuaOperateType_JinhuaOperateType_Call
+	}
+	msgErr := o.callDo(pb.JinhuaDoType_JinhuaDo_Operate, operateRequest, &pb.JinhuaOperateReply{}, extraInfo)
+	if msgErr != nil {
+		common.LogError("JinhuaPlay play call do err", operateRequest.GetOperateType(), msgErr)
+		return false, true, 5
+	}
+	return false, false, 1
+}
+
+// canOperate 可操作列表中是否包含某个操作
+func (o *JinhuaPlay) canOperate(canOperateTypes []pb.JinhuaOperateType, operateType pb.JinhuaOperateType) bool {
+	for _, oneType := range canOperateTypes {
+		if oneType == operateType {
+			return true
+		}
+	}
+	return false
+}
+
+// getRandomCompareUuid 随机选择一个还没出局的其他玩家比牌
+func (o *JinhuaPlay) getRandomCompareUuid(roomInfo *pb.RoomInfo, roomPlayerInfo *pb.RoomPlayerInfo) string {
+	var uuids []string
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetUuid() == roomPlayerInfo.GetUuid() ||
+			onePlayer.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay ||
+			onePlayer.GetJinhuaOutType() != pb.JinhuaOutType_JinhuaOutType_None {
+			continue
+		}
+		uuids = append(uuids, onePlayer.GetUuid())
+	}
+	if len(uuids) == 0 {
+		return ""
+	}
+	return uuids[common.GetRandomNum(0, len(uuids)-1)]
+}
+
+// callDo 封装炸金花的操作请求并调用路由
+func (o *JinhuaPlay) callDo(doType pb.JinhuaDoType, realRequest proto.Message, realReply proto.Message, extraInfo *pb.MessageExtroInfo) *pb.ErrorMessage {
+	jinhuaDoContent, err := ptypes.MarshalAny(realRequest)
+	if err != nil {
+		common.LogError("JinhuaPlay callDo MarshalAny err", err)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	request := &pb.JinhuaDoRequest{
+		DoType:           doType,
+		DoMessageContent: jinhuaDoContent,
+	}
+	return common.Router.Call("JinhuaRoute", "Do", request, realReply, extraInfo)
+}
@@ -0,0 +1,164 @@
+package action
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+func init() {
+}
+
+// LinkUpPlay 连连看机器人玩耍行为
+type LinkUpPlay struct {
+}
+
+// Action 触发行为
+//
+// 传入参数是机器人信息playerInfo可修改，且修改的信息在外部会保存
+// roomInfo都是只读的，修改是无用的
+// playerInfo也是只读的，修改需要自己保存一次，底层已对player加锁
+//
+// 返回值依次是：
+//
+// 行为是否结束，这里的行为结束意味着整个完成了，可以进行下一个行为了，而不是单次行为有没有完成
+// 比如下注在玩耍行为下会有多次，而不是一次，如果传结束了，则整个玩耍行为会结束
+//
+// 行为是否出错，同一行为出错的话外部会进行累积，如果累积到一定数字，则认为机器人卡死，会在底层作出处理
+//
+// 下次行为在多少秒之后，用于顶层tick
+func (o *LinkUpPlay) Action(playerInfo *pb.PlayerInfo, roomInfo *pb.RoomInfo, actionConfig *pb.RobotActionConfig, extraInfo *pb.MessageExtroInfo) (bool, bool, int64) {
+	// 当玩家房间信息为空时，结束当前行为
+	if roomInfo == nil {
+		return true, false, 5
+	}
+
+	roomPlayerInfo := common.GetRoomPlayerInfo(roomInfo, playerInfo.GetUuid())
+	if roomPlayerInfo == nil {
+		common.LogError("LinkUpPlay Action player not in room", playerInfo.GetUuid())
+		return true, false, 5
+	}
+
+	// 游戏中按照房间阶段操作
+	if roomPlayerInfo.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+		return o.play(roomInfo, roomPlayerInfo, extraInfo)
+	}
+
+	// 当机器人没得什么钱了，就退出去充钱
+	if roomPlayerInfo.Balance < actionConfig.MinBalance {
+		return true, false, int64(common.GetRandomNum(1, 3))
+	}
+
+	// 如果被标记为下岗，则结束行为
+	if playerInfo.GetRobotExtroInfo().GetIsLaidOff() == true {
+		return true, false, 1
+	}
+
+	// 不是准备阶段或者已经准备了，随缘加载
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStateReady ||
+		roomInfo.GetNextRoomState() == pb.RoomState_RoomStateReady ||
+		roomPlayerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStateFree {
+		return false, false, int64(common.GetRandomNum(1, 2))
+	}
+
+	// 如果大于了最大玩耍局数，则结束行为
+	if roomPlayerInfo.GetPlayNum() >= actionConfig.GetMaxPlayNum() {
+		return true, false, 1
+	}
+
+	// 如果介于最大和最小局数之间，则随机结束行为
+	if roomPlayerInfo.GetPlayNum() >= actionConfig.GetMinPlayNum() &&
+		roomPlayerInfo.GetPlayNum() < actionConfig.GetMaxPlayNum() {
+		randomNum := common.GetRandomNum(1, 100)
+		if int32(randomNum) <= actionConfig.GetPlayEndPre() {
+			return true, false, 1
+		}
+	}
+
+	// 随缘延迟
+	if int64(common.GetRandomNum(1, 3)) == 1 {
+		return false, false, 1
+	}
+
+	// 准备
+	changeStateRequest := &pb.GameChangeStateRequest{
+		WantState: pb.PlayerRoomState_PlayerRoomStateReady,
+	}
+	msgErr := o.callDo(pb.LinkUpDoType_LinkUpDo_ChangeState, changeStateRequest, &pb.GameChangeStateReply{}, extraInfo)
+	if msgErr != nil {
+		common.LogError("LinkUpPlay Action ready call do err", msgErr)
+		return false, true, 5
+	}
+	return false, false, int64(common.GetRandomNum(1, 3))
+}
+
+// play 机器人在游戏中的操作，每次随机挑一对一样的图标去消除，连不起来的由服务端判定消除失败
+// 根据房间的血池状态控制速度：平台要吃分时消除得快，平台要吐分时消除得慢
+func (o *LinkUpPlay) play(roomInfo *pb.RoomInfo, roomPlayerInfo *pb.RoomPlayerInfo, extraInfo *pb.MessageExtroInfo) (bool, bool, int64) {
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || roomInfo.GetNextRoomState() == pb.RoomState_RoomStatePlay ||
+		len(roomPlayerInfo.GetIconPoints()) == 0 {
+		return false, false, 1
+	}
+	switch roomInfo.GetLinkUpBloodSlotStatus() {
+	case pb.BloodSlotStatus_BloodSlotStatus_Lose:
+		if common.GetRandomNum(1, 3) != 1 {
+			return false, false, 1
+		}
+	case pb.BloodSlotStatus_BloodSlotStatus_Win:
+		// 吃分时每次都去消除
+	default:
+		if common.GetRandomNum(1, 2) == 1 {
+			return false, false, 1
+		}
+	}
+
+	pointA, pointB := o.getRandomSamePoints(roomPlayerInfo.GetIconPoints())
+	if pointA == nil {
+		return false, false, 1
+	}
+	clearIconRequest := &pb.LinkUpClearIconRequest{
+		IconPointA: pointA,
+		IconPointB: pointB,
+	}
+	msgErr := o.callDo(pb.LinkUpDoType_LinkUpDo_ClearIcon, clearIconRequest, &pb.LinkUpClearIconReply{}, extraInfo)
+	if msgErr != nil {
+		common.LogError("LinkUpPlay play call do err", msgErr)
+		return false, true, 5
+	}
+	return false, false, 1
+}
+
+// getRandomSamePoints 随机挑选一对一样的图标
+func (o *LinkUpPlay) getRandomSamePoints(iconPoints []*pb.IconPoint) (*pb.IconPoint, *pb.IconPoint) {
+	if len(iconPoints) < 2 {
+		return nil, nil
+	}
+	pointA := iconPoints[common.GetRandomNum(0, len(iconPoints)-1)]
+	var samePoints []*pb.IconPoint
+	for _, onePoint := range iconPoints {
+		if onePoint == pointA || onePoint.GetIcon().GetIconType() != pointA.GetIcon().GetIconType() ||
+			onePoint.GetIcon().GetIconNum() != pointA.GetIcon().GetIconNum() {
+			continue
+		}
+		samePoints = append(samePoints, onePoint)
+	}
+	if len(samePoints) == 0 {
+		return nil, nil
+	}
+	return pointA, samePoints[common.GetRandomNum(0, len(samePoints)-1)]
+}
+
+// callDo 封装连连看的操作请求并调用路由
+func (o *LinkUpPlay) callDo(doType pb.LinkUpDoType, realRequest proto.Message, realReply proto.Message, extraInfo *pb.MessageExtroInfo) *pb.ErrorMessage {
+	linkUpDoContent, err := ptypes.MarshalAny(realRequest)
+	if err != nil {
+		common.LogError("LinkUpPlay callDo MarshalAny err", err)
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	request := &pb.LinkUpDoRequest{
+		DoType:           doType,
+		DoMessageContent: linkUpDoContent,
+	}
+	return common.Router.Call("LinkUpRoute", "Do", request, realReply, extraInfo)
+}
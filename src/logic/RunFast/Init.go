@@ -0,0 +1,6 @@
+package logic
+
+// Init 用于方便包被外部引用的函数，同时在这里引用子包
+func Init() {
+
+}
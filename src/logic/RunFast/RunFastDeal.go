@@ -0,0 +1,90 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["RunFastDeal"] = &RunFastDeal{}
+}
+
+// RunFastDeal 跑得快游戏的发牌组件，用于处理发牌阶段的逻辑
+type RunFastDeal struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *RunFastDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *RunFastDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 跑得快发牌阶段的主驱动
+// 48张牌发给三个玩家，每人16张，手牌只推送给自己，其他玩家只知道发了牌
+func (obj *RunFastDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateDeal {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStatePlay
+		request.NextRoomState = pb.RoomState_RoomStatePlay
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	dealTimeStr := common.GetRoomConfig(request, "DealTime")
+	dealTime, err := strconv.Atoi(dealTimeStr)
+	if err != nil {
+		common.LogError("RunFastDeal Drive dealTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	players := getPlayPlayers(request)
+	if len(players) != playerNum {
+		common.LogError("RunFastDeal Drive player num has err", len(players))
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 推送房间状态 准备<->发牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateReady,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + int64(dealTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	cardHeap := getRunFastPokerHeap()
+	for index, onePlayer := range players {
+		onePlayer.Pokers = cardHeap[index*handPokerNum : (index+1)*handPokerNum]
+		onePlayer.OutPokers = nil
+		pushToSelf := &pb.PushPlayerCardChange{
+			RoomId:    request.GetUuid(),
+			UserId:    onePlayer.GetUuid(),
+			HandPoker: onePlayer.GetPokers(),
+		}
+		pushToOthers := &pb.PushPlayerCardChange{
+			RoomId: request.GetUuid(),
+			UserId: onePlayer.GetUuid(),
+		}
+		msgErr := common.PushRoom(pushToSelf, pushToOthers, onePlayer.GetUuid(), request)
+		if msgErr != nil {
+			common.LogError("RunFastDeal Drive PushRoom has err", onePlayer.GetUuid(), msgErr)
+		}
+	}
+	request.RunFastInRoom = &pb.RunFastInRoom{}
+
+	request.NextRoomState = pb.RoomState_RoomStatePlay
+	request.DoTime = nowTime + int64(dealTime)
+	return request, nil
+}
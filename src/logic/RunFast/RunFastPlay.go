@@ -0,0 +1,274 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["RunFastPlay"] = &RunFastPlay{}
+}
+
+// RunFastPlay 跑得快游戏的玩耍组件，用于处理玩家轮流出牌阶段的逻辑
+type RunFastPlay struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *RunFastPlay) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *RunFastPlay) Start() {
+	obj.Base.Start()
+}
+
+// Drive 跑得快玩耍阶段的主驱动
+// 拿到黑桃3的玩家先出牌，之后按座位顺序轮流出牌，有牌能压必须压，压不过才能过牌
+// 操作超时或者玩家断线、已经退出时由系统自动出牌，有玩家出完手牌本局结束进入结算
+func (obj *RunFastPlay) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState == pb.RoomState_RoomStatePlay {
+		// 推送房间状态 发牌<->玩耍
+		pushRoomState := &pb.PushRoomStateChange{
+			RoomId:      request.GetUuid(),
+			BeforeState: pb.RoomState_RoomStateDeal,
+			AfterState:  pb.RoomState_RoomStatePlay,
+		}
+		common.RoomBroadcast(request, pushRoomState)
+
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		for index, onePlayer := range request.GetPlayerInfo() {
+			if onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay && hasSpade3(onePlayer.GetPokers()) {
+				msgErr := obj.startTurn(request, int32(index), nowTime)
+				return request, msgErr
+			}
+		}
+		common.LogError("RunFastPlay Drive no player has spade 3", request.GetUuid())
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	if nowTime < request.GetDoTime() {
+		return request, nil
+	}
+	// 操作超时由系统自动出牌
+	curPlayer := request.GetPlayerInfo()[request.GetDoIndex()]
+	msgErr := obj.autoOperate(request, curPlayer, nowTime)
+	return request, msgErr
+}
+
+// startTurn 轮到座位index的玩家出牌，断线或者已经退出的玩家等待AutoOperateTime后自动出牌
+func (obj *RunFastPlay) startTurn(request *pb.RoomInfo, index int32, nowTime int64) *pb.ErrorMessage {
+	onePlayer := request.GetPlayerInfo()[index]
+	operateTimeName := "OperateTime"
+	if onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None || !isOnline(onePlayer) {
+		operateTimeName = "AutoOperateTime"
+	}
+	operateTime, msgErr := getRoomConfigInt64(request, operateTimeName)
+	if msgErr != nil {
+		return msgErr
+	}
+	request.DoIndex = index
+	request.DoTime = nowTime + operateTime
+	pushDoTime := &pb.PushRoomDoTimeChange{
+		RoomId:  request.GetUuid(),
+		EndTime: request.GetDoTime(),
+	}
+	common.RoomBroadcast(request, pushDoTime)
+	return nil
+}
+
+// nextTurn 轮到下一个玩家出牌，出牌的玩家出完手牌时本局结束
+func (obj *RunFastPlay) nextTurn(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, nowTime int64) *pb.ErrorMessage {
+	if len(onePlayer.GetPokers()) == 0 {
+		request.CurRoomState = pb.RoomState_RoomStateSettle
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		request.DoTime = nowTime
+		return nil
+	}
+	return obj.startTurn(request, getNextPlayIndex(request, request.GetDoIndex()), nowTime)
+}
+
+// isLeading 当前玩家是否是首出：本局还没有人出牌，或者其他玩家都没有压过他上一手牌
+func (obj *RunFastPlay) isLeading(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) bool {
+	lastPlayerUuid := request.GetRunFastInRoom().GetLastPlayerUuid()
+	return lastPlayerUuid == "" || lastPlayerUuid == onePlayer.GetUuid()
+}
+
+// isNextReportSingle 当前玩家的下家是否报单（只剩一张牌），报单时出单张必须出最大的
+func (obj *RunFastPlay) isNextReportSingle(request *pb.RoomInfo) bool {
+	nextPlayer := request.GetPlayerInfo()[getNextPlayIndex(request, request.GetDoIndex())]
+	return len(nextPlayer.GetPokers()) == 1
+}
+
+// getLastPattern 获取需要压的上一手牌的牌型
+func (obj *RunFastPlay) getLastPattern(request *pb.RoomInfo) *runFastPattern {
+	return getRunFastPattern(request.GetRunFastInRoom().GetLastOutPokers(), false)
+}
+
+// checkDiscard 检查玩家要出的牌是否符合规则
+func (obj *RunFastPlay) checkDiscard(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, pokers []*pb.Poker) bool {
+	hand := onePlayer.GetPokers()
+	if len(pokers) == 0 || !isSubPokers(pokers, hand) {
+		return false
+	}
+	pattern := getRunFastPattern(pokers, len(pokers) == len(hand))
+	if pattern == nil {
+		return false
+	}
+	// 本局第一手必须包含黑桃3
+	if request.GetRunFastInRoom().GetLastPlayerUuid() == "" && !hasSpade3(pokers) {
+		return false
+	}
+	if !obj.isLeading(request, onePlayer) {
+		lastPattern := obj.getLastPattern(request)
+		if lastPattern == nil || !canBeat(pattern, lastPattern) {
+			return false
+		}
+	}
+	if pattern.pokerType == pb.RunFastPokerType_RunFastPoker_OneCard && obj.isNextReportSingle(request) {
+		return pattern.keyValue == getMaxSingleValue(hand)
+	}
+	return true
+}
+
+// canPass 当前玩家是否可以过牌，首出不能过，有牌能压过上一手时必须压
+func (obj *RunFastPlay) canPass(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) bool {
+	if obj.isLeading(request, onePlayer) {
+		return false
+	}
+	lastPattern := obj.getLastPattern(request)
+	if lastPattern == nil {
+		return true
+	}
+	return findBeatPokers(onePlayer.GetPokers(), lastPattern, obj.isNextReportSingle(request)) == nil
+}
+
+// discard 玩家出牌，手牌推送给自己，打出的牌推送给所有人
+func (obj *RunFastPlay) discard(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, pokers []*pb.Poker) {
+	onePlayer.Pokers = removePokers(onePlayer.GetPokers(), pokers)
+	onePlayer.OutPokers = pokers
+	runFastInRoom := request.GetRunFastInRoom()
+	runFastInRoom.LastPlayerUuid = onePlayer.GetUuid()
+	runFastInRoom.LastOutPokers = pokers
+	obj.pushCardChange(request, onePlayer, pokers)
+}
+
+// pass 玩家过牌，推送没有打出牌的手牌变动
+func (obj *RunFastPlay) pass(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) {
+	obj.pushCardChange(request, onePlayer, nil)
+}
+
+// pushCardChange 推送玩家的出牌，outPokers为空表示过牌
+func (obj *RunFastPlay) pushCardChange(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, outPokers []*pb.Poker) {
+	pushToSelf := &pb.PushPlayerCardChange{
+		RoomId:    request.GetUuid(),
+		UserId:    onePlayer.GetUuid(),
+		HandPoker: onePlayer.GetPokers(),
+		OutPoker:  outPokers,
+	}
+	pushToOthers := &pb.PushPlayerCardChange{
+		RoomId:   request.GetUuid(),
+		UserId:   onePlayer.GetUuid(),
+		OutPoker: outPokers,
+	}
+	msgErr := common.PushRoom(pushToSelf, pushToOthers, onePlayer.GetUuid(), request)
+	if msgErr != nil {
+		common.LogError("RunFastPlay pushCardChange PushRoom has err", onePlayer.GetUuid(), msgErr)
+	}
+}
+
+// autoOperate 系统替当前玩家出牌：首出时出最小的牌，跟牌时出能压过的最小的牌，压不过就过牌
+func (obj *RunFastPlay) autoOperate(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, nowTime int64) *pb.ErrorMessage {
+	mustMaxSingle := obj.isNextReportSingle(request)
+	var pokers []*pb.Poker
+	if obj.isLeading(request, onePlayer) {
+		mustSpade3 := request.GetRunFastInRoom().GetLastPlayerUuid() == ""
+		pokers = getLeadPokers(onePlayer.GetPokers(), mustMaxSingle, mustSpade3)
+	} else if lastPattern := obj.getLastPattern(request); lastPattern != nil {
+		pokers = findBeatPokers(onePlayer.GetPokers(), lastPattern, mustMaxSingle)
+	}
+	if len(pokers) == 0 {
+		obj.pass(request, onePlayer)
+	} else {
+		obj.discard(request, onePlayer, pokers)
+	}
+	return obj.nextTurn(request, onePlayer, nowTime)
+}
+
+// RequestOperate 玩家在玩耍阶段的操作：出牌或者过牌
+func (obj *RunFastPlay) RequestOperate(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	realRequest := &pb.RunFastOperateRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("RunFastPlay RequestOperate ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("RunFastPlay RequestOperate player not in room", uid)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if roomInfo.GetPlayerInfo()[roomInfo.GetDoIndex()].GetUuid() != uid {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+
+	switch realRequest.GetOperateType() {
+	case pb.RunFastOperateType_RunFastOperateType_DoNothing:
+		if !obj.canPass(roomInfo, playerInfo) {
+			return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+		}
+		obj.pass(roomInfo, playerInfo)
+	case pb.RunFastOperateType_RunFastOperateType_Discard:
+		if !obj.checkDiscard(roomInfo, playerInfo, realRequest.GetOutPokers()) {
+			return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+		}
+		obj.discard(roomInfo, playerInfo, realRequest.GetOutPokers())
+	default:
+		// 协议中没有记录托管状态的字段，断线和超时的玩家由系统自动出牌
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+	}
+	msgErr := obj.nextTurn(roomInfo, playerInfo, time.Now().Unix())
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return packReply(roomInfo, &pb.RunFastOperateReply{})
+}
+
+// RequestExitInGame 玩家在对局中退出房间
+// 这里只标记为等待踢出，轮到他时由系统自动出牌，结算后状态置空由房间的Kick踢出
+func (obj *RunFastPlay) RequestExitInGame(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	playerInfo.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_Exit
+	// 轮到自己时退出，缩短等待时间尽快自动出牌
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStatePlay && roomInfo.GetNextRoomState() == pb.RoomState_RoomStateSettle &&
+		roomInfo.GetPlayerInfo()[roomInfo.GetDoIndex()].GetUuid() == uid {
+		msgErr := obj.startTurn(roomInfo, roomInfo.GetDoIndex(), time.Now().Unix())
+		if msgErr != nil {
+			return reply, msgErr
+		}
+	}
+	// 结算阶段本局已经结算完了，可以直接踢出
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStateSettle && roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+	}
+	return packReply(roomInfo, &pb.GameExitRoomReply{})
+}
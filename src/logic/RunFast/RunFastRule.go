@@ -0,0 +1,439 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"sort"
+	"strconv"
+)
+
+// 每个玩家的手牌张数
+const handPokerNum = 16
+
+// 游戏人数，48张牌正好发完
+const playerNum = 3
+
+// 顺子、连对、飞机中最大的点数（A），2不能连
+const maxSequenceValue = 14
+
+// getRoomConfigInt64 获取房间的整数配置
+func getRoomConfigInt64(roomInfo *pb.RoomInfo, configName string) (int64, *pb.ErrorMessage) {
+	configStr := common.GetRoomConfig(roomInfo, configName)
+	configNum, err := strconv.ParseInt(configStr, 10, 64)
+	if err != nil {
+		common.LogError("RunFast getRoomConfigInt64 has err", configName, configStr, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return configNum, nil
+}
+
+// runFastPattern 一手牌的牌型信息
+type runFastPattern struct {
+	pokerType pb.RunFastPokerType
+	// 比较大小的点数：单张、对子、炸弹是牌的点数，三带二、四带二是三张、四张的点数，顺子、连对、三顺、飞机是最大一组的点数
+	keyValue int
+	// 顺子、连对、三顺、飞机的组数，其他牌型为1
+	groupNum int
+	// 牌的张数
+	pokerNum int
+}
+
+// getRunFastPokerValue 获取单张牌比大小时的点数，3最小，A和2最大
+func getRunFastPokerValue(poker *pb.Poker) int {
+	switch poker.GetPokerNum() {
+	case pb.PokerNum_PokerNum1:
+		return 14
+	case pb.PokerNum_PokerNum2:
+		return 15
+	}
+	return int(poker.GetPokerNum())
+}
+
+// getRunFastPokerHeap 获取跑得快洗好的牌堆
+// 一副牌去掉大小王、红桃梅花方块三张2和黑桃A，剩下48张，每人16张
+func getRunFastPokerHeap() []*pb.Poker {
+	var pokers []*pb.Poker
+	for _, poker := range common.GetPokerHeap(1) {
+		if poker.GetPokerNum() == pb.PokerNum_PokerNum2 && poker.GetPokerColor() != pb.PokerColor_PokerColorSpade {
+			continue
+		}
+		if poker.GetPokerNum() == pb.PokerNum_PokerNum1 && poker.GetPokerColor() == pb.PokerColor_PokerColorSpade {
+			continue
+		}
+		pokers = append(pokers, poker)
+	}
+	common.RandSlice(pokers)
+	return pokers
+}
+
+// isSpade3 是否是黑桃3，拿到黑桃3的玩家先出牌，并且第一手牌必须包含黑桃3
+func isSpade3(poker *pb.Poker) bool {
+	return poker.GetPokerNum() == pb.PokerNum_PokerNum3 && poker.GetPokerColor() == pb.PokerColor_PokerColorSpade
+}
+
+// hasSpade3 一组牌中是否有黑桃3
+func hasSpade3(pokers []*pb.Poker) bool {
+	for _, poker := range pokers {
+		if isSpade3(poker) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupByValue 按照点数对牌分组
+func groupByValue(pokers []*pb.Poker) map[int][]*pb.Poker {
+	groups := make(map[int][]*pb.Poker)
+	for _, poker := range pokers {
+		value := getRunFastPokerValue(poker)
+		groups[value] = append(groups[value], poker)
+	}
+	return groups
+}
+
+// getSortedValues 获取分组中的所有点数，从小到大
+func getSortedValues(groups map[int][]*pb.Poker) []int {
+	values := make([]int, 0, len(groups))
+	for value := range groups {
+		values = append(values, value)
+	}
+	sort.Ints(values)
+	return values
+}
+
+// isRun 从endValue往下groupNum个连续点数是否都至少有minCount张，顺子类的牌型不能包含2
+func isRun(groups map[int][]*pb.Poker, endValue int, groupNum int, minCount int) bool {
+	if endValue > maxSequenceValue || endValue-groupNum+1 < 3 {
+		return false
+	}
+	for value := endValue - groupNum + 1; value <= endValue; value++ {
+		if len(groups[value]) < minCount {
+			return false
+		}
+	}
+	return true
+}
+
+// getRunFastPattern 获取一手牌的牌型，不是合法牌型返回nil
+// isLastHand表示这手牌是玩家的最后几张牌，最后一手的三带二、飞机可以少带
+func getRunFastPattern(pokers []*pb.Poker, isLastHand bool) *runFastPattern {
+	pokerNum := len(pokers)
+	if pokerNum == 0 {
+		return nil
+	}
+	groups := groupByValue(pokers)
+	values := getSortedValues(groups)
+	maxValue := values[len(values)-1]
+	newPattern := func(pokerType pb.RunFastPokerType, keyValue int, groupNum int) *runFastPattern {
+		return &runFastPattern{pokerType: pokerType, keyValue: keyValue, groupNum: groupNum, pokerNum: pokerNum}
+	}
+
+	// 每个点数张数都一样的牌型：单张、对子、炸弹、顺子、连对、三顺
+	sameCount := pokerNum / len(values)
+	if pokerNum%len(values) == 0 && len(groups[values[0]]) == sameCount {
+		isSame := true
+		for _, value := range values {
+			if len(groups[value]) != sameCount {
+				isSame = false
+				break
+			}
+		}
+		if isSame {
+			switch {
+			case len(values) == 1 && sameCount == 1:
+				return newPattern(pb.RunFastPokerType_RunFastPoker_OneCard, maxValue, 1)
+			case len(values) == 1 && sameCount == 2:
+				return newPattern(pb.RunFastPokerType_RunFastPoker_Pair, maxValue, 1)
+			case len(values) == 1 && sameCount == 4:
+				return newPattern(pb.RunFastPokerType_RunFastPoker_Bomb, maxValue, 1)
+			case sameCount == 1 && len(values) >= 5 && isRun(groups, maxValue, len(values), 1):
+				return newPattern(pb.RunFastPokerType_RunFastPoker_Sequence, maxValue, len(values))
+			case sameCount == 2 && len(values) >= 2 && isRun(groups, maxValue, len(values), 2):
+				return newPattern(pb.RunFastPokerType_RunFastPoker_MulPair, maxValue, len(values))
+			case sameCount == 3 && len(values) >= 2 && isRun(groups, maxValue, len(values), 3):
+				return newPattern(pb.RunFastPokerType_RunFastPoker_ThreeSequence, maxValue, len(values))
+			}
+		}
+	}
+
+	// 飞机带翅膀：连续的三张，每组带两张，最后一手可以少带
+	for groupNum := pokerNum / 3; groupNum >= 2; groupNum-- {
+		for index := len(values) - 1; index >= 0; index-- {
+			if !isRun(groups, values[index], groupNum, 3) {
+				continue
+			}
+			if pokerNum == groupNum*5 || (isLastHand && pokerNum > groupNum*3 && pokerNum < groupNum*5) {
+				return newPattern(pb.RunFastPokerType_RunFastPoker_Kite, values[index], groupNum)
+			}
+		}
+	}
+
+	for index := len(values) - 1; index >= 0; index-- {
+		count := len(groups[values[index]])
+		// 三带二，最后一手可以三带一或者三张
+		if count >= 3 && (pokerNum == 5 || (isLastHand && (pokerNum == 3 || pokerNum == 4))) {
+			return newPattern(pb.RunFastPokerType_RunFastPoker_ThreeBandTwo, values[index], 1)
+		}
+		// 四带二
+		if count == 4 && pokerNum == 6 {
+			return newPattern(pb.RunFastPokerType_RunFastPoker_FourBandTwo, values[index], 1)
+		}
+	}
+	return nil
+}
+
+// canBeat 判断cur这手牌是否能压过last
+// 炸弹可以压任何非炸弹的牌，其他牌型必须牌型、组数一样并且点数更大，最后一手少带的三带二和飞机也可以压
+func canBeat(cur *runFastPattern, last *runFastPattern) bool {
+	if cur.pokerType == pb.RunFastPokerType_RunFastPoker_Bomb {
+		return last.pokerType != pb.RunFastPokerType_RunFastPoker_Bomb || cur.keyValue > last.keyValue
+	}
+	if cur.pokerType != last.pokerType || cur.groupNum != last.groupNum || cur.keyValue <= last.keyValue {
+		return false
+	}
+	if cur.pokerType == pb.RunFastPokerType_RunFastPoker_ThreeBandTwo || cur.pokerType == pb.RunFastPokerType_RunFastPoker_Kite {
+		return cur.pokerNum <= last.pokerNum
+	}
+	return cur.pokerNum == last.pokerNum
+}
+
+// takeSmallest 从手牌中按点数从小到大取num张不在排除点数中的牌作为带牌，不够返回nil
+func takeSmallest(groups map[int][]*pb.Poker, excludeValues map[int]bool, num int) []*pb.Poker {
+	var pokers []*pb.Poker
+	for _, value := range getSortedValues(groups) {
+		if excludeValues[value] {
+			continue
+		}
+		for _, poker := range groups[value] {
+			if len(pokers) == num {
+				return pokers
+			}
+			pokers = append(pokers, poker)
+		}
+	}
+	if len(pokers) == num {
+		return pokers
+	}
+	return nil
+}
+
+// takeRun 从手牌中取从endValue往下groupNum个点数，每个点数取count张
+func takeRun(groups map[int][]*pb.Poker, endValue int, groupNum int, count int) ([]*pb.Poker, map[int]bool) {
+	var pokers []*pb.Poker
+	usedValues := make(map[int]bool)
+	for value := endValue - groupNum + 1; value <= endValue; value++ {
+		pokers = append(pokers, groups[value][:count]...)
+		usedValues[value] = true
+	}
+	return pokers, usedValues
+}
+
+// findBeatPokers 从手牌中找出能压过last的最小的一手牌，找不到返回nil
+// 先找同牌型的，再找炸弹；mustMaxSingle表示下家报单，出单张时必须出最大的
+func findBeatPokers(hand []*pb.Poker, last *runFastPattern, mustMaxSingle bool) []*pb.Poker {
+	groups := groupByValue(hand)
+	values := getSortedValues(groups)
+	if len(values) == 0 {
+		return nil
+	}
+	isLastHand := func(pokers []*pb.Poker) bool {
+		return len(pokers) == len(hand)
+	}
+	tryPokers := func(pokers []*pb.Poker) bool {
+		if pokers == nil {
+			return false
+		}
+		cur := getRunFastPattern(pokers, isLastHand(pokers))
+		return cur != nil && canBeat(cur, last)
+	}
+
+	switch last.pokerType {
+	case pb.RunFastPokerType_RunFastPoker_OneCard:
+		if mustMaxSingle {
+			maxPokers := groups[values[len(values)-1]][:1]
+			if tryPokers(maxPokers) {
+				return maxPokers
+			}
+			break
+		}
+		for _, value := range values {
+			if tryPokers(groups[value][:1]) {
+				return groups[value][:1]
+			}
+		}
+	case pb.RunFastPokerType_RunFastPoker_Pair:
+		for _, value := range values {
+			if len(groups[value]) >= 2 && tryPokers(groups[value][:2]) {
+				return groups[value][:2]
+			}
+		}
+	case pb.RunFastPokerType_RunFastPoker_ThreeBandTwo, pb.RunFastPokerType_RunFastPoker_FourBandTwo:
+		count, bandNum := 3, 2
+		if last.pokerType == pb.RunFastPokerType_RunFastPoker_FourBandTwo {
+			count = 4
+		}
+		for _, value := range values {
+			if len(groups[value]) < count {
+				continue
+			}
+			bandPokers := takeSmallest(groups, map[int]bool{value: true}, bandNum)
+			pokers := append(append([]*pb.Poker{}, groups[value][:count]...), bandPokers...)
+			if bandPokers != nil && tryPokers(pokers) {
+				return pokers
+			}
+		}
+		// 最后一手可以少带
+		if tryPokers(hand) {
+			return hand
+		}
+	case pb.RunFastPokerType_RunFastPoker_Sequence, pb.RunFastPokerType_RunFastPoker_MulPair,
+		pb.RunFastPokerType_RunFastPoker_ThreeSequence, pb.RunFastPokerType_RunFastPoker_Kite:
+		count := map[pb.RunFastPokerType]int{
+			pb.RunFastPokerType_RunFastPoker_Sequence:      1,
+			pb.RunFastPokerType_RunFastPoker_MulPair:       2,
+			pb.RunFastPokerType_RunFastPoker_ThreeSequence: 3,
+			pb.RunFastPokerType_RunFastPoker_Kite:          3,
+		}[last.pokerType]
+		for endValue := last.keyValue + 1; endValue <= maxSequenceValue; endValue++ {
+			if !isRun(groups, endValue, last.groupNum, count) {
+				continue
+			}
+			pokers, usedValues := takeRun(groups, endValue, last.groupNum, count)
+			if last.pokerType == pb.RunFastPokerType_RunFastPoker_Kite {
+				bandPokers := takeSmallest(groups, usedValues, last.groupNum*2)
+				if bandPokers == nil {
+					if tryPokers(hand) {
+						return hand
+					}
+					continue
+				}
+				pokers = append(pokers, bandPokers...)
+			}
+			if tryPokers(pokers) {
+				return pokers
+			}
+		}
+	}
+
+	// 用炸弹压
+	for _, value := range values {
+		if len(groups[value]) == 4 && tryPokers(groups[value]) {
+			return groups[value]
+		}
+	}
+	return nil
+}
+
+// getLeadPokers 自动出牌时获取首出的牌：剩下的牌能一手出完就全出，否则出最小点数的所有牌（三张带最小的两张）
+// 下家报单时不出单张，只剩单张可出时出最大的单张；mustSpade3表示本局第一手，必须包含黑桃3
+func getLeadPokers(hand []*pb.Poker, mustMaxSingle bool, mustSpade3 bool) []*pb.Poker {
+	if getRunFastPattern(hand, true) != nil {
+		return hand
+	}
+	groups := groupByValue(hand)
+	values := getSortedValues(groups)
+	for _, value := range values {
+		if mustSpade3 && value != 3 {
+			continue
+		}
+		pokers := groups[value]
+		if len(pokers) == 3 {
+			bandPokers := takeSmallest(groups, map[int]bool{value: true}, 2)
+			if bandPokers == nil {
+				continue
+			}
+			pokers = append(append([]*pb.Poker{}, pokers...), bandPokers...)
+		}
+		if len(pokers) == 1 && mustMaxSingle {
+			continue
+		}
+		return pokers
+	}
+	return groups[values[len(values)-1]][:1]
+}
+
+// getMaxSingleValue 获取手牌中最大的单张点数
+func getMaxSingleValue(hand []*pb.Poker) int {
+	maxValue := 0
+	for _, poker := range hand {
+		if getRunFastPokerValue(poker) > maxValue {
+			maxValue = getRunFastPokerValue(poker)
+		}
+	}
+	return maxValue
+}
+
+// isSubPokers 判断sub中的牌是否都在hand中（不重复使用）
+func isSubPokers(sub []*pb.Poker, hand []*pb.Poker) bool {
+	used := make([]bool, len(hand))
+	for _, subPoker := range sub {
+		found := false
+		for index, handPoker := range hand {
+			if used[index] || subPoker.GetPokerNum() != handPoker.GetPokerNum() || subPoker.GetPokerColor() != handPoker.GetPokerColor() {
+				continue
+			}
+			used[index] = true
+			found = true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// removePokers 从手牌中去掉打出的牌，返回剩下的手牌
+func removePokers(hand []*pb.Poker, out []*pb.Poker) []*pb.Poker {
+	used := make([]bool, len(hand))
+	for _, outPoker := range out {
+		for index, handPoker := range hand {
+			if !used[index] && outPoker.GetPokerNum() == handPoker.GetPokerNum() && outPoker.GetPokerColor() == handPoker.GetPokerColor() {
+				used[index] = true
+				break
+			}
+		}
+	}
+	var leftPokers []*pb.Poker
+	for index, handPoker := range hand {
+		if !used[index] {
+			leftPokers = append(leftPokers, handPoker)
+		}
+	}
+	return leftPokers
+}
+
+// getPlayPlayers 获取本局参与游戏的玩家
+func getPlayPlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+			continue
+		}
+		players = append(players, onePlayer)
+	}
+	return players
+}
+
+// getNextPlayIndex 获取座位index之后下一个游戏中的玩家的座位下标
+func getNextPlayIndex(roomInfo *pb.RoomInfo, index int32) int32 {
+	seatNum := int32(len(roomInfo.GetPlayerInfo()))
+	for step := int32(1); step <= seatNum; step++ {
+		nextIndex := (index + step) % seatNum
+		onePlayer := roomInfo.GetPlayerInfo()[nextIndex]
+		if onePlayer.GetUuid() != "" && onePlayer.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+			return nextIndex
+		}
+	}
+	return index
+}
+
+// isOnline 玩家是否在线
+func isOnline(onePlayer *pb.RoomPlayerInfo) bool {
+	online, msgErr := common.Pusher.CheckOnline(onePlayer.GetUuid())
+	if msgErr != nil {
+		common.LogError("RunFast isOnline CheckOnline has err", onePlayer.GetUuid(), msgErr)
+		return false
+	}
+	return online
+}
@@ -0,0 +1,217 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["ShiSanShuiCompare"] = &ShiSanShuiCompare{}
+}
+
+// ShiSanShuiCompare 十三水游戏的比牌组件，用于处理两两比牌和逐步展示比牌结果的逻辑
+type ShiSanShuiCompare struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *ShiSanShuiCompare) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *ShiSanShuiCompare) Start() {
+	obj.Base.Start()
+}
+
+// compareSteps 比牌展示的步骤顺序
+var compareSteps = []pb.ShiSanShuiCompareStep{
+	pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Heap1,
+	pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Heap2,
+	pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Heap3,
+	pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Shoot,
+	pb.ShiSanShuiCompareStep_ShiSanShuiCompare_HomeRun,
+	pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Special,
+}
+
+// Drive 十三水比牌阶段的主驱动
+// 刚进入比牌阶段时两两比牌算出所有的比牌记录，然后每隔CompareStepTime秒展示一步：头墩、中墩、尾墩、打枪、全垒打、特殊牌型
+// 没有记录的步骤直接跳过，展示完进入结算
+func (obj *ShiSanShuiCompare) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	compareStepTime, msgErr := getRoomConfigInt64(request, "CompareStepTime")
+	if msgErr != nil {
+		return request, msgErr
+	}
+	if request.NextRoomState == pb.RoomState_RoomStateCompare {
+		// 推送房间状态 玩耍<->比牌
+		pushRoomState := &pb.PushRoomStateChange{
+			RoomId:      request.GetUuid(),
+			BeforeState: pb.RoomState_RoomStatePlay,
+			AfterState:  pb.RoomState_RoomStateCompare,
+		}
+		common.RoomBroadcast(request, pushRoomState)
+
+		players := getPlayPlayers(request)
+		request.ShiSanShuiCompareLogs = getCompareLogs(players)
+		request.ShiSanShuiCompareCurStep = pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Start
+		// 亮牌，所有人的摆牌都展示出来
+		pushCompare := &pb.ShiSanShuiCompareMessege{
+			RoomId:  request.GetUuid(),
+			Players: players,
+			Step:    pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Start,
+		}
+		common.RoomBroadcast(request, pushCompare)
+
+		request.NextRoomState = pb.RoomState_RoomStateSettle
+		request.DoTime = nowTime + compareStepTime
+		return request, nil
+	}
+
+	if nowTime < request.GetDoTime() {
+		return request, nil
+	}
+	for _, step := range compareSteps {
+		if step <= request.GetShiSanShuiCompareCurStep() {
+			continue
+		}
+		stepLogs := getStepLogs(request.GetShiSanShuiCompareLogs(), step)
+		if len(stepLogs) == 0 {
+			continue
+		}
+		obj.showStep(request, step, stepLogs)
+		request.DoTime = nowTime + compareStepTime
+		return request, nil
+	}
+	request.CurRoomState = pb.RoomState_RoomStateSettle
+	request.NextRoomState = pb.RoomState_RoomStateSettle
+	request.DoTime = nowTime
+	return request, nil
+}
+
+// showStep 展示一步比牌结果，玩家的WinOrLoseCurStep为这一步的输赢水数
+func (obj *ShiSanShuiCompare) showStep(request *pb.RoomInfo, step pb.ShiSanShuiCompareStep, stepLogs []*pb.ShiSanShuiCompareLog) {
+	request.ShiSanShuiCompareCurStep = step
+	players := getPlayPlayers(request)
+	for _, onePlayer := range players {
+		onePlayer.WinOrLoseCurStep = 0
+		for _, oneLog := range stepLogs {
+			if oneLog.GetWinnerID() == onePlayer.GetUuid() {
+				onePlayer.WinOrLoseCurStep += int64(oneLog.GetAmount())
+			}
+			if oneLog.GetLoserID() == onePlayer.GetUuid() {
+				onePlayer.WinOrLoseCurStep -= int64(oneLog.GetAmount())
+			}
+		}
+	}
+	pushCompare := &pb.ShiSanShuiCompareMessege{
+		RoomId:  request.GetUuid(),
+		Players: players,
+		Step:    step,
+	}
+	if step == pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Shoot {
+		pushCompare.Shoot = stepLogs
+	}
+	if step == pb.ShiSanShuiCompareStep_ShiSanShuiCompare_HomeRun {
+		pushCompare.RunHomePlayer = common.GetRoomPlayerInfo(request, stepLogs[0].GetWinnerID())
+	}
+	common.RoomBroadcast(request, pushCompare)
+}
+
+// getStepLogs 获取某一步的比牌记录
+func getStepLogs(logs []*pb.ShiSanShuiCompareLog, step pb.ShiSanShuiCompareStep) []*pb.ShiSanShuiCompareLog {
+	var stepLogs []*pb.ShiSanShuiCompareLog
+	for _, oneLog := range logs {
+		if oneLog.GetCompareStep() == step {
+			stepLogs = append(stepLogs, oneLog)
+		}
+	}
+	return stepLogs
+}
+
+// getCompareLogs 所有玩家两两比牌，返回所有的比牌记录
+// 有特殊牌型时只比特殊牌型，特殊牌型大的赢对应的水数；都是普通牌型时逐墩比较，赢的一方得这一墩的水数
+// 三墩都赢是打枪，再赢一次三墩的水数；三人以上时打枪所有其他玩家是全垒打，和每个玩家的输赢再翻一倍
+func getCompareLogs(players []*pb.RoomPlayerInfo) []*pb.ShiSanShuiCompareLog {
+	var logs []*pb.ShiSanShuiCompareLog
+	shootNum := make(map[string]int)
+	// 每一对玩家打枪之后的输赢水数，用于全垒打翻倍
+	shootAmount := make(map[[2]string]int32)
+	for i := 0; i < len(players); i++ {
+		for j := i + 1; j < len(players); j++ {
+			a, b := players[i], players[j]
+			specialA := a.GetShiSanShuiPlacePoker().GetSpetialType()
+			specialB := b.GetShiSanShuiPlacePoker().GetSpetialType()
+			if specialA != pb.ShiSanShuiPokerType_ShiSanShuiPokerType_None || specialB != pb.ShiSanShuiPokerType_ShiSanShuiPokerType_None {
+				if specialA == specialB {
+					continue
+				}
+				winner, loser, winType := a, b, specialA
+				if specialB > specialA {
+					winner, loser, winType = b, a, specialB
+				}
+				logs = append(logs, newCompareLog(pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Special, winner, loser, specialPoints[winType]))
+				continue
+			}
+
+			infosA := getPlaceHeapInfos(a.GetShiSanShuiPlacePoker())
+			infosB := getPlaceHeapInfos(b.GetShiSanShuiPlacePoker())
+			winNumA, winNumB := 0, 0
+			var amountA, amountB int32
+			for heapIndex := range infosA {
+				step := pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Heap1 + pb.ShiSanShuiCompareStep(heapIndex)
+				switch {
+				case infosA[heapIndex].score > infosB[heapIndex].score:
+					point := getHeapPoint(heapIndex, infosA[heapIndex].pokerType)
+					logs = append(logs, newCompareLog(step, a, b, point))
+					winNumA++
+					amountA += point
+				case infosB[heapIndex].score > infosA[heapIndex].score:
+					point := getHeapPoint(heapIndex, infosB[heapIndex].pokerType)
+					logs = append(logs, newCompareLog(step, b, a, point))
+					winNumB++
+					amountB += point
+				}
+			}
+			if winNumA == len(infosA) {
+				logs = append(logs, newCompareLog(pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Shoot, a, b, amountA))
+				shootNum[a.GetUuid()]++
+				shootAmount[[2]string{a.GetUuid(), b.GetUuid()}] = amountA * 2
+			}
+			if winNumB == len(infosB) {
+				logs = append(logs, newCompareLog(pb.ShiSanShuiCompareStep_ShiSanShuiCompare_Shoot, b, a, amountB))
+				shootNum[b.GetUuid()]++
+				shootAmount[[2]string{b.GetUuid(), a.GetUuid()}] = amountB * 2
+			}
+		}
+	}
+
+	if len(players) < 3 {
+		return logs
+	}
+	for _, winner := range players {
+		if shootNum[winner.GetUuid()] != len(players)-1 {
+			continue
+		}
+		for _, loser := range players {
+			if amount, ok := shootAmount[[2]string{winner.GetUuid(), loser.GetUuid()}]; ok {
+				logs = append(logs, newCompareLog(pb.ShiSanShuiCompareStep_ShiSanShuiCompare_HomeRun, winner, loser, amount))
+			}
+		}
+	}
+	return logs
+}
+
+// newCompareLog 生成一条比牌记录
+func newCompareLog(step pb.ShiSanShuiCompareStep, winner *pb.RoomPlayerInfo, loser *pb.RoomPlayerInfo, amount int32) *pb.ShiSanShuiCompareLog {
+	return &pb.ShiSanShuiCompareLog{
+		CompareStep: step,
+		WinnerID:    winner.GetUuid(),
+		LoserID:     loser.GetUuid(),
+		Amount:      amount,
+	}
+}
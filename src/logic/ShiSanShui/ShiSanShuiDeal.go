@@ -0,0 +1,111 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"strconv"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["ShiSanShuiDeal"] = &ShiSanShuiDeal{}
+}
+
+// ShiSanShuiDeal 十三水游戏的发牌组件，用于处理发牌阶段的逻辑
+type ShiSanShuiDeal struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *ShiSanShuiDeal) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *ShiSanShuiDeal) Start() {
+	obj.Base.Start()
+}
+
+// Drive 十三水发牌阶段的主驱动，给每个游戏中的玩家发十三张牌
+// 拿到特殊牌型的玩家直接按特殊牌型摆好牌，不需要手动摆牌
+func (obj *ShiSanShuiDeal) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState != pb.RoomState_RoomStateDeal {
+		if nowTime < request.DoTime {
+			return request, nil
+		}
+		request.CurRoomState = pb.RoomState_RoomStatePlay
+		request.NextRoomState = pb.RoomState_RoomStatePlay
+		request.DoTime = nowTime
+		return request, nil
+	}
+
+	dealTimeStr := common.GetRoomConfig(request, "DealTime")
+	dealTime, err := strconv.Atoi(dealTimeStr)
+	if err != nil {
+		common.LogError("ShiSanShuiDeal Drive dealTimeStr has err", err)
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	players := getPlayPlayers(request)
+	if len(players)*handPokerNum > deckPokerNum {
+		common.LogError("ShiSanShuiDeal Drive too many players", len(players))
+		return request, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+
+	// 推送房间状态 准备<->发牌
+	pushRoomState := &pb.PushRoomStateChange{
+		RoomId:            request.GetUuid(),
+		BeforeState:       pb.RoomState_RoomStateReady,
+		AfterState:        pb.RoomState_RoomStateDeal,
+		AfterStateEndTime: nowTime + int64(dealTime),
+	}
+	common.RoomBroadcast(request, pushRoomState)
+
+	cardHeap := common.GetShufflePokerHeap(1)
+	for index, onePlayer := range players {
+		onePlayer.Pokers = cardHeap[index*handPokerNum : (index+1)*handPokerNum]
+		onePlayer.OutPokers = nil
+		// 自己能看到手牌，其他人只知道发了牌
+		pushToSelf := &pb.PushPlayerCardChange{
+			RoomId:    request.GetUuid(),
+			UserId:    onePlayer.GetUuid(),
+			HandPoker: onePlayer.GetPokers(),
+		}
+		pushToOthers := &pb.PushPlayerCardChange{
+			RoomId: request.GetUuid(),
+			UserId: onePlayer.GetUuid(),
+		}
+		msgErr := common.PushRoom(pushToSelf, pushToOthers, onePlayer.GetUuid(), request)
+		if msgErr != nil {
+			common.LogError("ShiSanShuiDeal Drive PushRoom has err", onePlayer.GetUuid(), msgErr)
+		}
+		obj.placeSpecial(request, onePlayer)
+	}
+
+	request.NextRoomState = pb.RoomState_RoomStatePlay
+	request.DoTime = nowTime + int64(dealTime)
+	return request, nil
+}
+
+// placeSpecial 玩家拿到特殊牌型时按特殊牌型摆好牌，并把默认的摆牌推送给玩家
+func (obj *ShiSanShuiDeal) placeSpecial(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) {
+	specialType, placePoker := getSpecialType(onePlayer.GetPokers())
+	if specialType == pb.ShiSanShuiPokerType_ShiSanShuiPokerType_None {
+		return
+	}
+	if placePoker == nil {
+		placePoker = getPlaceSuggestions(onePlayer.GetPokers(), 1)[0]
+	}
+	placePoker.SpetialType = specialType
+	onePlayer.ShiSanShuiPlacePoker = placePoker
+	onePlayer.ShiSanShuiPlaced = true
+
+	pushSpecialCard := &pb.PushPlayerSpecialCard{
+		RoomId:     request.GetUuid(),
+		PlacePoker: placePoker,
+	}
+	common.Pusher.Push(pushSpecialCard, onePlayer.GetUuid())
+}
@@ -0,0 +1,168 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+)
+
+func init() {
+	common.AllComponentMap["ShiSanShuiDriver"] = &ShiSanShuiDriver{}
+}
+
+// ShiSanShuiDriver 十三水游戏的房间管理组件，负责处理玩家请求操作
+type ShiSanShuiDriver struct {
+	base.Base
+	rm *common.RoomManager
+}
+
+var maxRoomNumCfgName = "ShiSanShuiMaxRoomNumOneServer"
+
+// LoadComponent 加载组件
+func (obj *ShiSanShuiDriver) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	// 这是一个多线路组件
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *ShiSanShuiDriver) Start() {
+	obj.Base.Start()
+
+	initGlobleConfigNameArr := []string{
+		maxRoomNumCfgName,
+	}
+	err := common.InitGlobleConfigTemp(initGlobleConfigNameArr)
+	if err != nil {
+		panic(err)
+	}
+	//将模板参数保存到内存中
+	common.InitGameConfigTemp(common.ShiSanShuiGameConfigTemp, pb.GameType_ShiSanShui)
+
+	obj.rm = new(common.RoomManager)
+	tableName := common.GetRoomRedisName(pb.GameType_ShiSanShui, common.ServerIndex)
+	obj.rm.InitRoomManager(pb.GameType_ShiSanShui, tableName, obj.DriveRoom)
+	//从redis里恢复房间数据（会导致配置不更新, 要刷新房间需要清空redis）
+	err = obj.rm.ReLoadRooms()
+	if err != nil {
+		panic(err)
+	}
+	obj.rm.ReStartRooms()
+	common.LogDebug("❤十三水服务初始化❤")
+}
+
+// DriveRoom 这个方法提供房间驱动的具体逻辑
+// (room公共驱动调用到这里，通过这个函数根据房间状态调用相应的房间逻辑)
+func (obj *ShiSanShuiDriver) DriveRoom(roomInfo *pb.RoomInfo) *pb.RoomInfo {
+	extraInfo := &pb.MessageExtroInfo{}
+	afterRoomInfo := &pb.RoomInfo{}
+
+	// 根据状态获取需要调用的组件名
+	componentName := (*obj.Base.Config)[pb.RoomState_name[int32(roomInfo.CurRoomState)]]
+	if componentName == "" {
+		common.LogError("ShiSanShui DriveRoom get componentName has empty")
+		return nil
+	}
+	msgErr := common.Router.Call(componentName, "Drive", roomInfo, afterRoomInfo, extraInfo)
+	if msgErr != nil {
+		common.LogError("ShiSanShui DriveRoom call ", componentName, " Drive has err", msgErr)
+		return afterRoomInfo
+	}
+	return afterRoomInfo
+}
+
+// RequestJoinRoom 加入房间逻辑
+func (obj *ShiSanShuiDriver) RequestJoinRoom(request *pb.GameJoinRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameJoinRoomReply, *pb.ErrorMessage) {
+	reply := &pb.GameJoinRoomReply{}
+	roomInfo, msgErr := common.GameDriverJoinRoom(request, maxRoomNumCfgName, obj.rm, extroInfo)
+	if msgErr != nil {
+		common.LogError("ShiSanShuiDriver gameDriverJoinRoom get RoomInfo has error:", msgErr)
+		return nil, msgErr
+	}
+	reply.RoomInfo = roomInfo
+	return reply, msgErr
+}
+
+// RequestExitRoom 退出房间逻辑
+// 对战场游戏中的玩家不能直接退出，这时标记为等待踢出，还没摆牌的由系统摆牌，本局结算后由房间的Kick踢出
+func (obj *ShiSanShuiDriver) RequestExitRoom(request *pb.GameExitRoomRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameExitRoomReply, *pb.ErrorMessage) {
+	reply := &pb.GameExitRoomReply{}
+	msgErr := common.GameDriverExitRoom(obj.rm, extroInfo)
+	if msgErr == nil || msgErr.GetCode() != pb.ErrorCode_NotAllowExitRoom {
+		return reply, msgErr
+	}
+	msgErr = common.GameDriverDo("ShiSanShuiPlay", "RequestExitInGame", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestChangeState 玩家准备或取消准备逻辑
+func (obj *ShiSanShuiDriver) RequestChangeState(request *pb.GameChangeStateRequest, extroInfo *pb.MessageExtroInfo) (*pb.GameChangeStateReply, *pb.ErrorMessage) {
+	reply := &pb.GameChangeStateReply{}
+	msgErr := common.GameDriverDo("ShiSanShuiReady", "RequestChangeState", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestAutoPlace 获取系统推荐的摆牌方案，多次请求按推荐顺序依次返回
+func (obj *ShiSanShuiDriver) RequestAutoPlace(request *pb.ShiSanShuiAutoPlaceRequest, extroInfo *pb.MessageExtroInfo) (*pb.ShiSanShuiAutoPlaceReply, *pb.ErrorMessage) {
+	reply := &pb.ShiSanShuiAutoPlaceReply{}
+	msgErr := common.GameDriverDo("ShiSanShuiPlay", "RequestAutoPlace", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestPlace 玩家提交摆牌方案
+func (obj *ShiSanShuiDriver) RequestPlace(request *pb.ShiSanShuiPlaceRequest, extroInfo *pb.MessageExtroInfo) (*pb.ShiSanShuiPlaceReply, *pb.ErrorMessage) {
+	reply := &pb.ShiSanShuiPlaceReply{}
+	msgErr := common.GameDriverDo("ShiSanShuiPlay", "RequestPlace", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestGetPokerType 获取摆牌方案每一墩的牌型
+func (obj *ShiSanShuiDriver) RequestGetPokerType(request *pb.ShiSanShuiPlaceRequest, extroInfo *pb.MessageExtroInfo) (*pb.ShiSanShuiPlaceReply, *pb.ErrorMessage) {
+	reply := &pb.ShiSanShuiPlaceReply{}
+	msgErr := common.GameDriverDo("ShiSanShuiPlay", "RequestGetPokerType", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// RequestAskForPlace 玩家取消已经提交的摆牌，重新摆牌
+func (obj *ShiSanShuiDriver) RequestAskForPlace(request *pb.ShiSanShuiAskForPlaceRequest, extroInfo *pb.MessageExtroInfo) (*pb.ShiSanShuiAskForPlaceReply, *pb.ErrorMessage) {
+	reply := &pb.ShiSanShuiAskForPlaceReply{}
+	msgErr := common.GameDriverDo("ShiSanShuiPlay", "RequestAskForPlace", request, reply, obj.rm, extroInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return reply, nil
+}
+
+// SyncRoomPlayerInfo 同步玩家房间玩家信息
+func (obj *ShiSanShuiDriver) SyncRoomPlayerInfo(request *pb.SyncRoomPlayerInfo, extroInfo *pb.MessageExtroInfo) (*pb.EmptyMessage, *pb.ErrorMessage) {
+	reply := &pb.EmptyMessage{}
+	msgErr := common.GameDriverSyncRoomPlayerInfo(request, obj.rm, extroInfo)
+	return reply, msgErr
+}
+
+// DelRoom 删除某房间
+func (obj *ShiSanShuiDriver) DelRoom(request *pb.DelRoomByGameTypeRequest, extraInfo *pb.MessageExtroInfo) (*pb.DelRoomByGameTypeReply, *pb.ErrorMessage) {
+	reply := &pb.DelRoomByGameTypeReply{}
+	// 根据uuid删除房间
+	err := obj.rm.DeleteRoom(request.GetRoomUUID(), true)
+	if err != nil {
+		common.LogError("ShiSanShuiDriver DelRoom has err:", request.GameType, err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return reply, nil
+}
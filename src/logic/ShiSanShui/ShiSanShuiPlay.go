@@ -0,0 +1,294 @@
+package logic
+
+import (
+	"gameServer-demo/src/base"
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"time"
+)
+
+func init() {
+	common.AllComponentMap["ShiSanShuiPlay"] = &ShiSanShuiPlay{}
+}
+
+// ShiSanShuiPlay 十三水游戏的玩耍组件，用于处理玩家摆牌阶段的逻辑
+type ShiSanShuiPlay struct {
+	base.Base
+}
+
+// LoadComponent 加载组件
+func (obj *ShiSanShuiPlay) LoadComponent(config *common.OneComponentConfig, componentName string) {
+	obj.Base.LoadComponent(config, componentName)
+	return
+}
+
+// Start 这个方法将在所有组件的LoadComponent之后依次调用
+func (obj *ShiSanShuiPlay) Start() {
+	obj.Base.Start()
+}
+
+// Drive 十三水摆牌阶段的主驱动
+// 所有玩家同时摆牌，断线或者已经退出的玩家由系统摆牌；所有人都摆好或者摆牌时间到了，没摆牌的由系统摆牌后进入比牌
+func (obj *ShiSanShuiPlay) Drive(request *pb.RoomInfo, _ *pb.MessageExtroInfo) (*pb.RoomInfo, *pb.ErrorMessage) {
+	// 获取当前时间时间戳
+	nowTime := time.Now().Unix()
+	if request.NextRoomState == pb.RoomState_RoomStatePlay {
+		placeTime, msgErr := getRoomConfigInt64(request, "PlaceTime")
+		if msgErr != nil {
+			return request, msgErr
+		}
+		// 推送房间状态 发牌<->玩耍
+		pushRoomState := &pb.PushRoomStateChange{
+			RoomId:            request.GetUuid(),
+			BeforeState:       pb.RoomState_RoomStateDeal,
+			AfterState:        pb.RoomState_RoomStatePlay,
+			AfterStateEndTime: nowTime + placeTime,
+		}
+		common.RoomBroadcast(request, pushRoomState)
+
+		request.NextRoomState = pb.RoomState_RoomStateCompare
+		request.DoTime = nowTime + placeTime
+		for _, onePlayer := range getPlayPlayers(request) {
+			// 特殊牌型在发牌时已经摆好了
+			if onePlayer.GetShiSanShuiPlaced() {
+				obj.pushPlaced(request, onePlayer)
+				continue
+			}
+			if onePlayer.GetWaitKick() != pb.RoomSeatsChangeReason_RoomSeatsChangeReason_None || !isOnline(onePlayer) {
+				msgErr = obj.autoPlace(request, onePlayer)
+				if msgErr != nil {
+					return request, msgErr
+				}
+			}
+		}
+		obj.checkAllPlaced(request, nowTime)
+		return request, nil
+	}
+
+	if nowTime < request.GetDoTime() {
+		return request, nil
+	}
+	// 摆牌时间到了，没摆牌的由系统摆牌
+	for _, onePlayer := range getPlayPlayers(request) {
+		msgErr := obj.autoPlace(request, onePlayer)
+		if msgErr != nil {
+			return request, msgErr
+		}
+	}
+	request.CurRoomState = pb.RoomState_RoomStateCompare
+	request.NextRoomState = pb.RoomState_RoomStateCompare
+	request.DoTime = nowTime
+	return request, nil
+}
+
+// getSuggestions 获取玩家手牌的摆牌建议
+func (obj *ShiSanShuiPlay) getSuggestions(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) ([]*pb.ShiSanShuiPlacePoker, *pb.ErrorMessage) {
+	suggestNum, msgErr := getRoomConfigInt64(request, "SuggestNum")
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	suggestions := getPlaceSuggestions(onePlayer.GetPokers(), int(suggestNum))
+	if len(suggestions) == 0 {
+		common.LogError("ShiSanShuiPlay getSuggestions has no suggestion", onePlayer.GetUuid())
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return suggestions, nil
+}
+
+// autoPlace 系统替玩家摆牌，玩家请求过推荐摆牌时使用最后一次推荐的，否则使用最优的摆牌
+func (obj *ShiSanShuiPlay) autoPlace(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) *pb.ErrorMessage {
+	if onePlayer.GetShiSanShuiPlaced() {
+		return nil
+	}
+	if onePlayer.GetShiSanShuiPlacePoker() == nil {
+		suggestions, msgErr := obj.getSuggestions(request, onePlayer)
+		if msgErr != nil {
+			return msgErr
+		}
+		onePlayer.ShiSanShuiPlacePoker = suggestions[0]
+	}
+	onePlayer.ShiSanShuiPlaced = true
+	obj.pushPlaced(request, onePlayer)
+	return nil
+}
+
+// pushPlaced 通知房间内的玩家某个玩家摆牌或者取消摆牌了
+func (obj *ShiSanShuiPlay) pushPlaced(request *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo) {
+	pushPlaced := &pb.ShiSanShuiPlasedMessege{
+		RoomId:     request.GetUuid(),
+		PlayerUuid: onePlayer.GetUuid(),
+		Placed:     onePlayer.GetShiSanShuiPlaced(),
+	}
+	common.RoomBroadcast(request, pushPlaced)
+}
+
+// checkAllPlaced 所有玩家都摆好牌时不用等摆牌时间结束，下次驱动直接进入比牌
+func (obj *ShiSanShuiPlay) checkAllPlaced(request *pb.RoomInfo, nowTime int64) {
+	for _, onePlayer := range getPlayPlayers(request) {
+		if !onePlayer.GetShiSanShuiPlaced() {
+			return
+		}
+	}
+	request.DoTime = nowTime
+}
+
+// getPlacingPlayer 获取可以摆牌的玩家，不在摆牌阶段或者不在游戏中返回错误
+func (obj *ShiSanShuiPlay) getPlacingPlayer(roomInfo *pb.RoomInfo, uid string) (*pb.RoomPlayerInfo, *pb.ErrorMessage) {
+	if roomInfo.GetCurRoomState() != pb.RoomState_RoomStatePlay || roomInfo.GetNextRoomState() != pb.RoomState_RoomStateCompare {
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		common.LogError("ShiSanShuiPlay getPlacingPlayer player not in room", uid)
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_UserNotInRoom, "")
+	}
+	if playerInfo.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_PlayerHaveNoPermissionsOpt, "")
+	}
+	if playerInfo.GetShiSanShuiPlacePoker().GetSpetialType() != pb.ShiSanShuiPokerType_ShiSanShuiPokerType_None {
+		return nil, common.GetGrpcErrorMessage(pb.ErrorCode_ErrorPlaceSpetialType, "")
+	}
+	return playerInfo, nil
+}
+
+// RequestAutoPlace 获取系统推荐的摆牌方案
+// 推荐方案按照从优到劣排序，每次请求返回上一次推荐的下一个，到最后一个后从头开始
+func (obj *ShiSanShuiPlay) RequestAutoPlace(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	roomInfo := request.GetRoomInfo()
+	playerInfo, msgErr := obj.getPlacingPlayer(roomInfo, extroInfo.GetUserId())
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	if playerInfo.GetShiSanShuiPlaced() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	suggestions, msgErr := obj.getSuggestions(roomInfo, playerInfo)
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	nextIndex := 0
+	if lastPlace := playerInfo.GetShiSanShuiPlacePoker(); lastPlace != nil {
+		for index, suggestion := range suggestions {
+			if suggestion.GetPokersType1() == lastPlace.GetPokersType1() &&
+				suggestion.GetPokersType2() == lastPlace.GetPokersType2() &&
+				suggestion.GetPokersType3() == lastPlace.GetPokersType3() {
+				nextIndex = (index + 1) % len(suggestions)
+				break
+			}
+		}
+	}
+	playerInfo.ShiSanShuiPlacePoker = suggestions[nextIndex]
+	return packReply(roomInfo, &pb.ShiSanShuiAutoPlaceReply{
+		AutoPlacePoker: playerInfo.GetShiSanShuiPlacePoker(),
+		EndTime:        roomInfo.GetDoTime(),
+	})
+}
+
+// RequestPlace 玩家提交摆牌方案，倒水的方案不能提交
+func (obj *ShiSanShuiPlay) RequestPlace(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	roomInfo := request.GetRoomInfo()
+	playerInfo, msgErr := obj.getPlacingPlayer(roomInfo, extroInfo.GetUserId())
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	if playerInfo.GetShiSanShuiPlaced() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	realRequest := &pb.ShiSanShuiPlaceRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("ShiSanShuiPlay RequestPlace ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	placePoker := realRequest.GetPlacePoker()
+	msgErr = checkPlacePoker(placePoker, playerInfo.GetPokers())
+	if msgErr != nil {
+		if msgErr.GetCode() == pb.ErrorCode_ErrorPokerType {
+			return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ErrorPlacePokerType, "")
+		}
+		return reply, msgErr
+	}
+	playerInfo.ShiSanShuiPlacePoker = placePoker
+	playerInfo.ShiSanShuiPlaced = true
+	obj.pushPlaced(roomInfo, playerInfo)
+	obj.checkAllPlaced(roomInfo, time.Now().Unix())
+	return packReply(roomInfo, &pb.ShiSanShuiPlaceReply{
+		PlacePoker: placePoker,
+		EndTime:    roomInfo.GetDoTime(),
+	})
+}
+
+// RequestGetPokerType 获取摆牌方案每一墩的牌型，倒水时返回错误
+func (obj *ShiSanShuiPlay) RequestGetPokerType(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	roomInfo := request.GetRoomInfo()
+	playerInfo, msgErr := obj.getPlacingPlayer(roomInfo, extroInfo.GetUserId())
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	realRequest := &pb.ShiSanShuiPlaceRequest{}
+	err := ptypes.UnmarshalAny(request.GetMessage(), realRequest)
+	if err != nil {
+		common.LogError("ShiSanShuiPlay RequestGetPokerType ptypes.UnmarshalAny has err", err)
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	placePoker := proto.Clone(realRequest.GetPlacePoker()).(*pb.ShiSanShuiPlacePoker)
+	msgErr = checkPlacePoker(placePoker, playerInfo.GetPokers())
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	return packReply(roomInfo, &pb.ShiSanShuiPlaceReply{
+		PlacePoker: placePoker,
+		EndTime:    roomInfo.GetDoTime(),
+	})
+}
+
+// RequestAskForPlace 玩家取消已经提交的摆牌，在摆牌时间内重新摆牌
+func (obj *ShiSanShuiPlay) RequestAskForPlace(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	roomInfo := request.GetRoomInfo()
+	playerInfo, msgErr := obj.getPlacingPlayer(roomInfo, extroInfo.GetUserId())
+	if msgErr != nil {
+		return reply, msgErr
+	}
+	if !playerInfo.GetShiSanShuiPlaced() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_RepetitiveOperation, "")
+	}
+	// 所有人都摆好后马上进入比牌，不能再取消
+	if time.Now().Unix() >= roomInfo.GetDoTime() {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_WrongRoomState, "")
+	}
+	playerInfo.ShiSanShuiPlaced = false
+	obj.pushPlaced(roomInfo, playerInfo)
+	return packReply(roomInfo, &pb.ShiSanShuiAskForPlaceReply{})
+}
+
+// RequestExitInGame 玩家在对局中退出房间
+// 摆牌阶段还没摆牌的由系统摆牌，这里只标记为等待踢出，结算后状态置空由房间的Kick踢出
+func (obj *ShiSanShuiPlay) RequestExitInGame(request *pb.Driver2GameLogicInfo, extroInfo *pb.MessageExtroInfo) (*pb.Driver2GameLogicInfo, *pb.ErrorMessage) {
+	reply := &pb.Driver2GameLogicInfo{}
+	uid := extroInfo.GetUserId()
+	roomInfo := request.GetRoomInfo()
+	playerInfo := common.GetRoomPlayerInfo(roomInfo, uid)
+	if playerInfo == nil {
+		return reply, common.GetGrpcErrorMessage(pb.ErrorCode_NotInRoom, "")
+	}
+	playerInfo.WaitKick = pb.RoomSeatsChangeReason_RoomSeatsChangeReason_Exit
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStatePlay && roomInfo.GetNextRoomState() == pb.RoomState_RoomStateCompare &&
+		playerInfo.GetPlayerRoomState() == pb.PlayerRoomState_PlayerRoomStatePlay {
+		msgErr := obj.autoPlace(roomInfo, playerInfo)
+		if msgErr != nil {
+			return reply, msgErr
+		}
+		obj.checkAllPlaced(roomInfo, time.Now().Unix())
+	}
+	// 结算阶段本局已经结算完了，可以直接踢出
+	if roomInfo.GetCurRoomState() == pb.RoomState_RoomStateSettle && roomInfo.GetNextRoomState() != pb.RoomState_RoomStateSettle {
+		playerInfo.PlayerRoomState = pb.PlayerRoomState_PlayerRoomStateNone
+	}
+	return packReply(roomInfo, &pb.GameExitRoomReply{})
+}
@@ -0,0 +1,521 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	"sort"
+	"strconv"
+)
+
+// 每个玩家的手牌张数
+const handPokerNum = 13
+
+// 一副牌（不含大小王）的张数
+const deckPokerNum = 52
+
+// 头墩的张数，中墩和尾墩都是5张
+const frontPokerNum = 3
+
+// 墩的张数
+const heapPokerNum = 5
+
+// 头墩冲三的水数
+const frontTrioPoint = 3
+
+// 中墩葫芦、铁支、同花顺的水数
+const middleFullHousePoint = 2
+const middleFourOfAKindPoint = 8
+const middleStraightFlushPoint = 10
+
+// 尾墩铁支、同花顺的水数
+const backFourOfAKindPoint = 4
+const backStraightFlushPoint = 5
+
+// specialPoints 特殊牌型赢每个玩家的水数
+var specialPoints = map[pb.ShiSanShuiPokerType]int32{
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_TrioFlush:          3,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_TrioStraight:       3,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_SixPairBandOne:     3,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_FivePairBandTrio:   5,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_FourTrioBandOne:    6,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_SameColor:          10,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_AllSmall:           10,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_AllBig:             10,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_ThreeGroupFour:     20,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_ThreeStraightFlush: 20,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_TwelveRoyalty:      24,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_Dragon:             36,
+	pb.ShiSanShuiPokerType_ShiSanShuiPokerType_SupremeDragon:      108,
+}
+
+// heapInfo 一墩牌的牌型和比大小的得分
+type heapInfo struct {
+	pokerType pb.ShiSanShuiPokerType
+	// 先比牌型，再按照张数多的点数优先、点数大的优先依次比较
+	score int64
+}
+
+// getRoomConfigInt64 获取房间的整数配置
+func getRoomConfigInt64(roomInfo *pb.RoomInfo, configName string) (int64, *pb.ErrorMessage) {
+	configStr := common.GetRoomConfig(roomInfo, configName)
+	configNum, err := strconv.ParseInt(configStr, 10, 64)
+	if err != nil {
+		common.LogError("ShiSanShui getRoomConfigInt64 has err", configName, configStr, err)
+		return 0, common.GetGrpcErrorMessage(pb.ErrorCode_ServerError, "")
+	}
+	return configNum, nil
+}
+
+// getShiSanShuiPokerValue 获取单张牌比大小时的点数，A最大
+func getShiSanShuiPokerValue(poker *pb.Poker) int {
+	if poker.GetPokerNum() == pb.PokerNum_PokerNum1 {
+		return 14
+	}
+	return int(poker.GetPokerNum())
+}
+
+// isRedPoker 是否是红色的牌（方块、红桃）
+func isRedPoker(poker *pb.Poker) bool {
+	return poker.GetPokerColor() == pb.PokerColor_PokerColorDiamond || poker.GetPokerColor() == pb.PokerColor_PokerColorHeart
+}
+
+// getHeapInfo 获取一墩牌的牌型，头墩3张只有乌龙、对子、冲三，中墩和尾墩5张
+func getHeapInfo(pokers []*pb.Poker) heapInfo {
+	countMap := make(map[int]int)
+	isFlush := true
+	for _, poker := range pokers {
+		countMap[getShiSanShuiPokerValue(poker)]++
+		if poker.GetPokerColor() != pokers[0].GetPokerColor() {
+			isFlush = false
+		}
+	}
+	// 张数多的点数在前，张数一样点数大的在前
+	values := make([]int, 0, len(countMap))
+	for value := range countMap {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if countMap[values[i]] != countMap[values[j]] {
+			return countMap[values[i]] > countMap[values[j]]
+		}
+		return values[i] > values[j]
+	})
+
+	isStraight := false
+	if len(pokers) == heapPokerNum && len(values) == heapPokerNum {
+		if values[0]-values[4] == 4 {
+			isStraight = true
+		} else if values[0] == 14 && values[1] == 5 {
+			// A2345是最小的顺子
+			isStraight = true
+			values = []int{5, 4, 3, 2, 1}
+		}
+	} else {
+		isFlush = isFlush && len(pokers) == heapPokerNum
+	}
+
+	var pokerType pb.ShiSanShuiPokerType
+	maxCount := countMap[values[0]]
+	switch {
+	case isStraight && isFlush:
+		pokerType = pb.ShiSanShuiPokerType_ShiSanShuiPokerType_StraightFlush
+	case maxCount == 4:
+		pokerType = pb.ShiSanShuiPokerType_ShiSanShuiPokerType_FourOfAKind
+	case maxCount == 3 && len(values) == 2:
+		pokerType = pb.ShiSanShuiPokerType_ShiSanShuiPokerType_FullHouse
+	case isFlush:
+		pokerType = pb.ShiSanShuiPokerType_ShiSanShuiPokerType_Flush
+	case isStraight:
+		pokerType = pb.ShiSanShuiPokerType_ShiSanShuiPokerType_Straight
+	case maxCount == 3:
+		pokerType = pb.ShiSanShuiPokerType_ShiSanShuiPokerType_Trio
+	case maxCount == 2 && countMap[values[1]] == 2:
+		pokerType = pb.ShiSanShuiPokerType_ShiSanShuiPokerType_DoublePair
+	case maxCount == 2:
+		pokerType = pb.ShiSanShuiPokerType_ShiSanShuiPokerType_Pair
+	default:
+		pokerType = pb.ShiSanShuiPokerType_ShiSanShuiPokerType_HighCard
+	}
+
+	score := int64(pokerType)
+	for index := 0; index < heapPokerNum; index++ {
+		score <<= 4
+		if index < len(values) {
+			score += int64(values[index])
+		}
+	}
+	return heapInfo{pokerType: pokerType, score: score}
+}
+
+// getHeapPoint 获取赢下一墩的水数，heapIndex为0、1、2分别表示头墩、中墩、尾墩
+func getHeapPoint(heapIndex int, pokerType pb.ShiSanShuiPokerType) int32 {
+	switch {
+	case heapIndex == 0 && pokerType == pb.ShiSanShuiPokerType_ShiSanShuiPokerType_Trio:
+		return frontTrioPoint
+	case heapIndex == 1 && pokerType == pb.ShiSanShuiPokerType_ShiSanShuiPokerType_FullHouse:
+		return middleFullHousePoint
+	case heapIndex == 1 && pokerType == pb.ShiSanShuiPokerType_ShiSanShuiPokerType_FourOfAKind:
+		return middleFourOfAKindPoint
+	case heapIndex == 1 && pokerType == pb.ShiSanShuiPokerType_ShiSanShuiPokerType_StraightFlush:
+		return middleStraightFlushPoint
+	case heapIndex == 2 && pokerType == pb.ShiSanShuiPokerType_ShiSanShuiPokerType_FourOfAKind:
+		return backFourOfAKindPoint
+	case heapIndex == 2 && pokerType == pb.ShiSanShuiPokerType_ShiSanShuiPokerType_StraightFlush:
+		return backStraightFlushPoint
+	}
+	return 1
+}
+
+// getPlaceHeaps 获取摆牌方案的三墩牌
+func getPlaceHeaps(placePoker *pb.ShiSanShuiPlacePoker) [][]*pb.Poker {
+	return [][]*pb.Poker{placePoker.GetPokers1(), placePoker.GetPokers2(), placePoker.GetPokers3()}
+}
+
+// getPlaceHeapInfos 获取摆牌方案三墩牌的牌型
+func getPlaceHeapInfos(placePoker *pb.ShiSanShuiPlacePoker) []heapInfo {
+	var infos []heapInfo
+	for _, heap := range getPlaceHeaps(placePoker) {
+		infos = append(infos, getHeapInfo(heap))
+	}
+	return infos
+}
+
+// checkPlacePoker 检查摆牌方案：三墩的张数正确，牌都是自己的手牌，并且头墩<=中墩<=尾墩（否则是倒水）
+// 检查通过时填上每一墩的牌型
+func checkPlacePoker(placePoker *pb.ShiSanShuiPlacePoker, hand []*pb.Poker) *pb.ErrorMessage {
+	if len(placePoker.GetPokers1()) != frontPokerNum || len(placePoker.GetPokers2()) != heapPokerNum || len(placePoker.GetPokers3()) != heapPokerNum {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+	}
+	var allPokers []*pb.Poker
+	for _, heap := range getPlaceHeaps(placePoker) {
+		allPokers = append(allPokers, heap...)
+	}
+	if !isSamePokers(allPokers, hand) {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_InvalidOperate, "")
+	}
+	infos := getPlaceHeapInfos(placePoker)
+	if infos[0].score > infos[1].score || infos[1].score > infos[2].score {
+		return common.GetGrpcErrorMessage(pb.ErrorCode_ErrorPokerType, "")
+	}
+	placePoker.PokersType1 = infos[0].pokerType
+	placePoker.PokersType2 = infos[1].pokerType
+	placePoker.PokersType3 = infos[2].pokerType
+	placePoker.SpetialType = pb.ShiSanShuiPokerType_ShiSanShuiPokerType_None
+	return nil
+}
+
+// isSamePokers 判断两组牌是否完全一样（不考虑顺序）
+func isSamePokers(a []*pb.Poker, b []*pb.Poker) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, aPoker := range a {
+		found := false
+		for index, bPoker := range b {
+			if used[index] || aPoker.GetPokerNum() != bPoker.GetPokerNum() || aPoker.GetPokerColor() != bPoker.GetPokerColor() {
+				continue
+			}
+			used[index] = true
+			found = true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// pickPokers 按照二进制位从手牌中取牌
+func pickPokers(hand []*pb.Poker, mask int) []*pb.Poker {
+	var pokers []*pb.Poker
+	for index, poker := range hand {
+		if mask&(1<<uint(index)) != 0 {
+			pokers = append(pokers, poker)
+		}
+	}
+	return pokers
+}
+
+// getCombinationMasks 获取从n张牌中取k张的所有组合的二进制位
+func getCombinationMasks(n int, k int, fromMask int) []int {
+	var masks []int
+	var pick func(start int, left int, mask int)
+	pick = func(start int, left int, mask int) {
+		if left == 0 {
+			masks = append(masks, mask)
+			return
+		}
+		for index := start; index < n; index++ {
+			if fromMask&(1<<uint(index)) != 0 {
+				pick(index+1, left-1, mask|1<<uint(index))
+			}
+		}
+	}
+	pick(0, k, 0)
+	return masks
+}
+
+// placeSuggestion 一种摆牌方案和它的排序依据
+type placeSuggestion struct {
+	masks [3]int
+	infos [3]heapInfo
+	// 牌型和额外水数的总和，越大越好
+	strength int
+}
+
+// forEachPlace 遍历手牌所有不倒水的摆牌方案
+func forEachPlace(hand []*pb.Poker, fn func(masks [3]int, infos [3]heapInfo)) {
+	allMask := 1<<uint(len(hand)) - 1
+	heapInfos := make(map[int]heapInfo)
+	getInfo := func(mask int) heapInfo {
+		info, ok := heapInfos[mask]
+		if !ok {
+			info = getHeapInfo(pickPokers(hand, mask))
+			heapInfos[mask] = info
+		}
+		return info
+	}
+	for _, frontMask := range getCombinationMasks(len(hand), frontPokerNum, allMask) {
+		frontInfo := getInfo(frontMask)
+		for _, middleMask := range getCombinationMasks(len(hand), heapPokerNum, allMask^frontMask) {
+			middleInfo := getInfo(middleMask)
+			if frontInfo.score > middleInfo.score {
+				continue
+			}
+			backMask := allMask ^ frontMask ^ middleMask
+			backInfo := getInfo(backMask)
+			if middleInfo.score > backInfo.score {
+				continue
+			}
+			fn([3]int{frontMask, middleMask, backMask}, [3]heapInfo{frontInfo, middleInfo, backInfo})
+		}
+	}
+}
+
+// getPlaceSuggestions 获取手牌排好序的摆牌建议，最多num个，第一个是最优的摆牌
+// 同样的三墩牌型只保留最大的一种摆法；按照三墩牌型加上额外水数的总和排序，一样时依次比较尾墩、中墩、头墩
+func getPlaceSuggestions(hand []*pb.Poker, num int) []*pb.ShiSanShuiPlacePoker {
+	bestByType := make(map[[3]pb.ShiSanShuiPokerType]*placeSuggestion)
+	forEachPlace(hand, func(masks [3]int, infos [3]heapInfo) {
+		strength := 0
+		for heapIndex, info := range infos {
+			strength += int(info.pokerType) + int(getHeapPoint(heapIndex, info.pokerType)) - 1
+		}
+		suggestion := &placeSuggestion{masks: masks, infos: infos, strength: strength}
+		typeKey := [3]pb.ShiSanShuiPokerType{infos[0].pokerType, infos[1].pokerType, infos[2].pokerType}
+		if best, ok := bestByType[typeKey]; !ok || isBetterSuggestion(suggestion, best) {
+			bestByType[typeKey] = suggestion
+		}
+	})
+	suggestions := make([]*placeSuggestion, 0, len(bestByType))
+	for _, suggestion := range bestByType {
+		suggestions = append(suggestions, suggestion)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return isBetterSuggestion(suggestions[i], suggestions[j])
+	})
+	if len(suggestions) > num {
+		suggestions = suggestions[:num]
+	}
+	var placePokers []*pb.ShiSanShuiPlacePoker
+	for _, suggestion := range suggestions {
+		placePokers = append(placePokers, &pb.ShiSanShuiPlacePoker{
+			Pokers1:     pickPokers(hand, suggestion.masks[0]),
+			PokersType1: suggestion.infos[0].pokerType,
+			Pokers2:     pickPokers(hand, suggestion.masks[1]),
+			PokersType2: suggestion.infos[1].pokerType,
+			Pokers3:     pickPokers(hand, suggestion.masks[2]),
+			PokersType3: suggestion.infos[2].pokerType,
+		})
+	}
+	return placePokers
+}
+
+// isBetterSuggestion 摆牌方案a是否比b好
+func isBetterSuggestion(a *placeSuggestion, b *placeSuggestion) bool {
+	if a.strength != b.strength {
+		return a.strength > b.strength
+	}
+	for heapIndex := 2; heapIndex >= 0; heapIndex-- {
+		if a.infos[heapIndex].score != b.infos[heapIndex].score {
+			return a.infos[heapIndex].score > b.infos[heapIndex].score
+		}
+	}
+	return false
+}
+
+// getSpecialType 获取手牌的特殊牌型，没有返回None
+// 三同花、三顺子、三同花顺需要找到对应的摆法，返回的摆牌方案为nil时使用最优摆牌
+func getSpecialType(hand []*pb.Poker) (pb.ShiSanShuiPokerType, *pb.ShiSanShuiPlacePoker) {
+	countMap := make(map[int]int)
+	colorMap := make(map[pb.PokerColor]int)
+	redNum, smallNum, bigNum, royaltyNum := 0, 0, 0, 0
+	for _, poker := range hand {
+		value := getShiSanShuiPokerValue(poker)
+		countMap[value]++
+		colorMap[poker.GetPokerColor()]++
+		if isRedPoker(poker) {
+			redNum++
+		}
+		if value <= 8 {
+			smallNum++
+		}
+		if value >= 8 {
+			bigNum++
+		}
+		if value >= 11 {
+			royaltyNum++
+		}
+	}
+	// 按照每个点数的张数统计：对子数（四条算两对）、三条数、四条数
+	pairNum, trioNum, fourNum := 0, 0, 0
+	for _, count := range countMap {
+		pairNum += count / 2
+		if count == 3 {
+			trioNum++
+		}
+		if count == 4 {
+			fourNum++
+		}
+	}
+
+	switch {
+	case len(countMap) == handPokerNum && len(colorMap) == 1:
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_SupremeDragon, nil
+	case len(countMap) == handPokerNum:
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_Dragon, nil
+	case royaltyNum >= 12:
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_TwelveRoyalty, nil
+	}
+	if placePoker := findSpecialPlace(hand, isStraightFlushHeap); placePoker != nil {
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_ThreeStraightFlush, placePoker
+	}
+	switch {
+	case fourNum == 3:
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_ThreeGroupFour, nil
+	case bigNum == handPokerNum:
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_AllBig, nil
+	case smallNum == handPokerNum:
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_AllSmall, nil
+	case redNum == 0 || redNum == handPokerNum:
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_SameColor, nil
+	case trioNum == 4 && fourNum == 0:
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_FourTrioBandOne, nil
+	case trioNum == 1 && pairNum == 6:
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_FivePairBandTrio, nil
+	case pairNum == 6:
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_SixPairBandOne, nil
+	}
+	if placePoker := findSpecialPlace(hand, isStraightHeap); placePoker != nil {
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_TrioStraight, placePoker
+	}
+	if placePoker := findSpecialPlace(hand, isFlushHeap); placePoker != nil {
+		return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_TrioFlush, placePoker
+	}
+	return pb.ShiSanShuiPokerType_ShiSanShuiPokerType_None, nil
+}
+
+// isFlushHeap 一墩牌是否同花，头墩3张同花也算
+func isFlushHeap(pokers []*pb.Poker) bool {
+	for _, poker := range pokers {
+		if poker.GetPokerColor() != pokers[0].GetPokerColor() {
+			return false
+		}
+	}
+	return true
+}
+
+// isStraightHeap 一墩牌是否是顺子，头墩3张连续也算，A可以当1
+func isStraightHeap(pokers []*pb.Poker) bool {
+	var values []int
+	hasAce := false
+	for _, poker := range pokers {
+		value := getShiSanShuiPokerValue(poker)
+		values = append(values, value)
+		if value == 14 {
+			hasAce = true
+		}
+	}
+	isContinuous := func(values []int) bool {
+		sorted := append([]int{}, values...)
+		sort.Ints(sorted)
+		for index := 1; index < len(sorted); index++ {
+			if sorted[index]-sorted[index-1] != 1 {
+				return false
+			}
+		}
+		return true
+	}
+	if isContinuous(values) {
+		return true
+	}
+	if !hasAce {
+		return false
+	}
+	for index, value := range values {
+		if value == 14 {
+			values[index] = 1
+		}
+	}
+	return isContinuous(values)
+}
+
+// isStraightFlushHeap 一墩牌是否是同花顺
+func isStraightFlushHeap(pokers []*pb.Poker) bool {
+	return isFlushHeap(pokers) && isStraightHeap(pokers)
+}
+
+// findSpecialPlace 找到三墩都满足isMatch的摆法，中墩不大于尾墩，找不到返回nil
+func findSpecialPlace(hand []*pb.Poker, isMatch func(pokers []*pb.Poker) bool) *pb.ShiSanShuiPlacePoker {
+	allMask := 1<<uint(len(hand)) - 1
+	for _, frontMask := range getCombinationMasks(len(hand), frontPokerNum, allMask) {
+		front := pickPokers(hand, frontMask)
+		if !isMatch(front) {
+			continue
+		}
+		for _, middleMask := range getCombinationMasks(len(hand), heapPokerNum, allMask^frontMask) {
+			middle := pickPokers(hand, middleMask)
+			back := pickPokers(hand, allMask^frontMask^middleMask)
+			if !isMatch(middle) || !isMatch(back) {
+				continue
+			}
+			middleInfo, backInfo := getHeapInfo(middle), getHeapInfo(back)
+			if middleInfo.score > backInfo.score {
+				continue
+			}
+			return &pb.ShiSanShuiPlacePoker{
+				Pokers1:     front,
+				PokersType1: getHeapInfo(front).pokerType,
+				Pokers2:     middle,
+				PokersType2: middleInfo.pokerType,
+				Pokers3:     back,
+				PokersType3: backInfo.pokerType,
+			}
+		}
+	}
+	return nil
+}
+
+// getPlayPlayers 获取本局参与游戏的玩家
+func getPlayPlayers(roomInfo *pb.RoomInfo) []*pb.RoomPlayerInfo {
+	var players []*pb.RoomPlayerInfo
+	for _, onePlayer := range roomInfo.GetPlayerInfo() {
+		if onePlayer.GetUuid() == "" || onePlayer.GetPlayerRoomState() != pb.PlayerRoomState_PlayerRoomStatePlay {
+			continue
+		}
+		players = append(players, onePlayer)
+	}
+	return players
+}
+
+// isOnline 玩家是否在线
+func isOnline(onePlayer *pb.RoomPlayerInfo) bool {
+	online, msgErr := common.Pusher.CheckOnline(onePlayer.GetUuid())
+	if msgErr != nil {
+		common.LogError("ShiSanShui isOnline CheckOnline has err", onePlayer.GetUuid(), msgErr)
+		return false
+	}
+	return online
+}
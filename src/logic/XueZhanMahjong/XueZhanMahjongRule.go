@@ -0,0 +1,215 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	Mahjong "gameServer-demo/src/logic/Mahjong"
+	"github.com/golang/protobuf/proto"
+)
+
+func init() {
+	common.AllComponentMap["XueZhanMahjongReady"] = &Mahjong.MahjongReady{Variant: xueZhanVariant}
+	common.AllComponentMap["XueZhanMahjongDeal"] = &Mahjong.MahjongDeal{Variant: xueZhanVariant}
+	common.AllComponentMap["XueZhanMahjongChangeThreeCards"] = &Mahjong.MahjongChangeThreeCards{Variant: xueZhanVariant}
+	common.AllComponentMap["XueZhanMahjongBlankSuit"] = &Mahjong.MahjongBlankSuit{Variant: xueZhanVariant}
+	common.AllComponentMap["XueZhanMahjongPlay"] = &Mahjong.MahjongPlay{Variant: xueZhanVariant}
+	common.AllComponentMap["XueZhanMahjongSettle"] = &Mahjong.MahjongSettle{Variant: xueZhanVariant}
+}
+
+// xueZhanVariant 血战麻将的规则：只有筒条万，要定缺，胡了牌的玩家退出，其他玩家血战到底；
+// 胡牌按番数计分，流局时查花猪、查大叫、退税
+var xueZhanVariant = &Mahjong.Variant{
+	Name:          "XueZhanMahjong",
+	GameType:      pb.GameType_XueZhanMahjong,
+	ConfigTemp:    &common.XueZhanMahjongGameConfigTemp,
+	Colors:        xueZhanColors,
+	HasBlankSuit:  true,
+	IsBloodBattle: true,
+	KongReason:    pb.ResourceChangeReason_XueZhanMahjongKongChangeGold,
+	WinReason:     pb.ResourceChangeReason_XueZhanMahjongSettleChangeGold,
+	ErrorCodes: Mahjong.ErrorCodes{
+		NoPermissionOutput: pb.ErrorCode_XueZhanMahjongErrorCodeNoPermissionOutput,
+		Output:             pb.ErrorCode_XueZhanMahjongErrorCodeOutput,
+		NoPermissionPong:   pb.ErrorCode_XueZhanMahjongErrorCodeNoPermissionPong,
+		NoPermissionKong:   pb.ErrorCode_XueZhanMahjongErrorCodeNoPermissionKong,
+		Kong:               pb.ErrorCode_XueZhanMahjongErrorCodeKong,
+		NoPermissionWin:    pb.ErrorCode_XueZhanMahjongErrorCodeNoPermissionWin,
+		BlankSuitColor:     pb.ErrorCode_XueZhanMahjongErrorCodeBlankSuitColorError,
+	},
+	NewOperateRequest: func() Mahjong.OperateRequest {
+		return &pb.XueZhanMahjongOperateRequest{}
+	},
+	NewOperateReply: func(roomId string, playerIndex int32) proto.Message {
+		return &pb.XueZhanMahjongOperateReply{RoomId: roomId, PlayerIndex: playerIndex}
+	},
+	GetWinChecker: func(_ *pb.RoomInfo) Mahjong.WinChecker {
+		return canWin
+	},
+	GetWin:       getWin,
+	BeforeSettle: beforeSettle,
+}
+
+// xueZhanColors 血战麻将只有筒条万三种花色，共108张
+var xueZhanColors = []pb.MahjongColor{
+	pb.MahjongColor_MahjongColorDot,
+	pb.MahjongColor_MahjongColorBamboo,
+	pb.MahjongColor_MahjongColorCharacter,
+}
+
+// winTypeFans 胡牌类型对应的番数，多个类型的番数相加
+var winTypeFans = map[pb.MahjongWinEnum]int64{
+	pb.MahjongWinEnum_Ping:             0,
+	pb.MahjongWinEnum_PongPong:         1,
+	pb.MahjongWinEnum_GoldHook:         1,
+	pb.MahjongWinEnum_FullFlush:        2,
+	pb.MahjongWinEnum_SevenPair:        2,
+	pb.MahjongWinEnum_OneNine:          2,
+	pb.MahjongWinEnum_Pair258:          2,
+	pb.MahjongWinEnum_DragonPair:       3,
+	pb.MahjongWinEnum_DoubleDragonPair: 4,
+	pb.MahjongWinEnum_Seabed:           1,
+	pb.MahjongWinEnum_EighteenArhats:   5,
+	pb.MahjongWinEnum_DrawSky:          5,
+	pb.MahjongWinEnum_DiscardLand:      5,
+}
+
+// winSourceFans 胡牌来源额外加的番数
+var winSourceFans = map[pb.MahjongWinSourceEnum]int64{
+	pb.MahjongWinSourceEnum_Draw:             1,
+	pb.MahjongWinSourceEnum_Discard:          0,
+	pb.MahjongWinSourceEnum_GrabKong:         1,
+	pb.MahjongWinSourceEnum_DrawAfterKong:    2,
+	pb.MahjongWinSourceEnum_DiscardAfterKong: 1,
+}
+
+// canWin 血战麻将的胡牌牌型：基本胡牌牌型或者七对
+func canWin(counts Mahjong.TileCounts) bool {
+	return Mahjong.CanWinNormal(counts) || Mahjong.IsSevenPairs(counts)
+}
+
+// hasBlankSuit 牌中是否还有定缺花色的牌，有定缺的牌不能胡牌
+func hasBlankSuit(mahjongs []*pb.Mahjong, blankSuit pb.MahjongColor) bool {
+	return Mahjong.CountColor(mahjongs, blankSuit) > 0
+}
+
+// getWinTypes 获取胡牌的类型和番数(不含胡牌来源的番数)，hand为包含胡的那张牌的手牌
+// 每有一个根(四张一样的牌，龙七对的龙除外)加一番
+func getWinTypes(mahjongPlayer *pb.MahjongPlayerInfo, hand []*pb.Mahjong) ([]pb.MahjongWinEnum, int64) {
+	var winTypes []pb.MahjongWinEnum
+	counts := Mahjong.GetTileCounts(hand)
+	allMahjongs := Mahjong.GetAllMahjongs(mahjongPlayer, hand)
+	rootNum := Mahjong.GetQuadNum(Mahjong.GetTileCounts(allMahjongs))
+
+	if Mahjong.IsSevenPairs(counts) {
+		switch quadNum := Mahjong.GetQuadNum(counts); {
+		case quadNum >= 2:
+			winTypes = append(winTypes, pb.MahjongWinEnum_DoubleDragonPair)
+			rootNum -= 2
+		case quadNum == 1:
+			winTypes = append(winTypes, pb.MahjongWinEnum_DragonPair)
+			rootNum--
+		default:
+			winTypes = append(winTypes, pb.MahjongWinEnum_SevenPair)
+		}
+	} else {
+		isAllTriplet, isOneNine := false, false
+		for _, oneSplit := range Mahjong.GetWinSplits(counts) {
+			isAllTriplet = isAllTriplet || Mahjong.IsAllTriplet(oneSplit)
+			isOneNine = isOneNine || Mahjong.IsAllWithOneNine(oneSplit)
+		}
+		// 碰牌和杠牌也必须带幺九
+		isOneNine = isOneNine && Mahjong.IsAllNum(Mahjong.GetMeldMahjongs(mahjongPlayer), 1, 9)
+
+		switch {
+		case len(mahjongPlayer.GetKongRegion()) == 4 && len(hand) == 2:
+			// 十八罗汉包含了金钩钓和碰碰胡，四个杠不再算根
+			winTypes = append(winTypes, pb.MahjongWinEnum_EighteenArhats)
+			rootNum -= 4
+		case isAllTriplet:
+			winTypes = append(winTypes, pb.MahjongWinEnum_PongPong)
+			if Mahjong.IsAllNum(allMahjongs, 2, 5, 8) {
+				winTypes = append(winTypes, pb.MahjongWinEnum_Pair258)
+			}
+			if len(hand) == 2 {
+				winTypes = append(winTypes, pb.MahjongWinEnum_GoldHook)
+			}
+		}
+		if isOneNine {
+			winTypes = append(winTypes, pb.MahjongWinEnum_OneNine)
+		}
+	}
+	if Mahjong.IsSameColor(allMahjongs) {
+		winTypes = append(winTypes, pb.MahjongWinEnum_FullFlush)
+	}
+	if len(winTypes) == 0 {
+		winTypes = append(winTypes, pb.MahjongWinEnum_Ping)
+	}
+
+	fan := int64(rootNum)
+	for _, oneType := range winTypes {
+		fan += winTypeFans[oneType]
+	}
+	return winTypes, fan
+}
+
+// getWinScore 根据番数计算胡牌的分数：底分乘以2的番数次方，番数不超过封顶番数
+func getWinScore(roomInfo *pb.RoomInfo, fan int64) (int64, *pb.ErrorMessage) {
+	baseScore, msgErr := Mahjong.GetRoomConfigInt64(roomInfo, "BaseScore")
+	if msgErr != nil {
+		return 0, msgErr
+	}
+	maxFan, msgErr := Mahjong.GetRoomConfigInt64(roomInfo, "MaxFan")
+	if msgErr != nil {
+		return 0, msgErr
+	}
+	if fan > maxFan {
+		fan = maxFan
+	}
+	return baseScore << uint(fan), nil
+}
+
+// getWin 计算胡牌的类型和分数：牌型的番数加上胡牌来源、海底、天胡、地胡的番数
+func getWin(roomInfo *pb.RoomInfo, onePlayer *pb.RoomPlayerInfo, winContext *Mahjong.WinContext) (*Mahjong.WinResult, *pb.ErrorMessage) {
+	winTypes, fan := getWinTypes(onePlayer.GetMahjongPlayerInfo(), winContext.Hand)
+	fan += winSourceFans[winContext.WinSource]
+	var extraTypes []pb.MahjongWinEnum
+	if winContext.IsSeabed {
+		extraTypes = append(extraTypes, pb.MahjongWinEnum_Seabed)
+	}
+	if winContext.IsDrawSky {
+		extraTypes = append(extraTypes, pb.MahjongWinEnum_DrawSky)
+	}
+	if winContext.IsDiscardLand {
+		extraTypes = append(extraTypes, pb.MahjongWinEnum_DiscardLand)
+	}
+	for _, oneType := range extraTypes {
+		winTypes = append(winTypes, oneType)
+		fan += winTypeFans[oneType]
+	}
+	winScore, msgErr := getWinScore(roomInfo, fan)
+	if msgErr != nil {
+		return nil, msgErr
+	}
+	return &Mahjong.WinResult{
+		WinTypes:  winTypes,
+		WinSource: winContext.WinSource,
+		WinScore:  winScore,
+	}, nil
+}
+
+// getMaxReadyFan 获取玩家听牌时能胡的最大番数，没有听牌返回-1
+func getMaxReadyFan(onePlayer *pb.RoomPlayerInfo) int64 {
+	mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+	hand := mahjongPlayer.GetHandRegion()
+	maxFan := int64(-1)
+	if hasBlankSuit(hand, mahjongPlayer.GetBlankSuit()) {
+		return maxFan
+	}
+	for _, readyMahjong := range Mahjong.GetReadyMahjongs(hand, canWin) {
+		_, fan := getWinTypes(mahjongPlayer, append(append([]*pb.Mahjong{}, hand...), readyMahjong))
+		if fan > maxFan {
+			maxFan = fan
+		}
+	}
+	return maxFan
+}
@@ -0,0 +1,121 @@
+package logic
+
+import (
+	"gameServer-demo/src/common"
+	pb "gameServer-demo/src/grpc"
+	Mahjong "gameServer-demo/src/logic/Mahjong"
+)
+
+// beforeSettle 牌墙摸完时还有两个以上的玩家没胡牌，进行流局前的结算：
+// 手里还有定缺牌的花猪赔给不是花猪的玩家封顶的分数，
+// 没听牌的玩家赔给听牌的玩家最大可能的胡牌分数，没听牌的玩家退还本局杠牌收的钱
+func beforeSettle(request *pb.RoomInfo) *pb.ErrorMessage {
+	if request.GetMahjongGameInfo().GetMahjongCardWallCount() > 0 || Mahjong.GetActiveNum(request) < 2 {
+		return nil
+	}
+	maxFan, msgErr := Mahjong.GetRoomConfigInt64(request, "MaxFan")
+	if msgErr != nil {
+		return msgErr
+	}
+	maxScore, msgErr := getWinScore(request, maxFan)
+	if msgErr != nil {
+		return msgErr
+	}
+	var activeIndexes []int32
+	readyFans := map[int32]int64{}
+	isFlowerPig := map[int32]bool{}
+	for index, onePlayer := range request.GetPlayerInfo() {
+		if !Mahjong.IsActive(onePlayer) {
+			continue
+		}
+		activeIndexes = append(activeIndexes, int32(index))
+		readyFans[int32(index)] = getMaxReadyFan(onePlayer)
+		mahjongPlayer := onePlayer.GetMahjongPlayerInfo()
+		isFlowerPig[int32(index)] = hasBlankSuit(mahjongPlayer.GetHandRegion(), mahjongPlayer.GetBlankSuit())
+	}
+
+	// 查花猪
+	beforeSettleTransfer(request, pb.MahjongBeforeSettleEnum_CheckFlowerPig, func(transferFn func(payer, receiver int32, amount int64)) {
+		for _, payer := range activeIndexes {
+			if !isFlowerPig[payer] {
+				continue
+			}
+			for _, receiver := range activeIndexes {
+				if !isFlowerPig[receiver] {
+					transferFn(payer, receiver, maxScore)
+				}
+			}
+		}
+	})
+
+	// 查大叫
+	var readyMsgErr *pb.ErrorMessage
+	beforeSettleTransfer(request, pb.MahjongBeforeSettleEnum_CheckReady, func(transferFn func(payer, receiver int32, amount int64)) {
+		for _, receiver := range activeIndexes {
+			if readyFans[receiver] < 0 {
+				continue
+			}
+			readyScore, msgErr := getWinScore(request, readyFans[receiver])
+			if msgErr != nil {
+				readyMsgErr = msgErr
+				return
+			}
+			for _, payer := range activeIndexes {
+				if readyFans[payer] < 0 && !isFlowerPig[payer] {
+					transferFn(payer, receiver, readyScore)
+				}
+			}
+		}
+	})
+	if readyMsgErr != nil {
+		return readyMsgErr
+	}
+
+	// 退税
+	beforeSettleTransfer(request, pb.MahjongBeforeSettleEnum_DrawBack, func(transferFn func(payer, receiver int32, amount int64)) {
+		kongDetails := append([]*pb.MahjongSettleDetail{}, request.GetMahjongGameInfo().GetSettleDetail()...)
+		for _, oneDetail := range kongDetails {
+			// 付杠钱的那一条明细，杠牌的玩家还没胡牌并且没有听牌时退还
+			konger := oneDetail.GetKongInfo().GetKongPlayerIndex()
+			readyFan, isKongerActive := readyFans[konger]
+			if oneDetail.GetKongInfo() == nil || oneDetail.GetAmountChange() >= 0 || !isKongerActive || readyFan >= 0 {
+				continue
+			}
+			transferFn(konger, oneDetail.GetPlayerIndex(), -oneDetail.GetAmountChange())
+		}
+	})
+	return nil
+}
+
+// beforeSettleTransfer 执行一种流局前的结算，并把每个座位的金币变化推送给所有玩家
+func beforeSettleTransfer(request *pb.RoomInfo, beforeSettleType pb.MahjongBeforeSettleEnum, fn func(transferFn func(payer, receiver int32, amount int64))) {
+	beforeBalances := Mahjong.GetSeatBalances(request)
+	hasTransfer := false
+	fn(func(payer, receiver int32, amount int64) {
+		detail := &pb.MahjongSettleDetail{
+			BeforeSettleInfo: &pb.MahjongBeforeSettleInfo{
+				PlayerIndex:  payer,
+				PlayerUuid:   request.GetPlayerInfo()[payer].GetUuid(),
+				BeforeSettle: beforeSettleType,
+			},
+		}
+		if Mahjong.Transfer(request, payer, receiver, amount, detail, pb.ResourceChangeReason_XueZhanMahjongSettleChangeGold) > 0 {
+			hasTransfer = true
+		}
+	})
+	if !hasTransfer {
+		return
+	}
+	remainMoney := Mahjong.GetSeatBalances(request)
+	changeAmount := make([]int64, len(remainMoney))
+	for i := range remainMoney {
+		changeAmount[i] = remainMoney[i] - beforeBalances[i]
+	}
+	pushBeforeSettle := &pb.MahjongBeforeSettleNotice{
+		RoomId:       request.GetUuid(),
+		Type:         beforeSettleType,
+		ChangeAmount: changeAmount,
+		RemainMoney:  remainMoney,
+	}
+	common.RoomBroadcast(request, pushBeforeSettle)
+}
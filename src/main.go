@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"reflect"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -76,6 +77,19 @@ func main() {
 	}
 	common.GameMode = gameMode
 	common.LogInfo("common.GameMode:", common.GameMode)
+	// 附加模式，逗号隔开，例如抢座模式
+	subModeStr := baseServerConfig["GameMode"]["sub_mode"]
+	if subModeStr != "" {
+		for _, oneModeStr := range strings.Split(subModeStr, ",") {
+			subModeInt, err := strconv.Atoi(strings.TrimSpace(oneModeStr))
+			if err != nil {
+				common.LogError("subModeStr atoi has err", subModeStr, err)
+				return
+			}
+			common.SubMode = append(common.SubMode, pb.GameMode(subModeInt))
+		}
+	}
+	common.LogInfo("common.SubMode:", common.SubMode)
 
 	curServerConfig := common.ServerConfig[serverName]
 	if curServerConfig == nil {
@@ -272,6 +286,16 @@ func main() {
 		}
 		common.Tokener = tokenComponent
 	}
+
+	bonusComponentInterface := common.ComponentMap["Bonus"]
+	if bonusComponentInterface != nil {
+		bonusComponent, ok := bonusComponentInterface.(*base.Bonus)
+		if !ok {
+			common.LogError(" bonusComponentInterface not bonusComponent ")
+			return
+		}
+		common.Bonuser = bonusComponent
+	}
 	// 所有组件加载完毕，初始化也完毕的情况下，调用BeforeStart方法
 	// 有些组件需要更前置的执行
 	for _, component := range common.ComponentMap {